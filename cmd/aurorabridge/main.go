@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
 	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
@@ -27,6 +28,8 @@ import (
 	"github.com/uber/peloton/.gen/thrift/aurora/api/readonlyschedulerserver"
 
 	"github.com/uber/peloton/pkg/aurorabridge"
+	"github.com/uber/peloton/pkg/auth"
+	auth_impl "github.com/uber/peloton/pkg/auth/impl"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/buildversion"
 	"github.com/uber/peloton/pkg/common/config"
@@ -36,6 +39,7 @@ import (
 	"github.com/uber/peloton/pkg/common/metrics"
 	"github.com/uber/peloton/pkg/common/rpc"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/peer"
+	"github.com/uber/peloton/pkg/middleware/outbound"
 
 	log "github.com/sirupsen/logrus"
 	"go.uber.org/yarpc"
@@ -91,6 +95,20 @@ var (
 		"respool-path", "Aurora Bridge Resource Pool path").
 		Envar("RESPOOL_PATH").
 		String()
+
+	authType = app.Flag(
+		"auth-type",
+		"Define the auth type used, default to NOOP").
+		Default("NOOP").
+		Envar("AUTH_TYPE").
+		Enum("NOOP", "BASIC", "JWT")
+
+	authConfigFile = app.Flag(
+		"auth-config-file",
+		"config file for the auth feature, which is specific to the auth type used").
+		Default("").
+		Envar("AUTH_CONFIG_FILE").
+		String()
 )
 
 func main() {
@@ -190,6 +208,28 @@ func main() {
 		},
 	}
 
+	// The bridge is exempt from the per-user authentication: the Aurora
+	// clients call it without Peloton credentials, so there is no caller
+	// identity to pass on, and all its calls to jobmgr and resmgr are
+	// made as the internal user. Any client which can reach the bridge
+	// acts with the permissions of the internal user, and is attributed
+	// to it in the audit records, so access to the bridge must be
+	// restricted where it is deployed.
+	securityClient, err := auth_impl.CreateNewSecurityClient(
+		auth.Type(*authType),
+		*authConfigFile,
+	)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Could not create security client")
+	}
+	if auth.Type(*authType) != auth.NOOP {
+		log.WithField("auth_type", *authType).
+			Warn("Aurora bridge calls are not authenticated per user, " +
+				"they are made as the internal user")
+	}
+	authOutboundMiddleware := outbound.NewAuthOutboundMiddleware(securityClient)
+
 	dispatcher := yarpc.NewDispatcher(yarpc.Config{
 		Name:      common.PelotonAuroraBridge,
 		Inbounds:  inbounds,
@@ -197,6 +237,11 @@ func main() {
 		Metrics: yarpc.MetricsConfig{
 			Tally: rootScope,
 		},
+		OutboundMiddleware: yarpc.OutboundMiddleware{
+			Unary:  authOutboundMiddleware,
+			Oneway: authOutboundMiddleware,
+			Stream: authOutboundMiddleware,
+		},
 	})
 
 	jobClient := statelesssvc.NewJobServiceYARPCClient(
@@ -205,6 +250,9 @@ func main() {
 	podClient := podsvc.NewPodServiceYARPCClient(
 		dispatcher.ClientConfig(common.PelotonJobManager))
 
+	cronClient := cronsvc.NewCronJobServiceYARPCClient(
+		dispatcher.ClientConfig(common.PelotonJobManager))
+
 	respoolClient := respool.NewResourceManagerYARPCClient(
 		dispatcher.ClientConfig(common.PelotonResourceManager))
 
@@ -253,6 +301,8 @@ func main() {
 		rootScope,
 		jobClient,
 		podClient,
+		cronClient,
+		respoolClient,
 		respoolLoader,
 	)
 	if err != nil {
@@ -106,6 +106,12 @@ var (
 		Envar("BASIC_AUTH_CONFIG").
 		String()
 
+	tokenAuthConfigFile = app.Flag(
+		"tokenAuthConfig",
+		"config file path containing the bearer token for token auth feature").
+		Envar("TOKEN_AUTH_CONFIG").
+		String()
+
 	timeout = app.Flag(
 		"timeout",
 		"default RPC timeout (set $TIMEOUT to override)").
@@ -193,6 +199,26 @@ var (
 
 	jobGetActiveJobs = job.Command("active-list", "get a list of active jobs")
 
+	// Top level job command for workflows of batch jobs
+	jobWorkflow = job.Command("workflow", "manage workflows of batch jobs with dependencies")
+
+	jobWorkflowCreate            = jobWorkflow.Command("create", "create a workflow")
+	jobWorkflowCreateResPoolPath = jobWorkflowCreate.Arg("respool", "complete path of the "+
+		"resource pool of the nodes without one, starting from the root").Required().String()
+	jobWorkflowCreateSpec = jobWorkflowCreate.Arg("spec", "YAML workflow specification").Required().ExistingFile()
+
+	jobWorkflowGet   = jobWorkflow.Command("get", "get the status of a workflow and of its nodes")
+	jobWorkflowGetID = jobWorkflowGet.Arg("workflow", "workflow identifier").Required().String()
+
+	jobWorkflowList       = jobWorkflow.Command("list", "list the workflows, most recent first")
+	jobWorkflowListStates = jobWorkflowList.Flag("states", "only the workflows in the comma separated states, such as running,failed").Default("").Short('s').String()
+
+	jobWorkflowCancel   = jobWorkflow.Command("cancel", "cancel a running workflow, killing the jobs of its running nodes")
+	jobWorkflowCancelID = jobWorkflowCancel.Arg("workflow", "workflow identifier").Required().String()
+
+	jobWorkflowRetry   = jobWorkflow.Command("retry", "run again the failed nodes of a failed workflow")
+	jobWorkflowRetryID = jobWorkflowRetry.Arg("workflow", "workflow identifier").Required().String()
+
 	// Top level job command for stateless jobs
 	stateless = job.Command("stateless", "manage stateless jobs")
 
@@ -217,6 +243,25 @@ var (
 	watchCancel        = watch.Command("cancel", "cancel watch")
 	watchCancelWatchID = watchCancel.Arg("id", "watch id").Required().String()
 
+	// Top level audit command
+	audit = app.Command("audit", "query the audit records of the calls which mutated the cluster")
+
+	auditQuery          = audit.Command("query", "query audit records by user, entity and time")
+	auditQueryUser      = auditQuery.Flag("user", "only the calls made by the user").Default("").String()
+	auditQueryEntity    = auditQuery.Flag("entity", "only the calls targeting the entity, as <kind>:<id> or <id>").Default("").String()
+	auditQueryStartTime = auditQuery.Flag("start", "start time of the calls in RFC3339 format, defaults to 24 hours before end").Default("").String()
+	auditQueryEndTime   = auditQuery.Flag("end", "end time of the calls in RFC3339 format, defaults to now").Default("").String()
+	auditQueryLimit     = auditQuery.Flag("limit", "maximum number of records to return").Default("100").Uint32()
+
+	// Top level rebalance command
+	rebalance = app.Command("rebalance", "relocate the stateless pods which would be better placed on another host")
+
+	rebalanceRecommendations      = rebalance.Command("recommendations", "list the relocation recommendations, highest rank first")
+	rebalanceRecommendationsLimit = rebalanceRecommendations.Flag("limit", "maximum number of recommendations to list, 0 for all").Default("0").Uint32()
+
+	rebalanceApply    = rebalance.Command("apply", "migrate the pods of the recommendations with the highest rank to their target host")
+	rebalanceApplyMax = rebalanceApply.Flag("max", "maximum number of pods to migrate, defaults to the max migrations of the rebalancer").Default("0").Uint32()
+
 	workflow                   = stateless.Command("workflow", "manage workflow for stateless job")
 	workflowPause              = workflow.Command("pause", "pause a workflow")
 	workflowPauseName          = workflowPause.Arg("job", "job identifier").Required().String()
@@ -530,6 +575,16 @@ var (
 	hostMaintenanceComplete          = hostMaintenance.Command("complete", "complete host maintenance on a list of hosts")
 	hostMaintenanceCompleteHostnames = hostMaintenanceComplete.Arg("hostnames", "comma separated hostnames").Required().String()
 
+	hostMaintenanceSchedule             = hostMaintenance.Command("schedule", "schedule a maintenance window for a list of hosts")
+	hostMaintenanceScheduleHostnames    = hostMaintenanceSchedule.Arg("hostnames", "comma separated hostnames").Required().String()
+	hostMaintenanceScheduleStartTime    = hostMaintenanceSchedule.Flag("start", "start time of the maintenance window in RFC3339 format").Required().String()
+	hostMaintenanceScheduleDurationSecs = hostMaintenanceSchedule.Flag("duration", "duration of the maintenance window in seconds").Required().Uint32()
+
+	hostMaintenanceWindows = hostMaintenance.Command("windows", "list the scheduled and active maintenance windows")
+
+	hostMaintenanceCancel         = hostMaintenance.Command("cancel", "cancel a maintenance window")
+	hostMaintenanceCancelWindowID = hostMaintenanceCancel.Arg("id", "maintenance window id").Required().String()
+
 	hostQuery       = host.Command("query", "query hosts by state(s)")
 	hostQueryStates = hostQuery.Flag("states", "host state(s) to filter").Default("").Short('s').String()
 
@@ -751,16 +806,26 @@ func main() {
 		app.FatalIfError(err, "Fail to initialize service discovery")
 	}
 
-	var basicAuthConfigPtr *middleware.BasicAuthConfig
-	if len(*basicAuthConfigFile) != 0 {
-		var basicAuthConfig middleware.BasicAuthConfig
-		if err := common_config.Parse(&basicAuthConfig, *basicAuthConfigFile); err != nil {
+	var authMiddleware middleware.OutboundMiddleware
+	if len(*tokenAuthConfigFile) != 0 {
+		var tokenAuthConfig middleware.TokenAuthConfig
+		if err := common_config.Parse(&tokenAuthConfig, *tokenAuthConfigFile); err != nil {
 			app.FatalIfError(err, "Fail to load auth config file")
 		}
-		basicAuthConfigPtr = &basicAuthConfig
+		authMiddleware = middleware.NewTokenAuthOutboundMiddleware(&tokenAuthConfig)
+	} else {
+		var basicAuthConfigPtr *middleware.BasicAuthConfig
+		if len(*basicAuthConfigFile) != 0 {
+			var basicAuthConfig middleware.BasicAuthConfig
+			if err := common_config.Parse(&basicAuthConfig, *basicAuthConfigFile); err != nil {
+				app.FatalIfError(err, "Fail to load auth config file")
+			}
+			basicAuthConfigPtr = &basicAuthConfig
+		}
+		authMiddleware = middleware.NewBasicAuthOutboundMiddleware(basicAuthConfigPtr)
 	}
 
-	client, err := pc.New(discovery, *timeout, basicAuthConfigPtr, *jsonFormat)
+	client, err := pc.New(discovery, *timeout, authMiddleware, *jsonFormat)
 	if err != nil {
 		app.FatalIfError(err, "Fail to initialize client")
 	}
@@ -826,6 +891,15 @@ func main() {
 		err = client.HostMaintenanceStartAction(*hostMaintenanceStartHostnames)
 	case hostMaintenanceComplete.FullCommand():
 		err = client.HostMaintenanceCompleteAction(*hostMaintenanceCompleteHostnames)
+	case hostMaintenanceSchedule.FullCommand():
+		err = client.HostMaintenanceScheduleAction(
+			*hostMaintenanceScheduleHostnames,
+			*hostMaintenanceScheduleStartTime,
+			*hostMaintenanceScheduleDurationSecs)
+	case hostMaintenanceWindows.FullCommand():
+		err = client.HostMaintenanceWindowsAction()
+	case hostMaintenanceCancel.FullCommand():
+		err = client.HostMaintenanceCancelAction(*hostMaintenanceCancelWindowID)
 	case hostQuery.FullCommand():
 		err = client.HostQueryAction(*hostQueryStates)
 	case resMgrActiveTasks.FullCommand():
@@ -1009,6 +1083,27 @@ func main() {
 		err = client.WatchPod(*watchPodJobID, *watchPodPodNames, *watchLabels)
 	case watchCancel.FullCommand():
 		err = client.CancelWatch(*watchCancelWatchID)
+	case auditQuery.FullCommand():
+		err = client.AuditQueryAction(
+			*auditQueryUser,
+			*auditQueryEntity,
+			*auditQueryStartTime,
+			*auditQueryEndTime,
+			*auditQueryLimit)
+	case jobWorkflowCreate.FullCommand():
+		err = client.WorkflowCreateAction(*jobWorkflowCreateResPoolPath, *jobWorkflowCreateSpec)
+	case jobWorkflowGet.FullCommand():
+		err = client.WorkflowGetAction(*jobWorkflowGetID)
+	case jobWorkflowList.FullCommand():
+		err = client.WorkflowListAction(*jobWorkflowListStates)
+	case jobWorkflowCancel.FullCommand():
+		err = client.WorkflowCancelAction(*jobWorkflowCancelID)
+	case jobWorkflowRetry.FullCommand():
+		err = client.WorkflowRetryAction(*jobWorkflowRetryID)
+	case rebalanceRecommendations.FullCommand():
+		err = client.RebalanceRecommendationsAction(*rebalanceRecommendationsLimit)
+	case rebalanceApply.FullCommand():
+		err = client.RebalanceApplyAction(*rebalanceApplyMax)
 	default:
 		app.Fatalf("Unknown command %s", cmd)
 	}
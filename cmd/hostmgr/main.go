@@ -17,11 +17,15 @@ package main
 import (
 	"net/url"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
+	"github.com/uber/peloton/pkg/auth"
+	auth_impl "github.com/uber/peloton/pkg/auth/impl"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/background"
 	"github.com/uber/peloton/pkg/common/backoff"
@@ -44,11 +48,15 @@ import (
 	"github.com/uber/peloton/pkg/hostmgr/queue"
 	"github.com/uber/peloton/pkg/hostmgr/reconcile"
 	"github.com/uber/peloton/pkg/hostmgr/task"
+	"github.com/uber/peloton/pkg/middleware/inbound"
+	"github.com/uber/peloton/pkg/middleware/outbound"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 	"github.com/uber/peloton/pkg/storage/stores"
 
 	log "github.com/sirupsen/logrus"
 	_ "go.uber.org/automaxprocs"
 	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/middleware"
 	"go.uber.org/yarpc/api/transport"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 )
@@ -176,6 +184,20 @@ var (
 		"bin_packing", "Bin Packing enable/disable, by default disabled.").
 		Envar("BIN_PACKING").
 		String()
+
+	authType = app.Flag(
+		"auth-type",
+		"Define the auth type used, default to NOOP").
+		Default("NOOP").
+		Envar("AUTH_TYPE").
+		Enum("NOOP", "BASIC", "JWT")
+
+	authConfigFile = app.Flag(
+		"auth-config-file",
+		"config file for the auth feature, which is specific to the auth type used").
+		Default("").
+		Envar("AUTH_CONFIG_FILE").
+		String()
 )
 
 func main() {
@@ -204,6 +226,10 @@ func main() {
 		log.WithField("error", err).Fatal("Cannot parse yaml config")
 	}
 
+	if err := cfg.HostManager.WeightedRanker.Validate(); err != nil {
+		log.WithError(err).Fatal("Invalid weighted ranker config")
+	}
+
 	if *enableSentry {
 		logging.ConfigureSentry(&cfg.SentryConfig)
 	}
@@ -318,6 +344,8 @@ func main() {
 	rootScope.Counter("boot").Inc(1)
 
 	store := stores.MustCreateStore(&cfg.Storage, rootScope)
+	ormStore := stores.MustCreateORMStore(&cfg.Storage, rootScope)
+	maintenanceWindowOps := ormobjects.NewMaintenanceWindowOps(ormStore)
 
 	authHeader, err := mesos.GetAuthHeader(&cfg.Mesos, *mesosSecretFile)
 	if err != nil {
@@ -378,7 +406,21 @@ func main() {
 	}
 	defer resmgrPeerChooser.Stop()
 
-	resmgrOutbound := t.NewOutbound(resmgrPeerChooser)
+	// pass the credentials of the internal user on the calls to
+	// resource manager, which authenticates all the calls. The calls
+	// to Mesos master do not get the credentials.
+	securityClient, err := auth_impl.CreateNewSecurityClient(
+		auth.Type(*authType),
+		*authConfigFile,
+	)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Could not create security client")
+	}
+	resmgrOutbound := middleware.ApplyUnaryOutbound(
+		t.NewOutbound(resmgrPeerChooser),
+		outbound.NewAuthOutboundMiddleware(securityClient),
+	)
 
 	outbounds := yarpc.Outbounds{
 		common.MesosMasterScheduler: mOutbound,
@@ -388,6 +430,25 @@ func main() {
 		},
 	}
 
+	securityManager, err := auth_impl.CreateNewSecurityManager(
+		auth.Type(*authType),
+		*authConfigFile,
+	)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Could not enable security feature")
+	}
+
+	// audit the calls before auth, so that the calls rejected by auth
+	// are recorded. The Mesos callbacks do not carry credentials, and
+	// are not authenticated.
+	inboundMiddleware := inbound.NewChainInboundMiddleware(
+		inbound.NewAuditInboundMiddleware(
+			ormobjects.NewAuditRecordOps(ormStore),
+			rootScope,
+		),
+		inbound.NewAuthInboundMiddleware(securityManager, driver.Name()),
+	)
 	dispatcher := yarpc.NewDispatcher(yarpc.Config{
 		Name:      common.PelotonHostManager,
 		Inbounds:  inbounds,
@@ -395,6 +456,11 @@ func main() {
 		Metrics: yarpc.MetricsConfig{
 			Tally: rootScope,
 		},
+		InboundMiddleware: yarpc.InboundMiddleware{
+			Unary:  inboundMiddleware,
+			Oneway: inboundMiddleware,
+			Stream: inboundMiddleware,
+		},
 	})
 
 	// Init the managers driven by the mesos callbacks.
@@ -482,7 +548,7 @@ func main() {
 		log.WithError(err).Fatal("Cannot register reconciler background worker.")
 	}
 
-	bin_packing.Init()
+	bin_packing.InitWithConfig(&cfg.HostManager.WeightedRanker)
 	log.Infof(" %s Bin Packing is enabled", cfg.HostManager.BinPacking)
 	offer.InitEventHandler(
 		dispatcher,
@@ -499,6 +565,7 @@ func main() {
 		bin_packing.CreateRanker(cfg.HostManager.BinPacking),
 		cfg.HostManager.BinPackingRefreshIntervalSec,
 		cfg.HostManager.HostPlacingOfferStatusTimeout,
+		maintenanceHostInfoMap,
 	)
 
 	maintenanceQueue := queue.NewMaintenanceQueue()
@@ -508,15 +575,19 @@ func main() {
 	// temporary. Eventually we should create proper API protocol for
 	// `WaitTaskStatusUpdate` and allow RM/JM to retrieve this
 	// separately.
-	taskStateManager := task.NewStateManager(
+	taskStateManager, err := task.NewStateManager(
 		dispatcher,
 		schedulerClient,
 		cfg.HostManager.TaskUpdateBufferSize,
 		cfg.HostManager.TaskUpdateAckConcurrency,
+		cfg.HostManager.TaskUpdateWAL,
 		resmgrsvc.NewResourceManagerServiceYARPCClient(
 			dispatcher.ClientConfig(common.PelotonResourceManager)),
 		rootScope,
 	)
+	if err != nil {
+		log.WithError(err).Fatal("Cannot create task state manager")
+	}
 
 	// Create new hostmgr internal service handler.
 	hostmgr.NewServiceHandler(
@@ -541,6 +612,8 @@ func main() {
 		masterOperatorClient,
 		maintenanceQueue,
 		maintenanceHostInfoMap,
+		maintenanceWindowOps,
+		offer.GetEventHandler().GetOfferPool(),
 	)
 
 	// Register background worker to start mesos task status update counter.
@@ -565,6 +638,7 @@ func main() {
 		masterOperatorClient,
 		maintenanceQueue,
 		maintenanceHostInfoMap,
+		maintenanceWindowOps,
 	)
 
 	server := hostmgr.NewServer(
@@ -578,6 +652,7 @@ func main() {
 		reconciler,
 		recoveryHandler,
 		drainer,
+		taskStateManager,
 	)
 	server.Start()
 
@@ -621,5 +696,10 @@ func main() {
 		cfg.Metrics.RuntimeMetrics.Enabled,
 		cfg.Metrics.RuntimeMetrics.CollectInterval)()
 
-	select {}
+	// wait for a stop signal, so that the task status update stream
+	// is flushed by the shutdown callback of the leader candidate
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-signals
+	log.WithField("signal", sig).Info("Stopping host manager")
 }
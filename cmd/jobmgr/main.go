@@ -35,12 +35,19 @@ import (
 	"github.com/uber/peloton/pkg/common/rpc"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/peer"
 	"github.com/uber/peloton/pkg/jobmgr"
+	"github.com/uber/peloton/pkg/jobmgr/auditsvc"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
+	"github.com/uber/peloton/pkg/jobmgr/cron"
+	"github.com/uber/peloton/pkg/jobmgr/daemon"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+	cronsvc "github.com/uber/peloton/pkg/jobmgr/jobsvc/cron"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc/stateless"
+	workflowsvc "github.com/uber/peloton/pkg/jobmgr/jobsvc/workflow"
 	"github.com/uber/peloton/pkg/jobmgr/logmanager"
 	"github.com/uber/peloton/pkg/jobmgr/podsvc"
+	"github.com/uber/peloton/pkg/jobmgr/rebalancer"
+	"github.com/uber/peloton/pkg/jobmgr/rebalancesvc"
 	"github.com/uber/peloton/pkg/jobmgr/task/activermtask"
 	"github.com/uber/peloton/pkg/jobmgr/task/deadline"
 	"github.com/uber/peloton/pkg/jobmgr/task/event"
@@ -51,7 +58,9 @@ import (
 	"github.com/uber/peloton/pkg/jobmgr/updatesvc"
 	"github.com/uber/peloton/pkg/jobmgr/volumesvc"
 	"github.com/uber/peloton/pkg/jobmgr/watchsvc"
+	"github.com/uber/peloton/pkg/jobmgr/workflow"
 	"github.com/uber/peloton/pkg/middleware/inbound"
+	"github.com/uber/peloton/pkg/middleware/outbound"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 	"github.com/uber/peloton/pkg/storage/stores"
 
@@ -199,7 +208,7 @@ var (
 		"Define the auth type used, default to NOOP").
 		Default("NOOP").
 		Envar("AUTH_TYPE").
-		Enum("NOOP", "BASIC")
+		Enum("NOOP", "BASIC", "JWT")
 
 	authConfigFile = app.Flag(
 		"auth-config-file",
@@ -322,12 +331,7 @@ func main() {
 	// store implements JobStore, TaskStore, VolumeStore, UpdateStore
 	// and FrameworkInfoStore
 	store := stores.MustCreateStore(&cfg.Storage, rootScope)
-	ormStore, ormErr := ormobjects.NewCassandraStore(
-		&cfg.Storage.Cassandra,
-		rootScope)
-	if ormErr != nil {
-		log.WithError(ormErr).Fatal("Failed to create ORM store for Cassandra")
-	}
+	ormStore := stores.MustCreateORMStore(&cfg.Storage, rootScope)
 
 	// Create both HTTP and GRPC inbounds
 	inbounds := rpc.NewInbounds(
@@ -394,7 +398,56 @@ func main() {
 		"auth_config_file": authConfigFile,
 	}).Info("Loaded auth config")
 
-	authInboundManager := inbound.NewAuthInboundMiddleware(securityManager)
+	// jobmgr calls resmgr and hostmgr on behalf of the jobs,
+	// not of their callers, so it authenticates as the internal user
+	securityClient, err := auth_impl.CreateNewSecurityClient(
+		auth.Type(*authType),
+		*authConfigFile,
+	)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Could not create security client")
+	}
+
+	rateLimitInboundMiddleware := inbound.NewRateLimitInboundMiddleware(
+		&cfg.RateLimit,
+		rootScope,
+	)
+	if cfg.RateLimit.ReloadInterval > 0 {
+		// the limits are reloaded on all the instances,
+		// whether they are leader or not
+		rateLimitReloadManager := background.NewManager()
+		rateLimitReloadManager.RegisterWorks(
+			inbound.NewRateLimitConfigReloadWork(
+				rateLimitInboundMiddleware,
+				cfg.RateLimit.ReloadInterval,
+				func() (*inbound.RateLimitConfig, error) {
+					var reloaded Config
+					if err := config.Parse(&reloaded, *cfgFiles...); err != nil {
+						return nil, err
+					}
+					return &reloaded.RateLimit, nil
+				},
+			),
+		)
+		rateLimitReloadManager.Start()
+		defer rateLimitReloadManager.Stop()
+	}
+
+	// rate limit the calls first, so that the calls over the limits of
+	// their procedure are rejected before being audited and authenticated,
+	// and audit the calls before auth, so that the calls rejected by auth
+	// are recorded. The limits of the users are enforced once the auth
+	// middleware sets the user of the call.
+	inboundMiddleware := inbound.NewChainInboundMiddleware(
+		rateLimitInboundMiddleware,
+		inbound.NewAuditInboundMiddleware(
+			ormobjects.NewAuditRecordOps(ormStore),
+			rootScope,
+		),
+		inbound.NewAuthInboundMiddleware(securityManager),
+		rateLimitInboundMiddleware.UserLimits(),
+	)
 	dispatcher := yarpc.NewDispatcher(yarpc.Config{
 		Name:      common.PelotonJobManager,
 		Inbounds:  inbounds,
@@ -402,7 +455,9 @@ func main() {
 		Metrics: yarpc.MetricsConfig{
 			Tally: rootScope,
 		},
-		InboundMiddleware: getInboundMiddleware(authInboundManager),
+		InboundMiddleware: getInboundMiddleware(inboundMiddleware),
+		OutboundMiddleware: getOutboundMiddleware(
+			outbound.NewAuthOutboundMiddleware(securityClient)),
 	})
 
 	// Declare background works
@@ -425,8 +480,24 @@ func main() {
 		dispatcher,
 		rootScope,
 		cfg.JobManager.Watch,
+		ormStore,
 	)
 
+	// Poll the resource pools and the hosts, which are not owned by
+	// job manager, for the watch api
+	watchPoller := watchsvc.NewPoller(dispatcher, watchProcessor, rootScope)
+	if cfg.JobManager.Watch.PollPeriod > 0 {
+		backgroundManager.RegisterWorks(
+			background.Work{
+				Name: "WatchPoller",
+				Func: func(_ *atomic.Bool) {
+					watchPoller.Poll()
+				},
+				Period: cfg.JobManager.Watch.PollPeriod,
+			},
+		)
+	}
+
 	jobFactory := cached.InitJobFactory(
 		store, // store implements JobStore
 		store, // store implements TaskStore
@@ -462,6 +533,112 @@ func main() {
 		cfg.JobManager.JobRuntimeCalculationViaCache,
 	)
 
+	// Create the controller which places one instance of each
+	// daemon job on every eligible host
+	daemonController := daemon.New(
+		dispatcher,
+		store, // store implements JobStore
+		jobFactory,
+		goalStateDriver,
+		rootScope,
+	)
+
+	if cfg.JobManager.Daemon.ReconcilePeriod > 0 {
+		backgroundManager.RegisterWorks(
+			background.Work{
+				Name: "DaemonJobController",
+				Func: func(_ *atomic.Bool) {
+					daemonController.Reconcile()
+				},
+				Period: cfg.JobManager.Daemon.ReconcilePeriod,
+			},
+		)
+	}
+
+	// Create the controller which launches the runs of the cron jobs
+	cronController := cron.New(
+		dispatcher,
+		store, // store implements JobStore
+		ormStore,
+		jobFactory,
+		goalStateDriver,
+		&cfg.JobManager.Cron,
+		rootScope,
+	)
+
+	if cfg.JobManager.Cron.SchedulePeriod > 0 {
+		backgroundManager.RegisterWorks(
+			background.Work{
+				Name: "CronJobController",
+				Func: func(_ *atomic.Bool) {
+					cronController.Schedule()
+				},
+				Period: cfg.JobManager.Cron.SchedulePeriod,
+			},
+		)
+	}
+
+	// Create the controller which creates the batch jobs of the nodes
+	// of the workflows once their dependencies are satisfied
+	workflowController := workflow.New(
+		dispatcher,
+		store, // store implements JobStore
+		ormStore,
+		jobFactory,
+		goalStateDriver,
+		&cfg.JobManager.Workflow,
+		rootScope,
+	)
+	goalStateDriver.AddJobTerminalListener(workflowController)
+
+	if cfg.JobManager.Workflow.ReconcilePeriod > 0 {
+		backgroundManager.RegisterWorks(
+			background.Work{
+				Name: "WorkflowController",
+				Func: func(_ *atomic.Bool) {
+					workflowController.Reconcile()
+				},
+				Period: cfg.JobManager.Workflow.ReconcilePeriod,
+			},
+		)
+	}
+
+	if cfg.JobManager.Workflow.CleanupPeriod > 0 &&
+		cfg.JobManager.Workflow.Retention > 0 {
+		backgroundManager.RegisterWorks(
+			background.Work{
+				Name: "WorkflowCleanup",
+				Func: func(_ *atomic.Bool) {
+					workflowController.Cleanup()
+				},
+				Period: cfg.JobManager.Workflow.CleanupPeriod,
+			},
+		)
+	}
+
+	// Create the rebalancer which recommends relocating the
+	// stateless pods which would be better placed on another host
+	podRebalancer := rebalancer.New(
+		dispatcher,
+		jobFactory,
+		store,
+		goalStateDriver,
+		&cfg.JobManager.Rebalancer,
+		rootScope,
+	)
+
+	if cfg.JobManager.Rebalancer.Period > 0 {
+		backgroundManager.RegisterWorks(
+			background.Work{
+				Name: "Rebalancer",
+				Func: func(_ *atomic.Bool) {
+					podRebalancer.Rebalance()
+				},
+				Period: cfg.JobManager.Rebalancer.Period,
+			},
+		)
+	}
+
 	// Init placement processor
 	placementProcessor := placement.InitProcessor(
 		dispatcher,
@@ -519,6 +696,7 @@ func main() {
 		statusUpdate,
 		backgroundManager,
 		watchProcessor,
+		watchPoller,
 	)
 
 	candidate, err := leader.NewCandidate(
@@ -557,6 +735,22 @@ func main() {
 		activeJobCache,
 	)
 
+	cronsvc.InitV1AlphaCronJobServiceHandler(
+		dispatcher,
+		ormStore,
+		cronController,
+		candidate,
+		cfg.JobManager.JobSvcCfg,
+	)
+
+	workflowsvc.InitV1AlphaWorkflowServiceHandler(
+		dispatcher,
+		ormStore,
+		workflowController,
+		candidate,
+		cfg.JobManager.JobSvcCfg,
+	)
+
 	tasksvc.InitServiceHandler(
 		dispatcher,
 		rootScope,
@@ -603,6 +797,19 @@ func main() {
 		jobFactory,
 	)
 
+	auditsvc.InitServiceHandler(
+		dispatcher,
+		rootScope,
+		ormStore,
+	)
+
+	rebalancesvc.InitServiceHandler(
+		dispatcher,
+		rootScope,
+		podRebalancer,
+		candidate,
+	)
+
 	// Start dispatch loop
 	if err := dispatcher.Start(); err != nil {
 		log.Fatalf("Could not start rpc server: %v", err)
@@ -638,3 +845,11 @@ func getInboundMiddleware(middleware inbound.DispatcherInboundMiddleWare) yarpc.
 		Stream: middleware,
 	}
 }
+
+func getOutboundMiddleware(middleware outbound.DispatcherOutboundMiddleWare) yarpc.OutboundMiddleware {
+	return yarpc.OutboundMiddleware{
+		Unary:  middleware,
+		Oneway: middleware,
+		Stream: middleware,
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/algorithms"
 
+	"github.com/uber/peloton/pkg/auth"
+	auth_impl "github.com/uber/peloton/pkg/auth/impl"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/async"
 	"github.com/uber/peloton/pkg/common/buildversion"
@@ -29,6 +31,7 @@ import (
 	"github.com/uber/peloton/pkg/common/metrics"
 	"github.com/uber/peloton/pkg/common/rpc"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/peer"
+	"github.com/uber/peloton/pkg/middleware/outbound"
 	"github.com/uber/peloton/pkg/placement"
 	"github.com/uber/peloton/pkg/placement/config"
 	"github.com/uber/peloton/pkg/placement/hosts"
@@ -134,6 +137,20 @@ var (
 		Default("BATCH").
 		Envar("TASK_TYPE").
 		String()
+
+	authType = app.Flag(
+		"auth-type",
+		"Define the auth type used, default to NOOP").
+		Default("NOOP").
+		Envar("AUTH_TYPE").
+		Enum("NOOP", "BASIC", "JWT")
+
+	authConfigFile = app.Flag(
+		"auth-config-file",
+		"config file for the auth feature, which is specific to the auth type used").
+		Default("").
+		Envar("AUTH_CONFIG_FILE").
+		String()
 )
 
 func main() {
@@ -279,6 +296,18 @@ func main() {
 		mux,
 	)
 
+	// placement engine has no users of its own, its calls to
+	// resmgr and hostmgr are authenticated as the internal user
+	securityClient, err := auth_impl.CreateNewSecurityClient(
+		auth.Type(*authType),
+		*authConfigFile,
+	)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Could not create security client")
+	}
+	authOutboundMiddleware := outbound.NewAuthOutboundMiddleware(securityClient)
+
 	log.Debug("Creating new YARPC dispatcher")
 	dispatcher := yarpc.NewDispatcher(yarpc.Config{
 		Name:      common.PelotonPlacement,
@@ -287,6 +316,11 @@ func main() {
 		Metrics: yarpc.MetricsConfig{
 			Tally: rootScope,
 		},
+		OutboundMiddleware: yarpc.OutboundMiddleware{
+			Unary:  authOutboundMiddleware,
+			Oneway: authOutboundMiddleware,
+			Stream: authOutboundMiddleware,
+		},
 	})
 
 	log.Debug("Starting YARPC dispatcher")
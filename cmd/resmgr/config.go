@@ -19,16 +19,18 @@ import (
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
 	"github.com/uber/peloton/pkg/common/metrics"
+	"github.com/uber/peloton/pkg/middleware/inbound"
 	"github.com/uber/peloton/pkg/resmgr"
 	storage "github.com/uber/peloton/pkg/storage/config"
 )
 
 // Config holds all configs to run a peloton-resmgr server.
 type Config struct {
-	Metrics      metrics.Config        `yaml:"metrics"`
-	Storage      storage.Config        `yaml:"storage"`
-	ResManager   resmgr.Config         `yaml:"resmgr"`
-	Election     leader.ElectionConfig `yaml:"election"`
-	Health       health.Config         `yaml:"health"`
-	SentryConfig logging.SentryConfig  `yaml:"sentry"`
+	Metrics      metrics.Config          `yaml:"metrics"`
+	Storage      storage.Config          `yaml:"storage"`
+	ResManager   resmgr.Config           `yaml:"resmgr"`
+	Election     leader.ElectionConfig   `yaml:"election"`
+	Health       health.Config           `yaml:"health"`
+	SentryConfig logging.SentryConfig    `yaml:"sentry"`
+	RateLimit    inbound.RateLimitConfig `yaml:"rate_limit"`
 }
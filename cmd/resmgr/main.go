@@ -19,7 +19,10 @@ import (
 
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 
+	"github.com/uber/peloton/pkg/auth"
+	auth_impl "github.com/uber/peloton/pkg/auth/impl"
 	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/background"
 	"github.com/uber/peloton/pkg/common/buildversion"
 	"github.com/uber/peloton/pkg/common/config"
 	"github.com/uber/peloton/pkg/common/health"
@@ -28,6 +31,8 @@ import (
 	"github.com/uber/peloton/pkg/common/metrics"
 	"github.com/uber/peloton/pkg/common/rpc"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/peer"
+	"github.com/uber/peloton/pkg/middleware/inbound"
+	"github.com/uber/peloton/pkg/middleware/outbound"
 	"github.com/uber/peloton/pkg/resmgr"
 	"github.com/uber/peloton/pkg/resmgr/entitlement"
 	maintenance "github.com/uber/peloton/pkg/resmgr/host"
@@ -35,6 +40,7 @@ import (
 	"github.com/uber/peloton/pkg/resmgr/respool"
 	"github.com/uber/peloton/pkg/resmgr/respool/respoolsvc"
 	"github.com/uber/peloton/pkg/resmgr/task"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 	"github.com/uber/peloton/pkg/storage/stores"
 
 	log "github.com/sirupsen/logrus"
@@ -140,6 +146,20 @@ var (
 		Default("false").
 		Envar("ENABLE_SLA_TRACKING").
 		Bool()
+
+	authType = app.Flag(
+		"auth-type",
+		"Define the auth type used, default to NOOP").
+		Default("NOOP").
+		Envar("AUTH_TYPE").
+		Enum("NOOP", "BASIC", "JWT")
+
+	authConfigFile = app.Flag(
+		"auth-config-file",
+		"config file for the auth feature, which is specific to the auth type used").
+		Default("").
+		Envar("AUTH_CONFIG_FILE").
+		String()
 )
 
 func getConfig(cfgFiles ...string) Config {
@@ -246,6 +266,7 @@ func main() {
 	mux.HandleFunc(buildversion.Get, buildversion.Handler(version))
 
 	store := stores.MustCreateStore(&cfg.Storage, rootScope)
+	ormStore := stores.MustCreateORMStore(&cfg.Storage, rootScope)
 
 	// Create both HTTP and GRPC inbounds
 	inbounds := rpc.NewInbounds(
@@ -282,6 +303,69 @@ func main() {
 		},
 	}
 
+	securityManager, err := auth_impl.CreateNewSecurityManager(
+		auth.Type(*authType),
+		*authConfigFile,
+	)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Could not enable security feature")
+	}
+	log.WithFields(log.Fields{
+		"auth_type":        *authType,
+		"auth_config_file": *authConfigFile,
+	}).Info("Loaded auth config")
+
+	// resmgr calls hostmgr to place and drain the tasks,
+	// which it does as the internal user
+	securityClient, err := auth_impl.CreateNewSecurityClient(
+		auth.Type(*authType),
+		*authConfigFile,
+	)
+	if err != nil {
+		log.WithError(err).
+			Fatal("Could not create security client")
+	}
+
+	rateLimitInboundMiddleware := inbound.NewRateLimitInboundMiddleware(
+		&cfg.RateLimit,
+		rootScope,
+	)
+	if cfg.RateLimit.ReloadInterval > 0 {
+		// the limits are reloaded on all the instances,
+		// whether they are leader or not
+		rateLimitReloadManager := background.NewManager()
+		rateLimitReloadManager.RegisterWorks(
+			inbound.NewRateLimitConfigReloadWork(
+				rateLimitInboundMiddleware,
+				cfg.RateLimit.ReloadInterval,
+				func() (*inbound.RateLimitConfig, error) {
+					var reloaded Config
+					if err := config.Parse(&reloaded, *cfgFiles...); err != nil {
+						return nil, err
+					}
+					return &reloaded.RateLimit, nil
+				},
+			),
+		)
+		rateLimitReloadManager.Start()
+		defer rateLimitReloadManager.Stop()
+	}
+
+	// rate limit the calls first, so that the calls over the limits of
+	// their procedure are rejected before being audited and authenticated,
+	// and audit the calls before auth, so that the calls rejected by auth
+	// are recorded. The limits of the users are enforced once the auth
+	// middleware sets the user of the call.
+	inboundMiddleware := inbound.NewChainInboundMiddleware(
+		rateLimitInboundMiddleware,
+		inbound.NewAuditInboundMiddleware(
+			ormobjects.NewAuditRecordOps(ormStore),
+			rootScope,
+		),
+		inbound.NewAuthInboundMiddleware(securityManager),
+		rateLimitInboundMiddleware.UserLimits(),
+	)
 	dispatcher := yarpc.NewDispatcher(yarpc.Config{
 		Name:      common.PelotonResourceManager,
 		Inbounds:  inbounds,
@@ -289,6 +373,9 @@ func main() {
 		Metrics: yarpc.MetricsConfig{
 			Tally: rootScope,
 		},
+		InboundMiddleware: getInboundMiddleware(inboundMiddleware),
+		OutboundMiddleware: getOutboundMiddleware(
+			outbound.NewAuthOutboundMiddleware(securityClient)),
 	})
 
 	hostmgrClient := hostsvc.NewInternalHostServiceYARPCClient(
@@ -343,12 +430,17 @@ func main() {
 	)
 
 	// Initializing the task preemptor
-	preemptor := preemption.NewPreemptor(
+	preemptor, err := preemption.NewPreemptor(
 		rootScope,
 		cfg.ResManager.PreemptionConfig,
 		task.GetTracker(),
 		tree,
 	)
+	if err != nil {
+		log.WithError(err).
+			WithField("ranker", cfg.ResManager.PreemptionConfig.Ranker).
+			Fatal("Could not create preemptor")
+	}
 
 	// Initializing the host drainer
 	drainer := maintenance.NewDrainer(
@@ -431,3 +523,19 @@ func main() {
 
 	select {}
 }
+
+func getInboundMiddleware(middleware inbound.DispatcherInboundMiddleWare) yarpc.InboundMiddleware {
+	return yarpc.InboundMiddleware{
+		Unary:  middleware,
+		Oneway: middleware,
+		Stream: middleware,
+	}
+}
+
+func getOutboundMiddleware(middleware outbound.DispatcherOutboundMiddleWare) yarpc.OutboundMiddleware {
+	return yarpc.OutboundMiddleware{
+		Unary:  middleware,
+		Oneway: middleware,
+		Stream: middleware,
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atop
+
+import (
+	"fmt"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+)
+
+// NewCronJobSpec creates a new CronJobSpec from the configuration
+// of an Aurora cron job.
+func NewCronJobSpec(
+	j *api.JobConfiguration,
+	respoolID *peloton.ResourcePoolID,
+	c ThermosExecutorConfig,
+) (*cron.CronJobSpec, error) {
+
+	if j.GetCronSchedule() == "" {
+		return nil, fmt.Errorf("cron schedule is not set in job configuration")
+	}
+
+	jobSpec, err := NewJobSpecFromJobConfiguration(j, respoolID, c)
+	if err != nil {
+		return nil, fmt.Errorf("new job spec: %s", err)
+	}
+
+	return &cron.CronJobSpec{
+		Name:            jobSpec.GetName(),
+		Schedule:        j.GetCronSchedule(),
+		CollisionPolicy: NewCronCollisionPolicy(j.GetCronCollisionPolicy()),
+		JobSpec:         jobSpec,
+	}, nil
+}
+
+// NewCronCollisionPolicy creates a new CollisionPolicy.
+func NewCronCollisionPolicy(p api.CronCollisionPolicy) cron.CollisionPolicy {
+	switch p {
+	case api.CronCollisionPolicyCancelNew:
+		return cron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW
+	case api.CronCollisionPolicyRunOverlap:
+		// RUN_OVERLAP is deprecated in Aurora, and treated as CANCEL_NEW.
+		return cron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW
+	default:
+		return cron.CollisionPolicy_COLLISION_POLICY_KILL_EXISTING
+	}
+}
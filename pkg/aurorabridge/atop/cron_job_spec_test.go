@@ -0,0 +1,83 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+	"github.com/uber/peloton/pkg/aurorabridge/fixture"
+	"go.uber.org/thriftrw/ptr"
+)
+
+// Ensures that a CronJobSpec is created from an Aurora cron job configuration.
+func TestNewCronJobSpec(t *testing.T) {
+	k := fixture.AuroraJobKey()
+	respoolID := &peloton.ResourcePoolID{Value: "respool"}
+
+	s, err := NewCronJobSpec(
+		&api.JobConfiguration{
+			Key:                 k,
+			CronSchedule:        ptr.String("0 * * * *"),
+			CronCollisionPolicy: api.CronCollisionPolicyCancelNew.Ptr(),
+			TaskConfig:          &api.TaskConfig{Job: k},
+			InstanceCount:       ptr.Int32(3),
+		},
+		respoolID,
+		ThermosExecutorConfig{},
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, NewJobName(k), s.GetName())
+	assert.Equal(t, "0 * * * *", s.GetSchedule())
+	assert.Equal(t, cron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW, s.GetCollisionPolicy())
+	assert.Equal(t, NewJobName(k), s.GetJobSpec().GetName())
+	assert.Equal(t, uint32(3), s.GetJobSpec().GetInstanceCount())
+	assert.Equal(t, respoolID, s.GetJobSpec().GetRespoolId())
+}
+
+// Ensures that a CronJobSpec cannot be created without a cron schedule.
+func TestNewCronJobSpec_NoSchedule(t *testing.T) {
+	k := fixture.AuroraJobKey()
+
+	_, err := NewCronJobSpec(
+		&api.JobConfiguration{
+			Key:        k,
+			TaskConfig: &api.TaskConfig{Job: k},
+		},
+		&peloton.ResourcePoolID{Value: "respool"},
+		ThermosExecutorConfig{},
+	)
+	assert.Error(t, err)
+}
+
+// Ensures that Aurora cron collision policies are translated, with the
+// deprecated RUN_OVERLAP treated as CANCEL_NEW like in Aurora.
+func TestNewCronCollisionPolicy(t *testing.T) {
+	testCases := []struct {
+		input api.CronCollisionPolicy
+		want  cron.CollisionPolicy
+	}{
+		{api.CronCollisionPolicyKillExisting, cron.CollisionPolicy_COLLISION_POLICY_KILL_EXISTING},
+		{api.CronCollisionPolicyCancelNew, cron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW},
+		{api.CronCollisionPolicyRunOverlap, cron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, NewCronCollisionPolicy(tc.input), tc.input.String())
+	}
+}
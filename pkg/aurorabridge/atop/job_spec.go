@@ -19,6 +19,7 @@ import (
 
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/thrift/aurora/api"
 
 	"github.com/uber/peloton/pkg/aurorabridge/common"
@@ -36,7 +37,45 @@ func NewJobSpecFromJobUpdateRequest(
 		return nil, fmt.Errorf("task config is not set in job update request")
 	}
 
-	p, err := NewPodSpec(r.GetTaskConfig(), c)
+	return newJobSpec(
+		r.GetTaskConfig(),
+		r.GetInstanceCount(),
+		r.GetSettings().GetMaxFailedInstances(),
+		respoolID,
+		c,
+	)
+}
+
+// NewJobSpecFromJobConfiguration creates a new JobSpec from the
+// configuration of a job, such as the template of a cron job.
+func NewJobSpecFromJobConfiguration(
+	j *api.JobConfiguration,
+	respoolID *peloton.ResourcePoolID,
+	c ThermosExecutorConfig,
+) (*stateless.JobSpec, error) {
+
+	if !j.IsSetTaskConfig() {
+		return nil, fmt.Errorf("task config is not set in job configuration")
+	}
+
+	return newJobSpec(
+		j.GetTaskConfig(),
+		j.GetInstanceCount(),
+		0, // Not set in job configuration.
+		respoolID,
+		c,
+	)
+}
+
+func newJobSpec(
+	t *api.TaskConfig,
+	instanceCount int32,
+	maxFailedInstances int32,
+	respoolID *peloton.ResourcePoolID,
+	c ThermosExecutorConfig,
+) (*stateless.JobSpec, error) {
+
+	p, err := NewPodSpec(t, c)
 	if err != nil {
 		return nil, fmt.Errorf("new pod spec: %s", err)
 	}
@@ -44,22 +83,22 @@ func NewJobSpecFromJobUpdateRequest(
 	// build labels for role, environment and job_name, used for task
 	// querying by partial job key (e.g. getTasksWithoutConfigs)
 	l := []*peloton.Label{
-		label.NewAuroraJobKeyRole(r.GetTaskConfig().GetJob().GetRole()),
-		label.NewAuroraJobKeyEnvironment(r.GetTaskConfig().GetJob().GetEnvironment()),
-		label.NewAuroraJobKeyName(r.GetTaskConfig().GetJob().GetName()),
+		label.NewAuroraJobKeyRole(t.GetJob().GetRole()),
+		label.NewAuroraJobKeyEnvironment(t.GetJob().GetEnvironment()),
+		label.NewAuroraJobKeyName(t.GetJob().GetName()),
 		common.BridgeJobLabel,
 	}
 
 	return &stateless.JobSpec{
 		Revision:      nil, // Unused.
-		Name:          NewJobName(r.GetTaskConfig().GetJob()),
-		Owner:         r.GetTaskConfig().GetOwner().GetUser(),
-		OwningTeam:    r.GetTaskConfig().GetOwner().GetUser(),
+		Name:          NewJobName(t.GetJob()),
+		Owner:         t.GetOwner().GetUser(),
+		OwningTeam:    t.GetOwner().GetUser(),
 		LdapGroups:    nil, // Unused.
 		Description:   "",  // Unused.
 		Labels:        l,
-		InstanceCount: uint32(r.GetInstanceCount()),
-		Sla:           newSLASpec(r.GetTaskConfig(), r.GetSettings().GetMaxFailedInstances()),
+		InstanceCount: uint32(instanceCount),
+		Sla:           newSLASpec(t, maxFailedInstances),
 		DefaultSpec:   p,
 		InstanceSpec:  nil, // TODO(codyg): Pinned instance support.
 		RespoolId:     respoolID,
@@ -89,3 +128,27 @@ func newSLASpec(t *api.TaskConfig, maxFailedInstances int32) *stateless.SlaSpec
 		MaximumUnavailableInstances: uint32(maxFailedInstances),
 	}
 }
+
+// NewJobSpecForAddInstances creates the JobSpec used to patch a job in
+// order to add count instances using the config of the given instance.
+// Only the instance count, and the instance specs of the new instances if
+// the given instance has its own spec, are set.
+func NewJobSpecForAddInstances(
+	spec *stateless.JobSpec,
+	instanceID uint32,
+	count uint32,
+) *stateless.JobSpec {
+
+	instanceCount := spec.GetInstanceCount()
+	result := &stateless.JobSpec{
+		InstanceCount: instanceCount + count,
+	}
+
+	if p, ok := spec.GetInstanceSpec()[instanceID]; ok {
+		result.InstanceSpec = make(map[uint32]*pod.PodSpec)
+		for i := instanceCount; i < instanceCount+count; i++ {
+			result.InstanceSpec[i] = p
+		}
+	}
+	return result
+}
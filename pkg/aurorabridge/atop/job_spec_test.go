@@ -0,0 +1,56 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atop
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that the JobSpec for adding instances only grows the instance
+// count when the instance uses the default spec.
+func TestNewJobSpecForAddInstances_DefaultSpec(t *testing.T) {
+	spec := &stateless.JobSpec{
+		InstanceCount: 3,
+		DefaultSpec:   &pod.PodSpec{Revocable: true},
+	}
+
+	s := NewJobSpecForAddInstances(spec, 1, 2)
+	assert.Equal(t, uint32(5), s.GetInstanceCount())
+	assert.Nil(t, s.GetDefaultSpec())
+	assert.Empty(t, s.GetInstanceSpec())
+}
+
+// Ensures that the new instances get the spec of the instance if it has
+// its own spec.
+func TestNewJobSpecForAddInstances_InstanceSpec(t *testing.T) {
+	instanceSpec := &pod.PodSpec{Revocable: true}
+	spec := &stateless.JobSpec{
+		InstanceCount: 3,
+		DefaultSpec:   &pod.PodSpec{},
+		InstanceSpec:  map[uint32]*pod.PodSpec{2: instanceSpec},
+	}
+
+	s := NewJobSpecForAddInstances(spec, 2, 2)
+	assert.Equal(t, uint32(5), s.GetInstanceCount())
+	assert.Equal(t, map[uint32]*pod.PodSpec{
+		3: instanceSpec,
+		4: instanceSpec,
+	}, s.GetInstanceSpec())
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atop
+
+import (
+	"sort"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+)
+
+// NewRestartSpec creates a new RestartSpec which restarts the given
+// instances all at once.
+func NewRestartSpec(instances map[int32]struct{}) *stateless.RestartSpec {
+	return &stateless.RestartSpec{
+		BatchSize: 0, // Restart all instances at once, like Aurora.
+		Ranges:    NewInstanceIDRanges(instances),
+		InPlace:   false,
+	}
+}
+
+// NewInstanceIDRanges converts a set of instance ids into a sorted list of
+// peloton instance id ranges, merging consecutive ids into a single range.
+// Note that the To bound of a peloton range is exclusive.
+func NewInstanceIDRanges(instances map[int32]struct{}) []*pod.InstanceIDRange {
+	var ids []int
+	for i := range instances {
+		ids = append(ids, int(i))
+	}
+	sort.Ints(ids)
+
+	var ranges []*pod.InstanceIDRange
+	for _, id := range ids {
+		if n := len(ranges); n > 0 && ranges[n-1].To == uint32(id) {
+			ranges[n-1].To++
+			continue
+		}
+		ranges = append(ranges, &pod.InstanceIDRange{
+			From: uint32(id),
+			To:   uint32(id) + 1,
+		})
+	}
+	return ranges
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atop
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensures that consecutive instance ids are merged into the same range.
+func TestNewInstanceIDRanges(t *testing.T) {
+	testCases := []struct {
+		name      string
+		instances map[int32]struct{}
+		want      []*pod.InstanceIDRange
+	}{
+		{
+			name:      "empty",
+			instances: nil,
+			want:      nil,
+		},
+		{
+			name:      "single instance",
+			instances: map[int32]struct{}{3: {}},
+			want:      []*pod.InstanceIDRange{{From: 3, To: 4}},
+		},
+		{
+			name: "consecutive and disjoint instances",
+			instances: map[int32]struct{}{
+				0: {}, 1: {}, 2: {}, 5: {}, 7: {}, 8: {},
+			},
+			want: []*pod.InstanceIDRange{
+				{From: 0, To: 3},
+				{From: 5, To: 6},
+				{From: 7, To: 9},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, NewInstanceIDRanges(tc.instances))
+		})
+	}
+}
+
+// Ensures that a RestartSpec restarts all the given instances at once.
+func TestNewRestartSpec(t *testing.T) {
+	s := NewRestartSpec(map[int32]struct{}{1: {}, 2: {}})
+	assert.Equal(t, uint32(0), s.GetBatchSize())
+	assert.False(t, s.GetInPlace())
+	assert.Equal(t, []*pod.InstanceIDRange{{From: 1, To: 3}}, s.GetRanges())
+}
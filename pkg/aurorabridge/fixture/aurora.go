@@ -45,6 +45,18 @@ func AuroraJobUpdateRequest() *api.JobUpdateRequest {
 	}
 }
 
+// AuroraCronJobConfiguration returns a random JobConfiguration of a cron job.
+func AuroraCronJobConfiguration() *api.JobConfiguration {
+	t := AuroraTaskConfig()
+	return &api.JobConfiguration{
+		Key:                 t.GetJob(),
+		CronSchedule:        ptr.String("0 * * * *"),
+		CronCollisionPolicy: api.CronCollisionPolicyCancelNew.Ptr(),
+		TaskConfig:          t,
+		InstanceCount:       ptr.Int32(1),
+	}
+}
+
 // AuroraJobUpdateKey returns a random JobUpdateKey.
 func AuroraJobUpdateKey() *api.JobUpdateKey {
 	return &api.JobUpdateKey{
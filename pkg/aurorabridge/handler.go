@@ -20,6 +20,10 @@ import (
 	"sort"
 
 	"github.com/pborman/uuid"
+	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
@@ -52,6 +56,8 @@ type ServiceHandler struct {
 	metrics       *Metrics
 	jobClient     statelesssvc.JobServiceYARPCClient
 	podClient     podsvc.PodServiceYARPCClient
+	cronClient    cronsvc.CronJobServiceYARPCClient
+	respoolClient respool.ResourceManagerYARPCClient
 	respoolLoader RespoolLoader
 }
 
@@ -61,6 +67,8 @@ func NewServiceHandler(
 	parent tally.Scope,
 	jobClient statelesssvc.JobServiceYARPCClient,
 	podClient podsvc.PodServiceYARPCClient,
+	cronClient cronsvc.CronJobServiceYARPCClient,
+	respoolClient respool.ResourceManagerYARPCClient,
 	respoolLoader RespoolLoader,
 ) (*ServiceHandler, error) {
 
@@ -74,10 +82,80 @@ func NewServiceHandler(
 		metrics:       NewMetrics(parent.SubScope("aurorabridge").SubScope("api")),
 		jobClient:     jobClient,
 		podClient:     podClient,
+		cronClient:    cronClient,
+		respoolClient: respoolClient,
 		respoolLoader: respoolLoader,
 	}, nil
 }
 
+// GetRoleSummary returns a summary of the jobs and cron jobs of each role.
+func (h *ServiceHandler) GetRoleSummary(
+	ctx context.Context,
+) (*api.Response, error) {
+
+	result, err := h.getRoleSummary(ctx)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("GetRoleSummary error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"result": result,
+		}).Debug("GetRoleSummary success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) getRoleSummary(
+	ctx context.Context,
+) (*api.Result, *auroraError) {
+
+	resp, err := h.cronClient.ListCronJobs(ctx, &cronsvc.ListCronJobsRequest{})
+	if err != nil {
+		return nil, auroraErrorf("list cron jobs: %s", err)
+	}
+
+	cronJobNames := make(map[string]struct{})
+	var cronJobKeys []*api.JobKey
+	for _, c := range resp.GetCronJobs() {
+		k, err := ptoa.NewJobKey(c.GetSpec().GetName())
+		if err != nil {
+			// Not created through the bridge.
+			continue
+		}
+		cronJobNames[c.GetSpec().GetName()] = struct{}{}
+		cronJobKeys = append(cronJobKeys, k)
+	}
+
+	summaries, err := h.queryJobSummaries(ctx, "", "", "")
+	if err != nil {
+		return nil, auroraErrorf("query job summaries: %s", err)
+	}
+
+	var jobKeys []*api.JobKey
+	for _, s := range summaries {
+		if _, ok := cronJobNames[s.GetName()]; ok {
+			// The runs of a cron job are only counted as the cron job.
+			continue
+		}
+		k, err := ptoa.NewJobKey(s.GetName())
+		if err != nil {
+			return nil, auroraErrorf("new job key: %s", err)
+		}
+		jobKeys = append(jobKeys, k)
+	}
+
+	return &api.Result{
+		RoleSummaryResult: &api.RoleSummaryResult{
+			Summaries: ptoa.NewRoleSummaries(jobKeys, cronJobKeys),
+		},
+	}, nil
+}
+
 // GetJobSummary returns a summary of jobs, optionally only those owned by a specific role.
 func (h *ServiceHandler) GetJobSummary(
 	ctx context.Context,
@@ -168,7 +246,7 @@ func (h *ServiceHandler) GetTasksWithoutConfigs(
 	query *api.TaskQuery,
 ) (*api.Response, error) {
 
-	result, err := h.getTasksWithoutConfigs(ctx, query)
+	result, err := h.getTasks(ctx, query, false)
 	defer func() {
 		if err != nil {
 			log.WithFields(log.Fields{
@@ -191,17 +269,50 @@ func (h *ServiceHandler) GetTasksWithoutConfigs(
 	return newResponse(result, err), nil
 }
 
+// GetTasksStatus fetches the status of tasks, including the
+// TaskConfig.ExecutorConfig data.
+func (h *ServiceHandler) GetTasksStatus(
+	ctx context.Context,
+	query *api.TaskQuery,
+) (*api.Response, error) {
+
+	result, err := h.getTasks(ctx, query, true)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"query": query,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("GetTasksStatus error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"query": query,
+			},
+			"result": result,
+		}).Debug("GetTasksStatus success")
+	}()
+	return newResponse(result, err), nil
+}
+
 // getScheduledTaskResult represents output
 // value returned on channel generated by
-// getTasksWithoutConfigs and error if occured
+// getTasks and error if occured
 type getScheduledTaskResult struct {
 	task *api.ScheduledTask
 	err  error
 }
 
-func (h *ServiceHandler) getTasksWithoutConfigs(
+// getTasks returns the tasks matching the query. The TaskConfig.ExecutorConfig
+// of the tasks is only set if includeExecutorConfig is true.
+func (h *ServiceHandler) getTasks(
 	ctx context.Context,
 	query *api.TaskQuery,
+	includeExecutorConfig bool,
 ) (*api.Result, *auroraError) {
 
 	var podStates []pod.PodState
@@ -246,9 +357,10 @@ func (h *ServiceHandler) getTasksWithoutConfigs(
 			jobSummary,
 			pods,
 			&taskFilter{statuses: query.GetStatuses()},
+			includeExecutorConfig,
 		)
 		if err != nil {
-			return nil, auroraErrorf("get tasks without configs: %s", err)
+			return nil, auroraErrorf("get scheduled tasks: %s", err)
 		}
 
 		tasks = append(tasks, ts...)
@@ -276,12 +388,14 @@ func (f *taskFilter) include(t *api.ScheduledTask) bool {
 }
 
 // getScheduledTasks generates a list of Aurora ScheduledTask in a worker
-// pool.
+// pool. The executor config is set in the task config of the current run
+// of the pods if includeExecutorConfig is true.
 func (h *ServiceHandler) getScheduledTasks(
 	ctx context.Context,
 	jobSummary *stateless.JobSummary,
 	podInfos []*pod.PodInfo,
 	filter *taskFilter,
+	includeExecutorConfig bool,
 ) ([]*api.ScheduledTask, error) {
 
 	var inputs []interface{}
@@ -342,6 +456,15 @@ func (h *ServiceHandler) getScheduledTasks(
 					"new scheduled task: %s", err)
 			}
 
+			if includeExecutorConfig && t.GetAssignedTask().GetTask() != nil {
+				c, err := ptoa.NewExecutorConfig(podInfo.GetSpec())
+				if err != nil {
+					return nil, fmt.Errorf(
+						"new executor config: %s", err)
+				}
+				t.AssignedTask.Task.ExecutorConfig = c
+			}
+
 			if filter.include(t) {
 				ts = append(ts, t)
 			}
@@ -365,6 +488,87 @@ func (h *ServiceHandler) getScheduledTasks(
 	return tasks, nil
 }
 
+// GetPendingReason returns the reasons why the pending tasks matched by the
+// query are not scheduled yet.
+func (h *ServiceHandler) GetPendingReason(
+	ctx context.Context,
+	query *api.TaskQuery,
+) (*api.Response, error) {
+
+	result, err := h.getPendingReason(ctx, query)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"query": query,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("GetPendingReason error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"query": query,
+			},
+			"result": result,
+		}).Debug("GetPendingReason success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) getPendingReason(
+	ctx context.Context,
+	query *api.TaskQuery,
+) (*api.Result, *auroraError) {
+
+	// Same as Aurora, only the PENDING tasks are considered.
+	if len(query.GetStatuses()) > 0 || len(query.GetSlaveHosts()) > 0 {
+		return nil, auroraErrorf("statuses or slave hosts are not supported in query").
+			code(api.ResponseCodeInvalidRequest)
+	}
+
+	jobIDs, err := h.getJobIDsFromTaskQuery(ctx, query)
+	if err != nil {
+		return nil, auroraErrorf("get job ids from task query: %s", err)
+	}
+
+	reasons := []*api.PendingReason{}
+	for _, jobID := range jobIDs {
+		jobSummary, err := h.getJobInfoSummary(ctx, jobID)
+		if err != nil {
+			if yarpcerrors.IsNotFound(err) {
+				continue
+			}
+			return nil, auroraErrorf("get job info for job id %q: %s",
+				jobID.GetValue(), err)
+		}
+
+		pods, err := h.queryPods(
+			ctx,
+			jobID,
+			jobSummary.GetInstanceCount(),
+		)
+		if err != nil {
+			return nil, auroraErrorf(
+				"query pods for job id %q: %s", jobID.GetValue(), err)
+		}
+
+		rs, err := ptoa.NewPendingReasons(pods)
+		if err != nil {
+			return nil, auroraErrorf("new pending reasons: %s", err)
+		}
+		reasons = append(reasons, rs...)
+	}
+
+	return &api.Result{
+		GetPendingReasonResult: &api.GetPendingReasonResult{
+			Reasons: reasons,
+		},
+	}, nil
+}
+
 // GetConfigSummary fetches the configuration summary of active tasks for the specified job.
 func (h *ServiceHandler) GetConfigSummary(
 	ctx context.Context,
@@ -757,6 +961,62 @@ func (h *ServiceHandler) GetTierConfigs(
 	}, nil), nil
 }
 
+// GetQuota returns the quota and the consumption of the resource pool of
+// the bridge. All the roles share the same resource pool, so ownerRole is
+// ignored.
+func (h *ServiceHandler) GetQuota(
+	ctx context.Context,
+	ownerRole *string,
+) (*api.Response, error) {
+
+	result, err := h.getQuota(ctx)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"ownerRole": ownerRole,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("GetQuota error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"ownerRole": ownerRole,
+			},
+			"result": result,
+		}).Debug("GetQuota success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) getQuota(
+	ctx context.Context,
+) (*api.Result, *auroraError) {
+
+	respoolID, err := h.respoolLoader.Load(ctx)
+	if err != nil {
+		return nil, auroraErrorf("load respool: %s", err)
+	}
+
+	req := &respool.GetRequest{
+		Id: &v0peloton.ResourcePoolID{Value: respoolID.GetValue()},
+	}
+	resp, err := h.respoolClient.GetResourcePool(ctx, req)
+	if err != nil {
+		return nil, auroraErrorf("get resource pool: %s", err)
+	}
+	if rerr := resp.GetError(); rerr != nil {
+		return nil, auroraErrorf("get resource pool: %s", rerr.String())
+	}
+
+	return &api.Result{
+		GetQuotaResult: ptoa.NewGetQuotaResult(resp.GetPoolinfo()),
+	}, nil
+}
+
 // KillTasks initiates a kill on tasks.
 func (h *ServiceHandler) KillTasks(
 	ctx context.Context,
@@ -893,6 +1153,183 @@ func instanceBounds(instances map[int32]struct{}) (low, high int32) {
 	return low, high
 }
 
+// AddInstances adds new instances with the config of the given instance.
+func (h *ServiceHandler) AddInstances(
+	ctx context.Context,
+	key *api.InstanceKey,
+	count *int32,
+) (*api.Response, error) {
+
+	result, err := h.addInstances(ctx, key, count)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"key":   key,
+					"count": count,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("AddInstances error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"key":   key,
+				"count": count,
+			},
+			"result": result,
+		}).Debug("AddInstances success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) addInstances(
+	ctx context.Context,
+	key *api.InstanceKey,
+	count *int32,
+) (*api.Result, *auroraError) {
+
+	if count == nil || *count <= 0 {
+		return nil, auroraErrorf("instance count must be positive").
+			code(api.ResponseCodeInvalidRequest)
+	}
+
+	id, err := h.getJobID(ctx, key.GetJobKey())
+	if err != nil {
+		aerr := auroraErrorf("get job id: %s", err)
+		if yarpcerrors.IsNotFound(err) {
+			aerr.code(api.ResponseCodeInvalidRequest)
+		}
+		return nil, aerr
+	}
+	jobInfo, err := h.getJobInfo(ctx, id)
+	if err != nil {
+		return nil, auroraErrorf("get job info: %s", err)
+	}
+
+	instanceID := key.GetInstanceId()
+	if instanceID < 0 ||
+		uint32(instanceID) >= jobInfo.GetSpec().GetInstanceCount() {
+		return nil, auroraErrorf("invalid instance id %d", instanceID).
+			code(api.ResponseCodeInvalidRequest)
+	}
+
+	d := &opaquedata.Data{UpdateID: uuid.New()}
+	od, err := d.Serialize()
+	if err != nil {
+		return nil, auroraErrorf("serialize opaque data: %s", err)
+	}
+
+	req := &statelesssvc.PatchJobRequest{
+		JobId:   id,
+		Version: jobInfo.GetStatus().GetVersion(),
+		Spec: atop.NewJobSpecForAddInstances(
+			jobInfo.GetSpec(),
+			uint32(instanceID),
+			uint32(*count),
+		),
+		UpdateSpec: &stateless.UpdateSpec{StartPods: true},
+		OpaqueData: od,
+	}
+	if _, err := h.jobClient.PatchJob(ctx, req); err != nil {
+		aerr := auroraErrorf("patch job: %s", err)
+		if yarpcerrors.IsAborted(err) {
+			// Update conflict.
+			aerr.code(api.ResponseCodeInvalidRequest)
+		}
+		return nil, aerr
+	}
+	return dummyResult(), nil
+}
+
+// RestartShards restarts the given instances of a job.
+func (h *ServiceHandler) RestartShards(
+	ctx context.Context,
+	job *api.JobKey,
+	shardIds map[int32]struct{},
+) (*api.Response, error) {
+
+	result, err := h.restartShards(ctx, job, shardIds)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"job":      job,
+					"shardIds": shardIds,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("RestartShards error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"job":      job,
+				"shardIds": shardIds,
+			},
+			"result": result,
+		}).Debug("RestartShards success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) restartShards(
+	ctx context.Context,
+	job *api.JobKey,
+	shardIds map[int32]struct{},
+) (*api.Result, *auroraError) {
+
+	if len(shardIds) == 0 {
+		return nil, auroraErrorf("no shards to restart").
+			code(api.ResponseCodeInvalidRequest)
+	}
+
+	id, err := h.getJobID(ctx, job)
+	if err != nil {
+		aerr := auroraErrorf("get job id: %s", err)
+		if yarpcerrors.IsNotFound(err) {
+			aerr.code(api.ResponseCodeInvalidRequest)
+		}
+		return nil, aerr
+	}
+	summary, err := h.getJobInfoSummary(ctx, id)
+	if err != nil {
+		return nil, auroraErrorf("get job info summary: %s", err)
+	}
+
+	low, high := instanceBounds(shardIds)
+	if low < 0 || uint32(high) >= summary.GetInstanceCount() {
+		return nil, auroraErrorf(
+			"invalid shards: job has %d instances", summary.GetInstanceCount()).
+			code(api.ResponseCodeInvalidRequest)
+	}
+
+	d := &opaquedata.Data{UpdateID: uuid.New()}
+	od, err := d.Serialize()
+	if err != nil {
+		return nil, auroraErrorf("serialize opaque data: %s", err)
+	}
+
+	req := &statelesssvc.RestartJobRequest{
+		JobId:       id,
+		Version:     summary.GetStatus().GetVersion(),
+		RestartSpec: atop.NewRestartSpec(shardIds),
+		OpaqueData:  od,
+	}
+	if _, err := h.jobClient.RestartJob(ctx, req); err != nil {
+		aerr := auroraErrorf("restart job: %s", err)
+		if yarpcerrors.IsAborted(err) {
+			// Update conflict.
+			aerr.code(api.ResponseCodeInvalidRequest)
+		}
+		return nil, aerr
+	}
+	return dummyResult(), nil
+}
+
 // StartJobUpdate starts update of the existing service job.
 func (h *ServiceHandler) StartJobUpdate(
 	ctx context.Context,
@@ -1427,6 +1864,238 @@ func (h *ServiceHandler) pulseJobUpdate(
 	}, nil
 }
 
+// ScheduleCronJob creates a cron job, or replaces the template of the
+// cron job if it already exists.
+func (h *ServiceHandler) ScheduleCronJob(
+	ctx context.Context,
+	description *api.JobConfiguration,
+) (*api.Response, error) {
+
+	result, err := h.scheduleCronJob(ctx, description)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"description": description,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("ScheduleCronJob error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"description": description,
+			},
+			"result": result,
+		}).Debug("ScheduleCronJob success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) scheduleCronJob(
+	ctx context.Context,
+	description *api.JobConfiguration,
+) (*api.Result, *auroraError) {
+
+	spec, aerr := h.newCronJobSpec(ctx, description)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	_, err := h.cronClient.CreateCronJob(
+		ctx,
+		&cronsvc.CreateCronJobRequest{Spec: spec},
+	)
+	if err == nil {
+		return dummyResult(), nil
+	}
+	if !yarpcerrors.IsAlreadyExists(err) {
+		return nil, newCronJobError("create cron job", err)
+	}
+
+	// Cron job already exists. Replace its template.
+	if _, err := h.cronClient.ReplaceCronJob(
+		ctx,
+		&cronsvc.ReplaceCronJobRequest{Spec: spec},
+	); err != nil {
+		return nil, newCronJobError("replace cron job", err)
+	}
+	return dummyResult(), nil
+}
+
+// DescheduleCronJob removes a cron job. The jobs launched by the
+// cron job are not affected.
+func (h *ServiceHandler) DescheduleCronJob(
+	ctx context.Context,
+	job *api.JobKey,
+) (*api.Response, error) {
+
+	result, err := h.descheduleCronJob(ctx, job)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"job": job,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("DescheduleCronJob error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"job": job,
+			},
+			"result": result,
+		}).Debug("DescheduleCronJob success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) descheduleCronJob(
+	ctx context.Context,
+	job *api.JobKey,
+) (*api.Result, *auroraError) {
+
+	req := &cronsvc.DeleteCronJobRequest{Name: atop.NewJobName(job)}
+	if _, err := h.cronClient.DeleteCronJob(ctx, req); err != nil {
+		// Like Aurora, descheduling an unknown cron job is a no-op.
+		if yarpcerrors.IsNotFound(err) {
+			return dummyResult(), nil
+		}
+		return nil, newCronJobError("delete cron job", err)
+	}
+	return dummyResult(), nil
+}
+
+// StartCronJob starts a run of a cron job immediately, irrespective
+// of its cron schedule.
+func (h *ServiceHandler) StartCronJob(
+	ctx context.Context,
+	job *api.JobKey,
+) (*api.Response, error) {
+
+	result, err := h.startCronJob(ctx, job)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"job": job,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("StartCronJob error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"job": job,
+			},
+			"result": result,
+		}).Debug("StartCronJob success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) startCronJob(
+	ctx context.Context,
+	job *api.JobKey,
+) (*api.Result, *auroraError) {
+
+	req := &cronsvc.StartCronJobRequest{Name: atop.NewJobName(job)}
+	if _, err := h.cronClient.StartCronJob(ctx, req); err != nil {
+		return nil, newCronJobError("start cron job", err)
+	}
+	return dummyResult(), nil
+}
+
+// ReplaceCronTemplate replaces the template of an existing cron job.
+// The new template applies to the runs launched after the replacement.
+func (h *ServiceHandler) ReplaceCronTemplate(
+	ctx context.Context,
+	config *api.JobConfiguration,
+) (*api.Response, error) {
+
+	result, err := h.replaceCronTemplate(ctx, config)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"config": config,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("ReplaceCronTemplate error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"config": config,
+			},
+			"result": result,
+		}).Debug("ReplaceCronTemplate success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) replaceCronTemplate(
+	ctx context.Context,
+	config *api.JobConfiguration,
+) (*api.Result, *auroraError) {
+
+	spec, aerr := h.newCronJobSpec(ctx, config)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	if _, err := h.cronClient.ReplaceCronJob(
+		ctx,
+		&cronsvc.ReplaceCronJobRequest{Spec: spec},
+	); err != nil {
+		return nil, newCronJobError("replace cron job", err)
+	}
+	return dummyResult(), nil
+}
+
+// newCronJobSpec creates the spec of a cron job from its Aurora configuration.
+func (h *ServiceHandler) newCronJobSpec(
+	ctx context.Context,
+	config *api.JobConfiguration,
+) (*cron.CronJobSpec, *auroraError) {
+
+	if config.GetCronSchedule() == "" {
+		return nil, auroraErrorf("cron schedule is not set").
+			code(api.ResponseCodeInvalidRequest)
+	}
+
+	respoolID, err := h.respoolLoader.Load(ctx)
+	if err != nil {
+		return nil, auroraErrorf("load respool: %s", err)
+	}
+
+	spec, err := atop.NewCronJobSpec(config, respoolID, h.config.ThermosExecutor)
+	if err != nil {
+		return nil, auroraErrorf("new cron job spec: %s", err)
+	}
+	return spec, nil
+}
+
+// newCronJobError converts an error of the cron job service into
+// an Aurora error, where the errors caused by the request are invalid
+// requests.
+func newCronJobError(action string, err error) *auroraError {
+	aerr := auroraErrorf("%s: %s", action, err)
+	if yarpcerrors.IsNotFound(err) || yarpcerrors.IsInvalidArgument(err) {
+		aerr.code(api.ResponseCodeInvalidRequest)
+	}
+	return aerr
+}
+
 // queryJobUpdates is an awkward helper which returns JobUpdateDetails which
 // will include instance events if flag is set.
 func (h *ServiceHandler) queryJobUpdates(
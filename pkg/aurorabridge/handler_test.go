@@ -22,6 +22,12 @@ import (
 	"testing"
 
 	"github.com/pborman/uuid"
+	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
+	cronmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc/mocks"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	jobmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc/mocks"
@@ -56,6 +62,8 @@ type ServiceHandlerTestSuite struct {
 	ctrl          *gomock.Controller
 	jobClient     *jobmocks.MockJobServiceYARPCClient
 	podClient     *podmocks.MockPodServiceYARPCClient
+	cronClient    *cronmocks.MockCronJobServiceYARPCClient
+	respoolClient *respoolmocks.MockResourceManagerYARPCClient
 	respoolLoader *aurorabridgemocks.MockRespoolLoader
 
 	config        ServiceHandlerConfig
@@ -70,6 +78,8 @@ func (suite *ServiceHandlerTestSuite) SetupTest() {
 	suite.ctrl = gomock.NewController(suite.T())
 	suite.jobClient = jobmocks.NewMockJobServiceYARPCClient(suite.ctrl)
 	suite.podClient = podmocks.NewMockPodServiceYARPCClient(suite.ctrl)
+	suite.cronClient = cronmocks.NewMockCronJobServiceYARPCClient(suite.ctrl)
+	suite.respoolClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
 	suite.respoolLoader = aurorabridgemocks.NewMockRespoolLoader(suite.ctrl)
 
 	suite.config = ServiceHandlerConfig{
@@ -86,6 +96,8 @@ func (suite *ServiceHandlerTestSuite) SetupTest() {
 		tally.NoopScope,
 		suite.jobClient,
 		suite.podClient,
+		suite.cronClient,
+		suite.respoolClient,
 		suite.respoolLoader,
 	)
 	suite.NoError(err)
@@ -382,6 +394,114 @@ func (suite *ServiceHandlerTestSuite) TestGetTierConfigs() {
 	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
 }
 
+// Ensures that GetRoleSummary counts the jobs and cron jobs of each role,
+// and does not count the runs of the cron jobs as jobs.
+func (suite *ServiceHandlerTestSuite) TestGetRoleSummary() {
+	jobKey := fixture.AuroraJobKey()
+	cronJobKey := fixture.AuroraJobKey()
+
+	suite.cronClient.EXPECT().
+		ListCronJobs(suite.ctx, &cronsvc.ListCronJobsRequest{}).
+		Return(&cronsvc.ListCronJobsResponse{
+			CronJobs: []*cron.CronJobInfo{
+				{Spec: &cron.CronJobSpec{Name: atop.NewJobName(cronJobKey)}},
+			},
+		}, nil)
+
+	ql := []*peloton.Label{common.BridgeJobLabel}
+	summaries := []*stateless.JobSummary{
+		{
+			JobId:  fixture.PelotonJobID(),
+			Name:   atop.NewJobName(jobKey),
+			Labels: ql,
+		},
+		{
+			JobId:  fixture.PelotonJobID(),
+			Name:   atop.NewJobName(cronJobKey),
+			Labels: ql,
+		},
+	}
+	suite.jobClient.EXPECT().
+		QueryJobs(suite.ctx, gomock.Any()).
+		Return(&statelesssvc.QueryJobsResponse{Records: summaries}, nil)
+
+	resp, err := suite.handler.GetRoleSummary(suite.ctx)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	counts := make(map[string][2]int32)
+	for _, s := range resp.GetResult().GetRoleSummaryResult().GetSummaries() {
+		counts[s.GetRole()] = [2]int32{s.GetJobCount(), s.GetCronJobCount()}
+	}
+	suite.Equal(map[string][2]int32{
+		jobKey.GetRole():     {1, 0},
+		cronJobKey.GetRole(): {0, 1},
+	}, counts)
+}
+
+// Ensures that GetRoleSummary fails if the cron jobs cannot be listed.
+func (suite *ServiceHandlerTestSuite) TestGetRoleSummary_ListCronJobsError() {
+	suite.cronClient.EXPECT().
+		ListCronJobs(suite.ctx, &cronsvc.ListCronJobsRequest{}).
+		Return(nil, errors.New("some error"))
+
+	resp, err := suite.handler.GetRoleSummary(suite.ctx)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeError, resp.GetResponseCode())
+}
+
+// Ensures that GetQuota returns the usage of the resource pool of the bridge.
+func (suite *ServiceHandlerTestSuite) TestGetQuota() {
+	respoolID := fixture.PelotonResourcePoolID()
+
+	suite.respoolLoader.EXPECT().Load(suite.ctx).Return(respoolID, nil)
+	suite.respoolClient.EXPECT().
+		GetResourcePool(suite.ctx, &respool.GetRequest{
+			Id: &v0peloton.ResourcePoolID{Value: respoolID.GetValue()},
+		}).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Config: &respool.ResourcePoolConfig{
+					Resources: []*respool.ResourceConfig{
+						{Kind: "cpu", Reservation: 10},
+					},
+				},
+				Usages: []*respool.ResourceUsage{
+					{Kind: "cpu", Allocation: 4, Demand: 2, Slack: 1},
+				},
+			},
+		}, nil)
+
+	resp, err := suite.handler.GetQuota(suite.ctx, ptr.String("role"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	result := resp.GetResult().GetGetQuotaResult()
+	suite.Equal(10.0, result.GetQuota().GetNumCpus())
+	suite.Equal(6.0, result.GetProdSharedConsumption().GetNumCpus())
+	suite.Equal(1.0, result.GetNonProdSharedConsumption().GetNumCpus())
+}
+
+// Ensures that GetQuota fails if the resource pool cannot be read.
+func (suite *ServiceHandlerTestSuite) TestGetQuota_GetResourcePoolError() {
+	respoolID := fixture.PelotonResourcePoolID()
+
+	suite.respoolLoader.EXPECT().Load(suite.ctx).Return(respoolID, nil)
+	suite.respoolClient.EXPECT().
+		GetResourcePool(suite.ctx, gomock.Any()).
+		Return(&respool.GetResponse{
+			Error: &respool.GetResponse_Error{
+				NotFound: &respool.ResourcePoolNotFound{
+					Id: &v0peloton.ResourcePoolID{Value: respoolID.GetValue()},
+				},
+			},
+		}, nil)
+
+	resp, err := suite.handler.GetQuota(suite.ctx, ptr.String("role"))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeError, resp.GetResponseCode())
+}
+
 // Ensures StartJobUpdate creates jobs which don't exist.
 func (suite *ServiceHandlerTestSuite) TestStartJobUpdate_NewJobSuccess() {
 	respoolID := fixture.PelotonResourcePoolID()
@@ -1408,6 +1528,143 @@ func (suite *ServiceHandlerTestSuite) TestGetTasksWithoutConfigs_QueryPreviousRu
 	}
 }
 
+// Ensures that GetTasksStatus sets the executor config of the current run
+// of the pods, which GetTasksWithoutConfigs omits.
+func (suite *ServiceHandlerTestSuite) TestGetTasksStatus_ExecutorConfig() {
+	query := fixture.AuroraTaskQuery()
+	jobKey := query.GetJobKeys()[0]
+	jobID := fixture.PelotonJobID()
+	executorConfig := &api.ExecutorConfig{
+		Name: ptr.String("AuroraExecutor"),
+		Data: ptr.String("{\n\"role\": \"role\"\n}"),
+	}
+
+	podSpec, err := atop.NewPodSpec(
+		&api.TaskConfig{
+			Job:            jobKey,
+			ExecutorConfig: executorConfig,
+		},
+		suite.config.ThermosExecutor,
+	)
+	suite.NoError(err)
+	podName := &peloton.PodName{
+		Value: util.CreatePelotonTaskID(jobID.GetValue(), 0),
+	}
+	podSpec.PodName = podName
+	podID := &peloton.PodID{Value: podName.GetValue() + "-1"}
+
+	for _, withConfigs := range []bool{true, false} {
+		suite.expectGetJobSummary(jobKey, jobID, 1)
+
+		suite.jobClient.EXPECT().
+			QueryPods(suite.ctx, &statelesssvc.QueryPodsRequest{
+				JobId: jobID,
+				Spec: &pod.QuerySpec{
+					Pagination: &pbquery.PaginationSpec{Limit: 1},
+				},
+				Pagination: &pbquery.PaginationSpec{Limit: 1},
+			}).
+			Return(&statelesssvc.QueryPodsResponse{
+				Pods: []*pod.PodInfo{{
+					Spec: podSpec,
+					Status: &pod.PodStatus{
+						PodId: podID,
+						State: pod.PodState_POD_STATE_RUNNING,
+					},
+				}},
+			}, nil)
+
+		suite.podClient.EXPECT().
+			GetPodEvents(gomock.Any(), &podsvc.GetPodEventsRequest{
+				PodName: podName,
+			}).
+			Return(&podsvc.GetPodEventsResponse{
+				Events: []*pod.PodEvent{{
+					PodId:       podID,
+					Timestamp:   "2019-01-03T22:14:58Z",
+					ActualState: pod.PodState_POD_STATE_RUNNING.String(),
+					Hostname:    "peloton-host-0",
+				}},
+			}, nil)
+
+		var resp *api.Response
+		if withConfigs {
+			resp, err = suite.handler.GetTasksStatus(suite.ctx, query)
+		} else {
+			resp, err = suite.handler.GetTasksWithoutConfigs(suite.ctx, query)
+		}
+		suite.NoError(err)
+		suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+		tasks := resp.GetResult().GetScheduleStatusResult().GetTasks()
+		suite.Len(tasks, 1)
+		if withConfigs {
+			suite.Equal(executorConfig,
+				tasks[0].GetAssignedTask().GetTask().GetExecutorConfig())
+		} else {
+			suite.Nil(tasks[0].GetAssignedTask().GetTask().GetExecutorConfig())
+		}
+	}
+}
+
+// Ensures that GetPendingReason returns the pending reasons of the pending
+// pods of the queried jobs.
+func (suite *ServiceHandlerTestSuite) TestGetPendingReason() {
+	query := fixture.AuroraTaskQuery()
+	jobKey := query.GetJobKeys()[0]
+	jobID := fixture.PelotonJobID()
+
+	suite.expectGetJobSummary(jobKey, jobID, 2)
+
+	suite.jobClient.EXPECT().
+		QueryPods(suite.ctx, &statelesssvc.QueryPodsRequest{
+			JobId: jobID,
+			Spec: &pod.QuerySpec{
+				Pagination: &pbquery.PaginationSpec{Limit: 2},
+			},
+			Pagination: &pbquery.PaginationSpec{Limit: 2},
+		}).
+		Return(&statelesssvc.QueryPodsResponse{
+			Pods: []*pod.PodInfo{
+				{
+					Status: &pod.PodStatus{
+						PodId:         &peloton.PodID{Value: "pod-0-1"},
+						State:         pod.PodState_POD_STATE_PENDING,
+						PendingReason: "entitlement exhausted in resource pool /pool",
+					},
+				},
+				{
+					Status: &pod.PodStatus{
+						PodId: &peloton.PodID{Value: "pod-1-1"},
+						State: pod.PodState_POD_STATE_RUNNING,
+					},
+				},
+			},
+		}, nil)
+
+	resp, err := suite.handler.GetPendingReason(suite.ctx, query)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+	suite.Equal([]*api.PendingReason{
+		{
+			TaskId: ptr.String("pod-0-1"),
+			Reason: ptr.String("entitlement exhausted in resource pool /pool"),
+		},
+	}, resp.GetResult().GetGetPendingReasonResult().GetReasons())
+}
+
+// Ensures that GetPendingReason rejects queries with statuses.
+func (suite *ServiceHandlerTestSuite) TestGetPendingReason_InvalidQuery() {
+	query := fixture.AuroraTaskQuery()
+	query.Statuses = map[api.ScheduleStatus]struct{}{
+		api.ScheduleStatusRunning: {},
+	}
+
+	resp, err := suite.handler.GetPendingReason(suite.ctx, query)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
 // Ensures that KillTasks maps to StopPods correctly.
 func (suite *ServiceHandlerTestSuite) TestKillTasks_Success() {
 	k := fixture.AuroraJobKey()
@@ -1587,6 +1844,189 @@ func (suite *ServiceHandlerTestSuite) TestKillTasks_StopAll() {
 	}
 }
 
+// Ensures that AddInstances patches the job to grow its instance count,
+// copying the spec of the given instance if it has one.
+func (suite *ServiceHandlerTestSuite) TestAddInstances_Success() {
+	k := fixture.AuroraJobKey()
+	id := fixture.PelotonJobID()
+	v := fixture.PelotonEntityVersion()
+	instanceSpec := &pod.PodSpec{Revocable: true}
+
+	suite.expectGetJobIDFromJobName(k, id)
+
+	suite.jobClient.EXPECT().
+		GetJob(suite.ctx, &statelesssvc.GetJobRequest{
+			JobId:       id,
+			SummaryOnly: false,
+		}).
+		Return(&statelesssvc.GetJobResponse{
+			JobInfo: &stateless.JobInfo{
+				Spec: &stateless.JobSpec{
+					InstanceCount: 2,
+					DefaultSpec:   &pod.PodSpec{},
+					InstanceSpec:  map[uint32]*pod.PodSpec{1: instanceSpec},
+				},
+				Status: &stateless.JobStatus{
+					Version: v,
+				},
+			},
+		}, nil)
+
+	suite.jobClient.EXPECT().
+		PatchJob(suite.ctx, gomock.Any()).
+		Do(func(_ context.Context, req *statelesssvc.PatchJobRequest) {
+			suite.Equal(id, req.GetJobId())
+			suite.Equal(v, req.GetVersion())
+			suite.Equal(uint32(5), req.GetSpec().GetInstanceCount())
+			suite.Equal(map[uint32]*pod.PodSpec{
+				2: instanceSpec,
+				3: instanceSpec,
+				4: instanceSpec,
+			}, req.GetSpec().GetInstanceSpec())
+			suite.True(req.GetUpdateSpec().GetStartPods())
+
+			d, err := opaquedata.Deserialize(req.GetOpaqueData())
+			suite.NoError(err)
+			suite.NotEmpty(d.UpdateID)
+		}).
+		Return(&statelesssvc.PatchJobResponse{}, nil)
+
+	resp, err := suite.handler.AddInstances(
+		suite.ctx,
+		&api.InstanceKey{JobKey: k, InstanceId: ptr.Int32(1)},
+		ptr.Int32(3))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures that AddInstances returns an INVALID_REQUEST error for an invalid
+// instance count or instance id.
+func (suite *ServiceHandlerTestSuite) TestAddInstances_InvalidRequest() {
+	k := fixture.AuroraJobKey()
+	id := fixture.PelotonJobID()
+
+	resp, err := suite.handler.AddInstances(
+		suite.ctx,
+		&api.InstanceKey{JobKey: k, InstanceId: ptr.Int32(0)},
+		ptr.Int32(0))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+
+	suite.expectGetJob(k, id, 2)
+
+	resp, err = suite.handler.AddInstances(
+		suite.ctx,
+		&api.InstanceKey{JobKey: k, InstanceId: ptr.Int32(2)},
+		ptr.Int32(1))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures that AddInstances returns an INVALID_REQUEST error if the job
+// is being updated concurrently.
+func (suite *ServiceHandlerTestSuite) TestAddInstances_PatchJobConflict() {
+	k := fixture.AuroraJobKey()
+	id := fixture.PelotonJobID()
+
+	suite.expectGetJob(k, id, 2)
+
+	suite.jobClient.EXPECT().
+		PatchJob(suite.ctx, gomock.Any()).
+		Return(nil, yarpcerrors.AbortedErrorf("version mismatch"))
+
+	resp, err := suite.handler.AddInstances(
+		suite.ctx,
+		&api.InstanceKey{JobKey: k, InstanceId: ptr.Int32(0)},
+		ptr.Int32(1))
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures that RestartShards restarts the given instances using RestartJob.
+func (suite *ServiceHandlerTestSuite) TestRestartShards_Success() {
+	k := fixture.AuroraJobKey()
+	id := fixture.PelotonJobID()
+	v := fixture.PelotonEntityVersion()
+
+	suite.expectGetJobIDFromJobName(k, id)
+
+	suite.jobClient.EXPECT().
+		GetJob(suite.ctx, &statelesssvc.GetJobRequest{
+			JobId:       id,
+			SummaryOnly: true,
+		}).
+		Return(&statelesssvc.GetJobResponse{
+			Summary: &stateless.JobSummary{
+				InstanceCount: 10,
+				Status: &stateless.JobStatus{
+					Version: v,
+				},
+			},
+		}, nil)
+
+	suite.jobClient.EXPECT().
+		RestartJob(suite.ctx, gomock.Any()).
+		Do(func(_ context.Context, req *statelesssvc.RestartJobRequest) {
+			suite.Equal(id, req.GetJobId())
+			suite.Equal(v, req.GetVersion())
+			suite.Equal([]*pod.InstanceIDRange{
+				{From: 1, To: 3},
+				{From: 7, To: 8},
+			}, req.GetRestartSpec().GetRanges())
+
+			d, err := opaquedata.Deserialize(req.GetOpaqueData())
+			suite.NoError(err)
+			suite.NotEmpty(d.UpdateID)
+		}).
+		Return(&statelesssvc.RestartJobResponse{}, nil)
+
+	resp, err := suite.handler.RestartShards(
+		suite.ctx,
+		k,
+		map[int32]struct{}{1: {}, 2: {}, 7: {}})
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures that RestartShards returns an INVALID_REQUEST error if no shards,
+// or shards out of the range of the job instances, are given.
+func (suite *ServiceHandlerTestSuite) TestRestartShards_InvalidShards() {
+	k := fixture.AuroraJobKey()
+	id := fixture.PelotonJobID()
+
+	resp, err := suite.handler.RestartShards(suite.ctx, k, nil)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+
+	suite.expectGetJobSummary(k, id, 3)
+
+	resp, err = suite.handler.RestartShards(
+		suite.ctx,
+		k,
+		map[int32]struct{}{1: {}, 3: {}})
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures that RestartShards returns an error if RestartJob fails.
+func (suite *ServiceHandlerTestSuite) TestRestartShards_RestartJobError() {
+	k := fixture.AuroraJobKey()
+	id := fixture.PelotonJobID()
+
+	suite.expectGetJobSummary(k, id, 3)
+
+	suite.jobClient.EXPECT().
+		RestartJob(suite.ctx, gomock.Any()).
+		Return(nil, errors.New("some error"))
+
+	resp, err := suite.handler.RestartShards(
+		suite.ctx,
+		k,
+		map[int32]struct{}{0: {}})
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeError, resp.GetResponseCode())
+}
+
 // Ensures that RollbackJobUpdate calls ReplaceJob using the previous JobSpec.
 func (suite *ServiceHandlerTestSuite) TestRollbackJobUpdate_Success() {
 	k := fixture.AuroraJobUpdateKey()
@@ -2028,3 +2468,173 @@ func (suite *ServiceHandlerTestSuite) TestGetJobUpdateDetails_UpdateStatusFilter
 		api.JobUpdateStatusRolledForward,
 		result[0].GetUpdate().GetSummary().GetState().GetStatus())
 }
+
+// Ensures that ScheduleCronJob creates a cron job if it does not exist.
+func (suite *ServiceHandlerTestSuite) TestScheduleCronJob_Create() {
+	respoolID := fixture.PelotonResourcePoolID()
+	config := fixture.AuroraCronJobConfiguration()
+	spec, err := atop.NewCronJobSpec(
+		config,
+		respoolID,
+		suite.config.ThermosExecutor,
+	)
+	suite.NoError(err)
+
+	suite.respoolLoader.EXPECT().Load(suite.ctx).Return(respoolID, nil)
+
+	suite.cronClient.EXPECT().
+		CreateCronJob(suite.ctx, &cronsvc.CreateCronJobRequest{Spec: spec}).
+		Return(&cronsvc.CreateCronJobResponse{}, nil)
+
+	resp, err := suite.handler.ScheduleCronJob(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures that ScheduleCronJob replaces the template of an existing cron job.
+func (suite *ServiceHandlerTestSuite) TestScheduleCronJob_Replace() {
+	respoolID := fixture.PelotonResourcePoolID()
+	config := fixture.AuroraCronJobConfiguration()
+	spec, err := atop.NewCronJobSpec(
+		config,
+		respoolID,
+		suite.config.ThermosExecutor,
+	)
+	suite.NoError(err)
+
+	suite.respoolLoader.EXPECT().Load(suite.ctx).Return(respoolID, nil)
+
+	suite.cronClient.EXPECT().
+		CreateCronJob(suite.ctx, &cronsvc.CreateCronJobRequest{Spec: spec}).
+		Return(nil, yarpcerrors.AlreadyExistsErrorf("cron job exists"))
+
+	suite.cronClient.EXPECT().
+		ReplaceCronJob(suite.ctx, &cronsvc.ReplaceCronJobRequest{Spec: spec}).
+		Return(&cronsvc.ReplaceCronJobResponse{}, nil)
+
+	resp, err := suite.handler.ScheduleCronJob(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures that ScheduleCronJob rejects jobs without a cron schedule.
+func (suite *ServiceHandlerTestSuite) TestScheduleCronJob_NoSchedule() {
+	config := fixture.AuroraCronJobConfiguration()
+	config.CronSchedule = nil
+
+	resp, err := suite.handler.ScheduleCronJob(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures that ScheduleCronJob returns an invalid request if the cron
+// job is rejected by jobmgr.
+func (suite *ServiceHandlerTestSuite) TestScheduleCronJob_InvalidSpec() {
+	respoolID := fixture.PelotonResourcePoolID()
+
+	suite.respoolLoader.EXPECT().Load(suite.ctx).Return(respoolID, nil)
+
+	suite.cronClient.EXPECT().
+		CreateCronJob(suite.ctx, gomock.Any()).
+		Return(nil, yarpcerrors.InvalidArgumentErrorf("invalid schedule"))
+
+	resp, err := suite.handler.ScheduleCronJob(
+		suite.ctx,
+		fixture.AuroraCronJobConfiguration())
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures that DescheduleCronJob deletes the cron job, and is a no-op
+// for an unknown cron job.
+func (suite *ServiceHandlerTestSuite) TestDescheduleCronJob() {
+	k := fixture.AuroraJobKey()
+	req := &cronsvc.DeleteCronJobRequest{Name: atop.NewJobName(k)}
+
+	suite.cronClient.EXPECT().
+		DeleteCronJob(suite.ctx, req).
+		Return(&cronsvc.DeleteCronJobResponse{}, nil)
+
+	resp, err := suite.handler.DescheduleCronJob(suite.ctx, k)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	suite.cronClient.EXPECT().
+		DeleteCronJob(suite.ctx, req).
+		Return(nil, yarpcerrors.NotFoundErrorf("cron job not found"))
+
+	resp, err = suite.handler.DescheduleCronJob(suite.ctx, k)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	suite.cronClient.EXPECT().
+		DeleteCronJob(suite.ctx, req).
+		Return(nil, errors.New("some error"))
+
+	resp, err = suite.handler.DescheduleCronJob(suite.ctx, k)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeError, resp.GetResponseCode())
+}
+
+// Ensures that StartCronJob starts a run of the cron job.
+func (suite *ServiceHandlerTestSuite) TestStartCronJob() {
+	k := fixture.AuroraJobKey()
+	req := &cronsvc.StartCronJobRequest{Name: atop.NewJobName(k)}
+
+	suite.cronClient.EXPECT().
+		StartCronJob(suite.ctx, req).
+		Return(&cronsvc.StartCronJobResponse{
+			JobId: fixture.PelotonJobID(),
+		}, nil)
+
+	resp, err := suite.handler.StartCronJob(suite.ctx, k)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures that StartCronJob returns an invalid request for an
+// unknown cron job.
+func (suite *ServiceHandlerTestSuite) TestStartCronJob_NotFound() {
+	k := fixture.AuroraJobKey()
+
+	suite.cronClient.EXPECT().
+		StartCronJob(suite.ctx, &cronsvc.StartCronJobRequest{
+			Name: atop.NewJobName(k),
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf("cron job not found"))
+
+	resp, err := suite.handler.StartCronJob(suite.ctx, k)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures that ReplaceCronTemplate replaces the template of the cron job,
+// and returns an invalid request for an unknown cron job.
+func (suite *ServiceHandlerTestSuite) TestReplaceCronTemplate() {
+	respoolID := fixture.PelotonResourcePoolID()
+	config := fixture.AuroraCronJobConfiguration()
+	spec, err := atop.NewCronJobSpec(
+		config,
+		respoolID,
+		suite.config.ThermosExecutor,
+	)
+	suite.NoError(err)
+
+	suite.respoolLoader.EXPECT().Load(suite.ctx).Return(respoolID, nil).Times(2)
+
+	suite.cronClient.EXPECT().
+		ReplaceCronJob(suite.ctx, &cronsvc.ReplaceCronJobRequest{Spec: spec}).
+		Return(&cronsvc.ReplaceCronJobResponse{}, nil)
+
+	resp, err := suite.handler.ReplaceCronTemplate(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	suite.cronClient.EXPECT().
+		ReplaceCronJob(suite.ctx, &cronsvc.ReplaceCronJobRequest{Spec: spec}).
+		Return(nil, yarpcerrors.NotFoundErrorf("cron job not found"))
+
+	resp, err = suite.handler.ReplaceCronTemplate(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptoa
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"go.uber.org/thriftrw/protocol"
+	"go.uber.org/thriftrw/wire"
+)
+
+// NewExecutorConfig returns the aurora executor config for a provided
+// peloton pod spec. The executor data of the pod contains the aurora
+// TaskConfig serialized by atop.NewPodSpec, which in turn contains the
+// executor config. Returns nil if the pod has no executor data.
+func NewExecutorConfig(podSpec *pod.PodSpec) (*api.ExecutorConfig, error) {
+	c := podSpec.GetContainers()
+	if len(c) == 0 {
+		return nil, fmt.Errorf("pod spec does not contain containers")
+	}
+
+	data := c[0].GetExecutor().GetData()
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	w, err := protocol.Binary.Decode(bytes.NewReader(data), wire.TStruct)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize task config from binary: %s", err)
+	}
+
+	t := &api.TaskConfig{}
+	if err := t.FromWire(w); err != nil {
+		return nil, fmt.Errorf("convert wire value to task config: %s", err)
+	}
+	return t.GetExecutorConfig(), nil
+}
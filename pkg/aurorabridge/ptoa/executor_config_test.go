@@ -0,0 +1,74 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptoa
+
+import (
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/uber/peloton/pkg/aurorabridge/atop"
+	"github.com/uber/peloton/pkg/aurorabridge/fixture"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/thriftrw/ptr"
+)
+
+// Ensures that the executor config is extracted from the executor data
+// of a pod spec created from an aurora task config.
+func TestNewExecutorConfig(t *testing.T) {
+	executorConfig := &api.ExecutorConfig{
+		Name: ptr.String("AuroraExecutor"),
+		Data: ptr.String("{\n\"role\": \"role\"\n}"),
+	}
+
+	p, err := atop.NewPodSpec(
+		&api.TaskConfig{
+			Job:            fixture.AuroraJobKey(),
+			ExecutorConfig: executorConfig,
+		},
+		atop.ThermosExecutorConfig{Path: "/usr/share/aurora/bin/thermos_executor.pex"},
+	)
+	assert.NoError(t, err)
+
+	c, err := NewExecutorConfig(p)
+	assert.NoError(t, err)
+	assert.Equal(t, executorConfig, c)
+}
+
+// Ensures that no executor config is returned for a pod without
+// executor data.
+func TestNewExecutorConfig_NoExecutorData(t *testing.T) {
+	c, err := NewExecutorConfig(&pod.PodSpec{
+		Containers: []*pod.ContainerSpec{{}},
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+}
+
+// Ensures that an error is returned for invalid executor data.
+func TestNewExecutorConfig_InvalidExecutorData(t *testing.T) {
+	_, err := NewExecutorConfig(&pod.PodSpec{
+		Containers: []*pod.ContainerSpec{{
+			Executor: &mesos.ExecutorInfo{Data: []byte("invalid")},
+		}},
+	})
+	assert.Error(t, err)
+
+	_, err = NewExecutorConfig(&pod.PodSpec{})
+	assert.Error(t, err)
+}
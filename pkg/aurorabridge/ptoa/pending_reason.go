@@ -0,0 +1,51 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptoa
+
+import (
+	"fmt"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"go.uber.org/thriftrw/ptr"
+)
+
+// NewPendingReasons returns the reasons why the pods which are PENDING in
+// Aurora are not scheduled yet. The reason is the last admission or
+// placement failure of the pod reported by Peloton, and falls back to the
+// reason of the pod state if there was no such failure.
+func NewPendingReasons(podInfos []*pod.PodInfo) ([]*api.PendingReason, error) {
+	var reasons []*api.PendingReason
+	for _, p := range podInfos {
+		s, err := NewScheduleStatus(p.GetStatus().GetState())
+		if err != nil {
+			return nil, fmt.Errorf("new schedule status: %s", err)
+		}
+		if *s != api.ScheduleStatusPending {
+			continue
+		}
+
+		reason := p.GetStatus().GetPendingReason()
+		if reason == "" {
+			reason = p.GetStatus().GetReason()
+		}
+		reasons = append(reasons, &api.PendingReason{
+			TaskId: ptr.String(p.GetStatus().GetPodId().GetValue()),
+			Reason: ptr.String(reason),
+		})
+	}
+	return reasons, nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptoa
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/thriftrw/ptr"
+)
+
+// Ensures that the pending reasons are only returned for pending pods,
+// and fall back to the reason of the pod state.
+func TestNewPendingReasons(t *testing.T) {
+	podInfos := []*pod.PodInfo{
+		{
+			Status: &pod.PodStatus{
+				State:         pod.PodState_POD_STATE_PENDING,
+				PodId:         &peloton.PodID{Value: "job-0-1"},
+				Reason:        "task enqueued",
+				PendingReason: "entitlement exhausted in resource pool /pool",
+			},
+		},
+		{
+			Status: &pod.PodStatus{
+				State:  pod.PodState_POD_STATE_PENDING,
+				PodId:  &peloton.PodID{Value: "job-1-1"},
+				Reason: "task enqueued",
+			},
+		},
+		{
+			Status: &pod.PodStatus{
+				State: pod.PodState_POD_STATE_RUNNING,
+				PodId: &peloton.PodID{Value: "job-2-1"},
+			},
+		},
+	}
+
+	reasons, err := NewPendingReasons(podInfos)
+	assert.NoError(t, err)
+	assert.Equal(t, []*api.PendingReason{
+		{
+			TaskId: ptr.String("job-0-1"),
+			Reason: ptr.String("entitlement exhausted in resource pool /pool"),
+		},
+		{
+			TaskId: ptr.String("job-1-1"),
+			Reason: ptr.String("task enqueued"),
+		},
+	}, reasons)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptoa
+
+import (
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/uber/peloton/pkg/common"
+
+	"go.uber.org/thriftrw/ptr"
+)
+
+// NewGetQuotaResult converts the info of a Peloton resource pool into
+// an Aurora GetQuotaResult. The reservation of the pool is reported as
+// the quota. The allocation and the demand of the non-revocable tasks is
+// reported as the production consumption, and the allocation and the
+// demand of the revocable tasks as the non-production consumption. All
+// the resources are shared since Peloton has no dedicated resources.
+func NewGetQuotaResult(info *respool.ResourcePoolInfo) *api.GetQuotaResult {
+	reservation := make(map[string]float64)
+	for _, r := range info.GetConfig().GetResources() {
+		reservation[r.GetKind()] = r.GetReservation()
+	}
+
+	consumption := make(map[string]float64)
+	slackConsumption := make(map[string]float64)
+	for _, u := range info.GetUsage() {
+		consumption[u.GetKind()] = u.GetAllocation() + u.GetDemand()
+		slackConsumption[u.GetKind()] = u.GetSlack() + u.GetSlackDemand()
+	}
+
+	return &api.GetQuotaResult{
+		Quota:                    NewResourceAggregate(reservation),
+		ProdSharedConsumption:    NewResourceAggregate(consumption),
+		NonProdSharedConsumption: NewResourceAggregate(slackConsumption),
+		//ProdDedicatedConsumption:    nil,
+		//NonProdDedicatedConsumption: nil,
+	}
+}
+
+// NewResourceAggregate creates an Aurora ResourceAggregate from the
+// amount of each kind of Peloton resource.
+func NewResourceAggregate(resources map[string]float64) *api.ResourceAggregate {
+	cpus := resources[common.CPU]
+	ramMb := int64(resources[common.MEMORY])
+	diskMb := int64(resources[common.DISK])
+	gpus := int64(resources[common.GPU])
+
+	return &api.ResourceAggregate{
+		NumCpus: ptr.Float64(cpus),
+		RamMb:   ptr.Int64(ramMb),
+		DiskMb:  ptr.Int64(diskMb),
+		Resources: []*api.Resource{
+			{NumCpus: ptr.Float64(cpus)},
+			{RamMb: ptr.Int64(ramMb)},
+			{DiskMb: ptr.Int64(diskMb)},
+			{NumGpus: ptr.Int64(gpus)},
+		},
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptoa
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/uber/peloton/pkg/common"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/thriftrw/ptr"
+)
+
+// Ensures that the reservation of a resource pool is converted to the
+// quota, and its allocation and demand to the consumption.
+func TestNewGetQuotaResult(t *testing.T) {
+	info := &respool.ResourcePoolInfo{
+		Config: &respool.ResourcePoolConfig{
+			Resources: []*respool.ResourceConfig{
+				{Kind: common.CPU, Reservation: 10, Limit: 20},
+				{Kind: common.MEMORY, Reservation: 1024, Limit: 2048},
+				{Kind: common.DISK, Reservation: 4096, Limit: 8192},
+				{Kind: common.GPU, Reservation: 1, Limit: 2},
+			},
+		},
+		Usage: []*respool.ResourceUsage{
+			{Kind: common.CPU, Allocation: 4, Slack: 1, Demand: 2, SlackDemand: 1},
+			{Kind: common.MEMORY, Allocation: 512, Slack: 128, Demand: 256},
+			{Kind: common.DISK, Allocation: 1024},
+			{Kind: common.GPU},
+		},
+	}
+
+	r := NewGetQuotaResult(info)
+	assert.Equal(t, &api.ResourceAggregate{
+		NumCpus: ptr.Float64(10),
+		RamMb:   ptr.Int64(1024),
+		DiskMb:  ptr.Int64(4096),
+		Resources: []*api.Resource{
+			{NumCpus: ptr.Float64(10)},
+			{RamMb: ptr.Int64(1024)},
+			{DiskMb: ptr.Int64(4096)},
+			{NumGpus: ptr.Int64(1)},
+		},
+	}, r.GetQuota())
+	assert.Equal(t, 6.0, r.GetProdSharedConsumption().GetNumCpus())
+	assert.Equal(t, int64(768), r.GetProdSharedConsumption().GetRamMb())
+	assert.Equal(t, int64(1024), r.GetProdSharedConsumption().GetDiskMb())
+	assert.Equal(t, 2.0, r.GetNonProdSharedConsumption().GetNumCpus())
+	assert.Equal(t, int64(128), r.GetNonProdSharedConsumption().GetRamMb())
+	assert.Equal(t, int64(0), r.GetNonProdSharedConsumption().GetDiskMb())
+	assert.Nil(t, r.GetProdDedicatedConsumption())
+	assert.Nil(t, r.GetNonProdDedicatedConsumption())
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptoa
+
+import (
+	"sort"
+
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"go.uber.org/thriftrw/ptr"
+)
+
+// NewRoleSummaries aggregates the number of jobs and cron jobs of each
+// role from the keys of the jobs and of the cron jobs. The summaries are
+// sorted by role.
+func NewRoleSummaries(
+	jobKeys []*api.JobKey,
+	cronJobKeys []*api.JobKey,
+) []*api.RoleSummary {
+	summaries := make(map[string]*api.RoleSummary)
+	get := func(role string) *api.RoleSummary {
+		s, ok := summaries[role]
+		if !ok {
+			s = &api.RoleSummary{
+				Role:         ptr.String(role),
+				JobCount:     ptr.Int32(0),
+				CronJobCount: ptr.Int32(0),
+			}
+			summaries[role] = s
+		}
+		return s
+	}
+
+	for _, k := range jobKeys {
+		s := get(k.GetRole())
+		*s.JobCount++
+	}
+	for _, k := range cronJobKeys {
+		s := get(k.GetRole())
+		*s.CronJobCount++
+	}
+
+	result := make([]*api.RoleSummary, 0, len(summaries))
+	for _, s := range summaries {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GetRole() < result[j].GetRole()
+	})
+	return result
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptoa
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/thriftrw/ptr"
+)
+
+// Ensures that the jobs and cron jobs are counted per role.
+func TestNewRoleSummaries(t *testing.T) {
+	key := func(role string) *api.JobKey {
+		return &api.JobKey{
+			Role:        ptr.String(role),
+			Environment: ptr.String("prod"),
+			Name:        ptr.String("job"),
+		}
+	}
+
+	summaries := NewRoleSummaries(
+		[]*api.JobKey{key("role2"), key("role1"), key("role2")},
+		[]*api.JobKey{key("role3"), key("role1")},
+	)
+	assert.Equal(t, []*api.RoleSummary{
+		{
+			Role:         ptr.String("role1"),
+			JobCount:     ptr.Int32(1),
+			CronJobCount: ptr.Int32(1),
+		},
+		{
+			Role:         ptr.String("role2"),
+			JobCount:     ptr.Int32(2),
+			CronJobCount: ptr.Int32(0),
+		},
+		{
+			Role:         ptr.String("role3"),
+			JobCount:     ptr.Int32(0),
+			CronJobCount: ptr.Int32(1),
+		},
+	}, summaries)
+}
+
+// Ensures that no summary is returned when there is no job.
+func TestNewRoleSummaries_Empty(t *testing.T) {
+	assert.Empty(t, NewRoleSummaries(nil, nil))
+}
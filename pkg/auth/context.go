@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type userContextKey struct{}
+
+// callUserKey is the context key of the callUser of a call
+type callUserKey struct{}
+
+// callUser is the user authenticated for a call, recorded by WithUser
+// for the readers which only have the context of the call before it
+// was authenticated
+type callUser struct {
+	sync.RWMutex
+	user User
+}
+
+// userContext is the user authenticated for a procedure
+type userContext struct {
+	user      User
+	procedure string
+}
+
+// WithUser returns a copy of ctx which carries the user
+// authenticated to call the procedure. The user is also recorded
+// for GetCallUsername if ctx was returned by WithCallUser.
+func WithUser(ctx context.Context, user User, procedure string) context.Context {
+	if cu, ok := ctx.Value(callUserKey{}).(*callUser); ok {
+		cu.Lock()
+		cu.user = user
+		cu.Unlock()
+	}
+	return context.WithValue(ctx, userContextKey{}, &userContext{
+		user:      user,
+		procedure: procedure,
+	})
+}
+
+// HasUser returns whether ctx carries an authenticated user
+func HasUser(ctx context.Context) bool {
+	_, ok := ctx.Value(userContextKey{}).(*userContext)
+	return ok
+}
+
+// GetUsername returns the name of the user carried by ctx,
+// or an empty string if ctx does not carry a user
+func GetUsername(ctx context.Context) string {
+	uc, ok := ctx.Value(userContextKey{}).(*userContext)
+	if !ok {
+		return ""
+	}
+	return uc.user.Username()
+}
+
+// WithCallUser returns a copy of ctx in which WithUser records the user
+// authenticated for the call, so that it can be read with GetCallUsername
+// even if the call is rejected once authenticated, or returned.
+func WithCallUser(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callUserKey{}, &callUser{})
+}
+
+// GetCallUsername returns the name of the user authenticated for the
+// call of ctx, which is returned by WithCallUser. It returns the name of
+// the user carried by ctx if any, or an empty string if the call was not
+// authenticated.
+func GetCallUsername(ctx context.Context) string {
+	if username := GetUsername(ctx); len(username) != 0 {
+		return username
+	}
+	cu, ok := ctx.Value(callUserKey{}).(*callUser)
+	if !ok {
+		return ""
+	}
+	cu.RLock()
+	defer cu.RUnlock()
+	if cu.user == nil {
+		return ""
+	}
+	return cu.user.Username()
+}
+
+// CheckEntityPermission returns a permission denied error if the user
+// carried by ctx is not permitted to call its procedure on the entity.
+// No check is done if ctx does not carry a user, which is the case
+// for the requests which have not gone through the auth middleware.
+func CheckEntityPermission(ctx context.Context, entity *Entity) error {
+	uc, ok := ctx.Value(userContextKey{}).(*userContext)
+	if !ok {
+		return nil
+	}
+
+	if !uc.user.IsPermittedOnEntity(uc.procedure, entity) {
+		return yarpcerrors.PermissionDeniedErrorf(
+			"not permitted to call %s on an entity owned by %s",
+			uc.procedure,
+			entityOwners(entity),
+		)
+	}
+	return nil
+}
+
+// entityOwners returns a printable list of the owners of the entity
+func entityOwners(entity *Entity) []string {
+	var owners []string
+	if len(entity.Owner) != 0 {
+		owners = append(owners, entity.Owner)
+	}
+	if len(entity.OwningTeam) != 0 {
+		owners = append(owners, entity.OwningTeam)
+	}
+	return append(owners, entity.LdapGroups...)
+}
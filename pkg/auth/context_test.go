@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// testUser is owner of the entities owned by its groups
+type testUser struct {
+	groups []string
+}
+
+func (u *testUser) Username() string {
+	return ""
+}
+
+func (u *testUser) IsPermitted(procedure string) bool {
+	return true
+}
+
+func (u *testUser) IsPermittedOnEntity(procedure string, entity *Entity) bool {
+	return entity.IsOwnedBy("", u.groups)
+}
+
+func TestCheckEntityPermission(t *testing.T) {
+	entity := &Entity{Owner: "user1", OwningTeam: "team1"}
+
+	// no check is done without a user
+	ctx := context.Background()
+	assert.False(t, HasUser(ctx))
+	assert.NoError(t, CheckEntityPermission(ctx, entity))
+
+	ctx = WithUser(ctx, &testUser{groups: []string{"team1"}}, "JobManager::Delete")
+	assert.True(t, HasUser(ctx))
+	assert.NoError(t, CheckEntityPermission(ctx, entity))
+
+	ctx = WithUser(ctx, &testUser{groups: []string{"team2"}}, "JobManager::Delete")
+	err := CheckEntityPermission(ctx, entity)
+	assert.Error(t, err)
+	assert.True(t, yarpcerrors.IsPermissionDenied(err))
+}
+
+// namedUser is a user with a name
+type namedUser struct {
+	testUser
+	name string
+}
+
+func (u *namedUser) Username() string {
+	return u.name
+}
+
+func TestGetCallUsername(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, GetCallUsername(ctx))
+
+	// the user is read from the context of the call before auth
+	callCtx := WithCallUser(ctx)
+	assert.Empty(t, GetCallUsername(callCtx))
+	userCtx := WithUser(callCtx, &namedUser{name: "user1"}, "JobManager::Delete")
+	assert.Equal(t, "user1", GetCallUsername(callCtx))
+	assert.Equal(t, "user1", GetCallUsername(userCtx))
+
+	// the user carried by the context is returned without WithCallUser
+	userCtx = WithUser(ctx, &namedUser{name: "user2"}, "JobManager::Delete")
+	assert.Equal(t, "user2", GetCallUsername(userCtx))
+	assert.Empty(t, GetCallUsername(ctx))
+}
+
+func TestEntityIsOwnedBy(t *testing.T) {
+	tests := []struct {
+		entity   *Entity
+		username string
+		groups   []string
+		owned    bool
+	}{
+		{entity: &Entity{}, owned: true},
+		{entity: &Entity{Owner: "user1"}, username: "user1", owned: true},
+		{entity: &Entity{Owner: "user1"}, username: "user2", owned: false},
+		{entity: &Entity{Owner: "user1"}, owned: false},
+		{entity: &Entity{OwningTeam: "team1"}, groups: []string{"team1"}, owned: true},
+		{entity: &Entity{LdapGroups: []string{"a", "b"}}, groups: []string{"b"}, owned: true},
+		{entity: &Entity{OwningTeam: "team1", LdapGroups: []string{"a"}}, groups: []string{"b"}, owned: false},
+		{entity: &Entity{Owner: "user1"}, groups: []string{""}, owned: false},
+		{entity: &Entity{LdapGroups: []string{"a"}}, groups: []string{""}, owned: false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(
+			t,
+			test.owned,
+			test.entity.IsOwnedBy(test.username, test.groups),
+			"%v", test.entity)
+	}
+}
@@ -3,6 +3,7 @@ package impl
 import (
 	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/auth/impl/basic"
+	"github.com/uber/peloton/pkg/auth/impl/jwt"
 	"github.com/uber/peloton/pkg/auth/impl/noop"
 
 	"go.uber.org/yarpc/yarpcerrors"
@@ -15,6 +16,23 @@ func CreateNewSecurityManager(t auth.Type, configPath string) (auth.SecurityMana
 		return noop.NewNoopSecurityManager(), nil
 	case auth.BASIC:
 		return basic.NewBasicSecurityManager(configPath)
+	case auth.JWT:
+		return jwt.NewJWTSecurityManager(configPath)
+	default:
+		return nil,
+			yarpcerrors.InvalidArgumentErrorf("unknown security type provided: %s", t)
+	}
+}
+
+// CreateNewSecurityClient creates SecurityClient based on type
+func CreateNewSecurityClient(t auth.Type, configPath string) (auth.SecurityClient, error) {
+	switch t {
+	case auth.NOOP, auth.UNDEFINED:
+		return noop.NewNoopSecurityClient(), nil
+	case auth.BASIC:
+		return basic.NewBasicSecurityClient(configPath)
+	case auth.JWT:
+		return jwt.NewJWTSecurityClient(configPath)
 	default:
 		return nil,
 			yarpcerrors.InvalidArgumentErrorf("unknown security type provided: %s", t)
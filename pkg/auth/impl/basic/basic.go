@@ -31,20 +31,25 @@ type SecurityManager struct {
 // need lock protection if the assumption breaks
 type user struct {
 	username string
-	role     *role
+	role     *Role
 	// store the Password in hashed way,
 	// so it is not exposed by mem dump.
 	hashedPassword []byte
+	// LDAP groups of the user
+	groups []string
 }
 
-// all fields are immutable after init,
+// Role accepts and rejects procedures by the rules of its config.
+// All fields are immutable after init,
 // need lock protection if the assumption breaks
-type role struct {
+type Role struct {
 	role string
 	// service -> methods
 	accepts map[string][]string
 	// service -> methods
 	rejects map[string][]string
+	// admin can access the entities it does not own
+	admin bool
 }
 
 var _ auth.SecurityManager = &SecurityManager{}
@@ -85,22 +90,49 @@ func (m *SecurityManager) Authenticate(token auth.Token) (auth.User, error) {
 	return user, nil
 }
 
+// Username returns the name of user
+func (u *user) Username() string {
+	return u.username
+}
+
 // IsPermitted returns if a procedure is permitted for user
 func (u *user) IsPermitted(procedure string) bool {
+	return u.role.IsPermitted(procedure)
+}
+
+// IsPermittedOnEntity returns if a procedure is permitted for user on
+// the entity. The procedure is permitted if it is permitted for user,
+// and the entity is owned by user or one of its groups, unless user
+// has an admin role.
+func (u *user) IsPermittedOnEntity(procedure string, entity *auth.Entity) bool {
+	if !u.IsPermitted(procedure) {
+		return false
+	}
+
+	return u.role.IsAdmin() || entity.IsOwnedBy(u.username, u.groups)
+}
+
+// IsPermitted returns if a procedure is permitted for the role
+func (r *Role) IsPermitted(procedure string) bool {
 	// procedure is permitted if it is accepted by
 	// the role and is not rejected
 	results := strings.Split(procedure, _procedureSeparator)
 	service := results[0]
 	method := results[1]
 
-	if matchRules(service, method, u.role.accepts) &&
-		!matchRules(service, method, u.role.rejects) {
+	if matchRules(service, method, r.accepts) &&
+		!matchRules(service, method, r.rejects) {
 		return true
 	}
 
 	return false
 }
 
+// IsAdmin returns if the role can access the entities it does not own
+func (r *Role) IsAdmin() bool {
+	return r.admin
+}
+
 func matchRules(service, method string, rules map[string][]string) bool {
 	// _matchAllRule is set, all services and methods are matched
 	if _, ok := rules[_matchAllRule]; ok {
@@ -148,7 +180,7 @@ func newBasicSecurityManager(mConfig *managerConfig) (*SecurityManager, error) {
 		return nil, err
 	}
 
-	defaultUser, users, err := constructUsers(mConfig, constructRoles(mConfig))
+	defaultUser, users, err := constructUsers(mConfig, NewRoles(mConfig.Roles))
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +191,55 @@ func newBasicSecurityManager(mConfig *managerConfig) (*SecurityManager, error) {
 	}, nil
 }
 
+// SecurityClient passes the Username and Password of the internal user
+type SecurityClient struct {
+	username string
+	password string
+}
+
+var _ auth.SecurityClient = &SecurityClient{}
+
+// GetCredentials returns the Username and Password headers of the
+// internal user, or no header if there is no internal user
+func (c *SecurityClient) GetCredentials() map[string]string {
+	if len(c.username) == 0 {
+		return nil
+	}
+
+	return map[string]string{
+		_usernameHeaderKey: c.username,
+		_passwordHeaderKey: c.password,
+	}
+}
+
+// NewBasicSecurityClient returns SecurityClient
+func NewBasicSecurityClient(configPath string) (*SecurityClient, error) {
+	mConfig, err := parseConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBasicSecurityClient(mConfig)
+}
+
+// helper method to create SecurityClient which makes test easier
+func newBasicSecurityClient(mConfig *managerConfig) (*SecurityClient, error) {
+	if err := validateConfig(mConfig); err != nil {
+		return nil, err
+	}
+
+	for _, userConfig := range mConfig.Users {
+		if len(mConfig.InternalUser) != 0 &&
+			userConfig.Username == mConfig.InternalUser {
+			return &SecurityClient{
+				username: userConfig.Username,
+				password: userConfig.Password,
+			}, nil
+		}
+	}
+	return &SecurityClient{}, nil
+}
+
 func parseConfig(configPath string) (*managerConfig, error) {
 	mConfig := &managerConfig{}
 	if err := config.Parse(mConfig, configPath); err != nil {
@@ -168,26 +249,12 @@ func parseConfig(configPath string) (*managerConfig, error) {
 }
 
 func validateConfig(config *managerConfig) error {
+	if err := ValidateRoles(config.Roles); err != nil {
+		return err
+	}
+
 	roleSet := make(map[string]struct{})
-	// check if rules are valid
 	for _, roleConfig := range config.Roles {
-		for _, acceptRule := range roleConfig.Accept {
-			if err := validateRule(acceptRule); err != nil {
-				return err
-			}
-		}
-		for _, rejectRule := range roleConfig.Reject {
-			if err := validateRule(rejectRule); err != nil {
-				return err
-			}
-		}
-
-		if _, ok := roleSet[roleConfig.Role]; ok {
-			return yarpcerrors.InvalidArgumentErrorf(
-				"same Role defined more than once. Role:%s",
-				roleConfig.Role,
-			)
-		}
 		roleSet[roleConfig.Role] = struct{}{}
 	}
 
@@ -232,6 +299,40 @@ func validateConfig(config *managerConfig) error {
 		}
 		userSet[userConfig.Username] = struct{}{}
 	}
+
+	if len(config.InternalUser) != 0 {
+		if _, ok := userSet[config.InternalUser]; !ok {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"undefined internal user: %s", config.InternalUser)
+		}
+	}
+	return nil
+}
+
+// ValidateRoles checks that the rules of the roles are valid,
+// and that no role is defined more than once
+func ValidateRoles(roles []*RoleConfig) error {
+	roleSet := make(map[string]struct{})
+	for _, roleConfig := range roles {
+		for _, acceptRule := range roleConfig.Accept {
+			if err := validateRule(acceptRule); err != nil {
+				return err
+			}
+		}
+		for _, rejectRule := range roleConfig.Reject {
+			if err := validateRule(rejectRule); err != nil {
+				return err
+			}
+		}
+
+		if _, ok := roleSet[roleConfig.Role]; ok {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"same Role defined more than once. Role:%s",
+				roleConfig.Role,
+			)
+		}
+		roleSet[roleConfig.Role] = struct{}{}
+	}
 	return nil
 }
 
@@ -299,9 +400,11 @@ func isValidMethod(method string) bool {
 	return true
 }
 
-func constructRoles(mConfig *managerConfig) map[string]*role {
-	result := make(map[string]*role)
-	for _, roleConfig := range mConfig.Roles {
+// NewRoles returns the roles of the configs by name,
+// the configs are expected to be validated by ValidateRoles
+func NewRoles(roles []*RoleConfig) map[string]*Role {
+	result := make(map[string]*Role)
+	for _, roleConfig := range roles {
 		accepts := make(map[string][]string)
 		rejects := make(map[string][]string)
 
@@ -324,17 +427,18 @@ func constructRoles(mConfig *managerConfig) map[string]*role {
 
 		}
 
-		result[roleConfig.Role] = &role{
+		result[roleConfig.Role] = &Role{
 			role:    roleConfig.Role,
 			accepts: accepts,
 			rejects: rejects,
+			admin:   roleConfig.Admin,
 		}
 	}
 
 	return result
 }
 
-func constructUsers(mConfig *managerConfig, roles map[string]*role) (
+func constructUsers(mConfig *managerConfig, roles map[string]*Role) (
 	defaultUser *user,
 	users map[string]*user,
 	err error,
@@ -360,7 +464,8 @@ func constructUsers(mConfig *managerConfig, roles map[string]*role) (
 					yarpcerrors.InvalidArgumentErrorf("more than one default user specified")
 			}
 			defaultUser = &user{
-				role: role,
+				role:   role,
+				groups: userConfig.Groups,
 			}
 		}
 
@@ -373,6 +478,7 @@ func constructUsers(mConfig *managerConfig, roles map[string]*role) (
 			username:       userConfig.Username,
 			role:           role,
 			hashedPassword: hashedBytes,
+			groups:         userConfig.Groups,
 		}
 	}
 
@@ -3,6 +3,8 @@ package basic
 import (
 	"testing"
 
+	"github.com/uber/peloton/pkg/auth"
+
 	"github.com/stretchr/testify/suite"
 )
 
@@ -21,10 +23,10 @@ func (suite *SecurityManagerTestSuite) SetupTest() {
 }
 
 func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerSuccess() {
-	role1 := &roleConfig{
+	role1 := &RoleConfig{
 		Role: "admin",
 	}
-	role2 := &roleConfig{
+	role2 := &RoleConfig{
 		Role: "default",
 	}
 
@@ -44,7 +46,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerSuccess() {
 
 	config := &managerConfig{
 		Users: []*userConfig{user1, user2, user3},
-		Roles: []*roleConfig{role1, role2},
+		Roles: []*RoleConfig{role1, role2},
 	}
 
 	m, err := newBasicSecurityManager(config)
@@ -53,7 +55,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerSuccess() {
 }
 
 func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMultiDefaultUserErr() {
-	role1 := &roleConfig{
+	role1 := &RoleConfig{
 		Role: "default",
 	}
 
@@ -66,7 +68,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMultiDefaul
 
 	config := &managerConfig{
 		Users: []*userConfig{user1, user2},
-		Roles: []*roleConfig{role1},
+		Roles: []*RoleConfig{role1},
 	}
 
 	m, err := newBasicSecurityManager(config)
@@ -75,10 +77,10 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMultiDefaul
 }
 
 func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerDuplicatedRolesErr() {
-	role1 := &roleConfig{
+	role1 := &RoleConfig{
 		Role: "admin",
 	}
-	role2 := &roleConfig{
+	role2 := &RoleConfig{
 		Role: "admin",
 	}
 
@@ -93,7 +95,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerDuplicatedR
 
 	config := &managerConfig{
 		Users: []*userConfig{user1, user2},
-		Roles: []*roleConfig{role1, role2},
+		Roles: []*RoleConfig{role1, role2},
 	}
 
 	m, err := newBasicSecurityManager(config)
@@ -102,7 +104,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerDuplicatedR
 }
 
 func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerDuplicatedUsersErr() {
-	role1 := &roleConfig{
+	role1 := &RoleConfig{
 		Role: "admin",
 	}
 
@@ -119,7 +121,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerDuplicatedU
 
 	config := &managerConfig{
 		Users: []*userConfig{user1, user2},
-		Roles: []*roleConfig{role1},
+		Roles: []*RoleConfig{role1},
 	}
 
 	m, err := newBasicSecurityManager(config)
@@ -128,7 +130,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerDuplicatedU
 }
 
 func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerUndefinedRoleErr() {
-	role1 := &roleConfig{
+	role1 := &RoleConfig{
 		Role: "admin",
 	}
 
@@ -145,7 +147,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerUndefinedRo
 
 	config := &managerConfig{
 		Users: []*userConfig{user1, user2},
-		Roles: []*roleConfig{role1},
+		Roles: []*RoleConfig{role1},
 	}
 
 	m, err := newBasicSecurityManager(config)
@@ -154,7 +156,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerUndefinedRo
 }
 
 func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMissingUserInfoErr() {
-	role := &roleConfig{
+	role := &RoleConfig{
 		Role: "admin",
 	}
 
@@ -165,7 +167,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMissingUser
 	}
 	config := &managerConfig{
 		Users: []*userConfig{user},
-		Roles: []*roleConfig{role},
+		Roles: []*RoleConfig{role},
 	}
 
 	m, err := newBasicSecurityManager(config)
@@ -179,7 +181,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMissingUser
 	}
 	config = &managerConfig{
 		Users: []*userConfig{user},
-		Roles: []*roleConfig{role},
+		Roles: []*RoleConfig{role},
 	}
 
 	m, err = newBasicSecurityManager(config)
@@ -193,7 +195,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMissingUser
 	}
 	config = &managerConfig{
 		Users: []*userConfig{user},
-		Roles: []*roleConfig{role},
+		Roles: []*RoleConfig{role},
 	}
 
 	m, err = newBasicSecurityManager(config)
@@ -248,7 +250,7 @@ func (suite *SecurityManagerTestSuite) TestAuthenticateUser() {
 }
 
 func (suite *SecurityManagerTestSuite) TestAuthenticateDefaultUserWhenNonDefinedErr() {
-	role1 := &roleConfig{
+	role1 := &RoleConfig{
 		Role: "admin",
 	}
 
@@ -265,7 +267,7 @@ func (suite *SecurityManagerTestSuite) TestAuthenticateDefaultUserWhenNonDefined
 
 	config := &managerConfig{
 		Users: []*userConfig{user1, user2},
-		Roles: []*roleConfig{role1},
+		Roles: []*RoleConfig{role1},
 	}
 
 	// no default user defined
@@ -328,6 +330,46 @@ func (suite *SecurityManagerTestSuite) TestNormalUserPermission() {
 	}
 }
 
+func (suite *SecurityManagerTestSuite) TestEntityPermission() {
+	procedureName := "peloton.api.v1alpha.respool.svc.ResourcePoolService::UpdateResourcePool"
+	tests := []struct {
+		entity      *auth.Entity
+		isPermitted bool
+	}{
+		{entity: &auth.Entity{}, isPermitted: true},
+		{entity: &auth.Entity{Owner: "user1"}, isPermitted: true},
+		{entity: &auth.Entity{OwningTeam: "team1"}, isPermitted: true},
+		{entity: &auth.Entity{LdapGroups: []string{"group2", "group1"}}, isPermitted: true},
+		{entity: &auth.Entity{Owner: "user2"}, isPermitted: false},
+		{entity: &auth.Entity{OwningTeam: "team2"}, isPermitted: false},
+		{entity: &auth.Entity{LdapGroups: []string{"group2"}}, isPermitted: false},
+	}
+
+	u, err := suite.m.Authenticate(
+		&testToken{username: "user1", password: "password1"},
+	)
+	suite.NoError(err)
+
+	// the admin role can access the entities it does not own
+	admin, err := suite.m.Authenticate(
+		&testToken{username: "user2", password: "password2"},
+	)
+	suite.NoError(err)
+
+	for _, test := range tests {
+		suite.Equal(
+			test.isPermitted,
+			u.IsPermittedOnEntity(procedureName, test.entity),
+			"%v", test.entity)
+		suite.True(admin.IsPermittedOnEntity(procedureName, test.entity))
+	}
+
+	// the procedure must be permitted as well
+	suite.False(u.IsPermittedOnEntity(
+		"peloton.api.v1alpha.job.stateless.svc.JobService::DeleteJob",
+		&auth.Entity{OwningTeam: "team1"}))
+}
+
 func (suite *SecurityManagerTestSuite) TestValidateRule() {
 	tests := []struct {
 		rule      string
@@ -376,6 +418,44 @@ func (suite *SecurityManagerTestSuite) TestValidateRule() {
 	}
 }
 
+// TestSecurityClient tests that the credentials of the
+// internal user are authenticated by the SecurityManager
+func (suite *SecurityManagerTestSuite) TestSecurityClient() {
+	c, err := NewBasicSecurityClient(_testConfigPath)
+	suite.NoError(err)
+
+	credentials := c.GetCredentials()
+	u, err := suite.m.Authenticate(&testToken{
+		username: credentials[_usernameHeaderKey],
+		password: credentials[_passwordHeaderKey],
+	})
+	suite.NoError(err)
+	suite.Equal("user2", u.Username())
+}
+
+// TestSecurityClientWithoutInternalUser tests that no credentials
+// are passed when there is no internal user
+func (suite *SecurityManagerTestSuite) TestSecurityClientWithoutInternalUser() {
+	c, err := newBasicSecurityClient(&managerConfig{
+		Users: []*userConfig{{Role: "role1", Username: "user1", Password: "password1"}},
+		Roles: []*RoleConfig{{Role: "role1"}},
+	})
+	suite.NoError(err)
+	suite.Empty(c.GetCredentials())
+}
+
+// TestSecurityClientUndefinedInternalUserErr tests that the
+// internal user must be one of the users
+func (suite *SecurityManagerTestSuite) TestSecurityClientUndefinedInternalUserErr() {
+	c, err := newBasicSecurityClient(&managerConfig{
+		Users:        []*userConfig{{Role: "role1", Username: "user1", Password: "password1"}},
+		Roles:        []*RoleConfig{{Role: "role1"}},
+		InternalUser: "user2",
+	})
+	suite.Nil(c)
+	suite.Error(err)
+}
+
 type testToken struct {
 	username string
 	password string
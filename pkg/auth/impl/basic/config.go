@@ -2,17 +2,30 @@ package basic
 
 type managerConfig struct {
 	Users []*userConfig
-	Roles []*roleConfig
+	Roles []*RoleConfig
+	// InternalUser is the name of the user whose credentials the
+	// Peloton components pass on their calls to each other
+	InternalUser string `yaml:"internal_user"`
 }
 
 type userConfig struct {
 	Role     string
 	Username string
 	Password string
+	// Groups are the LDAP groups of the user, which are matched
+	// against the owning team and LDAP groups of the entities
+	Groups []string
 }
 
-type roleConfig struct {
+// RoleConfig is the config of a role, which accepts and rejects
+// procedures by rules of the form "service:method". The method may
+// end with "*" to match all the methods with its prefix, and the rule
+// "*" matches all the procedures.
+type RoleConfig struct {
 	Role   string
 	Accept []string
 	Reject []string
+	// Admin allows the users of the role to access
+	// the entities they do not own
+	Admin bool
 }
@@ -0,0 +1,83 @@
+package jwt
+
+import (
+	"github.com/uber/peloton/pkg/auth/impl/basic"
+)
+
+type managerConfig struct {
+	// Issuer is the expected iss claim of the tokens,
+	// the claim is not checked if it is empty
+	Issuer string
+	// Audience is the expected aud claim of the tokens,
+	// the claim is not checked if it is empty
+	Audience string
+	// Keys are the keys which verify the signature of the tokens
+	Keys []*keyConfig
+	// JWKSFile is the path of a JSON Web Key Set file,
+	// whose keys are used in addition to Keys
+	JWKSFile string `yaml:"jwks_file"`
+	// Claims are the names of the claims mapped onto the users
+	Claims claimsConfig
+	// Roles have the same rules as the roles of the basic auth,
+	// and are matched against the roles claim of the tokens
+	Roles []*basic.RoleConfig
+	// DefaultRole is the role of the requests without a token,
+	// those requests are rejected if it is empty
+	DefaultRole string `yaml:"default_role"`
+	// InternalUser is the identity of the Peloton components on their
+	// calls to each other, they sign the tokens of the internal user
+	InternalUser *internalUserConfig `yaml:"internal_user"`
+}
+
+type internalUserConfig struct {
+	// Username is the name of the internal user
+	Username string
+	// Roles are the roles of the internal user
+	Roles []string
+	// KeyID is the ID of the HS256 key which signs
+	// the tokens of the internal user
+	KeyID string `yaml:"key_id"`
+}
+
+type keyConfig struct {
+	// ID is matched against the kid header of the tokens,
+	// a key without ID verifies the tokens without kid
+	ID string
+	// Algorithm is the signing algorithm of the key, HS256 or RS256
+	Algorithm string
+	// Secret is the shared secret of a HS256 key
+	Secret string
+	// PublicKeyFile is the path of the PEM encoded public key of a RS256 key
+	PublicKeyFile string `yaml:"public_key_file"`
+}
+
+type claimsConfig struct {
+	// Username is the claim with the name of the user, defaults to sub
+	Username string
+	// Groups is the claim with the LDAP groups of the user,
+	// defaults to groups
+	Groups string
+	// Roles is the claim with the roles of the user, defaults to roles
+	Roles string
+}
+
+const (
+	_defaultUsernameClaim = "sub"
+	_defaultGroupsClaim   = "groups"
+	_defaultRolesClaim    = "roles"
+)
+
+// withDefaults returns the claims config with
+// the default names of the claims which are not set
+func (c claimsConfig) withDefaults() claimsConfig {
+	if len(c.Username) == 0 {
+		c.Username = _defaultUsernameClaim
+	}
+	if len(c.Groups) == 0 {
+		c.Groups = _defaultGroupsClaim
+	}
+	if len(c.Roles) == 0 {
+		c.Roles = _defaultRolesClaim
+	}
+	return c
+}
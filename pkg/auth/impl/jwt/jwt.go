@@ -0,0 +1,449 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/auth/impl/basic"
+	"github.com/uber/peloton/pkg/common/config"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// expected field passed by token, which is
+	// the signed JWT prefixed by _bearerPrefix
+	_authorizationHeaderKey = "authorization"
+	_bearerPrefix           = "Bearer "
+
+	_tokenSeparator = "."
+
+	// lifetime of the tokens signed for the internal user, which
+	// are signed again once half of their lifetime has passed
+	_internalTokenTTL = time.Hour
+)
+
+// SecurityManager authenticates the users with signed JWTs
+type SecurityManager struct {
+	issuer   string
+	audience string
+	// key ID -> key
+	keys   map[string]*key
+	claims claimsConfig
+	roles  map[string]*basic.Role
+	// user of the requests without a token, nil if
+	// those requests are rejected
+	defaultUser *user
+	// returns the current time, used to check the
+	// expiration of the tokens
+	now func() time.Time
+}
+
+// all fields are immutable after init,
+// need lock protection if the assumption breaks
+type user struct {
+	username string
+	// LDAP groups of the user
+	groups []string
+	roles  []*basic.Role
+}
+
+var _ auth.SecurityManager = &SecurityManager{}
+
+// tokenHeader is the JOSE header of a JWT
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Authenticate authenticates a user,
+// it expects to Accept a bearer JWT in the authorization field
+func (m *SecurityManager) Authenticate(token auth.Token) (auth.User, error) {
+	authErr := yarpcerrors.UnauthenticatedErrorf("invalid bearer token")
+
+	authorization, _ := token.Get(_authorizationHeaderKey)
+
+	// no token provided, return default user
+	if len(authorization) == 0 {
+		if m.defaultUser == nil {
+			return nil, authErr
+		}
+		return m.defaultUser, nil
+	}
+
+	if !strings.HasPrefix(authorization, _bearerPrefix) {
+		return nil, authErr
+	}
+
+	claims, err := m.verify(
+		strings.TrimSpace(strings.TrimPrefix(authorization, _bearerPrefix)))
+	if err != nil {
+		return nil, authErr
+	}
+
+	return m.newUser(claims), nil
+}
+
+// verify checks the signature and the registered claims of the
+// token, and returns all its claims
+func (m *SecurityManager) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, _tokenSeparator)
+	if len(parts) != 3 {
+		return nil, yarpcerrors.UnauthenticatedErrorf("malformed token")
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	// the algorithm of the token must be the algorithm of the key,
+	// so that a public key cannot be used as a HMAC secret
+	k, ok := m.keys[header.Kid]
+	if !ok || k.algorithm != header.Alg {
+		return nil, yarpcerrors.UnauthenticatedErrorf(
+			"unknown key:%s for algorithm:%s", header.Kid, header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	if !k.verify([]byte(parts[0]+_tokenSeparator+parts[1]), signature) {
+		return nil, yarpcerrors.UnauthenticatedErrorf("invalid signature")
+	}
+
+	claims := make(map[string]interface{})
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	if err := m.verifyClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// verifyClaims checks the expiration, not before, issuer
+// and audience claims of a token. The expiration is required,
+// so that a leaked token cannot be used forever.
+func (m *SecurityManager) verifyClaims(claims map[string]interface{}) error {
+	now := float64(m.now().Unix())
+
+	exp, ok := claims["exp"]
+	if !ok {
+		return yarpcerrors.UnauthenticatedErrorf("token has no expiration")
+	}
+	if t, ok := exp.(float64); !ok || now >= t {
+		return yarpcerrors.UnauthenticatedErrorf("token expired")
+	}
+
+	if nbf, ok := claims["nbf"]; ok {
+		if t, ok := nbf.(float64); !ok || now < t {
+			return yarpcerrors.UnauthenticatedErrorf("token not valid yet")
+		}
+	}
+
+	if len(m.issuer) != 0 {
+		if iss, _ := claims["iss"].(string); iss != m.issuer {
+			return yarpcerrors.UnauthenticatedErrorf("unexpected issuer:%s", iss)
+		}
+	}
+
+	if len(m.audience) != 0 {
+		found := false
+		for _, aud := range stringsClaim(claims, "aud") {
+			if aud == m.audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return yarpcerrors.UnauthenticatedErrorf("unexpected audience")
+		}
+	}
+	return nil
+}
+
+// newUser maps the claims of a token onto a user.
+// The roles of the claims which are not defined are ignored.
+func (m *SecurityManager) newUser(claims map[string]interface{}) *user {
+	username, _ := claims[m.claims.Username].(string)
+
+	var roles []*basic.Role
+	for _, name := range stringsClaim(claims, m.claims.Roles) {
+		if r, ok := m.roles[name]; ok {
+			roles = append(roles, r)
+		}
+	}
+
+	return &user{
+		username: username,
+		groups:   stringsClaim(claims, m.claims.Groups),
+		roles:    roles,
+	}
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// stringsClaim returns the value of a claim which is
+// either a string or a list of strings
+func stringsClaim(claims map[string]interface{}, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var result []string
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// Username returns the name of user
+func (u *user) Username() string {
+	return u.username
+}
+
+// IsPermitted returns if a procedure is permitted for user,
+// which is the case if any role of the user permits it
+func (u *user) IsPermitted(procedure string) bool {
+	for _, r := range u.roles {
+		if r.IsPermitted(procedure) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPermittedOnEntity returns if a procedure is permitted for user on
+// the entity. The procedure is permitted if it is permitted for user,
+// and the entity is owned by user or one of its groups, unless user
+// has an admin role.
+func (u *user) IsPermittedOnEntity(procedure string, entity *auth.Entity) bool {
+	if !u.IsPermitted(procedure) {
+		return false
+	}
+
+	for _, r := range u.roles {
+		if r.IsAdmin() {
+			return true
+		}
+	}
+	return entity.IsOwnedBy(u.username, u.groups)
+}
+
+// NewJWTSecurityManager returns SecurityManager
+func NewJWTSecurityManager(configPath string) (*SecurityManager, error) {
+	mConfig := &managerConfig{}
+	if err := config.Parse(mConfig, configPath); err != nil {
+		return nil, err
+	}
+
+	return newJWTSecurityManager(mConfig)
+}
+
+// helper method to create SecurityManager which makes test easier
+func newJWTSecurityManager(mConfig *managerConfig) (*SecurityManager, error) {
+	if err := basic.ValidateRoles(mConfig.Roles); err != nil {
+		return nil, err
+	}
+
+	keys, err := loadKeys(mConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := basic.NewRoles(mConfig.Roles)
+
+	if _, err := internalUserKey(mConfig.InternalUser, keys, roles); err != nil {
+		return nil, err
+	}
+
+	var defaultUser *user
+	if len(mConfig.DefaultRole) != 0 {
+		r, ok := roles[mConfig.DefaultRole]
+		if !ok {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"undefined default Role: %s", mConfig.DefaultRole)
+		}
+		defaultUser = &user{roles: []*basic.Role{r}}
+	}
+
+	return &SecurityManager{
+		issuer:      mConfig.Issuer,
+		audience:    mConfig.Audience,
+		keys:        keys,
+		claims:      mConfig.Claims.withDefaults(),
+		roles:       roles,
+		defaultUser: defaultUser,
+		now:         time.Now,
+	}, nil
+}
+
+// SecurityClient passes the tokens of the internal user,
+// which it signs with the key of the internal user
+type SecurityClient struct {
+	sync.Mutex
+
+	issuer       string
+	audience     string
+	claims       claimsConfig
+	internalUser *internalUserConfig
+	// key signing the tokens, nil if there is no internal user
+	key *key
+	// returns the current time, used to set the
+	// expiration of the tokens
+	now func() time.Time
+
+	// last signed token and its expiration
+	token      string
+	expiration time.Time
+}
+
+var _ auth.SecurityClient = &SecurityClient{}
+
+// GetCredentials returns the authorization header with a bearer token
+// of the internal user, or no header if there is no internal user
+func (c *SecurityClient) GetCredentials() map[string]string {
+	if c.key == nil {
+		return nil
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	now := c.now()
+	if len(c.token) == 0 || now.Add(_internalTokenTTL/2).After(c.expiration) {
+		c.expiration = now.Add(_internalTokenTTL)
+		c.token = c.sign(now, c.expiration)
+	}
+	return map[string]string{
+		_authorizationHeaderKey: _bearerPrefix + c.token,
+	}
+}
+
+// sign returns a token of the internal user, which
+// is valid from now until the expiration
+func (c *SecurityClient) sign(now time.Time, expiration time.Time) string {
+	claims := map[string]interface{}{
+		c.claims.Username: c.internalUser.Username,
+		c.claims.Roles:    c.internalUser.Roles,
+		"iat":             now.Unix(),
+		"exp":             expiration.Unix(),
+	}
+	if len(c.issuer) != 0 {
+		claims["iss"] = c.issuer
+	}
+	if len(c.audience) != 0 {
+		claims["aud"] = c.audience
+	}
+
+	signed := encodeSegment(tokenHeader{Alg: _algHS256, Kid: c.key.id}) +
+		_tokenSeparator + encodeSegment(claims)
+	return signed + _tokenSeparator +
+		base64.RawURLEncoding.EncodeToString(c.key.sign([]byte(signed)))
+}
+
+// encodeSegment encodes a JSON segment of a token in base64url
+func encodeSegment(v interface{}) string {
+	// the segments are plain structs and maps,
+	// which are always marshaled successfully
+	data, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// internalUserKey returns the key which signs the tokens of the
+// internal user, and nil if there is no internal user
+func internalUserKey(
+	internalUser *internalUserConfig,
+	keys map[string]*key,
+	roles map[string]*basic.Role,
+) (*key, error) {
+	if internalUser == nil {
+		return nil, nil
+	}
+
+	if len(internalUser.Username) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"no Username specified for internal user")
+	}
+
+	for _, r := range internalUser.Roles {
+		if _, ok := roles[r]; !ok {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"internal user: %s has undefined Role: %s",
+				internalUser.Username,
+				r,
+			)
+		}
+	}
+
+	// only a shared secret can sign tokens,
+	// the private keys of RS256 are not known
+	k, ok := keys[internalUser.KeyID]
+	if !ok || k.algorithm != _algHS256 {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"internal user: %s needs a %s key, got key:%s",
+			internalUser.Username,
+			_algHS256,
+			internalUser.KeyID,
+		)
+	}
+	return k, nil
+}
+
+// NewJWTSecurityClient returns SecurityClient
+func NewJWTSecurityClient(configPath string) (*SecurityClient, error) {
+	mConfig := &managerConfig{}
+	if err := config.Parse(mConfig, configPath); err != nil {
+		return nil, err
+	}
+
+	return newJWTSecurityClient(mConfig)
+}
+
+// helper method to create SecurityClient which makes test easier
+func newJWTSecurityClient(mConfig *managerConfig) (*SecurityClient, error) {
+	if err := basic.ValidateRoles(mConfig.Roles); err != nil {
+		return nil, err
+	}
+
+	keys, err := loadKeys(mConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := internalUserKey(
+		mConfig.InternalUser,
+		keys,
+		basic.NewRoles(mConfig.Roles),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecurityClient{
+		issuer:       mConfig.Issuer,
+		audience:     mConfig.Audience,
+		claims:       mConfig.Claims.withDefaults(),
+		internalUser: mConfig.InternalUser,
+		key:          k,
+		now:          time.Now,
+	}, nil
+}
@@ -0,0 +1,336 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/auth/impl/basic"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	_testConfigPath     = "testdata/test_jwt_auth_config.yaml"
+	_testPrivateKeyPath = "testdata/test_rsa_key.pem"
+	_testSecret         = "secret-of-a-test-key-of-32-bytes"
+
+	_testGetJob      = "peloton.api.v1alpha.job.stateless.svc.JobService::GetJob"
+	_testGetJobCache = "peloton.api.v1alpha.job.stateless.svc.JobService::GetJobCache"
+	_testQueryJobs   = "peloton.api.v1alpha.job.stateless.svc.JobService::QueryJobs"
+	_testDeleteJob   = "peloton.api.v1alpha.job.stateless.svc.JobService::DeleteJob"
+)
+
+type SecurityManagerTestSuite struct {
+	suite.Suite
+
+	m          *SecurityManager
+	privateKey *rsa.PrivateKey
+	now        time.Time
+}
+
+func (suite *SecurityManagerTestSuite) SetupTest() {
+	m, err := NewJWTSecurityManager(_testConfigPath)
+	suite.NoError(err)
+	suite.now = time.Unix(1560000000, 0)
+	m.now = func() time.Time { return suite.now }
+	suite.m = m
+
+	data, err := ioutil.ReadFile(_testPrivateKeyPath)
+	suite.NoError(err)
+	block, _ := pem.Decode(data)
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	suite.NoError(err)
+	suite.privateKey = privateKey.(*rsa.PrivateKey)
+}
+
+// claims returns valid claims of a token for user1
+func (suite *SecurityManagerTestSuite) claims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":         "user1",
+		"iss":         "peloton-test",
+		"aud":         []string{"peloton", "other"},
+		"exp":         suite.now.Add(time.Hour).Unix(),
+		"nbf":         suite.now.Add(-time.Hour).Unix(),
+		"roles":       []string{"role1", "undefined-role"},
+		"ldap_groups": []string{"team1"},
+	}
+}
+
+// sign returns a token with the claims signed by the algorithm and key
+func (suite *SecurityManagerTestSuite) sign(
+	alg string,
+	kid string,
+	claims map[string]interface{}) string {
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		suite.NoError(err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signed := encode(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) +
+		"." + encode(claims)
+
+	var signature []byte
+	switch alg {
+	case _algHS256:
+		mac := hmac.New(sha256.New, []byte("test-secret-of-at-least-32-bytes"))
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case _algRS256:
+		hashed := sha256.Sum256([]byte(signed))
+		var err error
+		signature, err = rsa.SignPKCS1v15(
+			rand.Reader, suite.privateKey, crypto.SHA256, hashed[:])
+		suite.NoError(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (suite *SecurityManagerTestSuite) authenticate(token string) (auth.User, error) {
+	return suite.m.Authenticate(&testToken{authorization: "Bearer " + token})
+}
+
+// TestAuthenticateHS256 tests the claims of a HS256 token are
+// mapped onto the user
+func (suite *SecurityManagerTestSuite) TestAuthenticateHS256() {
+	u, err := suite.authenticate(
+		suite.sign(_algHS256, "hmac-key", suite.claims()))
+	suite.NoError(err)
+
+	suite.True(u.IsPermitted(_testGetJob))
+	suite.False(u.IsPermitted(_testGetJobCache))
+	suite.False(u.IsPermitted(_testDeleteJob))
+
+	// user1 is in the group team1
+	suite.True(u.IsPermittedOnEntity(
+		_testGetJob, &auth.Entity{OwningTeam: "team1"}))
+	suite.True(u.IsPermittedOnEntity(
+		_testGetJob, &auth.Entity{Owner: "user1"}))
+	suite.False(u.IsPermittedOnEntity(
+		_testGetJob, &auth.Entity{OwningTeam: "team2"}))
+}
+
+// TestAuthenticateRS256 tests RS256 tokens verified by the PEM
+// public key and by the JWKS key
+func (suite *SecurityManagerTestSuite) TestAuthenticateRS256() {
+	claims := suite.claims()
+	// a single role can be a string
+	claims["roles"] = "role2"
+
+	for _, kid := range []string{"rsa-pem-key", "rsa-key"} {
+		u, err := suite.authenticate(suite.sign(_algRS256, kid, claims))
+		suite.NoError(err, kid)
+
+		suite.True(u.IsPermitted(_testDeleteJob))
+		// role2 is an admin role
+		suite.True(u.IsPermittedOnEntity(
+			_testDeleteJob, &auth.Entity{OwningTeam: "team2"}))
+	}
+}
+
+// TestAuthenticateDefaultUser tests that requests without
+// a token get the default role
+func (suite *SecurityManagerTestSuite) TestAuthenticateDefaultUser() {
+	u, err := suite.m.Authenticate(&testToken{})
+	suite.NoError(err)
+	suite.True(u.IsPermitted(_testQueryJobs))
+	suite.False(u.IsPermitted(_testGetJob))
+
+	suite.m.defaultUser = nil
+	_, err = suite.m.Authenticate(&testToken{})
+	suite.Error(err)
+}
+
+// TestAuthenticateInvalidToken tests the tokens which are rejected
+func (suite *SecurityManagerTestSuite) TestAuthenticateInvalidToken() {
+	valid := suite.sign(_algHS256, "hmac-key", suite.claims())
+
+	withClaim := func(name string, value interface{}) string {
+		claims := suite.claims()
+		claims[name] = value
+		return suite.sign(_algHS256, "hmac-key", claims)
+	}
+
+	noExpiration := suite.claims()
+	delete(noExpiration, "exp")
+
+	tests := map[string]string{
+		"no expiration":         suite.sign(_algHS256, "hmac-key", noExpiration),
+		"malformed":             "a.b",
+		"invalid signature":     valid[:len(valid)-4] + "AAAA",
+		"expired":               withClaim("exp", suite.now.Unix()),
+		"not valid yet":         withClaim("nbf", suite.now.Add(time.Minute).Unix()),
+		"unexpected issuer":     withClaim("iss", "other-issuer"),
+		"unexpected audience":   withClaim("aud", "other"),
+		"unknown key":           suite.sign(_algHS256, "unknown-key", suite.claims()),
+		"algorithm of key":      suite.sign(_algHS256, "rsa-key", suite.claims()),
+		"unsupported algorithm": suite.sign("none", "hmac-key", suite.claims()),
+	}
+	for name, token := range tests {
+		_, err := suite.authenticate(token)
+		suite.Error(err, name)
+	}
+
+	// the token must be a bearer token
+	_, err := suite.m.Authenticate(&testToken{authorization: valid})
+	suite.Error(err)
+}
+
+// TestCreateJWTSecurityManagerErr tests the invalid configs
+func (suite *SecurityManagerTestSuite) TestCreateJWTSecurityManagerErr() {
+	roles := []*basic.RoleConfig{{Role: "role1"}}
+	hmacKey := &keyConfig{ID: "key", Algorithm: _algHS256, Secret: _testSecret}
+
+	tests := map[string]*managerConfig{
+		"no key": {Roles: roles},
+		"no secret": {
+			Keys: []*keyConfig{{ID: "key", Algorithm: _algHS256}},
+		},
+		"short secret": {
+			Keys: []*keyConfig{{ID: "key", Algorithm: _algHS256, Secret: "secret"}},
+		},
+		"unsupported algorithm": {
+			Keys: []*keyConfig{{ID: "key", Algorithm: "ES256"}},
+		},
+		"no public key": {
+			Keys: []*keyConfig{{ID: "key", Algorithm: _algRS256}},
+		},
+		"same key twice": {Keys: []*keyConfig{hmacKey, hmacKey}},
+		"undefined default role": {
+			Keys:        []*keyConfig{hmacKey},
+			Roles:       roles,
+			DefaultRole: "role2",
+		},
+		"invalid rule": {
+			Keys:  []*keyConfig{hmacKey},
+			Roles: []*basic.RoleConfig{{Role: "role1", Accept: []string{"a:b:c"}}},
+		},
+		"internal user without name": {
+			Keys:         []*keyConfig{hmacKey},
+			InternalUser: &internalUserConfig{KeyID: "key"},
+		},
+		"internal user with undefined role": {
+			Keys:  []*keyConfig{hmacKey},
+			Roles: roles,
+			InternalUser: &internalUserConfig{
+				Username: "peloton",
+				Roles:    []string{"role2"},
+				KeyID:    "key",
+			},
+		},
+		"internal user with unknown key": {
+			Keys: []*keyConfig{hmacKey},
+			InternalUser: &internalUserConfig{
+				Username: "peloton",
+				KeyID:    "other-key",
+			},
+		},
+	}
+	for name, config := range tests {
+		_, err := newJWTSecurityManager(config)
+		suite.Error(err, name)
+	}
+
+	m, err := newJWTSecurityManager(&managerConfig{
+		Keys:  []*keyConfig{hmacKey},
+		Roles: roles,
+	})
+	suite.NoError(err)
+	suite.Equal(_defaultUsernameClaim, m.claims.Username)
+	suite.Equal(_defaultGroupsClaim, m.claims.Groups)
+	suite.Equal(_defaultRolesClaim, m.claims.Roles)
+}
+
+// TestSecurityClient tests that the tokens of the internal user
+// are authenticated, and signed again before they expire
+func (suite *SecurityManagerTestSuite) TestSecurityClient() {
+	c, err := NewJWTSecurityClient(_testConfigPath)
+	suite.NoError(err)
+	c.now = suite.m.now
+
+	credentials := c.GetCredentials()
+	u, err := suite.m.Authenticate(
+		&testToken{authorization: credentials[_authorizationHeaderKey]})
+	suite.NoError(err)
+	suite.Equal("peloton", u.Username())
+	// role2 is an admin role
+	suite.True(u.IsPermittedOnEntity(
+		_testDeleteJob, &auth.Entity{OwningTeam: "team2"}))
+
+	// the token is reused until half of its lifetime has passed
+	suite.now = suite.now.Add(_internalTokenTTL / 4)
+	suite.Equal(credentials, c.GetCredentials())
+
+	suite.now = suite.now.Add(_internalTokenTTL / 2)
+	renewed := c.GetCredentials()
+	suite.NotEqual(credentials, renewed)
+
+	suite.now = suite.now.Add(_internalTokenTTL / 2)
+	_, err = suite.m.Authenticate(
+		&testToken{authorization: renewed[_authorizationHeaderKey]})
+	suite.NoError(err)
+}
+
+// TestSecurityClientWithoutInternalUser tests that no credentials
+// are passed when there is no internal user
+func (suite *SecurityManagerTestSuite) TestSecurityClientWithoutInternalUser() {
+	c, err := newJWTSecurityClient(&managerConfig{
+		Keys: []*keyConfig{{ID: "key", Algorithm: _algHS256, Secret: _testSecret}},
+	})
+	suite.NoError(err)
+	suite.Empty(c.GetCredentials())
+}
+
+// TestNewKeyFromJWK tests the symmetric JSON web keys
+func (suite *SecurityManagerTestSuite) TestNewKeyFromJWK() {
+	encode := func(secret string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(secret))
+	}
+
+	k, err := newKeyFromJWK(&jsonWebKey{
+		Kty: _ktyOct,
+		Kid: "oct-key",
+		K:   encode(_testSecret),
+	})
+	suite.NoError(err)
+	suite.Equal(_algHS256, k.algorithm)
+
+	for name, secret := range map[string]string{
+		"empty secret": "",
+		"short secret": "secret",
+	} {
+		_, err := newKeyFromJWK(&jsonWebKey{
+			Kty: _ktyOct,
+			Kid: "oct-key",
+			K:   encode(secret),
+		})
+		suite.Error(err, name)
+	}
+}
+
+type testToken struct {
+	authorization string
+}
+
+func (t *testToken) Get(k string) (string, bool) {
+	if k == _authorizationHeaderKey {
+		return t.authorization, len(t.authorization) != 0
+	}
+
+	return "", false
+}
+
+func TestSecurityManagerTestSuite(t *testing.T) {
+	suite.Run(t, new(SecurityManagerTestSuite))
+}
@@ -0,0 +1,259 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_algHS256 = "HS256"
+	_algRS256 = "RS256"
+
+	// key types of the JSON Web Keys
+	_ktyRSA = "RSA"
+	_ktyOct = "oct"
+
+	// minimum size in bytes of a HS256 secret, which is the
+	// size of the hash output as required by RFC 7518
+	_minSecretSize = 32
+)
+
+// key verifies the signature of the tokens signed with its algorithm
+type key struct {
+	id        string
+	algorithm string
+	// secret of a HS256 key
+	secret []byte
+	// public key of a RS256 key
+	publicKey *rsa.PublicKey
+}
+
+// verify returns whether the signature is valid for the signed content
+func (k *key) verify(signed, signature []byte) bool {
+	switch k.algorithm {
+	case _algHS256:
+		return hmac.Equal(signature, k.sign(signed))
+	case _algRS256:
+		hashed := sha256.Sum256(signed)
+		return rsa.VerifyPKCS1v15(
+			k.publicKey,
+			crypto.SHA256,
+			hashed[:],
+			signature) == nil
+	}
+	return false
+}
+
+// sign returns the signature of the signed content,
+// which only a HS256 key can produce
+func (k *key) sign(signed []byte) []byte {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write(signed)
+	return mac.Sum(nil)
+}
+
+// loadKeys returns the keys of the config by ID
+func loadKeys(config *managerConfig) (map[string]*key, error) {
+	keys := make(map[string]*key)
+	addKey := func(k *key) error {
+		if _, ok := keys[k.id]; ok {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"same key defined more than once. key:%s", k.id)
+		}
+		keys[k.id] = k
+		return nil
+	}
+
+	for _, keyConfig := range config.Keys {
+		k, err := newKey(keyConfig)
+		if err != nil {
+			return nil, err
+		}
+		if err := addKey(k); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(config.JWKSFile) != 0 {
+		jwks, err := loadJWKS(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range jwks {
+			if err := addKey(k); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf("no key specified")
+	}
+	return keys, nil
+}
+
+func newKey(config *keyConfig) (*key, error) {
+	switch config.Algorithm {
+	case _algHS256:
+		return newHMACKey(config.ID, []byte(config.Secret))
+	case _algRS256:
+		publicKey, err := loadPublicKey(config.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return &key{
+			id:        config.ID,
+			algorithm: _algRS256,
+			publicKey: publicKey,
+		}, nil
+	default:
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"unsupported algorithm:%s for key:%s",
+			config.Algorithm,
+			config.ID,
+		)
+	}
+}
+
+// newHMACKey returns a HS256 key, the secret must be long
+// enough that the signatures cannot be forged by brute force
+func newHMACKey(id string, secret []byte) (*key, error) {
+	if len(secret) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"no secret specified for key:%s", id)
+	}
+	if len(secret) < _minSecretSize {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"secret of key:%s is shorter than %d bytes", id, _minSecretSize)
+	}
+	return &key{
+		id:        id,
+		algorithm: _algHS256,
+		secret:    secret,
+	}, nil
+}
+
+// loadPublicKey reads a PEM encoded RSA public key,
+// either in PKIX or in PKCS1 format
+func loadPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"no PEM data found in public key file:%s", path)
+	}
+
+	if publicKey, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return publicKey, nil
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"public key file:%s does not contain a RSA public key", path)
+	}
+	return rsaPublicKey, nil
+}
+
+// jsonWebKeySet is a JSON Web Key Set as defined by RFC 7517
+type jsonWebKeySet struct {
+	Keys []*jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// modulus and exponent of a RSA key
+	N string `json:"n"`
+	E string `json:"e"`
+	// secret of a symmetric key
+	K string `json:"k"`
+}
+
+// loadJWKS reads the keys of a JSON Web Key Set file. The keys which
+// are not used for signatures are skipped.
+func loadJWKS(path string) ([]*key, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks jsonWebKeySet
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, err
+	}
+
+	var keys []*key
+	for _, jwk := range jwks.Keys {
+		if len(jwk.Use) != 0 && jwk.Use != "sig" {
+			continue
+		}
+		k, err := newKeyFromJWK(jwk)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func newKeyFromJWK(jwk *jsonWebKey) (*key, error) {
+	switch jwk.Kty {
+	case _ktyRSA:
+		if len(jwk.Alg) != 0 && jwk.Alg != _algRS256 {
+			break
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &key{
+			id:        jwk.Kid,
+			algorithm: _algRS256,
+			publicKey: &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			},
+		}, nil
+	case _ktyOct:
+		if len(jwk.Alg) != 0 && jwk.Alg != _algHS256 {
+			break
+		}
+		secret, err := base64.RawURLEncoding.DecodeString(jwk.K)
+		if err != nil {
+			return nil, err
+		}
+		return newHMACKey(jwk.Kid, secret)
+	}
+
+	return nil, yarpcerrors.InvalidArgumentErrorf(
+		"unsupported JSON web key:%s of type:%s and algorithm:%s",
+		jwk.Kid,
+		jwk.Kty,
+		jwk.Alg,
+	)
+}
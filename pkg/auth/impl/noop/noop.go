@@ -14,12 +14,37 @@ func (m *SecurityManager) Authenticate(token auth.Token) (auth.User, error) {
 
 type noopUser struct{}
 
+// Username always return an empty name
+func (u *noopUser) Username() string {
+	return ""
+}
+
 // IsPermitted always return true
 func (u *noopUser) IsPermitted(procedure string) bool {
 	return true
 }
 
+// IsPermittedOnEntity always return true
+func (u *noopUser) IsPermittedOnEntity(procedure string, entity *auth.Entity) bool {
+	return true
+}
+
 // NewNoopSecurityManager returns SecurityManager
 func NewNoopSecurityManager() *SecurityManager {
 	return &SecurityManager{}
 }
+
+// SecurityClient passes no credentials
+type SecurityClient struct{}
+
+var _ auth.SecurityClient = &SecurityClient{}
+
+// GetCredentials always returns no header
+func (c *SecurityClient) GetCredentials() map[string]string {
+	return nil
+}
+
+// NewNoopSecurityClient returns SecurityClient
+func NewNoopSecurityClient() *SecurityClient {
+	return &SecurityClient{}
+}
@@ -3,6 +3,8 @@ package noop
 import (
 	"testing"
 
+	"github.com/uber/peloton/pkg/auth"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,4 +17,15 @@ func TestNoopSecurityManager(t *testing.T) {
 	assert.True(t, u.IsPermitted("peloton.api.v1alpha.job.stateless.svc.JobService::CreateJob"))
 	// even if the procedure name is not valid, still should pass permit check
 	assert.True(t, u.IsPermitted(""))
+
+	// even if the entity is owned by others, still should pass permit check
+	assert.True(t, u.IsPermittedOnEntity(
+		"peloton.api.v1alpha.job.stateless.svc.JobService::DeleteJob",
+		&auth.Entity{OwningTeam: "team1"},
+	))
+}
+
+func TestNoopSecurityClient(t *testing.T) {
+	c := NewNoopSecurityClient()
+	assert.Empty(t, c.GetCredentials())
 }
@@ -10,6 +10,8 @@ const (
 	NOOP = Type("NOOP")
 	// BASIC would use username and password for auth
 	BASIC = Type("BASIC")
+	// JWT would use signed bearer tokens for auth
+	JWT = Type("JWT")
 )
 
 // Token is used by SecurityManager to authenticate a user
@@ -24,9 +26,66 @@ type SecurityManager interface {
 	Authenticate(token Token) (User, error)
 }
 
+// SecurityClient provides the credentials of a Peloton component,
+// which are passed on its calls to the other components
+type SecurityClient interface {
+	// GetCredentials returns the headers which authenticate
+	// the component with the SecurityManager of the callee
+	GetCredentials() map[string]string
+}
+
 // User includes authorization related methods
 type User interface {
+	// Username returns the name of the user,
+	// which is empty for an anonymous user
+	Username() string
+
 	// IsPermitted returns whether user can
 	// access the specified procedure
 	IsPermitted(procedure string) bool
+
+	// IsPermittedOnEntity returns whether user can
+	// access the specified procedure on the entity
+	IsPermittedOnEntity(procedure string, entity *Entity) bool
+}
+
+// Entity is the target of a procedure, such as a job
+// or a resource pool, which is owned by a user or groups
+type Entity struct {
+	// Owner is the user who owns the entity
+	Owner string
+	// OwningTeam is the team which owns the entity
+	OwningTeam string
+	// LdapGroups are the LDAP groups which own the entity
+	LdapGroups []string
+}
+
+// IsOwnedBy returns whether the entity is owned by the user
+// or one of the groups. An entity without any owner is owned
+// by everyone, so that the entities created before the owners
+// were enforced are still accessible.
+func (e *Entity) IsOwnedBy(username string, groups []string) bool {
+	if len(e.Owner) == 0 && len(e.OwningTeam) == 0 && len(e.LdapGroups) == 0 {
+		return true
+	}
+
+	if len(username) != 0 && username == e.Owner {
+		return true
+	}
+
+	for _, group := range groups {
+		// an empty group does not match an entity without owning team
+		if len(group) == 0 {
+			continue
+		}
+		if group == e.OwningTeam {
+			return true
+		}
+		for _, ldapGroup := range e.LdapGroups {
+			if group == ldapGroup {
+				return true
+			}
+		}
+	}
+	return false
 }
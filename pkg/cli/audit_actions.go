@@ -0,0 +1,81 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	auditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+)
+
+const (
+	auditRecordFormatHeader = "Time\tUser\tProcedure\tEntities\tOutcome\tMessage\t\n"
+	auditRecordFormatBody   = "%s\t%s\t%s\t%s\t%s\t%s\t\n"
+	auditEntitySeparator    = ","
+)
+
+// AuditQueryAction is the action for querying the records of the calls
+// which mutated the state of the cluster, filtered by user, entity and
+// time range
+func (c *Client) AuditQueryAction(
+	user string,
+	entity string,
+	startTime string,
+	endTime string,
+	limit uint32) error {
+	response, err := c.auditClient.QueryAuditRecords(
+		c.ctx,
+		&auditsvc.QueryAuditRecordsRequest{
+			User:      user,
+			Entity:    entity,
+			StartTime: startTime,
+			EndTime:   endTime,
+			Limit:     limit,
+		})
+	if err != nil {
+		return err
+	}
+
+	printAuditQueryResponse(response, c.Debug)
+	return nil
+}
+
+func printAuditQueryResponse(
+	r *auditsvc.QueryAuditRecordsResponse,
+	debug bool) {
+	if debug {
+		printResponseJSON(r)
+	} else {
+		if len(r.GetRecords()) == 0 {
+			fmt.Fprintf(tabWriter, "No audit records found\n")
+			return
+		}
+		fmt.Fprintf(tabWriter, auditRecordFormatHeader)
+		for _, record := range r.GetRecords() {
+			fmt.Fprintf(
+				tabWriter,
+				auditRecordFormatBody,
+				record.GetTime(),
+				record.GetUser(),
+				record.GetProcedure(),
+				strings.Join(record.GetEntities(), auditEntitySeparator),
+				record.GetOutcome(),
+				record.GetMessage(),
+			)
+		}
+	}
+	tabWriter.Flush()
+}
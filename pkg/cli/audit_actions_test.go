@@ -0,0 +1,103 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	auditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+	auditmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type auditActionsTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	auditClient *auditmocks.MockAuditServiceYARPCClient
+	ctx         context.Context
+}
+
+func TestAuditActions(t *testing.T) {
+	suite.Run(t, new(auditActionsTestSuite))
+}
+
+func (suite *auditActionsTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.auditClient = auditmocks.NewMockAuditServiceYARPCClient(suite.ctrl)
+	suite.ctx = context.Background()
+}
+
+func (suite *auditActionsTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *auditActionsTestSuite) TestAuditQueryAction() {
+	c := Client{
+		Debug:       false,
+		auditClient: suite.auditClient,
+		dispatcher:  nil,
+		ctx:         suite.ctx,
+	}
+
+	suite.auditClient.EXPECT().
+		QueryAuditRecords(gomock.Any(), &auditsvc.QueryAuditRecordsRequest{
+			User:      "user1",
+			Entity:    "job:job1",
+			StartTime: "2019-06-01T00:00:00Z",
+			EndTime:   "2019-06-02T00:00:00Z",
+			Limit:     10,
+		}).
+		Return(&auditsvc.QueryAuditRecordsResponse{
+			Records: []*audit.AuditRecord{
+				{
+					Time:      "2019-06-01T12:00:00Z",
+					User:      "user1",
+					Procedure: "peloton.api.v0.job.JobManager::Delete",
+					Entities:  []string{"job:job1"},
+					Outcome:   "ok",
+				},
+			},
+		}, nil)
+	suite.NoError(c.AuditQueryAction(
+		"user1",
+		"job:job1",
+		"2019-06-01T00:00:00Z",
+		"2019-06-02T00:00:00Z",
+		10,
+	))
+
+	// Test no records
+	suite.auditClient.EXPECT().
+		QueryAuditRecords(gomock.Any(), gomock.Any()).
+		Return(&auditsvc.QueryAuditRecordsResponse{}, nil)
+	suite.NoError(c.AuditQueryAction("", "", "", "", 0))
+
+	// Test QueryAuditRecords error
+	suite.auditClient.EXPECT().
+		QueryAuditRecords(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake QueryAuditRecords error"))
+	suite.Error(c.AuditQueryAction("", "", "", "", 0))
+}
+
+func (suite *auditActionsTestSuite) TestPrintAuditQueryResponseDebug() {
+	printAuditQueryResponse(&auditsvc.QueryAuditRecordsResponse{
+		Records: []*audit.AuditRecord{{User: "user1"}},
+	}, true)
+}
@@ -29,8 +29,11 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	updatesvc "github.com/uber/peloton/.gen/peloton/api/v0/update/svc"
 	volume_svc "github.com/uber/peloton/.gen/peloton/api/v0/volume/svc"
+	auditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
+	workflowsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow/svc"
 	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
+	rebalancesvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/rebalance/svc"
 	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
 	hostmgr_svc "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
@@ -53,6 +56,9 @@ type Client struct {
 	volumeClient    volume_svc.VolumeServiceYARPCClient
 	hostMgrClient   hostmgr_svc.InternalHostServiceYARPCClient
 	hostClient      hostsvc.HostServiceYARPCClient
+	auditClient     auditsvc.AuditServiceYARPCClient
+	rebalanceClient rebalancesvc.RebalanceServiceYARPCClient
+	workflowClient  workflowsvc.WorkflowServiceYARPCClient
 	dispatcher      *yarpc.Dispatcher
 	ctx             context.Context
 	cancelFunc      context.CancelFunc
@@ -64,7 +70,7 @@ type Client struct {
 func New(
 	discovery leader.Discovery,
 	timeout time.Duration,
-	authConfig *middleware.BasicAuthConfig,
+	authMiddleware middleware.OutboundMiddleware,
 	debug bool) (*Client, error) {
 
 	jobmgrURL, err := discovery.GetAppURL(common.JobManagerRole)
@@ -84,8 +90,6 @@ func New(
 
 	t := grpc.NewTransport()
 
-	authMiddleware := middleware.NewBasicAuthOutboundMiddleware(authConfig)
-
 	dispatcher := yarpc.NewDispatcher(yarpc.Config{
 		Name: common.PelotonCLI,
 		Outbounds: yarpc.Outbounds{
@@ -146,6 +150,15 @@ func New(
 		watchClient: watchsvc.NewWatchServiceYARPCClient(
 			dispatcher.ClientConfig(common.PelotonJobManager),
 		),
+		auditClient: auditsvc.NewAuditServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager),
+		),
+		rebalanceClient: rebalancesvc.NewRebalanceServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager),
+		),
+		workflowClient: workflowsvc.NewWorkflowServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager),
+		),
 		dispatcher: dispatcher,
 		ctx:        ctx,
 		cancelFunc: cancelFunc,
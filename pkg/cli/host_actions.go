@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	host "github.com/uber/peloton/.gen/peloton/api/v0/host"
 	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
@@ -28,11 +29,14 @@ import (
 )
 
 const (
-	hostQueryFormatHeader = "Hostname\tIP\tState\n"
-	hostQueryFormatBody   = "%s\t%s\t%s\n"
+	hostQueryFormatHeader = "Hostname\tIP\tState\tBlocking Jobs\n"
+	hostQueryFormatBody   = "%s\t%s\t%s\t%s\n"
 	hostSeparator         = ","
 	getHostsFormatHeader  = "Hostname\tCPU\tGPU\tMEM\tDisk\tState\t\n"
 	getHostsFormatBody    = "%s\t%.2f\t%.2f\t%.2f MB\t%.2f MB\t%s\t\n"
+
+	maintenanceWindowFormatHeader = "ID\tHosts\tStart\tDuration\tState\t\n"
+	maintenanceWindowFormatBody   = "%s\t%s\t%s\t%s\t%s\t\n"
 )
 
 // HostMaintenanceStartAction is the action for starting host maintenance. StartMaintenance puts the host(s)
@@ -82,6 +86,90 @@ func (c *Client) HostMaintenanceCompleteAction(hosts string) error {
 	return nil
 }
 
+// HostMaintenanceScheduleAction is the action for scheduling a maintenance
+// window for a list of hosts. No long running tasks are placed on the hosts
+// until the window opens, at which point the hosts are drained. The hosts
+// are brought back UP once the window closes.
+func (c *Client) HostMaintenanceScheduleAction(
+	hosts string,
+	startTime string,
+	durationSecs uint32) error {
+	hostnames, err := c.ExtractHostnames(hosts, hostSeparator)
+	if err != nil {
+		return err
+	}
+
+	request := &host_svc.ScheduleMaintenanceRequest{
+		Hostnames:    hostnames,
+		StartTime:    startTime,
+		DurationSecs: durationSecs,
+	}
+	response, err := c.hostClient.ScheduleMaintenance(c.ctx, request)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(tabWriter, "Scheduled maintenance window %s\n",
+		response.GetWindow().GetId())
+	tabWriter.Flush()
+	return nil
+}
+
+// HostMaintenanceWindowsAction is the action for listing the
+// scheduled and active maintenance windows
+func (c *Client) HostMaintenanceWindowsAction() error {
+	response, err := c.hostClient.QueryMaintenanceWindows(
+		c.ctx,
+		&host_svc.QueryMaintenanceWindowsRequest{})
+	if err != nil {
+		return err
+	}
+
+	printMaintenanceWindowsResponse(response, c.Debug)
+	return nil
+}
+
+func printMaintenanceWindowsResponse(
+	r *host_svc.QueryMaintenanceWindowsResponse,
+	debug bool) {
+	if debug {
+		printResponseJSON(r)
+	} else {
+		if len(r.GetWindows()) == 0 {
+			fmt.Fprintf(tabWriter, "No maintenance windows found\n")
+			return
+		}
+		fmt.Fprintf(tabWriter, maintenanceWindowFormatHeader)
+		for _, w := range r.GetWindows() {
+			fmt.Fprintf(
+				tabWriter,
+				maintenanceWindowFormatBody,
+				w.GetId(),
+				strings.Join(w.GetHostnames(), hostSeparator),
+				w.GetStartTime(),
+				time.Duration(w.GetDurationSecs())*time.Second,
+				w.GetState(),
+			)
+		}
+	}
+	tabWriter.Flush()
+}
+
+// HostMaintenanceCancelAction is the action for cancelling a maintenance
+// window. The hosts of an active window are brought back UP.
+func (c *Client) HostMaintenanceCancelAction(id string) error {
+	_, err := c.hostClient.CancelMaintenanceWindow(
+		c.ctx,
+		&host_svc.CancelMaintenanceWindowRequest{Id: id})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(tabWriter, "Maintenance window %s cancelled\n", id)
+	tabWriter.Flush()
+	return nil
+}
+
 // HostQueryAction is the action for querying hosts by states. This can be to used to monitor the state of the host(s)
 // Eg. When a list of hosts are put into maintenance (`host maintenance start`).
 // A host, at any given time, will be in one of the following states
@@ -120,12 +208,17 @@ func printHostQueryResponse(r *host_svc.QueryHostsResponse, debug bool) {
 		}
 		fmt.Fprintf(tabWriter, hostQueryFormatHeader)
 		for _, h := range r.GetHostInfos() {
+			var blockingJobs []string
+			for _, jobID := range h.GetBlockingJobs() {
+				blockingJobs = append(blockingJobs, jobID.GetValue())
+			}
 			fmt.Fprintf(
 				tabWriter,
 				hostQueryFormatBody,
 				h.GetHostname(),
 				h.GetIp(),
 				h.GetState(),
+				strings.Join(blockingJobs, hostSeparator),
 			)
 		}
 	}
@@ -128,6 +128,103 @@ func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceCompleteAction()
 	suite.Error(err)
 }
 
+func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceScheduleAction() {
+	c := Client{
+		Debug:      false,
+		hostClient: suite.mockHostmgr,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+
+	suite.mockHostmgr.EXPECT().
+		ScheduleMaintenance(gomock.Any(), &hostsvc.ScheduleMaintenanceRequest{
+			Hostnames:    []string{"hostname"},
+			StartTime:    "2019-01-01T00:00:00Z",
+			DurationSecs: 3600,
+		}).
+		Return(&hostsvc.ScheduleMaintenanceResponse{
+			Window: &host.MaintenanceWindow{Id: "window"},
+		}, nil)
+	err := c.HostMaintenanceScheduleAction(
+		"hostname", "2019-01-01T00:00:00Z", 3600)
+	suite.NoError(err)
+
+	// Test ScheduleMaintenance error
+	suite.mockHostmgr.EXPECT().
+		ScheduleMaintenance(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake ScheduleMaintenance error"))
+	err = c.HostMaintenanceScheduleAction(
+		"hostname", "2019-01-01T00:00:00Z", 3600)
+	suite.Error(err)
+
+	// Test empty hostname error
+	err = c.HostMaintenanceScheduleAction("", "2019-01-01T00:00:00Z", 3600)
+	suite.Error(err)
+}
+
+func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceWindowsAction() {
+	c := Client{
+		Debug:      false,
+		hostClient: suite.mockHostmgr,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+
+	suite.mockHostmgr.EXPECT().
+		QueryMaintenanceWindows(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.QueryMaintenanceWindowsResponse{
+			Windows: []*host.MaintenanceWindow{
+				{
+					Id:           "window",
+					Hostnames:    []string{"host1", "host2"},
+					StartTime:    "2019-01-01T00:00:00Z",
+					DurationSecs: 3600,
+					State:        host.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+				},
+			},
+		}, nil)
+	err := c.HostMaintenanceWindowsAction()
+	suite.NoError(err)
+
+	// Test no windows
+	suite.mockHostmgr.EXPECT().
+		QueryMaintenanceWindows(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.QueryMaintenanceWindowsResponse{}, nil)
+	err = c.HostMaintenanceWindowsAction()
+	suite.NoError(err)
+
+	// Test QueryMaintenanceWindows error
+	suite.mockHostmgr.EXPECT().
+		QueryMaintenanceWindows(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake QueryMaintenanceWindows error"))
+	err = c.HostMaintenanceWindowsAction()
+	suite.Error(err)
+}
+
+func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceCancelAction() {
+	c := Client{
+		Debug:      false,
+		hostClient: suite.mockHostmgr,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+
+	suite.mockHostmgr.EXPECT().
+		CancelMaintenanceWindow(gomock.Any(), &hostsvc.CancelMaintenanceWindowRequest{
+			Id: "window",
+		}).
+		Return(&hostsvc.CancelMaintenanceWindowResponse{}, nil)
+	err := c.HostMaintenanceCancelAction("window")
+	suite.NoError(err)
+
+	// Test CancelMaintenanceWindow error
+	suite.mockHostmgr.EXPECT().
+		CancelMaintenanceWindow(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake CancelMaintenanceWindow error"))
+	err = c.HostMaintenanceCancelAction("window")
+	suite.Error(err)
+}
+
 func (suite *hostmgrActionsTestSuite) TestClientHostQueryAction() {
 	c := Client{
 		Debug:      false,
@@ -8,17 +8,21 @@ import (
 )
 
 const (
-	_usernameHeader = "username"
-	_passwordHeader = "password"
+	_usernameHeader      = "username"
+	_passwordHeader      = "password"
+	_authorizationHeader = "authorization"
+	_bearerPrefix        = "Bearer "
 )
 
-type outboundMiddleware interface {
+// OutboundMiddleware is the middleware of all outbound requests
+type OutboundMiddleware interface {
 	middleware.UnaryOutbound
 	middleware.OnewayOutbound
 	middleware.StreamOutbound
 }
 
-var _ outboundMiddleware = &BasicAuthOutboundMiddleware{}
+var _ OutboundMiddleware = &BasicAuthOutboundMiddleware{}
+var _ OutboundMiddleware = &TokenAuthOutboundMiddleware{}
 
 // BasicAuthConfig is the config for basic auth
 type BasicAuthConfig struct {
@@ -71,3 +75,52 @@ func (m *BasicAuthOutboundMiddleware) addAuthToHeader(headers transport.Headers)
 
 	return headers
 }
+
+// TokenAuthConfig is the config for token auth
+type TokenAuthConfig struct {
+	// Token is the signed JWT sent as bearer token
+	Token string
+}
+
+// TokenAuthOutboundMiddleware provides token auth
+// support for all outbound requests
+type TokenAuthOutboundMiddleware struct {
+	config *TokenAuthConfig
+}
+
+// NewTokenAuthOutboundMiddleware creates TokenAuthOutboundMiddleware
+func NewTokenAuthOutboundMiddleware(config *TokenAuthConfig) *TokenAuthOutboundMiddleware {
+	if config != nil && len(config.Token) == 0 {
+		config = nil
+	}
+
+	return &TokenAuthOutboundMiddleware{
+		config: config,
+	}
+}
+
+// Call adds auth info to yarpc request header and relay the request
+func (m *TokenAuthOutboundMiddleware) Call(ctx context.Context, request *transport.Request, out transport.UnaryOutbound) (*transport.Response, error) {
+	request.Headers = m.addAuthToHeader(request.Headers)
+	return out.Call(ctx, request)
+}
+
+// CallOneway adds auth info to yarpc request header and relay the request
+func (m *TokenAuthOutboundMiddleware) CallOneway(ctx context.Context, request *transport.Request, out transport.OnewayOutbound) (transport.Ack, error) {
+	request.Headers = m.addAuthToHeader(request.Headers)
+	return out.CallOneway(ctx, request)
+}
+
+// CallStream adds auth info to yarpc request header and relay the request
+func (m *TokenAuthOutboundMiddleware) CallStream(ctx context.Context, request *transport.StreamRequest, out transport.StreamOutbound) (*transport.ClientStream, error) {
+	request.Meta.Headers = m.addAuthToHeader(request.Meta.Headers)
+	return out.CallStream(ctx, request)
+}
+
+func (m *TokenAuthOutboundMiddleware) addAuthToHeader(headers transport.Headers) transport.Headers {
+	if m.config == nil {
+		return headers
+	}
+
+	return headers.With(_authorizationHeader, _bearerPrefix+m.config.Token)
+}
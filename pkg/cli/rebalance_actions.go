@@ -0,0 +1,129 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/rebalance"
+	rebalancesvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/rebalance/svc"
+)
+
+const (
+	rebalanceRecommendationFormatHeader = "Pod Name\tCurrent Host\tTarget Host\tRank\t\n"
+	rebalanceRecommendationFormatBody   = "%s\t%s\t%s\t%d\t\n"
+	rebalanceFailureFormatHeader        = "Pod Name\tCurrent Host\tTarget Host\tError\t\n"
+	rebalanceFailureFormatBody          = "%s\t%s\t%s\t%s\t\n"
+)
+
+// RebalanceRecommendationsAction is the action for listing the
+// recommendations of the rebalancer to relocate the stateless pods
+func (c *Client) RebalanceRecommendationsAction(limit uint32) error {
+	response, err := c.rebalanceClient.GetRecommendations(
+		c.ctx,
+		&rebalancesvc.GetRecommendationsRequest{
+			Limit: limit,
+		})
+	if err != nil {
+		return err
+	}
+
+	printRebalanceRecommendationsResponse(response, c.Debug)
+	return nil
+}
+
+// RebalanceApplyAction is the action for migrating the pods of the
+// recommendations with the highest rank to their target host
+func (c *Client) RebalanceApplyAction(maxMigrations uint32) error {
+	response, err := c.rebalanceClient.ApplyRecommendations(
+		c.ctx,
+		&rebalancesvc.ApplyRecommendationsRequest{
+			MaxMigrations: maxMigrations,
+		})
+	if err != nil {
+		return err
+	}
+
+	printRebalanceApplyResponse(response, c.Debug)
+	return nil
+}
+
+func printRebalanceRecommendationsResponse(
+	r *rebalancesvc.GetRecommendationsResponse,
+	debug bool) {
+	if debug {
+		printResponseJSON(r)
+		return
+	}
+	if len(r.GetGenerateTime()) == 0 {
+		fmt.Fprintf(tabWriter, "Recommendations not computed yet\n")
+		tabWriter.Flush()
+		return
+	}
+	fmt.Fprintf(tabWriter, "Computed at %s\n", r.GetGenerateTime())
+	printRebalanceRecommendations(r.GetRecommendations(), "No recommendations found\n")
+}
+
+func printRebalanceApplyResponse(
+	r *rebalancesvc.ApplyRecommendationsResponse,
+	debug bool) {
+	if debug {
+		printResponseJSON(r)
+		return
+	}
+	printRebalanceRecommendations(r.GetMigrations(), "No pods migrated\n")
+	printRebalanceFailures(r.GetFailures())
+}
+
+func printRebalanceFailures(failures []*rebalance.MigrationFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	fmt.Fprintf(tabWriter, "\nFailed to migrate %d pods\n", len(failures))
+	fmt.Fprintf(tabWriter, rebalanceFailureFormatHeader)
+	for _, failure := range failures {
+		fmt.Fprintf(
+			tabWriter,
+			rebalanceFailureFormatBody,
+			failure.GetRecommendation().GetPodName().GetValue(),
+			failure.GetRecommendation().GetCurrentHost(),
+			failure.GetRecommendation().GetTargetHost(),
+			failure.GetMessage(),
+		)
+	}
+	tabWriter.Flush()
+}
+
+func printRebalanceRecommendations(
+	recommendations []*rebalance.Recommendation,
+	emptyMessage string) {
+	if len(recommendations) == 0 {
+		fmt.Fprintf(tabWriter, emptyMessage)
+		tabWriter.Flush()
+		return
+	}
+	fmt.Fprintf(tabWriter, rebalanceRecommendationFormatHeader)
+	for _, recommendation := range recommendations {
+		fmt.Fprintf(
+			tabWriter,
+			rebalanceRecommendationFormatBody,
+			recommendation.GetPodName().GetValue(),
+			recommendation.GetCurrentHost(),
+			recommendation.GetTargetHost(),
+			recommendation.GetRank(),
+		)
+	}
+	tabWriter.Flush()
+}
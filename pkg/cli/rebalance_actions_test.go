@@ -0,0 +1,143 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/rebalance"
+	rebalancesvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/rebalance/svc"
+	rebalancemocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/rebalance/svc/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type rebalanceActionsTestSuite struct {
+	suite.Suite
+	ctrl            *gomock.Controller
+	rebalanceClient *rebalancemocks.MockRebalanceServiceYARPCClient
+	ctx             context.Context
+	client          Client
+	recommendations []*rebalance.Recommendation
+}
+
+func TestRebalanceActions(t *testing.T) {
+	suite.Run(t, new(rebalanceActionsTestSuite))
+}
+
+func (suite *rebalanceActionsTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.rebalanceClient = rebalancemocks.NewMockRebalanceServiceYARPCClient(suite.ctrl)
+	suite.ctx = context.Background()
+	suite.client = Client{
+		Debug:           false,
+		rebalanceClient: suite.rebalanceClient,
+		dispatcher:      nil,
+		ctx:             suite.ctx,
+	}
+	suite.recommendations = []*rebalance.Recommendation{
+		{
+			PodName:     &v1alphapeloton.PodName{Value: "job-0"},
+			CurrentHost: "host1",
+			TargetHost:  "host2",
+			Rank:        10,
+		},
+	}
+}
+
+func (suite *rebalanceActionsTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *rebalanceActionsTestSuite) TestRebalanceRecommendationsAction() {
+	suite.rebalanceClient.EXPECT().
+		GetRecommendations(gomock.Any(), &rebalancesvc.GetRecommendationsRequest{
+			Limit: 10,
+		}).
+		Return(&rebalancesvc.GetRecommendationsResponse{
+			Recommendations: suite.recommendations,
+			GenerateTime:    "2019-06-01T12:00:00Z",
+		}, nil)
+	suite.NoError(suite.client.RebalanceRecommendationsAction(10))
+
+	// Test no recommendations
+	suite.rebalanceClient.EXPECT().
+		GetRecommendations(gomock.Any(), gomock.Any()).
+		Return(&rebalancesvc.GetRecommendationsResponse{
+			GenerateTime: "2019-06-01T12:00:00Z",
+		}, nil)
+	suite.NoError(suite.client.RebalanceRecommendationsAction(0))
+
+	// Test recommendations not computed
+	suite.rebalanceClient.EXPECT().
+		GetRecommendations(gomock.Any(), gomock.Any()).
+		Return(&rebalancesvc.GetRecommendationsResponse{}, nil)
+	suite.NoError(suite.client.RebalanceRecommendationsAction(0))
+
+	// Test GetRecommendations error
+	suite.rebalanceClient.EXPECT().
+		GetRecommendations(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake GetRecommendations error"))
+	suite.Error(suite.client.RebalanceRecommendationsAction(0))
+}
+
+func (suite *rebalanceActionsTestSuite) TestRebalanceApplyAction() {
+	suite.rebalanceClient.EXPECT().
+		ApplyRecommendations(gomock.Any(), &rebalancesvc.ApplyRecommendationsRequest{
+			MaxMigrations: 5,
+		}).
+		Return(&rebalancesvc.ApplyRecommendationsResponse{
+			Migrations: suite.recommendations,
+		}, nil)
+	suite.NoError(suite.client.RebalanceApplyAction(5))
+
+	// Test pods failed to migrate
+	suite.rebalanceClient.EXPECT().
+		ApplyRecommendations(gomock.Any(), gomock.Any()).
+		Return(&rebalancesvc.ApplyRecommendationsResponse{
+			Failures: []*rebalance.MigrationFailure{
+				{
+					Recommendation: suite.recommendations[0],
+					Message:        "not permitted",
+				},
+			},
+		}, nil)
+	suite.NoError(suite.client.RebalanceApplyAction(0))
+
+	// Test no pods migrated
+	suite.rebalanceClient.EXPECT().
+		ApplyRecommendations(gomock.Any(), gomock.Any()).
+		Return(&rebalancesvc.ApplyRecommendationsResponse{}, nil)
+	suite.NoError(suite.client.RebalanceApplyAction(0))
+
+	// Test ApplyRecommendations error
+	suite.rebalanceClient.EXPECT().
+		ApplyRecommendations(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake ApplyRecommendations error"))
+	suite.Error(suite.client.RebalanceApplyAction(0))
+}
+
+func (suite *rebalanceActionsTestSuite) TestPrintRebalanceResponsesDebug() {
+	printRebalanceRecommendationsResponse(&rebalancesvc.GetRecommendationsResponse{
+		Recommendations: suite.recommendations,
+	}, true)
+	printRebalanceApplyResponse(&rebalancesvc.ApplyRecommendationsResponse{
+		Migrations: suite.recommendations,
+	}, true)
+}
@@ -23,8 +23,8 @@ import (
 )
 
 const (
-	activeTaskListFormatHeader = "TaskID\tState\tReason\tLast Update Time\n"
-	activeTaskListFormatBody   = "%s\t%s\t%s\t%s\n"
+	activeTaskListFormatHeader = "TaskID\tState\tReason\tPending Reason\tLast Update Time\n"
+	activeTaskListFormatBody   = "%s\t%s\t%s\t%s\t%s\n"
 )
 
 // ResMgrGetActiveTasks fetches the active tasks from resource manager.
@@ -80,6 +80,7 @@ func printActiveTasksResponse(r *resmgrsvc.GetActiveTasksResponse, debug bool) {
 						task.GetTaskID(),
 						task.GetTaskState(),
 						task.GetReason(),
+						task.GetPendingReason(),
 						task.GetLastUpdateTime())
 				}
 			}
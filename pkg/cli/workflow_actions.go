@@ -0,0 +1,213 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	pbworkflow "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow"
+	workflowsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	workflowSummaryFormatHeader = "ID\tName\tState\tCreation Time\tCompletion Time\tNodes\t\n"
+	workflowSummaryFormatBody   = "%s\t%s\t%s\t%s\t%s\t%d\t\n"
+	workflowNodeFormatHeader    = "Node\tState\tJob ID\tAttempts\tStart Time\tCompletion Time\tMessage\t\n"
+	workflowNodeFormatBody      = "%s\t%s\t%s\t%d\t%s\t%s\t%s\t\n"
+
+	workflowStatePrefix = "WORKFLOW_STATE_"
+)
+
+// WorkflowCreateAction is the action for creating a workflow of batch
+// jobs. The nodes whose job spec has no resource pool use the given one.
+func (c *Client) WorkflowCreateAction(respoolPath string, cfg string) error {
+	respoolID, err := c.LookupResourcePoolID(respoolPath)
+	if err != nil {
+		return err
+	}
+	if respoolID == nil {
+		return fmt.Errorf("unable to find resource pool ID for "+
+			":%s", respoolPath)
+	}
+
+	var spec pbworkflow.WorkflowSpec
+	buffer, err := ioutil.ReadFile(cfg)
+	if err != nil {
+		return fmt.Errorf("unable to open file %s: %v", cfg, err)
+	}
+	if err := yaml.Unmarshal(buffer, &spec); err != nil {
+		return fmt.Errorf("unable to parse file %s: %v", cfg, err)
+	}
+
+	for _, node := range spec.GetNodes() {
+		if node.GetJobSpec() != nil && node.GetJobSpec().GetRespoolId() == nil {
+			node.JobSpec.RespoolId = &v1alphapeloton.ResourcePoolID{
+				Value: respoolID.GetValue(),
+			}
+		}
+	}
+
+	response, err := c.workflowClient.CreateWorkflow(
+		c.ctx,
+		&workflowsvc.CreateWorkflowRequest{Spec: &spec})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(tabWriter, "Workflow %s created\n",
+		response.GetWorkflowId().GetValue())
+	tabWriter.Flush()
+	return nil
+}
+
+// WorkflowGetAction is the action for getting
+// the status of a workflow and of its nodes
+func (c *Client) WorkflowGetAction(workflowID string) error {
+	response, err := c.workflowClient.GetWorkflow(
+		c.ctx,
+		&workflowsvc.GetWorkflowRequest{
+			WorkflowId: &pbworkflow.WorkflowID{Value: workflowID},
+		})
+	if err != nil {
+		return err
+	}
+
+	printWorkflowGetResponse(response, c.Debug)
+	return nil
+}
+
+// WorkflowListAction is the action for listing the workflows in the
+// given comma separated states, such as running,failed
+func (c *Client) WorkflowListAction(states string) error {
+	var apiStates []pbworkflow.WorkflowState
+	for _, k := range strings.Split(states, labelSeparator) {
+		if k == "" {
+			continue
+		}
+		state, ok := pbworkflow.WorkflowState_value[workflowStatePrefix+strings.ToUpper(k)]
+		if !ok {
+			return fmt.Errorf("invalid workflow state %s", k)
+		}
+		apiStates = append(apiStates, pbworkflow.WorkflowState(state))
+	}
+
+	response, err := c.workflowClient.ListWorkflows(
+		c.ctx,
+		&workflowsvc.ListWorkflowsRequest{States: apiStates})
+	if err != nil {
+		return err
+	}
+
+	printWorkflowListResponse(response, c.Debug)
+	return nil
+}
+
+// WorkflowCancelAction is the action for cancelling a running workflow
+func (c *Client) WorkflowCancelAction(workflowID string) error {
+	_, err := c.workflowClient.CancelWorkflow(
+		c.ctx,
+		&workflowsvc.CancelWorkflowRequest{
+			WorkflowId: &pbworkflow.WorkflowID{Value: workflowID},
+		})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(tabWriter, "Workflow %s cancelled\n", workflowID)
+	tabWriter.Flush()
+	return nil
+}
+
+// WorkflowRetryAction is the action for running again
+// the failed nodes of a failed workflow
+func (c *Client) WorkflowRetryAction(workflowID string) error {
+	_, err := c.workflowClient.RetryWorkflow(
+		c.ctx,
+		&workflowsvc.RetryWorkflowRequest{
+			WorkflowId: &pbworkflow.WorkflowID{Value: workflowID},
+		})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(tabWriter, "Workflow %s retried\n", workflowID)
+	tabWriter.Flush()
+	return nil
+}
+
+func printWorkflowGetResponse(
+	r *workflowsvc.GetWorkflowResponse,
+	debug bool) {
+	if debug {
+		printResponseJSON(r)
+		return
+	}
+	fmt.Fprintf(tabWriter, workflowSummaryFormatHeader)
+	printWorkflowSummary(r.GetWorkflow())
+	fmt.Fprintf(tabWriter, "\n")
+
+	fmt.Fprintf(tabWriter, workflowNodeFormatHeader)
+	for _, node := range r.GetWorkflow().GetStatus().GetNodes() {
+		fmt.Fprintf(
+			tabWriter,
+			workflowNodeFormatBody,
+			node.GetName(),
+			strings.TrimPrefix(node.GetState().String(), "NODE_STATE_"),
+			node.GetJobId().GetValue(),
+			node.GetAttempts(),
+			node.GetStartTime(),
+			node.GetCompletionTime(),
+			node.GetMessage(),
+		)
+	}
+	tabWriter.Flush()
+}
+
+func printWorkflowListResponse(
+	r *workflowsvc.ListWorkflowsResponse,
+	debug bool) {
+	if debug {
+		printResponseJSON(r)
+		return
+	}
+	if len(r.GetWorkflows()) == 0 {
+		fmt.Fprintf(tabWriter, "No workflows found\n")
+		tabWriter.Flush()
+		return
+	}
+	fmt.Fprintf(tabWriter, workflowSummaryFormatHeader)
+	for _, workflow := range r.GetWorkflows() {
+		printWorkflowSummary(workflow)
+	}
+	tabWriter.Flush()
+}
+
+func printWorkflowSummary(workflow *pbworkflow.WorkflowInfo) {
+	fmt.Fprintf(
+		tabWriter,
+		workflowSummaryFormatBody,
+		workflow.GetWorkflowId().GetValue(),
+		workflow.GetSpec().GetName(),
+		strings.TrimPrefix(workflow.GetStatus().GetState().String(), workflowStatePrefix),
+		workflow.GetStatus().GetCreationTime(),
+		workflow.GetStatus().GetCompletionTime(),
+		len(workflow.GetSpec().GetNodes()),
+	)
+}
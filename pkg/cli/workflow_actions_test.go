@@ -0,0 +1,222 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	pbworkflow "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow"
+	workflowsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow/svc"
+	workflowmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow/svc/mocks"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testWorkflowSpecConfig = "../../example/workflow/testworkflow.yaml"
+	testWorkflowID         = "0cd6e2c5-9de1-4fb1-9b9f-5ef1a8e2f3a4"
+)
+
+type workflowActionsTestSuite struct {
+	suite.Suite
+	ctrl           *gomock.Controller
+	workflowClient *workflowmocks.MockWorkflowServiceYARPCClient
+	resClient      *respoolmocks.MockResourceManagerYARPCClient
+	ctx            context.Context
+	client         Client
+	workflow       *pbworkflow.WorkflowInfo
+}
+
+func TestWorkflowActions(t *testing.T) {
+	suite.Run(t, new(workflowActionsTestSuite))
+}
+
+func (suite *workflowActionsTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.workflowClient = workflowmocks.NewMockWorkflowServiceYARPCClient(suite.ctrl)
+	suite.resClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.ctx = context.Background()
+	suite.client = Client{
+		Debug:          false,
+		workflowClient: suite.workflowClient,
+		resClient:      suite.resClient,
+		dispatcher:     nil,
+		ctx:            suite.ctx,
+	}
+	suite.workflow = &pbworkflow.WorkflowInfo{
+		WorkflowId: &pbworkflow.WorkflowID{Value: testWorkflowID},
+		Spec: &pbworkflow.WorkflowSpec{
+			Name:  "TestWorkflow",
+			Nodes: []*pbworkflow.NodeSpec{{Name: "extract"}},
+		},
+		Status: &pbworkflow.WorkflowStatus{
+			State: pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+			Nodes: []*pbworkflow.NodeStatus{
+				{
+					Name:     "extract",
+					State:    pbworkflow.NodeState_NODE_STATE_RUNNING,
+					JobId:    &v1alphapeloton.JobID{Value: testJobID},
+					Attempts: 1,
+				},
+			},
+			CreationTime: "2019-06-01T12:00:00Z",
+		},
+	}
+}
+
+func (suite *workflowActionsTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *workflowActionsTestSuite) TestWorkflowCreateAction() {
+	respoolID := uuid.New()
+	suite.resClient.EXPECT().
+		LookupResourcePoolID(gomock.Any(), &respool.LookupRequest{
+			Path: &respool.ResourcePoolPath{Value: testRespoolPath},
+		}).
+		Return(&respool.LookupResponse{
+			Id: &peloton.ResourcePoolID{Value: respoolID},
+		}, nil)
+
+	suite.workflowClient.EXPECT().
+		CreateWorkflow(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *workflowsvc.CreateWorkflowRequest) {
+			suite.Equal("TestWorkflow", req.GetSpec().GetName())
+			suite.Len(req.GetSpec().GetNodes(), 3)
+
+			report := req.GetSpec().GetNodes()[1]
+			suite.Equal("report", report.GetName())
+			suite.Equal(uint32(1), report.GetJobSpec().GetInstanceCount())
+			suite.Equal(respoolID, report.GetJobSpec().GetRespoolId().GetValue())
+			suite.Equal([]*pbworkflow.Dependency{
+				{
+					Node:      "extract",
+					Condition: pbworkflow.DependencyCondition_DEPENDENCY_CONDITION_ON_SUCCESS,
+				},
+			}, report.GetDependencies())
+		}).
+		Return(&workflowsvc.CreateWorkflowResponse{
+			WorkflowId: &pbworkflow.WorkflowID{Value: testWorkflowID},
+		}, nil)
+
+	suite.NoError(suite.client.WorkflowCreateAction(
+		testRespoolPath, testWorkflowSpecConfig))
+}
+
+func (suite *workflowActionsTestSuite) TestWorkflowCreateActionRespoolNotFound() {
+	suite.resClient.EXPECT().
+		LookupResourcePoolID(gomock.Any(), gomock.Any()).
+		Return(&respool.LookupResponse{}, nil)
+
+	suite.Error(suite.client.WorkflowCreateAction(
+		testRespoolPath, testWorkflowSpecConfig))
+}
+
+func (suite *workflowActionsTestSuite) TestWorkflowCreateActionError() {
+	suite.resClient.EXPECT().
+		LookupResourcePoolID(gomock.Any(), gomock.Any()).
+		Return(&respool.LookupResponse{
+			Id: &peloton.ResourcePoolID{Value: uuid.New()},
+		}, nil)
+	suite.workflowClient.EXPECT().
+		CreateWorkflow(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	suite.Error(suite.client.WorkflowCreateAction(
+		testRespoolPath, testWorkflowSpecConfig))
+}
+
+func (suite *workflowActionsTestSuite) TestWorkflowGetAction() {
+	suite.workflowClient.EXPECT().
+		GetWorkflow(gomock.Any(), &workflowsvc.GetWorkflowRequest{
+			WorkflowId: &pbworkflow.WorkflowID{Value: testWorkflowID},
+		}).
+		Return(&workflowsvc.GetWorkflowResponse{Workflow: suite.workflow}, nil)
+	suite.NoError(suite.client.WorkflowGetAction(testWorkflowID))
+
+	// Test debug output
+	suite.client.Debug = true
+	suite.workflowClient.EXPECT().
+		GetWorkflow(gomock.Any(), gomock.Any()).
+		Return(&workflowsvc.GetWorkflowResponse{Workflow: suite.workflow}, nil)
+	suite.NoError(suite.client.WorkflowGetAction(testWorkflowID))
+}
+
+func (suite *workflowActionsTestSuite) TestWorkflowGetActionError() {
+	suite.workflowClient.EXPECT().
+		GetWorkflow(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error"))
+	suite.Error(suite.client.WorkflowGetAction(testWorkflowID))
+}
+
+func (suite *workflowActionsTestSuite) TestWorkflowListAction() {
+	suite.workflowClient.EXPECT().
+		ListWorkflows(gomock.Any(), &workflowsvc.ListWorkflowsRequest{
+			States: []pbworkflow.WorkflowState{
+				pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+				pbworkflow.WorkflowState_WORKFLOW_STATE_FAILED,
+			},
+		}).
+		Return(&workflowsvc.ListWorkflowsResponse{
+			Workflows: []*pbworkflow.WorkflowInfo{suite.workflow},
+		}, nil)
+	suite.NoError(suite.client.WorkflowListAction("running,failed"))
+
+	// Test no workflows
+	suite.workflowClient.EXPECT().
+		ListWorkflows(gomock.Any(), &workflowsvc.ListWorkflowsRequest{}).
+		Return(&workflowsvc.ListWorkflowsResponse{}, nil)
+	suite.NoError(suite.client.WorkflowListAction(""))
+}
+
+func (suite *workflowActionsTestSuite) TestWorkflowListActionInvalidState() {
+	suite.Error(suite.client.WorkflowListAction("running,unknown"))
+}
+
+func (suite *workflowActionsTestSuite) TestWorkflowCancelAction() {
+	suite.workflowClient.EXPECT().
+		CancelWorkflow(gomock.Any(), &workflowsvc.CancelWorkflowRequest{
+			WorkflowId: &pbworkflow.WorkflowID{Value: testWorkflowID},
+		}).
+		Return(&workflowsvc.CancelWorkflowResponse{}, nil)
+	suite.NoError(suite.client.WorkflowCancelAction(testWorkflowID))
+
+	suite.workflowClient.EXPECT().
+		CancelWorkflow(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error"))
+	suite.Error(suite.client.WorkflowCancelAction(testWorkflowID))
+}
+
+func (suite *workflowActionsTestSuite) TestWorkflowRetryAction() {
+	suite.workflowClient.EXPECT().
+		RetryWorkflow(gomock.Any(), &workflowsvc.RetryWorkflowRequest{
+			WorkflowId: &pbworkflow.WorkflowID{Value: testWorkflowID},
+		}).
+		Return(&workflowsvc.RetryWorkflowResponse{}, nil)
+	suite.NoError(suite.client.WorkflowRetryAction(testWorkflowID))
+
+	suite.workflowClient.EXPECT().
+		RetryWorkflow(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error"))
+	suite.Error(suite.client.WorkflowRetryAction(testWorkflowID))
+}
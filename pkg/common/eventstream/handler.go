@@ -16,26 +16,49 @@ package eventstream
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"math"
+	"os"
+	"path/filepath"
 	"sync"
+	"time"
 
 	pb_eventstream "github.com/uber/peloton/.gen/peloton/private/eventstream"
 
 	"github.com/uber/peloton/pkg/common/cirbuf"
+	"github.com/uber/peloton/pkg/common/wal"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/pborman/uuid"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
+const (
+	// name of the file holding the stream state of a durable stream
+	_streamMetaFile = "stream.meta"
+	// min period between the saves of the purge offsets of the clients
+	// of a durable stream, the events purged after the last save are
+	// delivered again after a restart
+	_metaSavePeriod = time.Second
+)
+
+// errStreamClosed is returned for the events added to a durable stream
+// which is closed
+var errStreamClosed = errors.New("event stream is closed")
+
 // PurgedEventsProcessor is the interface to handle the purged data
 type PurgedEventsProcessor interface {
 	EventPurged(events []*cirbuf.CircularBufferItem)
 }
 
 // Handler holds a circular buffer and serves request to pull data.
+// The events can be held by a write-ahead log instead, so that they
+// survive a restart.
 // This component is used in hostmgr and resmgr
 type Handler struct {
 	sync.RWMutex
@@ -48,9 +71,36 @@ type Handler struct {
 	clientPurgeOffsets   map[string]uint64
 	purgedEventProcessor PurgedEventsProcessor
 
+	// whether the events are held by a write-ahead log,
+	// the circular buffer is not used if set
+	durable bool
+	// config of the write-ahead log and its metrics scope
+	walConfig wal.Config
+	walScope  tally.Scope
+	// write-ahead log holding the events of a durable stream,
+	// nil while the stream is closed. It is replaced with both
+	// the handler lock and logLock held, so that the events are
+	// added holding only logLock.
+	eventLog *wal.Log
+	logLock  sync.RWMutex
+	// path of the file holding the stream ID and the purge
+	// offsets of a durable stream
+	metaPath string
+	// whether purge offsets changed since the last save, and
+	// the time of the last save
+	metaDirty    bool
+	metaSaveTime time.Time
+
 	metrics *HandlerMetrics
 }
 
+// streamMeta is the state of a durable stream which is persisted along
+// with the events, so that the clients resume after a restart
+type streamMeta struct {
+	StreamID           string            `json:"stream_id"`
+	ClientPurgeOffsets map[string]uint64 `json:"client_purge_offsets"`
+}
+
 // NewEventStreamHandler creates an EventStreamHandler
 func NewEventStreamHandler(
 	bufferSize int,
@@ -72,6 +122,263 @@ func NewEventStreamHandler(
 	return &handler
 }
 
+// NewDurableEventStreamHandler creates an EventStreamHandler which holds
+// the events in a write-ahead log. The stream ID and the purge offsets
+// of the clients are kept along with the events, so that the clients
+// resume consuming the stream after a restart. The events are removed
+// by the retention of the log, and can be replayed from any offset
+// retained.
+func NewDurableEventStreamHandler(
+	config wal.Config,
+	expectedClients []string,
+	parentScope tally.Scope) (*Handler, error) {
+	scope := parentScope.SubScope("EventStreamHandler")
+	handler := &Handler{
+		expectedClients: expectedClients,
+		durable:         true,
+		walConfig:       config,
+		walScope:        scope.SubScope("wal"),
+		metaPath:        filepath.Join(config.Dir, _streamMetaFile),
+		metrics:         NewHandlerMetrics(scope),
+	}
+	if err := handler.open(); err != nil {
+		return nil, err
+	}
+	return handler, nil
+}
+
+// open opens the write-ahead log of a durable stream, and resumes
+// the stream from its persisted state.
+// Must be called with the locks held, or before the handler is shared.
+func (h *Handler) open() error {
+	eventLog, err := wal.Open(h.walConfig, h.walScope)
+	if err != nil {
+		return errors.Wrap(err, "failed to open event log")
+	}
+
+	h.eventLog = eventLog
+	h.clientPurgeOffsets = make(map[string]uint64)
+	if err := h.loadMeta(); err != nil {
+		eventLog.Close()
+		h.eventLog = nil
+		return err
+	}
+
+	head, tail := h.getRange()
+	h.metrics.Head.Update(float64(head))
+	h.metrics.Tail.Update(float64(tail))
+	h.metrics.Size.Update(float64(head - tail))
+	log.WithFields(log.Fields{
+		"stream_id":            h.streamID,
+		"head":                 head,
+		"tail":                 tail,
+		"client_purge_offsets": h.clientPurgeOffsets,
+	}).Info("Durable event stream resumed")
+	return nil
+}
+
+// loadMeta loads the stream ID and the purge offsets of the clients of
+// a durable stream, and creates them if the stream is new.
+func (h *Handler) loadMeta() error {
+	var meta streamMeta
+	buffer, err := ioutil.ReadFile(h.metaPath)
+	if err == nil {
+		if err := json.Unmarshal(buffer, &meta); err != nil {
+			return errors.Wrap(err, "failed to unmarshal stream meta")
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to read stream meta")
+	}
+
+	h.streamID = meta.StreamID
+	if h.streamID == "" {
+		h.streamID = uuid.New()
+	}
+
+	head, _ := h.getRange()
+	for _, client := range h.expectedClients {
+		offset := meta.ClientPurgeOffsets[client]
+		if offset > head {
+			// the events consumed by the client were not persisted,
+			// a new stream makes the client initialize it again
+			log.WithFields(log.Fields{
+				"client_name":  client,
+				"purge_offset": offset,
+				"head":         head,
+			}).Warn("Purge offset beyond the events persisted, starting a new stream")
+			h.streamID = uuid.New()
+			offset = head
+		}
+		h.clientPurgeOffsets[client] = offset
+	}
+	return h.saveMeta()
+}
+
+// saveMeta persists the stream ID and the purge offsets of the clients
+// of a durable stream. The file is replaced atomically so that a crash
+// does not leave it partially written.
+func (h *Handler) saveMeta() error {
+	buffer, err := json.Marshal(&streamMeta{
+		StreamID:           h.streamID,
+		ClientPurgeOffsets: h.clientPurgeOffsets,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal stream meta")
+	}
+
+	tmpPath := h.metaPath + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to create stream meta")
+	}
+	if _, err := file.Write(buffer); err != nil {
+		file.Close()
+		return errors.Wrap(err, "failed to write stream meta")
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return errors.Wrap(err, "failed to sync stream meta")
+	}
+	if err := file.Close(); err != nil {
+		return errors.Wrap(err, "failed to close stream meta")
+	}
+	if err := os.Rename(tmpPath, h.metaPath); err != nil {
+		return errors.Wrap(err, "failed to rename stream meta")
+	}
+
+	// flush the rename to disk
+	dir, err := os.Open(filepath.Dir(h.metaPath))
+	if err != nil {
+		return errors.Wrap(err, "failed to open stream meta directory")
+	}
+	defer dir.Close()
+	if err := dir.Sync(); err != nil {
+		return errors.Wrap(err, "failed to sync stream meta directory")
+	}
+
+	h.metaDirty = false
+	h.metaSaveTime = time.Now()
+	return nil
+}
+
+// Durable returns whether the events are persisted in a write-ahead log
+func (h *Handler) Durable() bool {
+	return h.durable
+}
+
+// Open reopens a durable stream closed by Close, resuming it from the
+// events and the purge offsets persisted. It is a no-op if the stream
+// is open or is not durable.
+func (h *Handler) Open() error {
+	if !h.durable {
+		return nil
+	}
+
+	h.Lock()
+	defer h.Unlock()
+	h.logLock.Lock()
+	defer h.logLock.Unlock()
+	if h.eventLog != nil {
+		return nil
+	}
+	return h.open()
+}
+
+// Close saves the purge offsets of the clients and closes the
+// write-ahead log of a durable stream. The events are rejected
+// until the stream is opened again.
+func (h *Handler) Close() error {
+	if !h.durable {
+		return nil
+	}
+
+	h.Lock()
+	defer h.Unlock()
+	h.logLock.Lock()
+	defer h.logLock.Unlock()
+	if h.eventLog == nil {
+		return nil
+	}
+	var saveErr error
+	if h.metaDirty {
+		saveErr = h.saveMeta()
+	}
+	err := h.eventLog.Close()
+	h.eventLog = nil
+	if saveErr != nil {
+		return errors.Wrap(saveErr, "failed to save purge offsets")
+	}
+	return err
+}
+
+// closed returns whether a durable stream is closed
+func (h *Handler) closed() bool {
+	return h.durable && h.eventLog == nil
+}
+
+// getRange returns the head and the tail of the stream
+func (h *Handler) getRange() (uint64, uint64) {
+	if h.durable {
+		tail, head := h.eventLog.Range()
+		return head, tail
+	}
+	return h.circularBuffer.GetRange()
+}
+
+// getEventsByRange returns the events of the stream from offset from to
+// offset to included. Returns an error if the range does not overlap
+// the events held by the stream.
+func (h *Handler) getEventsByRange(from uint64, to uint64) ([]*pb_eventstream.Event, error) {
+	if !h.durable {
+		items, err := h.circularBuffer.GetItemsByRange(from, to)
+		if err != nil {
+			return nil, err
+		}
+		var events []*pb_eventstream.Event
+		for _, item := range items {
+			if event, ok := item.Value.(*pb_eventstream.Event); ok {
+				e := &pb_eventstream.Event{
+					Type:             event.Type,
+					MesosTaskStatus:  event.MesosTaskStatus,
+					PelotonTaskEvent: event.PelotonTaskEvent,
+					Offset:           item.SequenceID,
+				}
+				events = append(events, e)
+			}
+		}
+		return events, nil
+	}
+
+	head, tail := h.getRange()
+	if from > head || to < tail {
+		return nil, wal.ErrOutOfRange
+	}
+	if from == head {
+		return nil, nil
+	}
+	if from < tail {
+		from = tail
+	}
+	if to >= head {
+		to = head - 1
+	}
+
+	records, err := h.eventLog.Read(from, int(to-from+1))
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*pb_eventstream.Event, 0, len(records))
+	for _, record := range records {
+		event := &pb_eventstream.Event{}
+		if err := proto.Unmarshal(record.Data, event); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal event %d", record.Offset)
+		}
+		event.Offset = record.Offset
+		events = append(events, event)
+	}
+	return events, nil
+}
+
 // Check if the client is expected
 func (h *Handler) isClientExpected(clientName string) bool {
 	for _, ok := h.clientPurgeOffsets[clientName]; ok; {
@@ -83,8 +390,22 @@ func (h *Handler) isClientExpected(clientName string) bool {
 }
 
 // AddEvent adds a task Event or mesos status update into the
-// inner circular buffer
+// inner circular buffer. For a durable stream, the event is visible
+// to the clients once persisted as per the sync policy of the
+// write-ahead log.
 func (h *Handler) AddEvent(event *pb_eventstream.Event) error {
+	return h.AddEventAsync(event, nil)
+}
+
+// AddEventAsync adds an event like AddEvent, without waiting for the
+// event to be persisted. The callback, if not nil, is called once the
+// event is persisted for a durable stream, or with the error which
+// failed to persist it. For a stream which is not durable, it is called
+// once the event is added. The callback is not called if an error is
+// returned.
+func (h *Handler) AddEventAsync(
+	event *pb_eventstream.Event,
+	persisted func(err error)) error {
 	if event == nil {
 		return errors.New("event is nil")
 	}
@@ -92,44 +413,73 @@ func (h *Handler) AddEvent(event *pb_eventstream.Event) error {
 	log.WithFields(log.Fields{
 		"Type": event.Type,
 	}).Debug("Adding eventstream event")
-	item, err := h.circularBuffer.AddItem(event)
+	h.logLock.RLock()
+	defer h.logLock.RUnlock()
+	offset, err := h.addEvent(event, persisted)
 	if err != nil {
 		h.metrics.AddEventFail.Inc(1)
 		return err
 	}
 	h.metrics.AddEventSuccess.Inc(1)
-	head, tail := h.circularBuffer.GetRange()
+	head, tail := h.getRange()
 	h.metrics.Head.Update(float64(head))
 	h.metrics.Tail.Update(float64(tail))
 	h.metrics.Size.Update(float64(head - tail))
-	log.WithField("Current head", item.SequenceID).Debug("Event added")
+	log.WithField("Current head", offset).Debug("Event added")
 	return nil
 }
 
-// GetEvents returns all the events pending in circular buffer
+// addEvent adds the event to the stream and returns its offset
+func (h *Handler) addEvent(
+	event *pb_eventstream.Event,
+	persisted func(err error)) (uint64, error) {
+	if h.closed() {
+		return 0, errStreamClosed
+	}
+	if !h.durable {
+		item, err := h.circularBuffer.AddItem(event)
+		if err != nil {
+			return 0, err
+		}
+		if persisted != nil {
+			persisted(nil)
+		}
+		return item.SequenceID, nil
+	}
+
+	buffer, err := proto.Marshal(event)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to marshal event")
+	}
+	var callback wal.AppendCallback
+	if persisted != nil {
+		callback = func(_ uint64, err error) { persisted(err) }
+	}
+	return h.eventLog.Append(buffer, callback)
+}
+
+// GetEvents returns all the events pending in circular buffer.
+// For a durable stream, the events not yet purged by all the clients
+// are returned.
 // This method is primarily for debugging purpose
 func (h *Handler) GetEvents() ([]*pb_eventstream.Event, error) {
 	h.Lock()
 	defer h.Unlock()
+	if h.closed() {
+		return nil, errStreamClosed
+	}
 
 	// Get and return data
-	head, tail := h.circularBuffer.GetRange()
-	items, err := h.circularBuffer.GetItemsByRange(tail, head)
+	head, tail := h.getRange()
+	if h.durable {
+		tail = h.minPurgeOffset(tail)
+	}
+	events, err := h.getEventsByRange(tail, head)
 	if err != nil {
 		return nil, err
 	}
-
-	events := make([]*pb_eventstream.Event, 0, len(items))
-	for _, item := range items {
-		if event, ok := item.Value.(*pb_eventstream.Event); ok {
-			e := &pb_eventstream.Event{
-				Type:             event.Type,
-				MesosTaskStatus:  event.MesosTaskStatus,
-				PelotonTaskEvent: event.PelotonTaskEvent,
-				Offset:           item.SequenceID,
-			}
-			events = append(events, e)
-		}
+	if events == nil {
+		events = []*pb_eventstream.Event{}
 	}
 	return events, nil
 }
@@ -142,6 +492,10 @@ func (h *Handler) InitStream(
 	defer h.Unlock()
 	log.WithField("InitStream request", req).Debug("request")
 	h.metrics.InitStreamAPI.Inc(1)
+	if h.closed() {
+		h.metrics.InitStreamFail.Inc(1)
+		return nil, yarpcerrors.UnavailableErrorf("event stream is closed")
+	}
 	var response pb_eventstream.InitStreamResponse
 	clientName := req.ClientName
 	clientSupported := h.isClientExpected(clientName)
@@ -155,7 +509,7 @@ func (h *Handler) InitStream(
 		return &response, nil
 	}
 	response.StreamID = h.streamID
-	_, tail := h.circularBuffer.GetRange()
+	_, tail := h.getRange()
 	response.MinOffset = tail
 	response.PreviousPurgeOffset = h.clientPurgeOffsets[clientName]
 	log.WithField("InitStream response", response).Debug("")
@@ -172,6 +526,10 @@ func (h *Handler) WaitForEvents(
 	h.Lock()
 	defer h.Unlock()
 	h.metrics.WaitForEventsAPI.Inc(1)
+	if h.closed() {
+		h.metrics.WaitForEventsFailed.Inc(1)
+		return nil, yarpcerrors.UnavailableErrorf("event stream is closed")
+	}
 	var response pb_eventstream.WaitForEventsResponse
 	// Validate client
 	clientName := req.ClientName
@@ -201,10 +559,10 @@ func (h *Handler) WaitForEvents(
 		return &response, nil
 	}
 	// Get and return data
-	head, tail := h.circularBuffer.GetRange()
+	head, tail := h.getRange()
 	beginOffset := req.BeginOffset
 	limit := req.Limit
-	items, err := h.circularBuffer.GetItemsByRange(beginOffset, beginOffset+uint64(limit)-1)
+	events, err := h.getEventsByRange(beginOffset, beginOffset+uint64(limit)-1)
 	if err != nil {
 		response.Error = &pb_eventstream.WaitForEventsResponse_Error{
 			OutOfRange: &pb_eventstream.OffsetOutOfRange{
@@ -217,18 +575,6 @@ func (h *Handler) WaitForEvents(
 		h.metrics.WaitForEventsFailed.Inc(1)
 		return &response, nil
 	}
-	var events []*pb_eventstream.Event
-	for _, item := range items {
-		if event, ok := item.Value.(*pb_eventstream.Event); ok {
-			e := &pb_eventstream.Event{
-				Type:             event.Type,
-				MesosTaskStatus:  event.MesosTaskStatus,
-				PelotonTaskEvent: event.PelotonTaskEvent,
-				Offset:           item.SequenceID,
-			}
-			events = append(events, e)
-		}
-	}
 	h.metrics.WaitForEventsSuccess.Inc(1)
 	response.Events = events
 	// Purge old data if specified
@@ -251,6 +597,11 @@ func (h *Handler) WaitForEvents(
 // purgeData scans the min of the purgeOffset for each client, and move the buffer tail
 // to the minPurgeOffset
 func (h *Handler) purgeEvents(clientName string, purgeOffset uint64) {
+	if h.durable {
+		h.savePurgeOffset(clientName, purgeOffset)
+		return
+	}
+
 	h.clientPurgeOffsets[clientName] = purgeOffset
 	var minPurgeOffset uint64
 	var clientWithMinPurgeOffset string
@@ -284,3 +635,50 @@ func (h *Handler) purgeEvents(clientName string, purgeOffset uint64) {
 	h.metrics.Tail.Update(float64(tail))
 	h.metrics.Size.Update(float64(head - tail))
 }
+
+// savePurgeOffset persists the purge offset of a client of a durable
+// stream. The events are not removed, they are kept as long as they are
+// retained by the write-ahead log so that they can be replayed. The
+// purge offsets are saved at most once per _metaSavePeriod.
+func (h *Handler) savePurgeOffset(clientName string, purgeOffset uint64) {
+	head, tail := h.getRange()
+	if purgeOffset > head {
+		log.WithFields(log.Fields{
+			"client_name":  clientName,
+			"purge_offset": purgeOffset,
+			"head":         head,
+		}).Error("purgeOffset incorrect")
+		h.metrics.PurgeEventError.Inc(1)
+		return
+	}
+
+	if h.clientPurgeOffsets[clientName] != purgeOffset {
+		h.clientPurgeOffsets[clientName] = purgeOffset
+		h.metaDirty = true
+	}
+	if h.metaDirty && time.Since(h.metaSaveTime) >= _metaSavePeriod {
+		if err := h.saveMeta(); err != nil {
+			log.WithError(err).
+				WithField("client_name", clientName).
+				Error("Failed to save purge offset")
+			h.metrics.PurgeEventError.Inc(1)
+		}
+	}
+	h.metrics.Tail.Update(float64(tail))
+	h.metrics.Size.Update(float64(head - tail))
+}
+
+// minPurgeOffset returns the min of the purgeOffset for each client,
+// and at least the given offset
+func (h *Handler) minPurgeOffset(offset uint64) uint64 {
+	minOffset := uint64(math.MaxUint64)
+	for _, p := range h.clientPurgeOffsets {
+		if minOffset > p {
+			minOffset = p
+		}
+	}
+	if minOffset < offset || minOffset == math.MaxUint64 {
+		return offset
+	}
+	return minOffset
+}
@@ -16,15 +16,20 @@ package eventstream
 
 import (
 	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	pb_eventstream "github.com/uber/peloton/.gen/peloton/private/eventstream"
 	"github.com/uber/peloton/pkg/common/cirbuf"
+	"github.com/uber/peloton/pkg/common/wal"
 )
 
 type PurgeEventCollector struct {
@@ -303,3 +308,180 @@ func TestPurgeData(t *testing.T) {
 		assert.Equal(t, i, int(collector.data[i].SequenceID))
 	}
 }
+
+func makeTaskStatusEvent(i int) *pb_eventstream.Event {
+	taskID := fmt.Sprintf("task-%d", i)
+	return &pb_eventstream.Event{
+		Type: pb_eventstream.Event_MESOS_TASK_STATUS,
+		MesosTaskStatus: &mesos.TaskStatus{
+			TaskId: &mesos.TaskID{Value: &taskID},
+		},
+	}
+}
+
+func TestDurableHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventstream")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	config := wal.Config{Dir: dir, SyncPolicy: wal.SyncAlways}
+	clients := []string{"jobMgr", "resMgr"}
+
+	eventStreamHandler, err := NewDurableEventStreamHandler(config, clients, tally.NoopScope)
+	require.NoError(t, err)
+	assert.True(t, eventStreamHandler.Durable())
+	streamID := eventStreamHandler.streamID
+
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, eventStreamHandler.AddEvent(makeTaskStatusEvent(i)))
+	}
+
+	response, _ := eventStreamHandler.InitStream(context.Background(), makeInitStreamRequest("jobMgr"))
+	assert.Nil(t, response.Error)
+	assert.Equal(t, streamID, response.StreamID)
+	assert.Equal(t, uint64(0), response.MinOffset)
+
+	request := makeWaitForEventsRequest("jobMgr", streamID, uint64(5), int32(10), uint64(5))
+	waitResponse, _ := eventStreamHandler.WaitForEvents(context.Background(), request)
+	assert.Nil(t, waitResponse.Error)
+	require.Equal(t, 10, len(waitResponse.Events))
+	for i, event := range waitResponse.Events {
+		assert.Equal(t, uint64(i+5), event.Offset)
+		assert.Equal(t, fmt.Sprintf("task-%d", i+5), event.GetMesosTaskStatus().GetTaskId().GetValue())
+	}
+
+	request = makeWaitForEventsRequest("resMgr", streamID, uint64(8), int32(100), uint64(8))
+	waitResponse, _ = eventStreamHandler.WaitForEvents(context.Background(), request)
+	assert.Equal(t, 12, len(waitResponse.Events))
+
+	// beyond the head of the stream
+	request = makeWaitForEventsRequest("jobMgr", streamID, uint64(21), int32(10), uint64(5))
+	waitResponse, _ = eventStreamHandler.WaitForEvents(context.Background(), request)
+	assert.NotNil(t, waitResponse.Error.OutOfRange)
+
+	// the events not purged by all the clients are outstanding
+	events, err := eventStreamHandler.GetEvents()
+	assert.NoError(t, err)
+	assert.Equal(t, 15, len(events))
+	assert.Equal(t, uint64(5), events[0].Offset)
+	assert.NoError(t, eventStreamHandler.Close())
+
+	// the stream and the purge offsets are resumed after a restart
+	eventStreamHandler, err = NewDurableEventStreamHandler(config, clients, tally.NoopScope)
+	require.NoError(t, err)
+	defer eventStreamHandler.Close()
+	assert.Equal(t, streamID, eventStreamHandler.streamID)
+
+	response, _ = eventStreamHandler.InitStream(context.Background(), makeInitStreamRequest("resMgr"))
+	assert.Nil(t, response.Error)
+	assert.Equal(t, streamID, response.StreamID)
+	assert.Equal(t, uint64(8), response.PreviousPurgeOffset)
+
+	// the purged events can be replayed while they are retained
+	request = makeWaitForEventsRequest("jobMgr", streamID, uint64(0), int32(100), uint64(0))
+	waitResponse, _ = eventStreamHandler.WaitForEvents(context.Background(), request)
+	assert.Nil(t, waitResponse.Error)
+	assert.Equal(t, 20, len(waitResponse.Events))
+
+	persisted := false
+	assert.NoError(t, eventStreamHandler.AddEventAsync(
+		makeTaskStatusEvent(20),
+		func(err error) {
+			assert.NoError(t, err)
+			persisted = true
+		}))
+	assert.True(t, persisted)
+	head, tail := eventStreamHandler.getRange()
+	assert.Equal(t, uint64(21), head)
+	assert.Equal(t, uint64(0), tail)
+}
+
+// TestDurableHandlerReopen tests that the purge offsets not yet saved
+// are flushed on close, and recovered once the stream is opened again
+func TestDurableHandlerReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventstream")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	config := wal.Config{Dir: dir, SyncPolicy: wal.SyncNone}
+	clients := []string{"jobMgr", "resMgr"}
+
+	eventStreamHandler, err := NewDurableEventStreamHandler(config, clients, tally.NoopScope)
+	require.NoError(t, err)
+	streamID := eventStreamHandler.streamID
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, eventStreamHandler.AddEvent(makeTaskStatusEvent(i)))
+	}
+	// the purge offsets are saved at most once per save period,
+	// so that they are only flushed on close
+	request := makeWaitForEventsRequest("jobMgr", streamID, uint64(3), int32(10), uint64(3))
+	_, err = eventStreamHandler.WaitForEvents(context.Background(), request)
+	assert.NoError(t, err)
+	request = makeWaitForEventsRequest("jobMgr", streamID, uint64(7), int32(10), uint64(7))
+	_, err = eventStreamHandler.WaitForEvents(context.Background(), request)
+	assert.NoError(t, err)
+	request = makeWaitForEventsRequest("resMgr", streamID, uint64(5), int32(10), uint64(5))
+	_, err = eventStreamHandler.WaitForEvents(context.Background(), request)
+	assert.NoError(t, err)
+
+	assert.NoError(t, eventStreamHandler.Close())
+	assert.NoError(t, eventStreamHandler.Close())
+
+	// the stream rejects the events and the clients until opened again
+	assert.Error(t, eventStreamHandler.AddEvent(makeTaskStatusEvent(10)))
+	_, err = eventStreamHandler.InitStream(context.Background(), makeInitStreamRequest("jobMgr"))
+	assert.Error(t, err)
+	_, err = eventStreamHandler.WaitForEvents(context.Background(), request)
+	assert.Error(t, err)
+	_, err = eventStreamHandler.GetEvents()
+	assert.Error(t, err)
+
+	require.NoError(t, eventStreamHandler.Open())
+	assert.NoError(t, eventStreamHandler.Open())
+	assert.Equal(t, streamID, eventStreamHandler.streamID)
+	for client, offset := range map[string]uint64{"jobMgr": 7, "resMgr": 5} {
+		response, err := eventStreamHandler.InitStream(
+			context.Background(), makeInitStreamRequest(client))
+		assert.NoError(t, err)
+		assert.Equal(t, streamID, response.StreamID)
+		assert.Equal(t, offset, response.PreviousPurgeOffset, client)
+	}
+	assert.NoError(t, eventStreamHandler.AddEvent(makeTaskStatusEvent(10)))
+	head, _ := eventStreamHandler.getRange()
+	assert.Equal(t, uint64(11), head)
+	assert.NoError(t, eventStreamHandler.Close())
+
+	// the offsets flushed on close are recovered after a restart
+	eventStreamHandler, err = NewDurableEventStreamHandler(config, clients, tally.NoopScope)
+	require.NoError(t, err)
+	defer eventStreamHandler.Close()
+	assert.Equal(t, streamID, eventStreamHandler.streamID)
+	assert.Equal(t, uint64(7), eventStreamHandler.clientPurgeOffsets["jobMgr"])
+	assert.Equal(t, uint64(5), eventStreamHandler.clientPurgeOffsets["resMgr"])
+}
+
+// TestDurableHandlerLostEvents tests that a new stream is started when
+// the events consumed by a client were not persisted
+func TestDurableHandlerLostEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventstream")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	config := wal.Config{Dir: dir, SyncPolicy: wal.SyncNone}
+	clients := []string{"jobMgr", "resMgr"}
+
+	eventStreamHandler, err := NewDurableEventStreamHandler(config, clients, tally.NoopScope)
+	require.NoError(t, err)
+	streamID := eventStreamHandler.streamID
+	assert.NoError(t, eventStreamHandler.Close())
+
+	require.NoError(t, ioutil.WriteFile(
+		eventStreamHandler.metaPath,
+		[]byte(`{"stream_id":"`+streamID+`","client_purge_offsets":{"jobMgr":10}}`),
+		0644))
+
+	eventStreamHandler, err = NewDurableEventStreamHandler(config, clients, tally.NoopScope)
+	require.NoError(t, err)
+	defer eventStreamHandler.Close()
+	assert.NotEqual(t, streamID, eventStreamHandler.streamID)
+	assert.Equal(t, uint64(0), eventStreamHandler.clientPurgeOffsets["jobMgr"])
+	assert.Equal(t, uint64(0), eventStreamHandler.clientPurgeOffsets["resMgr"])
+}
@@ -15,10 +15,13 @@
 package logging
 
 import (
+	"reflect"
 	"strings"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/pkg/common"
 
@@ -44,6 +47,63 @@ func redactSecrets(taskConfig *task.TaskConfig) {
 	}
 }
 
+// RedactMessage returns a copy of the message in which the secret data
+// of the task configs and of the secrets is redacted, wherever they
+// are nested in the message
+func RedactMessage(msg proto.Message) proto.Message {
+	cloned := proto.Clone(msg)
+	redactValue(reflect.ValueOf(cloned))
+	return cloned
+}
+
+// redactValue walks the value and redacts the secret data it contains
+func redactValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		switch m := v.Interface().(type) {
+		case *task.TaskConfig:
+			redactSecrets(m)
+		case *peloton.Secret:
+			if m.GetValue() != nil {
+				m.Value.Data = []byte(redactedStr)
+			}
+			return
+		case *v1alphapeloton.Secret:
+			if m.GetValue() != nil {
+				m.Value.Data = []byte(redactedStr)
+			}
+			return
+		}
+		redactValue(v.Elem())
+	case reflect.Interface:
+		if !v.IsNil() {
+			redactValue(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			// skip the unexported fields
+			if len(v.Type().Field(i).PkgPath) != 0 {
+				continue
+			}
+			redactValue(v.Field(i))
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			redactValue(v.Index(i))
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			redactValue(v.MapIndex(k))
+		}
+	}
+}
+
 // Format is called by logrus and returns the formatted string.
 // It looks for secrets data in each entry and redacts it.
 func (f *SecretsFormatter) Format(entry *log.Entry) ([]byte, error) {
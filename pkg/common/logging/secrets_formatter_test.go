@@ -19,6 +19,8 @@ import (
 	"testing"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/pkg/common"
@@ -103,3 +105,35 @@ func TestLaunchableTasksFormatting(t *testing.T) {
 	assert.NoError(t, err)
 	validateSecretFormatting(string(b), t)
 }
+
+// TestRedactMessage tests that the secrets nested in a message are
+// redacted in its copy, and that the message is not modified
+func TestRedactMessage(t *testing.T) {
+	req := &job.CreateRequest{
+		Config: &job.JobConfig{
+			DefaultConfig: &task.TaskConfig{
+				Container: &mesos.ContainerInfo{
+					Volumes: []*mesos.Volume{
+						util.CreateSecretVolume(testPath, testSecretStr),
+					},
+				},
+			},
+		},
+		Secrets: []*peloton.Secret{
+			{
+				Path: testPath,
+				Value: &peloton.Secret_Value{
+					Data: []byte(testSecretStr),
+				},
+			},
+		},
+	}
+
+	redacted := RedactMessage(req).(*job.CreateRequest)
+	assert.Equal(t, []byte(redactedStr), redacted.GetConfig().GetDefaultConfig().
+		GetContainer().GetVolumes()[0].GetSource().GetSecret().GetValue().GetData())
+	assert.Equal(t, []byte(redactedStr), redacted.GetSecrets()[0].GetValue().GetData())
+	assert.Equal(t, testPath, redacted.GetSecrets()[0].GetPath())
+
+	assert.Equal(t, []byte(testSecretStr), req.GetSecrets()[0].GetValue().GetData())
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskconfig
+
+import (
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+)
+
+const (
+	// DefaultExecutorCPULimit is the cpu overhead of the Mesos default
+	// executor, which launches the tasks running more than one container.
+	DefaultExecutorCPULimit = 0.1
+
+	// DefaultExecutorMemLimitMb is the memory overhead in MB of the Mesos
+	// default executor.
+	DefaultExecutorMemLimitMb = 32
+)
+
+// HasMultipleContainers returns true if the task config has sidecar or init
+// containers, in which case the task is launched as a Mesos task group.
+func HasMultipleContainers(taskConfig *task.TaskConfig) bool {
+	return len(taskConfig.GetSidecars()) != 0 ||
+		len(taskConfig.GetInitContainers()) != 0
+}
+
+// GetAllPorts returns the port configs of all the containers of the task,
+// starting with the primary container, then the sidecar containers and
+// finally the init containers. Dynamic ports are assigned in this order.
+func GetAllPorts(taskConfig *task.TaskConfig) []*task.PortConfig {
+	if !HasMultipleContainers(taskConfig) {
+		return taskConfig.GetPorts()
+	}
+
+	var ports []*task.PortConfig
+	ports = append(ports, taskConfig.GetPorts()...)
+	for _, container := range taskConfig.GetSidecars() {
+		ports = append(ports, container.GetPorts()...)
+	}
+	for _, container := range taskConfig.GetInitContainers() {
+		ports = append(ports, container.GetPorts()...)
+	}
+	return ports
+}
+
+// GetPodResource returns the resources required to run all the containers
+// of the task. For tasks with sidecar or init containers, this is the sum
+// of the resources of every container, since Mesos allocates resources to
+// each task of a task group, plus the overhead of the default executor.
+func GetPodResource(taskConfig *task.TaskConfig) *task.ResourceConfig {
+	if !HasMultipleContainers(taskConfig) {
+		return taskConfig.GetResource()
+	}
+
+	result := &task.ResourceConfig{
+		CpuLimit:   DefaultExecutorCPULimit,
+		MemLimitMb: DefaultExecutorMemLimitMb,
+	}
+	addResource(result, taskConfig.GetResource())
+	for _, container := range taskConfig.GetSidecars() {
+		addResource(result, container.GetResource())
+	}
+	for _, container := range taskConfig.GetInitContainers() {
+		addResource(result, container.GetResource())
+	}
+	return result
+}
+
+// addResource adds the resource config of a container to the result
+func addResource(result *task.ResourceConfig, resource *task.ResourceConfig) {
+	result.CpuLimit += resource.GetCpuLimit()
+	result.MemLimitMb += resource.GetMemLimitMb()
+	result.DiskLimitMb += resource.GetDiskLimitMb()
+	result.FdLimit += resource.GetFdLimit()
+	result.GpuLimit += resource.GetGpuLimit()
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskconfig
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingleContainerTask(t *testing.T) {
+	taskConfig := &task.TaskConfig{
+		Resource: &task.ResourceConfig{CpuLimit: 1, MemLimitMb: 100},
+		Ports:    []*task.PortConfig{{Name: "http", EnvName: "PORT_HTTP"}},
+	}
+
+	assert.False(t, HasMultipleContainers(taskConfig))
+	assert.Equal(t, taskConfig.GetPorts(), GetAllPorts(taskConfig))
+	assert.Equal(t, taskConfig.GetResource(), GetPodResource(taskConfig))
+}
+
+func TestMultipleContainersTask(t *testing.T) {
+	taskConfig := &task.TaskConfig{
+		Resource: &task.ResourceConfig{CpuLimit: 1, MemLimitMb: 100, DiskLimitMb: 10},
+		Ports:    []*task.PortConfig{{Name: "http", EnvName: "PORT_HTTP"}},
+		Sidecars: []*task.ContainerConfig{
+			{
+				Name:     "proxy",
+				Resource: &task.ResourceConfig{CpuLimit: 0.5, MemLimitMb: 50},
+				Ports:    []*task.PortConfig{{Name: "proxy", EnvName: "PORT_PROXY"}},
+			},
+		},
+		InitContainers: []*task.ContainerConfig{
+			{
+				Name:     "setup",
+				Resource: &task.ResourceConfig{CpuLimit: 0.5, MemLimitMb: 10, FdLimit: 5},
+			},
+		},
+	}
+
+	assert.True(t, HasMultipleContainers(taskConfig))
+
+	var names []string
+	for _, port := range GetAllPorts(taskConfig) {
+		names = append(names, port.GetName())
+	}
+	assert.Equal(t, []string{"http", "proxy"}, names)
+
+	resource := GetPodResource(taskConfig)
+	assert.InDelta(t, 2+DefaultExecutorCPULimit, resource.GetCpuLimit(), 0.0001)
+	assert.InDelta(t, 160+DefaultExecutorMemLimitMb, resource.GetMemLimitMb(), 0.0001)
+	assert.InDelta(t, 10, resource.GetDiskLimitMb(), 0.0001)
+	assert.Equal(t, uint32(5), resource.GetFdLimit())
+}
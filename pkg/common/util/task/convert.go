@@ -23,6 +23,8 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
+	"github.com/uber/peloton/pkg/common/constraints"
+	"github.com/uber/peloton/pkg/common/taskconfig"
 	"github.com/uber/peloton/pkg/common/util"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 )
@@ -46,7 +48,8 @@ func ConvertToResMgrGangs(
 		// those tasks have their MinInstances field set > 1.
 		if resmgrtask.MinInstances > 1 &&
 			!resmgrtask.GetRevocable() &&
-			jobConfig.GetType() != job.JobType_SERVICE {
+			jobConfig.GetType() != job.JobType_SERVICE &&
+			jobConfig.GetType() != job.JobType_DAEMON {
 			if len(multiTaskGangs) == 0 {
 				var multiTaskGang resmgrsvc.Gang
 				multiTaskGangs = append(multiTaskGangs, &multiTaskGang)
@@ -86,7 +89,7 @@ func ConvertTaskToResMgrTask(
 	}
 
 	numPorts := 0
-	for _, portConfig := range taskInfo.GetConfig().GetPorts() {
+	for _, portConfig := range taskconfig.GetAllPorts(taskInfo.GetConfig()) {
 		if portConfig.GetValue() == 0 {
 			// Dynamic port.
 			numPorts++
@@ -106,21 +109,23 @@ func ConvertTaskToResMgrTask(
 	}
 
 	resmgrTask := &resmgr.Task{
-		Id:           taskID,
-		JobId:        taskInfo.GetJobId(),
-		TaskId:       taskInfo.GetRuntime().GetMesosTaskId(),
-		Name:         taskInfo.GetConfig().GetName(),
-		Preemptible:  preemptible,
-		Priority:     slaConfig.GetPriority(),
-		MinInstances: minInstances,
-		Resource:     taskInfo.GetConfig().GetResource(),
-		Constraint:   taskInfo.GetConfig().GetConstraint(),
-		NumPorts:     uint32(numPorts),
-		Type:         getTaskType(taskInfo.GetConfig(), jobConfig.GetType()),
-		Labels:       util.ConvertLabels(taskInfo.GetConfig().GetLabels()),
-		Controller:   taskInfo.GetConfig().GetController(),
-		Revocable:    taskInfo.GetConfig().GetRevocable(),
-		DesiredHost:  taskInfo.GetRuntime().GetDesiredHost(),
+		Id:                          taskID,
+		JobId:                       taskInfo.GetJobId(),
+		TaskId:                      taskInfo.GetRuntime().GetMesosTaskId(),
+		Name:                        taskInfo.GetConfig().GetName(),
+		Preemptible:                 preemptible,
+		Priority:                    slaConfig.GetPriority(),
+		MinInstances:                minInstances,
+		Resource:                    taskconfig.GetPodResource(taskInfo.GetConfig()),
+		Constraint:                  getTaskConstraint(taskInfo, jobConfig.GetType()),
+		NumPorts:                    uint32(numPorts),
+		Type:                        getTaskType(taskInfo.GetConfig(), jobConfig.GetType()),
+		Labels:                      util.ConvertLabels(taskInfo.GetConfig().GetLabels()),
+		Controller:                  taskInfo.GetConfig().GetController(),
+		Revocable:                   taskInfo.GetConfig().GetRevocable(),
+		DesiredHost:                 taskInfo.GetRuntime().GetDesiredHost(),
+		MaxRunningTime:              slaConfig.GetMaxRunningTime(),
+		MaximumUnavailableInstances: slaConfig.GetMaximumUnavailableInstances(),
 	}
 
 	taskState := taskInfo.GetRuntime().GetState()
@@ -147,6 +152,46 @@ func getTaskType(cfg *task.TaskConfig, jobType job.JobType) resmgr.TaskType {
 	if jobType == job.JobType_SERVICE {
 		return resmgr.TaskType_STATELESS
 	}
+
+	if jobType == job.JobType_DAEMON {
+		return resmgr.TaskType_DAEMON
+	}
 	// By default task type is batch.
 	return resmgr.TaskType_BATCH
 }
+
+// getTaskConstraint returns the scheduling constraint of the task.
+// A daemon task is pinned to its desired host, so a hostname constraint
+// is added to the constraint in the task config.
+func getTaskConstraint(
+	taskInfo *task.TaskInfo,
+	jobType job.JobType) *task.Constraint {
+	constraint := taskInfo.GetConfig().GetConstraint()
+	desiredHost := taskInfo.GetRuntime().GetDesiredHost()
+	if jobType != job.JobType_DAEMON || len(desiredHost) == 0 {
+		return constraint
+	}
+
+	hostConstraint := &task.Constraint{
+		Type: task.Constraint_LABEL_CONSTRAINT,
+		LabelConstraint: &task.LabelConstraint{
+			Kind:      task.LabelConstraint_HOST,
+			Condition: task.LabelConstraint_CONDITION_EQUAL,
+			Label: &peloton.Label{
+				Key:   constraints.HostNameKey,
+				Value: desiredHost,
+			},
+			Requirement: 1,
+		},
+	}
+	if constraint == nil {
+		return hostConstraint
+	}
+
+	return &task.Constraint{
+		Type: task.Constraint_AND_CONSTRAINT,
+		AndConstraint: &task.AndConstraint{
+			Constraints: []*task.Constraint{constraint, hostConstraint},
+		},
+	}
+}
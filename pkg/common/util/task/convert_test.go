@@ -49,6 +49,11 @@ func TestGetTaskType(t *testing.T) {
 			jobType:  job.JobType_SERVICE,
 			taskType: resmgr.TaskType_STATELESS,
 		},
+		{
+			cfg:      &task.TaskConfig{},
+			jobType:  job.JobType_DAEMON,
+			taskType: resmgr.TaskType_DAEMON,
+		},
 	}
 
 	for _, test := range tt {
@@ -142,6 +147,104 @@ func TestConvertTaskToResMgrTask(t *testing.T) {
 	}
 }
 
+// TestConvertDaemonTaskToResMgrTask tests that a daemon task is pinned
+// to its desired host through a hostname constraint
+func TestConvertDaemonTaskToResMgrTask(t *testing.T) {
+	jobID := peloton.JobID{Value: uuid.New()}
+	rackConstraint := &task.Constraint{
+		Type: task.Constraint_LABEL_CONSTRAINT,
+		LabelConstraint: &task.LabelConstraint{
+			Kind:      task.LabelConstraint_HOST,
+			Condition: task.LabelConstraint_CONDITION_EQUAL,
+			Label: &peloton.Label{
+				Key:   "rack",
+				Value: "rack1",
+			},
+			Requirement: 1,
+		},
+	}
+	taskInfo := &task.TaskInfo{
+		InstanceId: 0,
+		JobId:      &jobID,
+		Config: &task.TaskConfig{
+			Constraint: rackConstraint,
+		},
+		Runtime: &task.RuntimeInfo{
+			State:       task.TaskState_INITIALIZED,
+			DesiredHost: "host1",
+		},
+	}
+	jobConfig := &job.JobConfig{
+		Type: job.JobType_DAEMON,
+		SLA:  &job.SlaConfig{},
+	}
+
+	rmTask := ConvertTaskToResMgrTask(taskInfo, jobConfig)
+	assert.Equal(t, resmgr.TaskType_DAEMON, rmTask.GetType())
+	assert.Equal(t, "host1", rmTask.GetDesiredHost())
+	assert.Equal(t, task.Constraint_AND_CONSTRAINT, rmTask.GetConstraint().GetType())
+	andConstraints := rmTask.GetConstraint().GetAndConstraint().GetConstraints()
+	assert.Len(t, andConstraints, 2)
+	assert.Equal(t, rackConstraint, andConstraints[0])
+	assert.Equal(t, "hostname", andConstraints[1].GetLabelConstraint().GetLabel().GetKey())
+	assert.Equal(t, "host1", andConstraints[1].GetLabelConstraint().GetLabel().GetValue())
+
+	// a daemon task without a desired host is not pinned
+	taskInfo.Runtime.DesiredHost = ""
+	rmTask = ConvertTaskToResMgrTask(taskInfo, jobConfig)
+	assert.Equal(t, rackConstraint, rmTask.GetConstraint())
+
+	// a service task keeps the desired host as a soft preference only
+	taskInfo.Runtime.DesiredHost = "host1"
+	jobConfig.Type = job.JobType_SERVICE
+	rmTask = ConvertTaskToResMgrTask(taskInfo, jobConfig)
+	assert.Equal(t, rackConstraint, rmTask.GetConstraint())
+}
+
+// TestConvertTaskToResMgrTaskMaxRunningTime tests that the max running time
+// and the maximum unavailable instances in the job SLA are passed on to
+// the resmgr task
+func TestConvertTaskToResMgrTaskMaxRunningTime(t *testing.T) {
+	jobConfig := &job.JobConfig{
+		Type: job.JobType_BATCH,
+		SLA: &job.SlaConfig{
+			MaxRunningTime:              300,
+			MaximumUnavailableInstances: 2,
+		},
+	}
+
+	rmTask := ConvertTaskToResMgrTask(&task.TaskInfo{InstanceId: 0}, jobConfig)
+	assert.Equal(t, uint32(300), rmTask.GetMaxRunningTime())
+	assert.Equal(t, uint32(2), rmTask.GetMaximumUnavailableInstances())
+}
+
+// TestConvertMultipleContainersTaskToResMgrTask tests that the resources
+// and dynamic ports of all the containers of a task are requested
+func TestConvertMultipleContainersTaskToResMgrTask(t *testing.T) {
+	taskInfo := &task.TaskInfo{
+		InstanceId: 0,
+		Config: &task.TaskConfig{
+			Resource: &task.ResourceConfig{CpuLimit: 1, MemLimitMb: 100},
+			Ports:    []*task.PortConfig{{Name: "http", EnvName: "PORT_HTTP"}},
+			Sidecars: []*task.ContainerConfig{
+				{
+					Name:     "proxy",
+					Resource: &task.ResourceConfig{CpuLimit: 1, MemLimitMb: 100},
+					Ports: []*task.PortConfig{
+						{Name: "proxy", EnvName: "PORT_PROXY"},
+						{Name: "admin", Value: 9000},
+					},
+				},
+			},
+		},
+	}
+
+	rmTask := ConvertTaskToResMgrTask(taskInfo, &job.JobConfig{})
+	assert.Equal(t, uint32(2), rmTask.GetNumPorts())
+	assert.InDelta(t, 2.1, rmTask.GetResource().GetCpuLimit(), 0.0001)
+	assert.InDelta(t, 232, rmTask.GetResource().GetMemLimitMb(), 0.0001)
+}
+
 func TestConvertToResMgrGangs(t *testing.T) {
 	jobConfig := &job.JobConfig{
 		SLA: &job.SlaConfig{
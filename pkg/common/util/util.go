@@ -42,6 +42,10 @@ const (
 	ResourceEpsilon = 0.0009
 )
 
+// containerSeparator separates the mesos task ID of a task from the name
+// of one of its sidecar or init containers in the container mesos task ID.
+const containerSeparator = "."
+
 // UUIDLength represents the length of a 16 byte v4 UUID as a string
 var UUIDLength = len(uuid.New())
 
@@ -345,6 +349,29 @@ func CreateMesosTaskID(jobID *peloton.JobID,
 	return &mesos.TaskID{Value: &mesosID}
 }
 
+// CreateContainerMesosTaskID creates the mesos task id of a sidecar or init
+// container of a task, given the mesos task id of the task and the name
+// of the container
+func CreateContainerMesosTaskID(
+	mesosTaskID *mesos.TaskID,
+	containerName string) *mesos.TaskID {
+	mesosID := mesosTaskID.GetValue() + containerSeparator + containerName
+	return &mesos.TaskID{Value: &mesosID}
+}
+
+// ParseContainerMesosTaskID splits the mesos task id of a sidecar or init
+// container into the mesos task id of its task and the container name.
+// The container name is empty if the mesos task id is the one of a task.
+func ParseContainerMesosTaskID(mesosTaskID string) (string, string) {
+	// job ids and instance ids never contain the separator,
+	// so the first one starts the container name
+	pos := strings.Index(mesosTaskID, containerSeparator)
+	if pos == -1 {
+		return mesosTaskID, ""
+	}
+	return mesosTaskID[:pos], mesosTaskID[pos+len(containerSeparator):]
+}
+
 // CreatePelotonTaskID creates a PelotonTaskID given jobID and instanceID
 func CreatePelotonTaskID(
 	jobID string,
@@ -401,7 +428,9 @@ func ParseTaskID(taskID string) (string, uint32, error) {
 
 // ParseTaskIDFromMesosTaskID parses the taskID from mesosTaskID
 func ParseTaskIDFromMesosTaskID(mesosTaskID string) (string, error) {
-	// mesos task id would be "(jobID)-(instanceID)-(runID)" form
+	// mesos task id would be "(jobID)-(instanceID)-(runID)" form,
+	// followed by the container name for sidecar and init containers
+	mesosTaskID, _ = ParseContainerMesosTaskID(mesosTaskID)
 	if len(mesosTaskID) < UUIDLength+1 {
 		return "", yarpcerrors.InvalidArgumentErrorf("invalid mesostaskID %v", mesosTaskID)
 	}
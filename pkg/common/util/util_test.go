@@ -221,6 +221,12 @@ func TestParseTaskIDFromMesosTaskID(t *testing.T) {
 			pelotonTaskID: "",
 			err:           yarpcerrors.InvalidArgumentErrorf("invalid mesostaskID Test-170-1"),
 		},
+		{
+			msg:           "Correct container mesosTaskID uuid-instanceid-runid(int).name",
+			mesosTaskID:   ID + "-170-1.log-shipper",
+			pelotonTaskID: ID + "-170",
+			err:           nil,
+		},
 		{
 			msg:           "Incorrect mesosTaskID text",
 			mesosTaskID:   "Test",
@@ -254,6 +260,24 @@ func TestParseTaskIDFromMesosTaskID(t *testing.T) {
 	}
 }
 
+// TestContainerMesosTaskID tests creating and parsing the mesos task id
+// of sidecar and init containers
+func TestContainerMesosTaskID(t *testing.T) {
+	jobID := &peloton.JobID{Value: uuid.New()}
+	mesosTaskID := CreateMesosTaskID(jobID, 1, 2)
+
+	containerTaskID := CreateContainerMesosTaskID(mesosTaskID, "proxy.v2")
+	assert.Equal(t, mesosTaskID.GetValue()+".proxy.v2", containerTaskID.GetValue())
+
+	taskID, name := ParseContainerMesosTaskID(containerTaskID.GetValue())
+	assert.Equal(t, mesosTaskID.GetValue(), taskID)
+	assert.Equal(t, "proxy.v2", name)
+
+	taskID, name = ParseContainerMesosTaskID(mesosTaskID.GetValue())
+	assert.Equal(t, mesosTaskID.GetValue(), taskID)
+	assert.Empty(t, name)
+}
+
 func TestParseJobAndInstanceID(t *testing.T) {
 	ID := uuid.New()
 	testTable := []struct {
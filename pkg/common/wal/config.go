@@ -0,0 +1,89 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"time"
+)
+
+// SyncPolicy determines when the records appended to the log
+// are flushed to disk.
+type SyncPolicy string
+
+const (
+	// SyncAlways flushes each record to disk before it is acknowledged.
+	SyncAlways SyncPolicy = "always"
+	// SyncInterval flushes the records to disk periodically, each record
+	// is acknowledged once the flush covering it completes.
+	SyncInterval SyncPolicy = "interval"
+	// SyncNone leaves flushing to the operating system, the records are
+	// acknowledged once written to the file.
+	SyncNone SyncPolicy = "none"
+)
+
+const (
+	_defaultSegmentSize   int64 = 64 * 1024 * 1024
+	_defaultSyncInterval        = 10 * time.Millisecond
+	_defaultRetentionSize int64 = 1024 * 1024 * 1024
+	_defaultRetentionAge        = 24 * time.Hour
+)
+
+// Config is the configuration of a write-ahead log
+type Config struct {
+	// Directory holding the segment files of the log
+	Dir string `yaml:"dir"`
+
+	// Size in bytes after which a new segment file is started
+	SegmentSize int64 `yaml:"segment_size"`
+
+	// Policy for flushing the records to disk
+	SyncPolicy SyncPolicy `yaml:"sync_policy"`
+
+	// Period between the flushes for the interval sync policy
+	SyncInterval time.Duration `yaml:"sync_interval"`
+
+	// Total size in bytes of the segments kept, the oldest
+	// segments are removed once it is exceeded
+	RetentionSize int64 `yaml:"retention_size"`
+
+	// Age after which a segment is removed, counted from the
+	// last record written to the segment
+	RetentionAge time.Duration `yaml:"retention_age"`
+}
+
+// Enabled returns whether a write-ahead log is configured
+func (c *Config) Enabled() bool {
+	return c.Dir != ""
+}
+
+func (c *Config) normalize() {
+	if c.SegmentSize <= 0 {
+		c.SegmentSize = _defaultSegmentSize
+	}
+	switch c.SyncPolicy {
+	case SyncAlways, SyncInterval, SyncNone:
+	default:
+		c.SyncPolicy = SyncInterval
+	}
+	if c.SyncInterval <= 0 {
+		c.SyncInterval = _defaultSyncInterval
+	}
+	if c.RetentionSize <= 0 {
+		c.RetentionSize = _defaultRetentionSize
+	}
+	if c.RetentionAge <= 0 {
+		c.RetentionAge = _defaultRetentionAge
+	}
+}
@@ -0,0 +1,465 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wal implements a write-ahead log of records stored in segment
+// files on local disk. Each record is assigned a monotonically
+// increasing offset, and the records can be read back from any offset
+// retained by the log, including after a restart. Records are only
+// visible to readers once persisted as per the sync policy.
+package wal
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/common/lifecycle"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+)
+
+// period between the checks of the retention of the segments
+const _retentionCheckPeriod = time.Minute
+
+var (
+	// ErrOutOfRange is returned when reading from an offset which is
+	// not retained by the log.
+	ErrOutOfRange = errors.New("offset out of range")
+	// ErrClosed is returned when appending to a closed log.
+	ErrClosed = errors.New("log is closed")
+)
+
+// Record is a record of the log
+type Record struct {
+	Offset uint64
+	Data   []byte
+}
+
+// AppendCallback is called once a record appended is persisted as per
+// the sync policy, or with the error which failed to persist it.
+type AppendCallback func(offset uint64, err error)
+
+// pendingAppend is a record appended which is not flushed to disk yet
+type pendingAppend struct {
+	offset   uint64
+	callback AppendCallback
+}
+
+// Log is a write-ahead log of records
+type Log struct {
+	sync.RWMutex
+	config Config
+
+	// segments of the log sorted by base offset, the last one is
+	// the one the records are appended to
+	segments []*segment
+	// offset of the next record to be appended
+	head uint64
+
+	// the records with offsets below synced have been flushed to disk,
+	// only these records are visible to readers
+	synced uint64
+	// callbacks of the records appended which are not flushed yet
+	pending []pendingAppend
+	// error which failed to flush records, once set the log does not
+	// accept appends anymore since the records not flushed may be lost
+	syncErr error
+	closed  bool
+
+	lifeCycle lifecycle.LifeCycle
+	metrics   *Metrics
+}
+
+// Open opens the log in the configured directory, creating it if it
+// does not exist. Records which were partially written when the
+// process stopped are dropped.
+func Open(config Config, scope tally.Scope) (*Log, error) {
+	config.normalize()
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create log directory")
+	}
+
+	l := &Log{
+		config:    config,
+		lifeCycle: lifecycle.NewLifeCycle(),
+		metrics:   NewMetrics(scope),
+	}
+
+	if err := l.openSegments(); err != nil {
+		l.closeSegments()
+		return nil, err
+	}
+
+	l.head = l.active().next()
+	l.synced = l.head
+	l.updateMetrics()
+
+	log.WithFields(log.Fields{
+		"dir":      config.Dir,
+		"segments": len(l.segments),
+		"tail":     l.segments[0].base,
+		"head":     l.head,
+	}).Info("Write-ahead log opened")
+
+	l.start()
+	return l, nil
+}
+
+// openSegments opens the segment files of the directory. The segments
+// followed by a corrupted record in the middle of the log are dropped,
+// so that the records retained are consecutive.
+func (l *Log) openSegments() error {
+	files, err := ioutil.ReadDir(l.config.Dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to read log directory")
+	}
+
+	var bases []uint64
+	for _, f := range files {
+		if base, ok := parseSegmentName(f.Name()); ok && !f.IsDir() {
+			bases = append(bases, base)
+		}
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+
+	var garbage []int64
+	for _, base := range bases {
+		s, g, err := openSegment(l.config.Dir, base)
+		if err != nil {
+			return err
+		}
+		l.segments = append(l.segments, s)
+		garbage = append(garbage, g)
+	}
+
+	for i := len(l.segments) - 2; i >= 0; i-- {
+		if garbage[i] == 0 && l.segments[i].next() == l.segments[i+1].base {
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"segment":  l.segments[i].path,
+			"next":     l.segments[i].next(),
+			"next_log": l.segments[i+1].base,
+		}).Error("Corrupted write-ahead log segment, dropping the older segments")
+		l.metrics.CorruptRecords.Inc(1)
+		for _, s := range l.segments[:i+1] {
+			if err := s.remove(); err != nil {
+				return err
+			}
+		}
+		l.segments = l.segments[i+1:]
+		garbage = garbage[i+1:]
+		break
+	}
+
+	if len(l.segments) == 0 {
+		s, err := createSegment(l.config.Dir, 0)
+		if err != nil {
+			return err
+		}
+		l.segments = append(l.segments, s)
+		return nil
+	}
+
+	// drop the record partially written at the end of the log
+	if garbage[len(garbage)-1] > 0 {
+		log.WithField("segment", l.active().path).
+			Warn("Dropping partially written record of write-ahead log")
+		l.metrics.CorruptRecords.Inc(1)
+		if err := l.active().truncate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start starts flushing the records for the interval sync policy, and
+// removing the segments which are no longer retained.
+func (l *Log) start() {
+	if !l.lifeCycle.Start() {
+		return
+	}
+
+	go func() {
+		defer l.lifeCycle.StopComplete()
+
+		var syncCh <-chan time.Time
+		if l.config.SyncPolicy == SyncInterval {
+			syncTicker := time.NewTicker(l.config.SyncInterval)
+			defer syncTicker.Stop()
+			syncCh = syncTicker.C
+		}
+		retentionTicker := time.NewTicker(_retentionCheckPeriod)
+		defer retentionTicker.Stop()
+
+		for {
+			select {
+			case <-l.lifeCycle.StopCh():
+				return
+			case <-syncCh:
+				l.Lock()
+				done, err := l.sync()
+				l.Unlock()
+				notify(done, err)
+			case <-retentionTicker.C:
+				l.Lock()
+				l.enforceRetention()
+				l.Unlock()
+			}
+		}
+	}()
+}
+
+// Close flushes the records to disk and closes the segment files.
+func (l *Log) Close() error {
+	if !l.lifeCycle.Stop() {
+		return nil
+	}
+	l.lifeCycle.Wait()
+
+	l.Lock()
+	done, err := l.sync()
+	l.closed = true
+	l.closeSegments()
+	l.Unlock()
+
+	notify(done, err)
+	return err
+}
+
+// Append appends a record to the log and returns its offset without
+// waiting for the record to be flushed to disk. The callback, if not
+// nil, is called once the record is persisted as per the sync policy,
+// or with the error which failed to persist it. The callback is not
+// called if Append returns an error. Callbacks are called in the order
+// of the records, and must not block for long since they delay the
+// callbacks of the following records.
+func (l *Log) Append(data []byte, callback AppendCallback) (uint64, error) {
+	var done []pendingAppend
+	var syncErr error
+	defer func() { notify(done, syncErr) }()
+
+	l.Lock()
+	defer l.Unlock()
+
+	if l.closed {
+		return 0, ErrClosed
+	}
+	if l.syncErr != nil {
+		l.metrics.AppendFail.Inc(1)
+		return 0, l.syncErr
+	}
+
+	if l.active().size >= l.config.SegmentSize {
+		if done, syncErr = l.roll(); syncErr != nil {
+			l.metrics.AppendFail.Inc(1)
+			return 0, syncErr
+		}
+	}
+
+	if err := l.active().append(data); err != nil {
+		l.metrics.AppendFail.Inc(1)
+		return 0, err
+	}
+	offset := l.head
+	l.head++
+	l.metrics.Append.Inc(1)
+
+	switch l.config.SyncPolicy {
+	case SyncAlways:
+		l.pending = append(l.pending, pendingAppend{offset, callback})
+		var syncDone []pendingAppend
+		syncDone, syncErr = l.sync()
+		done = append(done, syncDone...)
+	case SyncInterval:
+		l.pending = append(l.pending, pendingAppend{offset, callback})
+	default:
+		// records are persisted once written to the file
+		l.synced = l.head
+		done = append(done, pendingAppend{offset, callback})
+	}
+	l.updateMetrics()
+	return offset, nil
+}
+
+// Read returns up to max records starting at offset from. Returns
+// ErrOutOfRange if from is older than the oldest record retained,
+// or newer than the next record persisted.
+func (l *Log) Read(from uint64, max int) ([]Record, error) {
+	l.RLock()
+	defer l.RUnlock()
+
+	if from < l.segments[0].base || from > l.synced {
+		l.metrics.ReadFail.Inc(1)
+		return nil, ErrOutOfRange
+	}
+	if head := l.synced - from; uint64(max) > head {
+		max = int(head)
+	}
+
+	// first segment which may hold the record at offset from
+	i := sort.Search(len(l.segments), func(i int) bool {
+		return l.segments[i].base > from
+	}) - 1
+
+	var records []Record
+	for ; i < len(l.segments) && len(records) < max; i++ {
+		rs, err := l.segments[i].read(from, max-len(records))
+		if err != nil {
+			l.metrics.ReadFail.Inc(1)
+			return nil, err
+		}
+		records = append(records, rs...)
+		from = l.segments[i].next()
+	}
+
+	l.metrics.Read.Inc(1)
+	return records, nil
+}
+
+// Range returns the offset of the oldest record retained, and the
+// offset following the last record persisted.
+func (l *Log) Range() (uint64, uint64) {
+	l.RLock()
+	defer l.RUnlock()
+	return l.segments[0].base, l.synced
+}
+
+// active returns the segment the records are appended to
+func (l *Log) active() *segment {
+	return l.segments[len(l.segments)-1]
+}
+
+// roll starts a new segment after flushing the active one. Returns the
+// records flushed. Must be called with the lock held.
+func (l *Log) roll() ([]pendingAppend, error) {
+	done, err := l.sync()
+	if err != nil {
+		return done, err
+	}
+
+	s, err := createSegment(l.config.Dir, l.head)
+	if err != nil {
+		return done, err
+	}
+	l.segments = append(l.segments, s)
+	l.enforceRetention()
+	return done, nil
+}
+
+// sync flushes the records appended to disk, and makes them visible to
+// readers. Returns the records flushed, or the records which failed to
+// be flushed along with the error. Once a flush fails, the log is
+// poisoned since the records not flushed may be lost. Must be called
+// with the lock held.
+func (l *Log) sync() ([]pendingAppend, error) {
+	if l.syncErr != nil {
+		return nil, l.syncErr
+	}
+	if l.synced == l.head || l.closed {
+		return nil, nil
+	}
+
+	sw := l.metrics.SyncDuration.Start()
+	err := l.active().sync()
+	sw.Stop()
+
+	done := l.pending
+	l.pending = nil
+	if err != nil {
+		log.WithError(err).Error("Failed to sync write-ahead log")
+		l.metrics.SyncFail.Inc(1)
+		l.syncErr = errors.Wrap(err, "write-ahead log failed to persist records")
+		return done, l.syncErr
+	}
+
+	l.metrics.Sync.Inc(1)
+	l.synced = l.head
+	l.updateMetrics()
+	return done, nil
+}
+
+// notify calls the callbacks of the records flushed, and must be called
+// without holding the lock.
+func notify(done []pendingAppend, err error) {
+	for _, p := range done {
+		if p.callback != nil {
+			p.callback(p.offset, err)
+		}
+	}
+}
+
+// enforceRetention removes the oldest segments once the log exceeds the
+// retention size, or once they exceed the retention age. The segment
+// the records are appended to is never removed. Must be called with
+// the lock held.
+func (l *Log) enforceRetention() {
+	var size int64
+	for _, s := range l.segments {
+		size += s.size
+	}
+
+	now := time.Now()
+	for len(l.segments) > 1 {
+		s := l.segments[0]
+		if size <= l.config.RetentionSize &&
+			now.Sub(s.lastWrite) <= l.config.RetentionAge {
+			break
+		}
+
+		if err := s.remove(); err != nil {
+			log.WithError(err).
+				WithField("segment", s.path).
+				Error("Failed to remove write-ahead log segment")
+			break
+		}
+		log.WithFields(log.Fields{
+			"segment": s.path,
+			"records": len(s.positions),
+		}).Info("Write-ahead log segment removed")
+		l.metrics.SegmentsRemoved.Inc(1)
+
+		size -= s.size
+		l.segments = l.segments[1:]
+	}
+
+	if err := syncDir(l.config.Dir); err != nil {
+		log.WithError(err).Warn("Failed to sync write-ahead log directory")
+	}
+	l.updateMetrics()
+}
+
+func (l *Log) closeSegments() {
+	for _, s := range l.segments {
+		s.close()
+	}
+}
+
+// updateMetrics updates the gauges of the log.
+// Must be called with the lock held.
+func (l *Log) updateMetrics() {
+	var size int64
+	for _, s := range l.segments {
+		size += s.size
+	}
+	l.metrics.Segments.Update(float64(len(l.segments)))
+	l.metrics.Size.Update(float64(size))
+	l.metrics.Head.Update(float64(l.synced))
+	l.metrics.Tail.Update(float64(l.segments[0].base))
+}
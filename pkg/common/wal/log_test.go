@@ -0,0 +1,353 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func newTestConfig(t *testing.T) (Config, func()) {
+	dir, err := ioutil.TempDir("", "wal")
+	require.NoError(t, err)
+	return Config{
+		Dir:        dir,
+		SyncPolicy: SyncAlways,
+	}, func() { os.RemoveAll(dir) }
+}
+
+func appendRecords(t *testing.T, l *Log, from, to int) {
+	for i := from; i < to; i++ {
+		offset, err := l.Append([]byte(fmt.Sprintf("record-%d", i)), nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(i), offset)
+	}
+}
+
+func checkRecords(t *testing.T, records []Record, from, to int) {
+	require.Len(t, records, to-from)
+	for i, r := range records {
+		assert.Equal(t, uint64(from+i), r.Offset)
+		assert.Equal(t, fmt.Sprintf("record-%d", from+i), string(r.Data))
+	}
+}
+
+func TestLogAppendRead(t *testing.T) {
+	config, cleanup := newTestConfig(t)
+	defer cleanup()
+
+	l, err := Open(config, tally.NoopScope)
+	require.NoError(t, err)
+	defer l.Close()
+
+	appendRecords(t, l, 0, 10)
+	tail, head := l.Range()
+	assert.Equal(t, uint64(0), tail)
+	assert.Equal(t, uint64(10), head)
+
+	records, err := l.Read(0, 100)
+	assert.NoError(t, err)
+	checkRecords(t, records, 0, 10)
+
+	records, err = l.Read(3, 4)
+	assert.NoError(t, err)
+	checkRecords(t, records, 3, 7)
+
+	records, err = l.Read(10, 4)
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+
+	_, err = l.Read(11, 4)
+	assert.Equal(t, ErrOutOfRange, err)
+}
+
+func TestLogReopen(t *testing.T) {
+	config, cleanup := newTestConfig(t)
+	defer cleanup()
+
+	l, err := Open(config, tally.NoopScope)
+	require.NoError(t, err)
+	appendRecords(t, l, 0, 5)
+	require.NoError(t, l.Close())
+
+	_, err = l.Append([]byte("closed"), nil)
+	assert.Equal(t, ErrClosed, err)
+
+	l, err = Open(config, tally.NoopScope)
+	require.NoError(t, err)
+	defer l.Close()
+
+	appendRecords(t, l, 5, 8)
+	records, err := l.Read(0, 100)
+	assert.NoError(t, err)
+	checkRecords(t, records, 0, 8)
+}
+
+// TestLogTruncateTornRecord tests that a record partially written
+// when the process stopped is dropped when the log is opened
+func TestLogTruncateTornRecord(t *testing.T) {
+	config, cleanup := newTestConfig(t)
+	defer cleanup()
+
+	l, err := Open(config, tally.NoopScope)
+	require.NoError(t, err)
+	appendRecords(t, l, 0, 3)
+	require.NoError(t, l.Close())
+
+	path := segmentPath(config.Dir, 0)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0, 20, 1, 2, 3, 4, 'p', 'a', 'r'})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	l, err = Open(config, tally.NoopScope)
+	require.NoError(t, err)
+	defer l.Close()
+
+	_, head := l.Range()
+	assert.Equal(t, uint64(3), head)
+	appendRecords(t, l, 3, 5)
+	records, err := l.Read(0, 100)
+	assert.NoError(t, err)
+	checkRecords(t, records, 0, 5)
+}
+
+// TestLogCorruptSegment tests that the segments older than a corrupted
+// record are dropped so that the records retained are consecutive
+func TestLogCorruptSegment(t *testing.T) {
+	config, cleanup := newTestConfig(t)
+	defer cleanup()
+	config.SegmentSize = 1
+
+	l, err := Open(config, tally.NoopScope)
+	require.NoError(t, err)
+	appendRecords(t, l, 0, 4)
+	require.NoError(t, l.Close())
+
+	// flip a byte of the data of the second record
+	path := segmentPath(config.Dir, 1)
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	data[_headerSize] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(path, data, 0644))
+
+	l, err = Open(config, tally.NoopScope)
+	require.NoError(t, err)
+	defer l.Close()
+
+	tail, head := l.Range()
+	assert.Equal(t, uint64(2), tail)
+	assert.Equal(t, uint64(4), head)
+	_, err = l.Read(0, 100)
+	assert.Equal(t, ErrOutOfRange, err)
+	records, err := l.Read(2, 100)
+	assert.NoError(t, err)
+	checkRecords(t, records, 2, 4)
+}
+
+func TestLogSegmentRoll(t *testing.T) {
+	config, cleanup := newTestConfig(t)
+	defer cleanup()
+	// each record is 8 bytes of header and 8 bytes of data
+	config.SegmentSize = 40
+
+	l, err := Open(config, tally.NoopScope)
+	require.NoError(t, err)
+	defer l.Close()
+
+	appendRecords(t, l, 0, 10)
+	files, err := filepath.Glob(filepath.Join(config.Dir, "*"+_segmentSuffix))
+	assert.NoError(t, err)
+	assert.Len(t, files, 4)
+
+	// reads span the segments
+	records, err := l.Read(1, 100)
+	assert.NoError(t, err)
+	checkRecords(t, records, 1, 10)
+	records, err = l.Read(2, 5)
+	assert.NoError(t, err)
+	checkRecords(t, records, 2, 7)
+}
+
+func TestLogRetentionSize(t *testing.T) {
+	config, cleanup := newTestConfig(t)
+	defer cleanup()
+	config.SegmentSize = 40
+	config.RetentionSize = 100
+
+	l, err := Open(config, tally.NoopScope)
+	require.NoError(t, err)
+	defer l.Close()
+
+	appendRecords(t, l, 0, 10)
+	tail, head := l.Range()
+	assert.Equal(t, uint64(3), tail)
+	assert.Equal(t, uint64(10), head)
+
+	_, err = l.Read(0, 100)
+	assert.Equal(t, ErrOutOfRange, err)
+	records, err := l.Read(tail, 100)
+	assert.NoError(t, err)
+	checkRecords(t, records, 3, 10)
+}
+
+func TestLogRetentionAge(t *testing.T) {
+	config, cleanup := newTestConfig(t)
+	defer cleanup()
+	config.SegmentSize = 40
+
+	l, err := Open(config, tally.NoopScope)
+	require.NoError(t, err)
+	defer l.Close()
+
+	appendRecords(t, l, 0, 6)
+	l.Lock()
+	for _, s := range l.segments[:len(l.segments)-1] {
+		s.lastWrite = time.Now().Add(-2 * l.config.RetentionAge)
+	}
+	l.enforceRetention()
+	l.Unlock()
+
+	tail, head := l.Range()
+	assert.Equal(t, uint64(3), tail)
+	assert.Equal(t, uint64(6), head)
+}
+
+func TestLogSyncPolicies(t *testing.T) {
+	for _, policy := range []SyncPolicy{SyncAlways, SyncInterval, SyncNone} {
+		t.Run(string(policy), func(t *testing.T) {
+			config, cleanup := newTestConfig(t)
+			defer cleanup()
+			config.SyncPolicy = policy
+
+			l, err := Open(config, tally.NoopScope)
+			require.NoError(t, err)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				_, err := l.Append([]byte("record"), func(_ uint64, err error) {
+					assert.NoError(t, err)
+					wg.Done()
+				})
+				assert.NoError(t, err)
+			}
+			wg.Wait()
+			_, head := l.Range()
+			assert.Equal(t, uint64(10), head)
+			require.NoError(t, l.Close())
+
+			l, err = Open(config, tally.NoopScope)
+			require.NoError(t, err)
+			defer l.Close()
+			_, head = l.Range()
+			assert.Equal(t, uint64(10), head)
+		})
+	}
+}
+
+// TestLogReadPersistedRecords tests that the records are visible to
+// readers only once flushed to disk
+func TestLogReadPersistedRecords(t *testing.T) {
+	config, cleanup := newTestConfig(t)
+	defer cleanup()
+	config.SyncPolicy = SyncInterval
+	config.SyncInterval = time.Hour
+
+	l, err := Open(config, tally.NoopScope)
+	require.NoError(t, err)
+
+	var persisted []uint64
+	for i := 0; i < 3; i++ {
+		offset, err := l.Append([]byte(fmt.Sprintf("record-%d", i)), func(offset uint64, err error) {
+			assert.NoError(t, err)
+			persisted = append(persisted, offset)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(i), offset)
+	}
+
+	_, head := l.Range()
+	assert.Equal(t, uint64(0), head)
+	assert.Empty(t, persisted)
+	records, err := l.Read(0, 100)
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+
+	l.Lock()
+	done, err := l.sync()
+	l.Unlock()
+	assert.NoError(t, err)
+	notify(done, err)
+
+	assert.Equal(t, []uint64{0, 1, 2}, persisted)
+	records, err = l.Read(0, 100)
+	assert.NoError(t, err)
+	checkRecords(t, records, 0, 3)
+	assert.NoError(t, l.Close())
+}
+
+// TestLogSyncFailure tests that the log does not accept records once
+// it failed to flush records to disk
+func TestLogSyncFailure(t *testing.T) {
+	config, cleanup := newTestConfig(t)
+	defer cleanup()
+	config.SyncPolicy = SyncInterval
+	config.SyncInterval = time.Hour
+
+	l, err := Open(config, tally.NoopScope)
+	require.NoError(t, err)
+	defer l.Close()
+
+	var syncErr error
+	_, err = l.Append([]byte("record"), func(_ uint64, err error) {
+		syncErr = err
+	})
+	assert.NoError(t, err)
+
+	l.Lock()
+	l.active().file.Close()
+	done, err := l.sync()
+	l.Unlock()
+	assert.Error(t, err)
+	notify(done, err)
+	assert.Error(t, syncErr)
+
+	_, err = l.Append([]byte("record"), nil)
+	assert.Error(t, err)
+	_, head := l.Range()
+	assert.Equal(t, uint64(0), head)
+}
+
+func TestConfigNormalize(t *testing.T) {
+	config := Config{SyncPolicy: "unknown"}
+	assert.False(t, config.Enabled())
+	config.normalize()
+	assert.Equal(t, SyncInterval, config.SyncPolicy)
+	assert.Equal(t, _defaultSegmentSize, config.SegmentSize)
+	assert.Equal(t, _defaultSyncInterval, config.SyncInterval)
+	assert.Equal(t, _defaultRetentionSize, config.RetentionSize)
+	assert.Equal(t, _defaultRetentionAge, config.RetentionAge)
+}
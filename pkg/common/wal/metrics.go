@@ -0,0 +1,63 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the metrics for write-ahead log
+type Metrics struct {
+	Append     tally.Counter
+	AppendFail tally.Counter
+	Read       tally.Counter
+	ReadFail   tally.Counter
+	Sync       tally.Counter
+	SyncFail   tally.Counter
+
+	SyncDuration tally.Timer
+
+	// Records dropped when the log was opened because they were
+	// partially written or corrupted
+	CorruptRecords tally.Counter
+	// Segments removed by the retention policy
+	SegmentsRemoved tally.Counter
+
+	Segments tally.Gauge
+	Size     tally.Gauge
+	Head     tally.Gauge
+	Tail     tally.Gauge
+}
+
+// NewMetrics creates a Metrics
+func NewMetrics(scope tally.Scope) *Metrics {
+	successScope := scope.Tagged(map[string]string{"result": "success"})
+	failScope := scope.Tagged(map[string]string{"result": "fail"})
+	return &Metrics{
+		Append:          successScope.Counter("append"),
+		AppendFail:      failScope.Counter("append"),
+		Read:            successScope.Counter("read"),
+		ReadFail:        failScope.Counter("read"),
+		Sync:            successScope.Counter("sync"),
+		SyncFail:        failScope.Counter("sync"),
+		SyncDuration:    scope.Timer("sync_duration"),
+		CorruptRecords:  scope.Counter("corrupt_records"),
+		SegmentsRemoved: scope.Counter("segments_removed"),
+		Segments:        scope.Gauge("segments"),
+		Size:            scope.Gauge("size"),
+		Head:            scope.Gauge("head"),
+		Tail:            scope.Gauge("tail"),
+	}
+}
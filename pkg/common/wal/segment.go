@@ -0,0 +1,234 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	_segmentSuffix = ".wal"
+	// each record is prefixed by the length and the checksum of its data
+	_headerSize = 8
+)
+
+// segment is a file holding consecutive records of the log
+type segment struct {
+	// offset of the first record of the segment
+	base uint64
+	path string
+	file *os.File
+	// file positions of the records
+	positions []int64
+	size      int64
+	// time when the last record was written to the segment
+	lastWrite time.Time
+}
+
+// segmentPath returns the path of the segment file starting at base
+func segmentPath(dir string, base uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", base, _segmentSuffix))
+}
+
+// parseSegmentName returns the base offset of a segment file name,
+// and whether the name is the one of a segment file
+func parseSegmentName(name string) (uint64, bool) {
+	if !strings.HasSuffix(name, _segmentSuffix) {
+		return 0, false
+	}
+	base, err := strconv.ParseUint(strings.TrimSuffix(name, _segmentSuffix), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return base, true
+}
+
+// createSegment creates an empty segment file starting at base
+func createSegment(dir string, base uint64) (*segment, error) {
+	path := segmentPath(dir, base)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create segment")
+	}
+	if err := syncDir(dir); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &segment{
+		base:      base,
+		path:      path,
+		file:      file,
+		lastWrite: time.Now(),
+	}, nil
+}
+
+// openSegment opens an existing segment file and indexes its records.
+// Returns the number of bytes at the end of the file which do not hold
+// a valid record, and which are not part of the segment.
+func openSegment(dir string, base uint64) (*segment, int64, error) {
+	path := segmentPath(dir, base)
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to open segment")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, errors.Wrap(err, "failed to stat segment")
+	}
+
+	s := &segment{
+		base:      base,
+		path:      path,
+		file:      file,
+		lastWrite: info.ModTime(),
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(file, 0, info.Size()))
+	header := make([]byte, _headerSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			// end of file, or a partially written header
+			break
+		}
+		length := int64(binary.BigEndian.Uint32(header[0:4]))
+		if s.size+_headerSize+length > info.Size() {
+			break
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			break
+		}
+		if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(header[4:8]) {
+			break
+		}
+		s.positions = append(s.positions, s.size)
+		s.size += _headerSize + length
+	}
+
+	return s, info.Size() - s.size, nil
+}
+
+// next returns the offset of the record following the segment
+func (s *segment) next() uint64 {
+	return s.base + uint64(len(s.positions))
+}
+
+// append writes a record at the end of the segment
+func (s *segment) append(data []byte) error {
+	buf := make([]byte, _headerSize+len(data))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(data))
+	copy(buf[_headerSize:], data)
+
+	if _, err := s.file.WriteAt(buf, s.size); err != nil {
+		// drop the partially written record
+		s.file.Truncate(s.size)
+		return errors.Wrap(err, "failed to write record")
+	}
+
+	s.positions = append(s.positions, s.size)
+	s.size += int64(len(buf))
+	s.lastWrite = time.Now()
+	return nil
+}
+
+// read returns up to max records of the segment starting at offset from
+func (s *segment) read(from uint64, max int) ([]Record, error) {
+	first := int(from - s.base)
+	last := first + max
+	if last > len(s.positions) {
+		last = len(s.positions)
+	}
+	if first >= last {
+		return nil, nil
+	}
+
+	start := s.positions[first]
+	end := s.size
+	if last < len(s.positions) {
+		end = s.positions[last]
+	}
+
+	// read all the records at once
+	buf := make([]byte, end-start)
+	if _, err := s.file.ReadAt(buf, start); err != nil {
+		return nil, errors.Wrap(err, "failed to read records")
+	}
+
+	records := make([]Record, 0, last-first)
+	for i := first; i < last; i++ {
+		pos := s.positions[i] - start
+		length := int64(binary.BigEndian.Uint32(buf[pos : pos+4]))
+		records = append(records, Record{
+			Offset: s.base + uint64(i),
+			Data:   buf[pos+_headerSize : pos+_headerSize+length],
+		})
+	}
+	return records, nil
+}
+
+// truncate removes the bytes of the file after the last record
+func (s *segment) truncate() error {
+	if err := s.file.Truncate(s.size); err != nil {
+		return errors.Wrap(err, "failed to truncate segment")
+	}
+	return s.sync()
+}
+
+func (s *segment) sync() error {
+	if err := s.file.Sync(); err != nil {
+		return errors.Wrap(err, "failed to sync segment")
+	}
+	return nil
+}
+
+func (s *segment) close() error {
+	return s.file.Close()
+}
+
+// remove closes and deletes the segment file
+func (s *segment) remove() error {
+	s.close()
+	if err := os.Remove(s.path); err != nil {
+		return errors.Wrap(err, "failed to remove segment")
+	}
+	return nil
+}
+
+// syncDir flushes the entries of a directory to disk, so that the
+// segment files created or removed survive a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to open log directory")
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return errors.Wrap(err, "failed to sync log directory")
+	}
+	return nil
+}
@@ -24,6 +24,9 @@ const (
 
 	// FirstFit is the name of the First Fit policy
 	FirstFit = "FIRST_FIT"
+
+	// WeightedScore is the name of the Weighted Score policy
+	WeightedScore = "WEIGHTED_SCORE"
 )
 
 // RankerFunc type of func which returns Ranker interface
@@ -47,10 +50,18 @@ func Register(name string, ranker RankerFunc) {
 	rankers[name] = ranker
 }
 
-// Init registers all the rankers
+// Init registers all the rankers, the weighted
+// score ranker with the default config
 func Init() {
+	InitWithConfig(nil)
+}
+
+// InitWithConfig registers all the rankers, the weighted
+// score ranker with the given config
+func InitWithConfig(weightedRankerConfig *WeightedRankerConfig) {
 	Register(DeFrag, NewDeFragRanker)
 	Register(FirstFit, NewFirstFitRanker)
+	Register(WeightedScore, NewWeightedRanker(weightedRankerConfig))
 }
 
 // CreateRanker creates and returns the ranker specified
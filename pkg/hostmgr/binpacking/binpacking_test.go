@@ -35,6 +35,7 @@ func (suite *BinPackingTestSuite) SetupTest() {
 func (suite *BinPackingTestSuite) TestInit() {
 	suite.EqualValues(rankers[DeFrag]().Name(), DeFrag)
 	suite.EqualValues(rankers[FirstFit]().Name(), FirstFit)
+	suite.EqualValues(rankers[WeightedScore]().Name(), WeightedScore)
 }
 
 func (suite *BinPackingTestSuite) TestRegister() {
@@ -0,0 +1,229 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binpacking
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/uber/peloton/pkg/hostmgr/host"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	"github.com/uber/peloton/pkg/hostmgr/summary"
+	"github.com/uber/peloton/pkg/hostmgr/util"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Pack ranks the most allocated hosts first
+	Pack = "PACK"
+
+	// Spread ranks the least allocated hosts first
+	Spread = "SPREAD"
+)
+
+// WeightedRankerConfig is the config of the weighted score ranker
+type WeightedRankerConfig struct {
+	// Mode is PACK to rank the most allocated hosts first,
+	// or SPREAD to rank the least allocated hosts first.
+	// Defaults to PACK, any other mode is rejected by Validate.
+	Mode string `yaml:"mode"`
+
+	// Weights of the allocated share of each resource in the score
+	// of a host. All the resources are weighted equally if none is set.
+	CPUWeight  float64 `yaml:"cpu_weight"`
+	MemWeight  float64 `yaml:"mem_weight"`
+	DiskWeight float64 `yaml:"disk_weight"`
+	GPUWeight  float64 `yaml:"gpu_weight"`
+
+	// Weight of the dominant resource share of a host in its score,
+	// which is the largest allocated share of its resources
+	DominantShareWeight float64 `yaml:"dominant_share_weight"`
+
+	// PreserveGPUHosts ranks the hosts with GPUs after the hosts
+	// without GPUs, so that they are left for the GPU tasks
+	PreserveGPUHosts bool `yaml:"preserve_gpu_hosts"`
+}
+
+// Validate validates WeightedRankerConfig
+func (c *WeightedRankerConfig) Validate() error {
+	switch c.Mode {
+	case "", Pack, Spread:
+		return nil
+	default:
+		return fmt.Errorf("invalid weighted ranker mode %q", c.Mode)
+	}
+}
+
+// weights returns the weights of cpu, mem, disk and gpu
+func (c *WeightedRankerConfig) weights() scalar.Resources {
+	weights := scalar.Resources{
+		CPU:  c.CPUWeight,
+		Mem:  c.MemWeight,
+		Disk: c.DiskWeight,
+		GPU:  c.GPUWeight,
+	}
+	if weights.Empty() {
+		return scalar.Resources{CPU: 1, Mem: 1, Disk: 1, GPU: 1}
+	}
+	return weights
+}
+
+// weightedRanker is the struct for implementation of
+// Weighted Score Ranker
+type weightedRanker struct {
+	mu          sync.RWMutex
+	name        string
+	config      WeightedRankerConfig
+	summaryList []interface{}
+
+	// totalResources returns the total resources of a host
+	totalResources func(hostname string) scalar.Resources
+}
+
+// NewWeightedRanker returns the weighted score ranker func
+// for the config
+func NewWeightedRanker(config *WeightedRankerConfig) RankerFunc {
+	if config == nil {
+		config = &WeightedRankerConfig{}
+	}
+	return func() Ranker {
+		return &weightedRanker{
+			name:           WeightedScore,
+			config:         *config,
+			totalResources: agentTotalResources,
+		}
+	}
+}
+
+// agentTotalResources returns the total resources of the
+// host registered in the agent map
+func agentTotalResources(hostname string) scalar.Resources {
+	return scalar.FromMesosResources(
+		host.GetAgentInfo(hostname).GetResources())
+}
+
+// Name is the implementation for Ranker interface.Name method
+// returns the name
+func (w *weightedRanker) Name() string {
+	return w.name
+}
+
+// GetRankedHostList returns the host list ranked by score.
+// This checks if there is already a list present pass that
+// and it depends on RefreshRanking to refresh the list
+func (w *weightedRanker) GetRankedHostList(
+	offerIndex map[string]summary.HostSummary) []interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	log.Debugf(" %s ranker GetRankedHostList is been called", w.Name())
+	if len(w.summaryList) == 0 {
+		w.summaryList = w.getRankedHostList(offerIndex)
+	}
+	return w.summaryList
+}
+
+// RefreshRanking refreshes the hostlist based on new host summary index
+// This function has to be called periodically to refresh the list
+func (w *weightedRanker) RefreshRanking(offerIndex map[string]summary.HostSummary) {
+	summaryList := w.getRankedHostList(offerIndex)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.summaryList = summaryList
+}
+
+// rankedHost is a host with its ranking
+type rankedHost struct {
+	summary summary.HostSummary
+	hasGPU  bool
+	score   float64
+}
+
+// getRankedHostList this is the unprotected method for ranking the
+// offer index by score, highest first. Ties are broken by hostname.
+func (w *weightedRanker) getRankedHostList(
+	offerIndex map[string]summary.HostSummary) []interface{} {
+	hosts := make([]*rankedHost, 0, len(offerIndex))
+	for _, s := range offerIndex {
+		free := util.GetResourcesFromOffers(s.GetOffers(summary.All))
+		total := w.totalResources(s.GetHostname())
+		hosts = append(hosts, &rankedHost{
+			summary: s,
+			hasGPU:  free.HasGPU() || total.HasGPU(),
+			score:   w.score(free, total),
+		})
+	}
+
+	sort.Slice(hosts, func(i, j int) bool {
+		if w.config.PreserveGPUHosts && hosts[i].hasGPU != hosts[j].hasGPU {
+			return !hosts[i].hasGPU
+		}
+		if hosts[i].score != hosts[j].score {
+			return hosts[i].score > hosts[j].score
+		}
+		return hosts[i].summary.GetHostname() < hosts[j].summary.GetHostname()
+	})
+
+	summaryList := make([]interface{}, 0, len(hosts))
+	for _, h := range hosts {
+		summaryList = append(summaryList, h.summary)
+	}
+	return summaryList
+}
+
+// score returns the score of a host in [0, 1] from its free and total
+// resources. For PACK it is the weighted allocated share of its resources,
+// and for SPREAD the weighted free share.
+func (w *weightedRanker) score(free, total scalar.Resources) float64 {
+	weights := w.config.weights()
+
+	var weighted, sum, dominant float64
+	for _, r := range []struct {
+		weight, free, total float64
+	}{
+		{weights.CPU, free.CPU, total.CPU},
+		{weights.Mem, free.Mem, total.Mem},
+		{weights.Disk, free.Disk, total.Disk},
+		{weights.GPU, free.GPU, total.GPU},
+	} {
+		share := allocatedShare(r.free, r.total)
+		dominant = math.Max(dominant, share)
+		weighted += r.weight * share
+		sum += r.weight
+	}
+
+	weighted += w.config.DominantShareWeight * dominant
+	sum += w.config.DominantShareWeight
+	if sum <= 0 {
+		return 0
+	}
+
+	score := weighted / sum
+	if w.config.Mode == Spread {
+		return 1 - score
+	}
+	return score
+}
+
+// allocatedShare returns the share of a resource which is allocated.
+// The free resource is the total if the total is unknown.
+func allocatedShare(free, total float64) float64 {
+	if total <= free || total <= 0 {
+		return 0
+	}
+	return (total - free) / total
+}
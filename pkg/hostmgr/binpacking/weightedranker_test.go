@@ -0,0 +1,179 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binpacking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	"github.com/uber/peloton/pkg/hostmgr/summary"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WeightedRankerTestSuite struct {
+	suite.Suite
+	offerIndex map[string]summary.HostSummary
+	totals     map[string]scalar.Resources
+}
+
+func TestWeightedRankerTestSuite(t *testing.T) {
+	suite.Run(t, new(WeightedRankerTestSuite))
+}
+
+func (suite *WeightedRankerTestSuite) SetupTest() {
+	suite.offerIndex = make(map[string]summary.HostSummary)
+	suite.totals = make(map[string]scalar.Resources)
+
+	// 75% allocated
+	suite.addHost("hostA",
+		scalar.Resources{CPU: 1, Mem: 1, Disk: 1},
+		scalar.Resources{CPU: 4, Mem: 4, Disk: 4})
+	// 25% allocated
+	suite.addHost("hostB",
+		scalar.Resources{CPU: 3, Mem: 3, Disk: 3},
+		scalar.Resources{CPU: 4, Mem: 4, Disk: 4})
+	// 50% allocated, with free GPUs
+	suite.addHost("hostC",
+		scalar.Resources{CPU: 2, Mem: 2, Disk: 2, GPU: 2},
+		scalar.Resources{CPU: 4, Mem: 4, Disk: 4, GPU: 2})
+	// total unknown
+	suite.addHost("hostD",
+		scalar.Resources{CPU: 2, Mem: 2, Disk: 2},
+		scalar.Resources{})
+}
+
+func (suite *WeightedRankerTestSuite) addHost(
+	hostname string,
+	free scalar.Resources,
+	total scalar.Resources) {
+	s := summary.New(nil, nil, hostname, nil, time.Duration(30*time.Second))
+	s.AddMesosOffers(context.Background(), []*mesos.Offer{CreateOffer(hostname, free)})
+	suite.offerIndex[hostname] = s
+	suite.totals[hostname] = total
+}
+
+func (suite *WeightedRankerTestSuite) newRanker(config *WeightedRankerConfig) Ranker {
+	ranker := NewWeightedRanker(config)()
+	ranker.(*weightedRanker).totalResources = func(hostname string) scalar.Resources {
+		return suite.totals[hostname]
+	}
+	return ranker
+}
+
+// hostnames returns the hostnames of the ranked host list
+func (suite *WeightedRankerTestSuite) hostnames(summaryList []interface{}) []string {
+	var hostnames []string
+	for _, s := range summaryList {
+		hostnames = append(hostnames, s.(summary.HostSummary).GetHostname())
+	}
+	return hostnames
+}
+
+func (suite *WeightedRankerTestSuite) TestName() {
+	suite.EqualValues(suite.newRanker(nil).Name(), WeightedScore)
+}
+
+func (suite *WeightedRankerTestSuite) TestValidate() {
+	for _, mode := range []string{"", Pack, Spread} {
+		config := &WeightedRankerConfig{Mode: mode}
+		suite.NoError(config.Validate(), mode)
+	}
+	for _, mode := range []string{"pack", "SPRED", "DEFRAG"} {
+		config := &WeightedRankerConfig{Mode: mode}
+		suite.Error(config.Validate(), mode)
+	}
+}
+
+func (suite *WeightedRankerTestSuite) TestGetRankedHostListPack() {
+	ranker := suite.newRanker(&WeightedRankerConfig{Mode: Pack})
+	suite.Equal(
+		[]string{"hostA", "hostC", "hostB", "hostD"},
+		suite.hostnames(ranker.GetRankedHostList(suite.offerIndex)))
+}
+
+func (suite *WeightedRankerTestSuite) TestGetRankedHostListSpread() {
+	ranker := suite.newRanker(&WeightedRankerConfig{Mode: Spread})
+	suite.Equal(
+		[]string{"hostD", "hostB", "hostC", "hostA"},
+		suite.hostnames(ranker.GetRankedHostList(suite.offerIndex)))
+}
+
+func (suite *WeightedRankerTestSuite) TestGetRankedHostListPreserveGPUHosts() {
+	ranker := suite.newRanker(&WeightedRankerConfig{
+		Mode:             Pack,
+		PreserveGPUHosts: true,
+	})
+	suite.Equal(
+		[]string{"hostA", "hostB", "hostD", "hostC"},
+		suite.hostnames(ranker.GetRankedHostList(suite.offerIndex)))
+}
+
+func (suite *WeightedRankerTestSuite) TestGetRankedHostListDominantShare() {
+	// hostE has the cpu allocation of hostB,
+	// and the mem allocation of hostA
+	suite.addHost("hostE",
+		scalar.Resources{CPU: 3, Mem: 1, Disk: 3},
+		scalar.Resources{CPU: 4, Mem: 4, Disk: 4})
+	delete(suite.offerIndex, "hostC")
+	delete(suite.offerIndex, "hostD")
+
+	ranker := suite.newRanker(&WeightedRankerConfig{CPUWeight: 1})
+	suite.Equal(
+		[]string{"hostA", "hostB", "hostE"},
+		suite.hostnames(ranker.GetRankedHostList(suite.offerIndex)))
+
+	ranker = suite.newRanker(&WeightedRankerConfig{
+		CPUWeight:           1,
+		DominantShareWeight: 1,
+	})
+	suite.Equal(
+		[]string{"hostA", "hostE", "hostB"},
+		suite.hostnames(ranker.GetRankedHostList(suite.offerIndex)))
+}
+
+func (suite *WeightedRankerTestSuite) TestGetRankedHostListWithRefresh() {
+	ranker := suite.newRanker(nil)
+	suite.Len(ranker.GetRankedHostList(suite.offerIndex), 4)
+
+	// the new host is not ranked before the refresh
+	suite.addHost("hostE",
+		scalar.Resources{CPU: 1, Mem: 1, Disk: 1},
+		scalar.Resources{CPU: 8, Mem: 8, Disk: 8})
+	suite.Len(ranker.GetRankedHostList(suite.offerIndex), 4)
+
+	ranker.RefreshRanking(suite.offerIndex)
+	suite.Equal(
+		[]string{"hostE", "hostA", "hostC", "hostB", "hostD"},
+		suite.hostnames(ranker.GetRankedHostList(suite.offerIndex)))
+}
+
+func (suite *WeightedRankerTestSuite) TestScore() {
+	ranker := suite.newRanker(&WeightedRankerConfig{
+		CPUWeight: 1,
+		MemWeight: 3,
+	}).(*weightedRanker)
+	suite.InDelta(0.6875, ranker.score(
+		scalar.Resources{CPU: 2, Mem: 1},
+		scalar.Resources{CPU: 4, Mem: 4}), 0.0001)
+
+	// resources without total are not allocated
+	suite.InDelta(0.0, ranker.score(
+		scalar.Resources{CPU: 2, Mem: 1},
+		scalar.Resources{}), 0.0001)
+}
@@ -17,6 +17,8 @@ package config
 import (
 	"time"
 
+	"github.com/uber/peloton/pkg/common/wal"
+	"github.com/uber/peloton/pkg/hostmgr/binpacking"
 	"github.com/uber/peloton/pkg/hostmgr/reconcile"
 )
 
@@ -46,6 +48,11 @@ type Config struct {
 	// Size of the channel buffer of the status updates
 	TaskUpdateBufferSize int `yaml:"taskupdate_buffer_size"`
 
+	// Write-ahead log persisting the status updates, the status updates
+	// are acknowledged once persisted. The status updates are only held
+	// in memory if the log directory is not set.
+	TaskUpdateWAL wal.Config `yaml:"taskupdate_wal"`
+
 	TaskReconcilerConfig *reconcile.TaskReconcilerConfig `yaml:"task_reconciler"`
 
 	HostmapRefreshInterval time.Duration `yaml:"hostmap_refresh_interval"`
@@ -78,4 +85,6 @@ type Config struct {
 	BinPacking string `yaml:"bin_packing"`
 	// Bin Packing Refresh Interval
 	BinPackingRefreshIntervalSec time.Duration `yaml:"bin_packing_refresh_interval"`
+	// Config of the WEIGHTED_SCORE bin packing
+	WeightedRanker binpacking.WeightedRankerConfig `yaml:"weighted_ranker"`
 }
@@ -304,3 +304,88 @@ func GetOfferCreateOperation(operations []*mesos.Offer_Operation) *mesos.Offer_O
 	}
 	return nil
 }
+
+// NewResourceOperation returns a standalone Mesos offer operation of given
+// type on the given resources. The operation resources are expected to carry
+// their final reservation and disk information, and are verified against the
+// resources of the offers which will be accepted with the operation.
+func NewResourceOperation(
+	opType mesos.Offer_Operation_Type,
+	offerRes []*mesos.Resource,
+	opRes []*mesos.Resource) (*mesos.Offer_Operation, error) {
+
+	if len(opRes) == 0 {
+		return nil, errors.New("empty operation resources")
+	}
+
+	op := &mesos.Offer_Operation{Type: &opType}
+	switch opType {
+	case mesos.Offer_Operation_RESERVE:
+		for _, res := range opRes {
+			if !isReservedResource(res) {
+				return nil, errors.New("invalid reserve operation")
+			}
+		}
+		var unreservedRes []*mesos.Resource
+		for _, res := range offerRes {
+			if !isReservedResource(res) && res.GetRevocable() == nil {
+				unreservedRes = append(unreservedRes, res)
+			}
+		}
+		if err := checkOfferResources(unreservedRes, opRes); err != nil {
+			return nil, err
+		}
+		op.Reserve = &mesos.Offer_Operation_Reserve{Resources: opRes}
+
+	case mesos.Offer_Operation_UNRESERVE:
+		for _, res := range opRes {
+			if !isReservedResource(res) {
+				return nil, errors.New("invalid unreserve operation")
+			}
+		}
+		if err := checkOfferResources(offerRes, opRes); err != nil {
+			return nil, err
+		}
+		op.Unreserve = &mesos.Offer_Operation_Unreserve{Resources: opRes}
+
+	case mesos.Offer_Operation_CREATE:
+		for _, res := range opRes {
+			if !isReservedResource(res) ||
+				res.GetName() != "disk" ||
+				len(res.GetDisk().GetPersistence().GetId()) == 0 ||
+				len(res.GetDisk().GetVolume().GetContainerPath()) == 0 {
+				return nil, errors.New("invalid create operation")
+			}
+		}
+		if err := checkOfferResources(offerRes, opRes); err != nil {
+			return nil, err
+		}
+		op.Create = &mesos.Offer_Operation_Create{Volumes: opRes}
+
+	case mesos.Offer_Operation_DESTROY:
+		offeredVolumes := make(map[string]bool)
+		for _, res := range offerRes {
+			if id := res.GetDisk().GetPersistence().GetId(); len(id) != 0 {
+				offeredVolumes[id] = true
+			}
+		}
+		for _, res := range opRes {
+			if !offeredVolumes[res.GetDisk().GetPersistence().GetId()] {
+				return nil, errors.New("invalid destroy operation")
+			}
+		}
+		op.Destroy = &mesos.Offer_Operation_Destroy{Volumes: opRes}
+
+	default:
+		return nil, errors.New("offer operation type not supported")
+	}
+
+	return op, nil
+}
+
+// isReservedResource returns true if the resource is reserved to a role.
+func isReservedResource(res *mesos.Resource) bool {
+	return len(res.GetRole()) != 0 &&
+		res.GetRole() != unreservedRole &&
+		res.GetReservation() != nil
+}
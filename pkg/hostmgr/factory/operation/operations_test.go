@@ -376,6 +376,110 @@ func (suite *OperationTestSuite) TestGetOfferInvalidUnreserveOperation() {
 	suite.Error(err)
 }
 
+func (suite *OperationTestSuite) TestNewResourceOperationReserve() {
+	unreservedResources := suite.reserveOperation.GetReserve().GetResources()
+
+	op, err := NewResourceOperation(
+		mesos.Offer_Operation_RESERVE,
+		unreservedResources,
+		suite.reservedResources[:1])
+	suite.NoError(err)
+	suite.Equal(mesos.Offer_Operation_RESERVE, op.GetType())
+	suite.Equal(suite.reservedResources[:1], op.GetReserve().GetResources())
+
+	// reserve more than offered
+	_, err = NewResourceOperation(
+		mesos.Offer_Operation_RESERVE,
+		unreservedResources[1:],
+		suite.reservedResources[:1])
+	suite.Error(err)
+
+	// reserve resources without reservation info
+	_, err = NewResourceOperation(
+		mesos.Offer_Operation_RESERVE,
+		unreservedResources,
+		unreservedResources)
+	suite.Error(err)
+}
+
+func (suite *OperationTestSuite) TestNewResourceOperationUnreserve() {
+	op, err := NewResourceOperation(
+		mesos.Offer_Operation_UNRESERVE,
+		suite.reservedResources,
+		suite.reservedResources[:2])
+	suite.NoError(err)
+	suite.Equal(mesos.Offer_Operation_UNRESERVE, op.GetType())
+	suite.Equal(suite.reservedResources[:2], op.GetUnreserve().GetResources())
+
+	_, err = NewResourceOperation(
+		mesos.Offer_Operation_UNRESERVE,
+		suite.reservedResources[1:],
+		suite.reservedResources[:2])
+	suite.Error(err)
+}
+
+func (suite *OperationTestSuite) TestNewResourceOperationCreateAndDestroy() {
+	containerPath := "test"
+	volume := util.NewMesosResourceBuilder().
+		WithName("disk").
+		WithValue(1.0).
+		WithRole(pelotonRole).
+		WithReservation(suite.reservedResources[2].GetReservation()).
+		WithDisk(&mesos.Resource_DiskInfo{
+			Persistence: &mesos.Resource_DiskInfo_Persistence{
+				Id: &_testVolumeID,
+			},
+			Volume: &mesos.Volume{
+				ContainerPath: &containerPath,
+			},
+		}).
+		Build()
+
+	op, err := NewResourceOperation(
+		mesos.Offer_Operation_CREATE,
+		suite.reservedResources,
+		[]*mesos.Resource{volume})
+	suite.NoError(err)
+	suite.Equal(mesos.Offer_Operation_CREATE, op.GetType())
+	suite.Equal(volume, op.GetCreate().GetVolumes()[0])
+
+	// volume without container path
+	_, err = NewResourceOperation(
+		mesos.Offer_Operation_CREATE,
+		suite.reservedResources,
+		suite.reservedResources[2:])
+	suite.Error(err)
+
+	op, err = NewResourceOperation(
+		mesos.Offer_Operation_DESTROY,
+		suite.reservedResources,
+		suite.reservedResources[2:])
+	suite.NoError(err)
+	suite.Equal(mesos.Offer_Operation_DESTROY, op.GetType())
+	suite.Equal(suite.reservedResources[2:], op.GetDestroy().GetVolumes())
+
+	// volume not present in offers
+	_, err = NewResourceOperation(
+		mesos.Offer_Operation_DESTROY,
+		suite.reservedResources[:2],
+		suite.reservedResources[2:])
+	suite.Error(err)
+}
+
+func (suite *OperationTestSuite) TestNewResourceOperationInvalid() {
+	_, err := NewResourceOperation(
+		mesos.Offer_Operation_LAUNCH,
+		suite.reservedResources,
+		suite.reservedResources)
+	suite.Error(err)
+
+	_, err = NewResourceOperation(
+		mesos.Offer_Operation_RESERVE,
+		suite.reservedResources,
+		nil)
+	suite.Error(err)
+}
+
 func (suite *OperationTestSuite) createReservedMesosOffer(res []*mesos.Resource) *mesos.Offer {
 	return &mesos.Offer{
 		Id: &mesos.OfferID{
@@ -15,8 +15,10 @@
 package task
 
 import (
+	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
@@ -24,6 +26,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/taskconfig"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
 	hostmgrutil "github.com/uber/peloton/pkg/hostmgr/util"
@@ -50,12 +53,27 @@ const (
 
 	// Default custom executor name
 	_defaultCustomExecutorName = "AuroraExecutor"
+
+	// Executor id prefix of the Mesos default executor launching
+	// task groups
+	_defaultExecutorPrefix = "peloton-"
+
+	// Directory in the sandbox of the default executor, shared by all the
+	// containers of a task group, in which the init containers create a
+	// marker file once they have succeeded
+	_initContainerMarkerDir = ".peloton-init"
 )
 
 var (
 	_pelotonRole      = "peloton"
 	_pelotonPrinciple = "peloton"
 
+	// Resources of the Mesos default executor launching task groups
+	_defaultExecutorResource = &task.ResourceConfig{
+		CpuLimit:   taskconfig.DefaultExecutorCPULimit,
+		MemLimitMb: taskconfig.DefaultExecutorMemLimitMb,
+	}
+
 	// ErrPortMismatch represents port not matching.
 	ErrPortMismatch = errors.New("port in launch not in offer")
 	// ErrNotEnoughResource means resource is not enough to match given task.
@@ -150,7 +168,8 @@ func (tb *Builder) pickPorts(
 		portResources: []*mesos.Resource{},
 	}
 
-	if len(taskConfig.Ports) == 0 {
+	ports := taskconfig.GetAllPorts(taskConfig)
+	if len(ports) == 0 {
 		return result, nil
 	}
 
@@ -173,7 +192,7 @@ func (tb *Builder) pickPorts(
 
 	// Populate static ports and extra environment variables, which will be
 	// added to `CommandInfo` to launch the task.
-	for _, portConfig := range taskConfig.GetPorts() {
+	for _, portConfig := range ports {
 		name := portConfig.GetName()
 		if len(name) == 0 {
 			return nil, errors.New("Empty port name in task")
@@ -278,10 +297,270 @@ func (tb *Builder) Build(
 	tb.populateLabels(mesosTask, taskConfig.GetLabels(), jobID, instanceID)
 
 	tb.populateHealthCheck(mesosTask, taskConfig.GetHealthCheck())
+	tb.populateReadinessCheck(mesosTask, taskConfig.GetReadinessCheck())
 
 	return mesosTask, nil
 }
 
+// groupContainer is a container of a task launched as a task group.
+type groupContainer struct {
+	name           string
+	resource       *task.ResourceConfig
+	container      *mesos.ContainerInfo
+	command        *mesos.CommandInfo
+	healthCheck    *task.HealthCheckConfig
+	readinessCheck *task.HealthCheckConfig
+}
+
+// BuildTaskGroup is used to build the `mesos.ExecutorInfo` and the
+// `mesos.TaskGroupInfo` of a task with sidecar or init containers from
+// cached resources. Each container is launched as a Mesos task of the
+// task group by the Mesos default executor, so the containers share the
+// network namespace and the sandbox of the executor. The primary container
+// keeps the mesos task id of the task, while the mesos task ids of the
+// other containers are derived from it.
+// All the containers are started together, so the commands of the init
+// containers are wrapped to run one after the other, and the commands of
+// the other containers to wait for the last init container to succeed.
+func (tb *Builder) BuildTaskGroup(
+	task *hostsvc.LaunchableTask) (*mesos.ExecutorInfo, *mesos.TaskGroupInfo, error) {
+
+	// Validation of input.
+	taskConfig := task.GetConfig()
+	if taskConfig == nil {
+		return nil, nil, errors.New("TaskConfig cannot be nil")
+	}
+
+	taskID := task.GetTaskId()
+	if taskID == nil {
+		return nil, nil, errors.New("taskID cannot be nil")
+	}
+
+	jobID, instanceID, err := util.ParseJobAndInstanceID(taskID.GetValue())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	containers := []*groupContainer{{
+		name:           taskConfig.GetName(),
+		resource:       taskConfig.GetResource(),
+		container:      taskConfig.GetContainer(),
+		command:        taskConfig.GetCommand(),
+		healthCheck:    taskConfig.GetHealthCheck(),
+		readinessCheck: taskConfig.GetReadinessCheck(),
+	}}
+	for _, c := range taskConfig.GetSidecars() {
+		containers = append(containers, newGroupContainer(c))
+	}
+	for _, c := range taskConfig.GetInitContainers() {
+		containers = append(containers, newGroupContainer(c))
+	}
+
+	for _, c := range containers {
+		if c.resource == nil {
+			return nil, nil, errors.Errorf(
+				"Resource of container %s cannot be nil", c.name)
+		}
+		if c.command == nil {
+			return nil, nil, errors.Errorf(
+				"Command of container %s cannot be nil", c.name)
+		}
+		// The commands are wrapped in shell scripts to
+		// order the containers after the init containers.
+		if !c.command.GetShell() ||
+			len(strings.TrimSpace(c.command.GetValue())) == 0 {
+			return nil, nil, errors.Errorf(
+				"Command of container %s must be a non-empty shell command",
+				c.name)
+		}
+	}
+
+	executorResources, err := tb.extractScalarResources(
+		_defaultExecutorResource,
+		taskConfig.GetRevocable())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pick, err := tb.pickPorts(taskConfig, task.GetPorts())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The containers of a task group share the network of the executor.
+	var networkInfos []*mesos.NetworkInfo
+	for _, networkInfo := range taskConfig.GetContainer().GetNetworkInfos() {
+		networkInfos = append(
+			networkInfos,
+			proto.Clone(networkInfo).(*mesos.NetworkInfo))
+	}
+
+	executorType := mesos.ExecutorInfo_DEFAULT
+	executorIDValue := _defaultExecutorPrefix + taskID.GetValue()
+	containerType := mesos.ContainerInfo_MESOS
+	executor := &mesos.ExecutorInfo{
+		Type: &executorType,
+		ExecutorId: &mesos.ExecutorID{
+			Value: &executorIDValue,
+		},
+		Resources: executorResources,
+		Container: &mesos.ContainerInfo{
+			Type:         &containerType,
+			NetworkInfos: networkInfos,
+		},
+	}
+
+	numInitContainers := len(taskConfig.GetInitContainers())
+	numMainContainers := len(containers) - numInitContainers
+	taskGroup := &mesos.TaskGroupInfo{}
+	for i, c := range containers {
+		lres, err := tb.extractScalarResources(
+			c.resource,
+			taskConfig.GetRevocable())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mesosTaskID := taskID
+		if i != 0 {
+			mesosTaskID = util.CreateContainerMesosTaskID(taskID, c.name)
+		} else if len(pick.portResources) > 0 {
+			// The port resources are allocated to the primary container.
+			lres = append(lres, pick.portResources...)
+		}
+
+		mesosTask := &mesos.TaskInfo{
+			Name:      &jobID,
+			TaskId:    mesosTaskID,
+			Resources: lres,
+		}
+
+		// Init container i waits for init container i-1 to succeed, and
+		// the other containers wait for the last init container.
+		var waitFor, done string
+		if i >= numMainContainers {
+			done = strconv.Itoa(i - numMainContainers)
+			if i > numMainContainers {
+				waitFor = strconv.Itoa(i - numMainContainers - 1)
+			}
+		} else if numInitContainers > 0 {
+			waitFor = strconv.Itoa(numInitContainers - 1)
+		}
+
+		tb.populateKillPolicy(mesosTask, taskConfig.GetKillGracePeriodSeconds())
+		if i == 0 {
+			tb.populateDiscoveryInfo(mesosTask, pick.selectedPorts, jobID)
+		}
+		tb.populateCommandInfo(
+			mesosTask,
+			wrapGroupCommand(c.command, waitFor, done),
+			pick.portEnvs,
+			jobID,
+			instanceID,
+		)
+		tb.populateContainerInfo(
+			mesosTask,
+			getGroupContainerInfo(c.container, numInitContainers > 0),
+		)
+		tb.populateLabels(mesosTask, taskConfig.GetLabels(), jobID, instanceID)
+		tb.populateHealthCheck(mesosTask, c.healthCheck)
+		tb.populateReadinessCheck(mesosTask, c.readinessCheck)
+
+		taskGroup.Tasks = append(taskGroup.Tasks, mesosTask)
+	}
+
+	return executor, taskGroup, nil
+}
+
+// newGroupContainer returns the group container of a sidecar
+// or init container.
+func newGroupContainer(c *task.ContainerConfig) *groupContainer {
+	return &groupContainer{
+		name:           c.GetName(),
+		resource:       c.GetResource(),
+		container:      c.GetContainer(),
+		command:        c.GetCommand(),
+		healthCheck:    c.GetHealthCheck(),
+		readinessCheck: c.GetReadinessCheck(),
+	}
+}
+
+// getGroupContainerInfo returns the `ContainerInfo` of a task of a task
+// group, which must be a Mesos container without its own network. It mounts
+// the init container marker directory if the task has init containers.
+func getGroupContainerInfo(
+	container *mesos.ContainerInfo,
+	hasInitContainers bool,
+) *mesos.ContainerInfo {
+	var containerInfo *mesos.ContainerInfo
+	if container == nil {
+		containerInfo = &mesos.ContainerInfo{}
+	} else {
+		containerInfo = proto.Clone(container).(*mesos.ContainerInfo)
+	}
+
+	containerType := mesos.ContainerInfo_MESOS
+	containerInfo.Type = &containerType
+	containerInfo.NetworkInfos = nil
+
+	if hasInitContainers {
+		volumeMode := mesos.Volume_RW
+		containerPath := _initContainerMarkerDir
+		sourceType := mesos.Volume_Source_SANDBOX_PATH
+		sandboxType := mesos.Volume_Source_SandboxPath_PARENT
+		sandboxPath := _initContainerMarkerDir
+		containerInfo.Volumes = append(containerInfo.Volumes, &mesos.Volume{
+			Mode:          &volumeMode,
+			ContainerPath: &containerPath,
+			Source: &mesos.Volume_Source{
+				Type: &sourceType,
+				SandboxPath: &mesos.Volume_Source_SandboxPath{
+					Type: &sandboxType,
+					Path: &sandboxPath,
+				},
+			},
+		})
+	}
+	return containerInfo
+}
+
+// wrapGroupCommand returns a shell command which waits for the marker file
+// waitFor of an init container, if any, before running the given shell
+// command, and creates the marker file done, if any, once the command
+// succeeds. The script requires /bin/sh, sleep and touch in the container.
+func wrapGroupCommand(
+	command *mesos.CommandInfo,
+	waitFor string,
+	done string,
+) *mesos.CommandInfo {
+	if len(waitFor) == 0 && len(done) == 0 {
+		return command
+	}
+
+	// Make a deep copy of pass through fields to avoid changing input.
+	commandInfo := proto.Clone(command).(*mesos.CommandInfo)
+
+	var script []string
+	if len(waitFor) != 0 {
+		script = append(script, fmt.Sprintf(
+			"until [ -f %s/%s ]; do sleep 1; done", _initContainerMarkerDir, waitFor))
+	}
+	script = append(script, fmt.Sprintf("( %s\n)", commandInfo.GetValue()))
+	if len(done) != 0 {
+		script = append(script, fmt.Sprintf(
+			"touch %s/%s", _initContainerMarkerDir, done))
+	}
+
+	value := strings.Join(script, " && ")
+	commandInfo.Value = &value
+	return commandInfo
+}
+
+// shellQuote quotes a string to be used as a single shell word.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
 // populateReservationVolumeInfo sets up the reservation and volume fields on
 // mesos resources.
 func populateReservationVolumeInfo(
@@ -606,6 +885,29 @@ func (tb *Builder) populateHealthCheck(
 			Path:   &path,
 		}
 		mh.Http = h
+	case task.HealthCheckConfig_GRPC:
+		// Mesos does not support GRPC health checks, so run them as
+		// command health checks calling the GRPC health service with
+		// the probe of the container.
+		grpcCheck := health.GetGrpcCheck()
+		t := mesos.HealthCheck_COMMAND
+		mh.Type = &t
+		shell := true
+		value := getGRPCHealthCheckCommand(grpcCheck)
+		mh.Command = &mesos.CommandInfo{
+			Shell: &shell,
+			Value: &value,
+			Environment: proto.Clone(
+				mesosTask.GetCommand().GetEnvironment(),
+			).(*mesos.Environment),
+		}
+	case task.HealthCheckConfig_TCP:
+		t := mesos.HealthCheck_TCP
+		mh.Type = &t
+		port := health.GetTcpCheck().GetPort()
+		mh.Tcp = &mesos.HealthCheck_TCPCheckInfo{
+			Port: &port,
+		}
 	default:
 		log.WithField("type", health.GetType()).
 			Warn("Unknown health check type")
@@ -619,6 +921,107 @@ func (tb *Builder) populateHealthCheck(
 	mesosTask.HealthCheck = mh
 }
 
+// getGRPCHealthCheckCommand returns the shell command checking the health
+// of a GRPC server listening on localhost with the probe of the container.
+func getGRPCHealthCheckCommand(grpcCheck *task.HealthCheckConfig_GRPCCheck) string {
+	command := fmt.Sprintf(
+		"%s -addr=127.0.0.1:%d",
+		shellQuote(grpcCheck.GetProbePath()),
+		grpcCheck.GetPort())
+	if service := grpcCheck.GetService(); len(service) > 0 {
+		command += " -service=" + shellQuote(service)
+	}
+	return command
+}
+
+// populateReadinessCheck sets up the readiness check of a Mesos task as a
+// Mesos check. Unlike a health check, the results of a check are only
+// reported back to the framework and never kill the task.
+func (tb *Builder) populateReadinessCheck(
+	mesosTask *mesos.TaskInfo, readiness *task.HealthCheckConfig) {
+	if readiness == nil || !readiness.GetEnabled() {
+		return
+	}
+
+	mc := &mesos.CheckInfo{}
+
+	if t := readiness.GetInitialIntervalSecs(); t > 0 {
+		tmp := float64(t)
+		mc.DelaySeconds = &tmp
+	}
+
+	if t := readiness.GetIntervalSecs(); t > 0 {
+		tmp := float64(t)
+		mc.IntervalSeconds = &tmp
+	}
+
+	if t := readiness.GetTimeoutSecs(); t > 0 {
+		tmp := float64(t)
+		mc.TimeoutSeconds = &tmp
+	}
+
+	switch readiness.GetType() {
+	case task.HealthCheckConfig_COMMAND:
+		cc := readiness.GetCommandCheck()
+		t := mesos.CheckInfo_COMMAND
+		mc.Type = &t
+		shell := true
+		value := cc.GetCommand()
+		cmd := &mesos.CommandInfo{
+			Shell: &shell,
+			Value: &value,
+		}
+		if !cc.GetUnshareEnvironments() {
+			cmd.Environment = proto.Clone(
+				mesosTask.GetCommand().GetEnvironment(),
+			).(*mesos.Environment)
+		}
+		mc.Command = &mesos.CheckInfo_Command{Command: cmd}
+	case task.HealthCheckConfig_HTTP:
+		cc := readiness.GetHttpCheck()
+		t := mesos.CheckInfo_HTTP
+		mc.Type = &t
+		port := cc.GetPort()
+		path := cc.GetPath()
+		mc.Http = &mesos.CheckInfo_Http{
+			Port: &port,
+			Path: &path,
+		}
+	case task.HealthCheckConfig_GRPC:
+		grpcCheck := readiness.GetGrpcCheck()
+		t := mesos.CheckInfo_COMMAND
+		mc.Type = &t
+		shell := true
+		value := getGRPCHealthCheckCommand(grpcCheck)
+		mc.Command = &mesos.CheckInfo_Command{
+			Command: &mesos.CommandInfo{
+				Shell: &shell,
+				Value: &value,
+				Environment: proto.Clone(
+					mesosTask.GetCommand().GetEnvironment(),
+				).(*mesos.Environment),
+			},
+		}
+	case task.HealthCheckConfig_TCP:
+		t := mesos.CheckInfo_TCP
+		mc.Type = &t
+		port := readiness.GetTcpCheck().GetPort()
+		mc.Tcp = &mesos.CheckInfo_Tcp{
+			Port: &port,
+		}
+	default:
+		log.WithField("type", readiness.GetType()).
+			Warn("Unknown readiness check type")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"readiness": mc,
+		"task":      mesosTask.GetTaskId(),
+	}).Debug("Populated readiness check for mesos task")
+	mesosTask.Check = mc
+}
+
 // extractScalarResources takes necessary scalar resources from cached resources
 // of this instance to construct a task, and returns error if not enough
 // resources are left.
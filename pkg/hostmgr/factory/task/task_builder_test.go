@@ -529,10 +529,111 @@ func (suite *BuilderTestSuite) TestRevocableTask() {
 	suite.Error(err)
 }
 
+// This tests task with command readiness check can be created.
+func (suite *BuilderTestSuite) TestCommandReadinessCheck() {
+	numTasks := 1
+	resources := suite.getResources(numTasks)
+	builder := NewBuilder(resources)
+	tid := suite.createTestTaskIDs(numTasks)[0]
+	c := createTestTaskConfigs(numTasks)[0]
+
+	rcCmd := "ready"
+	c.ReadinessCheck = &task.HealthCheckConfig{
+		Enabled:             true,
+		Type:                task.HealthCheckConfig_COMMAND,
+		InitialIntervalSecs: 5,
+		IntervalSecs:        10,
+		CommandCheck: &task.HealthCheckConfig_CommandCheck{
+			Command: rcCmd,
+		},
+	}
+	task := &hostsvc.LaunchableTask{
+		TaskId: tid,
+		Config: c,
+	}
+	info, err := builder.Build(task, nil, nil)
+	suite.NoError(err)
+	suite.Nil(info.GetHealthCheck())
+	suite.Equal(mesos.CheckInfo_COMMAND, info.GetCheck().GetType())
+	suite.Equal(float64(5), info.GetCheck().GetDelaySeconds())
+	suite.Equal(float64(10), info.GetCheck().GetIntervalSeconds())
+	cmd := info.GetCheck().GetCommand().GetCommand()
+	suite.Equal(rcCmd, cmd.GetValue())
+	suite.True(cmd.GetShell())
+	suite.Len(cmd.GetEnvironment().GetVariables(), 3)
+}
+
+// This tests task with http readiness check can be created, and that a
+// disabled readiness check is not set.
+func (suite *BuilderTestSuite) TestHTTPReadinessCheck() {
+	numTasks := 1
+	resources := suite.getResources(numTasks)
+	builder := NewBuilder(resources)
+	tid := suite.createTestTaskIDs(numTasks)[0]
+	c := createTestTaskConfigs(numTasks)[0]
+
+	c.ReadinessCheck = &task.HealthCheckConfig{
+		Enabled: true,
+		Type:    task.HealthCheckConfig_HTTP,
+		HttpCheck: &task.HealthCheckConfig_HTTPCheck{
+			Port: 8080,
+			Path: "/ready",
+		},
+	}
+	task := &hostsvc.LaunchableTask{
+		TaskId: tid,
+		Config: c,
+	}
+	info, err := builder.Build(task, nil, nil)
+	suite.NoError(err)
+	suite.Equal(mesos.CheckInfo_HTTP, info.GetCheck().GetType())
+	suite.Equal(uint32(8080), info.GetCheck().GetHttp().GetPort())
+	suite.Equal("/ready", info.GetCheck().GetHttp().GetPath())
+
+	c.ReadinessCheck.Enabled = false
+	builder = NewBuilder(suite.getResources(numTasks))
+	info, err = builder.Build(task, nil, nil)
+	suite.NoError(err)
+	suite.Nil(info.GetCheck())
+}
+
+// This tests populating GRPC and TCP readiness checks.
+func (suite *BuilderTestSuite) TestPopulateReadinessCheckGRPCAndTCP() {
+	builder := NewBuilder(nil)
+
+	mesosTask := &mesos.TaskInfo{}
+	builder.populateReadinessCheck(mesosTask, &task.HealthCheckConfig{
+		Enabled: true,
+		Type:    task.HealthCheckConfig_GRPC,
+		GrpcCheck: &task.HealthCheckConfig_GRPCCheck{
+			Port:      8080,
+			ProbePath: "/bin/grpc_health_probe",
+		},
+	})
+	suite.Equal(mesos.CheckInfo_COMMAND, mesosTask.GetCheck().GetType())
+	suite.Equal(
+		"'/bin/grpc_health_probe' -addr=127.0.0.1:8080",
+		mesosTask.GetCheck().GetCommand().GetCommand().GetValue())
+
+	mesosTask = &mesos.TaskInfo{}
+	builder.populateReadinessCheck(mesosTask, &task.HealthCheckConfig{
+		Enabled: true,
+		Type:    task.HealthCheckConfig_TCP,
+		TcpCheck: &task.HealthCheckConfig_TCPCheck{
+			Port: 8080,
+		},
+	})
+	suite.Equal(mesos.CheckInfo_TCP, mesosTask.GetCheck().GetType())
+	suite.Equal(uint32(8080), mesosTask.GetCheck().GetTcp().GetPort())
+}
+
 // This tests various combination of populating health check.
 func (suite *BuilderTestSuite) TestPopulateHealthCheck() {
 	cmdType := mesos.HealthCheck_COMMAND
+	tcpType := mesos.HealthCheck_TCP
 	command := "hello world"
+	grpcCommand := "'/bin/grpc_health_probe' -addr=127.0.0.1:8080 -service='test.Service'"
+	port := uint32(8080)
 	tmpTrue := true
 
 	delaySeconds := float64(1)
@@ -626,6 +727,45 @@ func (suite *BuilderTestSuite) TestPopulateHealthCheck() {
 				},
 			},
 		},
+		// grpc health check run as a command health check
+		{
+			input: &task.HealthCheckConfig{
+				Type: task.HealthCheckConfig_GRPC,
+				GrpcCheck: &task.HealthCheckConfig_GRPCCheck{
+					Port:      port,
+					Service:   "test.Service",
+					ProbePath: "/bin/grpc_health_probe",
+				},
+			},
+			output: &mesos.HealthCheck{
+				Type: &cmdType,
+				Command: &mesos.CommandInfo{
+					Shell:       &tmpTrue,
+					Value:       &grpcCommand,
+					Environment: environment,
+				},
+			},
+			taskInfo: &mesos.TaskInfo{
+				Command: &mesos.CommandInfo{
+					Environment: environment,
+				},
+			},
+		},
+		// tcp health check
+		{
+			input: &task.HealthCheckConfig{
+				Type: task.HealthCheckConfig_TCP,
+				TcpCheck: &task.HealthCheckConfig_TCPCheck{
+					Port: port,
+				},
+			},
+			output: &mesos.HealthCheck{
+				Type: &tcpType,
+				Tcp: &mesos.HealthCheck_TCPCheckInfo{
+					Port: &port,
+				},
+			},
+		},
 	}
 
 	for _, tt := range testCases {
@@ -943,6 +1083,188 @@ func (suite *BuilderTestSuite) TestExtractScalarResourcesOverflow() {
 	suite.Error(err)
 }
 
+// createTestTaskGroupConfig creates a task config with
+// a sidecar container and an init container
+func createTestTaskGroupConfig() *task.TaskConfig {
+	shell := true
+	sidecarCmd := "envoy -c config.yaml"
+	initCmd := "echo init"
+	taskConfig := createTestTaskConfigs(1)[0]
+	taskConfig.Sidecars = []*task.ContainerConfig{
+		{
+			Name: "proxy",
+			Resource: &task.ResourceConfig{
+				CpuLimit:   1,
+				MemLimitMb: 2,
+			},
+			Command: &mesos.CommandInfo{
+				Value: &sidecarCmd,
+			},
+			Ports: []*task.PortConfig{
+				{
+					Name:    "proxy",
+					EnvName: "PROXY_PORT",
+				},
+			},
+		},
+	}
+	taskConfig.InitContainers = []*task.ContainerConfig{
+		{
+			Name: "init",
+			Resource: &task.ResourceConfig{
+				CpuLimit:   1,
+				MemLimitMb: 2,
+			},
+			Command: &mesos.CommandInfo{
+				Shell: &shell,
+				Value: &initCmd,
+			},
+		},
+	}
+	return taskConfig
+}
+
+// TestBuildTaskGroup tests building the task group of
+// a task with sidecar and init containers.
+func (suite *BuilderTestSuite) TestBuildTaskGroup() {
+	resources := suite.getResources(4)
+	resources = append(resources,
+		util.CreatePortResources(map[uint32]string{1000: "*"})...)
+	builder := NewBuilder(resources)
+
+	tid := suite.createTestTaskIDs(1)[0]
+	executor, taskGroup, err := builder.BuildTaskGroup(
+		&hostsvc.LaunchableTask{
+			TaskId: tid,
+			Config: createTestTaskGroupConfig(),
+			Ports:  map[string]uint32{"proxy": 1000},
+		})
+	suite.NoError(err)
+
+	suite.Equal(mesos.ExecutorInfo_DEFAULT, executor.GetType())
+	suite.Equal("peloton-"+tid.GetValue(), executor.GetExecutorId().GetValue())
+	suite.Equal(mesos.ContainerInfo_MESOS, executor.GetContainer().GetType())
+	suite.Equal(
+		scalar.Resources{CPU: 0.1, Mem: 32},
+		scalar.FromMesosResources(executor.GetResources()))
+
+	tasks := taskGroup.GetTasks()
+	suite.Len(tasks, 3)
+	suite.Equal(tid.GetValue(), tasks[0].GetTaskId().GetValue())
+	suite.Equal(tid.GetValue()+".proxy", tasks[1].GetTaskId().GetValue())
+	suite.Equal(tid.GetValue()+".init", tasks[2].GetTaskId().GetValue())
+
+	// The port resources and discovery info belong to the primary container.
+	suite.Len(tasks[0].GetResources(), 4)
+	suite.Len(tasks[0].GetDiscovery().GetPorts().GetPorts(), 1)
+	suite.Len(tasks[1].GetResources(), 2)
+	suite.Nil(tasks[1].GetDiscovery())
+
+	for _, mesosTask := range tasks {
+		suite.True(mesosTask.GetCommand().GetShell())
+		suite.Equal(mesos.ContainerInfo_MESOS, mesosTask.GetContainer().GetType())
+		volumes := mesosTask.GetContainer().GetVolumes()
+		suite.Len(volumes, 1)
+		suite.Equal(
+			mesos.Volume_Source_SandboxPath_PARENT,
+			volumes[0].GetSource().GetSandboxPath().GetType())
+
+		var portEnv string
+		for _, env := range mesosTask.GetCommand().GetEnvironment().GetVariables() {
+			if env.GetName() == "PROXY_PORT" {
+				portEnv = env.GetValue()
+			}
+		}
+		suite.Equal("1000", portEnv)
+	}
+
+	suite.Equal(
+		"until [ -f .peloton-init/0 ]; do sleep 1; done && ( /bin/sh\n)",
+		tasks[0].GetCommand().GetValue())
+	suite.Equal(
+		"until [ -f .peloton-init/0 ]; do sleep 1; done && "+
+			"( envoy -c config.yaml\n)",
+		tasks[1].GetCommand().GetValue())
+	suite.Equal(
+		"( echo init\n) && touch .peloton-init/0",
+		tasks[2].GetCommand().GetValue())
+}
+
+// TestBuildTaskGroupSidecarsOnly tests that the commands of a task
+// group without init containers are not modified.
+func (suite *BuilderTestSuite) TestBuildTaskGroupSidecarsOnly() {
+	resources := suite.getResources(4)
+	resources = append(resources,
+		util.CreatePortResources(map[uint32]string{1000: "*"})...)
+	builder := NewBuilder(resources)
+
+	taskConfig := createTestTaskGroupConfig()
+	taskConfig.InitContainers = nil
+	tid := suite.createTestTaskIDs(1)[0]
+	_, taskGroup, err := builder.BuildTaskGroup(
+		&hostsvc.LaunchableTask{
+			TaskId: tid,
+			Config: taskConfig,
+			Ports:  map[string]uint32{"proxy": 1000},
+		})
+	suite.NoError(err)
+
+	tasks := taskGroup.GetTasks()
+	suite.Len(tasks, 2)
+	suite.Equal(defaultCmd, tasks[0].GetCommand().GetValue())
+	suite.Equal("envoy -c config.yaml", tasks[1].GetCommand().GetValue())
+	suite.Empty(tasks[1].GetContainer().GetVolumes())
+}
+
+// TestBuildTaskGroupErrors tests the failures to build a task group.
+func (suite *BuilderTestSuite) TestBuildTaskGroupErrors() {
+	tid := suite.createTestTaskIDs(1)[0]
+
+	// not enough resources for the executor and all the containers
+	builder := NewBuilder(suite.getResources(1))
+	_, _, err := builder.BuildTaskGroup(&hostsvc.LaunchableTask{
+		TaskId: tid,
+		Config: createTestTaskGroupConfig(),
+	})
+	suite.Equal(ErrNotEnoughResource, err)
+
+	// missing command of a container
+	taskConfig := createTestTaskGroupConfig()
+	taskConfig.Sidecars[0].Command = nil
+	builder = NewBuilder(suite.getResources(4))
+	_, _, err = builder.BuildTaskGroup(&hostsvc.LaunchableTask{
+		TaskId: tid,
+		Config: taskConfig,
+	})
+	suite.Error(err)
+
+	// command of a container which is not a shell command
+	shell := false
+	taskConfig = createTestTaskGroupConfig()
+	taskConfig.Sidecars[0].Command.Shell = &shell
+	_, _, err = builder.BuildTaskGroup(&hostsvc.LaunchableTask{
+		TaskId: tid,
+		Config: taskConfig,
+	})
+	suite.Error(err)
+
+	// empty command of a container
+	emptyCmd := ""
+	taskConfig = createTestTaskGroupConfig()
+	taskConfig.InitContainers[0].Command.Value = &emptyCmd
+	_, _, err = builder.BuildTaskGroup(&hostsvc.LaunchableTask{
+		TaskId: tid,
+		Config: taskConfig,
+	})
+	suite.Error(err)
+
+	// missing task config
+	_, _, err = builder.BuildTaskGroup(&hostsvc.LaunchableTask{
+		TaskId: tid,
+	})
+	suite.Error(err)
+}
+
 func TestBuilderTestSuite(t *testing.T) {
 	suite.Run(t, new(BuilderTestSuite))
 }
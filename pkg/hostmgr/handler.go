@@ -35,6 +35,7 @@ import (
 	"github.com/uber/peloton/pkg/common/queue"
 	"github.com/uber/peloton/pkg/common/reservation"
 	"github.com/uber/peloton/pkg/common/stringset"
+	"github.com/uber/peloton/pkg/common/taskconfig"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/hostmgr/config"
 	"github.com/uber/peloton/pkg/hostmgr/factory/operation"
@@ -80,6 +81,7 @@ var (
 	errOfferOperationNotSupported        = errors.New("offer operation not supported")
 	errInvalidOfferOperation             = errors.New("invalid offer operation")
 	errReservationNotFound               = errors.New("reservation could not be made")
+	errEmptyResources                    = errors.New("empty resources")
 )
 
 // ServiceHandler implements peloton.private.hostmgr.InternalHostService.
@@ -226,10 +228,16 @@ func (h *ServiceHandler) GetHostsByQuery(
 			continue
 		}
 
+		var inverseOffers []*mesos.InverseOffer
+		for _, inverseOffer := range hostSummary.GetInverseOffers() {
+			inverseOffers = append(inverseOffers, inverseOffer)
+		}
+
 		hosts = append(hosts, &hostsvc.GetHostsByQueryResponse_Host{
-			Hostname:  hostname,
-			Resources: nonRevocable,
-			Status:    toHostStatus(hostSummary.GetHostStatus()),
+			Hostname:      hostname,
+			Resources:     nonRevocable,
+			Status:        toHostStatus(hostSummary.GetHostStatus()),
+			InverseOffers: inverseOffers,
 		})
 	}
 
@@ -606,7 +614,18 @@ func (h *ServiceHandler) persistVolumeInfo(
 		return nil
 	}
 
-	volumeRes := createOperation.GetCreate().GetVolumes()[0]
+	return h.persistVolume(
+		ctx,
+		createOperation.GetCreate().GetVolumes()[0],
+		hostname)
+}
+
+// persistVolume writes information of given volume resource into db,
+// if it does not exist yet.
+func (h *ServiceHandler) persistVolume(
+	ctx context.Context,
+	volumeRes *mesos.Resource,
+	hostname string) error {
 	volumeID := &peloton.VolumeID{
 		Value: volumeRes.GetDisk().GetPersistence().GetId(),
 	}
@@ -624,9 +643,8 @@ func (h *ServiceHandler) persistVolumeInfo(
 		switch pv.GetState() {
 		case volume.VolumeState_CREATED, volume.VolumeState_DELETED:
 			log.WithFields(log.Fields{
-				"volume":           pv,
-				"offer_operations": offerOperations,
-				"hostname":         hostname,
+				"volume":   pv,
+				"hostname": hostname,
 			}).Error("try create to create volume that already exists")
 		}
 		// TODO(mu): Volume info already exist in db and check if we need to update hostname
@@ -743,10 +761,19 @@ func (h *ServiceHandler) LaunchTasks(
 
 	var mesosTasks []*mesos.TaskInfo
 	var mesosTaskIds []string
+	var launchGroups []*mesos.Offer_Operation_LaunchGroup
 
 	builder := task.NewBuilder(mesosResources)
 	for _, t := range req.GetTasks() {
-		mesosTask, err := builder.Build(t, nil, nil)
+		var mesosTask *mesos.TaskInfo
+		var executor *mesos.ExecutorInfo
+		var taskGroup *mesos.TaskGroupInfo
+		var err error
+		if taskconfig.HasMultipleContainers(t.GetConfig()) {
+			executor, taskGroup, err = builder.BuildTaskGroup(t)
+		} else {
+			mesosTask, err = builder.Build(t, nil, nil)
+		}
 		if err != nil {
 			log.WithFields(log.Fields{
 				"tasks_total":    len(req.GetTasks()),
@@ -788,26 +815,52 @@ func (h *ServiceHandler) LaunchTasks(
 			}, nil
 		}
 
+		if taskGroup != nil {
+			// Tasks with sidecar or init containers are launched
+			// as a task group by the Mesos default executor.
+			executor.FrameworkId = h.frameworkInfoProvider.GetFrameworkID(ctx)
+			for _, groupTask := range taskGroup.GetTasks() {
+				groupTask.AgentId = req.GetAgentId()
+				mesosTaskIds = append(mesosTaskIds, groupTask.GetTaskId().GetValue())
+			}
+			launchGroups = append(launchGroups, &mesos.Offer_Operation_LaunchGroup{
+				Executor:  executor,
+				TaskGroup: taskGroup,
+			})
+			continue
+		}
+
 		mesosTask.AgentId = req.GetAgentId()
 		mesosTasks = append(mesosTasks, mesosTask)
 		mesosTaskIds = append(mesosTaskIds, mesosTask.GetTaskId().GetValue())
 	}
 
+	var operations []*mesos.Offer_Operation
+	if len(mesosTasks) > 0 {
+		opType := mesos.Offer_Operation_LAUNCH
+		operations = append(operations, &mesos.Offer_Operation{
+			Type: &opType,
+			Launch: &mesos.Offer_Operation_Launch{
+				TaskInfos: mesosTasks,
+			},
+		})
+	}
+	for _, launchGroup := range launchGroups {
+		opType := mesos.Offer_Operation_LAUNCH_GROUP
+		operations = append(operations, &mesos.Offer_Operation{
+			Type:        &opType,
+			LaunchGroup: launchGroup,
+		})
+	}
+	numTasks := len(mesosTasks) + len(launchGroups)
+
 	callType := sched.Call_ACCEPT
-	opType := mesos.Offer_Operation_LAUNCH
 	msg := &sched.Call{
 		FrameworkId: h.frameworkInfoProvider.GetFrameworkID(ctx),
 		Type:        &callType,
 		Accept: &sched.Call_Accept{
-			OfferIds: offerIds,
-			Operations: []*mesos.Offer_Operation{
-				{
-					Type: &opType,
-					Launch: &mesos.Offer_Operation_Launch{
-						TaskInfos: mesosTasks,
-					},
-				},
-			},
+			OfferIds:   offerIds,
+			Operations: operations,
 		},
 	}
 
@@ -819,9 +872,10 @@ func (h *ServiceHandler) LaunchTasks(
 	msid := h.frameworkInfoProvider.GetMesosStreamID(ctx)
 	err = h.schedulerClient.Call(msid, msg)
 	if err != nil {
-		h.metrics.LaunchTasksFail.Inc(int64(len(mesosTasks)))
+		h.metrics.LaunchTasksFail.Inc(int64(numTasks))
 		log.WithFields(log.Fields{
 			"tasks":         mesosTasks,
+			"task_groups":   launchGroups,
 			"offers":        offerIds,
 			"error":         err,
 			"host_offer_id": req.GetId().GetValue(),
@@ -836,9 +890,9 @@ func (h *ServiceHandler) LaunchTasks(
 		}, nil
 	}
 
-	h.metrics.LaunchTasks.Inc(int64(len(mesosTasks)))
+	h.metrics.LaunchTasks.Inc(int64(numTasks))
 	log.WithFields(log.Fields{
-		"tasks":         len(mesosTasks),
+		"tasks":         numTasks,
 		"offers":        len(offerIds),
 		"host_offer_id": req.GetId().GetValue(),
 	}).Debug("Tasks launched.")
@@ -1106,9 +1160,28 @@ func (h *ServiceHandler) ReserveResources(
 	ctx context.Context,
 	body *hostsvc.ReserveResourcesRequest) (
 	*hostsvc.ReserveResourcesResponse, error) {
+	log.WithField("request", body).Debug("ReserveResources called.")
 
-	log.Debug("ReserveResources called.")
-	return nil, fmt.Errorf("Unimplemented")
+	opErr := h.acceptResourceOperation(
+		ctx,
+		body.GetHostname(),
+		mesos.Offer_Operation_RESERVE,
+		body.GetResources(),
+		nil,
+	)
+	if opErr != nil {
+		h.metrics.ReserveResourcesFail.Inc(1)
+		return &hostsvc.ReserveResourcesResponse{
+			Error: &hostsvc.ReserveResourcesResponse_Error{
+				Failure:         opErr.failure,
+				InvalidArgument: opErr.invalidArgument,
+				InvalidOffers:   opErr.invalidOffers,
+			},
+		}, nil
+	}
+
+	h.metrics.ReserveResources.Inc(1)
+	return &hostsvc.ReserveResourcesResponse{}, nil
 }
 
 // UnreserveResources implements InternalHostService.UnreserveResources.
@@ -1116,9 +1189,28 @@ func (h *ServiceHandler) UnreserveResources(
 	ctx context.Context,
 	body *hostsvc.UnreserveResourcesRequest) (
 	*hostsvc.UnreserveResourcesResponse, error) {
+	log.WithField("request", body).Debug("UnreserveResources called.")
+
+	opErr := h.acceptResourceOperation(
+		ctx,
+		body.GetHostname(),
+		mesos.Offer_Operation_UNRESERVE,
+		body.GetResources(),
+		nil,
+	)
+	if opErr != nil {
+		h.metrics.UnreserveResourcesFail.Inc(1)
+		return &hostsvc.UnreserveResourcesResponse{
+			Error: &hostsvc.UnreserveResourcesResponse_Error{
+				Failure:         opErr.failure,
+				InvalidArgument: opErr.invalidArgument,
+				InvalidOffers:   opErr.invalidOffers,
+			},
+		}, nil
+	}
 
-	log.Debug("UnreserveResources called.")
-	return nil, fmt.Errorf("Unimplemented")
+	h.metrics.UnreserveResources.Inc(1)
+	return &hostsvc.UnreserveResourcesResponse{}, nil
 }
 
 // CreateVolumes implements InternalHostService.CreateVolumes.
@@ -1126,9 +1218,45 @@ func (h *ServiceHandler) CreateVolumes(
 	ctx context.Context,
 	body *hostsvc.CreateVolumesRequest) (
 	*hostsvc.CreateVolumesResponse, error) {
+	log.WithField("request", body).Debug("CreateVolumes called.")
+
+	// Volume info is written into db before the operation is sent to
+	// Mesos, same as a CREATE operation sent via OfferOperations.
+	persistVolumes := func(ctx context.Context) error {
+		for _, volumeRes := range body.GetVolumes() {
+			if err := h.persistVolume(
+				ctx,
+				volumeRes,
+				body.GetHostname()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 
-	log.Debug("CreateVolumes called.")
-	return nil, fmt.Errorf("Unimplemented")
+	opErr := h.acceptResourceOperation(
+		ctx,
+		body.GetHostname(),
+		mesos.Offer_Operation_CREATE,
+		body.GetVolumes(),
+		persistVolumes,
+	)
+	if opErr != nil {
+		h.metrics.CreateVolumesFail.Inc(1)
+		return &hostsvc.CreateVolumesResponse{
+			Error: &hostsvc.CreateVolumesResponse_Error{
+				Failure:         opErr.failure,
+				InvalidArgument: opErr.invalidArgument,
+				InvalidOffers:   opErr.invalidOffers,
+			},
+		}, nil
+	}
+
+	// The volumes stay in INITIALIZED state until Mesos has created them,
+	// they are moved to CREATED state by the reservation cleaner once they
+	// are offered with their persistence IDs.
+	h.metrics.CreateVolumes.Inc(1)
+	return &hostsvc.CreateVolumesResponse{}, nil
 }
 
 // DestroyVolumes implements InternalHostService.DestroyVolumes.
@@ -1136,9 +1264,192 @@ func (h *ServiceHandler) DestroyVolumes(
 	ctx context.Context,
 	body *hostsvc.DestroyVolumesRequest) (
 	*hostsvc.DestroyVolumesResponse, error) {
+	log.WithField("request", body).Debug("DestroyVolumes called.")
+
+	opErr := h.acceptResourceOperation(
+		ctx,
+		body.GetHostname(),
+		mesos.Offer_Operation_DESTROY,
+		body.GetVolumes(),
+		nil,
+	)
+	if opErr != nil {
+		h.metrics.DestroyVolumesFail.Inc(1)
+		return &hostsvc.DestroyVolumesResponse{
+			Error: &hostsvc.DestroyVolumesResponse_Error{
+				Failure:         opErr.failure,
+				InvalidArgument: opErr.invalidArgument,
+				InvalidOffers:   opErr.invalidOffers,
+			},
+		}, nil
+	}
+
+	h.updateVolumesState(
+		ctx,
+		body.GetVolumes(),
+		volume.VolumeState_DELETED,
+		volume.VolumeState_DELETED)
+
+	h.metrics.DestroyVolumes.Inc(1)
+	return &hostsvc.DestroyVolumesResponse{}, nil
+}
+
+// resourceOperationError is the failure of a standalone offer operation.
+// It maps onto the Error message of each of the operation responses.
+type resourceOperationError struct {
+	failure         *hostsvc.OperationsFailure
+	invalidArgument *hostsvc.InvalidArgument
+	invalidOffers   *hostsvc.InvalidOffers
+}
+
+// acceptResourceOperation claims offers on given host from the offer pool,
+// and accepts them with a Mesos operation of given type on given resources.
+// RESERVE is performed on unreserved offers, all other operations on
+// the reserved offers holding the reservations of the resources. The
+// optional prepare callback is invoked after the operation is built and
+// before it is sent to Mesos, its failure is reported as an operation
+// failure rather than an invalid argument.
+func (h *ServiceHandler) acceptResourceOperation(
+	ctx context.Context,
+	hostname string,
+	opType mesos.Offer_Operation_Type,
+	resources []*mesos.Resource,
+	prepare func(context.Context) error,
+) *resourceOperationError {
+	if len(hostname) == 0 {
+		return &resourceOperationError{
+			invalidArgument: &hostsvc.InvalidArgument{
+				Message: errEmptyHostName.Error(),
+			},
+		}
+	}
+	if len(resources) == 0 {
+		return &resourceOperationError{
+			invalidArgument: &hostsvc.InvalidArgument{
+				Message: errEmptyResources.Error(),
+			},
+		}
+	}
+
+	useReservedOffers := opType != mesos.Offer_Operation_RESERVE
+	offers, err := h.offerPool.ClaimForOperation(
+		hostname,
+		useReservedOffers,
+		resources)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"hostname":  hostname,
+			"operation": opType,
+		}).WithError(err).Warn("claim offers for operation failed")
+		return &resourceOperationError{
+			invalidOffers: &hostsvc.InvalidOffers{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	var offerIds []*mesos.OfferID
+	var offerResources []*mesos.Resource
+	var claimedOffers []*mesos.Offer
+	for _, offer := range offers {
+		offerIds = append(offerIds, offer.GetId())
+		offerResources = append(offerResources, offer.GetResources()...)
+		claimedOffers = append(claimedOffers, offer)
+	}
+
+	op, err := operation.NewResourceOperation(opType, offerResources, resources)
+	if err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"hostname":  hostname,
+			"operation": opType,
+			"resources": resources,
+			"offers":    offerIds,
+		}).Warn("get offer operation failed")
+		// Put the offers back, so that host summary reflects
+		// what Mesos has offered on the host.
+		h.offerPool.AddOffers(ctx, claimedOffers)
+		return &resourceOperationError{
+			invalidArgument: &hostsvc.InvalidArgument{
+				Message: "Cannot get offer operation: " + err.Error(),
+			},
+		}
+	}
+
+	if prepare != nil {
+		if err := prepare(ctx); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"hostname":  hostname,
+				"operation": opType,
+				"offers":    offerIds,
+			}).Warn("prepare offer operation failed")
+			h.offerPool.AddOffers(ctx, claimedOffers)
+			return &resourceOperationError{
+				failure: &hostsvc.OperationsFailure{
+					Message: "Cannot prepare offer operation: " + err.Error(),
+				},
+			}
+		}
+	}
+
+	callType := sched.Call_ACCEPT
+	msg := &sched.Call{
+		FrameworkId: h.frameworkInfoProvider.GetFrameworkID(ctx),
+		Type:        &callType,
+		Accept: &sched.Call_Accept{
+			OfferIds:   offerIds,
+			Operations: []*mesos.Offer_Operation{op},
+		},
+	}
+
+	msid := h.frameworkInfoProvider.GetMesosStreamID(ctx)
+	if err := h.schedulerClient.Call(msid, msg); err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"hostname":  hostname,
+			"operation": op,
+			"offers":    offerIds,
+		}).Warn("offer operation failure")
+		return &resourceOperationError{
+			failure: &hostsvc.OperationsFailure{
+				Message: err.Error(),
+			},
+		}
+	}
 
-	log.Debug("DestroyVolumes called.")
-	return nil, fmt.Errorf("Unimplemented")
+	log.WithFields(log.Fields{
+		"hostname":  hostname,
+		"operation": opType,
+		"offers":    offerIds,
+	}).Info("accepted offers with operation")
+	return nil
+}
+
+// updateVolumesState sets the state and goal state of given volumes in db.
+// Volumes which are not tracked in db are skipped.
+func (h *ServiceHandler) updateVolumesState(
+	ctx context.Context,
+	volumes []*mesos.Resource,
+	state volume.VolumeState,
+	goalState volume.VolumeState) {
+	for _, volumeRes := range volumes {
+		volumeID := &peloton.VolumeID{
+			Value: volumeRes.GetDisk().GetPersistence().GetId(),
+		}
+		pv, err := h.volumeStore.GetPersistentVolume(ctx, volumeID)
+		if err != nil {
+			log.WithError(err).
+				WithField("volume_id", volumeID.GetValue()).
+				Warn("failed to get volume to update state")
+			continue
+		}
+
+		pv.State = state
+		pv.GoalState = goalState
+		if err := h.volumeStore.UpdatePersistentVolume(ctx, pv); err != nil {
+			log.WithError(err).
+				WithField("volume_id", volumeID.GetValue()).
+				Error("failed to update volume state")
+		}
+	}
 }
 
 // ClusterCapacity fetches the allocated resources to the framework
@@ -1390,6 +1701,18 @@ func (h *ServiceHandler) MarkHostsDrained(
 	if len(machineIDs) == 0 {
 		return &hostsvc.MarkHostsDrainedResponse{}, nil
 	}
+	// Accept the inverse offers of the drained hosts to let Mesos know
+	// that the hosts can be made unavailable
+	var drainedHosts []string
+	for _, machineID := range machineIDs {
+		drainedHosts = append(drainedHosts, machineID.GetHostname())
+	}
+	if err := h.offerPool.AcceptInverseOffers(ctx, drainedHosts); err != nil {
+		log.WithError(err).
+			WithField("hosts", drainedHosts).
+			Warn("failed to accept inverse offers")
+	}
+
 	var downedHosts []string
 	var errs error
 	for _, machineID := range machineIDs {
@@ -1429,6 +1752,38 @@ func (h *ServiceHandler) MarkHostsDrained(
 	}, errs
 }
 
+// MarkHostsBlocked implements InternalHostService.MarkHostsBlocked
+// Set the jobs blocking the drain of the DRAINING hosts. This method is
+// called by Resource Manager Drainer when the SLA of the jobs running on
+// the DRAINING hosts holds the eviction of their tasks
+func (h *ServiceHandler) MarkHostsBlocked(
+	ctx context.Context,
+	request *hostsvc.MarkHostsBlockedRequest,
+) (*hostsvc.MarkHostsBlockedResponse, error) {
+	var ignoredHosts []string
+	for _, blockedHost := range request.GetHosts() {
+		if err := h.maintenanceHostInfoMap.SetBlockingJobs(
+			blockedHost.GetHostname(),
+			blockedHost.GetBlockingJobs()); err != nil {
+			// the host may have been drained or brought up
+			// since it was dequeued by the drainer
+			log.WithError(err).
+				WithField("hostname", blockedHost.GetHostname()).
+				Info("failed to set blocking jobs of host")
+			ignoredHosts = append(ignoredHosts, blockedHost.GetHostname())
+			h.metrics.MarkHostsBlockedFail.Inc(1)
+			continue
+		}
+		if len(blockedHost.GetBlockingJobs()) != 0 {
+			h.metrics.MarkHostsBlocked.Inc(1)
+		}
+	}
+
+	return &hostsvc.MarkHostsBlockedResponse{
+		IgnoredHosts: ignoredHosts,
+	}, nil
+}
+
 // GetMesosAgentInfo implements InternalHostService.GetMesosAgentInfo
 // Returns Mesos agent info for a single agent or all agents.
 func (h *ServiceHandler) GetMesosAgentInfo(
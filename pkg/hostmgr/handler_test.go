@@ -58,6 +58,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
 	"golang.org/x/net/context"
 )
 
@@ -416,6 +417,41 @@ func (suite *HostMgrHandlerTestSuite) TestGetHostsByQueryFilterHostnames() {
 	suite.Equal("hostname-2", resp.Hosts[1].Hostname)
 }
 
+// TestGetHostsByQueryInverseOffers tests that the pending inverse offers
+// of the hosts are returned
+func (suite *HostMgrHandlerTestSuite) TestGetHostsByQueryInverseOffers() {
+	defer suite.ctrl.Finish()
+
+	hostname := "hostname-0"
+	inverseOfferID := "inverse-offer-0"
+	start := time.Now().Add(time.Hour).UnixNano()
+	suite.pool.AddOffers(context.Background(), []*mesos.Offer{
+		generateOfferWithResource(
+			"offer-0", "agent-0", hostname, 1.0, _perHostMem, _perHostDisk, 1.0),
+	})
+	suite.pool.AddInverseOffers(context.Background(), []*mesos.InverseOffer{
+		{
+			Id: &mesos.OfferID{Value: &inverseOfferID},
+			Url: &mesos.URL{
+				Address: &mesos.Address{Hostname: &hostname},
+			},
+			Unavailability: &mesos.Unavailability{
+				Start: &mesos.TimeInfo{Nanoseconds: &start},
+			},
+		},
+	})
+
+	resp, err := suite.handler.GetHostsByQuery(
+		rootCtx,
+		&hostsvc.GetHostsByQueryRequest{Hostnames: []string{hostname}})
+	suite.NoError(err)
+	suite.Len(resp.GetHosts(), 1)
+	suite.Len(resp.GetHosts()[0].GetInverseOffers(), 1)
+	suite.Equal(
+		inverseOfferID,
+		resp.GetHosts()[0].GetInverseOffers()[0].GetId().GetValue())
+}
+
 func (suite *HostMgrHandlerTestSuite) TestGetHostsByQueryGreaterThanEqualTo() {
 	defer suite.ctrl.Finish()
 
@@ -2060,6 +2096,343 @@ func (suite *HostMgrHandlerTestSuite) TestReserveCreateLaunchOperationWithCreate
 		suite.testScope.Snapshot().Counters()["offer_operations+"].Value())
 }
 
+// createReservedResources returns cpu, mem and disk resources reserved
+// with the test reservation labels.
+func createReservedResources() []*mesos.Resource {
+	reservation := &mesos.Resource_ReservationInfo{
+		Labels: createReservationLabels(),
+	}
+	return []*mesos.Resource{
+		util.NewMesosResourceBuilder().
+			WithName(_cpuName).
+			WithValue(_perHostCPU).
+			WithRole(_pelotonRole).
+			WithReservation(reservation).
+			Build(),
+		util.NewMesosResourceBuilder().
+			WithName(_memName).
+			WithValue(_perHostMem).
+			WithRole(_pelotonRole).
+			WithReservation(reservation).
+			Build(),
+		util.NewMesosResourceBuilder().
+			WithName(_diskName).
+			WithValue(_perHostDisk).
+			WithRole(_pelotonRole).
+			WithReservation(reservation).
+			Build(),
+	}
+}
+
+// createVolumeResource returns a persistent volume on reserved disk.
+func createVolumeResource() *mesos.Resource {
+	volumeID := "volume-0"
+	containerPath := "/data"
+	return util.NewMesosResourceBuilder().
+		WithName(_diskName).
+		WithValue(_defaultResourceValue).
+		WithRole(_pelotonRole).
+		WithReservation(&mesos.Resource_ReservationInfo{
+			Labels: createReservationLabels(),
+		}).
+		WithDisk(&mesos.Resource_DiskInfo{
+			Persistence: &mesos.Resource_DiskInfo_Persistence{
+				Id: &volumeID,
+			},
+			Volume: &mesos.Volume{
+				ContainerPath: &containerPath,
+			},
+		}).
+		Build()
+}
+
+// expectAcceptOperation sets expectations for an ACCEPT call carrying
+// a single operation of given type.
+func (suite *HostMgrHandlerTestSuite) expectAcceptOperation(
+	opType mesos.Offer_Operation_Type,
+	callErr error) {
+	suite.provider.EXPECT().GetFrameworkID(gomock.Any()).Return(
+		suite.frameworkID)
+	suite.provider.EXPECT().GetMesosStreamID(gomock.Any()).Return(_streamID)
+	suite.schedulerClient.EXPECT().
+		Call(gomock.Eq(_streamID), gomock.Any()).
+		Do(func(_ string, msg proto.Message) {
+			call := msg.(*sched.Call)
+			suite.Equal(sched.Call_ACCEPT, call.GetType())
+			suite.Equal("offer-0", call.GetAccept().GetOfferIds()[0].GetValue())
+			suite.Equal(1, len(call.GetAccept().GetOperations()))
+			suite.Equal(opType, call.GetAccept().GetOperations()[0].GetType())
+		}).
+		Return(callErr)
+}
+
+// TestReserveResources tests reserving resources from unreserved offers.
+func (suite *HostMgrHandlerTestSuite) TestReserveResources() {
+	defer suite.ctrl.Finish()
+
+	suite.pool.AddOffers(context.Background(), generateOffers(1))
+	suite.expectAcceptOperation(mesos.Offer_Operation_RESERVE, nil)
+
+	resp, err := suite.handler.ReserveResources(
+		rootCtx,
+		&hostsvc.ReserveResourcesRequest{
+			Hostname:  "hostname-0",
+			Resources: createReservedResources()[:2],
+		})
+	suite.NoError(err)
+	suite.Nil(resp.GetError())
+	suite.Equal(
+		int64(1),
+		suite.testScope.Snapshot().Counters()["reserve_resources+"].Value())
+
+	// offers on the host are used by the operation.
+	offers, _ := suite.pool.GetOffers(summary.All)
+	suite.Empty(offers["hostname-0"])
+}
+
+// TestReserveResourcesErrors tests failures of reserving resources.
+func (suite *HostMgrHandlerTestSuite) TestReserveResourcesErrors() {
+	defer suite.ctrl.Finish()
+
+	// empty hostname
+	resp, err := suite.handler.ReserveResources(
+		rootCtx,
+		&hostsvc.ReserveResourcesRequest{
+			Resources: createReservedResources(),
+		})
+	suite.NoError(err)
+	suite.NotNil(resp.GetError().GetInvalidArgument())
+
+	// no offer on the host
+	resp, err = suite.handler.ReserveResources(
+		rootCtx,
+		&hostsvc.ReserveResourcesRequest{
+			Hostname:  "hostname-0",
+			Resources: createReservedResources(),
+		})
+	suite.NoError(err)
+	suite.NotNil(resp.GetError().GetInvalidOffers())
+
+	// resources more than offered, offers are put back in the pool
+	suite.pool.AddOffers(context.Background(), generateOffers(1))
+	resources := createReservedResources()
+	resources[0].Scalar.Value = proto.Float64(_perHostCPU + 1)
+	resp, err = suite.handler.ReserveResources(
+		rootCtx,
+		&hostsvc.ReserveResourcesRequest{
+			Hostname:  "hostname-0",
+			Resources: resources,
+		})
+	suite.NoError(err)
+	suite.NotNil(resp.GetError().GetInvalidArgument())
+	offers, _ := suite.pool.GetOffers(summary.Unreserved)
+	suite.Len(offers["hostname-0"], 1)
+
+	// scheduler call failure
+	suite.expectAcceptOperation(
+		mesos.Offer_Operation_RESERVE,
+		errors.New("some error"))
+	resp, err = suite.handler.ReserveResources(
+		rootCtx,
+		&hostsvc.ReserveResourcesRequest{
+			Hostname:  "hostname-0",
+			Resources: createReservedResources(),
+		})
+	suite.NoError(err)
+	suite.NotNil(resp.GetError().GetFailure())
+	suite.Equal(
+		int64(4),
+		suite.testScope.Snapshot().Counters()["reserve_resources_fail+"].Value())
+}
+
+// TestUnreserveResources tests unreserving resources from reserved offers.
+func (suite *HostMgrHandlerTestSuite) TestUnreserveResources() {
+	defer suite.ctrl.Finish()
+
+	reservedOffers := generateOffers(1)
+	reservedOffers[0].Resources = createReservedResources()
+	suite.pool.AddOffers(context.Background(), reservedOffers)
+	suite.expectAcceptOperation(mesos.Offer_Operation_UNRESERVE, nil)
+
+	resp, err := suite.handler.UnreserveResources(
+		rootCtx,
+		&hostsvc.UnreserveResourcesRequest{
+			Hostname:  "hostname-0",
+			Resources: createReservedResources(),
+		})
+	suite.NoError(err)
+	suite.Nil(resp.GetError())
+	suite.Equal(
+		int64(1),
+		suite.testScope.Snapshot().Counters()["unreserve_resources+"].Value())
+
+	offers, _ := suite.pool.GetOffers(summary.Reserved)
+	suite.Empty(offers["hostname-0"])
+}
+
+// TestCreateAndDestroyVolumes tests creating and destroying a volume on
+// reserved offers, and that the volume store is kept up to date.
+func (suite *HostMgrHandlerTestSuite) TestCreateAndDestroyVolumes() {
+	defer suite.ctrl.Finish()
+
+	volumeRes := createVolumeResource()
+	volumeInfo := &volume.PersistentVolumeInfo{
+		Id: &peloton.VolumeID{
+			Value: volumeRes.GetDisk().GetPersistence().GetId(),
+		},
+	}
+
+	reservedOffers := generateOffers(1)
+	reservedOffers[0].Resources = createReservedResources()
+	suite.pool.AddOffers(context.Background(), reservedOffers)
+
+	gomock.InOrder(
+		suite.volumeStore.EXPECT().
+			GetPersistentVolume(gomock.Any(), volumeInfo.GetId()).
+			Return(nil, nil),
+		suite.volumeStore.EXPECT().
+			CreatePersistentVolume(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, info *volume.PersistentVolumeInfo) {
+				suite.Equal(_testJobID, info.GetJobId().GetValue())
+				suite.Equal("hostname-0", info.GetHostname())
+				suite.Equal(volume.VolumeState_INITIALIZED, info.GetState())
+			}).
+			Return(nil),
+	)
+	// the volume is not moved to CREATED state until Mesos offers it.
+	suite.expectAcceptOperation(mesos.Offer_Operation_CREATE, nil)
+
+	createResp, err := suite.handler.CreateVolumes(
+		rootCtx,
+		&hostsvc.CreateVolumesRequest{
+			Hostname: "hostname-0",
+			Volumes:  []*mesos.Resource{volumeRes},
+		})
+	suite.NoError(err)
+	suite.Nil(createResp.GetError())
+	suite.Equal(
+		int64(1),
+		suite.testScope.Snapshot().Counters()["create_volumes+"].Value())
+
+	// Mesos offers the volume back with the reserved resources.
+	reservedOffers = generateOffers(1)
+	reservedOffers[0].Resources = append(createReservedResources(), volumeRes)
+	suite.pool.AddOffers(context.Background(), reservedOffers)
+
+	suite.expectAcceptOperation(mesos.Offer_Operation_DESTROY, nil)
+	suite.volumeStore.EXPECT().
+		GetPersistentVolume(gomock.Any(), volumeInfo.GetId()).
+		Return(volumeInfo, nil)
+	suite.volumeStore.EXPECT().
+		UpdatePersistentVolume(gomock.Any(), volumeInfo).
+		Do(func(_ context.Context, info *volume.PersistentVolumeInfo) {
+			suite.Equal(volume.VolumeState_DELETED, info.GetState())
+			suite.Equal(volume.VolumeState_DELETED, info.GetGoalState())
+		}).
+		Return(nil)
+
+	destroyResp, err := suite.handler.DestroyVolumes(
+		rootCtx,
+		&hostsvc.DestroyVolumesRequest{
+			Hostname: "hostname-0",
+			Volumes:  []*mesos.Resource{volumeRes},
+		})
+	suite.NoError(err)
+	suite.Nil(destroyResp.GetError())
+	suite.Equal(
+		int64(1),
+		suite.testScope.Snapshot().Counters()["destroy_volumes+"].Value())
+}
+
+// TestCreateVolumesStoreFailure tests that a failure to write the volume
+// into db is reported as an operation failure, and the offers are put back.
+func (suite *HostMgrHandlerTestSuite) TestCreateVolumesStoreFailure() {
+	defer suite.ctrl.Finish()
+
+	volumeRes := createVolumeResource()
+	reservedOffers := generateOffers(1)
+	reservedOffers[0].Resources = createReservedResources()
+	suite.pool.AddOffers(context.Background(), reservedOffers)
+
+	suite.volumeStore.EXPECT().
+		GetPersistentVolume(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("some error"))
+
+	resp, err := suite.handler.CreateVolumes(
+		rootCtx,
+		&hostsvc.CreateVolumesRequest{
+			Hostname: "hostname-0",
+			Volumes:  []*mesos.Resource{volumeRes},
+		})
+	suite.NoError(err)
+	suite.NotNil(resp.GetError().GetFailure())
+	suite.Nil(resp.GetError().GetInvalidArgument())
+
+	offers, _ := suite.pool.GetOffers(summary.Reserved)
+	suite.Len(offers["hostname-0"], 1)
+}
+
+// TestUnreserveResourcesOtherReservation tests that the reserved offers
+// which do not hold the reservation of the resources are not claimed.
+func (suite *HostMgrHandlerTestSuite) TestUnreserveResourcesOtherReservation() {
+	defer suite.ctrl.Finish()
+
+	reservedOffers := generateOffers(1)
+	reservedOffers[0].Resources = createReservedResources()
+	suite.pool.AddOffers(context.Background(), reservedOffers)
+
+	resources := createReservedResources()
+	for _, res := range resources {
+		res.Reservation = &mesos.Resource_ReservationInfo{
+			Labels: &mesos.Labels{
+				Labels: []*mesos.Label{
+					{
+						Key:   proto.String("other-key"),
+						Value: proto.String("other-value"),
+					},
+				},
+			},
+		}
+	}
+
+	resp, err := suite.handler.UnreserveResources(
+		rootCtx,
+		&hostsvc.UnreserveResourcesRequest{
+			Hostname:  "hostname-0",
+			Resources: resources,
+		})
+	suite.NoError(err)
+	suite.NotNil(resp.GetError().GetInvalidOffers())
+
+	offers, _ := suite.pool.GetOffers(summary.Reserved)
+	suite.Len(offers["hostname-0"], 1)
+}
+
+// TestDestroyVolumesNotOffered tests destroying a volume which is not
+// present in the offers of the host.
+func (suite *HostMgrHandlerTestSuite) TestDestroyVolumesNotOffered() {
+	defer suite.ctrl.Finish()
+
+	reservedOffers := generateOffers(1)
+	reservedOffers[0].Resources = createReservedResources()
+	suite.pool.AddOffers(context.Background(), reservedOffers)
+
+	resp, err := suite.handler.DestroyVolumes(
+		rootCtx,
+		&hostsvc.DestroyVolumesRequest{
+			Hostname: "hostname-0",
+			Volumes:  []*mesos.Resource{createVolumeResource()},
+		})
+	suite.NoError(err)
+	suite.NotNil(resp.GetError().GetInvalidArgument())
+	suite.Equal(
+		int64(1),
+		suite.testScope.Snapshot().Counters()["destroy_volumes_fail+"].Value())
+
+	offers, _ := suite.pool.GetOffers(summary.Reserved)
+	suite.Len(offers["hostname-0"], 1)
+}
+
 func (suite *HostMgrHandlerTestSuite) TestGetMesosMasterHostPort() {
 	defer suite.ctrl.Finish()
 
@@ -2207,6 +2580,35 @@ func (suite *HostMgrHandlerTestSuite) TestServiceHandlerMarkHostsDrained() {
 	suite.Nil(resp.GetMarkedHosts())
 }
 
+// TestServiceHandlerMarkHostsBlocked tests setting the jobs blocking
+// the drain of the hosts, and ignoring the hosts which are not DRAINING
+func (suite *HostMgrHandlerTestSuite) TestServiceHandlerMarkHostsBlocked() {
+	defer suite.ctrl.Finish()
+
+	blockingJobs := []*peloton.JobID{{Value: uuid.New()}}
+	suite.maintenanceHostInfoMap.EXPECT().
+		SetBlockingJobs("host1", blockingJobs).
+		Return(nil)
+	suite.maintenanceHostInfoMap.EXPECT().
+		SetBlockingJobs("host2", nil).
+		Return(nil)
+	suite.maintenanceHostInfoMap.EXPECT().
+		SetBlockingJobs("host3", blockingJobs).
+		Return(yarpcerrors.InvalidArgumentErrorf("host not in expected state"))
+
+	resp, err := suite.handler.MarkHostsBlocked(
+		context.Background(),
+		&hostsvc.MarkHostsBlockedRequest{
+			Hosts: []*hostsvc.BlockedHost{
+				{Hostname: "host1", BlockingJobs: blockingJobs},
+				{Hostname: "host2"},
+				{Hostname: "host3", BlockingJobs: blockingJobs},
+			},
+		})
+	suite.NoError(err)
+	suite.Equal([]string{"host3"}, resp.GetIgnoredHosts())
+}
+
 func getAcquireHostOffersRequest() *hostsvc.AcquireHostOffersRequest {
 	return &hostsvc.AcquireHostOffersRequest{
 		Filter: &hostsvc.HostFilter{
@@ -2797,6 +3199,105 @@ func (suite *HostMgrHandlerTestSuite) TestLaunchTasksSchedulerError() {
 		errString)
 }
 
+// TestLaunchTasksWithTaskGroup tests that tasks with sidecar containers
+// are launched as task groups along with the other tasks.
+func (suite *HostMgrHandlerTestSuite) TestLaunchTasksWithTaskGroup() {
+	defer suite.ctrl.Finish()
+
+	// the default executor requires more memory than the default offer
+	suite.pool.AddOffers(context.Background(), []*mesos.Offer{
+		generateOfferWithResource(
+			"offer-0", "agent-0", "hostname-0",
+			_perHostCPU, 5*_perHostMem, _perHostDisk, 0.0),
+	})
+	acquiredResp, err := suite.handler.AcquireHostOffers(
+		rootCtx,
+		&hostsvc.AcquireHostOffersRequest{
+			Filter: &hostsvc.HostFilter{
+				Quantity: &hostsvc.QuantityControl{
+					MaxHosts: uint32(1),
+				},
+				ResourceConstraint: &hostsvc.ResourceConstraint{
+					Minimum: &task.ResourceConfig{
+						CpuLimit:   _perHostCPU,
+						MemLimitMb: _perHostMem,
+					},
+				},
+			},
+		},
+	)
+	suite.NoError(err)
+	acquiredHostOffers := acquiredResp.GetHostOffers()
+	suite.Len(acquiredHostOffers, 1)
+
+	sidecarCmd := _defaultCmd
+	tasks := generateLaunchableTasks(2)
+	for _, t := range tasks {
+		t.Config.Resource = &task.ResourceConfig{CpuLimit: 1, MemLimitMb: 1}
+	}
+	tasks[1].Config.Sidecars = []*task.ContainerConfig{
+		{
+			Name:     "proxy",
+			Resource: &task.ResourceConfig{CpuLimit: 1, MemLimitMb: 1},
+			Command:  &mesos.CommandInfo{Value: &sidecarCmd},
+		},
+	}
+
+	gomock.InOrder(
+		suite.provider.EXPECT().GetFrameworkID(context.Background()).Return(
+			suite.frameworkID).Times(2),
+		suite.provider.EXPECT().GetMesosStreamID(context.Background()).Return(_streamID),
+		suite.schedulerClient.EXPECT().
+			Call(
+				gomock.Eq(_streamID),
+				gomock.Any(),
+			).
+			Do(func(_ string, msg proto.Message) {
+				operations := msg.(*sched.Call).GetAccept().GetOperations()
+				suite.Len(operations, 2)
+
+				suite.Equal(mesos.Offer_Operation_LAUNCH, operations[0].GetType())
+				suite.Len(operations[0].GetLaunch().GetTaskInfos(), 1)
+				suite.Equal(
+					fmt.Sprintf(_taskIDFmt, 0),
+					operations[0].GetLaunch().GetTaskInfos()[0].GetTaskId().GetValue())
+
+				suite.Equal(mesos.Offer_Operation_LAUNCH_GROUP, operations[1].GetType())
+				launchGroup := operations[1].GetLaunchGroup()
+				suite.Equal(mesos.ExecutorInfo_DEFAULT, launchGroup.GetExecutor().GetType())
+				suite.Equal(_frameworkID, launchGroup.GetExecutor().GetFrameworkId().GetValue())
+				groupTasks := launchGroup.GetTaskGroup().GetTasks()
+				suite.Len(groupTasks, 2)
+				suite.Equal(
+					fmt.Sprintf(_taskIDFmt, 1),
+					groupTasks[0].GetTaskId().GetValue())
+				suite.Equal(
+					fmt.Sprintf(_taskIDFmt, 1)+".proxy",
+					groupTasks[1].GetTaskId().GetValue())
+				for _, groupTask := range groupTasks {
+					suite.Equal("agent-0", groupTask.GetAgentId().GetValue())
+				}
+			}).
+			Return(nil),
+	)
+
+	launchResp, err := suite.handler.LaunchTasks(
+		rootCtx,
+		&hostsvc.LaunchTasksRequest{
+			Hostname: acquiredHostOffers[0].GetHostname(),
+			AgentId:  acquiredHostOffers[0].GetAgentId(),
+			Tasks:    tasks,
+			Id:       acquiredHostOffers[0].GetId(),
+		},
+	)
+
+	suite.NoError(err)
+	suite.Nil(launchResp.GetError())
+	suite.Equal(
+		int64(2),
+		suite.testScope.Snapshot().Counters()["launch_tasks+"].Value())
+}
+
 func (suite *HostMgrHandlerTestSuite) TestReleaseHostsHeldForTasks() {
 	defer suite.ctrl.Finish()
 
@@ -15,17 +15,24 @@
 package host
 
 import (
+	"context"
 	"time"
 
 	"github.com/uber/peloton/pkg/common/lifecycle"
+	"github.com/uber/peloton/pkg/common/stringset"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
 	"github.com/uber/peloton/pkg/hostmgr/queue"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
 	host "github.com/uber/peloton/.gen/peloton/api/v0/host"
 
 	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
 )
 
+// timeout of the storage calls for the maintenance windows
+const _maintenanceWindowTimeout = 10 * time.Second
+
 // drainer defines the host drainer which drains
 // the hosts which are to be put into maintenance
 type drainer struct {
@@ -34,6 +41,7 @@ type drainer struct {
 	maintenanceQueue       queue.MaintenanceQueue
 	lifecycle              lifecycle.LifeCycle // lifecycle manager
 	maintenanceHostInfoMap MaintenanceHostInfoMap
+	maintenanceWindowOps   ormobjects.MaintenanceWindowOps
 }
 
 // Drainer defines the interface for host drainer
@@ -48,6 +56,7 @@ func NewDrainer(
 	masterOperatorClient mpb.MasterOperatorClient,
 	maintenanceQueue queue.MaintenanceQueue,
 	hostInfoMap MaintenanceHostInfoMap,
+	maintenanceWindowOps ormobjects.MaintenanceWindowOps,
 ) Drainer {
 	return &drainer{
 		drainerPeriod:          drainerPeriod,
@@ -55,6 +64,7 @@ func NewDrainer(
 		maintenanceQueue:       maintenanceQueue,
 		lifecycle:              lifecycle.NewLifeCycle(),
 		maintenanceHostInfoMap: hostInfoMap,
+		maintenanceWindowOps:   maintenanceWindowOps,
 	}
 }
 
@@ -103,14 +113,35 @@ func (d *drainer) Stop() {
 }
 
 func (d *drainer) reconcileMaintenanceState() error {
+	if err := d.completeMaintenanceWindows(); err != nil {
+		log.WithError(err).
+			Warn("Failed to complete closed maintenance windows")
+	}
+
 	response, err := d.masterOperatorClient.GetMaintenanceStatus()
 	if err != nil {
 		return err
 	}
+
+	// hosts whose maintenance window has not opened yet
+	scheduledHosts := stringset.New()
+	if len(response.GetStatus().GetDrainingMachines()) != 0 {
+		scheduleResponse, err := d.masterOperatorClient.GetMaintenanceSchedule()
+		if err != nil {
+			return err
+		}
+		scheduledHosts = GetScheduledHosts(
+			scheduleResponse.GetSchedule(),
+			time.Now())
+	}
+
 	var drainingHosts []string
 	var hostInfos []*host.HostInfo
 	for _, drainingMachine := range response.GetStatus().GetDrainingMachines() {
 		machineID := drainingMachine.GetId()
+		if scheduledHosts.Contains(machineID.GetHostname()) {
+			continue
+		}
 		hostInfos = append(hostInfos,
 			&host.HostInfo{
 				Hostname: machineID.GetHostname(),
@@ -131,3 +162,50 @@ func (d *drainer) reconcileMaintenanceState() error {
 	d.maintenanceHostInfoMap.ClearAndFillMap(hostInfos)
 	return d.maintenanceQueue.Enqueue(drainingHosts)
 }
+
+// completeMaintenanceWindows ends the maintenance of the hosts of the
+// maintenance windows which have closed
+func (d *drainer) completeMaintenanceWindows() error {
+	ctx, cancelFunc := context.WithTimeout(
+		context.Background(),
+		_maintenanceWindowTimeout)
+	defer cancelFunc()
+
+	objs, err := d.maintenanceWindowOps.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	now := time.Now()
+	for _, obj := range objs {
+		window, err := obj.GetWindow()
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		_, end, err := GetMaintenanceWindowTimes(window)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		if now.Before(end) {
+			continue
+		}
+
+		if err := EndMaintenance(
+			d.masterOperatorClient,
+			d.maintenanceHostInfoMap,
+			window); err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		if err := d.maintenanceWindowOps.Delete(ctx, window.GetId()); err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		log.WithField("maintenance_window", window).
+			Info("Maintenance window completed")
+	}
+	return errs
+}
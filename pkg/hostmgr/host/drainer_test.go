@@ -28,7 +28,10 @@ import (
 	host_mocks "github.com/uber/peloton/pkg/hostmgr/host/mocks"
 	mpb_mocks "github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb/mocks"
 	mq_mocks "github.com/uber/peloton/pkg/hostmgr/queue/mocks"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
 )
@@ -44,6 +47,7 @@ type drainerTestSuite struct {
 	mockMasterOperatorClient *mpb_mocks.MockMasterOperatorClient
 	mockMaintenanceQueue     *mq_mocks.MockMaintenanceQueue
 	mockMaintenanceMap       *host_mocks.MockMaintenanceHostInfoMap
+	mockWindowOps            *objectmocks.MockMaintenanceWindowOps
 	drainingMachines         []*mesos.MachineID
 	downMachines             []*mesos.MachineID
 	hostInfos                []*host.HostInfo
@@ -108,6 +112,7 @@ func (suite *drainerTestSuite) SetupTest() {
 	suite.mockMasterOperatorClient = mpb_mocks.NewMockMasterOperatorClient(suite.mockCtrl)
 	suite.mockMaintenanceQueue = mq_mocks.NewMockMaintenanceQueue(suite.mockCtrl)
 	suite.mockMaintenanceMap = host_mocks.NewMockMaintenanceHostInfoMap(suite.mockCtrl)
+	suite.mockWindowOps = objectmocks.NewMockMaintenanceWindowOps(suite.mockCtrl)
 
 	suite.drainer = &drainer{
 		drainerPeriod:          drainerPeriod,
@@ -115,6 +120,7 @@ func (suite *drainerTestSuite) SetupTest() {
 		maintenanceQueue:       suite.mockMaintenanceQueue,
 		lifecycle:              lifecycle.NewLifeCycle(),
 		maintenanceHostInfoMap: suite.mockMaintenanceMap,
+		maintenanceWindowOps:   suite.mockWindowOps,
 	}
 }
 
@@ -131,7 +137,8 @@ func (suite *drainerTestSuite) TestDrainerNewDrainer() {
 	drainer := NewDrainer(drainerPeriod,
 		suite.mockMasterOperatorClient,
 		suite.mockMaintenanceQueue,
-		host_mocks.NewMockMaintenanceHostInfoMap(suite.mockCtrl))
+		host_mocks.NewMockMaintenanceHostInfoMap(suite.mockCtrl),
+		suite.mockWindowOps)
 	suite.NotNil(drainer)
 }
 
@@ -158,12 +165,24 @@ func (suite *drainerTestSuite) TestDrainerStartSuccess() {
 		drainingHostnames = append(drainingHostnames, drainingMachine.GetHostname())
 	}
 
+	suite.mockWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, nil).
+		MinTimes(1).
+		MaxTimes(2)
+
 	suite.mockMasterOperatorClient.EXPECT().
 		GetMaintenanceStatus().
 		Return(&response, nil).
 		MinTimes(1).
 		MaxTimes(2)
 
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceSchedule().
+		Return(&mesos_master.Response_GetMaintenanceSchedule{}, nil).
+		MinTimes(1).
+		MaxTimes(2)
+
 	suite.mockMaintenanceMap.EXPECT().
 		ClearAndFillMap(suite.hostInfos).
 		MinTimes(1).
@@ -185,6 +204,12 @@ func (suite *drainerTestSuite) TestDrainerStartSuccess() {
 // TestDrainerStartGetMaintenanceStatusFailure tests the failure case of
 // starting the host drainer due to error while getting maintenance status
 func (suite *drainerTestSuite) TestDrainerStartGetMaintenanceStatusFailure() {
+	suite.mockWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, fmt.Errorf("Fake GetAll error")).
+		MinTimes(1).
+		MaxTimes(2)
+
 	suite.mockMasterOperatorClient.EXPECT().
 		GetMaintenanceStatus().
 		Return(nil, fmt.Errorf("Fake GetMaintenanceStatus error")).
@@ -221,12 +246,24 @@ func (suite *drainerTestSuite) TestDrainerStartEnqueueFailure() {
 			drainingMachine.GetHostname())
 	}
 
+	suite.mockWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, nil).
+		MinTimes(1).
+		MaxTimes(2)
+
 	suite.mockMasterOperatorClient.EXPECT().
 		GetMaintenanceStatus().
 		Return(&response, nil).
 		MinTimes(1).
 		MaxTimes(2)
 
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceSchedule().
+		Return(&mesos_master.Response_GetMaintenanceSchedule{}, nil).
+		MinTimes(1).
+		MaxTimes(2)
+
 	suite.mockMaintenanceMap.EXPECT().
 		ClearAndFillMap(suite.hostInfos).
 		MinTimes(1).
@@ -243,6 +280,104 @@ func (suite *drainerTestSuite) TestDrainerStartEnqueueFailure() {
 	suite.drainer.Stop()
 }
 
+// TestDrainerSkipsScheduledHosts tests that the draining hosts whose
+// maintenance window has not opened yet are not drained
+func (suite *drainerTestSuite) TestDrainerSkipsScheduledHosts() {
+	response := &mesos_master.Response_GetMaintenanceStatus{
+		Status: &mesos_maintenance.ClusterStatus{
+			DrainingMachines: []*mesos_maintenance.ClusterStatus_DrainingMachine{
+				{Id: suite.drainingMachines[0]},
+			},
+		},
+	}
+	start := time.Now().Add(time.Hour).UnixNano()
+	schedule := &mesos_master.Response_GetMaintenanceSchedule{
+		Schedule: &mesos_maintenance.Schedule{
+			Windows: []*mesos_maintenance.Window{
+				{
+					MachineIds: suite.drainingMachines,
+					Unavailability: &mesos.Unavailability{
+						Start: &mesos.TimeInfo{Nanoseconds: &start},
+					},
+				},
+			},
+		},
+	}
+
+	suite.mockWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceStatus().
+		Return(response, nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceSchedule().
+		Return(schedule, nil)
+	suite.mockMaintenanceMap.EXPECT().
+		ClearAndFillMap(nil)
+
+	suite.NoError(suite.drainer.reconcileMaintenanceState())
+}
+
+// TestDrainerCompleteMaintenanceWindows tests that the maintenance of the
+// hosts of the closed maintenance windows is completed
+func (suite *drainerTestSuite) TestDrainerCompleteMaintenanceWindows() {
+	closedWindow := &host.MaintenanceWindow{
+		Id:           "closed",
+		Hostnames:    []string{"host2"},
+		StartTime:    time.Now().Add(-2 * time.Hour).Format(time.RFC3339),
+		DurationSecs: 3600,
+	}
+	openWindow := &host.MaintenanceWindow{
+		Id:           "open",
+		Hostnames:    []string{"host3"},
+		StartTime:    time.Now().Add(-time.Hour).Format(time.RFC3339),
+		DurationSecs: 7200,
+	}
+	var objs []*ormobjects.MaintenanceWindowObject
+	for _, window := range []*host.MaintenanceWindow{closedWindow, openWindow} {
+		buf, err := proto.Marshal(window)
+		suite.NoError(err)
+		objs = append(objs, &ormobjects.MaintenanceWindowObject{
+			WindowID: window.GetId(),
+			Window:   buf,
+		})
+	}
+
+	suite.mockWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(objs, nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceSchedule().
+		Return(&mesos_master.Response_GetMaintenanceSchedule{}, nil)
+	suite.mockMaintenanceMap.EXPECT().
+		GetDownHostInfos([]string{"host2"}).
+		Return([]*host.HostInfo{suite.hostInfos[1]})
+	suite.mockMasterOperatorClient.EXPECT().
+		StopMaintenance(suite.downMachines).
+		Return(nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		UpdateMaintenanceSchedule(&mesos_maintenance.Schedule{}).
+		Return(nil)
+	suite.mockMaintenanceMap.EXPECT().
+		RemoveHostInfos([]string{"host2"})
+	suite.mockWindowOps.EXPECT().
+		Delete(gomock.Any(), "closed").
+		Return(nil)
+
+	suite.NoError(suite.drainer.completeMaintenanceWindows())
+
+	// failure to complete a window is returned
+	suite.mockWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(objs, nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceSchedule().
+		Return(nil, fmt.Errorf("fake GetMaintenanceSchedule error"))
+
+	suite.Error(suite.drainer.completeMaintenanceWindows())
+}
+
 // TestStop tests stopping the host drainer
 func (suite *drainerTestSuite) TestStop() {
 	suite.drainer.Stop()
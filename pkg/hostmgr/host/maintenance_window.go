@@ -0,0 +1,215 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	mesos_maintenance "github.com/uber/peloton/.gen/mesos/v1/maintenance"
+	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+
+	"github.com/uber/peloton/pkg/common/stringset"
+	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
+
+	"github.com/pkg/errors"
+)
+
+// GetMaintenanceWindowTimes returns the time at which the maintenance
+// window opens and the time at which it closes
+func GetMaintenanceWindowTimes(
+	window *hpb.MaintenanceWindow,
+) (time.Time, time.Time, error) {
+	start, err := time.Parse(time.RFC3339, window.GetStartTime())
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.Wrapf(err,
+			"invalid start time of maintenance window %s", window.GetId())
+	}
+	end := start.Add(time.Duration(window.GetDurationSecs()) * time.Second)
+	return start, end, nil
+}
+
+// GetMaintenanceWindowState returns the state of the maintenance
+// window at the given time
+func GetMaintenanceWindowState(
+	window *hpb.MaintenanceWindow,
+	now time.Time,
+) (hpb.MaintenanceWindowState, error) {
+	start, _, err := GetMaintenanceWindowTimes(window)
+	if err != nil {
+		return hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_INVALID, err
+	}
+	if now.Before(start) {
+		return hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED, nil
+	}
+	return hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_ACTIVE, nil
+}
+
+// GetScheduledHosts returns the hosts of the Mesos maintenance schedule
+// whose maintenance windows have not opened yet. Mesos reports those hosts
+// as draining as soon as they are in the schedule, but they must only be
+// drained once one of their windows opens. Each window is evaluated on
+// its own, so a host with an opened window and a later window is not
+// returned.
+func GetScheduledHosts(
+	schedule *mesos_maintenance.Schedule,
+	now time.Time,
+) stringset.StringSet {
+	scheduledHosts := stringset.New()
+	openedHosts := getOpenedHosts(schedule, now)
+	for _, window := range schedule.GetWindows() {
+		if isWindowOpened(window, now) {
+			continue
+		}
+		for _, machineID := range window.GetMachineIds() {
+			if !openedHosts.Contains(machineID.GetHostname()) {
+				scheduledHosts.Add(machineID.GetHostname())
+			}
+		}
+	}
+	return scheduledHosts
+}
+
+// getOpenedHosts returns the hosts of the Mesos maintenance
+// schedule which have a maintenance window which has opened
+func getOpenedHosts(
+	schedule *mesos_maintenance.Schedule,
+	now time.Time,
+) stringset.StringSet {
+	hosts := stringset.New()
+	for _, window := range schedule.GetWindows() {
+		if !isWindowOpened(window, now) {
+			continue
+		}
+		for _, machineID := range window.GetMachineIds() {
+			hosts.Add(machineID.GetHostname())
+		}
+	}
+	return hosts
+}
+
+// isWindowOpened returns whether the Mesos maintenance window has opened
+func isWindowOpened(window *mesos_maintenance.Window, now time.Time) bool {
+	return window.GetUnavailability().GetStart().GetNanoseconds() <= now.UnixNano()
+}
+
+// isMaintenanceWindow returns whether the Mesos maintenance window
+// was posted for the maintenance window opening at start and
+// closing at end
+func isMaintenanceWindow(
+	window *mesos_maintenance.Window,
+	start time.Time,
+	end time.Time,
+) bool {
+	unavailability := window.GetUnavailability()
+	return unavailability.GetStart().GetNanoseconds() == start.UnixNano() &&
+		unavailability.GetDuration().GetNanoseconds() == end.Sub(start).Nanoseconds()
+}
+
+// EndMaintenance brings the hosts of a maintenance window back up at the
+// end of the window. The hosts are removed only from the Mesos window of
+// the maintenance window, which stops their drain, and the hosts which are
+// DOWN are brought up unless another of their windows has opened.
+func EndMaintenance(
+	operatorClient mpb.MasterOperatorClient,
+	hostInfoMap MaintenanceHostInfoMap,
+	maintenanceWindow *hpb.MaintenanceWindow,
+) error {
+	hostnames := maintenanceWindow.GetHostnames()
+	if len(hostnames) == 0 {
+		return nil
+	}
+
+	start, end, err := GetMaintenanceWindowTimes(maintenanceWindow)
+	if err != nil {
+		return err
+	}
+
+	response, err := operatorClient.GetMaintenanceSchedule()
+	if err != nil {
+		return err
+	}
+
+	hostSet := stringset.New()
+	for _, hostname := range hostnames {
+		hostSet.Add(hostname)
+	}
+
+	schedule := response.GetSchedule()
+	if schedule == nil {
+		schedule = &mesos_maintenance.Schedule{}
+	}
+	var windows []*mesos_maintenance.Window
+	removed := false
+	for _, window := range schedule.GetWindows() {
+		if !isMaintenanceWindow(window, start, end) {
+			windows = append(windows, window)
+			continue
+		}
+
+		var machineIDs []*mesos.MachineID
+		for _, machineID := range window.GetMachineIds() {
+			if hostSet.Contains(machineID.GetHostname()) {
+				removed = true
+				continue
+			}
+			machineIDs = append(machineIDs, machineID)
+		}
+		if len(machineIDs) == 0 {
+			continue
+		}
+		window.MachineIds = machineIDs
+		windows = append(windows, window)
+	}
+	schedule.Windows = windows
+
+	// hosts which remain in maintenance for another window
+	openedHosts := getOpenedHosts(schedule, time.Now())
+
+	var downMachines []*mesos.MachineID
+	for _, hostInfo := range hostInfoMap.GetDownHostInfos(hostnames) {
+		hostname := hostInfo.GetHostname()
+		if openedHosts.Contains(hostname) {
+			continue
+		}
+		ip := hostInfo.GetIp()
+		downMachines = append(downMachines, &mesos.MachineID{
+			Hostname: &hostname,
+			Ip:       &ip,
+		})
+	}
+	if len(downMachines) != 0 {
+		if err := operatorClient.StopMaintenance(downMachines); err != nil {
+			return err
+		}
+	}
+
+	// Mesos removes the machines brought up from all the windows of the
+	// schedule, so the schedule is updated to keep their other windows
+	if removed || len(downMachines) != 0 {
+		if err := operatorClient.UpdateMaintenanceSchedule(schedule); err != nil {
+			return err
+		}
+	}
+
+	var endedHosts []string
+	for _, hostname := range hostnames {
+		if !openedHosts.Contains(hostname) {
+			endedHosts = append(endedHosts, hostname)
+		}
+	}
+	hostInfoMap.RemoveHostInfos(endedHosts)
+	return nil
+}
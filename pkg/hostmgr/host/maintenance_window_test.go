@@ -0,0 +1,151 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"testing"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	mesos_maintenance "github.com/uber/peloton/.gen/mesos/v1/maintenance"
+	mesos_master "github.com/uber/peloton/.gen/mesos/v1/master"
+	host "github.com/uber/peloton/.gen/peloton/api/v0/host"
+
+	host_mocks "github.com/uber/peloton/pkg/hostmgr/host/mocks"
+	mpb_mocks "github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type maintenanceWindowTestSuite struct {
+	suite.Suite
+	mockCtrl                 *gomock.Controller
+	mockMasterOperatorClient *mpb_mocks.MockMasterOperatorClient
+	mockMaintenanceMap       *host_mocks.MockMaintenanceHostInfoMap
+}
+
+func (suite *maintenanceWindowTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockMasterOperatorClient = mpb_mocks.NewMockMasterOperatorClient(suite.mockCtrl)
+	suite.mockMaintenanceMap = host_mocks.NewMockMaintenanceHostInfoMap(suite.mockCtrl)
+}
+
+func (suite *maintenanceWindowTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestMaintenanceWindow(t *testing.T) {
+	suite.Run(t, new(maintenanceWindowTestSuite))
+}
+
+// machineID returns the machine ID of a host
+func machineID(hostname string, ip string) *mesos.MachineID {
+	return &mesos.MachineID{Hostname: &hostname, Ip: &ip}
+}
+
+// mesosWindow returns a Mesos maintenance window of the machines
+func mesosWindow(
+	start time.Time,
+	duration time.Duration,
+	machineIDs ...*mesos.MachineID,
+) *mesos_maintenance.Window {
+	startNanos := start.UnixNano()
+	durationNanos := duration.Nanoseconds()
+	return &mesos_maintenance.Window{
+		MachineIds: machineIDs,
+		Unavailability: &mesos.Unavailability{
+			Start:    &mesos.TimeInfo{Nanoseconds: &startNanos},
+			Duration: &mesos.DurationInfo{Nanoseconds: &durationNanos},
+		},
+	}
+}
+
+// TestGetScheduledHosts tests that only the hosts without an opened
+// window are scheduled, including the hosts which also have a later window
+func (suite *maintenanceWindowTestSuite) TestGetScheduledHosts() {
+	now := time.Now()
+	schedule := &mesos_maintenance.Schedule{
+		Windows: []*mesos_maintenance.Window{
+			mesosWindow(now.Add(-time.Hour), 2*time.Hour,
+				machineID("host1", "172.17.0.5")),
+			mesosWindow(now.Add(time.Hour), time.Hour,
+				machineID("host1", "172.17.0.5"),
+				machineID("host2", "172.17.0.6")),
+		},
+	}
+
+	scheduledHosts := GetScheduledHosts(schedule, now)
+	suite.Equal([]string{"host2"}, scheduledHosts.ToSlice())
+}
+
+// TestEndMaintenance tests that the hosts are removed only from the
+// Mesos window of the maintenance window, and that the hosts which
+// have another opened window are not brought up
+func (suite *maintenanceWindowTestSuite) TestEndMaintenance() {
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	window := &host.MaintenanceWindow{
+		Id:           "closed",
+		Hostnames:    []string{"host1", "host2"},
+		StartTime:    start.UTC().Format(time.RFC3339),
+		DurationSecs: 3600,
+	}
+
+	host1 := machineID("host1", "172.17.0.5")
+	host2 := machineID("host2", "172.17.0.6")
+	host3 := machineID("host3", "172.17.0.7")
+	// host2 remains in maintenance for an overlapping window
+	openedWindow := mesosWindow(start.Add(time.Minute), 3*time.Hour, host2)
+	// host1 keeps its later window
+	laterWindow := mesosWindow(time.Now().Add(time.Hour), time.Hour, host1)
+
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceSchedule().
+		Return(&mesos_master.Response_GetMaintenanceSchedule{
+			Schedule: &mesos_maintenance.Schedule{
+				Windows: []*mesos_maintenance.Window{
+					mesosWindow(start, time.Hour, host1, host2, host3),
+					openedWindow,
+					laterWindow,
+				},
+			},
+		}, nil)
+	suite.mockMaintenanceMap.EXPECT().
+		GetDownHostInfos([]string{"host1", "host2"}).
+		Return([]*host.HostInfo{
+			{Hostname: "host1", Ip: "172.17.0.5", State: host.HostState_HOST_STATE_DOWN},
+			{Hostname: "host2", Ip: "172.17.0.6", State: host.HostState_HOST_STATE_DOWN},
+		})
+	suite.mockMasterOperatorClient.EXPECT().
+		StopMaintenance([]*mesos.MachineID{host1}).
+		Return(nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		UpdateMaintenanceSchedule(gomock.Any()).
+		Do(func(schedule *mesos_maintenance.Schedule) {
+			suite.Equal([]*mesos_maintenance.Window{
+				mesosWindow(start, time.Hour, host3),
+				openedWindow,
+				laterWindow,
+			}, schedule.GetWindows())
+		}).
+		Return(nil)
+	suite.mockMaintenanceMap.EXPECT().
+		RemoveHostInfos([]string{"host1"})
+
+	suite.NoError(EndMaintenance(
+		suite.mockMasterOperatorClient,
+		suite.mockMaintenanceMap,
+		window))
+}
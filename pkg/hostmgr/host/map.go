@@ -21,6 +21,7 @@ import (
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	mesos_master "github.com/uber/peloton/.gen/mesos/v1/master"
 	host "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
@@ -66,6 +67,22 @@ func GetAgentInfo(hostname string) *mesos.AgentInfo {
 	return m.RegisteredAgents[hostname].GetAgentInfo()
 }
 
+// GetHostnameByAgentID returns the hostname of the registered agent with
+// the given agent id, or an empty string if the agent is not registered.
+func GetHostnameByAgentID(agentID string) string {
+	m := GetAgentMap()
+	if m == nil {
+		return ""
+	}
+
+	for hostname, agent := range m.RegisteredAgents {
+		if agent.GetAgentInfo().GetId().GetValue() == agentID {
+			return hostname
+		}
+	}
+	return ""
+}
+
 // GetAgentMap returns a full map of all registered agents. Note that caller
 // should not mutable the content since it's not protected by any lock.
 func GetAgentMap() *AgentMap {
@@ -175,6 +192,10 @@ type MaintenanceHostInfoMap interface {
 	// UpdateHostState updates the HostInfo.HostState of the specified
 	// host from 'from' state to 'to' state.
 	UpdateHostState(hostname string, from host.HostState, to host.HostState) error
+	// SetBlockingJobs sets the jobs blocking the drain of the specified
+	// DRAINING host because of their SLA. The blocking jobs are reset once
+	// the host leaves the DRAINING state.
+	SetBlockingJobs(hostname string, jobIDs []*peloton.JobID) error
 	// ClearAndFillMap clears the content of the
 	// map and fills the map with the given host infos
 	ClearAndFillMap(hostInfos []*host.HostInfo)
@@ -273,6 +294,7 @@ func (m *maintenanceHostInfoMap) RemoveHostInfos(hosts []string) {
 
 	m.metrics.DrainingHosts.Update(float64(len(m.drainingHosts)))
 	m.metrics.DownHosts.Update(float64(len(m.downHosts)))
+	m.updateBlockedHosts()
 }
 
 // UpdateHostState updates the HostInfo.HostState of the specified
@@ -316,6 +338,8 @@ func (m *maintenanceHostInfoMap) UpdateHostState(
 	}
 
 	hostInfo.State = to
+	// only a DRAINING host can be blocked
+	hostInfo.BlockingJobs = nil
 	switch to {
 	case host.HostState_HOST_STATE_DRAINING:
 		m.drainingHosts[hostname] = hostInfo
@@ -325,17 +349,39 @@ func (m *maintenanceHostInfoMap) UpdateHostState(
 
 	m.metrics.DrainingHosts.Update(float64(len(m.drainingHosts)))
 	m.metrics.DownHosts.Update(float64(len(m.downHosts)))
+	m.updateBlockedHosts()
 	return nil
 }
 
-func (m *maintenanceHostInfoMap) ClearAndFillMap(hostInfos []*host.HostInfo) {
+// SetBlockingJobs sets the jobs blocking the drain of the specified
+// DRAINING host
+func (m *maintenanceHostInfoMap) SetBlockingJobs(
+	hostname string,
+	jobIDs []*peloton.JobID) error {
+
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	for hostname := range m.drainingHosts {
-		delete(m.drainingHosts, hostname)
+	hostInfo, ok := m.drainingHosts[hostname]
+	if !ok {
+		return yarpcerrors.InvalidArgumentErrorf("host not in expected state")
 	}
 
+	// the host infos are shared with the callers of the map,
+	// so the host info is replaced instead of being modified
+	m.drainingHosts[hostname] = withBlockingJobs(hostInfo, jobIDs)
+	m.updateBlockedHosts()
+	return nil
+}
+
+func (m *maintenanceHostInfoMap) ClearAndFillMap(hostInfos []*host.HostInfo) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// the blocking jobs are kept for the hosts which are still DRAINING
+	drainingHosts := m.drainingHosts
+	m.drainingHosts = make(map[string]*host.HostInfo)
+
 	for hostname := range m.downHosts {
 		delete(m.downHosts, hostname)
 	}
@@ -343,6 +389,11 @@ func (m *maintenanceHostInfoMap) ClearAndFillMap(hostInfos []*host.HostInfo) {
 	for _, hostInfo := range hostInfos {
 		switch hostInfo.State {
 		case host.HostState_HOST_STATE_DRAINING:
+			if drainingHost, ok := drainingHosts[hostInfo.GetHostname()]; ok &&
+				len(drainingHost.GetBlockingJobs()) != 0 {
+				hostInfo = withBlockingJobs(
+					hostInfo, drainingHost.GetBlockingJobs())
+			}
 			m.drainingHosts[hostInfo.GetHostname()] = hostInfo
 		case host.HostState_HOST_STATE_DOWN:
 			m.downHosts[hostInfo.GetHostname()] = hostInfo
@@ -351,4 +402,30 @@ func (m *maintenanceHostInfoMap) ClearAndFillMap(hostInfos []*host.HostInfo) {
 
 	m.metrics.DrainingHosts.Update(float64(len(m.drainingHosts)))
 	m.metrics.DownHosts.Update(float64(len(m.downHosts)))
+	m.updateBlockedHosts()
+}
+
+// updateBlockedHosts updates the gauge of the DRAINING hosts
+// which are blocked. It must be called with the lock held.
+func (m *maintenanceHostInfoMap) updateBlockedHosts() {
+	var blocked int
+	for _, hostInfo := range m.drainingHosts {
+		if len(hostInfo.GetBlockingJobs()) != 0 {
+			blocked++
+		}
+	}
+	m.metrics.BlockedHosts.Update(float64(blocked))
+}
+
+// withBlockingJobs returns a copy of the host info with the blocking jobs
+func withBlockingJobs(
+	hostInfo *host.HostInfo,
+	jobIDs []*peloton.JobID) *host.HostInfo {
+	return &host.HostInfo{
+		Hostname:         hostInfo.GetHostname(),
+		Ip:               hostInfo.GetIp(),
+		State:            hostInfo.GetState(),
+		Unavailabilities: hostInfo.GetUnavailabilities(),
+		BlockingJobs:     jobIDs,
+	}
 }
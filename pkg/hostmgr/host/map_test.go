@@ -22,6 +22,7 @@ import (
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	mesos_master "github.com/uber/peloton/.gen/mesos/v1/master"
 	host "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/util"
@@ -88,6 +89,7 @@ func makeAgentsResponse(numAgents int) *mesos_master.Response_GetAgents {
 		}
 		getAgent := &mesos_master.Response_GetAgents_Agent{
 			AgentInfo: &mesos.AgentInfo{
+				Id:        &mesos.AgentID{Value: &tmpID},
 				Hostname:  &tmpID,
 				Resources: resources,
 			},
@@ -147,6 +149,8 @@ func (suite *HostMapTestSuite) TestRefresh() {
 	id2 := "id-20000"
 	a2 := GetAgentInfo(id2)
 	suite.Nil(a2)
+	suite.Equal(id1, GetHostnameByAgentID(id1))
+	suite.Empty(GetHostnameByAgentID(id2))
 
 	gauges := suite.testScope.Snapshot().Gauges()
 	suite.Contains(gauges, "registered_hosts+")
@@ -301,6 +305,57 @@ func (suite *HostMapTestSuite) TestMaintenanceHostInfoMap() {
 	suite.Empty(maintenanceHostInfoMap.GetDrainingHostInfos([]string{}))
 }
 
+// TestMaintenanceHostInfoMapBlockingJobs tests that the blocking jobs of a
+// DRAINING host are kept until the host leaves the DRAINING state
+func (suite *HostMapTestSuite) TestMaintenanceHostInfoMapBlockingJobs() {
+	maintenanceHostInfoMap := NewMaintenanceHostInfoMap(tally.NoopScope)
+	blockingJobs := []*peloton.JobID{{Value: "job1"}}
+
+	drainingHostInfo := &host.HostInfo{
+		Hostname: "host1",
+		Ip:       "0.0.0.0",
+		State:    host.HostState_HOST_STATE_DRAINING,
+	}
+	maintenanceHostInfoMap.AddHostInfos([]*host.HostInfo{drainingHostInfo})
+
+	// only a DRAINING host can be blocked
+	suite.Error(maintenanceHostInfoMap.SetBlockingJobs("host2", blockingJobs))
+
+	suite.NoError(maintenanceHostInfoMap.SetBlockingJobs("host1", blockingJobs))
+	hostInfos := maintenanceHostInfoMap.GetDrainingHostInfos([]string{"host1"})
+	suite.Len(hostInfos, 1)
+	suite.Equal(blockingJobs, hostInfos[0].GetBlockingJobs())
+	// the host info of the caller is not modified
+	suite.Empty(drainingHostInfo.GetBlockingJobs())
+
+	// the blocking jobs are kept while the host is DRAINING
+	maintenanceHostInfoMap.ClearAndFillMap([]*host.HostInfo{
+		{
+			Hostname: "host1",
+			Ip:       "0.0.0.0",
+			State:    host.HostState_HOST_STATE_DRAINING,
+		},
+	})
+	hostInfos = maintenanceHostInfoMap.GetDrainingHostInfos([]string{"host1"})
+	suite.Len(hostInfos, 1)
+	suite.Equal(blockingJobs, hostInfos[0].GetBlockingJobs())
+
+	// the host is not blocked anymore
+	suite.NoError(maintenanceHostInfoMap.SetBlockingJobs("host1", nil))
+	hostInfos = maintenanceHostInfoMap.GetDrainingHostInfos([]string{"host1"})
+	suite.Empty(hostInfos[0].GetBlockingJobs())
+
+	// the blocking jobs are reset once the host is DOWN
+	suite.NoError(maintenanceHostInfoMap.SetBlockingJobs("host1", blockingJobs))
+	suite.NoError(maintenanceHostInfoMap.UpdateHostState(
+		"host1",
+		host.HostState_HOST_STATE_DRAINING,
+		host.HostState_HOST_STATE_DOWN))
+	hostInfos = maintenanceHostInfoMap.GetDownHostInfos([]string{"host1"})
+	suite.Len(hostInfos, 1)
+	suite.Empty(hostInfos[0].GetBlockingJobs())
+}
+
 func TestHostMapTestSuite(t *testing.T) {
 	suite.Run(t, new(HostMapTestSuite))
 }
@@ -24,6 +24,7 @@ type Metrics struct {
 
 	DrainingHosts tally.Gauge
 	DownHosts     tally.Gauge
+	BlockedHosts  tally.Gauge
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics
@@ -34,5 +35,6 @@ func NewMetrics(scope tally.Scope) *Metrics {
 
 		DrainingHosts: scope.Gauge("draining_hosts"),
 		DownHosts:     scope.Gauge("down_hosts"),
+		BlockedHosts:  scope.Gauge("blocked_hosts"),
 	}
 }
@@ -17,6 +17,7 @@ package hostsvc
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
@@ -28,8 +29,11 @@ import (
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/hostmgr/host"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
+	"github.com/uber/peloton/pkg/hostmgr/offer/offerpool"
 	"github.com/uber/peloton/pkg/hostmgr/queue"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
+	"github.com/pborman/uuid"
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
 	"go.uber.org/yarpc"
@@ -41,6 +45,8 @@ type serviceHandler struct {
 	metrics                *Metrics
 	operatorMasterClient   mpb.MasterOperatorClient
 	maintenanceHostInfoMap host.MaintenanceHostInfoMap
+	maintenanceWindowOps   ormobjects.MaintenanceWindowOps
+	offerPool              offerpool.Pool
 }
 
 // InitServiceHandler initializes the HostService
@@ -49,12 +55,16 @@ func InitServiceHandler(
 	parent tally.Scope,
 	operatorMasterClient mpb.MasterOperatorClient,
 	maintenanceQueue queue.MaintenanceQueue,
-	hostInfoMap host.MaintenanceHostInfoMap) {
+	hostInfoMap host.MaintenanceHostInfoMap,
+	maintenanceWindowOps ormobjects.MaintenanceWindowOps,
+	offerPool offerpool.Pool) {
 	handler := &serviceHandler{
 		maintenanceQueue:       maintenanceQueue,
 		metrics:                NewMetrics(parent.SubScope("hostsvc")),
 		operatorMasterClient:   operatorMasterClient,
 		maintenanceHostInfoMap: hostInfoMap,
+		maintenanceWindowOps:   maintenanceWindowOps,
+		offerPool:              offerPool,
 	}
 	d.Register(host_svc.BuildHostServiceYARPCProcedures(handler))
 	log.Info("Hostsvc handler initialized")
@@ -121,10 +131,59 @@ func (m *serviceHandler) QueryHosts(
 
 	m.metrics.QueryHostsSuccess.Inc(1)
 	return &host_svc.QueryHostsResponse{
-		HostInfos: hostInfos,
+		HostInfos: m.addHostUnavailabilities(hostInfos),
 	}, nil
 }
 
+// addHostUnavailabilities returns the host infos with the unavailabilities
+// of the hosts which have pending inverse offers in the offer pool
+func (m *serviceHandler) addHostUnavailabilities(
+	hostInfos []*hpb.HostInfo) []*hpb.HostInfo {
+	hostSummaries, err := m.offerPool.GetHostSummaries(nil)
+	if err != nil {
+		log.WithError(err).Warn("failed to get host summaries")
+		return hostInfos
+	}
+
+	result := make([]*hpb.HostInfo, 0, len(hostInfos))
+	for _, hostInfo := range hostInfos {
+		hostSummary, ok := hostSummaries[hostInfo.GetHostname()]
+		if !ok || len(hostSummary.GetInverseOffers()) == 0 {
+			result = append(result, hostInfo)
+			continue
+		}
+
+		// the host infos of the maintenance map are shared,
+		// so return a copy with the unavailabilities
+		hostInfoWithUnavailabilities := &hpb.HostInfo{
+			Hostname:     hostInfo.GetHostname(),
+			Ip:           hostInfo.GetIp(),
+			State:        hostInfo.GetState(),
+			BlockingJobs: hostInfo.GetBlockingJobs(),
+		}
+		for _, inverseOffer := range hostSummary.GetInverseOffers() {
+			unavailability := inverseOffer.GetUnavailability()
+			hostInfoWithUnavailabilities.Unavailabilities = append(
+				hostInfoWithUnavailabilities.Unavailabilities,
+				&hpb.HostUnavailability{
+					StartTime: time.Unix(
+						0,
+						unavailability.GetStart().GetNanoseconds(),
+					).UTC().Format(time.RFC3339),
+					DurationSecs: uint32(time.Duration(
+						unavailability.GetDuration().GetNanoseconds(),
+					).Seconds()),
+				})
+		}
+		sort.Slice(hostInfoWithUnavailabilities.Unavailabilities, func(i, j int) bool {
+			return hostInfoWithUnavailabilities.Unavailabilities[i].GetStartTime() <
+				hostInfoWithUnavailabilities.Unavailabilities[j].GetStartTime()
+		})
+		result = append(result, hostInfoWithUnavailabilities)
+	}
+	return result
+}
+
 // StartMaintenance puts the host(s) into DRAINING state by posting a maintenance
 // schedule to Mesos Master. Inverse offers are sent out and all future offers
 // from the(se) host(s) are tagged with unavailability (Please check Mesos
@@ -240,6 +299,204 @@ func (m *serviceHandler) CompleteMaintenance(
 	return &host_svc.CompleteMaintenanceResponse{}, nil
 }
 
+// ScheduleMaintenance schedules a maintenance window for the specified hosts.
+// The window is posted to the Mesos Master maintenance schedule, so the
+// offers from the hosts are tagged with the unavailability of the window and
+// no long running tasks are placed on them. The hosts are drained once the
+// window opens, and brought back UP once it closes.
+func (m *serviceHandler) ScheduleMaintenance(
+	ctx context.Context,
+	request *host_svc.ScheduleMaintenanceRequest,
+) (*host_svc.ScheduleMaintenanceResponse, error) {
+	m.metrics.ScheduleMaintenanceAPI.Inc(1)
+
+	if len(request.GetHostnames()) == 0 {
+		m.metrics.ScheduleMaintenanceFail.Inc(1)
+		return nil, fmt.Errorf("invalid request. No hosts specified")
+	}
+	start, err := time.Parse(time.RFC3339, request.GetStartTime())
+	if err != nil {
+		m.metrics.ScheduleMaintenanceFail.Inc(1)
+		return nil, fmt.Errorf(
+			"invalid request. Invalid start time %s", request.GetStartTime())
+	}
+	if !start.After(time.Now()) {
+		m.metrics.ScheduleMaintenanceFail.Inc(1)
+		return nil, fmt.Errorf("invalid request. Start time is in the past")
+	}
+	if request.GetDurationSecs() == 0 {
+		m.metrics.ScheduleMaintenanceFail.Inc(1)
+		return nil, fmt.Errorf("invalid request. Duration must be positive")
+	}
+
+	machineIds, err := buildMachineIDsForHosts(request.GetHostnames())
+	if err != nil {
+		m.metrics.ScheduleMaintenanceFail.Inc(1)
+		return nil, err
+	}
+
+	window := &hpb.MaintenanceWindow{
+		Id:           uuid.New(),
+		Hostnames:    request.GetHostnames(),
+		StartTime:    start.UTC().Format(time.RFC3339),
+		DurationSecs: request.GetDurationSecs(),
+	}
+	if err := m.maintenanceWindowOps.Create(ctx, window); err != nil {
+		m.metrics.ScheduleMaintenanceFail.Inc(1)
+		return nil, err
+	}
+
+	if err := m.postMaintenanceWindow(machineIds, start, window); err != nil {
+		m.metrics.ScheduleMaintenanceFail.Inc(1)
+		if err := m.maintenanceWindowOps.Delete(ctx, window.GetId()); err != nil {
+			log.WithError(err).
+				WithField("maintenance_window", window).
+				Error("Failed to delete maintenance window")
+		}
+		return nil, err
+	}
+	log.WithField("maintenance_window", window).
+		Info("Maintenance window scheduled")
+
+	window.State = hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED
+	m.metrics.ScheduleMaintenanceSuccess.Inc(1)
+	return &host_svc.ScheduleMaintenanceResponse{
+		Window: window,
+	}, nil
+}
+
+// postMaintenanceWindow adds the maintenance window of the
+// machines to the maintenance schedule of Mesos Master
+func (m *serviceHandler) postMaintenanceWindow(
+	machineIds []*mesos.MachineID,
+	start time.Time,
+	window *hpb.MaintenanceWindow,
+) error {
+	response, err := m.operatorMasterClient.GetMaintenanceSchedule()
+	if err != nil {
+		return err
+	}
+	schedule := response.GetSchedule()
+	if schedule == nil {
+		schedule = &mesos_maintenance.Schedule{}
+	}
+
+	startNanos := start.UnixNano()
+	durationNanos := (time.Duration(window.GetDurationSecs()) * time.Second).Nanoseconds()
+	schedule.Windows = append(schedule.Windows, &mesos_maintenance.Window{
+		MachineIds: machineIds,
+		Unavailability: &mesos.Unavailability{
+			Start: &mesos.TimeInfo{
+				Nanoseconds: &startNanos,
+			},
+			Duration: &mesos.DurationInfo{
+				Nanoseconds: &durationNanos,
+			},
+		},
+	})
+	return m.operatorMasterClient.UpdateMaintenanceSchedule(schedule)
+}
+
+// QueryMaintenanceWindows returns the maintenance windows which are
+// scheduled or active, sorted on their start time
+func (m *serviceHandler) QueryMaintenanceWindows(
+	ctx context.Context,
+	request *host_svc.QueryMaintenanceWindowsRequest,
+) (*host_svc.QueryMaintenanceWindowsResponse, error) {
+	m.metrics.QueryMaintenanceWindowsAPI.Inc(1)
+
+	windows, err := m.getMaintenanceWindows(ctx)
+	if err != nil {
+		m.metrics.QueryMaintenanceWindowsFail.Inc(1)
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, window := range windows {
+		state, err := host.GetMaintenanceWindowState(window, now)
+		if err != nil {
+			m.metrics.QueryMaintenanceWindowsFail.Inc(1)
+			return nil, err
+		}
+		window.State = state
+	}
+	// RFC3339 times in UTC sort lexicographically
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].GetStartTime() < windows[j].GetStartTime()
+	})
+
+	m.metrics.QueryMaintenanceWindowsSuccess.Inc(1)
+	return &host_svc.QueryMaintenanceWindowsResponse{
+		Windows: windows,
+	}, nil
+}
+
+// CancelMaintenanceWindow cancels a maintenance window. The hosts of the
+// window are removed from the Mesos Master maintenance schedule, and
+// brought back UP if the window is active.
+func (m *serviceHandler) CancelMaintenanceWindow(
+	ctx context.Context,
+	request *host_svc.CancelMaintenanceWindowRequest,
+) (*host_svc.CancelMaintenanceWindowResponse, error) {
+	m.metrics.CancelMaintenanceWindowAPI.Inc(1)
+
+	windows, err := m.getMaintenanceWindows(ctx)
+	if err != nil {
+		m.metrics.CancelMaintenanceWindowFail.Inc(1)
+		return nil, err
+	}
+
+	var window *hpb.MaintenanceWindow
+	for _, w := range windows {
+		if w.GetId() == request.GetId() {
+			window = w
+			break
+		}
+	}
+	if window == nil {
+		m.metrics.CancelMaintenanceWindowFail.Inc(1)
+		return nil, fmt.Errorf(
+			"invalid request. Maintenance window %s not found", request.GetId())
+	}
+
+	if err := host.EndMaintenance(
+		m.operatorMasterClient,
+		m.maintenanceHostInfoMap,
+		window); err != nil {
+		m.metrics.CancelMaintenanceWindowFail.Inc(1)
+		return nil, err
+	}
+	if err := m.maintenanceWindowOps.Delete(ctx, window.GetId()); err != nil {
+		m.metrics.CancelMaintenanceWindowFail.Inc(1)
+		return nil, err
+	}
+	log.WithField("maintenance_window", window).
+		Info("Maintenance window cancelled")
+
+	m.metrics.CancelMaintenanceWindowSuccess.Inc(1)
+	return &host_svc.CancelMaintenanceWindowResponse{}, nil
+}
+
+// getMaintenanceWindows returns all the maintenance windows from the db
+func (m *serviceHandler) getMaintenanceWindows(
+	ctx context.Context,
+) ([]*hpb.MaintenanceWindow, error) {
+	objs, err := m.maintenanceWindowOps.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []*hpb.MaintenanceWindow
+	for _, obj := range objs {
+		window, err := obj.GetWindow()
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
 // Build host info for registered agents
 func buildHostInfoForRegisteredAgents() (map[string]*hpb.HostInfo, error) {
 	agentMap := host.GetAgentMap()
@@ -18,19 +18,27 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	mesosmaintenance "github.com/uber/peloton/.gen/mesos/v1/maintenance"
 	mesosmaster "github.com/uber/peloton/.gen/mesos/v1/master"
 	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
 	"github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 
 	"github.com/uber/peloton/pkg/common/stringset"
 	"github.com/uber/peloton/pkg/hostmgr/host"
 	hm "github.com/uber/peloton/pkg/hostmgr/host/mocks"
 	ym "github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb/mocks"
+	om "github.com/uber/peloton/pkg/hostmgr/offer/offerpool/mocks"
 	qm "github.com/uber/peloton/pkg/hostmgr/queue/mocks"
+	"github.com/uber/peloton/pkg/hostmgr/summary"
+	summary_mocks "github.com/uber/peloton/pkg/hostmgr/summary/mocks"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
@@ -50,6 +58,8 @@ type HostSvcHandlerTestSuite struct {
 	mockMasterOperatorClient *ym.MockMasterOperatorClient
 	mockMaintenanceQueue     *qm.MockMaintenanceQueue
 	mockMaintenanceMap       *hm.MockMaintenanceHostInfoMap
+	mockWindowOps            *objectmocks.MockMaintenanceWindowOps
+	mockOfferPool            *om.MockPool
 }
 
 func (suite *HostSvcHandlerTestSuite) SetupSuite() {
@@ -118,7 +128,11 @@ func (suite *HostSvcHandlerTestSuite) SetupTest() {
 	suite.mockMaintenanceMap = hm.NewMockMaintenanceHostInfoMap(suite.mockCtrl)
 	suite.handler.operatorMasterClient = suite.mockMasterOperatorClient
 	suite.handler.maintenanceQueue = suite.mockMaintenanceQueue
+	suite.mockWindowOps = objectmocks.NewMockMaintenanceWindowOps(suite.mockCtrl)
 	suite.handler.maintenanceHostInfoMap = suite.mockMaintenanceMap
+	suite.handler.maintenanceWindowOps = suite.mockWindowOps
+	suite.mockOfferPool = om.NewMockPool(suite.mockCtrl)
+	suite.handler.offerPool = suite.mockOfferPool
 
 	response := suite.makeAgentsResponse()
 	loader := &host.Loader{
@@ -418,6 +432,10 @@ func (suite *HostSvcHandlerTestSuite) TestQueryHosts() {
 		GetDownHostInfos([]string{}).
 		Return(downHostsInfos).
 		AnyTimes()
+	suite.mockOfferPool.EXPECT().
+		GetHostSummaries(nil).
+		Return(map[string]summary.HostSummary{}, nil).
+		Times(3)
 	resp, err := suite.handler.QueryHosts(suite.ctx, &svcpb.QueryHostsRequest{
 		HostStates: []hpb.HostState{
 			hpb.HostState_HOST_STATE_UP,
@@ -531,6 +549,9 @@ func (suite *HostSvcHandlerTestSuite) TestQueryHostsError() {
 			GetDownHostInfos(gomock.Any()).
 			Return([]*hpb.HostInfo{}),
 	)
+	suite.mockOfferPool.EXPECT().
+		GetHostSummaries(nil).
+		Return(map[string]summary.HostSummary{}, nil)
 
 	resp, err = suite.handler.QueryHosts(suite.ctx, &svcpb.QueryHostsRequest{
 		HostStates: []hpb.HostState{
@@ -540,3 +561,314 @@ func (suite *HostSvcHandlerTestSuite) TestQueryHostsError() {
 	suite.NoError(err)
 	suite.NotNil(resp)
 }
+
+// TestQueryHostsUnavailabilities tests that the unavailabilities of the
+// hosts with pending inverse offers are returned, along with the jobs
+// blocking their drain
+func (suite *HostSvcHandlerTestSuite) TestQueryHostsUnavailabilities() {
+	drainingHostInfo := &hpb.HostInfo{
+		Hostname:     suite.drainingMachines[0].GetHostname(),
+		Ip:           suite.drainingMachines[0].GetIp(),
+		State:        hpb.HostState_HOST_STATE_DRAINING,
+		BlockingJobs: []*peloton.JobID{{Value: "job1"}},
+	}
+	suite.mockMaintenanceMap.EXPECT().
+		GetDrainingHostInfos([]string{}).
+		Return([]*hpb.HostInfo{drainingHostInfo})
+	suite.mockMaintenanceMap.EXPECT().
+		GetDownHostInfos([]string{}).
+		Return([]*hpb.HostInfo{})
+
+	start := time.Date(2019, 6, 1, 2, 0, 0, 0, time.UTC)
+	startNanos := start.UnixNano()
+	durationNanos := (2 * time.Hour).Nanoseconds()
+	inverseOfferID := "inverse-offer-1"
+	hostSummary := summary_mocks.NewMockHostSummary(suite.mockCtrl)
+	hostSummary.EXPECT().GetInverseOffers().
+		Return(map[string]*mesos.InverseOffer{
+			inverseOfferID: {
+				Id: &mesos.OfferID{Value: &inverseOfferID},
+				Unavailability: &mesos.Unavailability{
+					Start:    &mesos.TimeInfo{Nanoseconds: &startNanos},
+					Duration: &mesos.DurationInfo{Nanoseconds: &durationNanos},
+				},
+			},
+		}).
+		AnyTimes()
+	suite.mockOfferPool.EXPECT().
+		GetHostSummaries(nil).
+		Return(map[string]summary.HostSummary{
+			drainingHostInfo.GetHostname(): hostSummary,
+		}, nil)
+
+	resp, err := suite.handler.QueryHosts(suite.ctx, &svcpb.QueryHostsRequest{
+		HostStates: []hpb.HostState{
+			hpb.HostState_HOST_STATE_DRAINING,
+		},
+	})
+	suite.NoError(err)
+	suite.Len(resp.GetHostInfos(), 1)
+	suite.Equal(drainingHostInfo.GetHostname(), resp.GetHostInfos()[0].GetHostname())
+	suite.Equal([]*hpb.HostUnavailability{
+		{
+			StartTime:    "2019-06-01T02:00:00Z",
+			DurationSecs: 7200,
+		},
+	}, resp.GetHostInfos()[0].GetUnavailabilities())
+	suite.Equal(
+		drainingHostInfo.GetBlockingJobs(),
+		resp.GetHostInfos()[0].GetBlockingJobs())
+	// the host info of the maintenance map is not modified
+	suite.Empty(drainingHostInfo.GetUnavailabilities())
+}
+
+// makeWindowObjects returns the db objects of the maintenance windows
+func (suite *HostSvcHandlerTestSuite) makeWindowObjects(
+	windows ...*hpb.MaintenanceWindow,
+) []*ormobjects.MaintenanceWindowObject {
+	var objs []*ormobjects.MaintenanceWindowObject
+	for _, window := range windows {
+		buf, err := proto.Marshal(window)
+		suite.NoError(err)
+		objs = append(objs, &ormobjects.MaintenanceWindowObject{
+			WindowID: window.GetId(),
+			Window:   buf,
+		})
+	}
+	return objs
+}
+
+// TestScheduleMaintenance tests scheduling a maintenance window
+func (suite *HostSvcHandlerTestSuite) TestScheduleMaintenance() {
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	hostname := suite.upMachines[0].GetHostname()
+
+	gomock.InOrder(
+		suite.mockWindowOps.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, window *hpb.MaintenanceWindow) {
+				suite.Equal([]string{hostname}, window.GetHostnames())
+				suite.Equal(start.UTC().Format(time.RFC3339), window.GetStartTime())
+				suite.Equal(uint32(3600), window.GetDurationSecs())
+			}).
+			Return(nil),
+		suite.mockMasterOperatorClient.EXPECT().
+			GetMaintenanceSchedule().
+			Return(&mesosmaster.Response_GetMaintenanceSchedule{
+				Schedule: &mesosmaintenance.Schedule{},
+			}, nil),
+		suite.mockMasterOperatorClient.EXPECT().
+			UpdateMaintenanceSchedule(gomock.Any()).
+			Do(func(schedule *mesosmaintenance.Schedule) {
+				suite.Len(schedule.GetWindows(), 1)
+				unavailability := schedule.GetWindows()[0].GetUnavailability()
+				suite.Equal(start.UnixNano(), unavailability.GetStart().GetNanoseconds())
+				suite.Equal(time.Hour.Nanoseconds(), unavailability.GetDuration().GetNanoseconds())
+			}).
+			Return(nil),
+	)
+
+	response, err := suite.handler.ScheduleMaintenance(suite.ctx,
+		&svcpb.ScheduleMaintenanceRequest{
+			Hostnames:    []string{hostname},
+			StartTime:    start.Format(time.RFC3339),
+			DurationSecs: 3600,
+		})
+	suite.NoError(err)
+	suite.NotEmpty(response.GetWindow().GetId())
+	suite.Equal(hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+		response.GetWindow().GetState())
+}
+
+// TestScheduleMaintenanceInvalidRequest tests the validation of the
+// requests to schedule a maintenance window
+func (suite *HostSvcHandlerTestSuite) TestScheduleMaintenanceInvalidRequest() {
+	hostname := suite.upMachines[0].GetHostname()
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	requests := []*svcpb.ScheduleMaintenanceRequest{
+		{StartTime: future, DurationSecs: 60},
+		{Hostnames: []string{hostname}, StartTime: "tomorrow", DurationSecs: 60},
+		{Hostnames: []string{hostname}, StartTime: past, DurationSecs: 60},
+		{Hostnames: []string{hostname}, StartTime: future},
+		{Hostnames: []string{"unknown"}, StartTime: future, DurationSecs: 60},
+	}
+	for _, request := range requests {
+		response, err := suite.handler.ScheduleMaintenance(suite.ctx, request)
+		suite.Error(err)
+		suite.Nil(response)
+	}
+}
+
+// TestScheduleMaintenanceError tests the failures to schedule
+// a maintenance window
+func (suite *HostSvcHandlerTestSuite) TestScheduleMaintenanceError() {
+	request := &svcpb.ScheduleMaintenanceRequest{
+		Hostnames:    []string{suite.upMachines[0].GetHostname()},
+		StartTime:    time.Now().Add(time.Hour).Format(time.RFC3339),
+		DurationSecs: 60,
+	}
+
+	// Test error while persisting the window
+	suite.mockWindowOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Return(fmt.Errorf("fake Create error"))
+	response, err := suite.handler.ScheduleMaintenance(suite.ctx, request)
+	suite.Error(err)
+	suite.Nil(response)
+
+	// Test error while posting the maintenance schedule, the window
+	// is deleted from the db
+	suite.mockWindowOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Return(nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceSchedule().
+		Return(&mesosmaster.Response_GetMaintenanceSchedule{
+			Schedule: &mesosmaintenance.Schedule{},
+		}, nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		UpdateMaintenanceSchedule(gomock.Any()).
+		Return(fmt.Errorf("fake UpdateMaintenanceSchedule error"))
+	suite.mockWindowOps.EXPECT().
+		Delete(gomock.Any(), gomock.Any()).
+		Return(nil)
+	response, err = suite.handler.ScheduleMaintenance(suite.ctx, request)
+	suite.Error(err)
+	suite.Nil(response)
+}
+
+// TestQueryMaintenanceWindows tests querying the maintenance windows
+func (suite *HostSvcHandlerTestSuite) TestQueryMaintenanceWindows() {
+	scheduled := &hpb.MaintenanceWindow{
+		Id:           "scheduled",
+		Hostnames:    []string{"host1"},
+		StartTime:    time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		DurationSecs: 60,
+	}
+	active := &hpb.MaintenanceWindow{
+		Id:           "active",
+		Hostnames:    []string{"host2"},
+		StartTime:    time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+		DurationSecs: 3600,
+	}
+	suite.mockWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(suite.makeWindowObjects(scheduled, active), nil)
+
+	response, err := suite.handler.QueryMaintenanceWindows(suite.ctx,
+		&svcpb.QueryMaintenanceWindowsRequest{})
+	suite.NoError(err)
+	suite.Len(response.GetWindows(), 2)
+	suite.Equal("active", response.GetWindows()[0].GetId())
+	suite.Equal(hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_ACTIVE,
+		response.GetWindows()[0].GetState())
+	suite.Equal("scheduled", response.GetWindows()[1].GetId())
+	suite.Equal(hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+		response.GetWindows()[1].GetState())
+
+	// Test error while reading the windows
+	suite.mockWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, fmt.Errorf("fake GetAll error"))
+	response, err = suite.handler.QueryMaintenanceWindows(suite.ctx,
+		&svcpb.QueryMaintenanceWindowsRequest{})
+	suite.Error(err)
+	suite.Nil(response)
+}
+
+// TestCancelMaintenanceWindow tests cancelling a maintenance window
+func (suite *HostSvcHandlerTestSuite) TestCancelMaintenanceWindow() {
+	hostname := suite.upMachines[0].GetHostname()
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	window := &hpb.MaintenanceWindow{
+		Id:           "window",
+		Hostnames:    []string{hostname},
+		StartTime:    start.UTC().Format(time.RFC3339),
+		DurationSecs: 60,
+	}
+
+	startNanos := start.UnixNano()
+	durationNanos := time.Minute.Nanoseconds()
+	laterNanos := start.Add(time.Hour).UnixNano()
+	// the window of the host scheduled later is kept
+	laterWindow := &mesosmaintenance.Window{
+		MachineIds: suite.upMachines,
+		Unavailability: &mesos.Unavailability{
+			Start:    &mesos.TimeInfo{Nanoseconds: &laterNanos},
+			Duration: &mesos.DurationInfo{Nanoseconds: &durationNanos},
+		},
+	}
+
+	suite.mockWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(suite.makeWindowObjects(window), nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceSchedule().
+		Return(&mesosmaster.Response_GetMaintenanceSchedule{
+			Schedule: &mesosmaintenance.Schedule{
+				Windows: []*mesosmaintenance.Window{
+					{
+						MachineIds: suite.upMachines,
+						Unavailability: &mesos.Unavailability{
+							Start:    &mesos.TimeInfo{Nanoseconds: &startNanos},
+							Duration: &mesos.DurationInfo{Nanoseconds: &durationNanos},
+						},
+					},
+					laterWindow,
+				},
+			},
+		}, nil)
+	suite.mockMaintenanceMap.EXPECT().
+		GetDownHostInfos([]string{hostname}).
+		Return(nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		UpdateMaintenanceSchedule(gomock.Any()).
+		Do(func(schedule *mesosmaintenance.Schedule) {
+			suite.Equal(
+				[]*mesosmaintenance.Window{laterWindow},
+				schedule.GetWindows())
+		}).
+		Return(nil)
+	suite.mockMaintenanceMap.EXPECT().
+		RemoveHostInfos([]string{hostname})
+	suite.mockWindowOps.EXPECT().
+		Delete(gomock.Any(), "window").
+		Return(nil)
+
+	_, err := suite.handler.CancelMaintenanceWindow(suite.ctx,
+		&svcpb.CancelMaintenanceWindowRequest{Id: "window"})
+	suite.NoError(err)
+}
+
+// TestCancelMaintenanceWindowError tests the failures to cancel
+// a maintenance window
+func (suite *HostSvcHandlerTestSuite) TestCancelMaintenanceWindowError() {
+	// Test unknown window
+	suite.mockWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, nil)
+	response, err := suite.handler.CancelMaintenanceWindow(suite.ctx,
+		&svcpb.CancelMaintenanceWindowRequest{Id: "unknown"})
+	suite.Error(err)
+	suite.Nil(response)
+
+	// Test error while updating the maintenance schedule
+	window := &hpb.MaintenanceWindow{
+		Id:           "window",
+		Hostnames:    []string{"host1"},
+		StartTime:    time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		DurationSecs: 60,
+	}
+	suite.mockWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(suite.makeWindowObjects(window), nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceSchedule().
+		Return(nil, fmt.Errorf("fake GetMaintenanceSchedule error"))
+	response, err = suite.handler.CancelMaintenanceWindow(suite.ctx,
+		&svcpb.CancelMaintenanceWindowRequest{Id: "window"})
+	suite.Error(err)
+	suite.Nil(response)
+}
@@ -29,6 +29,18 @@ type Metrics struct {
 	QueryHostsAPI     tally.Counter
 	QueryHostsSuccess tally.Counter
 	QueryHostsFail    tally.Counter
+
+	ScheduleMaintenanceAPI     tally.Counter
+	ScheduleMaintenanceSuccess tally.Counter
+	ScheduleMaintenanceFail    tally.Counter
+
+	QueryMaintenanceWindowsAPI     tally.Counter
+	QueryMaintenanceWindowsSuccess tally.Counter
+	QueryMaintenanceWindowsFail    tally.Counter
+
+	CancelMaintenanceWindowAPI     tally.Counter
+	CancelMaintenanceWindowSuccess tally.Counter
+	CancelMaintenanceWindowFail    tally.Counter
 }
 
 // NewMetrics returns a new instance of host.svc.Metrics
@@ -48,5 +60,17 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		QueryHostsAPI:     apiScope.Counter("query_hosts"),
 		QueryHostsSuccess: successScope.Counter("query_hosts"),
 		QueryHostsFail:    failScope.Counter("query_hosts"),
+
+		ScheduleMaintenanceAPI:     apiScope.Counter("schedule_maintenance"),
+		ScheduleMaintenanceSuccess: successScope.Counter("schedule_maintenance"),
+		ScheduleMaintenanceFail:    failScope.Counter("schedule_maintenance"),
+
+		QueryMaintenanceWindowsAPI:     apiScope.Counter("query_maintenance_windows"),
+		QueryMaintenanceWindowsSuccess: successScope.Counter("query_maintenance_windows"),
+		QueryMaintenanceWindowsFail:    failScope.Counter("query_maintenance_windows"),
+
+		CancelMaintenanceWindowAPI:     apiScope.Counter("cancel_maintenance_window"),
+		CancelMaintenanceWindowSuccess: successScope.Counter("cancel_maintenance_window"),
+		CancelMaintenanceWindowFail:    failScope.Counter("cancel_maintenance_window"),
 	}
 }
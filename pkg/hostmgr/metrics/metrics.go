@@ -59,6 +59,15 @@ type Metrics struct {
 	OfferOperationsInvalid       tally.Counter
 	OfferOperationsInvalidOffers tally.Counter
 
+	ReserveResources       tally.Counter
+	ReserveResourcesFail   tally.Counter
+	UnreserveResources     tally.Counter
+	UnreserveResourcesFail tally.Counter
+	CreateVolumes          tally.Counter
+	CreateVolumesFail      tally.Counter
+	DestroyVolumes         tally.Counter
+	DestroyVolumesFail     tally.Counter
+
 	RecoverySuccess tally.Counter
 	RecoveryFail    tally.Counter
 
@@ -68,6 +77,9 @@ type Metrics struct {
 	MarkHostsDrained     tally.Counter
 	MarkHostsDrainedFail tally.Counter
 
+	MarkHostsBlocked     tally.Counter
+	MarkHostsBlockedFail tally.Counter
+
 	scope tally.Scope
 }
 
@@ -85,6 +97,15 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		OfferOperationsInvalid:       scope.Counter("offer_operations_invalid"),
 		OfferOperationsInvalidOffers: scope.Counter("offer_operations_invalid_offers"),
 
+		ReserveResources:       scope.Counter("reserve_resources"),
+		ReserveResourcesFail:   scope.Counter("reserve_resources_fail"),
+		UnreserveResources:     scope.Counter("unreserve_resources"),
+		UnreserveResourcesFail: scope.Counter("unreserve_resources_fail"),
+		CreateVolumes:          scope.Counter("create_volumes"),
+		CreateVolumesFail:      scope.Counter("create_volumes_fail"),
+		DestroyVolumes:         scope.Counter("destroy_volumes"),
+		DestroyVolumesFail:     scope.Counter("destroy_volumes_fail"),
+
 		AcquireHostOffers:        scope.Counter("acquire_host_offers"),
 		AcquireHostOffersInvalid: scope.Counter("acquire_host_offers_invalid"),
 		AcquireHostOffersCount:   scope.Counter("acquire_host_offers_count"),
@@ -120,6 +141,9 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		MarkHostsDrained:     scope.Counter("mark_hosts_drained"),
 		MarkHostsDrainedFail: scope.Counter("mark_hosts_drained_fail"),
 
+		MarkHostsBlocked:     scope.Counter("mark_hosts_blocked"),
+		MarkHostsBlockedFail: scope.Counter("mark_hosts_blocked_fail"),
+
 		scope: scope,
 	}
 }
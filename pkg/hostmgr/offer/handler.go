@@ -26,6 +26,7 @@ import (
 	sched "github.com/uber/peloton/.gen/mesos/v1/scheduler"
 	"github.com/uber/peloton/pkg/common/background"
 	"github.com/uber/peloton/pkg/hostmgr/binpacking"
+	"github.com/uber/peloton/pkg/hostmgr/host"
 	hostmgr_mesos "github.com/uber/peloton/pkg/hostmgr/mesos"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
 	"github.com/uber/peloton/pkg/hostmgr/offer/offerpool"
@@ -44,6 +45,9 @@ const (
 
 	_poolMetricsRefresh       = "poolMetricsRefresh"
 	_poolMetricsRefreshPeriod = 10 * time.Second
+
+	_inverseOfferDeclinerName   = "inverseOfferDecliner"
+	_inverseOfferDeclinerPeriod = 30 * time.Second
 )
 
 // EventHandler defines the interface for offer event handler that is
@@ -87,7 +91,8 @@ func InitEventHandler(
 	slackResourceTypes []string,
 	ranker binpacking.Ranker,
 	binPackingRefreshIntervalSec time.Duration,
-	hostPlacingOfferStatusTimeout time.Duration) {
+	hostPlacingOfferStatusTimeout time.Duration,
+	maintenanceHostInfoMap host.MaintenanceHostInfoMap) {
 
 	if handler != nil {
 		log.Warning("Offer event handler has already been initialized")
@@ -164,6 +169,19 @@ func InitEventHandler(
 			Period: _poolMetricsRefreshPeriod,
 		},
 	)
+
+	inverseOfferDecliner := newInverseOfferDecliner(
+		pool,
+		maintenanceHostInfoMap,
+		parent.SubScope(_inverseOfferDeclinerName),
+	)
+	backgroundMgr.RegisterWorks(
+		background.Work{
+			Name:   _inverseOfferDeclinerName,
+			Func:   inverseOfferDecliner.Decline,
+			Period: _inverseOfferDeclinerPeriod,
+		},
+	)
 	//TODO: refactor OfferPruner as a background worker
 	handler = &eventHandler{
 		offerPool:   pool,
@@ -210,8 +228,8 @@ func (h *eventHandler) InverseOffers(ctx context.Context, body *sched.Event) err
 	event := body.GetInverseOffers()
 	log.WithField("event", event).
 		Debug("OfferManager: processing InverseOffers event")
+	h.offerPool.AddInverseOffers(ctx, event.GetInverseOffers())
 
-	// TODO: Handle inverse offers from Mesos
 	return nil
 }
 
@@ -231,8 +249,8 @@ func (h *eventHandler) RescindInverseOffer(ctx context.Context, body *sched.Even
 	event := body.GetRescindInverseOffer()
 	log.WithField("event", event).
 		Debug("OfferManager: processing RescindInverseOffer event")
+	h.offerPool.RescindInverseOffer(event.GetInverseOfferId())
 
-	// TODO: handle rescind inverse offer events
 	return nil
 }
 
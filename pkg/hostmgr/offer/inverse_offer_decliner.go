@@ -0,0 +1,95 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offer
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/atomic"
+	"github.com/uber-go/tally"
+
+	"github.com/uber/peloton/pkg/hostmgr/host"
+	"github.com/uber/peloton/pkg/hostmgr/offer/offerpool"
+)
+
+// inverseOfferDecliner declines the inverse offers of the hosts whose
+// unavailability has started while they are still being drained. The
+// drain does not evict the tasks of a job beyond the maximum unavailable
+// instances of its SLA, so such hosts cannot be safely made unavailable
+// yet. The inverse offers of the hosts are accepted once the hosts are
+// marked as drained.
+type inverseOfferDecliner struct {
+	offerPool              offerpool.Pool
+	maintenanceHostInfoMap host.MaintenanceHostInfoMap
+	scope                  tally.Scope
+}
+
+// newInverseOfferDecliner returns a new inverseOfferDecliner
+func newInverseOfferDecliner(
+	pool offerpool.Pool,
+	maintenanceHostInfoMap host.MaintenanceHostInfoMap,
+	scope tally.Scope) *inverseOfferDecliner {
+	return &inverseOfferDecliner{
+		offerPool:              pool,
+		maintenanceHostInfoMap: maintenanceHostInfoMap,
+		scope:                  scope,
+	}
+}
+
+// Decline declines the inverse offers of the draining hosts
+// whose unavailability has started
+func (d *inverseOfferDecliner) Decline(_ *atomic.Bool) {
+	var drainingHosts []string
+	for _, hostInfo := range d.maintenanceHostInfoMap.GetDrainingHostInfos([]string{}) {
+		drainingHosts = append(drainingHosts, hostInfo.GetHostname())
+	}
+	if len(drainingHosts) == 0 {
+		return
+	}
+
+	hostSummaries, err := d.offerPool.GetHostSummaries(drainingHosts)
+	if err != nil {
+		log.WithError(err).Warn("Failed to get host summaries")
+		return
+	}
+
+	now := time.Now().UnixNano()
+	var hostnames []string
+	for hostname, hostSummary := range hostSummaries {
+		for _, inverseOffer := range hostSummary.GetInverseOffers() {
+			if inverseOffer.GetUnavailability().GetStart().GetNanoseconds() <= now {
+				hostnames = append(hostnames, hostname)
+				break
+			}
+		}
+	}
+	if len(hostnames) == 0 {
+		return
+	}
+
+	if err := d.offerPool.DeclineInverseOffers(
+		context.Background(),
+		hostnames); err != nil {
+		log.WithError(err).
+			WithField("hosts", hostnames).
+			Warn("Failed to decline inverse offers")
+		return
+	}
+	d.scope.Counter("declined_hosts").Inc(int64(len(hostnames)))
+	log.WithField("hosts", hostnames).
+		Info("Declined inverse offers of hosts which are still draining")
+}
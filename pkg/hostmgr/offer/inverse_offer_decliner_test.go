@@ -0,0 +1,105 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/host"
+	"github.com/uber/peloton/pkg/hostmgr/summary"
+
+	host_mocks "github.com/uber/peloton/pkg/hostmgr/host/mocks"
+	offerpool_mocks "github.com/uber/peloton/pkg/hostmgr/offer/offerpool/mocks"
+	summary_mocks "github.com/uber/peloton/pkg/hostmgr/summary/mocks"
+)
+
+func createInverseOffer(id string, start time.Time) *mesos.InverseOffer {
+	startNanos := start.UnixNano()
+	return &mesos.InverseOffer{
+		Id: &mesos.OfferID{Value: &id},
+		Unavailability: &mesos.Unavailability{
+			Start: &mesos.TimeInfo{Nanoseconds: &startNanos},
+		},
+	}
+}
+
+func TestInverseOfferDecliner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	offerPool := offerpool_mocks.NewMockPool(ctrl)
+	maintenanceHostInfoMap := host_mocks.NewMockMaintenanceHostInfoMap(ctrl)
+	testScope := tally.NewTestScope("", map[string]string{})
+	decliner := newInverseOfferDecliner(
+		offerPool,
+		maintenanceHostInfoMap,
+		testScope)
+
+	// no draining hosts
+	maintenanceHostInfoMap.EXPECT().
+		GetDrainingHostInfos([]string{}).
+		Return(nil)
+	decliner.Decline(nil)
+
+	// host0 unavailability has started, host1 unavailability is
+	// in the future
+	maintenanceHostInfoMap.EXPECT().
+		GetDrainingHostInfos([]string{}).
+		Return([]*host.HostInfo{
+			{Hostname: "host0", State: host.HostState_HOST_STATE_DRAINING},
+			{Hostname: "host1", State: host.HostState_HOST_STATE_DRAINING},
+		}).Times(2)
+
+	hostSummaries := make(map[string]summary.HostSummary)
+	starts := []time.Time{
+		time.Now().Add(-time.Minute),
+		time.Now().Add(time.Hour),
+	}
+	for i, start := range starts {
+		hostSummary := summary_mocks.NewMockHostSummary(ctrl)
+		id := fmt.Sprintf("inverse-offer-%d", i)
+		hostSummary.EXPECT().GetInverseOffers().
+			Return(map[string]*mesos.InverseOffer{
+				id: createInverseOffer(id, start),
+			}).Times(2)
+		hostSummaries[fmt.Sprintf("host%d", i)] = hostSummary
+	}
+	offerPool.EXPECT().
+		GetHostSummaries(gomock.Any()).
+		Return(hostSummaries, nil).
+		Times(2)
+
+	offerPool.EXPECT().
+		DeclineInverseOffers(gomock.Any(), []string{"host0"}).
+		Return(fmt.Errorf("mesos error"))
+	decliner.Decline(nil)
+	assert.Nil(t, testScope.Snapshot().Counters()["declined_hosts+"])
+
+	offerPool.EXPECT().
+		DeclineInverseOffers(gomock.Any(), []string{"host0"}).
+		Return(nil)
+	decliner.Decline(nil)
+	assert.Equal(
+		t,
+		int64(1),
+		testScope.Snapshot().Counters()["declined_hosts+"].Value())
+}
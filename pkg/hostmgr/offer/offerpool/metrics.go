@@ -43,6 +43,15 @@ type Metrics struct {
 	RescindEvents     tally.Counter
 	Decline           tally.Counter
 	DeclineFail       tally.Counter
+
+	// metrics for inverse offers
+	InverseOffers             tally.Counter
+	RescindInverseOfferEvents tally.Counter
+	AcceptInverseOffers       tally.Counter
+	AcceptInverseOffersFail   tally.Counter
+	DeclineInverseOffers      tally.Counter
+	DeclineInverseOffersFail  tally.Counter
+	UnknownAgentInverseOffers tally.Counter
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics initialized
@@ -58,6 +67,7 @@ func NewMetrics(scope tally.Scope) *Metrics {
 
 	hostsScope := poolScope.SubScope("hosts")
 	offersScope := poolScope.SubScope("offers")
+	inverseOffersScope := poolScope.SubScope("inverse_offers")
 
 	return &Metrics{
 		Ready:            scalar.NewGaugeMaps(readyScope),
@@ -72,6 +82,14 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		Decline:           offersScope.Counter("decline"),
 		DeclineFail:       offersScope.Counter("decline_fail"),
 
+		InverseOffers:             inverseOffersScope.Counter("received"),
+		RescindInverseOfferEvents: inverseOffersScope.Counter("rescind"),
+		AcceptInverseOffers:       inverseOffersScope.Counter("accept"),
+		AcceptInverseOffersFail:   inverseOffersScope.Counter("accept_fail"),
+		DeclineInverseOffers:      inverseOffersScope.Counter("decline"),
+		DeclineInverseOffersFail:  inverseOffersScope.Counter("decline_fail"),
+		UnknownAgentInverseOffers: inverseOffersScope.Counter("unknown_agent"),
+
 		ReadyHosts:               hostsScope.Gauge("ready"),
 		PlacingHosts:             hostsScope.Gauge("placing"),
 		AvailableHosts:           hostsScope.Gauge("available"),
@@ -33,6 +33,7 @@ import (
 
 	"github.com/uber/peloton/pkg/common/constraints"
 	"github.com/uber/peloton/pkg/hostmgr/binpacking"
+	"github.com/uber/peloton/pkg/hostmgr/host"
 	hostmgr_mesos "github.com/uber/peloton/pkg/hostmgr/mesos"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
@@ -84,6 +85,16 @@ type Pool interface {
 		hostOfferID string,
 		taskIDs ...*peloton.TaskID) (map[string]*mesos.Offer, error)
 
+	// ClaimForOperation takes reserved or unreserved offers on given host
+	// out of the pool, so they can be used in a standalone offer operation
+	// such as RESERVE, UNRESERVE, CREATE or DESTROY on given resources.
+	// Reserved offers which do not hold the reservations of the resources
+	// are left in the pool.
+	ClaimForOperation(
+		hostname string,
+		useReservedOffers bool,
+		resources []*mesos.Resource) (map[string]*mesos.Offer, error)
+
 	// ReturnUnusedOffers returns previously placed offers on hostname back
 	// to current offer pool so they can be used by future launch actions.
 	ReturnUnusedOffers(hostname string) error
@@ -133,6 +144,22 @@ type Pool interface {
 
 	// ReleaseHoldForTasks release the hold of host for the tasks specified
 	ReleaseHoldForTasks(hostname string, taskIDs []*peloton.TaskID) error
+
+	// AddInverseOffers adds the inverse offers sent by Mesos Master to the
+	// summaries of their hosts.
+	AddInverseOffers(ctx context.Context, inverseOffers []*mesos.InverseOffer)
+
+	// RescindInverseOffer removes an inverse offer rescinded by Mesos Master.
+	// Returns whether the inverse offer is found in the pool.
+	RescindInverseOffer(*mesos.OfferID) bool
+
+	// AcceptInverseOffers accepts the pending inverse offers of the hosts,
+	// telling Mesos Master that the hosts can be made unavailable.
+	AcceptInverseOffers(ctx context.Context, hostnames []string) error
+
+	// DeclineInverseOffers declines the pending inverse offers of the hosts,
+	// telling Mesos Master that the hosts cannot be safely made unavailable.
+	DeclineInverseOffers(ctx context.Context, hostnames []string) error
 }
 
 const (
@@ -243,6 +270,10 @@ type offerPool struct {
 	// taskHeldIndex --- key: task id,
 	// value: host held for the task
 	taskHeldIndex sync.Map
+
+	// Map from inverse offer id to hostname.
+	// Used when inverse offer is rescinded, accepted or declined.
+	inverseOffers sync.Map
 }
 
 // ClaimForPlace obtains offers from pool conforming to given constraints.
@@ -358,6 +389,44 @@ func (p *offerPool) ClaimForLaunch(
 	return offerMap, nil
 }
 
+// ClaimForOperation takes offers from pool (removes from hostsummary) for
+// a standalone offer operation.
+func (p *offerPool) ClaimForOperation(
+	hostname string,
+	useReservedOffers bool,
+	resources []*mesos.Resource,
+) (map[string]*mesos.Offer, error) {
+	p.RLock()
+	defer p.RUnlock()
+
+	hs, ok := p.hostOfferIndex[hostname]
+	if !ok {
+		return nil, errors.New("cannot find input hostname " + hostname)
+	}
+
+	offerMap, err := hs.ClaimForOperation(useReservedOffers, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(offerMap) == 0 {
+		return nil, errors.New("no offer found for operation on " + hostname)
+	}
+
+	for id := range offerMap {
+		if _, ok := p.timedOffers.Load(id); ok {
+			p.timedOffers.Delete(id)
+		} else {
+			log.WithFields(log.Fields{
+				"offer_id": id,
+				"host":     hostname,
+			}).Warn("ClaimForOperation: OfferID not found in pool.")
+		}
+	}
+
+	return offerMap, nil
+}
+
 // validateOfferUnavailability for incoming offer.
 // Reject an offer if maintenance start time is less than current time.
 // Reject an offer if current time is less than 3 hours to maintenance start time.
@@ -523,6 +592,10 @@ func (p *offerPool) Clear() {
 		p.timedOffers.Delete(key)
 		return true
 	})
+	p.inverseOffers.Range(func(key interface{}, value interface{}) bool {
+		p.inverseOffers.Delete(key)
+		return true
+	})
 	p.hostOfferIndex = map[string]summary.HostSummary{}
 }
 
@@ -562,6 +635,199 @@ func (p *offerPool) DeclineOffers(
 	return nil
 }
 
+// AddInverseOffers is a callback event when Mesos Master sends inverse
+// offers. Inverse offers announce the planned unavailability of the hosts,
+// and stay in the summaries of their hosts until they are rescinded,
+// accepted or declined.
+func (p *offerPool) AddInverseOffers(
+	ctx context.Context,
+	inverseOffers []*mesos.InverseOffer) {
+	p.metrics.InverseOffers.Inc(int64(len(inverseOffers)))
+
+	hostnameToInverseOffers := make(map[string][]*mesos.InverseOffer)
+	for _, inverseOffer := range inverseOffers {
+		hostname := getInverseOfferHostname(inverseOffer)
+		if hostname == "" {
+			log.WithFields(log.Fields{
+				"inverse_offer_id": inverseOffer.GetId().GetValue(),
+				"agent_id":         inverseOffer.GetAgentId().GetValue(),
+			}).Warn("Inverse offer for unknown agent")
+			p.metrics.UnknownAgentInverseOffers.Inc(1)
+			continue
+		}
+		hostnameToInverseOffers[hostname] = append(
+			hostnameToInverseOffers[hostname], inverseOffer)
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	for hostname, inverseOffers := range hostnameToInverseOffers {
+		hs, ok := p.hostOfferIndex[hostname]
+		if !ok {
+			hs = summary.New(
+				p.volumeStore,
+				p.scarceResourceTypes,
+				hostname,
+				p.slackResourceTypes,
+				p.hostPlacingOfferStatusTimeout)
+			p.hostOfferIndex[hostname] = hs
+		}
+		for _, inverseOffer := range inverseOffers {
+			p.inverseOffers.Store(inverseOffer.GetId().GetValue(), hostname)
+			hs.AddInverseOffer(inverseOffer)
+		}
+		log.WithFields(log.Fields{
+			"host":           hostname,
+			"inverse_offers": inverseOffers,
+		}).Info("Inverse offers received")
+	}
+}
+
+// getInverseOfferHostname returns the hostname of the agent of an inverse
+// offer. Mesos Master sets the hostname of the agent in the URL of the
+// inverse offer, otherwise the hostname is looked up in the agent map.
+func getInverseOfferHostname(inverseOffer *mesos.InverseOffer) string {
+	if hostname := inverseOffer.GetUrl().GetAddress().GetHostname(); hostname != "" {
+		return hostname
+	}
+	return host.GetHostnameByAgentID(inverseOffer.GetAgentId().GetValue())
+}
+
+// removeInverseOffer is a helper method to remove an inverse offer
+// from inverseOffers and hostSummary.
+func (p *offerPool) removeInverseOffer(offerID, reason string) {
+	hostname, ok := p.inverseOffers.Load(offerID)
+	if !ok {
+		log.
+			WithField("inverse_offer_id", offerID).
+			Info("inverse offer not found in pool.")
+		return
+	}
+	p.inverseOffers.Delete(offerID)
+
+	hs, ok := p.hostOfferIndex[hostname.(string)]
+	if !ok {
+		log.WithFields(log.Fields{
+			"host":             hostname,
+			"inverse_offer_id": offerID,
+		}).Warn("host not found in hostOfferIndex")
+		return
+	}
+	hs.RemoveInverseOffer(offerID)
+	log.WithFields(log.Fields{
+		"host":             hostname,
+		"inverse_offer_id": offerID,
+		"reason":           reason,
+	}).Info("Inverse offer removed")
+}
+
+// RescindInverseOffer is a callback event when Mesos Master rescinds
+// an inverse offer, for example when the host is removed from the
+// maintenance schedule.
+func (p *offerPool) RescindInverseOffer(offerID *mesos.OfferID) bool {
+	p.RLock()
+	defer p.RUnlock()
+
+	p.metrics.RescindInverseOfferEvents.Inc(1)
+	if _, ok := p.inverseOffers.Load(offerID.GetValue()); !ok {
+		return false
+	}
+	p.removeInverseOffer(offerID.GetValue(), "inverse offer is rescinded.")
+	return true
+}
+
+// getInverseOfferIDs returns the ids of the pending
+// inverse offers of the hosts
+func (p *offerPool) getInverseOfferIDs(hostnames []string) []*mesos.OfferID {
+	var offerIDs []*mesos.OfferID
+	for _, hostname := range hostnames {
+		hs, ok := p.hostOfferIndex[hostname]
+		if !ok {
+			continue
+		}
+		for id := range hs.GetInverseOffers() {
+			offerID := id
+			offerIDs = append(offerIDs, &mesos.OfferID{Value: &offerID})
+		}
+	}
+	return offerIDs
+}
+
+// AcceptInverseOffers calls mesos master to accept the
+// pending inverse offers of the hosts
+func (p *offerPool) AcceptInverseOffers(
+	ctx context.Context,
+	hostnames []string) error {
+	p.RLock()
+	defer p.RUnlock()
+
+	offerIDs := p.getInverseOfferIDs(hostnames)
+	if len(offerIDs) == 0 {
+		return nil
+	}
+
+	callType := sched.Call_ACCEPT_INVERSE_OFFERS
+	msg := &sched.Call{
+		FrameworkId: p.mesosFrameworkInfoProvider.GetFrameworkID(ctx),
+		Type:        &callType,
+		AcceptInverseOffers: &sched.Call_AcceptInverseOffers{
+			InverseOfferIds: offerIDs,
+		},
+	}
+	msid := p.mesosFrameworkInfoProvider.GetMesosStreamID(ctx)
+	if err := p.mSchedulerClient.Call(msid, msg); err != nil {
+		log.WithError(err).
+			WithField("call", msg).
+			Warn("Failed to accept inverse offers.")
+		p.metrics.AcceptInverseOffersFail.Inc(1)
+		return err
+	}
+
+	p.metrics.AcceptInverseOffers.Inc(int64(len(offerIDs)))
+	for _, offerID := range offerIDs {
+		p.removeInverseOffer(offerID.GetValue(), "inverse offer is accepted")
+	}
+	return nil
+}
+
+// DeclineInverseOffers calls mesos master to decline the
+// pending inverse offers of the hosts
+func (p *offerPool) DeclineInverseOffers(
+	ctx context.Context,
+	hostnames []string) error {
+	p.RLock()
+	defer p.RUnlock()
+
+	offerIDs := p.getInverseOfferIDs(hostnames)
+	if len(offerIDs) == 0 {
+		return nil
+	}
+
+	callType := sched.Call_DECLINE_INVERSE_OFFERS
+	msg := &sched.Call{
+		FrameworkId: p.mesosFrameworkInfoProvider.GetFrameworkID(ctx),
+		Type:        &callType,
+		DeclineInverseOffers: &sched.Call_DeclineInverseOffers{
+			InverseOfferIds: offerIDs,
+		},
+	}
+	msid := p.mesosFrameworkInfoProvider.GetMesosStreamID(ctx)
+	if err := p.mSchedulerClient.Call(msid, msg); err != nil {
+		log.WithError(err).
+			WithField("call", msg).
+			Warn("Failed to decline inverse offers.")
+		p.metrics.DeclineInverseOffersFail.Inc(1)
+		return err
+	}
+
+	p.metrics.DeclineInverseOffers.Inc(int64(len(offerIDs)))
+	for _, offerID := range offerIDs {
+		p.removeInverseOffer(offerID.GetValue(), "inverse offer is declined")
+	}
+	return nil
+}
+
 // ReturnUnusedOffers returns resources previously sent to placement engine
 // back to ready state.
 func (p *offerPool) ReturnUnusedOffers(hostname string) error {
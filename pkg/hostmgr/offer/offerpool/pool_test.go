@@ -904,6 +904,132 @@ func (suite *OfferPoolTestSuite) TestDeclineOffers() {
 	suite.Equal(suite.GetTimedOfferLen(), 2)
 }
 
+// createInverseOffer creates an inverse offer for the agent of the
+// host, whose unavailability starts at the given time
+func createInverseOffer(
+	hostname string,
+	offerID string,
+	start time.Time) *mesos.InverseOffer {
+	agentID := fmt.Sprintf("%s-%d", hostname, 1)
+	startNanos := start.UnixNano()
+	return &mesos.InverseOffer{
+		Id: &mesos.OfferID{
+			Value: &offerID,
+		},
+		AgentId: &mesos.AgentID{
+			Value: &agentID,
+		},
+		Url: &mesos.URL{
+			Address: &mesos.Address{
+				Hostname: &hostname,
+			},
+		},
+		Unavailability: &mesos.Unavailability{
+			Start: &mesos.TimeInfo{
+				Nanoseconds: &startNanos,
+			},
+		},
+	}
+}
+
+func (suite *OfferPoolTestSuite) TestAddRescindInverseOffers() {
+	start := time.Now().Add(time.Hour)
+	inverseOffer1 := createInverseOffer(_testAgent1, "inverse-offer-1", start)
+	inverseOffer2 := createInverseOffer(_testAgent2, "inverse-offer-2", start)
+	unknownAgentID := "unknown-agent-id"
+	unknownOfferID := "inverse-offer-3"
+	inverseOffer3 := &mesos.InverseOffer{
+		Id:      &mesos.OfferID{Value: &unknownOfferID},
+		AgentId: &mesos.AgentID{Value: &unknownAgentID},
+	}
+
+	suite.pool.AddOffers(context.Background(), suite.agent1Offers[:1])
+	suite.pool.AddInverseOffers(
+		context.Background(),
+		[]*mesos.InverseOffer{inverseOffer1, inverseOffer2, inverseOffer3})
+
+	// a summary is created for the host without offers
+	suite.Len(suite.pool.hostOfferIndex, 2)
+	suite.Equal(
+		map[string]*mesos.InverseOffer{"inverse-offer-1": inverseOffer1},
+		suite.pool.hostOfferIndex[_testAgent1].GetInverseOffers())
+	suite.Equal(
+		map[string]*mesos.InverseOffer{"inverse-offer-2": inverseOffer2},
+		suite.pool.hostOfferIndex[_testAgent2].GetInverseOffers())
+
+	suite.True(suite.pool.RescindInverseOffer(inverseOffer1.GetId()))
+	suite.Empty(suite.pool.hostOfferIndex[_testAgent1].GetInverseOffers())
+	suite.False(suite.pool.RescindInverseOffer(inverseOffer1.GetId()))
+	suite.False(suite.pool.RescindInverseOffer(inverseOffer3.GetId()))
+
+	suite.pool.Clear()
+	suite.False(suite.pool.RescindInverseOffer(inverseOffer2.GetId()))
+}
+
+func (suite *OfferPoolTestSuite) TestAcceptDeclineInverseOffers() {
+	start := time.Now().Add(time.Hour)
+	inverseOffer1 := createInverseOffer(_testAgent1, "inverse-offer-1", start)
+	inverseOffer2 := createInverseOffer(_testAgent2, "inverse-offer-2", start)
+	suite.pool.AddInverseOffers(
+		context.Background(),
+		[]*mesos.InverseOffer{inverseOffer1, inverseOffer2})
+
+	_frameworkID := "frameworkID"
+	frameworkID := &mesos.FrameworkID{
+		Value: &_frameworkID,
+	}
+
+	acceptType := sched.Call_ACCEPT_INVERSE_OFFERS
+	acceptMsg := &sched.Call{
+		FrameworkId: frameworkID,
+		Type:        &acceptType,
+		AcceptInverseOffers: &sched.Call_AcceptInverseOffers{
+			InverseOfferIds: []*mesos.OfferID{inverseOffer1.Id},
+		},
+	}
+	declineType := sched.Call_DECLINE_INVERSE_OFFERS
+	declineMsg := &sched.Call{
+		FrameworkId: frameworkID,
+		Type:        &declineType,
+		DeclineInverseOffers: &sched.Call_DeclineInverseOffers{
+			InverseOfferIds: []*mesos.OfferID{inverseOffer2.Id},
+		},
+	}
+
+	gomock.InOrder(
+		suite.provider.EXPECT().GetFrameworkID(context.Background()).Return(frameworkID),
+		suite.provider.EXPECT().GetMesosStreamID(context.Background()).Return(_streamID),
+		suite.schedulerClient.EXPECT().Call(_streamID, acceptMsg).Return(nil),
+
+		suite.provider.EXPECT().GetFrameworkID(context.Background()).Return(frameworkID),
+		suite.provider.EXPECT().GetMesosStreamID(context.Background()).Return(_streamID),
+		suite.schedulerClient.EXPECT().
+			Call(_streamID, declineMsg).
+			Return(fmt.Errorf("fake decline error")),
+
+		suite.provider.EXPECT().GetFrameworkID(context.Background()).Return(frameworkID),
+		suite.provider.EXPECT().GetMesosStreamID(context.Background()).Return(_streamID),
+		suite.schedulerClient.EXPECT().Call(_streamID, declineMsg).Return(nil),
+	)
+
+	suite.NoError(suite.pool.AcceptInverseOffers(
+		context.Background(), []string{_testAgent1}))
+	suite.Empty(suite.pool.hostOfferIndex[_testAgent1].GetInverseOffers())
+
+	// the inverse offers stay in the pool if the call fails
+	suite.Error(suite.pool.DeclineInverseOffers(
+		context.Background(), []string{_testAgent2}))
+	suite.Len(suite.pool.hostOfferIndex[_testAgent2].GetInverseOffers(), 1)
+
+	suite.NoError(suite.pool.DeclineInverseOffers(
+		context.Background(), []string{_testAgent2}))
+	suite.Empty(suite.pool.hostOfferIndex[_testAgent2].GetInverseOffers())
+
+	// no call is made for hosts without inverse offers
+	suite.NoError(suite.pool.AcceptInverseOffers(
+		context.Background(), []string{_testAgent1, _testAgent3}))
+}
+
 func (suite *OfferPoolTestSuite) TestOfferSorting() {
 	// Verify offer pool is empty
 	suite.Equal(suite.GetTimedOfferLen(), 0)
@@ -15,6 +15,9 @@
 package hostmgr
 
 import (
+	"time"
+
+	"github.com/uber/peloton/pkg/common/stringset"
 	"github.com/uber/peloton/pkg/hostmgr/host"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
 	"github.com/uber/peloton/pkg/hostmgr/metrics"
@@ -90,10 +93,26 @@ func (r *recoveryHandler) recoverMaintenanceState() error {
 		return nil
 	}
 
+	// hosts whose maintenance window has not opened yet are
+	// drained by the drainer once their window opens
+	scheduledHosts := stringset.New()
+	if len(clusterStatus.GetDrainingMachines()) != 0 {
+		scheduleResponse, err := r.masterOperatorClient.GetMaintenanceSchedule()
+		if err != nil {
+			return err
+		}
+		scheduledHosts = host.GetScheduledHosts(
+			scheduleResponse.GetSchedule(),
+			time.Now())
+	}
+
 	var drainingHosts []string
 	var hostInfos []*hpb.HostInfo
 	for _, drainingMachine := range clusterStatus.GetDrainingMachines() {
 		machineID := drainingMachine.GetId()
+		if scheduledHosts.Contains(machineID.GetHostname()) {
+			continue
+		}
 		hostInfos = append(hostInfos,
 			&hpb.HostInfo{
 				Hostname: machineID.GetHostname(),
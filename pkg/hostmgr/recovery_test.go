@@ -17,6 +17,7 @@ package hostmgr
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	mesos_maintenance "github.com/uber/peloton/.gen/mesos/v1/maintenance"
@@ -104,6 +105,9 @@ func (suite *RecoveryTestSuite) TestStart() {
 		Return(&mesos_master.Response_GetMaintenanceStatus{
 			Status: clusterStatus,
 		}, nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceSchedule().
+		Return(&mesos_master.Response_GetMaintenanceSchedule{}, nil)
 
 	var drainingHostnames []string
 	for _, machine := range suite.drainingMachines {
@@ -134,6 +138,60 @@ func (suite *RecoveryTestSuite) TestStart_Error() {
 	suite.Error(err)
 }
 
+// TestStartSkipsScheduledHosts tests that the draining hosts whose
+// maintenance window has not opened yet are not enqueued
+func (suite *RecoveryTestSuite) TestStartSkipsScheduledHosts() {
+	start := time.Now().Add(time.Hour).UnixNano()
+	suite.mockMaintenanceQueue.EXPECT().Clear()
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceStatus().
+		Return(&mesos_master.Response_GetMaintenanceStatus{
+			Status: &mesos_maintenance.ClusterStatus{
+				DrainingMachines: []*mesos_maintenance.ClusterStatus_DrainingMachine{
+					{Id: suite.drainingMachines[0]},
+				},
+			},
+		}, nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceSchedule().
+		Return(&mesos_master.Response_GetMaintenanceSchedule{
+			Schedule: &mesos_maintenance.Schedule{
+				Windows: []*mesos_maintenance.Window{
+					{
+						MachineIds: suite.drainingMachines,
+						Unavailability: &mesos.Unavailability{
+							Start: &mesos.TimeInfo{Nanoseconds: &start},
+						},
+					},
+				},
+			},
+		}, nil)
+	suite.maintenanceHostInfoMap.EXPECT().ClearAndFillMap(nil)
+	suite.mockMaintenanceQueue.EXPECT().Enqueue(nil).Return(nil)
+
+	suite.NoError(suite.recoveryHandler.Start())
+}
+
+// TestStartGetMaintenanceScheduleError tests the failure to get the
+// maintenance schedule during recovery
+func (suite *RecoveryTestSuite) TestStartGetMaintenanceScheduleError() {
+	suite.mockMaintenanceQueue.EXPECT().Clear()
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceStatus().
+		Return(&mesos_master.Response_GetMaintenanceStatus{
+			Status: &mesos_maintenance.ClusterStatus{
+				DrainingMachines: []*mesos_maintenance.ClusterStatus_DrainingMachine{
+					{Id: suite.drainingMachines[0]},
+				},
+			},
+		}, nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		GetMaintenanceSchedule().
+		Return(nil, fmt.Errorf("fake GetMaintenanceSchedule error"))
+
+	suite.Error(suite.recoveryHandler.Start())
+}
+
 func (suite *RecoveryTestSuite) TestStop() {
 	err := suite.recoveryHandler.Stop()
 	suite.NoError(err)
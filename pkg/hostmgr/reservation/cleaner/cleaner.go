@@ -155,6 +155,9 @@ func (c *cleaner) cleanOffer(offer *mesos.Offer) error {
 	return nil
 }
 
+// needCleanVolume returns true if the offered volume is to be deleted.
+// An offered volume which is still being initialized is moved to
+// CREATED state.
 func (c *cleaner) needCleanVolume(volumeID string, offer *mesos.Offer) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), _defaultContextTimeout)
 	defer cancel()
@@ -184,6 +187,20 @@ func (c *cleaner) needCleanVolume(volumeID string, offer *mesos.Offer) bool {
 		return true
 	}
 
+	if volumeInfo.GetState() == volume.VolumeState_INITIALIZED &&
+		volumeInfo.GetGoalState() == volume.VolumeState_CREATED {
+		// The volume is offered with its persistence ID, so Mesos has
+		// created it. No task may be launched on it yet, such as for a
+		// volume created by CreateVolumes, so it is moved here.
+		volumeInfo.State = volume.VolumeState_CREATED
+		if err := c.volumeStore.UpdatePersistentVolume(ctx, volumeInfo); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"volume": volumeInfo,
+				"offer":  offer,
+			}).Error("Failed to update db for given volume")
+		}
+	}
+
 	return false
 }
 
@@ -307,6 +307,82 @@ func TestNotCleanVolumeIfVolumeGoalstateNotDeleted(t *testing.T) {
 	cleaner.Run(nil)
 }
 
+// TestCreatedVolumeIfOffered tests that an offered volume which is
+// being initialized is moved to CREATED state, and is not cleaned.
+func TestCreatedVolumeIfOffered(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockSchedulerClient := mpb_mocks.NewMockSchedulerClient(ctrl)
+	mockVolumeStore := store_mocks.NewMockPersistentVolumeStore(ctrl)
+	mockOfferPool := offerpool_mocks.NewMockPool(ctrl)
+	defer ctrl.Finish()
+
+	testScope := tally.NewTestScope("", map[string]string{})
+	cleaner := NewCleaner(
+		mockOfferPool,
+		testScope,
+		mockVolumeStore,
+		mockSchedulerClient,
+		&mockMesosStreamIDProvider{})
+
+	reservation := &mesos.Resource_ReservationInfo{
+		Labels: &mesos.Labels{
+			Labels: []*mesos.Label{
+				{
+					Key:   &_testKey,
+					Value: &_testValue,
+				},
+			},
+		},
+	}
+	diskInfo := &mesos.Resource_DiskInfo{
+		Persistence: &mesos.Resource_DiskInfo_Persistence{
+			Id: &_testVolumeID,
+		},
+	}
+	resources := []*mesos.Resource{
+		util.NewMesosResourceBuilder().
+			WithName("cpus").
+			WithValue(_perHostCPU).
+			WithRole(pelotonRole).
+			WithReservation(reservation).
+			Build(),
+		util.NewMesosResourceBuilder().
+			WithName("mem").
+			WithValue(_perHostMem).
+			WithReservation(reservation).
+			WithRole(pelotonRole).
+			Build(),
+		util.NewMesosResourceBuilder().
+			WithName("disk").
+			WithValue(_perHostDisk).
+			WithRole(pelotonRole).
+			WithReservation(reservation).
+			WithDisk(diskInfo).
+			Build(),
+	}
+	offer := createMesosOffer(resources)
+	reservedOffers := make(map[string]*mesos.Offer)
+	reservedOffers[offer.GetId().GetValue()] = offer
+	hostOffers := make(map[string]map[string]*mesos.Offer)
+	hostOffers[offer.GetHostname()] = reservedOffers
+	volumeID := &peloton.VolumeID{
+		Value: _testVolumeID,
+	}
+	volumeInfo := &volume.PersistentVolumeInfo{
+		State:     volume.VolumeState_INITIALIZED,
+		GoalState: volume.VolumeState_CREATED,
+	}
+
+	gomock.InOrder(
+		mockOfferPool.EXPECT().GetOffers(summary.Reserved).Return(hostOffers, 4),
+		mockVolumeStore.EXPECT().GetPersistentVolume(gomock.Any(), volumeID).Return(volumeInfo, nil),
+		mockVolumeStore.EXPECT().UpdatePersistentVolume(gomock.Any(), volumeInfo).Return(nil),
+	)
+
+	cleaner.Run(nil)
+	assert.Equal(t, volume.VolumeState_CREATED, volumeInfo.GetState())
+}
+
 func createMesosOffer(res []*mesos.Resource) *mesos.Offer {
 	return &mesos.Offer{
 		Id: &mesos.OfferID{
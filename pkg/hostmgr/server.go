@@ -28,6 +28,7 @@ import (
 	"github.com/uber/peloton/pkg/hostmgr/metrics"
 	"github.com/uber/peloton/pkg/hostmgr/offer"
 	"github.com/uber/peloton/pkg/hostmgr/reconcile"
+	taskStateManager "github.com/uber/peloton/pkg/hostmgr/task"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
@@ -73,6 +74,10 @@ type Server struct {
 
 	drainer host.Drainer
 
+	// taskStateManager holds the event stream of the task status
+	// updates, which is flushed and closed once not leader
+	taskStateManager taskStateManager.StateManager
+
 	metrics *metrics.Metrics
 
 	// ticker controls connection state check loop
@@ -89,7 +94,8 @@ func NewServer(
 	mesosOutbound transport.Outbounds,
 	reconciler reconcile.TaskReconciler,
 	recoveryHandler RecoveryHandler,
-	drainer host.Drainer) *Server {
+	drainer host.Drainer,
+	taskStateManager taskStateManager.StateManager) *Server {
 
 	s := &Server{
 		ID:                   leader.NewID(httpPort, grpcPort),
@@ -104,6 +110,7 @@ func NewServer(
 		maxBackoff:           _maxBackoff,
 		recoveryHandler:      recoveryHandler,
 		drainer:              drainer,
+		taskStateManager:     taskStateManager,
 		metrics:              metrics.NewMetrics(parent),
 	}
 	log.Info("Hostmgr server started.")
@@ -125,6 +132,9 @@ func (s *Server) Stop() {
 // becomes the leader
 func (s *Server) GainedLeadershipCallback() error {
 	log.WithFields(log.Fields{"role": s.role}).Info("Gained leadership")
+	if err := s.taskStateManager.Start(); err != nil {
+		return err
+	}
 	s.elected.Store(true)
 	return nil
 }
@@ -134,14 +144,14 @@ func (s *Server) GainedLeadershipCallback() error {
 func (s *Server) LostLeadershipCallback() error {
 	log.WithField("role", s.role).Info("Lost leadership")
 	s.elected.Store(false)
-	return nil
+	return s.taskStateManager.Stop()
 }
 
 // ShutDownCallback is the callback to shut down gracefully if possible.
 func (s *Server) ShutDownCallback() error {
 	log.WithFields(log.Fields{"role": s.role}).Info("Quitting election")
 	s.elected.Store(false)
-	return nil
+	return s.taskStateManager.Stop()
 }
 
 // GetID function returns the peloton master address.
@@ -29,6 +29,7 @@ import (
 	"github.com/uber/peloton/pkg/hostmgr/offer"
 	offer_mocks "github.com/uber/peloton/pkg/hostmgr/offer/mocks"
 	reconciler_mocks "github.com/uber/peloton/pkg/hostmgr/reconcile/mocks"
+	task_state_mocks "github.com/uber/peloton/pkg/hostmgr/task/mocks"
 
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
@@ -61,8 +62,9 @@ type ServerTestSuite struct {
 	mInbound          *mhttp_mocks.MockInbound
 	recoveryHandler   *recovery_mocks.MockRecoveryHandler
 
-	reconciler *reconciler_mocks.MockTaskReconciler
-	drainer    *host_mocks.MockDrainer
+	reconciler       *reconciler_mocks.MockTaskReconciler
+	drainer          *host_mocks.MockDrainer
+	taskStateManager *task_state_mocks.MockStateManager
 
 	server *Server
 }
@@ -77,6 +79,7 @@ func (suite *ServerTestSuite) SetupTest() {
 	suite.reconciler = reconciler_mocks.NewMockTaskReconciler(suite.ctrl)
 	suite.recoveryHandler = recovery_mocks.NewMockRecoveryHandler(suite.ctrl)
 	suite.drainer = host_mocks.NewMockDrainer(suite.ctrl)
+	suite.taskStateManager = task_state_mocks.NewMockStateManager(suite.ctrl)
 
 	suite.server = &Server{
 		ID:   _ID,
@@ -94,6 +97,8 @@ func (suite *ServerTestSuite) SetupTest() {
 		drainer:         suite.drainer,
 		// Add outbound when we need it.
 
+		taskStateManager: suite.taskStateManager,
+
 		reconciler: suite.reconciler,
 
 		minBackoff: _minBackoff,
@@ -122,6 +127,7 @@ func (suite *ServerTestSuite) TestNewServer() {
 		suite.reconciler,
 		suite.recoveryHandler,
 		suite.drainer,
+		suite.taskStateManager,
 	)
 	suite.ctrl.Finish()
 	suite.NotNil(s)
@@ -130,16 +136,38 @@ func (suite *ServerTestSuite) TestNewServer() {
 // Test gained leadership callback
 func (suite *ServerTestSuite) TestGainedLeadershipCallback() {
 	suite.mInbound.EXPECT().IsRunning().Return(true).AnyTimes()
-	suite.server.GainedLeadershipCallback()
+	suite.taskStateManager.EXPECT().Start().Return(nil)
+	suite.NoError(suite.server.GainedLeadershipCallback())
 	suite.ctrl.Finish()
 	suite.True(suite.server.elected.Load())
 }
 
+// Test gained leadership callback failing to open the task
+// status update stream
+func (suite *ServerTestSuite) TestGainedLeadershipCallbackStartError() {
+	suite.mInbound.EXPECT().IsRunning().Return(false).AnyTimes()
+	suite.taskStateManager.EXPECT().Start().Return(errFoo)
+	suite.Equal(errFoo, suite.server.GainedLeadershipCallback())
+	suite.ctrl.Finish()
+	suite.False(suite.server.elected.Load())
+}
+
 // Test gained leadership callback
 func (suite *ServerTestSuite) TestLostLeadershipCallback() {
 	suite.mInbound.EXPECT().IsRunning().Return(false).AnyTimes()
 	suite.server.handlersRunning.Store(false)
-	suite.server.LostLeadershipCallback()
+	suite.taskStateManager.EXPECT().Stop().Return(nil)
+	suite.NoError(suite.server.LostLeadershipCallback())
+	suite.ctrl.Finish()
+	suite.False(suite.server.elected.Load())
+}
+
+// Test shut down callback flushing the task status update stream
+func (suite *ServerTestSuite) TestShutDownCallback() {
+	suite.mInbound.EXPECT().IsRunning().Return(false).AnyTimes()
+	suite.server.elected.Store(true)
+	suite.taskStateManager.EXPECT().Stop().Return(nil)
+	suite.NoError(suite.server.ShutDownCallback())
 	suite.ctrl.Finish()
 	suite.False(suite.server.elected.Load())
 }
@@ -133,6 +133,14 @@ type HostSummary interface {
 	// ClaimReservedOffersForLaunch releases reserved offers for task launch.
 	ClaimReservedOffersForLaunch() (map[string]*mesos.Offer, error)
 
+	// ClaimForOperation releases either unreserved or reserved offers for
+	// a standalone offer operation such as reserve or create volume.
+	// Only the reserved offers holding the reservations of given
+	// resources are released.
+	ClaimForOperation(
+		useReservedOffers bool,
+		resources []*mesos.Resource) (map[string]*mesos.Offer, error)
+
 	// CasStatus atomically sets the status to new value if current value is old,
 	// otherwise returns error.
 	CasStatus(old, new HostStatus) error
@@ -176,6 +184,17 @@ type HostSummary interface {
 	// ReturnPlacingHost is called when the host in PLACING state is not used,
 	// and is returned by placement engine
 	ReturnPlacingHost() error
+
+	// AddInverseOffer adds a Mesos inverse offer to the current HostSummary.
+	AddInverseOffer(offer *mesos.InverseOffer)
+
+	// RemoveInverseOffer removes the given Mesos inverse offer by its id,
+	// and returns the removed inverse offer if it was found.
+	RemoveInverseOffer(offerID string) *mesos.InverseOffer
+
+	// GetInverseOffers returns the pending inverse offers of the host.
+	// Returns map of offerid -> inverse offer
+	GetInverseOffers() map[string]*mesos.InverseOffer
 }
 
 type offerIDgenerator func() string
@@ -226,6 +245,10 @@ type hostSummary struct {
 	// key is the task id, value is the expiration time
 	// of the hold
 	heldTasks map[string]time.Time
+
+	// mesos offerID -> pending inverse offer, which announces a
+	// planned unavailability of the host
+	inverseOffers map[string]*mesos.InverseOffer
 }
 
 // New returns a zero initialized hostSummary
@@ -240,6 +263,7 @@ func New(
 		unreservedOffers:    make(map[string]*mesos.Offer),
 		reservedOffers:      make(map[string]*mesos.Offer),
 		heldTasks:           make(map[string]time.Time),
+		inverseOffers:       make(map[string]*mesos.InverseOffer),
 		scarceResourceTypes: scarceResourceTypes,
 		slackResourceTypes:  slackResourceTypes,
 
@@ -328,6 +352,16 @@ func matchHostFilter(
 		return hostsvc.HostFilterResult_INSUFFICIENT_OFFER_RESOURCES
 	}
 
+	// Mesos sets the unavailability of the offers of the hosts
+	// which are in the maintenance schedule.
+	if c.GetExcludeScheduledMaintenance() {
+		for _, offer := range offerMap {
+			if offer.GetUnavailability() != nil {
+				return hostsvc.HostFilterResult_MISMATCH_MAINTENANCE
+			}
+		}
+	}
+
 	// Only try to get first offer in this host because all the offers have
 	// the same host attributes.
 	var firstOffer *mesos.Offer
@@ -384,6 +418,40 @@ func matchHostFilter(
 	return hostsvc.HostFilterResult_MATCH
 }
 
+// matchInverseOffers determines whether the tasks placed with the given
+// HostFilter would still be running when the unavailability announced by
+// one of the inverse offers of the host starts.
+func matchInverseOffers(
+	inverseOffers map[string]*mesos.InverseOffer,
+	c *hostsvc.HostFilter,
+	now time.Time) hostsvc.HostFilterResult {
+	if len(inverseOffers) == 0 {
+		return hostsvc.HostFilterResult_MATCH
+	}
+
+	// long-running tasks, and tasks without a max running time,
+	// never finish before the unavailability
+	var end int64
+	if !c.GetExcludeScheduledMaintenance() && c.GetMaxRunningTimeSecs() > 0 {
+		end = now.Add(
+			time.Duration(c.GetMaxRunningTimeSecs()) * time.Second).UnixNano()
+	}
+
+	for _, inverseOffer := range inverseOffers {
+		unavailability := inverseOffer.GetUnavailability()
+		start := unavailability.GetStart().GetNanoseconds()
+		// the unavailability has already ended
+		if unavailability.GetDuration() != nil &&
+			start+unavailability.GetDuration().GetNanoseconds() <= now.UnixNano() {
+			continue
+		}
+		if end == 0 || end > start {
+			return hostsvc.HostFilterResult_MISMATCH_MAINTENANCE
+		}
+	}
+	return hostsvc.HostFilterResult_MATCH
+}
+
 // TryMatch atomically tries to match offers from the current host with given
 // HostFilter.
 // If current hostSummary is matched by given HostFilter, the first return
@@ -434,6 +502,10 @@ func (a *hostSummary) TryMatch(
 		scalar.FromMesosResources(host.GetAgentInfo(a.GetHostname()).GetResources()),
 		a.scarceResourceTypes)
 
+	if result == hostsvc.HostFilterResult_MATCH {
+		result = matchInverseOffers(a.inverseOffers, filter, time.Now())
+	}
+
 	if result != hostsvc.HostFilterResult_MATCH {
 		return Match{Result: result}
 	}
@@ -563,6 +635,58 @@ func (a *hostSummary) ClaimReservedOffersForLaunch() (map[string]*mesos.Offer, e
 	return result, nil
 }
 
+// ClaimForOperation atomically releases and returns offers on current host
+// for a standalone offer operation. Reserved offers can always be claimed,
+// but only those holding the reservations of given resources are, so that
+// the reservations of other tasks stay in the pool. Unreserved offers can
+// only be claimed if the host is in Ready status so that offers being placed
+// or held for tasks are not taken away.
+func (a *hostSummary) ClaimForOperation(
+	useReservedOffers bool,
+	resources []*mesos.Resource) (map[string]*mesos.Offer, error) {
+	a.Lock()
+	defer a.Unlock()
+
+	result := make(map[string]*mesos.Offer)
+	if useReservedOffers {
+		reservations := make(map[string]bool)
+		for _, res := range resources {
+			if res.GetReservation().GetLabels() != nil {
+				reservations[res.GetReservation().GetLabels().String()] = true
+			}
+		}
+		for offerID, offer := range a.reservedOffers {
+			if holdsReservations(offer, reservations) {
+				result[offerID] = offer
+				delete(a.reservedOffers, offerID)
+			}
+		}
+		return result, nil
+	}
+
+	if a.status != ReadyHost {
+		return nil, errors.New("host status is not Ready")
+	}
+
+	result, a.unreservedOffers = a.unreservedOffers, result
+	a.readyCount.Store(0)
+	return result, nil
+}
+
+// holdsReservations returns true if the offer has resources reserved
+// with any of given reservation labels.
+func holdsReservations(offer *mesos.Offer, reservations map[string]bool) bool {
+	for _, res := range offer.GetResources() {
+		if res.GetReservation().GetLabels() == nil {
+			continue
+		}
+		if reservations[res.GetReservation().GetLabels().String()] {
+			return true
+		}
+	}
+	return false
+}
+
 // RemoveMesosOffer removes the given Mesos offer by its id, and returns
 // CacheStatus and possibly removed offer for tracking purpose.
 func (a *hostSummary) RemoveMesosOffer(offerID, reason string) (HostStatus, *mesos.Offer) {
@@ -902,3 +1026,37 @@ func (a *hostSummary) getResetStatus() HostStatus {
 
 	return newStatus
 }
+
+// AddInverseOffer adds a Mesos inverse offer to the current HostSummary.
+func (a *hostSummary) AddInverseOffer(offer *mesos.InverseOffer) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.inverseOffers[offer.GetId().GetValue()] = offer
+}
+
+// RemoveInverseOffer removes the given Mesos inverse offer by its id,
+// and returns the removed inverse offer if it was found.
+func (a *hostSummary) RemoveInverseOffer(offerID string) *mesos.InverseOffer {
+	a.Lock()
+	defer a.Unlock()
+
+	offer, ok := a.inverseOffers[offerID]
+	if !ok {
+		return nil
+	}
+	delete(a.inverseOffers, offerID)
+	return offer
+}
+
+// GetInverseOffers returns the pending inverse offers of the host.
+func (a *hostSummary) GetInverseOffers() map[string]*mesos.InverseOffer {
+	a.Lock()
+	defer a.Unlock()
+
+	inverseOffers := make(map[string]*mesos.InverseOffer, len(a.inverseOffers))
+	for id, offer := range a.inverseOffers {
+		inverseOffers[id] = offer
+	}
+	return inverseOffers
+}
@@ -854,6 +854,118 @@ func (suite *HostOfferSummaryTestSuite) TestTryMatchSchedulingConstraint() {
 	}
 }
 
+// TestMatchHostFilterScheduledMaintenance tests that the hosts in the
+// maintenance schedule are excluded only if the filter requires it
+func (suite *HostOfferSummaryTestSuite) TestMatchHostFilterScheduledMaintenance() {
+	offer := suite.createUnreservedMesosOffer("offer-id")
+	offerMap := map[string]*mesos.Offer{"offer-id": offer}
+	filter := &hostsvc.HostFilter{}
+
+	suite.Equal(
+		hostsvc.HostFilterResult_MATCH,
+		matchHostFilter(offerMap, filter, nil, scalar.Resources{}, nil))
+
+	start := time.Now().Add(time.Hour).UnixNano()
+	offer.Unavailability = &mesos.Unavailability{
+		Start: &mesos.TimeInfo{Nanoseconds: &start},
+	}
+	suite.Equal(
+		hostsvc.HostFilterResult_MATCH,
+		matchHostFilter(offerMap, filter, nil, scalar.Resources{}, nil))
+
+	filter.ExcludeScheduledMaintenance = true
+	suite.Equal(
+		hostsvc.HostFilterResult_MISMATCH_MAINTENANCE,
+		matchHostFilter(offerMap, filter, nil, scalar.Resources{}, nil))
+}
+
+// TestMatchInverseOffers tests that the hosts with a pending inverse offer
+// are excluded if the tasks would be running when the unavailability starts
+func (suite *HostOfferSummaryTestSuite) TestMatchInverseOffers() {
+	now := time.Now()
+	start := now.Add(time.Hour).UnixNano()
+	inverseOfferID := "inverse-offer-id"
+	inverseOffers := map[string]*mesos.InverseOffer{
+		inverseOfferID: {
+			Id: &mesos.OfferID{Value: &inverseOfferID},
+			Unavailability: &mesos.Unavailability{
+				Start: &mesos.TimeInfo{Nanoseconds: &start},
+			},
+		},
+	}
+
+	testTable := []struct {
+		msg    string
+		filter *hostsvc.HostFilter
+		result hostsvc.HostFilterResult
+	}{
+		{
+			msg:    "unbounded running time",
+			filter: &hostsvc.HostFilter{},
+			result: hostsvc.HostFilterResult_MISMATCH_MAINTENANCE,
+		},
+		{
+			msg: "long-running tasks",
+			filter: &hostsvc.HostFilter{
+				ExcludeScheduledMaintenance: true,
+				MaxRunningTimeSecs:          60,
+			},
+			result: hostsvc.HostFilterResult_MISMATCH_MAINTENANCE,
+		},
+		{
+			msg:    "tasks finish before the unavailability",
+			filter: &hostsvc.HostFilter{MaxRunningTimeSecs: 60},
+			result: hostsvc.HostFilterResult_MATCH,
+		},
+		{
+			msg:    "tasks cross the unavailability",
+			filter: &hostsvc.HostFilter{MaxRunningTimeSecs: 7200},
+			result: hostsvc.HostFilterResult_MISMATCH_MAINTENANCE,
+		},
+	}
+	for _, tt := range testTable {
+		suite.Equal(
+			tt.result,
+			matchInverseOffers(inverseOffers, tt.filter, now),
+			tt.msg)
+	}
+
+	// no inverse offers
+	suite.Equal(
+		hostsvc.HostFilterResult_MATCH,
+		matchInverseOffers(nil, &hostsvc.HostFilter{}, now))
+
+	// the unavailability has already ended
+	start = now.Add(-2 * time.Hour).UnixNano()
+	duration := time.Hour.Nanoseconds()
+	inverseOffers[inverseOfferID].Unavailability.Duration =
+		&mesos.DurationInfo{Nanoseconds: &duration}
+	suite.Equal(
+		hostsvc.HostFilterResult_MATCH,
+		matchInverseOffers(inverseOffers, &hostsvc.HostFilter{}, now))
+}
+
+// TestInverseOffers tests adding and removing inverse offers
+func (suite *HostOfferSummaryTestSuite) TestInverseOffers() {
+	s := New(
+		suite.mockVolumeStore,
+		nil,
+		_testAgent,
+		supportedSlackResourceTypes,
+		time.Duration(30*time.Second)).(*hostSummary)
+
+	id := "inverse-offer-id"
+	inverseOffer := &mesos.InverseOffer{Id: &mesos.OfferID{Value: &id}}
+	s.AddInverseOffer(inverseOffer)
+	suite.Equal(
+		map[string]*mesos.InverseOffer{id: inverseOffer},
+		s.GetInverseOffers())
+
+	suite.Nil(s.RemoveInverseOffer("unknown"))
+	suite.Equal(inverseOffer, s.RemoveInverseOffer(id))
+	suite.Empty(s.GetInverseOffers())
+}
+
 func (suite *HostOfferSummaryTestSuite) TestTryMatchHostOnHeld() {
 	defer suite.ctrl.Finish()
 	offer := suite.createUnreservedMesosOffer("offer-id")
@@ -1390,6 +1502,50 @@ func (suite *HostOfferSummaryTestSuite) TestClaimForReservedOffersForLaunch() {
 	suite.Equal(len(summaryOffers), 0)
 }
 
+func (suite *HostOfferSummaryTestSuite) TestClaimForOperation() {
+	defer suite.ctrl.Finish()
+	offers := suite.createReservedMesosOffers(5, true)
+	offers = append(offers, suite.createUnreservedMesosOffer("unreserved-offerid-1"))
+
+	s := New(suite.mockVolumeStore, nil, offers[0].GetHostname(), supportedSlackResourceTypes, time.Duration(30*time.Second)).(*hostSummary)
+
+	s.AddMesosOffers(context.Background(), offers)
+	suite.Equal(int(s.readyCount.Load()), 1)
+
+	// reserved offers without the reservation of the resources stay
+	otherRes := util.NewMesosResourceBuilder().
+		WithName(common.MesosCPU).
+		WithValue(1.0).
+		WithRole(common.PelotonRole).
+		WithReservation(&mesos.Resource_ReservationInfo{
+			Labels: &mesos.Labels{},
+		}).
+		Build()
+	claimed, err := s.ClaimForOperation(true, []*mesos.Resource{otherRes})
+	suite.NoError(err)
+	suite.Empty(claimed)
+	suite.Equal(len(s.GetOffers(Reserved)), 5)
+
+	claimed, err = s.ClaimForOperation(true, offers[0].GetResources()[:1])
+	suite.NoError(err)
+	suite.Equal(len(claimed), 5)
+	suite.Equal(len(s.GetOffers(Reserved)), 0)
+	suite.Equal(int(s.readyCount.Load()), 1)
+
+	// unreserved offers cannot be claimed from a host being placed
+	suite.NoError(s.CasStatus(ReadyHost, PlacingHost))
+	_, err = s.ClaimForOperation(false, nil)
+	suite.Error(err)
+	suite.NoError(s.CasStatus(PlacingHost, ReadyHost))
+
+	claimed, err = s.ClaimForOperation(false, nil)
+	suite.NoError(err)
+	suite.Equal(len(claimed), 1)
+	suite.Equal(len(s.GetOffers(Unreserved)), 0)
+	suite.Equal(int(s.readyCount.Load()), 0)
+	suite.Equal(s.GetHostStatus(), ReadyHost)
+}
+
 func (suite *HostOfferSummaryTestSuite) TestHoldAndReleaseTask() {
 	defer suite.ctrl.Finish()
 
@@ -25,6 +25,7 @@ type Metrics struct {
 	taskAckChannelSize  tally.Gauge
 	taskAckMapSize      tally.Gauge
 	taskUpdateAckDeDupe tally.Counter
+	taskUpdateAckRetry  tally.Counter
 
 	scope tally.Scope
 }
@@ -38,6 +39,7 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		taskAckChannelSize:  scope.Gauge("task_ack_channel_size"),
 		taskAckMapSize:      scope.Gauge("task_ack_map_size"),
 		taskUpdateAckDeDupe: scope.Counter("task_update_ack_dedupe"),
+		taskUpdateAckRetry:  scope.Counter("task_update_ack_retry"),
 
 		scope: scope,
 	}
@@ -33,15 +33,20 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/cirbuf"
 	"github.com/uber/peloton/pkg/common/eventstream"
+	"github.com/uber/peloton/pkg/common/wal"
 	hostmgr_mesos "github.com/uber/peloton/pkg/hostmgr/mesos"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
 )
 
 const (
 	_errorWaitInterval = 10 * time.Second
+	// period of the retries of the acks which did not
+	// fit in the ack channel
+	_ackRetryPeriod = time.Second
 )
 
 // StateManager is the interface for mesos task status updates stream.
@@ -58,6 +63,14 @@ type StateManager interface {
 	// GetStatusUpdateEvents returns all the outstanding status update events
 	// from the event stream
 	GetStatusUpdateEvents() ([]*pb_eventstream.Event, error)
+
+	// Start opens the event stream of the status updates again
+	// once stopped
+	Start() error
+
+	// Stop flushes and closes the event stream of the status updates,
+	// so that it is recovered after a restart
+	Stop() error
 }
 
 type stateManager struct {
@@ -67,6 +80,11 @@ type stateManager struct {
 	ackChannel           chan *mesos.TaskStatus // Buffers the mesos task status updates to be acknowledged
 	ackStatusMap         sync.Map
 
+	// the task status updates to be acknowledged which did
+	// not fit in the ack channel, queued again periodically
+	ackRetryLock sync.Mutex
+	ackRetries   []*mesos.TaskStatus
+
 	eventStreamHandler *eventstream.Handler
 	metrics            *Metrics
 }
@@ -107,33 +125,53 @@ func initResMgrEventForwarder(
 // Job Manager: pulls task status update events from event stream.
 // Resource Manager: Host Manager call event stream client
 // to push task status update events.
+// The events are persisted in a write-ahead log if it is configured.
 func initEventStreamHandler(
 	d *yarpc.Dispatcher,
 	purgedEventProcessor eventstream.PurgedEventsProcessor,
 	bufferSize int,
-	scope tally.Scope) *eventstream.Handler {
-	eventStreamHandler := eventstream.NewEventStreamHandler(
-		bufferSize,
-		[]string{common.PelotonJobManager, common.PelotonResourceManager},
-		purgedEventProcessor,
-		scope,
-	)
+	walConfig wal.Config,
+	scope tally.Scope) (*eventstream.Handler, error) {
+	clients := []string{common.PelotonJobManager, common.PelotonResourceManager}
+
+	var eventStreamHandler *eventstream.Handler
+	if walConfig.Enabled() {
+		var err error
+		eventStreamHandler, err = eventstream.NewDurableEventStreamHandler(
+			walConfig,
+			clients,
+			scope,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create durable event stream")
+		}
+	} else {
+		eventStreamHandler = eventstream.NewEventStreamHandler(
+			bufferSize,
+			clients,
+			purgedEventProcessor,
+			scope,
+		)
+	}
 
 	d.Register(pb_eventstream.BuildEventStreamServiceYARPCProcedures(eventStreamHandler))
 
-	return eventStreamHandler
+	return eventStreamHandler, nil
 }
 
 // NewStateManager init the task state manager by setting up input stream
 // to receive mesos task status update, and outgoing event stream
 // for Job Manager & Resource Manager for consumption of these task status updates.
+// If the write-ahead log is configured, the task status updates are
+// acknowledged once persisted instead of once consumed.
 func NewStateManager(
 	d *yarpc.Dispatcher,
 	schedulerClient mpb.SchedulerClient,
 	updateBufferSize int,
 	updateAckConcurrency int,
+	walConfig wal.Config,
 	resmgrClient resmgrsvc.ResourceManagerServiceYARPCClient,
-	parentScope tally.Scope) StateManager {
+	parentScope tally.Scope) (StateManager, error) {
 
 	stateManagerScope := parentScope.SubScope("taskStateManager")
 	handler := &stateManager{
@@ -146,18 +184,23 @@ func NewStateManager(
 		d,
 		hostmgr_mesos.ServiceName,
 		mpb.Procedure(sched.Event_UPDATE.String(), handler.Update))
-	handler.startAsyncProcessTaskUpdates()
-	handler.eventStreamHandler = initEventStreamHandler(
+	eventStreamHandler, err := initEventStreamHandler(
 		d,
 		handler,
 		updateBufferSize,
+		walConfig,
 		stateManagerScope.SubScope("EventStreamHandler"))
+	if err != nil {
+		return nil, err
+	}
+	handler.eventStreamHandler = eventStreamHandler
+	handler.startAsyncProcessTaskUpdates()
 	initResMgrEventForwarder(
 		handler.eventStreamHandler,
 		resmgrClient,
 		stateManagerScope.SubScope("ResourceManagerClient"))
 	NewMetrics(parentScope)
-	return handler
+	return handler, nil
 }
 
 // GetEventProgress returns the event forward progress
@@ -221,7 +264,23 @@ func (m *stateManager) Update(ctx context.Context, body *sched.Event) error {
 		MesosTaskStatus: taskUpdate.GetStatus(),
 		Type:            pb_eventstream.Event_MESOS_TASK_STATUS,
 	}
-	err = m.eventStreamHandler.AddEvent(event)
+	if m.eventStreamHandler.Durable() {
+		// the status update is acked once persisted, without waiting
+		// for it to be consumed. The event stream does not block until
+		// the status update is persisted, so that the other Mesos events
+		// are not delayed.
+		err = m.eventStreamHandler.AddEventAsync(event, func(err error) {
+			if err != nil {
+				log.WithError(err).
+					WithField("status_update", taskUpdate.GetStatus()).
+					Error("Cannot persist status update")
+				return
+			}
+			m.ackTaskStatus(taskUpdate.GetStatus())
+		})
+	} else {
+		err = m.eventStreamHandler.AddEvent(event)
+	}
 	if err != nil {
 		log.WithError(err).
 			WithField("status_update", taskUpdate.GetStatus()).
@@ -245,6 +304,18 @@ func (m *stateManager) UpdateCounters(_ *uatomic.Bool) {
 	m.metrics.taskAckMapSize.Update(length)
 }
 
+// Start opens the event stream of the status updates again once stopped
+func (m *stateManager) Start() error {
+	return m.eventStreamHandler.Open()
+}
+
+// Stop flushes and closes the event stream of the status updates. The
+// status updates received while stopped are not acked, and are sent
+// again by Mesos.
+func (m *stateManager) Stop() error {
+	return m.eventStreamHandler.Close()
+}
+
 // GetStatusUpdateEvents returns all the outstanding status update events
 // from the event stream
 // This method is primarily for deubbing purpose
@@ -278,6 +349,24 @@ func (m *stateManager) startAsyncProcessTaskUpdates() {
 			}
 		}()
 	}
+	go m.retryAcks()
+}
+
+// retryAcks periodically queues again the task status updates to be
+// acked which did not fit in the ack channel
+func (m *stateManager) retryAcks() {
+	ticker := time.NewTicker(_ackRetryPeriod)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.ackRetryLock.Lock()
+		retries := m.ackRetries
+		m.ackRetries = nil
+		m.ackRetryLock.Unlock()
+
+		for _, taskStatus := range retries {
+			m.ackChannel <- taskStatus
+		}
+	}
 }
 
 // acknowledgeTaskUpdate, ACK task status update events
@@ -311,17 +400,32 @@ func (m *stateManager) EventPurged(events []*cirbuf.CircularBufferItem) {
 	for _, e := range events {
 		event, ok := e.Value.(*pb_eventstream.Event)
 		if ok {
-			uuid, err := uuid.FromBytes(event.GetMesosTaskStatus().GetUuid())
-			if err == nil {
-				// if ack for status update is pending, ignore it
-				_, ok := m.ackStatusMap.Load(uuid)
-				if ok {
-					m.metrics.taskUpdateAckDeDupe.Inc(1)
-					continue
-				}
-				m.ackStatusMap.Store(uuid, struct{}{})
-				m.ackChannel <- event.GetMesosTaskStatus()
-			}
+			m.ackTaskStatus(event.GetMesosTaskStatus())
 		}
 	}
 }
+
+// ackTaskStatus queues the task status update to be acked, unless an ack
+// for the status update is already pending. It does not block, as it is
+// called once the status update is persisted: the status update is
+// queued again later if the ack channel is full.
+func (m *stateManager) ackTaskStatus(taskStatus *mesos.TaskStatus) {
+	uuid, err := uuid.FromBytes(taskStatus.GetUuid())
+	if err != nil {
+		return
+	}
+	// if ack for status update is pending, ignore it
+	if _, ok := m.ackStatusMap.Load(uuid); ok {
+		m.metrics.taskUpdateAckDeDupe.Inc(1)
+		return
+	}
+	m.ackStatusMap.Store(uuid, struct{}{})
+	select {
+	case m.ackChannel <- taskStatus:
+	default:
+		m.metrics.taskUpdateAckRetry.Inc(1)
+		m.ackRetryLock.Lock()
+		m.ackRetries = append(m.ackRetries, taskStatus)
+		m.ackRetryLock.Unlock()
+	}
+}
@@ -16,7 +16,9 @@ package task
 
 import (
 	"context"
+	"io/ioutil"
 	"net/http"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -30,6 +32,7 @@ import (
 	res_mocks "github.com/uber/peloton/.gen/peloton/private/resmgrsvc/mocks"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/rpc"
+	"github.com/uber/peloton/pkg/common/wal"
 	hostmgr_mesos "github.com/uber/peloton/pkg/hostmgr/mesos"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
 	mpb_mocks "github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb/mocks"
@@ -143,13 +146,16 @@ func (s *stateManagerTestSuite) TearDownTest() {
 }
 
 func (s *stateManagerTestSuite) createNewStateManager(ackConcurrency int) StateManager {
-	return NewStateManager(
+	stateManager, err := NewStateManager(
 		s.dispatcher,
 		s.schedulerClient,
 		10,
 		ackConcurrency,
+		wal.Config{},
 		s.resMgrClient,
 		s.testScope)
+	s.NoError(err)
+	return stateManager
 }
 
 func (s *stateManagerTestSuite) TestStatusUpdateDedupe() {
@@ -241,6 +247,110 @@ func (s *stateManagerTestSuite) TestAckTaskStatusUpdate() {
 	}
 	items = append(items, item)
 
+	s.expectAck()
+
+	s.stateManager.EventPurged(items)
+	time.Sleep(500 * time.Millisecond)
+	s.Equal(s.testScope.Snapshot().Gauges()["taskStateManager.task_ack_map_size+"].Value(), float64(0))
+}
+
+// TestAckPersistedTaskStatusUpdate tests that the task status updates
+// are acked once persisted when the write-ahead log is configured
+func (s *stateManagerTestSuite) TestAckPersistedTaskStatusUpdate() {
+	dir, err := ioutil.TempDir("", "taskupdate")
+	s.NoError(err)
+	defer os.RemoveAll(dir)
+
+	s.stateManager, err = NewStateManager(
+		s.dispatcher,
+		s.schedulerClient,
+		10,
+		10,
+		wal.Config{Dir: dir, SyncPolicy: wal.SyncAlways},
+		s.resMgrClient,
+		s.testScope)
+	s.NoError(err)
+
+	s.resMgrClient.EXPECT().
+		NotifyTaskUpdates(gomock.Any(), gomock.Any()).
+		Return(&resmgrsvc.NotifyTaskUpdatesResponse{}, nil).
+		AnyTimes()
+	s.expectAck()
+
+	s.stateManager.Update(s.context, s.taskStatusUpdate)
+	time.Sleep(500 * time.Millisecond)
+	s.Equal(s.testScope.Snapshot().Gauges()["taskStateManager.task_ack_map_size+"].Value(), float64(0))
+
+	events, err := s.stateManager.GetStatusUpdateEvents()
+	s.NoError(err)
+	s.Equal(1, len(events))
+}
+
+// TestStopAndStart tests that the task status updates are rejected while
+// the state manager is stopped, and recovered once started again
+func (s *stateManagerTestSuite) TestStopAndStart() {
+	dir, err := ioutil.TempDir("", "taskupdate")
+	s.NoError(err)
+	defer os.RemoveAll(dir)
+
+	s.stateManager, err = NewStateManager(
+		s.dispatcher,
+		s.schedulerClient,
+		10,
+		10,
+		wal.Config{Dir: dir, SyncPolicy: wal.SyncAlways},
+		s.resMgrClient,
+		s.testScope)
+	s.NoError(err)
+
+	s.resMgrClient.EXPECT().
+		NotifyTaskUpdates(gomock.Any(), gomock.Any()).
+		Return(&resmgrsvc.NotifyTaskUpdatesResponse{}, nil).
+		AnyTimes()
+	s.expectAck()
+
+	s.NoError(s.stateManager.Update(s.context, s.taskStatusUpdate))
+	time.Sleep(500 * time.Millisecond)
+	s.NoError(s.stateManager.Stop())
+	s.NoError(s.stateManager.Stop())
+
+	// not persisted nor acked, Mesos sends it again
+	s.NoError(s.stateManager.Update(s.context, s.taskStatusUpdate))
+	_, err = s.stateManager.GetStatusUpdateEvents()
+	s.Error(err)
+
+	s.NoError(s.stateManager.Start())
+	defer s.stateManager.Stop()
+	events, err := s.stateManager.GetStatusUpdateEvents()
+	s.NoError(err)
+	s.Equal(1, len(events))
+}
+
+// TestAckRetry tests that the task status updates which do not fit in
+// the ack channel are queued again without blocking
+func (s *stateManagerTestSuite) TestAckRetry() {
+	s.stateManager = s.createNewStateManager(0)
+	var items []*cirbuf.CircularBufferItem
+	for i := 0; i < 12; i++ {
+		event := createEvent("59f2d54b-9688-4075-83dd-5fdf305a4f5e", i)
+		event.MesosTaskStatus.Uuid[0] = byte(i)
+		items = append(items, &cirbuf.CircularBufferItem{
+			SequenceID: uint64(i),
+			Value:      event,
+		})
+	}
+
+	s.stateManager.EventPurged(items)
+	s.stateManager.UpdateCounters(nil)
+
+	// the ack channel holds 10 status updates
+	s.Equal(int64(2), s.testScope.Snapshot().Counters()["taskStateManager.task_update_ack_retry+"].Value())
+	s.Equal(float64(10), s.testScope.Snapshot().Gauges()["taskStateManager.task_ack_channel_size+"].Value())
+	s.Equal(float64(12), s.testScope.Snapshot().Gauges()["taskStateManager.task_ack_map_size+"].Value())
+}
+
+// expectAck expects the task status update of the suite to be acked
+func (s *stateManagerTestSuite) expectAck() {
 	value := _frameworkID
 	frameworkID := &mesos.FrameworkID{
 		Value: &value,
@@ -269,10 +379,6 @@ func (s *stateManagerTestSuite) TestAckTaskStatusUpdate() {
 			Return(_streamID, nil),
 		s.schedulerClient.EXPECT().Call(_streamID, msg).Return(nil),
 	)
-
-	s.stateManager.EventPurged(items)
-	time.Sleep(500 * time.Millisecond)
-	s.Equal(s.testScope.Snapshot().Gauges()["taskStateManager.task_ack_map_size+"].Value(), float64(0))
 }
 
 func TestStateManager(t *testing.T) {
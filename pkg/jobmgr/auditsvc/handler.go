@@ -0,0 +1,170 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditsvc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// default time range of a query, ending at its end time
+	_defaultQueryRange = 24 * time.Hour
+	// max time range of a query
+	_maxQueryRange = 31 * 24 * time.Hour
+	// default number of records returned by a query
+	_defaultQueryLimit = 100
+
+	_entitySeparator = ":"
+)
+
+// serviceHandler implements peloton.api.v1alpha.audit.svc.AuditService
+type serviceHandler struct {
+	metrics        *Metrics
+	auditRecordOps ormobjects.AuditRecordOps
+	// returns the current time, which is the default
+	// end time of the queries
+	now func() time.Time
+}
+
+// InitServiceHandler initializes the AuditService
+func InitServiceHandler(
+	d *yarpc.Dispatcher,
+	parent tally.Scope,
+	ormStore *ormobjects.Store,
+) {
+	handler := &serviceHandler{
+		metrics:        NewMetrics(parent),
+		auditRecordOps: ormobjects.NewAuditRecordOps(ormStore),
+		now:            time.Now,
+	}
+
+	d.Register(svc.BuildAuditServiceYARPCProcedures(handler))
+}
+
+// QueryAuditRecords returns the records of the calls matching
+// the request, sorted by descending time
+func (h *serviceHandler) QueryAuditRecords(
+	ctx context.Context,
+	req *svc.QueryAuditRecordsRequest,
+) (*svc.QueryAuditRecordsResponse, error) {
+	h.metrics.QueryAuditRecordsAPI.Inc(1)
+
+	from, to, err := h.queryRange(req)
+	if err != nil {
+		h.metrics.QueryAuditRecordsFail.Inc(1)
+		return nil, err
+	}
+
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = _defaultQueryLimit
+	}
+
+	// the records are filtered while they are read, so that
+	// the query stops once limit records are found
+	objs, err := h.auditRecordOps.Query(
+		ctx,
+		from,
+		to,
+		func(obj *ormobjects.AuditRecordObject) bool {
+			return matchRecord(obj.ToProto(), req)
+		},
+		limit,
+	)
+	if err != nil {
+		log.WithError(err).
+			WithField("request", req).
+			Error("failed to query audit records")
+		h.metrics.QueryAuditRecordsFail.Inc(1)
+		return nil, err
+	}
+
+	var records []*audit.AuditRecord
+	for _, obj := range objs {
+		records = append(records, obj.ToProto())
+	}
+
+	h.metrics.QueryAuditRecords.Inc(1)
+	return &svc.QueryAuditRecordsResponse{Records: records}, nil
+}
+
+// queryRange returns the time range of the records to query
+func (h *serviceHandler) queryRange(
+	req *svc.QueryAuditRecordsRequest,
+) (time.Time, time.Time, error) {
+	to := h.now()
+	if len(req.GetEndTime()) != 0 {
+		t, err := time.Parse(time.RFC3339, req.GetEndTime())
+		if err != nil {
+			return time.Time{}, time.Time{}, yarpcerrors.InvalidArgumentErrorf(
+				"invalid end time: %v", err)
+		}
+		to = t
+	}
+
+	from := to.Add(-_defaultQueryRange)
+	if len(req.GetStartTime()) != 0 {
+		t, err := time.Parse(time.RFC3339, req.GetStartTime())
+		if err != nil {
+			return time.Time{}, time.Time{}, yarpcerrors.InvalidArgumentErrorf(
+				"invalid start time: %v", err)
+		}
+		from = t
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, yarpcerrors.InvalidArgumentErrorf(
+			"start time must be before end time")
+	}
+	if to.Sub(from) > _maxQueryRange {
+		return time.Time{}, time.Time{}, yarpcerrors.InvalidArgumentErrorf(
+			"time range must not be longer than %v", _maxQueryRange)
+	}
+	return from, to, nil
+}
+
+// matchRecord returns if the record matches the user and
+// the entity of the request
+func matchRecord(
+	record *audit.AuditRecord,
+	req *svc.QueryAuditRecordsRequest,
+) bool {
+	if len(req.GetUser()) != 0 && record.GetUser() != req.GetUser() {
+		return false
+	}
+
+	if len(req.GetEntity()) == 0 {
+		return true
+	}
+	for _, entity := range record.GetEntities() {
+		if entity == req.GetEntity() ||
+			strings.HasSuffix(entity, _entitySeparator+req.GetEntity()) {
+			return true
+		}
+	}
+	return false
+}
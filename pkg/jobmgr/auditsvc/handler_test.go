@@ -0,0 +1,194 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditsvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type AuditHandlerTestSuite struct {
+	suite.Suite
+
+	ctrl           *gomock.Controller
+	auditRecordOps *objectmocks.MockAuditRecordOps
+	handler        *serviceHandler
+	now            time.Time
+}
+
+func (suite *AuditHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.auditRecordOps = objectmocks.NewMockAuditRecordOps(suite.ctrl)
+	suite.now = time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.handler = &serviceHandler{
+		metrics:        NewMetrics(tally.NoopScope),
+		auditRecordOps: suite.auditRecordOps,
+		now:            func() time.Time { return suite.now },
+	}
+}
+
+func (suite *AuditHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// testRecords returns records made by user1 and user2
+// on job1 and host1, sorted by descending time
+func (suite *AuditHandlerTestSuite) testRecords() []*ormobjects.AuditRecordObject {
+	newRecord := func(age time.Duration, user string, entities string) *ormobjects.AuditRecordObject {
+		return &ormobjects.AuditRecordObject{
+			RecordID:  gocql.UUIDFromTime(suite.now.Add(-age)),
+			User:      user,
+			Procedure: "peloton.api.v0.job.JobManager::Update",
+			Entities:  entities,
+			Outcome:   "ok",
+		}
+	}
+	return []*ormobjects.AuditRecordObject{
+		newRecord(time.Minute, "user1", "job:job1"),
+		newRecord(2*time.Minute, "user2", "job:job1"),
+		newRecord(3*time.Minute, "user1", "host:host1"),
+		newRecord(4*time.Minute, "user2", ""),
+	}
+}
+
+// queryTestRecords reads the test records like the store does,
+// filtering them and stopping at the limit
+func (suite *AuditHandlerTestSuite) queryTestRecords(
+	_ context.Context,
+	_ time.Time,
+	_ time.Time,
+	filter func(*ormobjects.AuditRecordObject) bool,
+	limit int,
+) ([]*ormobjects.AuditRecordObject, error) {
+	var objs []*ormobjects.AuditRecordObject
+	for _, obj := range suite.testRecords() {
+		if len(objs) >= limit {
+			break
+		}
+		if filter(obj) {
+			objs = append(objs, obj)
+		}
+	}
+	return objs, nil
+}
+
+// TestQueryAuditRecords tests filtering the records by user and entity
+func (suite *AuditHandlerTestSuite) TestQueryAuditRecords() {
+	tests := []struct {
+		req   *svc.QueryAuditRecordsRequest
+		users []string
+	}{
+		{
+			req:   &svc.QueryAuditRecordsRequest{},
+			users: []string{"user1", "user2", "user1", "user2"},
+		},
+		{
+			req:   &svc.QueryAuditRecordsRequest{User: "user1"},
+			users: []string{"user1", "user1"},
+		},
+		{
+			req:   &svc.QueryAuditRecordsRequest{Entity: "job:job1"},
+			users: []string{"user1", "user2"},
+		},
+		{
+			req:   &svc.QueryAuditRecordsRequest{User: "user2", Entity: "job1"},
+			users: []string{"user2"},
+		},
+		{
+			req:   &svc.QueryAuditRecordsRequest{Entity: "host:job1"},
+			users: nil,
+		},
+		{
+			req:   &svc.QueryAuditRecordsRequest{Limit: 3},
+			users: []string{"user1", "user2", "user1"},
+		},
+	}
+
+	for _, test := range tests {
+		suite.auditRecordOps.EXPECT().
+			Query(gomock.Any(), suite.now.Add(-_defaultQueryRange), suite.now,
+				gomock.Any(), gomock.Any()).
+			DoAndReturn(suite.queryTestRecords)
+
+		resp, err := suite.handler.QueryAuditRecords(context.Background(), test.req)
+		suite.NoError(err)
+
+		var users []string
+		for _, record := range resp.GetRecords() {
+			users = append(users, record.GetUser())
+		}
+		suite.Equal(test.users, users, test.req.String())
+	}
+}
+
+// TestQueryAuditRecordsTimeRange tests the time range of the queries
+func (suite *AuditHandlerTestSuite) TestQueryAuditRecordsTimeRange() {
+	start := suite.now.Add(-48 * time.Hour)
+	end := suite.now.Add(-time.Hour)
+	suite.auditRecordOps.EXPECT().
+		Query(gomock.Any(), start, end, gomock.Any(), _defaultQueryLimit).
+		Return(nil, nil)
+	_, err := suite.handler.QueryAuditRecords(
+		context.Background(),
+		&svc.QueryAuditRecordsRequest{
+			StartTime: start.Format(time.RFC3339),
+			EndTime:   end.Format(time.RFC3339),
+		})
+	suite.NoError(err)
+
+	invalidReqs := []*svc.QueryAuditRecordsRequest{
+		{StartTime: "invalid"},
+		{EndTime: "invalid"},
+		{
+			StartTime: end.Format(time.RFC3339),
+			EndTime:   start.Format(time.RFC3339),
+		},
+		{StartTime: suite.now.Add(-_maxQueryRange - time.Hour).Format(time.RFC3339)},
+	}
+	for _, req := range invalidReqs {
+		_, err := suite.handler.QueryAuditRecords(context.Background(), req)
+		suite.Error(err, req.String())
+		suite.True(yarpcerrors.IsInvalidArgument(err), req.String())
+	}
+}
+
+// TestQueryAuditRecordsStoreFail tests failing to read the records
+func (suite *AuditHandlerTestSuite) TestQueryAuditRecordsStoreFail() {
+	suite.auditRecordOps.EXPECT().
+		Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+			gomock.Any()).
+		Return(nil, errors.New("test error"))
+	_, err := suite.handler.QueryAuditRecords(
+		context.Background(),
+		&svc.QueryAuditRecordsRequest{})
+	suite.Error(err)
+}
+
+func TestAuditHandler(t *testing.T) {
+	suite.Run(t, new(AuditHandlerTestSuite))
+}
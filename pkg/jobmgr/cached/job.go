@@ -1508,6 +1508,10 @@ func (j *job) CreateWorkflow(
 		option.apply(opts)
 	}
 
+	if err := validateCanaryConfig(workflowType, updateConfig, opts); err != nil {
+		return nil, nil, err
+	}
+
 	newConfig, err := j.compareAndSetConfig(ctx, opts.jobConfig, opts.configAddOn)
 	if err != nil {
 		return nil, nil, err
@@ -1574,6 +1578,28 @@ func (j *job) CreateWorkflow(
 	return updateID, newEntityVersion, err
 }
 
+// validateCanaryConfig validates that the canary phase of an update
+// covers fewer instances than the update, so that the rest of the
+// instances are gated on the canary phase.
+func validateCanaryConfig(
+	workflowType models.WorkflowType,
+	updateConfig *pbupdate.UpdateConfig,
+	opts *workflowOpts,
+) error {
+	canaryCount := updateConfig.GetCanary().GetInstanceCount()
+	if workflowType != models.WorkflowType_UPDATE || canaryCount == 0 {
+		return nil
+	}
+
+	instanceCount := uint32(len(opts.instanceAdded) + len(opts.instanceUpdated))
+	if canaryCount >= instanceCount {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"canary instance count %d must be less than the %d instances updated",
+			canaryCount, instanceCount)
+	}
+	return nil
+}
+
 func (j *job) PauseWorkflow(
 	ctx context.Context,
 	entityVersion *v1alphapeloton.EntityVersion,
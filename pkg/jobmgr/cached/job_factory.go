@@ -21,6 +21,7 @@ import (
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 	"github.com/uber/peloton/pkg/storage"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
@@ -264,3 +265,15 @@ func (f *jobFactory) notifyTaskRuntimeChanged(
 		// TODO add metric for listener execution latency
 	}
 }
+
+func (f *jobFactory) notifyUpdateChanged(
+	jobID *peloton.JobID,
+	updateModel *models.UpdateModel) {
+
+	if updateModel != nil {
+		for _, l := range f.listeners {
+			l.UpdateChanged(jobID, updateModel)
+		}
+		// TODO add metric for listener execution latency
+	}
+}
@@ -2318,6 +2318,43 @@ func (suite *JobTestSuite) TestJobCreateWorkflowUpdateConfigFailure() {
 	suite.Nil(suite.job.workflows[updateID.GetValue()])
 }
 
+// TestJobCreateWorkflowCanaryCoversAllInstances tests that creating an
+// update whose canary phase covers all the instances updated fails
+func (suite *JobTestSuite) TestJobCreateWorkflowCanaryCoversAllInstances() {
+	entityVersion := versionutil.GetJobEntityVersion(
+		suite.job.runtime.GetConfigurationVersion(),
+		suite.job.runtime.GetDesiredStateVersion(),
+		suite.job.runtime.GetWorkflowVersion(),
+	)
+	prevConfig := &pbjob.JobConfig{
+		ChangeLog: &peloton.ChangeLog{Version: 1},
+	}
+
+	updateID, newEntityVersion, err := suite.job.CreateWorkflow(
+		context.Background(),
+		models.WorkflowType_UPDATE,
+		&pbupdate.UpdateConfig{
+			BatchSize: 10,
+			Canary:    &pbupdate.CanaryConfig{InstanceCount: 3},
+		},
+		entityVersion,
+		WithConfig(
+			prevConfig,
+			prevConfig,
+			&models.ConfigAddOn{},
+		),
+		WithInstanceToProcess(
+			[]uint32{2},
+			[]uint32{0, 1},
+			nil,
+		),
+	)
+
+	suite.Nil(updateID)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+	suite.Nil(newEntityVersion)
+}
+
 // TestJobCreateWorkflowWorkflowCreationFailure tests the failure case of
 // creating workflow due to failure of creating update workflow in db
 func (suite *JobTestSuite) TestJobCreateWorkflowWorkflowCreationFailure() {
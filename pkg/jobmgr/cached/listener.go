@@ -18,6 +18,7 @@ import (
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 )
 
 // JobTaskListener defines an interface that must to be implemented by
@@ -54,4 +55,10 @@ type JobTaskListener interface {
 		jobType pbjob.JobType,
 		runtime *pbtask.RuntimeInfo,
 		labels []*peloton.Label)
+
+	// UpdateChanged is invoked when an update of a job is changed
+	// in cache and persistent store.
+	UpdateChanged(
+		jobID *peloton.JobID,
+		updateModel *models.UpdateModel)
 }
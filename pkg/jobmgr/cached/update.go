@@ -74,6 +74,11 @@ type Update interface {
 		instanceFailed []uint32,
 		instancesCurrent []uint32) error
 
+	// WriteCanaryProgress updates the progress of the canary phase
+	// of the update in DB and cache
+	WriteCanaryProgress(ctx context.Context,
+		canaryStatus *pbupdate.CanaryStatus) error
+
 	// Pause pauses the current update progress
 	Pause(ctx context.Context, opaqueData *peloton.OpaqueData) error
 
@@ -127,6 +132,10 @@ type Update interface {
 
 	GetWorkflowType() models.WorkflowType
 
+	// GetCanaryStatus returns the progress of the canary phase of the
+	// update, nil if the update does not have a canary phase
+	GetCanaryStatus() *pbupdate.CanaryStatus
+
 	// IsTaskInUpdateProgress returns true if a given task is
 	// in progress for the given update, else returns false
 	IsTaskInUpdateProgress(instanceID uint32) bool
@@ -148,6 +157,8 @@ type UpdateStateVector struct {
 	// and for goal state, it will store all the instances which
 	// need to be updated.
 	Instances []uint32
+	// For state, it will be the state of the canary phase of the update.
+	CanaryState pbupdate.CanaryState
 }
 
 // newUpdate creates a new cache update object
@@ -209,6 +220,9 @@ type update struct {
 	// instancesUpdated and instancesRemoved
 	instancesRemoved []uint32
 
+	// progress of the canary phase of the update
+	canaryStatus *pbupdate.CanaryStatus
+
 	jobVersion     uint64 // job configuration version
 	jobPrevVersion uint64 // previous job configuration version
 }
@@ -239,6 +253,11 @@ func (u *update) Create(
 	workflowType models.WorkflowType,
 	updateConfig *pbupdate.UpdateConfig,
 	opaqueData *peloton.OpaqueData) error {
+	var snapshot *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateChanged(snapshot.GetJobID(), snapshot)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -266,6 +285,12 @@ func (u *update) Create(
 		InstancesTotal:       uint32(len(instanceUpdated) + len(instanceAdded) + len(instanceRemoved)),
 		Type:                 workflowType,
 		OpaqueData:           opaqueData,
+		CanaryStatus: newCanaryStatus(
+			workflowType,
+			updateConfig,
+			instanceAdded,
+			instanceUpdated,
+		),
 	}
 
 	// write initialized workflow state for instances on create update
@@ -280,12 +305,25 @@ func (u *update) Create(
 		return err
 	}
 
+	if updateModel.GetCanaryStatus() != nil {
+		if err := u.jobFactory.updateStore.AddJobCanaryEvent(
+			ctx,
+			u.id,
+			workflowType,
+			state,
+			updateModel.GetCanaryStatus().GetState()); err != nil {
+			return err
+		}
+	}
+
 	// Store the new update in DB
 	if err := u.jobFactory.updateStore.CreateUpdate(ctx, updateModel); err != nil {
 		return err
 	}
 
 	u.populateCache(updateModel)
+	snapshot = u.getUpdateModel()
+	snapshot.OpaqueData = opaqueData
 
 	return nil
 }
@@ -295,6 +333,11 @@ func (u *update) Modify(
 	instancesAdded []uint32,
 	instancesUpdated []uint32,
 	instancesRemoved []uint32) error {
+	var snapshot *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateChanged(snapshot.GetJobID(), snapshot)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -350,6 +393,7 @@ func (u *update) Modify(
 		WithField("instance_updated", len(u.instancesUpdated)).
 		WithField("instance_removed", len(u.instancesRemoved)).
 		Debug("update is modified")
+	snapshot = u.getUpdateModel()
 	return nil
 }
 
@@ -359,6 +403,11 @@ func (u *update) WriteProgress(
 	instancesDone []uint32,
 	instancesFailed []uint32,
 	instancesCurrent []uint32) error {
+	var snapshot *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateChanged(snapshot.GetJobID(), snapshot)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -377,17 +426,82 @@ func (u *update) WriteProgress(
 		state = pbupdate.State_PAUSED
 	}
 
-	return u.writeProgress(
+	if err := u.writeProgress(
 		ctx,
 		state,
 		instancesDone,
 		instancesFailed,
 		instancesCurrent,
 		nil,
-	)
+	); err != nil {
+		return err
+	}
+	snapshot = u.getUpdateModel()
+	return nil
+}
+
+func (u *update) WriteCanaryProgress(
+	ctx context.Context,
+	canaryStatus *pbupdate.CanaryStatus) error {
+	var snapshot *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateChanged(snapshot.GetJobID(), snapshot)
+	}()
+	u.Lock()
+	defer u.Unlock()
+
+	// TODO: do recovery automatically when read state
+	if err := u.recover(ctx); err != nil {
+		return err
+	}
+
+	// once an update is in terminal state, its canary
+	// phase should not have any more state change
+	if IsUpdateStateTerminal(u.state) {
+		return nil
+	}
+
+	// write job update event on state change of the canary phase
+	if u.canaryStatus.GetState() != canaryStatus.GetState() {
+		if err := u.jobFactory.updateStore.AddJobCanaryEvent(
+			ctx,
+			u.id,
+			u.workflowType,
+			u.state,
+			canaryStatus.GetState()); err != nil {
+			u.clearCache()
+			return err
+		}
+	}
+
+	if err := u.jobFactory.updateStore.WriteUpdateProgress(
+		ctx,
+		&models.UpdateModel{
+			UpdateID:         u.id,
+			PrevState:        u.prevState,
+			State:            u.state,
+			InstancesDone:    uint32(len(u.instancesDone)),
+			InstancesFailed:  uint32(len(u.instancesFailed)),
+			InstancesCurrent: u.instancesCurrent,
+			CanaryStatus:     canaryStatus,
+		}); err != nil {
+		// clear the cache on DB error to avoid cache inconsistency
+		u.clearCache()
+		return err
+	}
+
+	u.canaryStatus = canaryStatus
+	snapshot = u.getUpdateModel()
+	return nil
 }
 
 func (u *update) Pause(ctx context.Context, opaqueData *peloton.OpaqueData) error {
+	var snapshot *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateChanged(snapshot.GetJobID(), snapshot)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -401,17 +515,27 @@ func (u *update) Pause(ctx context.Context, opaqueData *peloton.OpaqueData) erro
 		return nil
 	}
 
-	return u.writeProgress(
+	if err := u.writeProgress(
 		ctx,
 		pbupdate.State_PAUSED,
 		u.instancesDone,
 		u.instancesFailed,
 		u.instancesCurrent,
 		opaqueData,
-	)
+	); err != nil {
+		return err
+	}
+	snapshot = u.getUpdateModel()
+	snapshot.OpaqueData = opaqueData
+	return nil
 }
 
 func (u *update) Resume(ctx context.Context, opaqueData *peloton.OpaqueData) error {
+	var snapshot *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateChanged(snapshot.GetJobID(), snapshot)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -425,14 +549,19 @@ func (u *update) Resume(ctx context.Context, opaqueData *peloton.OpaqueData) err
 		return nil
 	}
 
-	return u.writeProgress(
+	if err := u.writeProgress(
 		ctx,
 		u.prevState,
 		u.instancesDone,
 		u.instancesFailed,
 		u.instancesCurrent,
 		opaqueData,
-	)
+	); err != nil {
+		return err
+	}
+	snapshot = u.getUpdateModel()
+	snapshot.OpaqueData = opaqueData
+	return nil
 }
 
 // writeProgress write update progress into cache and db,
@@ -554,6 +683,11 @@ func (u *update) recover(ctx context.Context) error {
 }
 
 func (u *update) Cancel(ctx context.Context, opaqueData *peloton.OpaqueData) error {
+	var snapshot *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateChanged(snapshot.GetJobID(), snapshot)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -562,14 +696,19 @@ func (u *update) Cancel(ctx context.Context, opaqueData *peloton.OpaqueData) err
 		return err
 	}
 
-	return u.writeProgress(
+	if err := u.writeProgress(
 		ctx,
 		pbupdate.State_ABORTED,
 		u.instancesDone,
 		u.instancesFailed,
 		u.instancesCurrent,
 		opaqueData,
-	)
+	); err != nil {
+		return err
+	}
+	snapshot = u.getUpdateModel()
+	snapshot.OpaqueData = opaqueData
+	return nil
 }
 
 // Rollback rolls back the current update.
@@ -578,6 +717,11 @@ func (u *update) Rollback(
 	currentConfig *pbjob.JobConfig,
 	targetConfig *pbjob.JobConfig,
 ) error {
+	var snapshot *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateChanged(snapshot.GetJobID(), snapshot)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -649,6 +793,7 @@ func (u *update) Rollback(
 	u.instancesDone = []uint32{}
 	u.instancesFailed = []uint32{}
 	u.populateCache(updateModel)
+	snapshot = u.getUpdateModel()
 
 	return nil
 }
@@ -664,9 +809,10 @@ func (u *update) GetState() *UpdateStateVector {
 	copy(instancesFailed, u.instancesFailed)
 
 	return &UpdateStateVector{
-		State:      u.state,
-		Instances:  append(instancesDone, instancesFailed...),
-		JobVersion: u.jobPrevVersion,
+		State:       u.state,
+		Instances:   append(instancesDone, instancesFailed...),
+		JobVersion:  u.jobPrevVersion,
+		CanaryState: u.canaryStatus.GetState(),
 	}
 }
 
@@ -762,6 +908,17 @@ func (u *update) GetWorkflowType() models.WorkflowType {
 	return u.workflowType
 }
 
+func (u *update) GetCanaryStatus() *pbupdate.CanaryStatus {
+	u.RLock()
+	defer u.RUnlock()
+
+	if u.canaryStatus == nil {
+		return nil
+	}
+	canaryStatus := *u.canaryStatus
+	return &canaryStatus
+}
+
 // IsTaskInUpdateProgress returns true if a given task is
 // in progress for the given update, else returns false
 func (u *update) IsTaskInUpdateProgress(instanceID uint32) bool {
@@ -793,6 +950,9 @@ func (u *update) populateCache(updateModel *models.UpdateModel) {
 	if updateModel.GetJobID() != nil {
 		u.jobID = updateModel.GetJobID()
 	}
+	if updateModel.GetCanaryStatus() != nil {
+		u.canaryStatus = updateModel.GetCanaryStatus()
+	}
 
 	u.state = updateModel.GetState()
 	u.prevState = updateModel.GetPrevState()
@@ -807,6 +967,29 @@ func (u *update) populateCache(updateModel *models.UpdateModel) {
 	u.WorkflowStrategy = getWorkflowStrategy(updateModel.GetState(), updateModel.GetType())
 }
 
+// getUpdateModel returns a snapshot of the update in cache,
+// it is not concurrency safe and must be called with lock held.
+func (u *update) getUpdateModel() *models.UpdateModel {
+	return &models.UpdateModel{
+		UpdateID:             u.id,
+		JobID:                u.jobID,
+		UpdateConfig:         u.updateConfig,
+		Type:                 u.workflowType,
+		JobConfigVersion:     u.jobVersion,
+		PrevJobConfigVersion: u.jobPrevVersion,
+		State:                u.state,
+		PrevState:            u.prevState,
+		InstancesAdded:       u.instancesAdded,
+		InstancesUpdated:     u.instancesUpdated,
+		InstancesRemoved:     u.instancesRemoved,
+		InstancesCurrent:     u.instancesCurrent,
+		InstancesTotal:       uint32(len(u.instancesTotal)),
+		InstancesDone:        uint32(len(u.instancesDone)),
+		InstancesFailed:      uint32(len(u.instancesFailed)),
+		CanaryStatus:         u.canaryStatus,
+	}
+}
+
 func (u *update) clearCache() {
 	u.state = pbupdate.State_INVALID
 	u.prevState = pbupdate.State_INVALID
@@ -817,9 +1000,38 @@ func (u *update) clearCache() {
 	u.instancesAdded = nil
 	u.instancesUpdated = nil
 	u.instancesRemoved = nil
+	u.canaryStatus = nil
 	u.workflowType = models.WorkflowType_UNKNOWN
 }
 
+// newCanaryStatus returns the initial progress of the canary phase for
+// a new update, nil if the update does not have a canary phase.
+// Existing instances being updated are picked as canaries before
+// the instances being added.
+func newCanaryStatus(
+	workflowType models.WorkflowType,
+	updateConfig *pbupdate.UpdateConfig,
+	instancesAdded []uint32,
+	instancesUpdated []uint32,
+) *pbupdate.CanaryStatus {
+	if workflowType != models.WorkflowType_UPDATE ||
+		updateConfig.GetCanary().GetInstanceCount() == 0 {
+		return nil
+	}
+
+	var instances []uint32
+	instances = append(instances, instancesUpdated...)
+	instances = append(instances, instancesAdded...)
+	if uint32(len(instances)) > updateConfig.GetCanary().GetInstanceCount() {
+		instances = instances[:updateConfig.GetCanary().GetInstanceCount()]
+	}
+
+	return &pbupdate.CanaryStatus{
+		State:     pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD,
+		Instances: instances,
+	}
+}
+
 // GetUpdateProgress iterates through instancesToCheck and check if they are running and
 // their current config version is the same as the desired config version.
 // TODO: find the right place to put the func
@@ -505,6 +505,67 @@ func (suite *UpdateTestSuite) TestPauseSuccess() {
 	)
 }
 
+// TestPauseNotifyListeners tests that the listeners receive the
+// paused update once it is written through to the store
+func (suite *UpdateTestSuite) TestPauseNotifyListeners() {
+	listener := &FakeJobListener{}
+	suite.update.jobFactory.listeners = []JobTaskListener{listener}
+	suite.update.jobID = suite.jobID
+	suite.update.workflowType = models.WorkflowType_UPDATE
+	suite.update.state = pbupdate.State_ROLLING_FORWARD
+	suite.update.instancesTotal = []uint32{0, 1, 2}
+	suite.update.instancesDone = []uint32{0}
+	opaque := "test"
+
+	suite.updateStore.EXPECT().
+		AddJobUpdateEvent(
+			gomock.Any(),
+			suite.updateID,
+			gomock.Any(),
+			pbupdate.State_PAUSED).
+		Return(nil)
+	suite.updateStore.EXPECT().
+		WriteUpdateProgress(gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	suite.NoError(suite.update.Pause(
+		context.Background(),
+		&peloton.OpaqueData{Data: opaque}),
+	)
+	suite.Equal(suite.updateID, listener.updateModel.GetUpdateID())
+	suite.Equal(suite.jobID, listener.updateModel.GetJobID())
+	suite.Equal(pbupdate.State_PAUSED, listener.updateModel.GetState())
+	suite.Equal(pbupdate.State_ROLLING_FORWARD,
+		listener.updateModel.GetPrevState())
+	suite.Equal(uint32(3), listener.updateModel.GetInstancesTotal())
+	suite.Equal(uint32(1), listener.updateModel.GetInstancesDone())
+	suite.Equal(opaque, listener.updateModel.GetOpaqueData().GetData())
+
+	// pausing a paused update does not notify the listeners
+	listener.Reset()
+	suite.NoError(suite.update.Pause(context.Background(), nil))
+	suite.Nil(listener.updateModel)
+}
+
+// TestPauseWriteFailNotNotifyListeners tests that the listeners are not
+// notified when the update fails to be written to the store
+func (suite *UpdateTestSuite) TestPauseWriteFailNotNotifyListeners() {
+	listener := &FakeJobListener{}
+	suite.update.jobFactory.listeners = []JobTaskListener{listener}
+	suite.update.state = pbupdate.State_ROLLING_FORWARD
+
+	suite.updateStore.EXPECT().
+		AddJobUpdateEvent(
+			gomock.Any(),
+			suite.updateID,
+			gomock.Any(),
+			pbupdate.State_PAUSED).
+		Return(yarpcerrors.InternalErrorf("test error"))
+
+	suite.Error(suite.update.Pause(context.Background(), nil))
+	suite.Nil(listener.updateModel)
+}
+
 // TestPauseRecoverFail tests the failure case of
 // pause an update due to recover failure
 func (suite *UpdateTestSuite) TestPauseRecoverFail() {
@@ -1813,3 +1874,183 @@ func (suite *UpdateTestSuite) TestUpdateCreatePausedSuccess() {
 func (suite *UpdateTestSuite) TestUpdateGetJobID() {
 	suite.Equal(suite.update.JobID(), suite.update.jobID)
 }
+
+// TestUpdateCreateWithCanary tests creating an update with a canary phase
+func (suite *UpdateTestSuite) TestUpdateCreateWithCanary() {
+	instancesAdded := []uint32{3}
+	instancesUpdated := []uint32{0, 1, 2}
+
+	workflowType := models.WorkflowType_UPDATE
+	updateConfig := &pbupdate.UpdateConfig{
+		BatchSize: 10,
+		Canary: &pbupdate.CanaryConfig{
+			InstanceCount:    2,
+			BakeDurationSecs: 60,
+		},
+	}
+
+	prevConfig := &pbjob.JobConfig{
+		ChangeLog: &peloton.ChangeLog{Version: 1},
+	}
+	jobConfig := &pbjob.JobConfig{
+		ChangeLog: &peloton.ChangeLog{Version: 2},
+	}
+	expectedCanaryStatus := &pbupdate.CanaryStatus{
+		State:     pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD,
+		Instances: []uint32{0, 1},
+	}
+
+	suite.updateStore.EXPECT().
+		AddJobUpdateEvent(
+			gomock.Any(),
+			gomock.Any(),
+			workflowType,
+			pbupdate.State_INITIALIZED).
+		Return(nil)
+
+	suite.updateStore.EXPECT().
+		AddJobCanaryEvent(
+			gomock.Any(),
+			suite.updateID,
+			workflowType,
+			pbupdate.State_INITIALIZED,
+			pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD).
+		Return(nil)
+
+	suite.updateStore.EXPECT().
+		CreateUpdate(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, updateInfo *models.UpdateModel) {
+			suite.Equal(expectedCanaryStatus, updateInfo.GetCanaryStatus())
+		}).
+		Return(nil)
+
+	suite.updateStore.EXPECT().
+		AddWorkflowEvent(
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+			workflowType,
+			pbupdate.State_INITIALIZED).
+		Return(nil).
+		Times(len(instancesAdded) + len(instancesUpdated))
+
+	suite.NoError(suite.update.Create(
+		context.Background(),
+		suite.jobID,
+		jobConfig,
+		prevConfig,
+		&models.ConfigAddOn{},
+		instancesAdded,
+		instancesUpdated,
+		nil,
+		workflowType,
+		updateConfig,
+		nil,
+	))
+	suite.Equal(expectedCanaryStatus, suite.update.GetCanaryStatus())
+}
+
+// TestNewCanaryStatus tests picking the canary instances for a new update
+func (suite *UpdateTestSuite) TestNewCanaryStatus() {
+	canaryConfig := &pbupdate.UpdateConfig{
+		Canary: &pbupdate.CanaryConfig{InstanceCount: 3},
+	}
+
+	suite.Nil(newCanaryStatus(
+		models.WorkflowType_UPDATE,
+		&pbupdate.UpdateConfig{},
+		[]uint32{2},
+		[]uint32{0, 1},
+	))
+	suite.Nil(newCanaryStatus(
+		models.WorkflowType_RESTART,
+		canaryConfig,
+		nil,
+		[]uint32{0, 1},
+	))
+	suite.Equal([]uint32{0, 1, 4}, newCanaryStatus(
+		models.WorkflowType_UPDATE,
+		canaryConfig,
+		[]uint32{4, 5},
+		[]uint32{0, 1},
+	).GetInstances())
+	suite.Equal([]uint32{4}, newCanaryStatus(
+		models.WorkflowType_UPDATE,
+		canaryConfig,
+		[]uint32{4},
+		nil,
+	).GetInstances())
+}
+
+// TestWriteCanaryProgress tests writing the progress of the canary phase
+func (suite *UpdateTestSuite) TestWriteCanaryProgress() {
+	suite.update.state = pbupdate.State_ROLLING_FORWARD
+	suite.update.workflowType = models.WorkflowType_UPDATE
+	suite.update.instancesCurrent = []uint32{0}
+	suite.update.canaryStatus = &pbupdate.CanaryStatus{
+		State:     pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD,
+		Instances: []uint32{0},
+	}
+	canaryStatus := &pbupdate.CanaryStatus{
+		State:         pbupdate.CanaryState_CANARY_STATE_BAKING,
+		Instances:     []uint32{0},
+		BakeStartTime: "2019-01-01T00:00:00Z",
+	}
+
+	suite.updateStore.EXPECT().
+		AddJobCanaryEvent(
+			gomock.Any(),
+			suite.updateID,
+			models.WorkflowType_UPDATE,
+			pbupdate.State_ROLLING_FORWARD,
+			pbupdate.CanaryState_CANARY_STATE_BAKING).
+		Return(nil)
+
+	suite.updateStore.EXPECT().
+		WriteUpdateProgress(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, updateModel *models.UpdateModel) {
+			suite.Equal(pbupdate.State_ROLLING_FORWARD, updateModel.GetState())
+			suite.Equal([]uint32{0}, updateModel.GetInstancesCurrent())
+			suite.Equal(canaryStatus, updateModel.GetCanaryStatus())
+		}).
+		Return(nil)
+
+	suite.NoError(suite.update.WriteCanaryProgress(
+		context.Background(), canaryStatus))
+	suite.Equal(canaryStatus, suite.update.GetCanaryStatus())
+}
+
+// TestWriteCanaryProgressDBError tests the failure to write the progress
+// of the canary phase
+func (suite *UpdateTestSuite) TestWriteCanaryProgressDBError() {
+	suite.update.state = pbupdate.State_ROLLING_FORWARD
+	suite.update.workflowType = models.WorkflowType_UPDATE
+	suite.update.canaryStatus = &pbupdate.CanaryStatus{
+		State: pbupdate.CanaryState_CANARY_STATE_BAKING,
+	}
+
+	suite.updateStore.EXPECT().
+		WriteUpdateProgress(gomock.Any(), gomock.Any()).
+		Return(yarpcerrors.UnavailableErrorf("test error"))
+
+	suite.Error(suite.update.WriteCanaryProgress(
+		context.Background(),
+		&pbupdate.CanaryStatus{
+			State: pbupdate.CanaryState_CANARY_STATE_BAKING,
+		}))
+	suite.Equal(pbupdate.State_INVALID, suite.update.state)
+	suite.Nil(suite.update.GetCanaryStatus())
+}
+
+// TestWriteCanaryProgressTerminatedUpdate tests that the progress of the
+// canary phase is not written for a terminated update
+func (suite *UpdateTestSuite) TestWriteCanaryProgressTerminatedUpdate() {
+	suite.update.state = pbupdate.State_ABORTED
+
+	suite.NoError(suite.update.WriteCanaryProgress(
+		context.Background(),
+		&pbupdate.CanaryStatus{
+			State: pbupdate.CanaryState_CANARY_STATE_FAILED,
+		}))
+	suite.Nil(suite.update.GetCanaryStatus())
+}
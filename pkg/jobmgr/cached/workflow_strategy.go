@@ -22,6 +22,7 @@ import (
 
 	"github.com/uber/peloton/pkg/common/util"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	taskutil "github.com/uber/peloton/pkg/jobmgr/util/task"
 	updateutil "github.com/uber/peloton/pkg/jobmgr/util/update"
 )
 
@@ -84,11 +85,13 @@ func (s *updateStrategy) IsInstanceComplete(desiredConfigVersion uint64, runtime
 	// 1. runtime desired configuration is set to desiredConfigVersion
 	// 2. runtime configuration is set to desired configuration
 	// 3. healthy state is DISABLED or HEALTHY
+	// 4. the task is ready
 	if runtime.GetState() == pbtask.TaskState_RUNNING {
 		return runtime.GetDesiredConfigVersion() == desiredConfigVersion &&
 			runtime.GetConfigVersion() == runtime.GetDesiredConfigVersion() &&
 			(runtime.GetHealthy() == pbtask.HealthState_DISABLED ||
-				runtime.GetHealthy() == pbtask.HealthState_HEALTHY)
+				runtime.GetHealthy() == pbtask.HealthState_HEALTHY) &&
+			taskutil.IsTaskReady(runtime)
 	}
 
 	// for a terminated task, update is completed if:
@@ -253,7 +256,7 @@ func (s *stopStrategy) GetRuntimeDiff(jobConfig *pbjob.JobConfig) jobmgrcommon.R
 // TODO: reuse the function in jobmgr/util, now it would create import cycle.
 func getDefaultTaskGoalState(jobType pbjob.JobType) pbtask.TaskState {
 	switch jobType {
-	case pbjob.JobType_SERVICE:
+	case pbjob.JobType_SERVICE, pbjob.JobType_DAEMON:
 		return pbtask.TaskState_RUNNING
 
 	default:
@@ -77,6 +77,30 @@ func TestUpdateStrategyIsInstanceComplete(t *testing.T) {
 			desiredConfigVersion: 2,
 			completed:            true,
 		},
+		{
+			taskRuntime: &pbtask.RuntimeInfo{
+				State:                pbtask.TaskState_RUNNING,
+				GoalState:            pbtask.TaskState_RUNNING,
+				ConfigVersion:        2,
+				DesiredConfigVersion: 2,
+				Healthy:              pbtask.HealthState_HEALTHY,
+				Readiness:            pbtask.HealthState_HEALTH_UNKNOWN,
+			},
+			desiredConfigVersion: 2,
+			completed:            false,
+		},
+		{
+			taskRuntime: &pbtask.RuntimeInfo{
+				State:                pbtask.TaskState_RUNNING,
+				GoalState:            pbtask.TaskState_RUNNING,
+				ConfigVersion:        2,
+				DesiredConfigVersion: 2,
+				Healthy:              pbtask.HealthState_DISABLED,
+				Readiness:            pbtask.HealthState_HEALTHY,
+			},
+			desiredConfigVersion: 2,
+			completed:            true,
+		},
 		{
 			taskRuntime: &pbtask.RuntimeInfo{
 				State:                pbtask.TaskState_PENDING,
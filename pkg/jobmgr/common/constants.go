@@ -20,6 +20,7 @@ const (
 	AgentIDField              = "AgentID"
 	CompletionTimeField       = "CompletionTime"
 	ConfigVersionField        = "ConfigVersion"
+	ContainersField           = "Containers"
 	DesiredConfigVersionField = "DesiredConfigVersion"
 	DesiredHostField          = "DesiredHost"
 	DesiredMesosTaskIDField   = "DesiredMesosTaskId"
@@ -31,6 +32,7 @@ const (
 	MessageField              = "Message"
 	PortsField                = "Ports"
 	PrevMesosTaskIDField      = "PrevMesosTaskId"
+	ReadinessField            = "Readiness"
 	ReasonField               = "Reason"
 	ResourceUsageField        = "ResourceUsage"
 	RevisionField             = "Revision"
@@ -17,12 +17,16 @@ package jobmgr
 import (
 	"time"
 
+	"github.com/uber/peloton/pkg/jobmgr/cron"
+	"github.com/uber/peloton/pkg/jobmgr/daemon"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+	"github.com/uber/peloton/pkg/jobmgr/rebalancer"
 	"github.com/uber/peloton/pkg/jobmgr/task/deadline"
 	"github.com/uber/peloton/pkg/jobmgr/task/placement"
 	"github.com/uber/peloton/pkg/jobmgr/task/preemptor"
 	"github.com/uber/peloton/pkg/jobmgr/watchsvc"
+	"github.com/uber/peloton/pkg/jobmgr/workflow"
 )
 
 // Config is JobManager specific configuration
@@ -50,6 +54,18 @@ type Config struct {
 
 	Deadline deadline.Config `yaml:"deadline"`
 
+	// Daemon job controller specific config
+	Daemon daemon.Config `yaml:"daemon"`
+
+	// Cron job controller specific config
+	Cron cron.Config `yaml:"cron"`
+
+	// Rebalancer specific config
+	Rebalancer rebalancer.Config `yaml:"rebalancer"`
+
+	// Workflow controller specific config
+	Workflow workflow.Config `yaml:"workflow"`
+
 	// Job service specific configuration
 	JobSvcCfg jobsvc.Config `yaml:"job_service"`
 
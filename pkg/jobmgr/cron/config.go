@@ -0,0 +1,31 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"time"
+)
+
+// Config is cron job controller specific config
+type Config struct {
+	// SchedulePeriod is the period to check for the cron jobs
+	// which are due to run. The cron jobs are not scheduled if it
+	// is not set.
+	SchedulePeriod time.Duration `yaml:"schedule_period"`
+
+	// MaxRunHistory is the maximum number of runs recorded for each
+	// cron job, the older runs are removed. All the runs are kept if 0.
+	MaxRunHistory int `yaml:"max_run_history"`
+}
@@ -0,0 +1,451 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
+	"github.com/uber/peloton/pkg/storage"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// timeout for the calls made to schedule the cron jobs
+	_defaultScheduleTimeout = 30 * time.Second
+
+	_runSkippedMessage = "Previous run is still active"
+	_runKilledMessage  = "Killed by the next run of the cron job"
+)
+
+// Controller launches the runs of the cron jobs. Each run of a cron job
+// creates a new batch job from the job template of the cron job.
+type Controller interface {
+	// Schedule launches a run of each cron job whose scheduled time
+	// has passed since its last run, applying its collision policy
+	// if the last run is still active. Runs missed while no job manager
+	// was leader are coalesced into a single run.
+	Schedule()
+
+	// StartRun launches a run of a cron job immediately, irrespective
+	// of its schedule and collision policy, and returns the ID of the
+	// batch job created for the run.
+	StartRun(
+		ctx context.Context,
+		name string,
+	) (*v1alphapeloton.JobID, error)
+
+	// NextScheduledTime returns the time of the next scheduled run of
+	// the cron job, or a zero time if the schedule never matches.
+	NextScheduledTime(cronJob *ormobjects.CronJobObject) (time.Time, error)
+}
+
+// controller implements the Controller interface
+type controller struct {
+	jobStore        storage.JobStore
+	cronJobOps      ormobjects.CronJobOps
+	cronJobRunOps   ormobjects.CronJobRunOps
+	jobFactory      cached.JobFactory
+	goalStateDriver goalstate.Driver
+	respoolClient   respool.ResourceManagerYARPCClient
+	config          *Config
+	metrics         *Metrics
+}
+
+// New creates a cron job controller
+func New(
+	d *yarpc.Dispatcher,
+	jobStore storage.JobStore,
+	ormStore *ormobjects.Store,
+	jobFactory cached.JobFactory,
+	goalStateDriver goalstate.Driver,
+	config *Config,
+	parent tally.Scope,
+) Controller {
+	return &controller{
+		jobStore:        jobStore,
+		cronJobOps:      ormobjects.NewCronJobOps(ormStore),
+		cronJobRunOps:   ormobjects.NewCronJobRunOps(ormStore),
+		jobFactory:      jobFactory,
+		goalStateDriver: goalStateDriver,
+		respoolClient: respool.NewResourceManagerYARPCClient(
+			d.ClientConfig(common.PelotonResourceManager)),
+		config:  config,
+		metrics: NewMetrics(parent.SubScope("jobmgr").SubScope("cron")),
+	}
+}
+
+// Schedule launches the runs of all the cron jobs which are due
+func (c *controller) Schedule() {
+	ctx, cancelFunc := context.WithTimeout(
+		context.Background(),
+		_defaultScheduleTimeout)
+	cronJobs, err := c.cronJobOps.GetAll(ctx)
+	cancelFunc()
+	if err != nil {
+		log.WithError(err).
+			Error("failed to get cron jobs to schedule")
+		c.metrics.ScheduleFail.Inc(1)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, cronJob := range cronJobs {
+		ctx, cancelFunc := context.WithTimeout(
+			context.Background(),
+			_defaultScheduleTimeout)
+		err := c.scheduleCronJob(ctx, cronJob, now)
+		cancelFunc()
+		if err != nil {
+			log.WithError(err).
+				WithField("cron_job", cronJob.Name).
+				Error("failed to schedule cron job")
+			c.metrics.CronJobRunFail.Inc(1)
+		}
+	}
+	c.metrics.Schedule.Inc(1)
+}
+
+// StartRun launches a run of a cron job on demand
+func (c *controller) StartRun(
+	ctx context.Context,
+	name string,
+) (*v1alphapeloton.JobID, error) {
+	cronJob, err := c.cronJobOps.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := cronJob.GetSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	// a manual run does not move the schedule forward
+	jobID, err := c.launchRun(
+		ctx,
+		cronJob,
+		spec,
+		&cron.CronJobRun{Manual: true},
+		cronJob.LastScheduledTime)
+	if err != nil {
+		return nil, err
+	}
+	return &v1alphapeloton.JobID{Value: jobID.GetValue()}, nil
+}
+
+// NextScheduledTime returns the time of the next run of a cron job
+func (c *controller) NextScheduledTime(
+	cronJob *ormobjects.CronJobObject,
+) (time.Time, error) {
+	spec, err := cronJob.GetSpec()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	schedule, err := ParseSchedule(spec.GetSchedule())
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(getLastScheduledTime(cronJob)), nil
+}
+
+// getLastScheduledTime returns the time from which the next run of a cron
+// job is scheduled, which is its creation time if it has never run
+func getLastScheduledTime(cronJob *ormobjects.CronJobObject) time.Time {
+	if cronJob.LastScheduledTime.IsZero() {
+		return cronJob.CreationTime
+	}
+	return cronJob.LastScheduledTime
+}
+
+// scheduleCronJob launches a run of a cron job if it is due
+func (c *controller) scheduleCronJob(
+	ctx context.Context,
+	cronJob *ormobjects.CronJobObject,
+	now time.Time,
+) error {
+	spec, err := cronJob.GetSpec()
+	if err != nil {
+		return err
+	}
+
+	schedule, err := ParseSchedule(spec.GetSchedule())
+	if err != nil {
+		return err
+	}
+
+	scheduledTime := getDueTime(schedule, getLastScheduledTime(cronJob), now)
+	if scheduledTime.IsZero() {
+		return nil
+	}
+
+	run := &cron.CronJobRun{
+		ScheduledTime: scheduledTime.Format(time.RFC3339),
+	}
+
+	active, err := c.isRunActive(ctx, cronJob.LastRunJobID)
+	if err != nil {
+		return err
+	}
+
+	if active {
+		switch spec.GetCollisionPolicy() {
+		case cron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW:
+			run.State = cron.CronJobRunState_CRON_JOB_RUN_STATE_SKIPPED
+			run.Message = _runSkippedMessage
+			if err := c.recordRun(ctx, spec.GetName(), run); err != nil {
+				return err
+			}
+			c.metrics.RunSkipped.Inc(1)
+
+			log.WithFields(log.Fields{
+				"cron_job":       spec.GetName(),
+				"scheduled_time": run.GetScheduledTime(),
+				"active_job_id":  cronJob.LastRunJobID,
+			}).Info("skipped run of cron job")
+
+			return c.cronJobOps.UpdateLastRun(
+				ctx,
+				spec.GetName(),
+				scheduledTime,
+				&v1alphapeloton.JobID{Value: cronJob.LastRunJobID})
+
+		case cron.CollisionPolicy_COLLISION_POLICY_ALLOW_CONCURRENT:
+			// launch the new run alongside the active run
+
+		default:
+			// kill the previous run like Aurora does by default
+			if err := c.killRun(ctx, cronJob.LastRunJobID); err != nil {
+				c.metrics.RunKillFail.Inc(1)
+				return err
+			}
+			c.metrics.RunKilled.Inc(1)
+		}
+	}
+
+	_, err = c.launchRun(ctx, cronJob, spec, run, scheduledTime)
+	return err
+}
+
+// getDueTime returns the latest time matching the schedule after the
+// last scheduled time and up to now, or a zero time if no run is due
+func getDueTime(
+	schedule *Schedule,
+	lastScheduledTime time.Time,
+	now time.Time,
+) time.Time {
+	var due time.Time
+	for next := schedule.Next(lastScheduledTime); !next.IsZero() &&
+		!next.After(now); next = schedule.Next(next) {
+		due = next
+	}
+	return due
+}
+
+// isRunActive returns true if the batch job of a run exists
+// and has not reached a terminal state yet
+func (c *controller) isRunActive(ctx context.Context, jobID string) (bool, error) {
+	if len(jobID) == 0 {
+		return false, nil
+	}
+
+	runtime, err := c.jobStore.GetJobRuntime(ctx, jobID)
+	if err != nil {
+		if yarpcerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return !util.IsPelotonJobStateTerminal(runtime.GetState()), nil
+}
+
+// killRun sets the goal state of the batch job of a run to KILLED
+func (c *controller) killRun(ctx context.Context, jobID string) error {
+	pelotonJobID := &peloton.JobID{Value: jobID}
+	cachedJob := c.jobFactory.AddJob(pelotonJobID)
+
+	killed, err := jobutil.KillJob(ctx, cachedJob, _runKilledMessage)
+	if err != nil {
+		return err
+	}
+	if killed {
+		c.goalStateDriver.EnqueueJob(pelotonJobID, time.Now())
+	}
+	return nil
+}
+
+// launchRun creates the batch job of a run, and records the run
+// as the last run of the cron job
+func (c *controller) launchRun(
+	ctx context.Context,
+	cronJob *ormobjects.CronJobObject,
+	spec *cron.CronJobSpec,
+	run *cron.CronJobRun,
+	scheduledTime time.Time,
+) (*peloton.JobID, error) {
+	jobID, err := c.createJob(ctx, spec, cronJob.CreatedBy)
+	if err != nil {
+		c.metrics.RunLaunchFail.Inc(1)
+		run.State = cron.CronJobRunState_CRON_JOB_RUN_STATE_FAILED
+		run.Message = err.Error()
+	} else {
+		c.metrics.RunLaunched.Inc(1)
+		run.State = cron.CronJobRunState_CRON_JOB_RUN_STATE_LAUNCHED
+		run.JobId = &v1alphapeloton.JobID{Value: jobID.GetValue()}
+	}
+
+	log.WithFields(log.Fields{
+		"cron_job":       spec.GetName(),
+		"scheduled_time": run.GetScheduledTime(),
+		"manual":         run.GetManual(),
+		"job_id":         jobID.GetValue(),
+		"state":          run.GetState().String(),
+	}).Info("processed run of cron job")
+
+	if recordErr := c.recordRun(ctx, spec.GetName(), run); recordErr != nil {
+		return nil, recordErr
+	}
+
+	if err != nil {
+		// a failed scheduled run still moves the schedule forward,
+		// so that the cron job does not retry the same run in a loop
+		if !run.GetManual() {
+			if updateErr := c.cronJobOps.UpdateLastRun(
+				ctx,
+				spec.GetName(),
+				scheduledTime,
+				&v1alphapeloton.JobID{Value: cronJob.LastRunJobID},
+			); updateErr != nil {
+				return nil, updateErr
+			}
+		}
+		return nil, err
+	}
+
+	if err := c.cronJobOps.UpdateLastRun(
+		ctx,
+		spec.GetName(),
+		scheduledTime,
+		run.GetJobId()); err != nil {
+		return nil, err
+	}
+	return jobID, nil
+}
+
+// createJob creates a batch job from the job template of a cron job
+// on behalf of the user who created the cron job
+func (c *controller) createJob(
+	ctx context.Context,
+	spec *cron.CronJobSpec,
+	createdBy string,
+) (*peloton.JobID, error) {
+	jobConfig, err := handlerutil.ConvertJobSpecToJobConfig(spec.GetJobSpec())
+	if err != nil {
+		return nil, err
+	}
+	jobConfig.Type = job.JobType_BATCH
+
+	respoolPath, err := c.getResourcePoolPath(ctx, jobConfig.GetRespoolID())
+	if err != nil {
+		return nil, err
+	}
+
+	jobID := &peloton.JobID{Value: uuid.New()}
+	cachedJob := c.jobFactory.AddJob(jobID)
+
+	configAddOn := &models.ConfigAddOn{
+		SystemLabels: jobutil.ConstructSystemLabels(
+			jobConfig,
+			respoolPath.GetValue()),
+	}
+	err = cachedJob.Create(ctx, jobConfig, configAddOn, createdBy)
+	// if err is not nil, still enqueue to goal state engine,
+	// because job may be partially created. Goal state engine
+	// knows if the job can be recovered
+	c.goalStateDriver.EnqueueJob(jobID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return jobID, nil
+}
+
+// getResourcePoolPath returns the path of the resource pool of a job
+func (c *controller) getResourcePoolPath(
+	ctx context.Context,
+	respoolID *peloton.ResourcePoolID,
+) (*respool.ResourcePoolPath, error) {
+	resp, err := c.respoolClient.GetResourcePool(
+		ctx,
+		&respool.GetRequest{Id: respoolID})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.GetError() != nil || resp.GetPoolinfo() == nil {
+		return nil, fmt.Errorf(
+			"resource pool %s not found", respoolID.GetValue())
+	}
+	return resp.GetPoolinfo().GetPath(), nil
+}
+
+// recordRun adds a run to the run history of a cron job,
+// and removes the runs beyond the maximum run history
+func (c *controller) recordRun(
+	ctx context.Context,
+	name string,
+	run *cron.CronJobRun,
+) error {
+	if err := c.cronJobRunOps.Create(ctx, name, run); err != nil {
+		return err
+	}
+
+	if c.config.MaxRunHistory <= 0 {
+		return nil
+	}
+
+	runs, err := c.cronJobRunOps.GetAll(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	// runs are sorted by descending run time
+	for i := c.config.MaxRunHistory; i < len(runs); i++ {
+		if err := c.cronJobRunOps.Delete(ctx, name, runs[i].RunID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,476 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
+	storage_mocks "github.com/uber/peloton/pkg/storage/mocks"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_testCronJobName = "test-cron-job"
+	_testRespoolID   = "respool-1"
+	_testCreatedBy   = "user1"
+)
+
+type CronControllerTestSuite struct {
+	suite.Suite
+
+	ctrl            *gomock.Controller
+	controller      *controller
+	mockJobStore    *storage_mocks.MockJobStore
+	cronJobOps      *objectmocks.MockCronJobOps
+	cronJobRunOps   *objectmocks.MockCronJobRunOps
+	jobFactory      *cachedmocks.MockJobFactory
+	goalStateDriver *goalstatemocks.MockDriver
+	respoolClient   *respoolmocks.MockResourceManagerYARPCClient
+
+	lastJobID *peloton.JobID
+	cachedJob *cachedmocks.MockJob
+	now       time.Time
+}
+
+func TestCronController(t *testing.T) {
+	suite.Run(t, new(CronControllerTestSuite))
+}
+
+func (suite *CronControllerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.mockJobStore = storage_mocks.NewMockJobStore(suite.ctrl)
+	suite.cronJobOps = objectmocks.NewMockCronJobOps(suite.ctrl)
+	suite.cronJobRunOps = objectmocks.NewMockCronJobRunOps(suite.ctrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.ctrl)
+	suite.respoolClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.controller = &controller{
+		jobStore:        suite.mockJobStore,
+		cronJobOps:      suite.cronJobOps,
+		cronJobRunOps:   suite.cronJobRunOps,
+		jobFactory:      suite.jobFactory,
+		goalStateDriver: suite.goalStateDriver,
+		respoolClient:   suite.respoolClient,
+		config:          &Config{},
+		metrics:         NewMetrics(tally.NoopScope),
+	}
+
+	suite.lastJobID = &peloton.JobID{Value: "bca875f5-322a-4439-b0c9-63e3cf9f982e"}
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.now = time.Now().UTC()
+}
+
+func (suite *CronControllerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// createCronJob returns a cron job object running every minute, whose
+// last run was scheduled at the given time
+func (suite *CronControllerTestSuite) createCronJob(
+	policy cron.CollisionPolicy,
+	lastScheduledTime time.Time,
+	lastRunJobID string,
+) *ormobjects.CronJobObject {
+	spec := &cron.CronJobSpec{
+		Name:            _testCronJobName,
+		Schedule:        "* * * * *",
+		CollisionPolicy: policy,
+		JobSpec: &stateless.JobSpec{
+			Name:          _testCronJobName,
+			InstanceCount: 3,
+			RespoolId:     &v1alphapeloton.ResourcePoolID{Value: _testRespoolID},
+		},
+	}
+	specBuffer, err := proto.Marshal(spec)
+	suite.NoError(err)
+
+	return &ormobjects.CronJobObject{
+		Name:              _testCronJobName,
+		Spec:              specBuffer,
+		CreationTime:      suite.now.Add(-time.Hour),
+		LastScheduledTime: lastScheduledTime,
+		LastRunJobID:      lastRunJobID,
+		CreatedBy:         _testCreatedBy,
+	}
+}
+
+// expectLastRunState sets up the state of the last run of the cron job
+func (suite *CronControllerTestSuite) expectLastRunState(state job.JobState) {
+	suite.mockJobStore.EXPECT().
+		GetJobRuntime(gomock.Any(), suite.lastJobID.GetValue()).
+		Return(&job.RuntimeInfo{State: state}, nil)
+}
+
+// expectJobCreate sets up the creation of the batch job of a run
+func (suite *CronControllerTestSuite) expectJobCreate(createErr error) {
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{
+			Id: &peloton.ResourcePoolID{Value: _testRespoolID},
+		}).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Id:   &peloton.ResourcePoolID{Value: _testRespoolID},
+				Path: &respool.ResourcePoolPath{Value: "/respool-1"},
+			},
+		}, nil)
+
+	suite.jobFactory.EXPECT().
+		AddJob(gomock.Any()).
+		Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any(), _testCreatedBy).
+		Do(func(
+			_ context.Context,
+			config *job.JobConfig,
+			configAddOn *models.ConfigAddOn,
+			_ string) {
+			suite.Equal(job.JobType_BATCH, config.GetType())
+			suite.Equal(_testCronJobName, config.GetName())
+			suite.Equal(uint32(3), config.GetInstanceCount())
+			suite.NotEmpty(configAddOn.GetSystemLabels())
+		}).
+		Return(createErr)
+
+	suite.goalStateDriver.EXPECT().
+		EnqueueJob(gomock.Any(), gomock.Any())
+}
+
+// expectRun sets up the recording of a run and of the last run
+func (suite *CronControllerTestSuite) expectRun(
+	state cron.CronJobRunState,
+	scheduledTime time.Time,
+	lastRunJobID string,
+) {
+	suite.cronJobRunOps.EXPECT().
+		Create(gomock.Any(), _testCronJobName, gomock.Any()).
+		Do(func(_ context.Context, _ string, run *cron.CronJobRun) {
+			suite.Equal(state, run.GetState())
+		}).
+		Return(nil)
+
+	suite.cronJobOps.EXPECT().
+		UpdateLastRun(
+			gomock.Any(),
+			_testCronJobName,
+			scheduledTime,
+			gomock.Any()).
+		Do(func(
+			_ context.Context,
+			_ string,
+			_ time.Time,
+			jobID *v1alphapeloton.JobID) {
+			if len(lastRunJobID) != 0 {
+				suite.Equal(lastRunJobID, jobID.GetValue())
+			} else {
+				suite.NotEmpty(jobID.GetValue())
+			}
+		}).
+		Return(nil)
+}
+
+// TestScheduleNotDue tests that a cron job whose next
+// scheduled time has not passed yet is not run
+func (suite *CronControllerTestSuite) TestScheduleNotDue() {
+	cronJob := suite.createCronJob(
+		cron.CollisionPolicy_COLLISION_POLICY_KILL_EXISTING,
+		suite.now.Add(time.Minute),
+		"")
+
+	suite.cronJobOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*ormobjects.CronJobObject{cronJob}, nil)
+
+	suite.controller.Schedule()
+}
+
+// TestScheduleGetAllFail tests failing to read the cron jobs
+func (suite *CronControllerTestSuite) TestScheduleGetAllFail() {
+	suite.cronJobOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	suite.controller.Schedule()
+}
+
+// TestScheduleLaunchRun tests launching a run of a cron job whose last
+// run is terminated, where the missed runs are coalesced into one run
+func (suite *CronControllerTestSuite) TestScheduleLaunchRun() {
+	cronJob := suite.createCronJob(
+		cron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW,
+		suite.now.Add(-10*time.Minute),
+		suite.lastJobID.GetValue())
+
+	suite.expectLastRunState(job.JobState_SUCCEEDED)
+	suite.expectJobCreate(nil)
+	suite.expectRun(
+		cron.CronJobRunState_CRON_JOB_RUN_STATE_LAUNCHED,
+		suite.now.Truncate(time.Minute),
+		"")
+
+	suite.NoError(
+		suite.controller.scheduleCronJob(context.Background(), cronJob, suite.now))
+}
+
+// TestScheduleFirstRun tests launching the first run of a cron job,
+// which is scheduled from the creation time of the cron job
+func (suite *CronControllerTestSuite) TestScheduleFirstRun() {
+	cronJob := suite.createCronJob(
+		cron.CollisionPolicy_COLLISION_POLICY_KILL_EXISTING,
+		time.Time{},
+		"")
+
+	suite.expectJobCreate(nil)
+	suite.expectRun(
+		cron.CronJobRunState_CRON_JOB_RUN_STATE_LAUNCHED,
+		suite.now.Truncate(time.Minute),
+		"")
+
+	suite.NoError(
+		suite.controller.scheduleCronJob(context.Background(), cronJob, suite.now))
+}
+
+// TestScheduleLastRunNotFound tests that a deleted
+// last run is not considered to be active
+func (suite *CronControllerTestSuite) TestScheduleLastRunNotFound() {
+	cronJob := suite.createCronJob(
+		cron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW,
+		suite.now.Add(-time.Minute),
+		suite.lastJobID.GetValue())
+
+	suite.mockJobStore.EXPECT().
+		GetJobRuntime(gomock.Any(), suite.lastJobID.GetValue()).
+		Return(nil, yarpcerrors.NotFoundErrorf("not found"))
+	suite.expectJobCreate(nil)
+	suite.expectRun(
+		cron.CronJobRunState_CRON_JOB_RUN_STATE_LAUNCHED,
+		suite.now.Truncate(time.Minute),
+		"")
+
+	suite.NoError(
+		suite.controller.scheduleCronJob(context.Background(), cronJob, suite.now))
+}
+
+// TestScheduleSkipNew tests skipping a run while the last run is active
+func (suite *CronControllerTestSuite) TestScheduleSkipNew() {
+	cronJob := suite.createCronJob(
+		cron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW,
+		suite.now.Add(-time.Minute),
+		suite.lastJobID.GetValue())
+
+	suite.expectLastRunState(job.JobState_RUNNING)
+	suite.expectRun(
+		cron.CronJobRunState_CRON_JOB_RUN_STATE_SKIPPED,
+		suite.now.Truncate(time.Minute),
+		suite.lastJobID.GetValue())
+
+	suite.NoError(
+		suite.controller.scheduleCronJob(context.Background(), cronJob, suite.now))
+}
+
+// TestScheduleKillExisting tests killing the active
+// last run before launching the new run
+func (suite *CronControllerTestSuite) TestScheduleKillExisting() {
+	cronJob := suite.createCronJob(
+		cron.CollisionPolicy_COLLISION_POLICY_KILL_EXISTING,
+		suite.now.Add(-time.Minute),
+		suite.lastJobID.GetValue())
+
+	lastCachedJob := cachedmocks.NewMockJob(suite.ctrl)
+
+	suite.expectLastRunState(job.JobState_RUNNING)
+	gomock.InOrder(
+		suite.jobFactory.EXPECT().
+			AddJob(suite.lastJobID).
+			Return(lastCachedJob),
+		lastCachedJob.EXPECT().
+			GetRuntime(gomock.Any()).
+			Return(&job.RuntimeInfo{
+				State:     job.JobState_RUNNING,
+				GoalState: job.JobState_SUCCEEDED,
+			}, nil),
+		lastCachedJob.EXPECT().
+			CompareAndSetRuntime(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, runtime *job.RuntimeInfo) {
+				suite.Equal(job.JobState_KILLED, runtime.GetGoalState())
+				suite.Equal(uint64(1), runtime.GetDesiredStateVersion())
+			}).
+			Return(&job.RuntimeInfo{}, nil),
+		suite.goalStateDriver.EXPECT().
+			EnqueueJob(suite.lastJobID, gomock.Any()),
+	)
+	suite.expectJobCreate(nil)
+	suite.expectRun(
+		cron.CronJobRunState_CRON_JOB_RUN_STATE_LAUNCHED,
+		suite.now.Truncate(time.Minute),
+		"")
+
+	suite.NoError(
+		suite.controller.scheduleCronJob(context.Background(), cronJob, suite.now))
+}
+
+// TestScheduleKillExistingFail tests that the run is not launched, and
+// the schedule does not move forward, if the last run cannot be killed
+func (suite *CronControllerTestSuite) TestScheduleKillExistingFail() {
+	cronJob := suite.createCronJob(
+		cron.CollisionPolicy_COLLISION_POLICY_KILL_EXISTING,
+		suite.now.Add(-time.Minute),
+		suite.lastJobID.GetValue())
+
+	lastCachedJob := cachedmocks.NewMockJob(suite.ctrl)
+
+	suite.expectLastRunState(job.JobState_RUNNING)
+	suite.jobFactory.EXPECT().
+		AddJob(suite.lastJobID).
+		Return(lastCachedJob)
+	lastCachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	suite.Error(
+		suite.controller.scheduleCronJob(context.Background(), cronJob, suite.now))
+}
+
+// TestScheduleAllowConcurrent tests launching a new
+// run alongside the active last run
+func (suite *CronControllerTestSuite) TestScheduleAllowConcurrent() {
+	cronJob := suite.createCronJob(
+		cron.CollisionPolicy_COLLISION_POLICY_ALLOW_CONCURRENT,
+		suite.now.Add(-time.Minute),
+		suite.lastJobID.GetValue())
+
+	suite.expectLastRunState(job.JobState_RUNNING)
+	suite.expectJobCreate(nil)
+	suite.expectRun(
+		cron.CronJobRunState_CRON_JOB_RUN_STATE_LAUNCHED,
+		suite.now.Truncate(time.Minute),
+		"")
+
+	suite.NoError(
+		suite.controller.scheduleCronJob(context.Background(), cronJob, suite.now))
+}
+
+// TestScheduleLaunchFail tests that a failed run is recorded and
+// moves the schedule forward, keeping the previous last run
+func (suite *CronControllerTestSuite) TestScheduleLaunchFail() {
+	cronJob := suite.createCronJob(
+		cron.CollisionPolicy_COLLISION_POLICY_ALLOW_CONCURRENT,
+		suite.now.Add(-time.Minute),
+		suite.lastJobID.GetValue())
+
+	suite.expectLastRunState(job.JobState_RUNNING)
+	suite.expectJobCreate(errors.New("test error"))
+	suite.expectRun(
+		cron.CronJobRunState_CRON_JOB_RUN_STATE_FAILED,
+		suite.now.Truncate(time.Minute),
+		suite.lastJobID.GetValue())
+
+	suite.Error(
+		suite.controller.scheduleCronJob(context.Background(), cronJob, suite.now))
+}
+
+// TestStartRun tests launching a run of a cron job on demand
+func (suite *CronControllerTestSuite) TestStartRun() {
+	lastScheduledTime := suite.now.Add(-time.Minute).Truncate(time.Minute)
+	cronJob := suite.createCronJob(
+		cron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW,
+		lastScheduledTime,
+		suite.lastJobID.GetValue())
+
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), _testCronJobName).
+		Return(cronJob, nil)
+	suite.expectJobCreate(nil)
+	suite.expectRun(
+		cron.CronJobRunState_CRON_JOB_RUN_STATE_LAUNCHED,
+		lastScheduledTime,
+		"")
+
+	jobID, err := suite.controller.StartRun(context.Background(), _testCronJobName)
+	suite.NoError(err)
+	suite.NotEmpty(jobID.GetValue())
+}
+
+// TestStartRunNotFound tests starting a run of a cron job which does not exist
+func (suite *CronControllerTestSuite) TestStartRunNotFound() {
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), _testCronJobName).
+		Return(nil, gocql.ErrNotFound)
+
+	_, err := suite.controller.StartRun(context.Background(), _testCronJobName)
+	suite.Equal(gocql.ErrNotFound, err)
+}
+
+// TestRecordRunPrunesHistory tests that the runs beyond
+// the maximum run history are removed
+func (suite *CronControllerTestSuite) TestRecordRunPrunesHistory() {
+	suite.controller.config.MaxRunHistory = 2
+
+	runs := []*ormobjects.CronJobRunObject{
+		{Name: _testCronJobName, RunID: gocql.TimeUUID()},
+		{Name: _testCronJobName, RunID: gocql.TimeUUID()},
+		{Name: _testCronJobName, RunID: gocql.TimeUUID()},
+	}
+
+	suite.cronJobRunOps.EXPECT().
+		Create(gomock.Any(), _testCronJobName, gomock.Any()).
+		Return(nil)
+	suite.cronJobRunOps.EXPECT().
+		GetAll(gomock.Any(), _testCronJobName).
+		Return(runs, nil)
+	suite.cronJobRunOps.EXPECT().
+		Delete(gomock.Any(), _testCronJobName, runs[2].RunID).
+		Return(nil)
+
+	suite.NoError(suite.controller.recordRun(
+		context.Background(),
+		_testCronJobName,
+		&cron.CronJobRun{}))
+}
+
+// TestNextScheduledTime tests computing the next run of a cron job
+func (suite *CronControllerTestSuite) TestNextScheduledTime() {
+	lastScheduledTime := time.Date(2019, 3, 4, 10, 17, 0, 0, time.UTC)
+	cronJob := suite.createCronJob(
+		cron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW,
+		lastScheduledTime,
+		"")
+
+	next, err := suite.controller.NextScheduledTime(cronJob)
+	suite.NoError(err)
+	suite.Equal(lastScheduledTime.Add(time.Minute), next)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the struct containing all the counters that track internal state
+// of cron job controller.
+type Metrics struct {
+	Schedule     tally.Counter
+	ScheduleFail tally.Counter
+
+	RunLaunched    tally.Counter
+	RunLaunchFail  tally.Counter
+	RunSkipped     tally.Counter
+	RunKilled      tally.Counter
+	RunKillFail    tally.Counter
+	CronJobRunFail tally.Counter
+}
+
+// NewMetrics returns a new Metrics struct, with all metrics
+// initialized and rooted at the given tally.Scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	successScope := scope.Tagged(map[string]string{"result": "success"})
+	failScope := scope.Tagged(map[string]string{"result": "fail"})
+
+	return &Metrics{
+		Schedule:       successScope.Counter("schedule"),
+		ScheduleFail:   failScope.Counter("schedule"),
+		RunLaunched:    successScope.Counter("run_launch"),
+		RunLaunchFail:  failScope.Counter("run_launch"),
+		RunSkipped:     scope.Counter("run_skipped"),
+		RunKilled:      successScope.Counter("run_kill"),
+		RunKillFail:    failScope.Counter("run_kill"),
+		CronJobRunFail: failScope.Counter("cron_job_run"),
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// _maxScheduleLookAhead is how far in the future the next run of a
+// schedule is searched for, so that a schedule which never matches
+// (e.g. on February 30th) does not loop forever.
+const _maxScheduleLookAhead = 5 * 366 * 24 * time.Hour
+
+// _predefinedSchedules maps the predefined schedules
+// to their crontab representation
+var _predefinedSchedules = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// scheduleField describes the allowed values of a field of a schedule
+type scheduleField struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	_minuteField = scheduleField{name: "minute", min: 0, max: 59}
+	_hourField   = scheduleField{name: "hour", min: 0, max: 23}
+	_domField    = scheduleField{name: "day of month", min: 1, max: 31}
+	_monthField  = scheduleField{
+		name: "month",
+		min:  1,
+		max:  12,
+		names: map[string]uint{
+			"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+			"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+		},
+	}
+	// both 0 and 7 represent Sunday
+	_dowField = scheduleField{
+		name: "day of week",
+		min:  0,
+		max:  7,
+		names: map[string]uint{
+			"sun": 0, "mon": 1, "tue": 2, "wed": 3,
+			"thu": 4, "fri": 5, "sat": 6,
+		},
+	}
+)
+
+// Schedule is a parsed cron schedule. The schedule is evaluated in UTC.
+type Schedule struct {
+	// bitsets of the matching values of each field
+	minute, hour, dom, month, dow uint64
+
+	// whether the day of month and day of week fields are
+	// unrestricted, which changes how the days are matched
+	domStar, dowStar bool
+}
+
+// ParseSchedule parses a schedule in the crontab format
+// "<minute> <hour> <day of month> <month> <day of week>", or one of
+// the predefined schedules like @daily. Each field is either "*", a
+// value, a range "a-b", or a comma separated list of them, and can be
+// followed by a step "/n". Months and days of week can be given by
+// their three letter name.
+func ParseSchedule(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if predefined, ok := _predefinedSchedules[strings.ToLower(spec)]; ok {
+		spec = predefined
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf(
+			"schedule %q has %d fields instead of 5", spec, len(fields))
+	}
+
+	s := &Schedule{
+		domStar: isStar(fields[2]),
+		dowStar: isStar(fields[4]),
+	}
+
+	var err error
+	if s.minute, err = parseScheduleField(fields[0], _minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseScheduleField(fields[1], _hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseScheduleField(fields[2], _domField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseScheduleField(fields[3], _monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseScheduleField(fields[4], _dowField); err != nil {
+		return nil, err
+	}
+
+	// Sunday can be given as either 0 or 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// isStar returns true if a field matches all its values
+func isStar(expr string) bool {
+	return expr == "*" || expr == "?"
+}
+
+// parseScheduleField parses one field of a schedule, and returns
+// the bitset of its matching values
+func parseScheduleField(expr string, f scheduleField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr := part
+		step := uint(1)
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangeExpr = part[:i]
+			s, err := strconv.ParseUint(part[i+1:], 10, 32)
+			if err != nil || s == 0 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, part)
+			}
+			step = uint(s)
+		}
+
+		var low, high uint
+		switch {
+		case isStar(rangeExpr):
+			low, high = f.min, f.max
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if low, err = parseScheduleValue(bounds[0], f); err != nil {
+				return 0, err
+			}
+			if high, err = parseScheduleValue(bounds[1], f); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range in %s field %q", f.name, part)
+			}
+		default:
+			value, err := parseScheduleValue(rangeExpr, f)
+			if err != nil {
+				return 0, err
+			}
+			low, high = value, value
+			// "a/n" means every n starting at a
+			if step > 1 {
+				high = f.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// parseScheduleValue parses a single value of a field of a schedule
+func parseScheduleValue(expr string, f scheduleField) (uint, error) {
+	if value, ok := f.names[strings.ToLower(expr)]; ok {
+		return value, nil
+	}
+	value, err := strconv.ParseUint(expr, 10, 32)
+	if err != nil || uint(value) < f.min || uint(value) > f.max {
+		return 0, fmt.Errorf(
+			"invalid value %q for %s field, must be between %d and %d",
+			expr, f.name, f.min, f.max)
+	}
+	return uint(value), nil
+}
+
+// Next returns the first time matching the schedule strictly after the
+// given time. A zero time is returned if the schedule does not match
+// any time in the foreseeable future.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	deadline := t.Add(_maxScheduleLookAhead)
+
+	for t.Before(deadline) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay returns true if the day of the given time matches the
+// schedule. If both the day of month and day of week are restricted,
+// the day matches if either of them matches, like in crontab.
+func (s *Schedule) matchDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ScheduleTestSuite struct {
+	suite.Suite
+}
+
+func TestSchedule(t *testing.T) {
+	suite.Run(t, new(ScheduleTestSuite))
+}
+
+// TestParseScheduleInvalid tests parsing invalid schedules
+func (suite *ScheduleTestSuite) TestParseScheduleInvalid() {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"@every",
+	}
+	for _, spec := range specs {
+		_, err := ParseSchedule(spec)
+		suite.Error(err, spec)
+	}
+}
+
+// TestScheduleNext tests computing the next time matching a schedule
+func (suite *ScheduleTestSuite) TestScheduleNext() {
+	// a Monday
+	now := time.Date(2019, 3, 4, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2019, 3, 4, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2019, 3, 4, 10, 30, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2019, 3, 4, 10, 25, 0, 0, time.UTC)},
+		{"0,17 * * * *", time.Date(2019, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{"30 9-11 * * *", time.Date(2019, 3, 4, 10, 30, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2019, 3, 5, 2, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * sun", time.Date(2019, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2019, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * MON-FRI", time.Date(2019, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// day of month or day of week when both are restricted
+		{"0 0 15 * 3", time.Date(2019, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2019, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2019, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2019, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// never matches
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, test := range tests {
+		s, err := ParseSchedule(test.spec)
+		suite.NoError(err, test.spec)
+		suite.Equal(test.expected, s.Next(now), test.spec)
+	}
+}
+
+// TestScheduleNextIsStrictlyAfter tests that the next time of a schedule
+// is strictly after the given time, even if the time matches the schedule
+func (suite *ScheduleTestSuite) TestScheduleNextIsStrictlyAfter() {
+	s, err := ParseSchedule("0 * * * *")
+	suite.NoError(err)
+
+	now := time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)
+	suite.Equal(time.Date(2019, 3, 4, 11, 0, 0, 0, time.UTC), s.Next(now))
+}
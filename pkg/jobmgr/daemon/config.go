@@ -0,0 +1,27 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"time"
+)
+
+// Config is daemon job controller specific config
+type Config struct {
+	// ReconcilePeriod is the period to reconcile the instances of the
+	// daemon jobs with the hosts in the cluster. The daemon jobs are
+	// not reconciled if it is not set.
+	ReconcilePeriod time.Duration `yaml:"reconcile_period"`
+}
@@ -0,0 +1,427 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/constraints"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	jobmgr_task "github.com/uber/peloton/pkg/jobmgr/task"
+	"github.com/uber/peloton/pkg/storage"
+
+	"github.com/golang/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+)
+
+const (
+	// timeout for the calls made to reconcile the hosts or a daemon job
+	_defaultReconcileTimeout = 10 * time.Second
+
+	_instanceAddedMessage   = "Instance added on eligible host"
+	_instanceDrainedMessage = "Instance drained from ineligible host"
+)
+
+var (
+	// the states of the hosts in maintenance,
+	// on which daemon instances should not run
+	_maintenanceHostStates = []hpb.HostState{
+		hpb.HostState_HOST_STATE_DRAINING,
+		hpb.HostState_HOST_STATE_DRAINED,
+		hpb.HostState_HOST_STATE_DOWN,
+	}
+)
+
+// Controller reconciles the instances of the daemon jobs with the hosts
+// in the cluster. A daemon job runs one instance on each host which matches
+// the host constraints of the job, and each instance is pinned to its host
+// through the desired host in the task runtime.
+type Controller interface {
+	// Reconcile adds instances of the daemon jobs on the eligible hosts
+	// which do not run one yet, and drains the instances on the hosts
+	// which are no longer eligible, are in maintenance or have left
+	// the cluster.
+	Reconcile()
+}
+
+// controller implements the Controller interface
+type controller struct {
+	jobStore        storage.JobStore
+	jobFactory      cached.JobFactory
+	goalStateDriver goalstate.Driver
+	hostMgrClient   hostsvc.InternalHostServiceYARPCClient
+	hostClient      host_svc.HostServiceYARPCClient
+	metrics         *Metrics
+}
+
+// New creates a daemon job controller
+func New(
+	d *yarpc.Dispatcher,
+	jobStore storage.JobStore,
+	jobFactory cached.JobFactory,
+	goalStateDriver goalstate.Driver,
+	parent tally.Scope,
+) Controller {
+	return &controller{
+		jobStore:        jobStore,
+		jobFactory:      jobFactory,
+		goalStateDriver: goalStateDriver,
+		hostMgrClient: hostsvc.NewInternalHostServiceYARPCClient(
+			d.ClientConfig(common.PelotonHostManager)),
+		hostClient: host_svc.NewHostServiceYARPCClient(
+			d.ClientConfig(common.PelotonHostManager)),
+		metrics: NewMetrics(parent.SubScope("jobmgr").SubScope("daemon")),
+	}
+}
+
+// Reconcile reconciles all the daemon jobs in cache with the hosts
+// which can run daemon instances
+func (c *controller) Reconcile() {
+	ctx, cancelFunc := context.WithTimeout(
+		context.Background(),
+		_defaultReconcileTimeout)
+	hosts, err := c.getSchedulableHosts(ctx)
+	cancelFunc()
+	if err != nil {
+		log.WithError(err).
+			Error("failed to get hosts to reconcile daemon jobs")
+		c.metrics.ReconcileFail.Inc(1)
+		return
+	}
+
+	// no registered host most likely means that host manager has not
+	// recovered the agents yet, do not drain all the daemon instances
+	if len(hosts) == 0 {
+		log.Info("no schedulable host, skip reconciling daemon jobs")
+		return
+	}
+
+	for id, cachedJob := range c.jobFactory.GetAllJobs() {
+		if cachedJob.GetJobType() != job.JobType_DAEMON {
+			continue
+		}
+
+		ctx, cancelFunc := context.WithTimeout(
+			context.Background(),
+			_defaultReconcileTimeout)
+		err := c.reconcileJob(ctx, cachedJob, hosts)
+		cancelFunc()
+		if err != nil {
+			log.WithError(err).
+				WithField("job_id", id).
+				Error("failed to reconcile daemon job")
+			c.metrics.JobReconcileFail.Inc(1)
+		}
+	}
+	c.metrics.Reconcile.Inc(1)
+}
+
+// getSchedulableHosts returns the attributes of the registered hosts
+// which are not in maintenance, keyed by hostname
+func (c *controller) getSchedulableHosts(
+	ctx context.Context,
+) (map[string][]*mesos.Attribute, error) {
+	agentResp, err := c.hostMgrClient.GetMesosAgentInfo(
+		ctx,
+		&hostsvc.GetMesosAgentInfoRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	maintenanceResp, err := c.hostClient.QueryHosts(
+		ctx,
+		&host_svc.QueryHostsRequest{HostStates: _maintenanceHostStates})
+	if err != nil {
+		return nil, err
+	}
+
+	maintenanceHosts := make(map[string]bool)
+	for _, hostInfo := range maintenanceResp.GetHostInfos() {
+		maintenanceHosts[hostInfo.GetHostname()] = true
+	}
+
+	hosts := make(map[string][]*mesos.Attribute)
+	for _, agent := range agentResp.GetAgents() {
+		hostname := agent.GetAgentInfo().GetHostname()
+		if len(hostname) == 0 || maintenanceHosts[hostname] {
+			continue
+		}
+		hosts[hostname] = agent.GetAgentInfo().GetAttributes()
+	}
+	return hosts, nil
+}
+
+// reconcileJob makes sure that a daemon job runs exactly one instance
+// on each of its eligible hosts
+func (c *controller) reconcileJob(
+	ctx context.Context,
+	cachedJob cached.Job,
+	hosts map[string][]*mesos.Attribute,
+) error {
+	jobID := cachedJob.ID()
+
+	jobRuntime, err := cachedJob.GetRuntime(ctx)
+	if err != nil {
+		return err
+	}
+	if util.IsPelotonJobStateTerminal(jobRuntime.GetGoalState()) {
+		return nil
+	}
+
+	jobConfig, configAddOn, err := c.jobStore.GetJobConfig(
+		ctx,
+		jobID.GetValue())
+	if err != nil {
+		return err
+	}
+
+	eligibleHosts, err := getEligibleHosts(
+		jobConfig.GetDefaultConfig().GetConstraint(),
+		hosts)
+	if err != nil {
+		return err
+	}
+
+	tasks := cachedJob.GetAllTasks()
+	instanceIDs := make([]uint32, 0, len(tasks))
+	for id := range tasks {
+		instanceIDs = append(instanceIDs, id)
+	}
+	sort.Slice(instanceIDs, func(i, j int) bool {
+		return instanceIDs[i] < instanceIDs[j]
+	})
+
+	// the instance pinned to each host
+	hostInstances := make(map[string]uint32)
+	// the killed instances which can be reused for new hosts
+	var freeInstances []uint32
+	runtimeDiffs := make(map[uint32]jobmgrcommon.RuntimeDiff)
+	added, drained := 0, 0
+
+	for _, id := range instanceIDs {
+		runtime, err := tasks[id].GetRuntime(ctx)
+		if err != nil {
+			return err
+		}
+
+		if runtime.GetGoalState() == task.TaskState_KILLED {
+			if util.IsPelotonStateTerminal(runtime.GetState()) {
+				freeInstances = append(freeInstances, id)
+			}
+			continue
+		}
+
+		hostname := runtime.GetDesiredHost()
+		if len(hostname) == 0 {
+			hostname = runtime.GetHost()
+		}
+		if len(hostname) == 0 {
+			// the instance has not been placed yet,
+			// pin it once it lands on a host
+			continue
+		}
+
+		_, eligible := eligibleHosts[hostname]
+		_, duplicate := hostInstances[hostname]
+		if !eligible || duplicate {
+			runtimeDiffs[id] = jobmgrcommon.RuntimeDiff{
+				jobmgrcommon.GoalStateField:   task.TaskState_KILLED,
+				jobmgrcommon.DesiredHostField: "",
+				jobmgrcommon.MessageField:     _instanceDrainedMessage,
+				jobmgrcommon.TerminationStatusField: &task.TerminationStatus{
+					Reason: task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_HOST_MAINTENANCE,
+				},
+			}
+			drained++
+			continue
+		}
+
+		hostInstances[hostname] = id
+		if len(runtime.GetDesiredHost()) == 0 {
+			runtimeDiffs[id] = jobmgrcommon.RuntimeDiff{
+				jobmgrcommon.DesiredHostField: hostname,
+			}
+		}
+	}
+
+	var newHosts []string
+	for hostname := range eligibleHosts {
+		if _, ok := hostInstances[hostname]; !ok {
+			newHosts = append(newHosts, hostname)
+		}
+	}
+	sort.Strings(newHosts)
+
+	// reuse the killed instances before growing the job
+	for len(newHosts) > 0 && len(freeInstances) > 0 {
+		runtimeDiffs[freeInstances[0]] = jobmgrcommon.RuntimeDiff{
+			jobmgrcommon.GoalStateField:   task.TaskState_RUNNING,
+			jobmgrcommon.DesiredHostField: newHosts[0],
+			jobmgrcommon.MessageField:     _instanceAddedMessage,
+		}
+		freeInstances = freeInstances[1:]
+		newHosts = newHosts[1:]
+		added++
+	}
+
+	if len(runtimeDiffs) == 0 && len(newHosts) == 0 {
+		return nil
+	}
+
+	if len(runtimeDiffs) > 0 {
+		if err := cachedJob.PatchTasks(ctx, runtimeDiffs); err != nil {
+			return err
+		}
+		for id := range runtimeDiffs {
+			c.goalStateDriver.EnqueueTask(jobID, id, time.Now())
+		}
+	}
+
+	if len(newHosts) > 0 {
+		if err := c.addInstances(
+			ctx,
+			cachedJob,
+			jobConfig,
+			configAddOn,
+			newHosts); err != nil {
+			return err
+		}
+		added += len(newHosts)
+	}
+
+	goalstate.EnqueueJobWithDefaultDelay(jobID, c.goalStateDriver, cachedJob)
+
+	log.WithFields(log.Fields{
+		"job_id":            jobID.GetValue(),
+		"instances_added":   added,
+		"instances_drained": drained,
+	}).Info("daemon job reconciled")
+	c.metrics.InstancesAdded.Inc(int64(added))
+	c.metrics.InstancesDrained.Inc(int64(drained))
+	return nil
+}
+
+// addInstances grows the instance count of a daemon job, and creates
+// one instance pinned to each of the given hosts
+func (c *controller) addInstances(
+	ctx context.Context,
+	cachedJob cached.Job,
+	jobConfig *job.JobConfig,
+	configAddOn *models.ConfigAddOn,
+	hostnames []string,
+) error {
+	jobID := cachedJob.ID()
+
+	newConfig := proto.Clone(jobConfig).(*job.JobConfig)
+	newConfig.InstanceCount = jobConfig.GetInstanceCount() +
+		uint32(len(hostnames))
+
+	// first persist the configuration
+	updatedConfig, err := cachedJob.CompareAndSetConfig(
+		ctx,
+		newConfig,
+		configAddOn)
+	if err != nil {
+		return err
+	}
+	newConfig.ChangeLog = updatedConfig.GetChangeLog()
+
+	if err := cachedJob.CreateTaskConfigs(
+		ctx,
+		jobID,
+		newConfig,
+		configAddOn); err != nil {
+		return err
+	}
+
+	// next create the runtimes of the new instances pinned to their host
+	runtimes := make(map[uint32]*task.RuntimeInfo)
+	for i, hostname := range hostnames {
+		instanceID := jobConfig.GetInstanceCount() + uint32(i)
+		runtime := jobmgr_task.CreateInitializingTask(
+			jobID,
+			instanceID,
+			newConfig)
+		runtime.DesiredHost = hostname
+		runtime.Message = _instanceAddedMessage
+		runtimes[instanceID] = runtime
+	}
+
+	if err := cachedJob.CreateTaskRuntimes(
+		ctx,
+		runtimes,
+		newConfig.GetOwningTeam()); err != nil {
+		return err
+	}
+
+	// last persist the new configuration version in the job runtime
+	if err := cachedJob.Update(ctx, &job.JobInfo{
+		Runtime: &job.RuntimeInfo{
+			ConfigurationVersion: updatedConfig.GetChangeLog().GetVersion(),
+		},
+	}, nil,
+		cached.UpdateCacheAndDB); err != nil {
+		return err
+	}
+
+	for instanceID := range runtimes {
+		c.goalStateDriver.EnqueueTask(jobID, instanceID, time.Now())
+	}
+	return nil
+}
+
+// getEligibleHosts returns the hosts which match the host constraint
+// of a daemon job
+func getEligibleHosts(
+	constraint *task.Constraint,
+	hosts map[string][]*mesos.Attribute,
+) (map[string]struct{}, error) {
+	evaluator := constraints.NewEvaluator(task.LabelConstraint_HOST)
+	eligibleHosts := make(map[string]struct{})
+	for hostname, attributes := range hosts {
+		if constraint == nil {
+			eligibleHosts[hostname] = struct{}{}
+			continue
+		}
+
+		result, err := evaluator.Evaluate(
+			constraint,
+			constraints.GetHostLabelValues(hostname, attributes))
+		if err != nil {
+			return nil, err
+		}
+		if result == constraints.EvaluateResultMatch ||
+			result == constraints.EvaluateResultNotApplicable {
+			eligibleHosts[hostname] = struct{}{}
+		}
+	}
+	return eligibleHosts, nil
+}
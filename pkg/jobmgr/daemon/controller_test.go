@@ -0,0 +1,441 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	mesos_master "github.com/uber/peloton/.gen/mesos/v1/master"
+	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	hostmocks "github.com/uber/peloton/.gen/peloton/api/v0/host/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	hostmgrmocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
+	storage_mocks "github.com/uber/peloton/pkg/storage/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type DaemonControllerTestSuite struct {
+	suite.Suite
+
+	ctrl            *gomock.Controller
+	controller      *controller
+	mockJobStore    *storage_mocks.MockJobStore
+	jobFactory      *cachedmocks.MockJobFactory
+	goalStateDriver *goalstatemocks.MockDriver
+	hostMgrClient   *hostmgrmocks.MockInternalHostServiceYARPCClient
+	hostClient      *hostmocks.MockHostServiceYARPCClient
+
+	jobID     *peloton.JobID
+	cachedJob *cachedmocks.MockJob
+}
+
+func TestDaemonController(t *testing.T) {
+	suite.Run(t, new(DaemonControllerTestSuite))
+}
+
+func (suite *DaemonControllerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.mockJobStore = storage_mocks.NewMockJobStore(suite.ctrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.ctrl)
+	suite.hostMgrClient = hostmgrmocks.NewMockInternalHostServiceYARPCClient(suite.ctrl)
+	suite.hostClient = hostmocks.NewMockHostServiceYARPCClient(suite.ctrl)
+	suite.controller = &controller{
+		jobStore:        suite.mockJobStore,
+		jobFactory:      suite.jobFactory,
+		goalStateDriver: suite.goalStateDriver,
+		hostMgrClient:   suite.hostMgrClient,
+		hostClient:      suite.hostClient,
+		metrics:         NewMetrics(tally.NoopScope),
+	}
+
+	suite.jobID = &peloton.JobID{Value: "bca875f5-322a-4439-b0c9-63e3cf9f982e"}
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.cachedJob.EXPECT().ID().Return(suite.jobID).AnyTimes()
+}
+
+func (suite *DaemonControllerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// createAgent creates a registered mesos agent on a rack
+func createAgent(hostname string, rack string) *mesos_master.Response_GetAgents_Agent {
+	attrType := mesos.Value_TEXT
+	attrName := "rack"
+	return &mesos_master.Response_GetAgents_Agent{
+		AgentInfo: &mesos.AgentInfo{
+			Hostname: &hostname,
+			Attributes: []*mesos.Attribute{
+				{
+					Name: &attrName,
+					Type: &attrType,
+					Text: &mesos.Value_Text{Value: &rack},
+				},
+			},
+		},
+	}
+}
+
+// expectHosts sets up the registered hosts and the hosts in maintenance
+func (suite *DaemonControllerTestSuite) expectHosts(
+	agents []*mesos_master.Response_GetAgents_Agent,
+	maintenanceHosts []string,
+) {
+	suite.hostMgrClient.EXPECT().
+		GetMesosAgentInfo(gomock.Any(), &hostsvc.GetMesosAgentInfoRequest{}).
+		Return(&hostsvc.GetMesosAgentInfoResponse{Agents: agents}, nil)
+
+	var hostInfos []*hpb.HostInfo
+	for _, hostname := range maintenanceHosts {
+		hostInfos = append(hostInfos, &hpb.HostInfo{
+			Hostname: hostname,
+			State:    hpb.HostState_HOST_STATE_DRAINING,
+		})
+	}
+	suite.hostClient.EXPECT().
+		QueryHosts(gomock.Any(), &host_svc.QueryHostsRequest{
+			HostStates: _maintenanceHostStates,
+		}).
+		Return(&host_svc.QueryHostsResponse{HostInfos: hostInfos}, nil)
+}
+
+// expectDaemonJob sets up a daemon job with the given instances
+func (suite *DaemonControllerTestSuite) expectDaemonJob(
+	jobConfig *job.JobConfig,
+	runtimes map[uint32]*task.RuntimeInfo,
+) {
+	suite.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{suite.jobID.GetValue(): suite.cachedJob})
+	suite.cachedJob.EXPECT().
+		GetJobType().
+		Return(job.JobType_DAEMON).
+		AnyTimes()
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{
+			State:     job.JobState_RUNNING,
+			GoalState: job.JobState_RUNNING,
+		}, nil)
+	suite.mockJobStore.EXPECT().
+		GetJobConfig(gomock.Any(), suite.jobID.GetValue()).
+		Return(jobConfig, &models.ConfigAddOn{}, nil)
+
+	tasks := make(map[uint32]cached.Task)
+	for id, runtime := range runtimes {
+		cachedTask := cachedmocks.NewMockTask(suite.ctrl)
+		cachedTask.EXPECT().
+			GetRuntime(gomock.Any()).
+			Return(runtime, nil).
+			AnyTimes()
+		tasks[id] = cachedTask
+	}
+	suite.cachedJob.EXPECT().GetAllTasks().Return(tasks)
+}
+
+// expectJobEnqueue sets up the expectation of enqueuing the job
+// into goal state engine
+func (suite *DaemonControllerTestSuite) expectJobEnqueue() {
+	suite.goalStateDriver.EXPECT().
+		JobRuntimeDuration(job.JobType_DAEMON).
+		Return(time.Second)
+	suite.goalStateDriver.EXPECT().
+		EnqueueJob(suite.jobID, gomock.Any())
+}
+
+// TestReconcileAddInstances tests adding one instance pinned to each
+// eligible host which is not in maintenance
+func (suite *DaemonControllerTestSuite) TestReconcileAddInstances() {
+	suite.expectHosts(
+		[]*mesos_master.Response_GetAgents_Agent{
+			createAgent("host1", "rack1"),
+			createAgent("host2", "rack1"),
+			createAgent("host3", "rack1"),
+		},
+		[]string{"host3"},
+	)
+
+	jobConfig := &job.JobConfig{
+		Type:          job.JobType_DAEMON,
+		DefaultConfig: &task.TaskConfig{},
+		ChangeLog:     &peloton.ChangeLog{Version: 1},
+	}
+	suite.expectDaemonJob(jobConfig, nil)
+
+	suite.cachedJob.EXPECT().
+		CompareAndSetConfig(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, config *job.JobConfig, _ *models.ConfigAddOn) {
+			suite.Equal(uint32(2), config.GetInstanceCount())
+		}).
+		Return(&job.JobConfig{
+			InstanceCount: 2,
+			ChangeLog:     &peloton.ChangeLog{Version: 2},
+		}, nil)
+	suite.cachedJob.EXPECT().
+		CreateTaskConfigs(gomock.Any(), suite.jobID, gomock.Any(), gomock.Any()).
+		Return(nil)
+	suite.cachedJob.EXPECT().
+		CreateTaskRuntimes(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(
+			_ context.Context,
+			runtimes map[uint32]*task.RuntimeInfo,
+			_ string) {
+			suite.Len(runtimes, 2)
+			suite.Equal("host1", runtimes[0].GetDesiredHost())
+			suite.Equal("host2", runtimes[1].GetDesiredHost())
+			for _, runtime := range runtimes {
+				suite.Equal(task.TaskState_INITIALIZED, runtime.GetState())
+				suite.Equal(task.TaskState_RUNNING, runtime.GetGoalState())
+				suite.Equal(uint64(2), runtime.GetConfigVersion())
+			}
+		}).
+		Return(nil)
+	suite.cachedJob.EXPECT().
+		Update(gomock.Any(), &job.JobInfo{
+			Runtime: &job.RuntimeInfo{ConfigurationVersion: 2},
+		}, nil, cached.UpdateCacheAndDB).
+		Return(nil)
+	suite.goalStateDriver.EXPECT().
+		EnqueueTask(suite.jobID, uint32(0), gomock.Any())
+	suite.goalStateDriver.EXPECT().
+		EnqueueTask(suite.jobID, uint32(1), gomock.Any())
+	suite.expectJobEnqueue()
+
+	suite.controller.Reconcile()
+}
+
+// TestReconcileDrainAndReuseInstances tests draining the instances on
+// hosts in maintenance or already running an instance, and reusing the
+// killed instances for new hosts
+func (suite *DaemonControllerTestSuite) TestReconcileDrainAndReuseInstances() {
+	suite.expectHosts(
+		[]*mesos_master.Response_GetAgents_Agent{
+			createAgent("host1", "rack1"),
+			createAgent("host2", "rack1"),
+			createAgent("host3", "rack1"),
+			createAgent("host4", "rack1"),
+		},
+		[]string{"host2"},
+	)
+
+	jobConfig := &job.JobConfig{
+		Type:          job.JobType_DAEMON,
+		InstanceCount: 5,
+		DefaultConfig: &task.TaskConfig{},
+		ChangeLog:     &peloton.ChangeLog{Version: 1},
+	}
+	suite.expectDaemonJob(jobConfig, map[uint32]*task.RuntimeInfo{
+		// pinned to an eligible host
+		0: {
+			State:       task.TaskState_RUNNING,
+			GoalState:   task.TaskState_RUNNING,
+			Host:        "host1",
+			DesiredHost: "host1",
+		},
+		// pinned to a host in maintenance
+		1: {
+			State:       task.TaskState_RUNNING,
+			GoalState:   task.TaskState_RUNNING,
+			Host:        "host2",
+			DesiredHost: "host2",
+		},
+		// killed instance which can be reused
+		2: {
+			State:     task.TaskState_KILLED,
+			GoalState: task.TaskState_KILLED,
+		},
+		// duplicate instance on a host
+		3: {
+			State:     task.TaskState_RUNNING,
+			GoalState: task.TaskState_RUNNING,
+			Host:      "host1",
+		},
+		// instance running on an eligible host without being pinned
+		4: {
+			State:     task.TaskState_RUNNING,
+			GoalState: task.TaskState_RUNNING,
+			Host:      "host4",
+		},
+	})
+
+	drainDiff := jobmgrcommon.RuntimeDiff{
+		jobmgrcommon.GoalStateField:   task.TaskState_KILLED,
+		jobmgrcommon.DesiredHostField: "",
+		jobmgrcommon.MessageField:     _instanceDrainedMessage,
+		jobmgrcommon.TerminationStatusField: &task.TerminationStatus{
+			Reason: task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_HOST_MAINTENANCE,
+		},
+	}
+	suite.cachedJob.EXPECT().
+		PatchTasks(gomock.Any(), map[uint32]jobmgrcommon.RuntimeDiff{
+			1: drainDiff,
+			2: {
+				jobmgrcommon.GoalStateField:   task.TaskState_RUNNING,
+				jobmgrcommon.DesiredHostField: "host3",
+				jobmgrcommon.MessageField:     _instanceAddedMessage,
+			},
+			3: drainDiff,
+			4: {
+				jobmgrcommon.DesiredHostField: "host4",
+			},
+		}).
+		Return(nil)
+	for _, id := range []uint32{1, 2, 3, 4} {
+		suite.goalStateDriver.EXPECT().
+			EnqueueTask(suite.jobID, id, gomock.Any())
+	}
+	suite.expectJobEnqueue()
+
+	suite.controller.Reconcile()
+}
+
+// TestReconcileNoChange tests reconciling a daemon job which already
+// runs one instance on each eligible host
+func (suite *DaemonControllerTestSuite) TestReconcileNoChange() {
+	suite.expectHosts(
+		[]*mesos_master.Response_GetAgents_Agent{
+			createAgent("host1", "rack1"),
+		},
+		nil,
+	)
+
+	suite.expectDaemonJob(&job.JobConfig{
+		Type:          job.JobType_DAEMON,
+		InstanceCount: 1,
+		DefaultConfig: &task.TaskConfig{},
+	}, map[uint32]*task.RuntimeInfo{
+		0: {
+			State:       task.TaskState_PENDING,
+			GoalState:   task.TaskState_RUNNING,
+			DesiredHost: "host1",
+		},
+	})
+
+	suite.controller.Reconcile()
+}
+
+// TestReconcileSkipNonDaemonAndKilledJobs tests that only the daemon
+// jobs which are not being killed are reconciled
+func (suite *DaemonControllerTestSuite) TestReconcileSkipNonDaemonAndKilledJobs() {
+	suite.expectHosts(
+		[]*mesos_master.Response_GetAgents_Agent{
+			createAgent("host1", "rack1"),
+		},
+		nil,
+	)
+
+	batchJob := cachedmocks.NewMockJob(suite.ctrl)
+	batchJob.EXPECT().GetJobType().Return(job.JobType_BATCH)
+	suite.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{
+			suite.jobID.GetValue(): suite.cachedJob,
+			"batch-job":            batchJob,
+		})
+	suite.cachedJob.EXPECT().GetJobType().Return(job.JobType_DAEMON)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{
+			State:     job.JobState_KILLING,
+			GoalState: job.JobState_KILLED,
+		}, nil)
+
+	suite.controller.Reconcile()
+}
+
+// TestReconcileNoHosts tests that no instance is drained
+// when host manager does not return any host
+func (suite *DaemonControllerTestSuite) TestReconcileNoHosts() {
+	suite.expectHosts(nil, nil)
+	suite.controller.Reconcile()
+}
+
+// TestReconcileHostMgrError tests that nothing is reconciled
+// when host manager fails to return the hosts
+func (suite *DaemonControllerTestSuite) TestReconcileHostMgrError() {
+	suite.hostMgrClient.EXPECT().
+		GetMesosAgentInfo(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error"))
+	suite.controller.Reconcile()
+}
+
+// TestReconcileJobError tests that the failure to reconcile a job
+// does not fail the whole reconciliation
+func (suite *DaemonControllerTestSuite) TestReconcileJobError() {
+	suite.expectHosts(
+		[]*mesos_master.Response_GetAgents_Agent{
+			createAgent("host1", "rack1"),
+		},
+		nil,
+	)
+	suite.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{suite.jobID.GetValue(): suite.cachedJob})
+	suite.cachedJob.EXPECT().GetJobType().Return(job.JobType_DAEMON)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	suite.controller.Reconcile()
+}
+
+// TestGetEligibleHosts tests filtering the hosts with
+// the host constraint of a daemon job
+func (suite *DaemonControllerTestSuite) TestGetEligibleHosts() {
+	hosts := map[string][]*mesos.Attribute{
+		"host1": createAgent("host1", "rack1").GetAgentInfo().GetAttributes(),
+		"host2": createAgent("host2", "rack2").GetAgentInfo().GetAttributes(),
+	}
+
+	eligibleHosts, err := getEligibleHosts(nil, hosts)
+	suite.NoError(err)
+	suite.Len(eligibleHosts, 2)
+
+	eligibleHosts, err = getEligibleHosts(&task.Constraint{
+		Type: task.Constraint_LABEL_CONSTRAINT,
+		LabelConstraint: &task.LabelConstraint{
+			Kind:      task.LabelConstraint_HOST,
+			Condition: task.LabelConstraint_CONDITION_EQUAL,
+			Label: &peloton.Label{
+				Key:   "rack",
+				Value: "rack2",
+			},
+			Requirement: 1,
+		},
+	}, hosts)
+	suite.NoError(err)
+	suite.Len(eligibleHosts, 1)
+	suite.Contains(eligibleHosts, "host2")
+
+	_, err = getEligibleHosts(&task.Constraint{}, hosts)
+	suite.Error(err)
+}
@@ -126,12 +126,28 @@ type Driver interface {
 	// JobRuntimeDuration returns the mimimum inter-run duration between job
 	// runtime updates. This duration is different for batch and service jobs.
 	JobRuntimeDuration(jobType job.JobType) time.Duration
+	// AddJobTerminalListener adds a listener which is notified when a
+	// batch job in a terminal state is untracked from the goal state engine.
+	AddJobTerminalListener(listener JobTerminalListener)
 	// Start is used to start processing items in the goal state engine.
 	Start()
 	// Stop is used to clean all items and then stop the goal state engine.
 	Stop()
 }
 
+// JobTerminalListener is notified of the batch jobs which reached
+// a terminal state.
+type JobTerminalListener interface {
+	// JobTerminated is invoked before a batch job in a terminal state
+	// is untracked. If an error is returned, untracking the job is
+	// retried by the goal state engine, and the listener is notified again.
+	JobTerminated(
+		ctx context.Context,
+		jobID *peloton.JobID,
+		state job.JobState,
+	) error
+}
+
 // NewDriver returns a new goal state driver object.
 func NewDriver(
 	d *yarpc.Dispatcher,
@@ -241,6 +257,9 @@ type driver struct {
 	jobRuntimeCalculationViaCache bool
 	// job scope for goalstate driver
 	jobScope tally.Scope
+
+	// listeners notified of the terminal batch jobs
+	jobTerminalListeners []JobTerminalListener
 }
 
 func (d *driver) EnqueueJob(jobID *peloton.JobID, deadline time.Time) {
@@ -309,6 +328,22 @@ func (d *driver) IsScheduledTask(jobID *peloton.JobID, instanceID uint32) bool {
 	return d.taskEngine.IsScheduled(taskEntity)
 }
 
+// AddJobTerminalListener adds a listener notified of the terminal batch jobs
+func (d *driver) AddJobTerminalListener(listener JobTerminalListener) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.jobTerminalListeners = append(d.jobTerminalListeners, listener)
+}
+
+// getJobTerminalListeners returns the listeners of the terminal batch jobs
+func (d *driver) getJobTerminalListeners() []JobTerminalListener {
+	d.RLock()
+	defer d.RUnlock()
+
+	return d.jobTerminalListeners
+}
+
 func (d *driver) JobRuntimeDuration(jobType job.JobType) time.Duration {
 	if jobType == job.JobType_BATCH {
 		return d.cfg.JobBatchRuntimeUpdateInterval
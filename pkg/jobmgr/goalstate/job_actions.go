@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 
 	"github.com/uber/peloton/pkg/common/goalstate"
@@ -48,11 +49,22 @@ func JobUntrack(ctx context.Context, entity goalstate.Entity) error {
 			// if config is not found, untrack the job from cache
 			return err
 		}
-	} else if jobConfig.GetType() == job.JobType_SERVICE {
-		// service jobs are always active and never untracked
+	} else if jobConfig.GetType() == job.JobType_SERVICE ||
+		jobConfig.GetType() == job.JobType_DAEMON {
+		// service and daemon jobs are always active and never untracked
 		return nil
 	}
 
+	// notify the listeners before untracking the job, so that
+	// a failed notification is retried with the untrack action
+	if err := notifyJobTerminated(
+		ctx,
+		goalStateDriver,
+		jobEnt.id,
+		cachedJob); err != nil {
+		return err
+	}
+
 	// First clean from goal state
 	taskMap := cachedJob.GetAllTasks()
 	for instID := range taskMap {
@@ -65,6 +77,42 @@ func JobUntrack(ctx context.Context, entity goalstate.Entity) error {
 	return nil
 }
 
+// notifyJobTerminated notifies the terminal batch job listeners
+// of the driver if the job is in a terminal state
+func notifyJobTerminated(
+	ctx context.Context,
+	goalStateDriver *driver,
+	jobID *peloton.JobID,
+	cachedJob cached.Job,
+) error {
+	listeners := goalStateDriver.getJobTerminalListeners()
+	if len(listeners) == 0 {
+		return nil
+	}
+
+	jobRuntime, err := cachedJob.GetRuntime(ctx)
+	if err != nil {
+		if yarpcerrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !util.IsPelotonJobStateTerminal(jobRuntime.GetState()) {
+		return nil
+	}
+
+	for _, listener := range listeners {
+		if err := listener.JobTerminated(
+			ctx,
+			jobID,
+			jobRuntime.GetState()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // JobStateInvalid dumps a sentry error to indicate that the
 // job goal state, state combination is not valid
 func JobStateInvalid(ctx context.Context, entity goalstate.Entity) error {
@@ -126,6 +126,105 @@ func (suite *jobActionsTestSuite) TestUntrackJobBatch() {
 	suite.NoError(err)
 }
 
+// testJobTerminalListener records the terminal jobs it is notified of
+type testJobTerminalListener struct {
+	states map[string]job.JobState
+	err    error
+}
+
+func (l *testJobTerminalListener) JobTerminated(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	state job.JobState,
+) error {
+	if l.err != nil {
+		return l.err
+	}
+	l.states[jobID.GetValue()] = state
+	return nil
+}
+
+// TestUntrackJobBatchNotifiesListener tests that the listeners
+// are notified of the terminal batch job before it is untracked
+func (suite *jobActionsTestSuite) TestUntrackJobBatchNotifiesListener() {
+	listener := &testJobTerminalListener{states: make(map[string]job.JobState)}
+	suite.goalStateDriver.AddJobTerminalListener(listener)
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).
+		Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&job.JobConfig{
+			Type: job.JobType_BATCH,
+		}, nil)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{State: job.JobState_FAILED}, nil)
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{})
+	suite.jobGoalStateEngine.EXPECT().
+		Delete(gomock.Any()).
+		Return()
+	suite.jobFactory.EXPECT().
+		ClearJob(suite.jobID).Return()
+
+	suite.NoError(JobUntrack(context.Background(), suite.jobEnt))
+	suite.Equal(job.JobState_FAILED, listener.states[suite.jobID.GetValue()])
+}
+
+// TestUntrackJobBatchListenerError tests that the job is not
+// untracked if a listener fails
+func (suite *jobActionsTestSuite) TestUntrackJobBatchListenerError() {
+	listener := &testJobTerminalListener{err: fmt.Errorf("fake listener error")}
+	suite.goalStateDriver.AddJobTerminalListener(listener)
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).
+		Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&job.JobConfig{
+			Type: job.JobType_BATCH,
+		}, nil)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{State: job.JobState_SUCCEEDED}, nil)
+
+	suite.Error(JobUntrack(context.Background(), suite.jobEnt))
+}
+
+// TestUntrackJobBatchNotTerminal tests that the listeners are not
+// notified of a batch job untracked in a non terminal state
+func (suite *jobActionsTestSuite) TestUntrackJobBatchNotTerminal() {
+	listener := &testJobTerminalListener{states: make(map[string]job.JobState)}
+	suite.goalStateDriver.AddJobTerminalListener(listener)
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).
+		Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&job.JobConfig{
+			Type: job.JobType_BATCH,
+		}, nil)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{State: job.JobState_UNINITIALIZED}, nil)
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{})
+	suite.jobGoalStateEngine.EXPECT().
+		Delete(gomock.Any()).
+		Return()
+	suite.jobFactory.EXPECT().
+		ClearJob(suite.jobID).Return()
+
+	suite.NoError(JobUntrack(context.Background(), suite.jobEnt))
+	suite.Empty(listener.states)
+}
+
 func (suite *jobActionsTestSuite) TestUntrackJobStateless() {
 	suite.jobFactory.EXPECT().
 		GetJob(suite.jobID).
@@ -288,6 +288,21 @@ func (d *jobStateDeterminer) getState(
 ) (job.JobState, error) {
 	totalInstanceCount := d.config.GetInstanceCount()
 
+	// The instances of a daemon job follow the eligible hosts, and are
+	// drained when their host goes away. So a daemon job is never done
+	// until it is killed, even if it has no active instance.
+	if d.config.GetType() == job.JobType_DAEMON {
+		if !util.IsPelotonJobStateTerminal(jobRuntime.GetGoalState()) {
+			if d.stateCounts[task.TaskState_RUNNING.String()] > 0 {
+				return job.JobState_RUNNING, nil
+			}
+			return job.JobState_PENDING, nil
+		}
+		if totalInstanceCount == 0 {
+			return job.JobState_KILLED, nil
+		}
+	}
+
 	// There are two reasons where state counts can be greater than
 	// configured instance count
 	// 1. storage materialized view is diverged and till it converges
@@ -366,7 +381,7 @@ func (d *partiallyCreatedJobStateDeterminer) getState(
 	switch d.cachedJob.GetJobType() {
 	case job.JobType_BATCH:
 		return job.JobState_INITIALIZED, nil
-	case job.JobType_SERVICE:
+	case job.JobType_SERVICE, job.JobType_DAEMON:
 
 		// job goal state is terminal &&
 		// some killed + some succeeded + some failed + some lost -> killed
@@ -804,8 +819,8 @@ func shouldRecalculateJobStateFromCache(
 	jobState job.JobState,
 	forceRecalculateFromCache bool) bool {
 
-	if jobType == job.JobType_SERVICE {
-		// always compute from cache for stateless services
+	if jobType == job.JobType_SERVICE || jobType == job.JobType_DAEMON {
+		// always compute from cache for stateless services and daemons
 		return true
 	}
 
@@ -1850,6 +1850,73 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobStateDeterminer_NoInstancesCreat
 	suite.Equal(job.JobState_KILLED, jobState)
 }
 
+// TestJobStateDeterminer_DaemonJob tests determining the state of a daemon
+// job, which is never done until it is killed
+func (suite *JobRuntimeUpdaterTestSuite) TestJobStateDeterminer_DaemonJob() {
+	tt := []struct {
+		instanceCount uint32
+		stateCounts   map[string]uint32
+		goalState     pbjob.JobState
+		expectedState pbjob.JobState
+	}{
+		{
+			// no eligible host yet
+			instanceCount: 0,
+			stateCounts:   map[string]uint32{},
+			goalState:     pbjob.JobState_RUNNING,
+			expectedState: pbjob.JobState_PENDING,
+		},
+		{
+			instanceCount: 2,
+			stateCounts: map[string]uint32{
+				pbtask.TaskState_RUNNING.String(): 1,
+				pbtask.TaskState_PENDING.String(): 1,
+			},
+			goalState:     pbjob.JobState_RUNNING,
+			expectedState: pbjob.JobState_RUNNING,
+		},
+		{
+			// all the hosts are in maintenance
+			instanceCount: 2,
+			stateCounts: map[string]uint32{
+				pbtask.TaskState_KILLED.String(): 2,
+			},
+			goalState:     pbjob.JobState_RUNNING,
+			expectedState: pbjob.JobState_PENDING,
+		},
+		{
+			instanceCount: 0,
+			stateCounts:   map[string]uint32{},
+			goalState:     pbjob.JobState_KILLED,
+			expectedState: pbjob.JobState_KILLED,
+		},
+		{
+			instanceCount: 2,
+			stateCounts: map[string]uint32{
+				pbtask.TaskState_KILLED.String(): 2,
+			},
+			goalState:     pbjob.JobState_KILLED,
+			expectedState: pbjob.JobState_KILLED,
+		},
+	}
+
+	for _, test := range tt {
+		config := cachedmocks.NewMockJobConfigCache(suite.ctrl)
+		config.EXPECT().GetType().Return(pbjob.JobType_DAEMON).AnyTimes()
+		config.EXPECT().GetInstanceCount().Return(test.instanceCount).AnyTimes()
+
+		determiner := newJobStateDeterminer(test.stateCounts, config)
+		jobState, err := determiner.getState(
+			context.Background(),
+			&pbjob.RuntimeInfo{
+				State:     pbjob.JobState_PENDING,
+				GoalState: test.goalState,
+			})
+		suite.NoError(err)
+		suite.Equal(test.expectedState, jobState)
+	}
+}
+
 // TestDetermineJobRuntimeStateStaleJob tests determining job runtime state
 // for a stale active job with out of sync materialized view
 func (suite *JobRuntimeUpdaterTestSuite) TestDetermineJobRuntimeStateStaleJob() {
@@ -2492,6 +2559,9 @@ func (suite *JobRuntimeUpdaterTestSuite) TestShouldRecalculateJobStateNonBatch()
 	suite.True(shouldRecalculateJobStateFromCache(
 		suite.cachedJob, pbjob.JobType_SERVICE, pbjob.JobState_RUNNING,
 		suite.goalStateDriver.jobRuntimeCalculationViaCache))
+	suite.True(shouldRecalculateJobStateFromCache(
+		suite.cachedJob, pbjob.JobType_DAEMON, pbjob.JobState_RUNNING,
+		suite.goalStateDriver.jobRuntimeCalculationViaCache))
 }
 
 // TestshouldRecalculateJobStateTerminalJob tests shouldRecalculateJobStateFromCache
@@ -64,6 +64,8 @@ type UpdateMetrics struct {
 	UpdateRunFail           tally.Counter
 	UpdateWriteProgress     tally.Counter
 	UpdateWriteProgressFail tally.Counter
+	UpdateCanarySucceeded   tally.Counter
+	UpdateCanaryFailed      tally.Counter
 }
 
 // Metrics is the struct containing all the counters that track job and task
@@ -121,6 +123,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		UpdateRunFail:           updateScope.Counter("run_fail"),
 		UpdateWriteProgress:     updateScope.Counter("write_progress"),
 		UpdateWriteProgressFail: updateScope.Counter("write_progress_fail"),
+		UpdateCanarySucceeded:   updateScope.Counter("canary_succeeded"),
+		UpdateCanaryFailed:      updateScope.Counter("canary_failed"),
 	}
 
 	return &Metrics{
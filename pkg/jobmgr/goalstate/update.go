@@ -171,6 +171,12 @@ func (u *updateEntity) suggestUpdateAction(
 		// different instances in them? It is not clear what to behave other than
 		// give up.
 		if len(updateState.Instances) == len(updateGoalState.Instances) {
+			// the canary instances may be all the instances of the
+			// update, which is complete only once the canary succeeds
+			if updateState.State == update.State_ROLLING_FORWARD &&
+				isCanaryStateInProgress(updateState.CanaryState) {
+				return RunUpdateAction
+			}
 			// update is complete
 			return CompleteUpdateAction
 		}
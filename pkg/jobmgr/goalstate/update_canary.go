@@ -0,0 +1,233 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"time"
+
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	taskutil "github.com/uber/peloton/pkg/jobmgr/util/task"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// _canaryHealthCheckInterval is the interval at which the health of the
+// canary instances is checked, while waiting for them to become healthy
+// and while they are baking.
+const _canaryHealthCheckInterval = 10 * time.Second
+
+// isCanaryInProgress returns true if the update is rolling forward
+// and its canary phase has not finished yet.
+func isCanaryInProgress(cachedUpdate cached.Update) bool {
+	return isCanaryStateInProgress(cachedUpdate.GetCanaryStatus().GetState()) &&
+		cachedUpdate.GetState().State == pbupdate.State_ROLLING_FORWARD
+}
+
+// isCanaryStateInProgress returns true if the canary phase in the
+// given state has not finished yet.
+func isCanaryStateInProgress(state pbupdate.CanaryState) bool {
+	return state == pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD ||
+		state == pbupdate.CanaryState_CANARY_STATE_BAKING
+}
+
+// processCanary checks the progress of the canary phase of an update,
+// and moves the canary phase to the next state:
+// 1. If any canary instance has failed or became unhealthy, the canary
+// phase is failed and the update is failed or rolled back.
+// 2. Once all canary instances are in the required health state, the
+// canary phase starts baking.
+// 3. Once the canary instances stay in the required health state for the
+// bake duration, the canary phase succeeds and the update continues with
+// the rest of the instances.
+func processCanary(
+	ctx context.Context,
+	cachedJob cached.Job,
+	cachedUpdate cached.Update,
+	instancesDone []uint32,
+	instancesFailed []uint32,
+	instancesCurrent []uint32,
+	goalStateDriver *driver,
+) error {
+	canaryStatus := cachedUpdate.GetCanaryStatus()
+	canaryConfig := cachedUpdate.GetUpdateConfig().GetCanary()
+	jobVersion := cachedUpdate.GetGoalState().JobVersion
+
+	canaryFailed :=
+		len(util.IntersectSlice(canaryStatus.GetInstances(), instancesFailed)) != 0
+	canaryHealthy := true
+	for _, instID := range canaryStatus.GetInstances() {
+		if canaryFailed {
+			break
+		}
+
+		runtime, err := getTaskRuntimeIfExisted(ctx, cachedJob, instID)
+		if err != nil {
+			return err
+		}
+
+		if runtime.GetConfigVersion() == jobVersion &&
+			runtime.GetHealthy() == pbtask.HealthState_UNHEALTHY {
+			canaryFailed = true
+			break
+		}
+
+		if !isCanaryInstanceHealthy(
+			runtime, jobVersion, canaryConfig.GetRequiredHealthState()) {
+			canaryHealthy = false
+		}
+	}
+
+	// a canary instance has left the required health state while baking
+	if canaryStatus.GetState() == pbupdate.CanaryState_CANARY_STATE_BAKING &&
+		!canaryHealthy {
+		canaryFailed = true
+	}
+
+	if canaryFailed {
+		log.WithFields(log.Fields{
+			"update_id": cachedUpdate.ID().GetValue(),
+			"job_id":    cachedJob.ID().GetValue(),
+			"canary":    canaryStatus.GetInstances(),
+		}).Info("canary of update failed")
+
+		if err := cachedUpdate.WriteCanaryProgress(
+			ctx,
+			&pbupdate.CanaryStatus{
+				State:         pbupdate.CanaryState_CANARY_STATE_FAILED,
+				Instances:     canaryStatus.GetInstances(),
+				BakeStartTime: canaryStatus.GetBakeStartTime(),
+			}); err != nil {
+			return err
+		}
+		goalStateDriver.mtx.updateMetrics.UpdateCanaryFailed.Inc(1)
+
+		return processFailedUpdate(
+			ctx,
+			cachedJob,
+			cachedUpdate,
+			instancesDone,
+			instancesFailed,
+			instancesCurrent,
+			goalStateDriver,
+		)
+	}
+
+	// wait for the canary instances to be in the required health state
+	if !canaryHealthy {
+		goalStateDriver.EnqueueUpdate(
+			cachedJob.ID(),
+			cachedUpdate.ID(),
+			time.Now().Add(_canaryHealthCheckInterval))
+		return nil
+	}
+
+	bakeDuration := time.Duration(canaryConfig.GetBakeDurationSecs()) * time.Second
+	bakeStartTime := time.Now().UTC()
+	if canaryStatus.GetState() == pbupdate.CanaryState_CANARY_STATE_BAKING {
+		var err error
+		bakeStartTime, err = time.Parse(
+			time.RFC3339Nano, canaryStatus.GetBakeStartTime())
+		if err != nil {
+			return err
+		}
+	}
+
+	if time.Since(bakeStartTime) >= bakeDuration {
+		log.WithFields(log.Fields{
+			"update_id": cachedUpdate.ID().GetValue(),
+			"job_id":    cachedJob.ID().GetValue(),
+			"canary":    canaryStatus.GetInstances(),
+		}).Info("canary of update succeeded")
+
+		if err := cachedUpdate.WriteCanaryProgress(
+			ctx,
+			&pbupdate.CanaryStatus{
+				State:         pbupdate.CanaryState_CANARY_STATE_SUCCEEDED,
+				Instances:     canaryStatus.GetInstances(),
+				BakeStartTime: bakeStartTime.Format(time.RFC3339Nano),
+			}); err != nil {
+			return err
+		}
+		goalStateDriver.mtx.updateMetrics.UpdateCanarySucceeded.Inc(1)
+		return nil
+	}
+
+	if canaryStatus.GetState() == pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD {
+		if err := cachedUpdate.WriteCanaryProgress(
+			ctx,
+			&pbupdate.CanaryStatus{
+				State:         pbupdate.CanaryState_CANARY_STATE_BAKING,
+				Instances:     canaryStatus.GetInstances(),
+				BakeStartTime: bakeStartTime.Format(time.RFC3339Nano),
+			}); err != nil {
+			return err
+		}
+	}
+
+	// check the health of the canary instances periodically while
+	// baking, and finish the canary phase once the bake duration elapses
+	deadline := bakeStartTime.Add(bakeDuration)
+	if nextCheck := time.Now().Add(_canaryHealthCheckInterval); nextCheck.Before(deadline) {
+		deadline = nextCheck
+	}
+	goalStateDriver.EnqueueUpdate(cachedJob.ID(), cachedUpdate.ID(), deadline)
+	return nil
+}
+
+// isCanaryInstanceHealthy returns true if a canary instance is running
+// with the desired configuration in the required health state and ready.
+func isCanaryInstanceHealthy(
+	runtime *pbtask.RuntimeInfo,
+	jobVersion uint64,
+	requiredHealthState pbtask.HealthState,
+) bool {
+	if runtime.GetState() != pbtask.TaskState_RUNNING ||
+		runtime.GetConfigVersion() != jobVersion ||
+		!taskutil.IsTaskReady(runtime) {
+		return false
+	}
+
+	if requiredHealthState == pbtask.HealthState_HEALTHY {
+		return runtime.GetHealthy() == pbtask.HealthState_HEALTHY
+	}
+	return runtime.GetHealthy() == pbtask.HealthState_HEALTHY ||
+		runtime.GetHealthy() == pbtask.HealthState_DISABLED
+}
+
+// getCanaryInstancesForUpdateRun returns the canary instances to add and
+// update in the current run. All the canary instances are processed at
+// the same time irrespective of the batch size of the update.
+func getCanaryInstancesForUpdateRun(
+	update cached.Update,
+	instancesCurrent []uint32,
+	instancesDone []uint32,
+	instancesFailed []uint32,
+) (
+	instancesToAdd []uint32,
+	instancesToUpdate []uint32,
+) {
+	unprocessedInstancesToAdd, unprocessedInstancesToUpdate, _ :=
+		getUnprocessedInstances(
+			update, instancesCurrent, instancesDone, instancesFailed)
+
+	canaryInstances := update.GetCanaryStatus().GetInstances()
+	return util.IntersectSlice(unprocessedInstancesToAdd, canaryInstances),
+		util.IntersectSlice(unprocessedInstancesToUpdate, canaryInstances)
+}
@@ -0,0 +1,425 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+
+	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type UpdateCanaryTestSuite struct {
+	suite.Suite
+	ctrl                  *gomock.Controller
+	updateGoalStateEngine *goalstatemocks.MockEngine
+	goalStateDriver       *driver
+	jobID                 *peloton.JobID
+	updateID              *peloton.UpdateID
+	cachedJob             *cachedmocks.MockJob
+	cachedUpdate          *cachedmocks.MockUpdate
+	cachedTask            *cachedmocks.MockTask
+	jobVersion            uint64
+}
+
+func TestUpdateCanary(t *testing.T) {
+	suite.Run(t, new(UpdateCanaryTestSuite))
+}
+
+func (suite *UpdateCanaryTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.updateGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.goalStateDriver = &driver{
+		updateEngine: suite.updateGoalStateEngine,
+		mtx:          NewMetrics(tally.NoopScope),
+		cfg:          &Config{},
+	}
+	suite.goalStateDriver.cfg.normalize()
+
+	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.updateID = &peloton.UpdateID{Value: uuid.NewRandom().String()}
+	suite.jobVersion = uint64(4)
+
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.cachedUpdate = cachedmocks.NewMockUpdate(suite.ctrl)
+	suite.cachedTask = cachedmocks.NewMockTask(suite.ctrl)
+
+	suite.cachedJob.EXPECT().ID().Return(suite.jobID).AnyTimes()
+	suite.cachedUpdate.EXPECT().ID().Return(suite.updateID).AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		GetGoalState().
+		Return(&cached.UpdateStateVector{JobVersion: suite.jobVersion}).
+		AnyTimes()
+}
+
+func (suite *UpdateCanaryTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// setupCanary sets up a canary of a single instance in the given state,
+// with the task runtime of the canary instance
+func (suite *UpdateCanaryTestSuite) setupCanary(
+	canaryStatus *pbupdate.CanaryStatus,
+	runtime *pbtask.RuntimeInfo,
+) {
+	suite.cachedUpdate.EXPECT().
+		GetCanaryStatus().
+		Return(canaryStatus).
+		AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		GetUpdateConfig().
+		Return(&pbupdate.UpdateConfig{
+			Canary: &pbupdate.CanaryConfig{
+				InstanceCount:    1,
+				BakeDurationSecs: 60,
+			},
+		}).
+		AnyTimes()
+	suite.cachedJob.EXPECT().
+		GetTask(uint32(0)).
+		Return(suite.cachedTask).
+		AnyTimes()
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(runtime, nil).
+		AnyTimes()
+}
+
+func (suite *UpdateCanaryTestSuite) healthyRuntime() *pbtask.RuntimeInfo {
+	return &pbtask.RuntimeInfo{
+		State:                pbtask.TaskState_RUNNING,
+		Healthy:              pbtask.HealthState_HEALTHY,
+		ConfigVersion:        suite.jobVersion,
+		DesiredConfigVersion: suite.jobVersion,
+	}
+}
+
+// TestIsCanaryInProgress tests checking if the canary phase of an
+// update is in progress
+func (suite *UpdateCanaryTestSuite) TestIsCanaryInProgress() {
+	tests := []struct {
+		canaryStatus *pbupdate.CanaryStatus
+		updateState  pbupdate.State
+		inProgress   bool
+	}{
+		{nil, pbupdate.State_ROLLING_FORWARD, false},
+		{
+			&pbupdate.CanaryStatus{
+				State: pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD,
+			},
+			pbupdate.State_ROLLING_FORWARD,
+			true,
+		},
+		{
+			&pbupdate.CanaryStatus{
+				State: pbupdate.CanaryState_CANARY_STATE_BAKING,
+			},
+			pbupdate.State_ROLLING_FORWARD,
+			true,
+		},
+		{
+			&pbupdate.CanaryStatus{
+				State: pbupdate.CanaryState_CANARY_STATE_BAKING,
+			},
+			pbupdate.State_ROLLING_BACKWARD,
+			false,
+		},
+		{
+			&pbupdate.CanaryStatus{
+				State: pbupdate.CanaryState_CANARY_STATE_SUCCEEDED,
+			},
+			pbupdate.State_ROLLING_FORWARD,
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		cachedUpdate := cachedmocks.NewMockUpdate(suite.ctrl)
+		cachedUpdate.EXPECT().
+			GetCanaryStatus().
+			Return(test.canaryStatus).
+			AnyTimes()
+		cachedUpdate.EXPECT().
+			GetState().
+			Return(&cached.UpdateStateVector{State: test.updateState}).
+			AnyTimes()
+		suite.Equal(test.inProgress, isCanaryInProgress(cachedUpdate))
+	}
+}
+
+// TestGetCanaryInstancesForUpdateRun tests that only the unprocessed
+// canary instances are picked in an update run
+func (suite *UpdateCanaryTestSuite) TestGetCanaryInstancesForUpdateRun() {
+	suite.cachedUpdate.EXPECT().
+		GetCanaryStatus().
+		Return(&pbupdate.CanaryStatus{
+			State:     pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD,
+			Instances: []uint32{0, 1, 4},
+		})
+	suite.cachedUpdate.EXPECT().
+		GetInstancesAdded().
+		Return([]uint32{4, 5})
+	suite.cachedUpdate.EXPECT().
+		GetInstancesUpdated().
+		Return([]uint32{0, 1, 2, 3})
+	suite.cachedUpdate.EXPECT().
+		GetInstancesRemoved().
+		Return([]uint32{6})
+
+	instancesToAdd, instancesToUpdate := getCanaryInstancesForUpdateRun(
+		suite.cachedUpdate, []uint32{0}, nil, nil)
+	suite.Equal([]uint32{4}, instancesToAdd)
+	suite.Equal([]uint32{1}, instancesToUpdate)
+}
+
+// TestProcessCanaryStartBaking tests that the canary phase starts baking
+// once all the canary instances are healthy
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryStartBaking() {
+	suite.setupCanary(&pbupdate.CanaryStatus{
+		State:     pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD,
+		Instances: []uint32{0},
+	}, suite.healthyRuntime())
+
+	suite.cachedUpdate.EXPECT().
+		WriteCanaryProgress(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, canaryStatus *pbupdate.CanaryStatus) {
+			suite.Equal(pbupdate.CanaryState_CANARY_STATE_BAKING,
+				canaryStatus.GetState())
+			suite.NotEmpty(canaryStatus.GetBakeStartTime())
+		}).
+		Return(nil)
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		[]uint32{0},
+		nil,
+		nil,
+		suite.goalStateDriver,
+	))
+}
+
+// TestProcessCanaryWaitForHealthy tests that the canary phase waits
+// for the canary instances to be healthy
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryWaitForHealthy() {
+	runtime := suite.healthyRuntime()
+	runtime.Healthy = pbtask.HealthState_HEALTH_UNKNOWN
+	suite.setupCanary(&pbupdate.CanaryStatus{
+		State:     pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD,
+		Instances: []uint32{0},
+	}, runtime)
+
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		nil,
+		nil,
+		[]uint32{0},
+		suite.goalStateDriver,
+	))
+}
+
+// TestProcessCanaryWaitForReady tests that the canary phase waits for
+// healthy canary instances which are not ready yet
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryWaitForReady() {
+	runtime := suite.healthyRuntime()
+	runtime.Readiness = pbtask.HealthState_HEALTH_UNKNOWN
+	suite.setupCanary(&pbupdate.CanaryStatus{
+		State:     pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD,
+		Instances: []uint32{0},
+	}, runtime)
+
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		nil,
+		nil,
+		[]uint32{0},
+		suite.goalStateDriver,
+	))
+}
+
+// TestProcessCanaryBakeSucceeded tests that the canary phase succeeds
+// once the bake duration has elapsed
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryBakeSucceeded() {
+	bakeStartTime := time.Now().Add(-2 * time.Minute).UTC().
+		Format(time.RFC3339Nano)
+	suite.setupCanary(&pbupdate.CanaryStatus{
+		State:         pbupdate.CanaryState_CANARY_STATE_BAKING,
+		Instances:     []uint32{0},
+		BakeStartTime: bakeStartTime,
+	}, suite.healthyRuntime())
+
+	suite.cachedUpdate.EXPECT().
+		WriteCanaryProgress(gomock.Any(), &pbupdate.CanaryStatus{
+			State:         pbupdate.CanaryState_CANARY_STATE_SUCCEEDED,
+			Instances:     []uint32{0},
+			BakeStartTime: bakeStartTime,
+		}).
+		Return(nil)
+
+	suite.NoError(processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		[]uint32{0},
+		nil,
+		nil,
+		suite.goalStateDriver,
+	))
+}
+
+// TestProcessCanaryStillBaking tests that the canary phase keeps baking
+// before the bake duration has elapsed
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryStillBaking() {
+	suite.setupCanary(&pbupdate.CanaryStatus{
+		State:         pbupdate.CanaryState_CANARY_STATE_BAKING,
+		Instances:     []uint32{0},
+		BakeStartTime: time.Now().UTC().Format(time.RFC3339Nano),
+	}, suite.healthyRuntime())
+
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		[]uint32{0},
+		nil,
+		nil,
+		suite.goalStateDriver,
+	))
+}
+
+// TestProcessCanaryUnhealthyWhileBaking tests that the update fails if
+// a canary instance leaves the required health state while baking
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryUnhealthyWhileBaking() {
+	runtime := suite.healthyRuntime()
+	runtime.State = pbtask.TaskState_KILLED
+	suite.setupCanary(&pbupdate.CanaryStatus{
+		State:         pbupdate.CanaryState_CANARY_STATE_BAKING,
+		Instances:     []uint32{0},
+		BakeStartTime: time.Now().UTC().Format(time.RFC3339Nano),
+	}, runtime)
+
+	suite.cachedUpdate.EXPECT().
+		WriteCanaryProgress(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, canaryStatus *pbupdate.CanaryStatus) {
+			suite.Equal(pbupdate.CanaryState_CANARY_STATE_FAILED,
+				canaryStatus.GetState())
+		}).
+		Return(nil)
+	suite.cachedUpdate.EXPECT().
+		WriteProgress(
+			gomock.Any(),
+			pbupdate.State_FAILED,
+			[]uint32{0},
+			nil,
+			nil,
+		).Return(nil)
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		[]uint32{0},
+		nil,
+		nil,
+		suite.goalStateDriver,
+	))
+}
+
+// TestProcessCanaryInstanceFailed tests that the update fails if
+// a canary instance fails to be updated
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryInstanceFailed() {
+	suite.setupCanary(&pbupdate.CanaryStatus{
+		State:     pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD,
+		Instances: []uint32{0},
+	}, suite.healthyRuntime())
+
+	suite.cachedUpdate.EXPECT().
+		WriteCanaryProgress(gomock.Any(), gomock.Any()).
+		Return(nil)
+	suite.cachedUpdate.EXPECT().
+		WriteProgress(
+			gomock.Any(),
+			pbupdate.State_FAILED,
+			nil,
+			[]uint32{0},
+			nil,
+		).Return(nil)
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		nil,
+		[]uint32{0},
+		nil,
+		suite.goalStateDriver,
+	))
+}
+
+// TestProcessCanaryWriteProgressFail tests the failure to write
+// the progress of the canary phase
+func (suite *UpdateCanaryTestSuite) TestProcessCanaryWriteProgressFail() {
+	suite.setupCanary(&pbupdate.CanaryStatus{
+		State:     pbupdate.CanaryState_CANARY_STATE_ROLLING_FORWARD,
+		Instances: []uint32{0},
+	}, suite.healthyRuntime())
+
+	suite.cachedUpdate.EXPECT().
+		WriteCanaryProgress(gomock.Any(), gomock.Any()).
+		Return(fmt.Errorf("fake db error"))
+
+	suite.Error(processCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		[]uint32{0},
+		nil,
+		nil,
+		suite.goalStateDriver,
+	))
+}
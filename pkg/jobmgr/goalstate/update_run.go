@@ -100,9 +100,38 @@ func UpdateRun(ctx context.Context, entity goalstate.Entity) error {
 		return err
 	}
 
-	instancesToAdd, instancesToUpdate, instancesToRemove :=
-		getInstancesForUpdateRun(
+	// the canary instances are updated first, and the rest of the
+	// instances are updated only after the canary phase succeeds
+	if isCanaryInProgress(cachedWorkflow) {
+		if err := processCanary(
+			ctx,
+			cachedJob,
+			cachedWorkflow,
+			instancesDone,
+			instancesFailed,
+			instancesCurrent,
+			goalStateDriver,
+		); err != nil {
+			goalStateDriver.mtx.updateMetrics.UpdateRunFail.Inc(1)
+			return err
+		}
+
+		// failed canary has been processed by processCanary
+		if cachedWorkflow.GetCanaryStatus().GetState() ==
+			pbupdate.CanaryState_CANARY_STATE_FAILED {
+			return nil
+		}
+	}
+
+	var instancesToAdd, instancesToUpdate, instancesToRemove []uint32
+	if isCanaryInProgress(cachedWorkflow) {
+		instancesToAdd, instancesToUpdate = getCanaryInstancesForUpdateRun(
 			cachedWorkflow, instancesCurrent, instancesDone, instancesFailed)
+	} else {
+		instancesToAdd, instancesToUpdate, instancesToRemove =
+			getInstancesForUpdateRun(
+				cachedWorkflow, instancesCurrent, instancesDone, instancesFailed)
+	}
 
 	instancesToAdd, instancesToUpdate, instancesToRemove, instancesRemovedDone, err :=
 		confirmInstancesStatus(
@@ -99,6 +99,11 @@ func (suite *UpdateRunTestSuite) SetupTest() {
 	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
 	suite.cachedUpdate = cachedmocks.NewMockUpdate(suite.ctrl)
 	suite.cachedTask = cachedmocks.NewMockTask(suite.ctrl)
+
+	suite.cachedUpdate.EXPECT().
+		GetCanaryStatus().
+		Return(nil).
+		AnyTimes()
 }
 
 func (suite *UpdateRunTestSuite) TearDownTest() {
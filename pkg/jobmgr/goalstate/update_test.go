@@ -165,6 +165,7 @@ func (suite *UpdateGoalStateTestSuite) TestUpdateGetActionList() {
 func (suite *UpdateGoalStateTestSuite) TestUpdateSuggestAction() {
 	tt := []struct {
 		state          update.State
+		canaryState    update.CanaryState
 		instancesDone  []uint32
 		instancesTotal []uint32
 		action         UpdateAction
@@ -217,12 +218,27 @@ func (suite *UpdateGoalStateTestSuite) TestUpdateSuggestAction() {
 			instancesTotal: []uint32{1, 2, 3, 4, 5, 6},
 			action:         CompleteUpdateAction,
 		},
+		{
+			state:          update.State_ROLLING_FORWARD,
+			canaryState:    update.CanaryState_CANARY_STATE_BAKING,
+			instancesDone:  []uint32{1, 2},
+			instancesTotal: []uint32{1, 2},
+			action:         RunUpdateAction,
+		},
+		{
+			state:          update.State_ROLLING_FORWARD,
+			canaryState:    update.CanaryState_CANARY_STATE_SUCCEEDED,
+			instancesDone:  []uint32{1, 2},
+			instancesTotal: []uint32{1, 2},
+			action:         CompleteUpdateAction,
+		},
 	}
 
 	for _, test := range tt {
 		updateState := &cached.UpdateStateVector{
-			State:     test.state,
-			Instances: test.instancesDone,
+			State:       test.state,
+			Instances:   test.instancesDone,
+			CanaryState: test.canaryState,
 		}
 
 		updateGoalState := &cached.UpdateStateVector{
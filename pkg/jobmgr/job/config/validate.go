@@ -17,7 +17,10 @@ package jobconfig
 import (
 	"errors"
 	"fmt"
+	"math"
+	"path"
 	"reflect"
+	"strings"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
@@ -62,6 +65,24 @@ var (
 		"Data field not set in executor config")
 	errIncorrectRevocableSLA = yarpcerrors.InvalidArgumentErrorf(
 		"revocable job must be preemptible")
+	errIncorrectDaemonInstanceCount = yarpcerrors.InvalidArgumentErrorf(
+		"InstanceCount should be 0 for daemon job")
+	errIncorrectDaemonInstanceConfig = yarpcerrors.InvalidArgumentErrorf(
+		"InstanceConfig should not be set for daemon job")
+	errContainersExecutor = yarpcerrors.InvalidArgumentErrorf(
+		"Task with sidecar or init containers should not include executor config")
+	errContainersVolume = yarpcerrors.InvalidArgumentErrorf(
+		"Task with sidecar or init containers should not include persistent volume config")
+	errContainerNameMissing = yarpcerrors.InvalidArgumentErrorf(
+		"Container name is missing")
+	errIncorrectContainerType = yarpcerrors.InvalidArgumentErrorf(
+		"Only mesos containers are supported for task with sidecar or init containers")
+	errIncorrectReadinessCheck = yarpcerrors.InvalidArgumentErrorf(
+		"Batch job task should not set readiness check")
+	errReadinessCheckExecutor = yarpcerrors.InvalidArgumentErrorf(
+		"Task with custom executor should not set readiness check")
+	errInitContainerReadinessCheck = yarpcerrors.InvalidArgumentErrorf(
+		"Init container should not set readiness check")
 	errInvalidPreemptionOverride = yarpcerrors.InvalidArgumentErrorf(
 		"can't override the preemption policy of a task" +
 			" which is going to be a part of a gang having tasks with" +
@@ -70,11 +91,13 @@ var (
 	_jobTypeTaskValidate = map[job.JobType]func(*task.TaskConfig) error{
 		job.JobType_BATCH:   validateBatchTaskConfig,
 		job.JobType_SERVICE: validateStatelessTaskConfig,
+		job.JobType_DAEMON:  validateStatelessTaskConfig,
 	}
 
 	_jobTypeJobValidate = map[job.JobType]func(*job.JobConfig) error{
 		job.JobType_BATCH:   validateBatchJobConfig,
 		job.JobType_SERVICE: validateStatelessJobConfig,
+		job.JobType_DAEMON:  validateDaemonJobConfig,
 	}
 )
 
@@ -226,6 +249,18 @@ func validateTaskConfigWithRange(jobConfig *job.JobConfig, maxTasksPerJob uint32
 			return errInvalidTaskConfig(i, err)
 		}
 
+		if err := validateContainers(taskConfig); err != nil {
+			return errInvalidTaskConfig(i, err)
+		}
+
+		if err := validateHealthChecks(taskConfig); err != nil {
+			return errInvalidTaskConfig(i, err)
+		}
+
+		if err := validateReadinessChecks(taskConfig); err != nil {
+			return errInvalidTaskConfig(i, err)
+		}
+
 		if taskConfig.GetCommand() == nil {
 			return yarpcerrors.InvalidArgumentErrorf("missing command info for instance %v", i)
 		}
@@ -295,7 +330,7 @@ func validatePreemptionPolicy(instanceID uint32, taskConfig *task.TaskConfig,
 
 // validatePortConfig checks port name and port env name exists for dynamic port.
 func validatePortConfig(taskConfig *task.TaskConfig) error {
-	portConfigs := taskConfig.GetPorts()
+	portConfigs := taskconfig.GetAllPorts(taskConfig)
 	customExecutor := taskConfig.GetExecutor().GetType() == mesos.ExecutorInfo_CUSTOM
 	for _, port := range portConfigs {
 		if len(port.GetName()) == 0 {
@@ -308,12 +343,217 @@ func validatePortConfig(taskConfig *task.TaskConfig) error {
 	return nil
 }
 
+// validateContainers validates the sidecar and init containers of a task.
+// Every container needs a unique name, a shell command and resources, and
+// the port names need to be unique across all the containers of the task.
+func validateContainers(taskConfig *task.TaskConfig) error {
+	if !taskconfig.HasMultipleContainers(taskConfig) {
+		return nil
+	}
+
+	if taskConfig.GetExecutor() != nil {
+		return errContainersExecutor
+	}
+
+	if taskConfig.GetVolume() != nil {
+		return errContainersVolume
+	}
+
+	if err := validateContainerType(taskConfig.GetContainer()); err != nil {
+		return err
+	}
+	if err := validateContainerCommand(
+		taskConfig.GetName(),
+		taskConfig.GetCommand()); err != nil {
+		return err
+	}
+
+	containers := append(
+		append([]*task.ContainerConfig{}, taskConfig.GetSidecars()...),
+		taskConfig.GetInitContainers()...)
+	names := map[string]bool{taskConfig.GetName(): true}
+	for _, container := range containers {
+		name := container.GetName()
+		if len(name) == 0 {
+			return errContainerNameMissing
+		}
+		if names[name] {
+			return fmt.Errorf("duplicate container name %s", name)
+		}
+		names[name] = true
+
+		if err := validateContainerCommand(name, container.GetCommand()); err != nil {
+			return err
+		}
+		if container.GetResource() == nil {
+			return fmt.Errorf("missing resource config for container %s", name)
+		}
+		if err := validateContainerType(container.GetContainer()); err != nil {
+			return err
+		}
+	}
+
+	ports := make(map[string]bool)
+	for _, port := range taskconfig.GetAllPorts(taskConfig) {
+		if ports[port.GetName()] {
+			return fmt.Errorf("duplicate port name %s", port.GetName())
+		}
+		ports[port.GetName()] = true
+	}
+	return nil
+}
+
+// validateContainerCommand checks that the command of a container of a task
+// with multiple containers is a non-empty shell command. The commands are
+// run by /bin/sh, which also orders the containers after the init ones.
+func validateContainerCommand(name string, command *mesos.CommandInfo) error {
+	if command == nil {
+		return fmt.Errorf("missing command info for container %s", name)
+	}
+	if !command.GetShell() {
+		return fmt.Errorf("command of container %s must be a shell command", name)
+	}
+	if len(strings.TrimSpace(command.GetValue())) == 0 {
+		return fmt.Errorf("empty command for container %s", name)
+	}
+	return nil
+}
+
+// validateHealthChecks validates the health checks of the containers
+// of a task.
+func validateHealthChecks(taskConfig *task.TaskConfig) error {
+	if err := validateHealthCheck(taskConfig.GetHealthCheck()); err != nil {
+		return err
+	}
+	containers := append(
+		append([]*task.ContainerConfig{}, taskConfig.GetSidecars()...),
+		taskConfig.GetInitContainers()...)
+	for _, container := range containers {
+		if err := validateHealthCheck(container.GetHealthCheck()); err != nil {
+			return fmt.Errorf("container %s: %v", container.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// validateHealthCheck checks that a GRPC or TCP health check has a valid
+// port to connect to, and that a GRPC health check has the absolute path
+// of the probe calling the GRPC health service. A TCP check is to be used
+// to only check that the port accepts connections.
+func validateHealthCheck(health *task.HealthCheckConfig) error {
+	if health == nil || !health.GetEnabled() {
+		return nil
+	}
+
+	switch health.GetType() {
+	case task.HealthCheckConfig_GRPC:
+		grpcCheck := health.GetGrpcCheck()
+		if err := validateCheckPort("grpc", grpcCheck.GetPort()); err != nil {
+			return err
+		}
+		probePath := grpcCheck.GetProbePath()
+		if len(probePath) == 0 {
+			return errors.New("missing probe path for grpc health check")
+		}
+		if !path.IsAbs(probePath) {
+			return fmt.Errorf(
+				"probe path %s of grpc health check is not absolute", probePath)
+		}
+	case task.HealthCheckConfig_TCP:
+		return validateCheckPort("tcp", health.GetTcpCheck().GetPort())
+	}
+	return nil
+}
+
+// validateCheckPort checks that the port of a check is a valid TCP port.
+func validateCheckPort(checkType string, port uint32) error {
+	if port == 0 {
+		return fmt.Errorf("missing port for %s health check", checkType)
+	}
+	if port > math.MaxUint16 {
+		return fmt.Errorf("invalid port %d for %s health check", port, checkType)
+	}
+	return nil
+}
+
+// validateReadinessChecks validates the readiness checks of the containers
+// of a task. Readiness checks are run by the Mesos executors as checks, so
+// they are not supported with a custom executor nor for init containers,
+// which run to completion before the other containers start.
+func validateReadinessChecks(taskConfig *task.TaskConfig) error {
+	if taskConfig.GetReadinessCheck() != nil &&
+		taskConfig.GetExecutor() != nil {
+		return errReadinessCheckExecutor
+	}
+
+	for _, container := range taskConfig.GetInitContainers() {
+		if container.GetReadinessCheck() != nil {
+			return errInitContainerReadinessCheck
+		}
+	}
+
+	if err := validateReadinessCheck(taskConfig.GetReadinessCheck()); err != nil {
+		return err
+	}
+	for _, container := range taskConfig.GetSidecars() {
+		if err := validateReadinessCheck(container.GetReadinessCheck()); err != nil {
+			return fmt.Errorf("container %s: %v", container.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// validateReadinessCheck checks that a readiness check is of a type
+// supported by Mesos checks.
+func validateReadinessCheck(readiness *task.HealthCheckConfig) error {
+	if readiness == nil || !readiness.GetEnabled() {
+		return nil
+	}
+
+	switch readiness.GetType() {
+	case task.HealthCheckConfig_GRPC, task.HealthCheckConfig_TCP:
+		return validateHealthCheck(readiness)
+	case task.HealthCheckConfig_COMMAND:
+		if len(readiness.GetCommandCheck().GetCommand()) == 0 {
+			return errors.New("missing command for readiness check")
+		}
+	case task.HealthCheckConfig_HTTP:
+		if err := validateCheckPort("http", readiness.GetHttpCheck().GetPort()); err != nil {
+			return err
+		}
+		scheme := readiness.GetHttpCheck().GetScheme()
+		if len(scheme) != 0 && scheme != "http" {
+			return fmt.Errorf(
+				"unsupported scheme %s for http readiness check", scheme)
+		}
+	default:
+		return fmt.Errorf(
+			"unsupported readiness check type %s", readiness.GetType())
+	}
+	return nil
+}
+
+// validateContainerType checks that a container of a task group is
+// a mesos container, since nested containers are only supported for
+// the Mesos containerizer.
+func validateContainerType(container *mesos.ContainerInfo) error {
+	if container != nil &&
+		container.GetType() != mesos.ContainerInfo_MESOS {
+		return errIncorrectContainerType
+	}
+	return nil
+}
+
 // validateBatchJobConfig validate task config for batch job
 func validateBatchTaskConfig(taskConfig *task.TaskConfig) error {
 	// Healthy field should not be set for batch job
 	if taskConfig.GetHealthCheck() != nil {
 		return errIncorrectHealthCheck
 	}
+	// Readiness of batch tasks is not tracked
+	if taskConfig.GetReadinessCheck() != nil {
+		return errIncorrectReadinessCheck
+	}
 	// Batch jobs should not use custom executor (aurora thermos for now)
 	if taskConfig.GetExecutor() != nil {
 		return errIncorrectExecutor
@@ -371,3 +611,22 @@ func validateStatelessJobConfig(jobConfig *job.JobConfig) error {
 
 	return nil
 }
+
+// validateDaemonJobConfig validate jobconfig for daemon job
+func validateDaemonJobConfig(jobConfig *job.JobConfig) error {
+	if err := validateStatelessJobConfig(jobConfig); err != nil {
+		return err
+	}
+
+	// the instances of a daemon job are managed by job manager, one per
+	// eligible host, so the user should not specify them
+	if jobConfig.GetInstanceCount() != 0 {
+		return errIncorrectDaemonInstanceCount
+	}
+
+	if len(jobConfig.GetInstanceConfig()) != 0 {
+		return errIncorrectDaemonInstanceConfig
+	}
+
+	return nil
+}
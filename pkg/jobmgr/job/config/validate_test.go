@@ -426,6 +426,270 @@ func TestValidatePortConfigMissingEnvName_NoFailureCustomExecutor(t *testing.T)
 	assert.NoError(t, err)
 }
 
+// TestValidateContainers tests validation of the sidecar and
+// init containers of a task
+func TestValidateContainers(t *testing.T) {
+	newConfig := func() *task.TaskConfig {
+		return &task.TaskConfig{
+			Name:    "app",
+			Command: &mesos.CommandInfo{Value: util.PtrPrintf("./app")},
+			Ports:   []*task.PortConfig{{Name: "http", EnvName: "PORT_HTTP"}},
+			Sidecars: []*task.ContainerConfig{
+				{
+					Name:     "proxy",
+					Command:  &mesos.CommandInfo{Value: util.PtrPrintf("./proxy")},
+					Resource: &task.ResourceConfig{CpuLimit: 0.5},
+					Ports:    []*task.PortConfig{{Name: "proxy", EnvName: "PORT_PROXY"}},
+				},
+			},
+			InitContainers: []*task.ContainerConfig{
+				{
+					Name:     "setup",
+					Command:  &mesos.CommandInfo{Value: util.PtrPrintf("./setup")},
+					Resource: &task.ResourceConfig{CpuLimit: 0.5},
+				},
+			},
+		}
+	}
+
+	assert.NoError(t, validateContainers(&task.TaskConfig{}))
+	assert.NoError(t, validateContainers(newConfig()))
+
+	tests := []struct {
+		msg    string
+		modify func(*task.TaskConfig)
+	}{
+		{
+			msg: "custom executor",
+			modify: func(c *task.TaskConfig) {
+				c.Executor = &mesos.ExecutorInfo{Type: mesos.ExecutorInfo_CUSTOM.Enum()}
+			},
+		},
+		{
+			msg: "persistent volume",
+			modify: func(c *task.TaskConfig) {
+				c.Volume = &task.PersistentVolumeConfig{ContainerPath: "/data"}
+			},
+		},
+		{
+			msg: "docker container",
+			modify: func(c *task.TaskConfig) {
+				c.Sidecars[0].Container = &mesos.ContainerInfo{
+					Type: mesos.ContainerInfo_DOCKER.Enum(),
+				}
+			},
+		},
+		{
+			msg:    "missing container name",
+			modify: func(c *task.TaskConfig) { c.Sidecars[0].Name = "" },
+		},
+		{
+			msg:    "duplicate container name",
+			modify: func(c *task.TaskConfig) { c.InitContainers[0].Name = "app" },
+		},
+		{
+			msg:    "missing command",
+			modify: func(c *task.TaskConfig) { c.InitContainers[0].Command = nil },
+		},
+		{
+			msg: "command which is not a shell command",
+			modify: func(c *task.TaskConfig) {
+				shell := false
+				c.Sidecars[0].Command.Shell = &shell
+			},
+		},
+		{
+			msg:    "empty command",
+			modify: func(c *task.TaskConfig) { c.InitContainers[0].Command.Value = nil },
+		},
+		{
+			msg:    "empty command of the primary container",
+			modify: func(c *task.TaskConfig) { c.Command.Value = util.PtrPrintf(" ") },
+		},
+		{
+			msg:    "missing resource",
+			modify: func(c *task.TaskConfig) { c.Sidecars[0].Resource = nil },
+		},
+		{
+			msg:    "duplicate port name",
+			modify: func(c *task.TaskConfig) { c.Sidecars[0].Ports[0].Name = "http" },
+		},
+	}
+	for _, test := range tests {
+		taskConfig := newConfig()
+		test.modify(taskConfig)
+		assert.Error(t, validateContainers(taskConfig), test.msg)
+	}
+
+	// dynamic ports of sidecar containers need an environment variable
+	taskConfig := newConfig()
+	taskConfig.Sidecars[0].Ports[0].EnvName = ""
+	assert.Equal(t, errPortEnvNameMissing, validatePortConfig(taskConfig))
+}
+
+// TestValidateHealthChecks tests validation of the GRPC and TCP health
+// checks of the containers of a task
+func TestValidateHealthChecks(t *testing.T) {
+	newConfig := func() *task.TaskConfig {
+		return &task.TaskConfig{
+			Name: "app",
+			HealthCheck: &task.HealthCheckConfig{
+				Enabled: true,
+				Type:    task.HealthCheckConfig_GRPC,
+				GrpcCheck: &task.HealthCheckConfig_GRPCCheck{
+					Port:      8080,
+					Service:   "app.Service",
+					ProbePath: "/bin/grpc_health_probe",
+				},
+			},
+			Sidecars: []*task.ContainerConfig{
+				{
+					Name: "proxy",
+					HealthCheck: &task.HealthCheckConfig{
+						Enabled: true,
+						Type:    task.HealthCheckConfig_TCP,
+						TcpCheck: &task.HealthCheckConfig_TCPCheck{
+							Port: 8081,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	assert.NoError(t, validateHealthChecks(&task.TaskConfig{}))
+	assert.NoError(t, validateHealthChecks(newConfig()))
+
+	taskConfig := newConfig()
+	taskConfig.HealthCheck.GrpcCheck.Port = 0
+	assert.Error(t, validateHealthChecks(taskConfig))
+
+	taskConfig = newConfig()
+	taskConfig.Sidecars[0].HealthCheck.TcpCheck = nil
+	assert.Error(t, validateHealthChecks(taskConfig))
+
+	taskConfig = newConfig()
+	taskConfig.Sidecars[0].HealthCheck.TcpCheck.Port = 65536
+	assert.Error(t, validateHealthChecks(taskConfig))
+
+	// a grpc health check needs the absolute path of the probe,
+	// whether it checks a service or the overall server
+	taskConfig = newConfig()
+	taskConfig.HealthCheck.GrpcCheck.ProbePath = ""
+	assert.Error(t, validateHealthChecks(taskConfig))
+
+	taskConfig = newConfig()
+	taskConfig.HealthCheck.GrpcCheck.ProbePath = ""
+	taskConfig.HealthCheck.GrpcCheck.Service = ""
+	assert.Error(t, validateHealthChecks(taskConfig))
+
+	taskConfig = newConfig()
+	taskConfig.HealthCheck.GrpcCheck.ProbePath = "grpc_health_probe"
+	assert.Error(t, validateHealthChecks(taskConfig))
+
+	// disabled health checks are not validated
+	taskConfig.Sidecars[0].HealthCheck.Enabled = false
+	assert.NoError(t, validateHealthChecks(taskConfig))
+}
+
+// TestValidateReadinessChecks tests validation of the readiness checks
+// of the containers of a task
+func TestValidateReadinessChecks(t *testing.T) {
+	newConfig := func() *task.TaskConfig {
+		return &task.TaskConfig{
+			Name: "app",
+			ReadinessCheck: &task.HealthCheckConfig{
+				Enabled: true,
+				Type:    task.HealthCheckConfig_HTTP,
+				HttpCheck: &task.HealthCheckConfig_HTTPCheck{
+					Port: 8080,
+					Path: "/ready",
+				},
+			},
+			Sidecars: []*task.ContainerConfig{
+				{
+					Name: "proxy",
+					ReadinessCheck: &task.HealthCheckConfig{
+						Enabled: true,
+						Type:    task.HealthCheckConfig_COMMAND,
+						CommandCheck: &task.HealthCheckConfig_CommandCheck{
+							Command: "./ready",
+						},
+					},
+				},
+			},
+			InitContainers: []*task.ContainerConfig{{Name: "setup"}},
+		}
+	}
+
+	assert.NoError(t, validateReadinessChecks(&task.TaskConfig{}))
+	assert.NoError(t, validateReadinessChecks(newConfig()))
+
+	tests := []struct {
+		msg    string
+		modify func(*task.TaskConfig)
+		err    error
+	}{
+		{
+			msg: "custom executor",
+			modify: func(c *task.TaskConfig) {
+				c.Executor = &mesos.ExecutorInfo{Type: mesos.ExecutorInfo_CUSTOM.Enum()}
+			},
+			err: errReadinessCheckExecutor,
+		},
+		{
+			msg: "init container",
+			modify: func(c *task.TaskConfig) {
+				c.InitContainers[0].ReadinessCheck = &task.HealthCheckConfig{}
+			},
+			err: errInitContainerReadinessCheck,
+		},
+		{
+			msg: "missing command",
+			modify: func(c *task.TaskConfig) {
+				c.Sidecars[0].ReadinessCheck.CommandCheck.Command = ""
+			},
+		},
+		{
+			msg:    "missing port",
+			modify: func(c *task.TaskConfig) { c.ReadinessCheck.HttpCheck.Port = 0 },
+		},
+		{
+			msg: "https scheme",
+			modify: func(c *task.TaskConfig) {
+				c.ReadinessCheck.HttpCheck.Scheme = "https"
+			},
+		},
+		{
+			msg: "unsupported type",
+			modify: func(c *task.TaskConfig) {
+				c.ReadinessCheck.Type = task.HealthCheckConfig_UNKNOWN
+			},
+		},
+		{
+			msg: "missing port for tcp check",
+			modify: func(c *task.TaskConfig) {
+				c.ReadinessCheck.Type = task.HealthCheckConfig_TCP
+			},
+		},
+	}
+	for _, test := range tests {
+		taskConfig := newConfig()
+		test.modify(taskConfig)
+		err := validateReadinessChecks(taskConfig)
+		assert.Error(t, err, test.msg)
+		if test.err != nil {
+			assert.Equal(t, test.err, err, test.msg)
+		}
+	}
+
+	// disabled readiness checks are not validated
+	taskConfig := newConfig()
+	taskConfig.ReadinessCheck.Enabled = false
+	taskConfig.ReadinessCheck.HttpCheck.Port = 0
+	assert.NoError(t, validateReadinessChecks(taskConfig))
+}
+
 func TestValidateTaskConfigWithInvalidFieldType(t *testing.T) {
 	// Validates task config field type is string/ptr/slice/bool, otherwise
 	// we cannot distinguish between unset value and default value through
@@ -536,6 +800,59 @@ func TestValidateStatelessJobConfig(t *testing.T) {
 
 }
 
+func TestValidateDaemonJobConfig(t *testing.T) {
+	tt := []struct {
+		jobConfig *job.JobConfig
+		err       error
+	}{
+		{
+			jobConfig: &job.JobConfig{
+				Type:          job.JobType_DAEMON,
+				DefaultConfig: &task.TaskConfig{},
+				SLA:           &job.SlaConfig{},
+			},
+			err: nil,
+		},
+		{
+			jobConfig: &job.JobConfig{
+				Type:          job.JobType_DAEMON,
+				DefaultConfig: &task.TaskConfig{},
+				SLA: &job.SlaConfig{
+					MaximumRunningInstances: 1,
+				},
+			},
+			err: errIncorrectMaxInstancesSLA,
+		},
+		{
+			jobConfig: &job.JobConfig{
+				Type:          job.JobType_DAEMON,
+				InstanceCount: 3,
+				DefaultConfig: &task.TaskConfig{},
+				SLA:           &job.SlaConfig{},
+			},
+			err: errIncorrectDaemonInstanceCount,
+		},
+		{
+			jobConfig: &job.JobConfig{
+				Type:          job.JobType_DAEMON,
+				DefaultConfig: &task.TaskConfig{},
+				InstanceConfig: map[uint32]*task.TaskConfig{
+					0: {},
+				},
+				SLA: &job.SlaConfig{},
+			},
+			err: errIncorrectDaemonInstanceConfig,
+		},
+	}
+
+	for _, test := range tt {
+		assert.Equal(t, test.err, validateDaemonJobConfig(test.jobConfig))
+	}
+
+	// daemon job is a valid job type
+	assert.NoError(t, ValidateConfig(tt[0].jobConfig, maxTasksPerJob))
+}
+
 func TestValidateStatelessTaskConfig(t *testing.T) {
 	testMap := map[task.PreemptionPolicy]error{
 		{
@@ -567,6 +884,11 @@ func TestValidateBatchTaskConfig(t *testing.T) {
 		err := validateBatchTaskConfig(&taskConfig)
 		assert.Equal(t, err, errExp)
 	}
+
+	err := validateBatchTaskConfig(&task.TaskConfig{
+		ReadinessCheck: &task.HealthCheckConfig{Enabled: true},
+	})
+	assert.Equal(t, errIncorrectReadinessCheck, err)
 }
 
 // TestValidateTaskConfigFailureBatchExecutorConfig tests validation of
@@ -0,0 +1,454 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"context"
+	"time"
+
+	pbcron "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/leader"
+	jobmgrcron "github.com/uber/peloton/pkg/jobmgr/cron"
+	jobconfig "github.com/uber/peloton/pkg/jobmgr/job/config"
+	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// creator of the cron jobs created by unauthenticated calls
+const _defaultCreatedBy = "peloton"
+
+type serviceHandler struct {
+	cronJobOps     ormobjects.CronJobOps
+	cronJobRunOps  ormobjects.CronJobRunOps
+	cronController jobmgrcron.Controller
+	candidate      leader.Candidate
+	jobSvcCfg      jobsvc.Config
+}
+
+// InitV1AlphaCronJobServiceHandler initializes the Cron Job Service Handler
+func InitV1AlphaCronJobServiceHandler(
+	d *yarpc.Dispatcher,
+	ormStore *ormobjects.Store,
+	cronController jobmgrcron.Controller,
+	candidate leader.Candidate,
+	jobSvcCfg jobsvc.Config,
+) {
+	handler := &serviceHandler{
+		cronJobOps:     ormobjects.NewCronJobOps(ormStore),
+		cronJobRunOps:  ormobjects.NewCronJobRunOps(ormStore),
+		cronController: cronController,
+		candidate:      candidate,
+		jobSvcCfg:      jobSvcCfg,
+	}
+	d.Register(svc.BuildCronJobServiceYARPCProcedures(handler))
+}
+
+func (h *serviceHandler) CreateCronJob(
+	ctx context.Context,
+	req *svc.CreateCronJobRequest,
+) (resp *svc.CreateCronJobResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithField("name", req.GetSpec().GetName()).
+				WithError(err).
+				Warn("CronJobSVC.CreateCronJob failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("name", req.GetSpec().GetName()).
+			WithField("schedule", req.GetSpec().GetSchedule()).
+			Info("CronJobSVC.CreateCronJob succeeded")
+	}()
+
+	if !h.candidate.IsLeader() {
+		return nil,
+			yarpcerrors.UnavailableErrorf("CronJobSVC.CreateCronJob is not supported on non-leader")
+	}
+
+	if err := h.validateCronJobSpec(req.GetSpec()); err != nil {
+		return nil, err
+	}
+
+	if err := checkCronJobSpecPermission(ctx, req.GetSpec()); err != nil {
+		return nil, err
+	}
+
+	// the runs of the cron job are created on behalf of the
+	// user who created the cron job
+	createdBy := auth.GetUsername(ctx)
+	if len(createdBy) == 0 {
+		createdBy = _defaultCreatedBy
+	}
+
+	if err := h.cronJobOps.Create(ctx, req.GetSpec(), createdBy); err != nil {
+		return nil, errors.Wrap(err, "failed to create cron job in db")
+	}
+	return &svc.CreateCronJobResponse{}, nil
+}
+
+func (h *serviceHandler) ReplaceCronJob(
+	ctx context.Context,
+	req *svc.ReplaceCronJobRequest,
+) (resp *svc.ReplaceCronJobResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithField("name", req.GetSpec().GetName()).
+				WithError(err).
+				Warn("CronJobSVC.ReplaceCronJob failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("name", req.GetSpec().GetName()).
+			WithField("schedule", req.GetSpec().GetSchedule()).
+			Info("CronJobSVC.ReplaceCronJob succeeded")
+	}()
+
+	if !h.candidate.IsLeader() {
+		return nil,
+			yarpcerrors.UnavailableErrorf("CronJobSVC.ReplaceCronJob is not supported on non-leader")
+	}
+
+	if err := h.validateCronJobSpec(req.GetSpec()); err != nil {
+		return nil, err
+	}
+
+	cronJob, err := h.getCronJob(ctx, req.GetSpec().GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	// the user must be permitted on both the current
+	// and the new job template of the cron job
+	if err := checkCronJobPermission(ctx, cronJob); err != nil {
+		return nil, err
+	}
+	if err := checkCronJobSpecPermission(ctx, req.GetSpec()); err != nil {
+		return nil, err
+	}
+
+	if err := h.cronJobOps.UpdateSpec(ctx, req.GetSpec()); err != nil {
+		return nil, errors.Wrap(err, "failed to update cron job in db")
+	}
+	return &svc.ReplaceCronJobResponse{}, nil
+}
+
+func (h *serviceHandler) DeleteCronJob(
+	ctx context.Context,
+	req *svc.DeleteCronJobRequest,
+) (resp *svc.DeleteCronJobResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithField("name", req.GetName()).
+				WithError(err).
+				Warn("CronJobSVC.DeleteCronJob failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("name", req.GetName()).
+			Info("CronJobSVC.DeleteCronJob succeeded")
+	}()
+
+	if !h.candidate.IsLeader() {
+		return nil,
+			yarpcerrors.UnavailableErrorf("CronJobSVC.DeleteCronJob is not supported on non-leader")
+	}
+
+	cronJob, err := h.getCronJob(ctx, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkCronJobPermission(ctx, cronJob); err != nil {
+		return nil, err
+	}
+
+	// delete the cron job first so that no new run is recorded
+	// while its run history is removed
+	if err := h.cronJobOps.Delete(ctx, req.GetName()); err != nil {
+		return nil, errors.Wrap(err, "failed to delete cron job from db")
+	}
+
+	runs, err := h.cronJobRunOps.GetAll(ctx, req.GetName())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cron job runs from db")
+	}
+	for _, run := range runs {
+		if err := h.cronJobRunOps.Delete(ctx, req.GetName(), run.RunID); err != nil {
+			return nil, errors.Wrap(err, "failed to delete cron job run from db")
+		}
+	}
+	return &svc.DeleteCronJobResponse{}, nil
+}
+
+func (h *serviceHandler) GetCronJob(
+	ctx context.Context,
+	req *svc.GetCronJobRequest,
+) (resp *svc.GetCronJobResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithField("name", req.GetName()).
+				WithError(err).
+				Warn("CronJobSVC.GetCronJob failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("name", req.GetName()).
+			Debug("CronJobSVC.GetCronJob succeeded")
+	}()
+
+	cronJob, err := h.getCronJob(ctx, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := h.getCronJobInfo(cronJob)
+	if err != nil {
+		return nil, err
+	}
+	return &svc.GetCronJobResponse{CronJob: info}, nil
+}
+
+func (h *serviceHandler) StartCronJob(
+	ctx context.Context,
+	req *svc.StartCronJobRequest,
+) (resp *svc.StartCronJobResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithField("name", req.GetName()).
+				WithError(err).
+				Warn("CronJobSVC.StartCronJob failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("name", req.GetName()).
+			WithField("job_id", resp.GetJobId().GetValue()).
+			Info("CronJobSVC.StartCronJob succeeded")
+	}()
+
+	if !h.candidate.IsLeader() {
+		return nil,
+			yarpcerrors.UnavailableErrorf("CronJobSVC.StartCronJob is not supported on non-leader")
+	}
+
+	cronJob, err := h.getCronJob(ctx, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkCronJobPermission(ctx, cronJob); err != nil {
+		return nil, err
+	}
+
+	jobID, err := h.cronController.StartRun(ctx, req.GetName())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start cron job run")
+	}
+	return &svc.StartCronJobResponse{JobId: jobID}, nil
+}
+
+func (h *serviceHandler) ListCronJobRuns(
+	ctx context.Context,
+	req *svc.ListCronJobRunsRequest,
+) (resp *svc.ListCronJobRunsResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithField("name", req.GetName()).
+				WithError(err).
+				Warn("CronJobSVC.ListCronJobRuns failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("name", req.GetName()).
+			Debug("CronJobSVC.ListCronJobRuns succeeded")
+	}()
+
+	if _, err := h.getCronJob(ctx, req.GetName()); err != nil {
+		return nil, err
+	}
+
+	runObjs, err := h.cronJobRunOps.GetAll(ctx, req.GetName())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cron job runs from db")
+	}
+
+	if req.GetLimit() > 0 && int(req.GetLimit()) < len(runObjs) {
+		runObjs = runObjs[:req.GetLimit()]
+	}
+
+	var runs []*pbcron.CronJobRun
+	for _, runObj := range runObjs {
+		runs = append(runs, runObj.ToProto())
+	}
+	return &svc.ListCronJobRunsResponse{Runs: runs}, nil
+}
+
+func (h *serviceHandler) ListCronJobs(
+	ctx context.Context,
+	req *svc.ListCronJobsRequest,
+) (resp *svc.ListCronJobsResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithError(err).
+				Warn("CronJobSVC.ListCronJobs failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("count", len(resp.GetCronJobs())).
+			Debug("CronJobSVC.ListCronJobs succeeded")
+	}()
+
+	cronJobs, err := h.cronJobOps.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cron jobs from db")
+	}
+
+	var infos []*pbcron.CronJobInfo
+	for _, cronJob := range cronJobs {
+		info, err := h.getCronJobInfo(cronJob)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return &svc.ListCronJobsResponse{CronJobs: infos}, nil
+}
+
+// getCronJob returns the cron job with the given name, and a not found
+// error if it does not exist
+func (h *serviceHandler) getCronJob(
+	ctx context.Context,
+	name string,
+) (*ormobjects.CronJobObject, error) {
+	cronJob, err := h.cronJobOps.Get(ctx, name)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			return nil, yarpcerrors.NotFoundErrorf("cron job:%s not found", name)
+		}
+		return nil, errors.Wrap(err, "failed to get cron job from db")
+	}
+	return cronJob, nil
+}
+
+// checkCronJobPermission returns a permission denied error if the user
+// which called the API is not permitted on the job template of the cron job
+func checkCronJobPermission(
+	ctx context.Context,
+	cronJob *ormobjects.CronJobObject,
+) error {
+	if !auth.HasUser(ctx) {
+		return nil
+	}
+
+	spec, err := cronJob.GetSpec()
+	if err != nil {
+		return errors.Wrap(err, "failed to unmarshal cron job spec")
+	}
+	return checkCronJobSpecPermission(ctx, spec)
+}
+
+// checkCronJobSpecPermission returns a permission denied error if the user
+// which called the API is not permitted on the job template of the spec
+func checkCronJobSpecPermission(
+	ctx context.Context,
+	spec *pbcron.CronJobSpec,
+) error {
+	if !auth.HasUser(ctx) {
+		return nil
+	}
+
+	jobConfig, err := handlerutil.ConvertJobSpecToJobConfig(spec.GetJobSpec())
+	if err != nil {
+		return errors.Wrap(err, "failed to convert job spec")
+	}
+	return auth.CheckEntityPermission(ctx, handlerutil.JobEntity(jobConfig))
+}
+
+// getCronJobInfo returns the spec and the status of a cron job
+func (h *serviceHandler) getCronJobInfo(
+	cronJob *ormobjects.CronJobObject,
+) (*pbcron.CronJobInfo, error) {
+	spec, err := cronJob.GetSpec()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal cron job spec")
+	}
+
+	status := cronJob.GetStatus()
+	next, err := h.cronController.NextScheduledTime(cronJob)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get next scheduled time")
+	}
+	if !next.IsZero() {
+		status.NextScheduledTime = next.Format(time.RFC3339)
+	}
+
+	return &pbcron.CronJobInfo{
+		Spec:   spec,
+		Status: status,
+	}, nil
+}
+
+// validateCronJobSpec validates the name, the schedule and
+// the job template of a cron job
+func (h *serviceHandler) validateCronJobSpec(spec *pbcron.CronJobSpec) error {
+	if len(spec.GetName()) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf("cron job name is empty")
+	}
+
+	if _, err := jobmgrcron.ParseSchedule(spec.GetSchedule()); err != nil {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"invalid cron job schedule: %v", err)
+	}
+
+	if spec.GetJobSpec() == nil {
+		return yarpcerrors.InvalidArgumentErrorf("cron job has no job spec")
+	}
+
+	if spec.GetJobSpec().GetRespoolId() == nil {
+		return yarpcerrors.InvalidArgumentErrorf("resource pool ID is null")
+	}
+
+	jobConfig, err := handlerutil.ConvertJobSpecToJobConfig(spec.GetJobSpec())
+	if err != nil {
+		return errors.Wrap(err, "failed to convert job spec")
+	}
+
+	// the job template is validated as the stateless job spec it is,
+	// which allows the custom executor used by the jobs migrated from
+	// Aurora, even though each run creates a batch job
+	if err := jobconfig.ValidateConfig(
+		jobConfig,
+		h.jobSvcCfg.MaxTasksPerJob,
+	); err != nil {
+		return errors.Wrap(err, "invalid job spec")
+	}
+	return nil
+}
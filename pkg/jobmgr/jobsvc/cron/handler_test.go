@@ -0,0 +1,469 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	pbcron "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
+	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
+	cronmocks "github.com/uber/peloton/pkg/jobmgr/cron/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	testCronJobName = "test-cron-job"
+	testJobID       = "481d565e-28da-457d-8434-f6bb7faa0e95"
+)
+
+var testCmd = "echo test"
+
+type cronHandlerTestSuite struct {
+	suite.Suite
+
+	handler *serviceHandler
+
+	ctrl           *gomock.Controller
+	cronJobOps     *objectmocks.MockCronJobOps
+	cronJobRunOps  *objectmocks.MockCronJobRunOps
+	cronController *cronmocks.MockController
+	candidate      *leadermocks.MockCandidate
+}
+
+func (suite *cronHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.cronJobOps = objectmocks.NewMockCronJobOps(suite.ctrl)
+	suite.cronJobRunOps = objectmocks.NewMockCronJobRunOps(suite.ctrl)
+	suite.cronController = cronmocks.NewMockController(suite.ctrl)
+	suite.candidate = leadermocks.NewMockCandidate(suite.ctrl)
+	suite.handler = &serviceHandler{
+		cronJobOps:     suite.cronJobOps,
+		cronJobRunOps:  suite.cronJobRunOps,
+		cronController: suite.cronController,
+		candidate:      suite.candidate,
+		jobSvcCfg: jobsvc.Config{
+			MaxTasksPerJob: 100000,
+		},
+	}
+}
+
+func (suite *cronHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestCronHandler(t *testing.T) {
+	suite.Run(t, new(cronHandlerTestSuite))
+}
+
+// createCronJobSpec returns a valid cron job spec
+func (suite *cronHandlerTestSuite) createCronJobSpec() *pbcron.CronJobSpec {
+	return &pbcron.CronJobSpec{
+		Name:            testCronJobName,
+		Schedule:        "0 * * * *",
+		CollisionPolicy: pbcron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW,
+		JobSpec: &stateless.JobSpec{
+			Name:          testCronJobName,
+			InstanceCount: 1,
+			RespoolId:     &v1alphapeloton.ResourcePoolID{Value: "respool"},
+			DefaultSpec: &pod.PodSpec{
+				Containers: []*pod.ContainerSpec{
+					{
+						Command: &mesos.CommandInfo{Value: &testCmd},
+					},
+				},
+			},
+		},
+	}
+}
+
+// createCronJobObject returns the cron job object of a spec
+func (suite *cronHandlerTestSuite) createCronJobObject(
+	spec *pbcron.CronJobSpec,
+) *ormobjects.CronJobObject {
+	specBuffer, err := proto.Marshal(spec)
+	suite.NoError(err)
+	return &ormobjects.CronJobObject{
+		Name:              spec.GetName(),
+		Spec:              specBuffer,
+		CreationTime:      time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC),
+		LastScheduledTime: time.Date(2019, 3, 4, 11, 0, 0, 0, time.UTC),
+		LastRunJobID:      testJobID,
+	}
+}
+
+// TestCreateCronJob tests creating a cron job
+func (suite *cronHandlerTestSuite) TestCreateCronJob() {
+	spec := suite.createCronJobSpec()
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().Create(gomock.Any(), spec, _defaultCreatedBy).Return(nil)
+
+	resp, err := suite.handler.CreateCronJob(
+		context.Background(),
+		&svc.CreateCronJobRequest{Spec: spec})
+	suite.NoError(err)
+	suite.NotNil(resp)
+}
+
+// TestCreateCronJobNonLeader tests creating a cron job on a non-leader
+func (suite *cronHandlerTestSuite) TestCreateCronJobNonLeader() {
+	suite.candidate.EXPECT().IsLeader().Return(false)
+
+	_, err := suite.handler.CreateCronJob(
+		context.Background(),
+		&svc.CreateCronJobRequest{Spec: suite.createCronJobSpec()})
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestCreateCronJobInvalidSpec tests creating cron jobs with invalid specs
+func (suite *cronHandlerTestSuite) TestCreateCronJobInvalidSpec() {
+	noName := suite.createCronJobSpec()
+	noName.Name = ""
+
+	invalidSchedule := suite.createCronJobSpec()
+	invalidSchedule.Schedule = "every hour"
+
+	noJobSpec := suite.createCronJobSpec()
+	noJobSpec.JobSpec = nil
+
+	noRespool := suite.createCronJobSpec()
+	noRespool.JobSpec.RespoolId = nil
+
+	noCommand := suite.createCronJobSpec()
+	noCommand.JobSpec.DefaultSpec = nil
+
+	for _, spec := range []*pbcron.CronJobSpec{
+		noName,
+		invalidSchedule,
+		noJobSpec,
+		noRespool,
+		noCommand,
+	} {
+		suite.candidate.EXPECT().IsLeader().Return(true)
+
+		_, err := suite.handler.CreateCronJob(
+			context.Background(),
+			&svc.CreateCronJobRequest{Spec: spec})
+		suite.True(yarpcerrors.IsInvalidArgument(err))
+	}
+}
+
+// TestCreateCronJobAlreadyExists tests creating a cron job which exists
+func (suite *cronHandlerTestSuite) TestCreateCronJobAlreadyExists() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(yarpcerrors.AlreadyExistsErrorf("cron job exists"))
+
+	_, err := suite.handler.CreateCronJob(
+		context.Background(),
+		&svc.CreateCronJobRequest{Spec: suite.createCronJobSpec()})
+	suite.True(yarpcerrors.IsAlreadyExists(err))
+}
+
+// TestReplaceCronJob tests replacing the spec of a cron job
+func (suite *cronHandlerTestSuite) TestReplaceCronJob() {
+	spec := suite.createCronJobSpec()
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), testCronJobName).
+		Return(suite.createCronJobObject(spec), nil)
+	suite.cronJobOps.EXPECT().UpdateSpec(gomock.Any(), spec).Return(nil)
+
+	resp, err := suite.handler.ReplaceCronJob(
+		context.Background(),
+		&svc.ReplaceCronJobRequest{Spec: spec})
+	suite.NoError(err)
+	suite.NotNil(resp)
+}
+
+// TestReplaceCronJobNotFound tests replacing a cron job which does not exist
+func (suite *cronHandlerTestSuite) TestReplaceCronJobNotFound() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), testCronJobName).
+		Return(nil, gocql.ErrNotFound)
+
+	_, err := suite.handler.ReplaceCronJob(
+		context.Background(),
+		&svc.ReplaceCronJobRequest{Spec: suite.createCronJobSpec()})
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestDeleteCronJob tests deleting a cron job and its run history
+func (suite *cronHandlerTestSuite) TestDeleteCronJob() {
+	runs := []*ormobjects.CronJobRunObject{
+		{Name: testCronJobName, RunID: gocql.TimeUUID()},
+		{Name: testCronJobName, RunID: gocql.TimeUUID()},
+	}
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), testCronJobName).
+		Return(suite.createCronJobObject(suite.createCronJobSpec()), nil)
+	suite.cronJobOps.EXPECT().Delete(gomock.Any(), testCronJobName).Return(nil)
+	suite.cronJobRunOps.EXPECT().
+		GetAll(gomock.Any(), testCronJobName).
+		Return(runs, nil)
+	for _, run := range runs {
+		suite.cronJobRunOps.EXPECT().
+			Delete(gomock.Any(), testCronJobName, run.RunID).
+			Return(nil)
+	}
+
+	resp, err := suite.handler.DeleteCronJob(
+		context.Background(),
+		&svc.DeleteCronJobRequest{Name: testCronJobName})
+	suite.NoError(err)
+	suite.NotNil(resp)
+}
+
+// TestDeleteCronJobFail tests failing to delete a cron job
+func (suite *cronHandlerTestSuite) TestDeleteCronJobFail() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), testCronJobName).
+		Return(suite.createCronJobObject(suite.createCronJobSpec()), nil)
+	suite.cronJobOps.EXPECT().
+		Delete(gomock.Any(), testCronJobName).
+		Return(errors.New("test error"))
+
+	_, err := suite.handler.DeleteCronJob(
+		context.Background(),
+		&svc.DeleteCronJobRequest{Name: testCronJobName})
+	suite.Error(err)
+}
+
+// TestDeleteCronJobNotPermitted tests deleting a cron job
+// whose job template is not owned by the user
+func (suite *cronHandlerTestSuite) TestDeleteCronJobNotPermitted() {
+	procedure := "peloton.api.v1alpha.job.cron.svc.CronJobService::DeleteCronJob"
+	user := authmocks.NewMockUser(suite.ctrl)
+	ctx := auth.WithUser(context.Background(), user, procedure)
+	spec := suite.createCronJobSpec()
+	spec.JobSpec.OwningTeam = "team1"
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), testCronJobName).
+		Return(suite.createCronJobObject(spec), nil)
+	user.EXPECT().
+		IsPermittedOnEntity(procedure, &auth.Entity{OwningTeam: "team1"}).
+		Return(false)
+
+	_, err := suite.handler.DeleteCronJob(
+		ctx,
+		&svc.DeleteCronJobRequest{Name: testCronJobName})
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+}
+
+// TestGetCronJob tests getting the spec and status of a cron job
+func (suite *cronHandlerTestSuite) TestGetCronJob() {
+	spec := suite.createCronJobSpec()
+	cronJob := suite.createCronJobObject(spec)
+	next := time.Date(2019, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), testCronJobName).
+		Return(cronJob, nil)
+	suite.cronController.EXPECT().
+		NextScheduledTime(cronJob).
+		Return(next, nil)
+
+	resp, err := suite.handler.GetCronJob(
+		context.Background(),
+		&svc.GetCronJobRequest{Name: testCronJobName})
+	suite.NoError(err)
+	suite.True(proto.Equal(spec, resp.GetCronJob().GetSpec()))
+
+	status := resp.GetCronJob().GetStatus()
+	suite.Equal("2019-03-04T10:00:00Z", status.GetCreationTime())
+	suite.Equal("2019-03-04T11:00:00Z", status.GetLastScheduledTime())
+	suite.Equal("2019-03-04T12:00:00Z", status.GetNextScheduledTime())
+	suite.Equal(testJobID, status.GetLastRunJobId().GetValue())
+}
+
+// TestGetCronJobNotFound tests getting a cron job which does not exist
+func (suite *cronHandlerTestSuite) TestGetCronJobNotFound() {
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), testCronJobName).
+		Return(nil, gocql.ErrNotFound)
+
+	_, err := suite.handler.GetCronJob(
+		context.Background(),
+		&svc.GetCronJobRequest{Name: testCronJobName})
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestStartCronJob tests starting a run of a cron job
+func (suite *cronHandlerTestSuite) TestStartCronJob() {
+	jobID := &v1alphapeloton.JobID{Value: testJobID}
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), testCronJobName).
+		Return(suite.createCronJobObject(suite.createCronJobSpec()), nil)
+	suite.cronController.EXPECT().
+		StartRun(gomock.Any(), testCronJobName).
+		Return(jobID, nil)
+
+	resp, err := suite.handler.StartCronJob(
+		context.Background(),
+		&svc.StartCronJobRequest{Name: testCronJobName})
+	suite.NoError(err)
+	suite.Equal(jobID, resp.GetJobId())
+}
+
+// TestStartCronJobNotPermitted tests starting a run of a cron job
+// whose job template is not owned by the user
+func (suite *cronHandlerTestSuite) TestStartCronJobNotPermitted() {
+	procedure := "peloton.api.v1alpha.job.cron.svc.CronJobService::StartCronJob"
+	user := authmocks.NewMockUser(suite.ctrl)
+	ctx := auth.WithUser(context.Background(), user, procedure)
+	spec := suite.createCronJobSpec()
+	spec.JobSpec.OwningTeam = "team1"
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), testCronJobName).
+		Return(suite.createCronJobObject(spec), nil)
+	user.EXPECT().
+		IsPermittedOnEntity(procedure, &auth.Entity{OwningTeam: "team1"}).
+		Return(false)
+
+	_, err := suite.handler.StartCronJob(
+		ctx,
+		&svc.StartCronJobRequest{Name: testCronJobName})
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+}
+
+// TestStartCronJobFail tests failing to start a run of a cron job
+func (suite *cronHandlerTestSuite) TestStartCronJobFail() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), testCronJobName).
+		Return(suite.createCronJobObject(suite.createCronJobSpec()), nil)
+	suite.cronController.EXPECT().
+		StartRun(gomock.Any(), testCronJobName).
+		Return(nil, errors.New("test error"))
+
+	_, err := suite.handler.StartCronJob(
+		context.Background(),
+		&svc.StartCronJobRequest{Name: testCronJobName})
+	suite.Error(err)
+}
+
+// TestListCronJobRuns tests listing the runs of a cron job with a limit
+func (suite *cronHandlerTestSuite) TestListCronJobRuns() {
+	runs := []*ormobjects.CronJobRunObject{
+		{
+			Name:  testCronJobName,
+			RunID: gocql.TimeUUID(),
+			JobID: testJobID,
+			State: pbcron.CronJobRunState_CRON_JOB_RUN_STATE_LAUNCHED.String(),
+		},
+		{
+			Name:  testCronJobName,
+			RunID: gocql.TimeUUID(),
+			State: pbcron.CronJobRunState_CRON_JOB_RUN_STATE_SKIPPED.String(),
+		},
+	}
+
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), testCronJobName).
+		Return(suite.createCronJobObject(suite.createCronJobSpec()), nil).
+		Times(2)
+	suite.cronJobRunOps.EXPECT().
+		GetAll(gomock.Any(), testCronJobName).
+		Return(runs, nil).
+		Times(2)
+
+	resp, err := suite.handler.ListCronJobRuns(
+		context.Background(),
+		&svc.ListCronJobRunsRequest{Name: testCronJobName})
+	suite.NoError(err)
+	suite.Len(resp.GetRuns(), 2)
+	suite.Equal(testJobID, resp.GetRuns()[0].GetJobId().GetValue())
+	suite.Equal(
+		pbcron.CronJobRunState_CRON_JOB_RUN_STATE_SKIPPED,
+		resp.GetRuns()[1].GetState())
+
+	resp, err = suite.handler.ListCronJobRuns(
+		context.Background(),
+		&svc.ListCronJobRunsRequest{Name: testCronJobName, Limit: 1})
+	suite.NoError(err)
+	suite.Len(resp.GetRuns(), 1)
+}
+
+// TestListCronJobs tests listing all the cron jobs
+func (suite *cronHandlerTestSuite) TestListCronJobs() {
+	spec1 := suite.createCronJobSpec()
+	spec2 := suite.createCronJobSpec()
+	spec2.Name = "other-cron-job"
+	cronJob1 := suite.createCronJobObject(spec1)
+	cronJob2 := suite.createCronJobObject(spec2)
+
+	suite.cronJobOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*ormobjects.CronJobObject{cronJob1, cronJob2}, nil)
+	suite.cronController.EXPECT().
+		NextScheduledTime(gomock.Any()).
+		Return(time.Date(2019, 3, 4, 12, 0, 0, 0, time.UTC), nil).
+		Times(2)
+
+	resp, err := suite.handler.ListCronJobs(
+		context.Background(),
+		&svc.ListCronJobsRequest{})
+	suite.NoError(err)
+	suite.Len(resp.GetCronJobs(), 2)
+	suite.True(proto.Equal(spec1, resp.GetCronJobs()[0].GetSpec()))
+	suite.True(proto.Equal(spec2, resp.GetCronJobs()[1].GetSpec()))
+	suite.Equal(
+		"2019-03-04T12:00:00Z",
+		resp.GetCronJobs()[0].GetStatus().GetNextScheduledTime())
+}
+
+// TestListCronJobsFail tests failing to read the cron jobs from the db
+func (suite *cronHandlerTestSuite) TestListCronJobsFail() {
+	suite.cronJobOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	_, err := suite.handler.ListCronJobs(
+		context.Background(),
+		&svc.ListCronJobsRequest{})
+	suite.Error(err)
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/models"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
+	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/util"
@@ -143,7 +144,11 @@ func (h *serviceHandler) Create(
 
 	jobConfig := req.GetConfig()
 
-	respoolPath, err := h.validateResourcePool(jobConfig.GetRespoolID())
+	respoolPath, err := h.validateResourcePool(ctx, jobConfig.GetRespoolID())
+	if yarpcerrors.IsPermissionDenied(err) {
+		h.metrics.JobCreateFail.Inc(1)
+		return nil, err
+	}
 	if err != nil {
 		h.metrics.JobCreateFail.Inc(1)
 		return &job.CreateResponse{
@@ -259,6 +264,11 @@ func (h *serviceHandler) Update(
 		return nil, err
 	}
 
+	if err := auth.CheckEntityPermission(ctx, handler.JobEntity(oldConfig)); err != nil {
+		h.metrics.JobUpdateFail.Inc(1)
+		return nil, err
+	}
+
 	if oldConfig.GetType() != job.JobType_BATCH {
 		return nil, yarpcerrors.InvalidArgumentErrorf(
 			"job update is only supported for batch jobs")
@@ -499,6 +509,11 @@ func (h *serviceHandler) Delete(
 		return nil, yarpcerrors.NotFoundErrorf("job not found")
 	}
 
+	if err := handler.CheckJobPermission(ctx, req.GetId(), h.jobStore); err != nil {
+		h.metrics.JobDeleteFail.Inc(1)
+		return nil, err
+	}
+
 	if !util.IsPelotonJobStateTerminal(jobRuntime.State) {
 		h.metrics.JobDeleteFail.Inc(1)
 		return nil, yarpcerrors.InternalErrorf(
@@ -651,6 +666,10 @@ func (h *serviceHandler) createNonUpdateWorkflow(
 		return nil, 0, err
 	}
 
+	if err := auth.CheckEntityPermission(ctx, handler.JobEntity(jobConfig)); err != nil {
+		return nil, 0, err
+	}
+
 	if jobConfig.GetType() != job.JobType_SERVICE {
 		return nil, 0, yarpcerrors.InvalidArgumentErrorf(
 			"%s supported only for service jobs", workflowType.String())
@@ -767,9 +786,10 @@ func (h *serviceHandler) GetActiveJobs(
 
 // validateResourcePool validates the resource pool before submitting job
 func (h *serviceHandler) validateResourcePool(
+	ctx context.Context,
 	respoolID *peloton.ResourcePoolID,
 ) (*respool.ResourcePoolPath, error) {
-	ctx, cancelFunc := context.WithTimeout(h.rootCtx, 10*time.Second)
+	rpcCtx, cancelFunc := context.WithTimeout(h.rootCtx, 10*time.Second)
 	defer cancelFunc()
 
 	if respoolID == nil {
@@ -783,7 +803,7 @@ func (h *serviceHandler) validateResourcePool(
 	var request = &respool.GetRequest{
 		Id: respoolID,
 	}
-	response, err := h.respoolClient.GetResourcePool(ctx, request)
+	response, err := h.respoolClient.GetResourcePool(rpcCtx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -804,6 +824,14 @@ func (h *serviceHandler) validateResourcePool(
 		return nil, errNonLeafResourcePool
 	}
 
+	// the user submitting the job must be permitted on the target pool
+	if err := auth.CheckEntityPermission(
+		ctx,
+		handler.ResPoolEntity(response.GetPoolinfo().GetConfig()),
+	); err != nil {
+		return nil, err
+	}
+
 	return response.GetPoolinfo().GetPath(), nil
 }
 
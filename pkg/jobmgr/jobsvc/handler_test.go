@@ -32,6 +32,8 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 	resmocks "github.com/uber/peloton/.gen/peloton/private/resmgrsvc/mocks"
 
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
 	"github.com/uber/peloton/pkg/common"
 	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
 	"github.com/uber/peloton/pkg/common/util"
@@ -921,7 +923,7 @@ func (suite *JobHandlerTestSuite) TestValidateResourcePool_Failure() {
 				gomock.Eq(request)).
 			Return(t.getRespoolResponse, t.getRespoolError).MaxTimes(1)
 
-		respoolPath, errResponse := suite.handler.validateResourcePool(respoolID)
+		respoolPath, errResponse := suite.handler.validateResourcePool(context.Background(), respoolID)
 		suite.Error(errResponse)
 		suite.Equal(t.errMsg, errResponse.Error())
 		suite.Nil(respoolPath)
@@ -1664,6 +1666,33 @@ func (suite *JobHandlerTestSuite) TestJobQueryFailure() {
 	suite.Equal(expectedErr, resp.GetError())
 }
 
+// TestJobDeleteNotPermitted tests that a job cannot be deleted by a user
+// which does not own it
+func (suite *JobHandlerTestSuite) TestJobDeleteNotPermitted() {
+	id := &peloton.JobID{
+		Value: "my-job",
+	}
+	procedure := "peloton.api.v0.job.JobManager::Delete"
+	config := &job.JobConfig{OwningTeam: "team1"}
+	user := authmocks.NewMockUser(suite.ctrl)
+	ctx := auth.WithUser(suite.context, user, procedure)
+
+	suite.mockedJobFactory.EXPECT().GetJob(id).
+		Return(suite.mockedCachedJob)
+	suite.mockedCachedJob.EXPECT().GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{State: job.JobState_SUCCEEDED}, nil)
+	suite.mockedJobStore.EXPECT().
+		GetJobConfig(gomock.Any(), id.GetValue()).
+		Return(config, nil, nil)
+	user.EXPECT().
+		IsPermittedOnEntity(procedure, &auth.Entity{OwningTeam: "team1"}).
+		Return(false)
+
+	res, err := suite.handler.Delete(ctx, &job.DeleteRequest{Id: id})
+	suite.Nil(res)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+}
+
 func (suite *JobHandlerTestSuite) TestJobDelete() {
 	id := &peloton.JobID{
 		Value: "my-job",
@@ -34,6 +34,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/models"
 	"github.com/uber/peloton/pkg/common/concurrency"
 
+	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/util"
@@ -51,6 +52,7 @@ import (
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
 	"github.com/gocql/gocql"
+	"github.com/gogo/protobuf/proto"
 	"github.com/pborman/uuid"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -292,12 +294,109 @@ func (h *serviceHandler) ReplaceJob(
 		return nil, errors.Wrap(err, "invalid job spec")
 	}
 
-	jobID := &peloton.JobID{Value: req.GetJobId().GetValue()}
+	var newEntityVersion *v1alphapeloton.EntityVersion
+	updateID, newEntityVersion, err = h.updateJobConfig(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		req.GetVersion(),
+		req.GetUpdateSpec(),
+		req.GetOpaqueData(),
+		func(prevJobConfig *pbjob.JobConfig) (*pbjob.JobConfig, error) {
+			return jobConfig, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &svc.ReplaceJobResponse{Version: newEntityVersion}, nil
+}
+
+func (h *serviceHandler) PatchJob(
+	ctx context.Context,
+	req *svc.PatchJobRequest) (resp *svc.PatchJobResponse, err error) {
+	var updateID *peloton.UpdateID
+
+	defer func() {
+		jobID := req.GetJobId().GetValue()
+		entityVersion := req.GetVersion().GetValue()
+
+		if err != nil {
+			log.WithField("job_id", jobID).
+				WithField("entity_version", entityVersion).
+				WithError(err).
+				Warn("JobSVC.PatchJob failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("job_id", jobID).
+			WithField("entity_version", entityVersion).
+			WithField("response", resp).
+			WithField("update_id", updateID.GetValue()).
+			Info("JobSVC.PatchJob succeeded")
+	}()
+
+	if !h.candidate.IsLeader() {
+		return nil,
+			yarpcerrors.UnavailableErrorf("JobSVC.PatchJob is not supported on non-leader")
+	}
 
+	jobUUID := uuid.Parse(req.GetJobId().GetValue())
+	if jobUUID == nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"JobID must be of UUID format")
+	}
+
+	if len(req.GetSecrets()) > 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"secrets are not supported by patch job")
+	}
+
+	patch, err := handlerutil.ConvertJobSpecToJobConfig(req.GetSpec())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert job spec")
+	}
+
+	var newEntityVersion *v1alphapeloton.EntityVersion
+	updateID, newEntityVersion, err = h.updateJobConfig(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		req.GetVersion(),
+		req.GetUpdateSpec(),
+		req.GetOpaqueData(),
+		func(prevJobConfig *pbjob.JobConfig) (*pbjob.JobConfig, error) {
+			jobConfig := patchJobConfig(prevJobConfig, patch)
+			if err := jobconfig.ValidateConfig(
+				jobConfig,
+				h.jobSvcCfg.MaxTasksPerJob,
+			); err != nil {
+				return nil, errors.Wrap(err, "invalid patched job spec")
+			}
+			return jobConfig, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &svc.PatchJobResponse{Version: newEntityVersion}, nil
+}
+
+// updateJobConfig creates an update workflow which moves the job to the
+// job config returned by getJobConfig, given the current job config.
+func (h *serviceHandler) updateJobConfig(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	entityVersion *v1alphapeloton.EntityVersion,
+	updateSpec *stateless.UpdateSpec,
+	opaqueData *v1alphapeloton.OpaqueData,
+	getJobConfig func(prevJobConfig *pbjob.JobConfig) (*pbjob.JobConfig, error),
+) (*peloton.UpdateID, *v1alphapeloton.EntityVersion, error) {
 	cachedJob := h.jobFactory.AddJob(jobID)
 	jobRuntime, err := cachedJob.GetRuntime(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get job runtime from cache")
+		return nil, nil, errors.Wrap(err, "failed to get job runtime from cache")
 	}
 
 	prevJobConfig, prevConfigAddOn, err := h.jobStore.GetJobConfigWithVersion(
@@ -305,11 +404,20 @@ func (h *serviceHandler) ReplaceJob(
 		jobID.GetValue(),
 		jobRuntime.GetConfigurationVersion())
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get previous job spec")
+		return nil, nil, errors.Wrap(err, "failed to get previous job spec")
+	}
+
+	if err := auth.CheckEntityPermission(ctx, handlerutil.JobEntity(prevJobConfig)); err != nil {
+		return nil, nil, err
+	}
+
+	jobConfig, err := getJobConfig(prevJobConfig)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if err := validateJobConfigUpdate(prevJobConfig, jobConfig); err != nil {
-		return nil, errors.Wrap(err, "failed to validate spec update")
+		return nil, nil, errors.Wrap(err, "failed to validate spec update")
 	}
 
 	// get the new configAddOn
@@ -324,9 +432,9 @@ func (h *serviceHandler) ReplaceJob(
 	}
 
 	opaque := cached.WithOpaqueData(nil)
-	if req.GetOpaqueData() != nil {
+	if opaqueData != nil {
 		opaque = cached.WithOpaqueData(&peloton.OpaqueData{
-			Data: req.GetOpaqueData().GetData(),
+			Data: opaqueData.GetData(),
 		})
 	}
 
@@ -339,8 +447,8 @@ func (h *serviceHandler) ReplaceJob(
 	updateID, newEntityVersion, err := cachedJob.CreateWorkflow(
 		ctx,
 		models.WorkflowType_UPDATE,
-		handlerutil.ConvertUpdateSpecToUpdateConfig(req.GetUpdateSpec()),
-		req.GetVersion(),
+		handlerutil.ConvertUpdateSpecToUpdateConfig(updateSpec),
+		entityVersion,
 		cached.WithConfig(jobConfig, prevJobConfig, configAddOn),
 		opaque,
 	)
@@ -357,16 +465,10 @@ func (h *serviceHandler) ReplaceJob(
 	}
 
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create update workload")
+		return nil, nil, errors.Wrap(err, "failed to create update workload")
 	}
 
-	return &svc.ReplaceJobResponse{Version: newEntityVersion}, nil
-}
-
-func (h *serviceHandler) PatchJob(
-	ctx context.Context,
-	req *svc.PatchJobRequest) (*svc.PatchJobResponse, error) {
-	return &svc.PatchJobResponse{}, nil
+	return updateID, newEntityVersion, nil
 }
 
 func (h *serviceHandler) RestartJob(
@@ -406,6 +508,10 @@ func (h *serviceHandler) RestartJob(
 		return nil, errors.Wrap(err, "fail to get job config")
 	}
 
+	if err := auth.CheckEntityPermission(ctx, handlerutil.JobEntity(jobConfig)); err != nil {
+		return nil, err
+	}
+
 	// copy the config with provided resource version number
 	newConfig := *jobConfig
 	now := time.Now()
@@ -485,6 +591,13 @@ func (h *serviceHandler) PauseJobWorkflow(
 			Info("JobSVC.PauseJobWorkflow succeeded")
 	}()
 
+	if err := handlerutil.CheckJobPermission(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobStore); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&peloton.JobID{Value: req.GetJobId().GetValue()})
 	opaque := cached.WithOpaqueData(nil)
 	if req.GetOpaqueData() != nil {
@@ -531,6 +644,13 @@ func (h *serviceHandler) ResumeJobWorkflow(
 		return nil, yarpcerrors.UnavailableErrorf("JobSVC.ResumeJobWorkflow is not supported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobPermission(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobStore); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&peloton.JobID{Value: req.GetJobId().GetValue()})
 	opaque := cached.WithOpaqueData(nil)
 	if req.GetOpaqueData() != nil {
@@ -577,6 +697,13 @@ func (h *serviceHandler) AbortJobWorkflow(
 		return nil, yarpcerrors.UnavailableErrorf("JobSVC.AbortJobWorkflow is not supported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobPermission(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobStore); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&peloton.JobID{Value: req.GetJobId().GetValue()})
 	opaque := cached.WithOpaqueData(nil)
 	if req.GetOpaqueData() != nil {
@@ -625,6 +752,9 @@ func (h *serviceHandler) StartJob(
 	}
 
 	pelotonJobID := &peloton.JobID{Value: req.GetJobId().GetValue()}
+	if err := handlerutil.CheckJobPermission(ctx, pelotonJobID, h.jobStore); err != nil {
+		return nil, err
+	}
 
 	var jobRuntime *pbjob.RuntimeInfo
 	count := 0
@@ -695,6 +825,13 @@ func (h *serviceHandler) StopJob(
 		return nil, yarpcerrors.UnavailableErrorf("JobSVC.StopJob is not supported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobPermission(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobStore); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&peloton.JobID{
 		Value: req.GetJobId().GetValue(),
 	})
@@ -764,6 +901,13 @@ func (h *serviceHandler) DeleteJob(
 		return nil, yarpcerrors.UnavailableErrorf("JobSVC.DeleteJob is not supported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobPermission(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		h.jobStore); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&peloton.JobID{
 		Value: req.GetJobId().GetValue(),
 	})
@@ -1130,14 +1274,16 @@ func (h *serviceHandler) QueryPods(
 		return nil, errors.Wrap(err, "failed to query tasks from DB")
 	}
 
-	h.fillReasonForPendingTasksFromResMgr(
+	podInfos := handlerutil.ConvertTaskInfosToPodInfos(taskInfos)
+	h.fillReasonForPendingPodsFromResMgr(
 		ctx,
 		req.GetJobId().GetValue(),
 		taskInfos,
+		podInfos,
 	)
 
 	return &svc.QueryPodsResponse{
-		Pods: handlerutil.ConvertTaskInfosToPodInfos(taskInfos),
+		Pods: podInfos,
 		Pagination: &v1alphaquery.Pagination{
 			Offset: req.GetPagination().GetOffset(),
 			Limit:  req.GetPagination().GetLimit(),
@@ -1581,6 +1727,58 @@ func validateJobConfigUpdate(
 	return nil
 }
 
+// patchJobConfig returns a copy of the job config with the fields set
+// in the patch replaced. The instance configs in the patch are added to,
+// or replace, the instance configs of the job config.
+func patchJobConfig(
+	jobConfig *pbjob.JobConfig,
+	patch *pbjob.JobConfig,
+) *pbjob.JobConfig {
+	result := proto.Clone(jobConfig).(*pbjob.JobConfig)
+
+	if len(patch.GetName()) > 0 {
+		result.Name = patch.GetName()
+	}
+	if len(patch.GetOwner()) > 0 {
+		result.Owner = patch.GetOwner()
+	}
+	if len(patch.GetOwningTeam()) > 0 {
+		result.OwningTeam = patch.GetOwningTeam()
+	}
+	if len(patch.GetLdapGroups()) > 0 {
+		result.LdapGroups = patch.GetLdapGroups()
+	}
+	if len(patch.GetDescription()) > 0 {
+		result.Description = patch.GetDescription()
+	}
+	if len(patch.GetLabels()) > 0 {
+		result.Labels = patch.GetLabels()
+	}
+	if patch.GetInstanceCount() > 0 {
+		result.InstanceCount = patch.GetInstanceCount()
+	}
+	if patch.GetSLA() != nil {
+		result.SLA = patch.GetSLA()
+	}
+	if patch.GetDefaultConfig() != nil {
+		result.DefaultConfig = patch.GetDefaultConfig()
+		result.DefaultConfig.Revocable = result.GetSLA().GetRevocable()
+	}
+	if len(patch.GetInstanceConfig()) > 0 {
+		if result.InstanceConfig == nil {
+			result.InstanceConfig = make(map[uint32]*task.TaskConfig)
+		}
+		for instanceID, instanceConfig := range patch.GetInstanceConfig() {
+			instanceConfig.Revocable = result.GetSLA().GetRevocable()
+			result.InstanceConfig[instanceID] = instanceConfig
+		}
+	}
+	if patch.GetRespoolID() != nil {
+		result.RespoolID = patch.GetRespoolID()
+	}
+	return result
+}
+
 func convertCacheJobConfigToJobSpec(config jobmgrcommon.JobConfig) *stateless.JobSpec {
 	result := &stateless.JobSpec{}
 	// set the fields used by both job config and cached job config
@@ -1685,6 +1883,14 @@ func (h *serviceHandler) validateResourcePoolForJobCreation(
 		return nil, errNonLeafResourcePool
 	}
 
+	// the user submitting the job must be permitted on the target pool
+	if err := auth.CheckEntityPermission(
+		ctx,
+		handlerutil.ResPoolEntity(response.GetPoolinfo().GetConfig()),
+	); err != nil {
+		return nil, err
+	}
+
 	return response.GetPoolinfo().GetPath(), nil
 }
 
@@ -1845,19 +2051,20 @@ func convertInstanceIDRangesToSlice(ranges []*pod.InstanceIDRange, instanceCount
 }
 
 // TODO: remove this function once eventstream is enabled in RM
-// fillReasonForPendingTasksFromResMgr takes a list of taskinfo and
-// fills in the reason for pending tasks from ResourceManager.
-// All the tasks in `taskInfos` should belong to the same job
-func (h *serviceHandler) fillReasonForPendingTasksFromResMgr(
+// fillReasonForPendingPodsFromResMgr fills in the reason and the pending
+// reason for pending pods from ResourceManager. podInfos are converted from
+// `taskInfos` in the same order, and all of them should belong to the same job
+func (h *serviceHandler) fillReasonForPendingPodsFromResMgr(
 	ctx context.Context,
 	jobID string,
 	taskInfos []*task.TaskInfo,
+	podInfos []*pod.PodInfo,
 ) {
 	// only need to consult ResourceManager for PENDING tasks,
 	// because only tasks with PENDING states are being processed by ResourceManager
-	for _, taskInfo := range taskInfos {
+	for i, taskInfo := range taskInfos {
 		if taskInfo.GetRuntime().GetState() == task.TaskState_PENDING {
-			// attach the reason from the taskEntry in activeRMTasks
+			// attach the reasons from the taskEntry in activeRMTasks
 			taskEntry := h.activeRMTasks.GetTask(
 				util.CreatePelotonTaskID(
 					jobID,
@@ -1865,7 +2072,8 @@ func (h *serviceHandler) fillReasonForPendingTasksFromResMgr(
 				),
 			)
 			if taskEntry != nil {
-				taskInfo.GetRuntime().Reason = taskEntry.GetReason()
+				podInfos[i].GetStatus().Reason = taskEntry.GetReason()
+				podInfos[i].GetStatus().PendingReason = taskEntry.GetPendingReason()
 			}
 		}
 	}
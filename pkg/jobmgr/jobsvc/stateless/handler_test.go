@@ -38,6 +38,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/models"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
+	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/util"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
@@ -48,6 +49,7 @@ import (
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
 	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
 	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
 	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
@@ -1193,6 +1195,185 @@ func (suite *statelessHandlerTestSuite) TestReplaceJobGetJobConfigFailure() {
 	suite.Nil(resp)
 }
 
+// TestPatchJobSuccess tests the success case of patching a job
+func (suite *statelessHandlerTestSuite) TestPatchJobSuccess() {
+	opaque := "test"
+
+	suite.candidate.EXPECT().
+		IsLeader().
+		Return(true)
+
+	suite.jobFactory.EXPECT().
+		AddJob(&peloton.JobID{Value: testJobID}).
+		Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&pbjob.RuntimeInfo{
+			State:                pbjob.JobState_RUNNING,
+			WorkflowVersion:      testWorkflowVersion,
+			ConfigurationVersion: testConfigurationVersion,
+		}, nil)
+
+	suite.jobStore.EXPECT().
+		GetJobConfigWithVersion(
+			gomock.Any(),
+			testJobID,
+			testConfigurationVersion,
+		).Return(
+		&pbjob.JobConfig{
+			Type:          pbjob.JobType_SERVICE,
+			InstanceCount: 2,
+		},
+		&models.ConfigAddOn{},
+		nil)
+
+	suite.cachedJob.EXPECT().
+		CreateWorkflow(
+			gomock.Any(),
+			models.WorkflowType_UPDATE,
+			&pbupdate.UpdateConfig{
+				BatchSize: 1,
+			},
+			&v1alphapeloton.EntityVersion{Value: testEntityVersion},
+			gomock.Any(),
+		).
+		Return(
+			&peloton.UpdateID{Value: testUpdateID},
+			&v1alphapeloton.EntityVersion{Value: "3-3-5"},
+			nil)
+
+	suite.goalStateDriver.EXPECT().
+		EnqueueUpdate(
+			&peloton.JobID{Value: testJobID},
+			&peloton.UpdateID{Value: testUpdateID},
+			gomock.Any()).
+		Return()
+
+	resp, err := suite.handler.PatchJob(
+		context.Background(),
+		&statelesssvc.PatchJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: testJobID},
+			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
+			Spec:    &stateless.JobSpec{InstanceCount: 3},
+			UpdateSpec: &stateless.UpdateSpec{
+				BatchSize: 1,
+			},
+			OpaqueData: &v1alphapeloton.OpaqueData{Data: opaque},
+		},
+	)
+	suite.NoError(err)
+	suite.Equal("3-3-5", resp.GetVersion().GetValue())
+}
+
+// TestPatchJobFailNonLeader tests the failure case of patching a job
+// due to JobMgr is not leader
+func (suite *statelessHandlerTestSuite) TestPatchJobFailNonLeader() {
+	suite.candidate.EXPECT().
+		IsLeader().
+		Return(false)
+
+	resp, err := suite.handler.PatchJob(
+		context.Background(),
+		&statelesssvc.PatchJobRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		})
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestPatchJobSecretsNotSupported tests the failure case of patching
+// the secrets of a job
+func (suite *statelessHandlerTestSuite) TestPatchJobSecretsNotSupported() {
+	suite.candidate.EXPECT().
+		IsLeader().
+		Return(true)
+
+	resp, err := suite.handler.PatchJob(
+		context.Background(),
+		&statelesssvc.PatchJobRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+			Secrets: []*v1alphapeloton.Secret{
+				{Path: testSecretPath},
+			},
+		})
+	suite.Nil(resp)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestPatchJobGetJobConfigFailure tests the failure case of patching
+// a job due to not able to get job config
+func (suite *statelessHandlerTestSuite) TestPatchJobGetJobConfigFailure() {
+	suite.candidate.EXPECT().
+		IsLeader().
+		Return(true)
+
+	suite.jobFactory.EXPECT().
+		AddJob(&peloton.JobID{Value: testJobID}).
+		Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&pbjob.RuntimeInfo{
+			State:                pbjob.JobState_RUNNING,
+			WorkflowVersion:      testWorkflowVersion,
+			ConfigurationVersion: testConfigurationVersion,
+		}, nil)
+
+	suite.jobStore.EXPECT().
+		GetJobConfigWithVersion(
+			gomock.Any(),
+			testJobID,
+			testConfigurationVersion,
+		).Return(nil, nil, yarpcerrors.InternalErrorf("test error"))
+
+	resp, err := suite.handler.PatchJob(
+		context.Background(),
+		&statelesssvc.PatchJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: testJobID},
+			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
+			Spec:    &stateless.JobSpec{InstanceCount: 3},
+		},
+	)
+	suite.Error(err)
+	suite.Nil(resp)
+}
+
+// TestPatchJobConfig tests patching the fields of a job config
+func (suite *statelessHandlerTestSuite) TestPatchJobConfig() {
+	jobConfig := &pbjob.JobConfig{
+		Type:          pbjob.JobType_SERVICE,
+		Name:          testJobName,
+		InstanceCount: 2,
+		SLA: &pbjob.SlaConfig{
+			Revocable: true,
+		},
+		DefaultConfig: &pbtask.TaskConfig{Name: "default"},
+		InstanceConfig: map[uint32]*pbtask.TaskConfig{
+			0: {Name: "instance-0"},
+		},
+	}
+
+	patched := patchJobConfig(jobConfig, &pbjob.JobConfig{
+		InstanceCount: 3,
+		InstanceConfig: map[uint32]*pbtask.TaskConfig{
+			2: {Name: "instance-2"},
+		},
+	})
+
+	suite.Equal(testJobName, patched.GetName())
+	suite.Equal(uint32(3), patched.GetInstanceCount())
+	suite.Equal("default", patched.GetDefaultConfig().GetName())
+	suite.Len(patched.GetInstanceConfig(), 2)
+	suite.Equal("instance-0", patched.GetInstanceConfig()[0].GetName())
+	suite.Equal("instance-2", patched.GetInstanceConfig()[2].GetName())
+	suite.True(patched.GetInstanceConfig()[2].GetRevocable())
+
+	// the original job config is not modified
+	suite.Equal(uint32(2), jobConfig.GetInstanceCount())
+	suite.Len(jobConfig.GetInstanceConfig(), 1)
+}
+
 // TestGetReplaceJobDiffSuccess tests the success case of getting the
 // difference in configuration for ReplaceJob API
 func (suite *statelessHandlerTestSuite) TestGetReplaceJobDiffSuccess() {
@@ -2055,6 +2236,48 @@ func (suite *statelessHandlerTestSuite) TestCreateJobFailNonLeafResourcePool() {
 	suite.Nil(response)
 }
 
+// TestCreateJobFailResourcePoolNotPermitted tests the failure case of creating
+// job in a resource pool which is not owned by the user
+func (suite *statelessHandlerTestSuite) TestCreateJobFailResourcePoolNotPermitted() {
+	procedure := "peloton.api.v1alpha.job.stateless.svc.JobService::CreateJob"
+	user := authmocks.NewMockUser(suite.ctrl)
+	ctx := auth.WithUser(context.Background(), user, procedure)
+
+	gomock.InOrder(
+		suite.candidate.EXPECT().IsLeader().Return(true),
+
+		suite.respoolClient.EXPECT().
+			GetResourcePool(
+				gomock.Any(),
+				&respool.GetRequest{
+					Id: &peloton.ResourcePoolID{Value: testRespoolID.GetValue()},
+				},
+			).Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Id: &peloton.ResourcePoolID{Value: testRespoolID.GetValue()},
+				Config: &respool.ResourcePoolConfig{
+					OwningTeam: "team1",
+				},
+			},
+		}, nil),
+
+		user.EXPECT().
+			IsPermittedOnEntity(procedure, &auth.Entity{OwningTeam: "team1"}).
+			Return(false),
+	)
+
+	jobSpec := &stateless.JobSpec{
+		RespoolId: testRespoolID,
+	}
+	request := &statelesssvc.CreateJobRequest{
+		Spec: jobSpec,
+	}
+
+	response, err := suite.handler.CreateJob(ctx, request)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+	suite.Nil(response)
+}
+
 // TestCreateJobFailJobSpecToJobConfigConversionFailure tests the failure case of creating job
 // due to error while converting job spec to job config
 func (suite *statelessHandlerTestSuite) TestCreateJobFailJobSpecToJobConfigConversionFailure() {
@@ -3587,6 +3810,42 @@ func (suite *statelessHandlerTestSuite) TestDeleteJobFailNonLeader() {
 	suite.Nil(resp)
 }
 
+// TestDeleteJobNotPermitted tests the failure case of deleting
+// a job which is not owned by the user
+func (suite *statelessHandlerTestSuite) TestDeleteJobNotPermitted() {
+	procedure := "peloton.api.v1alpha.job.stateless.svc.JobService::DeleteJob"
+	user := authmocks.NewMockUser(suite.ctrl)
+	ctx := auth.WithUser(context.Background(), user, procedure)
+
+	gomock.InOrder(
+		suite.candidate.EXPECT().IsLeader().Return(true),
+
+		suite.jobStore.EXPECT().
+			GetJobConfig(gomock.Any(), testJobID).
+			Return(&pbjob.JobConfig{
+				OwningTeam: "team1",
+				LdapGroups: []string{"group1"},
+			}, nil, nil),
+
+		user.EXPECT().
+			IsPermittedOnEntity(procedure, &auth.Entity{
+				OwningTeam: "team1",
+				LdapGroups: []string{"group1"},
+			}).
+			Return(false),
+	)
+
+	resp, err := suite.handler.DeleteJob(
+		ctx,
+		&statelesssvc.DeleteJobRequest{
+			JobId:   &v1alphapeloton.JobID{Value: testJobID},
+			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
+		},
+	)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+	suite.Nil(resp)
+}
+
 // TestDeleteJobGetRuntimeFailure tests the failure case of
 // deleting a job due to error while getting job runtime
 func (suite *statelessHandlerTestSuite) TestDeleteJobGetRuntimeFailure() {
@@ -3917,6 +4176,54 @@ func (suite *statelessHandlerTestSuite) TestQueryPodsSuccess() {
 	suite.Equal(pagination, response.GetPagination())
 }
 
+// TestQueryPodsPendingReason tests that the pending reason of the pending
+// pods is filled in from the resource manager
+func (suite *statelessHandlerTestSuite) TestQueryPodsPendingReason() {
+	pelotonJobID := &peloton.JobID{Value: testJobID}
+	taskInfos := []*pbtask.TaskInfo{
+		{
+			InstanceId: 1,
+			JobId:      pelotonJobID,
+			Runtime: &pbtask.RuntimeInfo{
+				State:     pbtask.TaskState_PENDING,
+				GoalState: pbtask.TaskState_RUNNING,
+			},
+		},
+	}
+
+	request := &statelesssvc.QueryPodsRequest{
+		JobId: &v1alphapeloton.JobID{Value: testJobID},
+	}
+
+	gomock.InOrder(
+		suite.jobStore.EXPECT().
+			GetJobConfig(gomock.Any(), testJobID).
+			Return(&pbjob.JobConfig{}, nil, nil),
+
+		suite.taskStore.EXPECT().
+			QueryTasks(
+				gomock.Any(),
+				pelotonJobID,
+				handlerutil.ConvertPodQuerySpecToTaskQuerySpec(request.GetSpec()),
+			).Return(taskInfos, uint32(len(taskInfos)), nil),
+
+		suite.activeRMTasks.EXPECT().
+			GetTask(util.CreatePelotonTaskID(testJobID, 1)).
+			Return(&resmgrsvc.GetActiveTasksResponse_TaskEntry{
+				Reason:        "test reason",
+				PendingReason: "entitlement exhausted in resource pool /respool",
+			}),
+	)
+
+	response, err := suite.handler.QueryPods(context.Background(), request)
+	suite.NoError(err)
+	suite.Len(response.GetPods(), 1)
+	suite.Equal("test reason", response.GetPods()[0].GetStatus().GetReason())
+	suite.Equal(
+		"entitlement exhausted in resource pool /respool",
+		response.GetPods()[0].GetStatus().GetPendingReason())
+}
+
 // TestQueryPodsFailureJobRuntimeError tests failure case of
 // querying pods of a job due to error while getting job runtime
 func (suite *statelessHandlerTestSuite) TestQueryPodsFailureJobRuntimeError() {
@@ -0,0 +1,375 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"sort"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	pbworkflow "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow/svc"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/leader"
+	jobconfig "github.com/uber/peloton/pkg/jobmgr/job/config"
+	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+	jobmgrworkflow "github.com/uber/peloton/pkg/jobmgr/workflow"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type serviceHandler struct {
+	workflowOps        ormobjects.JobWorkflowOps
+	workflowController jobmgrworkflow.Controller
+	respoolClient      respool.ResourceManagerYARPCClient
+	candidate          leader.Candidate
+	jobSvcCfg          jobsvc.Config
+}
+
+// InitV1AlphaWorkflowServiceHandler initializes the Workflow Service Handler
+func InitV1AlphaWorkflowServiceHandler(
+	d *yarpc.Dispatcher,
+	ormStore *ormobjects.Store,
+	workflowController jobmgrworkflow.Controller,
+	candidate leader.Candidate,
+	jobSvcCfg jobsvc.Config,
+) {
+	handler := &serviceHandler{
+		workflowOps:        ormobjects.NewJobWorkflowOps(ormStore),
+		workflowController: workflowController,
+		respoolClient: respool.NewResourceManagerYARPCClient(
+			d.ClientConfig(common.PelotonResourceManager)),
+		candidate: candidate,
+		jobSvcCfg: jobSvcCfg,
+	}
+	d.Register(svc.BuildWorkflowServiceYARPCProcedures(handler))
+}
+
+func (h *serviceHandler) CreateWorkflow(
+	ctx context.Context,
+	req *svc.CreateWorkflowRequest,
+) (resp *svc.CreateWorkflowResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithField("name", req.GetSpec().GetName()).
+				WithError(err).
+				Warn("WorkflowSVC.CreateWorkflow failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("name", req.GetSpec().GetName()).
+			WithField("workflow_id", resp.GetWorkflowId().GetValue()).
+			Info("WorkflowSVC.CreateWorkflow succeeded")
+	}()
+
+	if !h.candidate.IsLeader() {
+		return nil,
+			yarpcerrors.UnavailableErrorf("WorkflowSVC.CreateWorkflow is not supported on non-leader")
+	}
+
+	if err := h.validateWorkflowSpec(req.GetSpec()); err != nil {
+		return nil, err
+	}
+
+	if err := h.checkResourcePoolPermissions(ctx, req.GetSpec()); err != nil {
+		return nil, err
+	}
+
+	id, err := h.workflowController.Create(ctx, req.GetSpec())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create workflow")
+	}
+	return &svc.CreateWorkflowResponse{WorkflowId: id}, nil
+}
+
+func (h *serviceHandler) GetWorkflow(
+	ctx context.Context,
+	req *svc.GetWorkflowRequest,
+) (resp *svc.GetWorkflowResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithField("workflow_id", req.GetWorkflowId().GetValue()).
+				WithError(err).
+				Warn("WorkflowSVC.GetWorkflow failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("workflow_id", req.GetWorkflowId().GetValue()).
+			Debug("WorkflowSVC.GetWorkflow succeeded")
+	}()
+
+	workflow, err := h.getWorkflow(ctx, req.GetWorkflowId())
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := workflow.ToProto()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal workflow")
+	}
+	return &svc.GetWorkflowResponse{Workflow: info}, nil
+}
+
+func (h *serviceHandler) ListWorkflows(
+	ctx context.Context,
+	req *svc.ListWorkflowsRequest,
+) (resp *svc.ListWorkflowsResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithError(err).
+				Warn("WorkflowSVC.ListWorkflows failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("count", len(resp.GetWorkflows())).
+			Debug("WorkflowSVC.ListWorkflows succeeded")
+	}()
+
+	workflows, err := h.workflowOps.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get workflows from db")
+	}
+	sort.SliceStable(workflows, func(i, j int) bool {
+		return workflows[i].CreationTime.After(workflows[j].CreationTime)
+	})
+
+	states := make(map[pbworkflow.WorkflowState]bool)
+	for _, state := range req.GetStates() {
+		states[state] = true
+	}
+
+	var infos []*pbworkflow.WorkflowInfo
+	for _, workflow := range workflows {
+		info, err := workflow.ToProto()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal workflow")
+		}
+		if len(states) != 0 && !states[info.GetStatus().GetState()] {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return &svc.ListWorkflowsResponse{Workflows: infos}, nil
+}
+
+func (h *serviceHandler) CancelWorkflow(
+	ctx context.Context,
+	req *svc.CancelWorkflowRequest,
+) (resp *svc.CancelWorkflowResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithField("workflow_id", req.GetWorkflowId().GetValue()).
+				WithError(err).
+				Warn("WorkflowSVC.CancelWorkflow failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("workflow_id", req.GetWorkflowId().GetValue()).
+			Info("WorkflowSVC.CancelWorkflow succeeded")
+	}()
+
+	if !h.candidate.IsLeader() {
+		return nil,
+			yarpcerrors.UnavailableErrorf("WorkflowSVC.CancelWorkflow is not supported on non-leader")
+	}
+
+	workflow, err := h.getWorkflow(ctx, req.GetWorkflowId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkWorkflowPermission(ctx, workflow); err != nil {
+		return nil, err
+	}
+
+	if err := h.workflowController.Cancel(ctx, req.GetWorkflowId()); err != nil {
+		return nil, errors.Wrap(err, "failed to cancel workflow")
+	}
+	return &svc.CancelWorkflowResponse{}, nil
+}
+
+func (h *serviceHandler) RetryWorkflow(
+	ctx context.Context,
+	req *svc.RetryWorkflowRequest,
+) (resp *svc.RetryWorkflowResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithField("workflow_id", req.GetWorkflowId().GetValue()).
+				WithError(err).
+				Warn("WorkflowSVC.RetryWorkflow failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("workflow_id", req.GetWorkflowId().GetValue()).
+			Info("WorkflowSVC.RetryWorkflow succeeded")
+	}()
+
+	if !h.candidate.IsLeader() {
+		return nil,
+			yarpcerrors.UnavailableErrorf("WorkflowSVC.RetryWorkflow is not supported on non-leader")
+	}
+
+	workflow, err := h.getWorkflow(ctx, req.GetWorkflowId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkWorkflowPermission(ctx, workflow); err != nil {
+		return nil, err
+	}
+
+	if err := h.workflowController.Retry(ctx, req.GetWorkflowId()); err != nil {
+		return nil, errors.Wrap(err, "failed to retry workflow")
+	}
+	return &svc.RetryWorkflowResponse{}, nil
+}
+
+// getWorkflow returns the workflow with the given ID, and a not found
+// error if it does not exist
+func (h *serviceHandler) getWorkflow(
+	ctx context.Context,
+	id *pbworkflow.WorkflowID,
+) (*ormobjects.JobWorkflowObject, error) {
+	if len(id.GetValue()) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf("workflow ID is empty")
+	}
+
+	workflow, err := h.workflowOps.Get(ctx, id)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			return nil, yarpcerrors.NotFoundErrorf(
+				"workflow:%s not found", id.GetValue())
+		}
+		return nil, errors.Wrap(err, "failed to get workflow from db")
+	}
+	return workflow, nil
+}
+
+// validateWorkflowSpec validates the dependencies between the nodes
+// of a workflow, and the job spec of each node
+func (h *serviceHandler) validateWorkflowSpec(spec *pbworkflow.WorkflowSpec) error {
+	if err := jobmgrworkflow.ValidateSpec(spec); err != nil {
+		return err
+	}
+
+	for _, node := range spec.GetNodes() {
+		if node.GetJobSpec().GetRespoolId() == nil {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"resource pool ID of node %s is null", node.GetName())
+		}
+
+		jobConfig, err := handlerutil.ConvertJobSpecToJobConfig(node.GetJobSpec())
+		if err != nil {
+			return errors.Wrapf(err,
+				"failed to convert job spec of node %s", node.GetName())
+		}
+
+		if err := jobconfig.ValidateConfig(
+			jobConfig,
+			h.jobSvcCfg.MaxTasksPerJob,
+		); err != nil {
+			return errors.Wrapf(err,
+				"invalid job spec of node %s", node.GetName())
+		}
+	}
+	return nil
+}
+
+// checkResourcePoolPermissions returns a permission denied error if the
+// user creating the workflow is not permitted on the resource pool of
+// one of its nodes. The resource pools are read only for the
+// authenticated calls.
+func (h *serviceHandler) checkResourcePoolPermissions(
+	ctx context.Context,
+	spec *pbworkflow.WorkflowSpec,
+) error {
+	if !auth.HasUser(ctx) {
+		return nil
+	}
+
+	checked := make(map[string]bool)
+	for _, node := range spec.GetNodes() {
+		respoolID := node.GetJobSpec().GetRespoolId().GetValue()
+		if checked[respoolID] {
+			continue
+		}
+		checked[respoolID] = true
+
+		resp, err := h.respoolClient.GetResourcePool(
+			ctx,
+			&respool.GetRequest{Id: &peloton.ResourcePoolID{Value: respoolID}})
+		if err != nil {
+			return errors.Wrap(err, "failed to get resource pool")
+		}
+		if resp.GetError() != nil || resp.GetPoolinfo() == nil {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"resource pool %s of node %s not found",
+				respoolID, node.GetName())
+		}
+
+		if err := auth.CheckEntityPermission(
+			ctx,
+			handlerutil.ResPoolEntity(resp.GetPoolinfo().GetConfig()),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWorkflowPermission returns a permission denied error if the
+// user is not permitted on the batch job of one of the nodes
+func checkWorkflowPermission(
+	ctx context.Context,
+	workflow *ormobjects.JobWorkflowObject,
+) error {
+	if !auth.HasUser(ctx) {
+		return nil
+	}
+
+	spec, err := workflow.GetSpec()
+	if err != nil {
+		return errors.Wrap(err, "failed to unmarshal workflow")
+	}
+
+	for _, node := range spec.GetNodes() {
+		jobConfig, err := handlerutil.ConvertJobSpecToJobConfig(node.GetJobSpec())
+		if err != nil {
+			return errors.Wrapf(err,
+				"failed to convert job spec of node %s", node.GetName())
+		}
+		if err := auth.CheckEntityPermission(
+			ctx,
+			handlerutil.JobEntity(jobConfig),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,503 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	pbworkflow "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
+	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
+	workflowmocks "github.com/uber/peloton/pkg/jobmgr/workflow/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	testWorkflowName = "test-workflow"
+	testWorkflowID   = "0cd6e2c5-9de1-4fb1-9b9f-5ef1a8e2f3a4"
+)
+
+var testCmd = "echo test"
+
+type workflowHandlerTestSuite struct {
+	suite.Suite
+
+	handler *serviceHandler
+
+	ctrl               *gomock.Controller
+	workflowOps        *objectmocks.MockJobWorkflowOps
+	workflowController *workflowmocks.MockController
+	respoolClient      *respoolmocks.MockResourceManagerYARPCClient
+	candidate          *leadermocks.MockCandidate
+}
+
+func (suite *workflowHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.workflowOps = objectmocks.NewMockJobWorkflowOps(suite.ctrl)
+	suite.workflowController = workflowmocks.NewMockController(suite.ctrl)
+	suite.respoolClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.candidate = leadermocks.NewMockCandidate(suite.ctrl)
+	suite.handler = &serviceHandler{
+		workflowOps:        suite.workflowOps,
+		workflowController: suite.workflowController,
+		respoolClient:      suite.respoolClient,
+		candidate:          suite.candidate,
+		jobSvcCfg: jobsvc.Config{
+			MaxTasksPerJob: 100000,
+		},
+	}
+}
+
+func (suite *workflowHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestWorkflowHandler(t *testing.T) {
+	suite.Run(t, new(workflowHandlerTestSuite))
+}
+
+// createWorkflowSpec returns a valid workflow spec
+// where node b runs on the success of node a
+func (suite *workflowHandlerTestSuite) createWorkflowSpec() *pbworkflow.WorkflowSpec {
+	jobSpec := &stateless.JobSpec{
+		InstanceCount: 1,
+		RespoolId:     &v1alphapeloton.ResourcePoolID{Value: "respool"},
+		DefaultSpec: &pod.PodSpec{
+			Containers: []*pod.ContainerSpec{
+				{
+					Command: &mesos.CommandInfo{Value: &testCmd},
+				},
+			},
+		},
+	}
+	return &pbworkflow.WorkflowSpec{
+		Name: testWorkflowName,
+		Nodes: []*pbworkflow.NodeSpec{
+			{Name: "a", JobSpec: jobSpec},
+			{
+				Name:    "b",
+				JobSpec: jobSpec,
+				Dependencies: []*pbworkflow.Dependency{
+					{
+						Node:      "a",
+						Condition: pbworkflow.DependencyCondition_DEPENDENCY_CONDITION_ON_SUCCESS,
+					},
+				},
+			},
+		},
+	}
+}
+
+// createWorkflowObject returns a workflow object in the given state
+func (suite *workflowHandlerTestSuite) createWorkflowObject(
+	id string,
+	state pbworkflow.WorkflowState,
+	creationTime time.Time,
+) *ormobjects.JobWorkflowObject {
+	specBuffer, err := proto.Marshal(suite.createWorkflowSpec())
+	suite.NoError(err)
+	statusBuffer, err := proto.Marshal(&pbworkflow.WorkflowStatus{State: state})
+	suite.NoError(err)
+	return &ormobjects.JobWorkflowObject{
+		WorkflowID:   id,
+		Name:         testWorkflowName,
+		Spec:         specBuffer,
+		Status:       statusBuffer,
+		CreationTime: creationTime,
+		UpdateTime:   creationTime,
+	}
+}
+
+// TestCreateWorkflow tests creating a workflow
+func (suite *workflowHandlerTestSuite) TestCreateWorkflow() {
+	spec := suite.createWorkflowSpec()
+	id := &pbworkflow.WorkflowID{Value: testWorkflowID}
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.workflowController.EXPECT().
+		Create(gomock.Any(), spec).
+		Return(id, nil)
+
+	resp, err := suite.handler.CreateWorkflow(
+		context.Background(),
+		&svc.CreateWorkflowRequest{Spec: spec})
+	suite.NoError(err)
+	suite.Equal(id, resp.GetWorkflowId())
+}
+
+// TestCreateWorkflowResourcePoolPermission tests that the user creating
+// a workflow must be permitted on the resource pools of its nodes
+func (suite *workflowHandlerTestSuite) TestCreateWorkflowResourcePoolPermission() {
+	spec := suite.createWorkflowSpec()
+	id := &pbworkflow.WorkflowID{Value: testWorkflowID}
+	entity := &auth.Entity{OwningTeam: "team1"}
+
+	user := authmocks.NewMockUser(suite.ctrl)
+	ctx := auth.WithUser(context.Background(), user, "CreateWorkflow")
+
+	// the resource pool shared by the nodes is checked once
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{
+			Id: &peloton.ResourcePoolID{Value: "respool"},
+		}).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Id:     &peloton.ResourcePoolID{Value: "respool"},
+				Config: &respool.ResourcePoolConfig{OwningTeam: "team1"},
+			},
+		}, nil).
+		Times(2)
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	user.EXPECT().IsPermittedOnEntity("CreateWorkflow", entity).Return(false)
+	_, err := suite.handler.CreateWorkflow(
+		ctx,
+		&svc.CreateWorkflowRequest{Spec: spec})
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	user.EXPECT().IsPermittedOnEntity("CreateWorkflow", entity).Return(true)
+	suite.workflowController.EXPECT().
+		Create(gomock.Any(), spec).
+		Return(id, nil)
+	resp, err := suite.handler.CreateWorkflow(
+		ctx,
+		&svc.CreateWorkflowRequest{Spec: spec})
+	suite.NoError(err)
+	suite.Equal(id, resp.GetWorkflowId())
+}
+
+// TestCreateWorkflowResourcePoolNotFound tests creating a workflow
+// whose resource pool does not exist
+func (suite *workflowHandlerTestSuite) TestCreateWorkflowResourcePoolNotFound() {
+	user := authmocks.NewMockUser(suite.ctrl)
+	ctx := auth.WithUser(context.Background(), user, "CreateWorkflow")
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), gomock.Any()).
+		Return(&respool.GetResponse{
+			Error: &respool.GetResponse_Error{
+				NotFound: &respool.ResourcePoolNotFound{},
+			},
+		}, nil)
+
+	_, err := suite.handler.CreateWorkflow(
+		ctx,
+		&svc.CreateWorkflowRequest{Spec: suite.createWorkflowSpec()})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestCreateWorkflowNonLeader tests creating a workflow on a non-leader
+func (suite *workflowHandlerTestSuite) TestCreateWorkflowNonLeader() {
+	suite.candidate.EXPECT().IsLeader().Return(false)
+
+	_, err := suite.handler.CreateWorkflow(
+		context.Background(),
+		&svc.CreateWorkflowRequest{Spec: suite.createWorkflowSpec()})
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestCreateWorkflowInvalidSpec tests creating workflows
+// with an invalid graph or job spec
+func (suite *workflowHandlerTestSuite) TestCreateWorkflowInvalidSpec() {
+	cycle := suite.createWorkflowSpec()
+	cycle.Nodes[0].Dependencies = []*pbworkflow.Dependency{
+		{
+			Node:      "b",
+			Condition: pbworkflow.DependencyCondition_DEPENDENCY_CONDITION_ALWAYS,
+		},
+	}
+
+	noRespool := suite.createWorkflowSpec()
+	noRespool.Nodes[1].JobSpec = proto.Clone(
+		noRespool.Nodes[1].GetJobSpec()).(*stateless.JobSpec)
+	noRespool.Nodes[1].JobSpec.RespoolId = nil
+
+	tooManyInstances := suite.createWorkflowSpec()
+	tooManyInstances.Nodes[0].JobSpec = proto.Clone(
+		tooManyInstances.Nodes[0].GetJobSpec()).(*stateless.JobSpec)
+	tooManyInstances.Nodes[0].JobSpec.InstanceCount = 200000
+
+	for _, spec := range []*pbworkflow.WorkflowSpec{
+		cycle,
+		noRespool,
+		tooManyInstances,
+	} {
+		suite.candidate.EXPECT().IsLeader().Return(true)
+		_, err := suite.handler.CreateWorkflow(
+			context.Background(),
+			&svc.CreateWorkflowRequest{Spec: spec})
+		suite.True(yarpcerrors.IsInvalidArgument(err))
+	}
+}
+
+// TestCreateWorkflowFail tests the failure to create a workflow
+func (suite *workflowHandlerTestSuite) TestCreateWorkflowFail() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.workflowController.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	_, err := suite.handler.CreateWorkflow(
+		context.Background(),
+		&svc.CreateWorkflowRequest{Spec: suite.createWorkflowSpec()})
+	suite.Error(err)
+}
+
+// TestGetWorkflow tests getting a workflow
+func (suite *workflowHandlerTestSuite) TestGetWorkflow() {
+	id := &pbworkflow.WorkflowID{Value: testWorkflowID}
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), id).
+		Return(suite.createWorkflowObject(
+			testWorkflowID,
+			pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+			time.Date(2019, 3, 4, 10, 0, 0, 0, time.UTC)), nil)
+
+	resp, err := suite.handler.GetWorkflow(
+		context.Background(),
+		&svc.GetWorkflowRequest{WorkflowId: id})
+	suite.NoError(err)
+	suite.Equal(id, resp.GetWorkflow().GetWorkflowId())
+	suite.Equal(testWorkflowName, resp.GetWorkflow().GetSpec().GetName())
+	suite.Equal(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		resp.GetWorkflow().GetStatus().GetState())
+	suite.Equal("2019-03-04T10:00:00Z",
+		resp.GetWorkflow().GetStatus().GetCreationTime())
+}
+
+// TestGetWorkflowNotFound tests getting a workflow which does not exist
+func (suite *workflowHandlerTestSuite) TestGetWorkflowNotFound() {
+	id := &pbworkflow.WorkflowID{Value: testWorkflowID}
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), id).
+		Return(nil, gocql.ErrNotFound)
+
+	_, err := suite.handler.GetWorkflow(
+		context.Background(),
+		&svc.GetWorkflowRequest{WorkflowId: id})
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestGetWorkflowEmptyID tests getting a workflow without ID
+func (suite *workflowHandlerTestSuite) TestGetWorkflowEmptyID() {
+	_, err := suite.handler.GetWorkflow(
+		context.Background(),
+		&svc.GetWorkflowRequest{})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestListWorkflows tests listing the workflows by
+// descending creation time, filtered by state
+func (suite *workflowHandlerTestSuite) TestListWorkflows() {
+	now := time.Now()
+	objs := []*ormobjects.JobWorkflowObject{
+		suite.createWorkflowObject("wf-1",
+			pbworkflow.WorkflowState_WORKFLOW_STATE_SUCCEEDED,
+			now.Add(-2*time.Hour)),
+		suite.createWorkflowObject("wf-2",
+			pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+			now),
+		suite.createWorkflowObject("wf-3",
+			pbworkflow.WorkflowState_WORKFLOW_STATE_FAILED,
+			now.Add(-time.Hour)),
+	}
+	suite.workflowOps.EXPECT().GetAll(gomock.Any()).Return(objs, nil).Times(2)
+
+	resp, err := suite.handler.ListWorkflows(
+		context.Background(),
+		&svc.ListWorkflowsRequest{})
+	suite.NoError(err)
+	var ids []string
+	for _, workflow := range resp.GetWorkflows() {
+		ids = append(ids, workflow.GetWorkflowId().GetValue())
+	}
+	suite.Equal([]string{"wf-2", "wf-3", "wf-1"}, ids)
+
+	resp, err = suite.handler.ListWorkflows(
+		context.Background(),
+		&svc.ListWorkflowsRequest{
+			States: []pbworkflow.WorkflowState{
+				pbworkflow.WorkflowState_WORKFLOW_STATE_SUCCEEDED,
+				pbworkflow.WorkflowState_WORKFLOW_STATE_FAILED,
+			},
+		})
+	suite.NoError(err)
+	ids = nil
+	for _, workflow := range resp.GetWorkflows() {
+		ids = append(ids, workflow.GetWorkflowId().GetValue())
+	}
+	suite.Equal([]string{"wf-3", "wf-1"}, ids)
+}
+
+// TestListWorkflowsFail tests the failure to read the workflows
+func (suite *workflowHandlerTestSuite) TestListWorkflowsFail() {
+	suite.workflowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	_, err := suite.handler.ListWorkflows(
+		context.Background(),
+		&svc.ListWorkflowsRequest{})
+	suite.Error(err)
+}
+
+// TestCancelWorkflow tests cancelling a workflow
+func (suite *workflowHandlerTestSuite) TestCancelWorkflow() {
+	id := &pbworkflow.WorkflowID{Value: testWorkflowID}
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), id).
+		Return(suite.createWorkflowObject(
+			testWorkflowID,
+			pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+			time.Now()), nil)
+	suite.workflowController.EXPECT().Cancel(gomock.Any(), id).Return(nil)
+
+	_, err := suite.handler.CancelWorkflow(
+		context.Background(),
+		&svc.CancelWorkflowRequest{WorkflowId: id})
+	suite.NoError(err)
+}
+
+// TestCancelWorkflowNotPermitted tests that the user cancelling a
+// workflow must be permitted on the batch jobs of its nodes
+func (suite *workflowHandlerTestSuite) TestCancelWorkflowNotPermitted() {
+	id := &pbworkflow.WorkflowID{Value: testWorkflowID}
+	user := authmocks.NewMockUser(suite.ctrl)
+	ctx := auth.WithUser(context.Background(), user, "CancelWorkflow")
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), id).
+		Return(suite.createWorkflowObject(
+			testWorkflowID,
+			pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+			time.Now()), nil)
+	user.EXPECT().
+		IsPermittedOnEntity("CancelWorkflow", gomock.Any()).
+		Return(false)
+
+	_, err := suite.handler.CancelWorkflow(
+		ctx,
+		&svc.CancelWorkflowRequest{WorkflowId: id})
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+}
+
+// TestCancelWorkflowNotRunning tests that the failed precondition
+// of a workflow which is not running is returned
+func (suite *workflowHandlerTestSuite) TestCancelWorkflowNotRunning() {
+	id := &pbworkflow.WorkflowID{Value: testWorkflowID}
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), id).
+		Return(suite.createWorkflowObject(
+			testWorkflowID,
+			pbworkflow.WorkflowState_WORKFLOW_STATE_SUCCEEDED,
+			time.Now()), nil)
+	suite.workflowController.EXPECT().
+		Cancel(gomock.Any(), id).
+		Return(yarpcerrors.FailedPreconditionErrorf("workflow is not running"))
+
+	_, err := suite.handler.CancelWorkflow(
+		context.Background(),
+		&svc.CancelWorkflowRequest{WorkflowId: id})
+	suite.True(yarpcerrors.IsFailedPrecondition(err))
+}
+
+// TestCancelWorkflowNotFound tests cancelling
+// a workflow which does not exist
+func (suite *workflowHandlerTestSuite) TestCancelWorkflowNotFound() {
+	id := &pbworkflow.WorkflowID{Value: testWorkflowID}
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), id).
+		Return(nil, gocql.ErrNotFound)
+
+	_, err := suite.handler.CancelWorkflow(
+		context.Background(),
+		&svc.CancelWorkflowRequest{WorkflowId: id})
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestCancelWorkflowNonLeader tests cancelling a workflow on a non-leader
+func (suite *workflowHandlerTestSuite) TestCancelWorkflowNonLeader() {
+	suite.candidate.EXPECT().IsLeader().Return(false)
+
+	_, err := suite.handler.CancelWorkflow(
+		context.Background(),
+		&svc.CancelWorkflowRequest{
+			WorkflowId: &pbworkflow.WorkflowID{Value: testWorkflowID},
+		})
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestRetryWorkflow tests retrying a workflow
+func (suite *workflowHandlerTestSuite) TestRetryWorkflow() {
+	id := &pbworkflow.WorkflowID{Value: testWorkflowID}
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), id).
+		Return(suite.createWorkflowObject(
+			testWorkflowID,
+			pbworkflow.WorkflowState_WORKFLOW_STATE_FAILED,
+			time.Now()), nil)
+	suite.workflowController.EXPECT().Retry(gomock.Any(), id).Return(nil)
+
+	_, err := suite.handler.RetryWorkflow(
+		context.Background(),
+		&svc.RetryWorkflowRequest{WorkflowId: id})
+	suite.NoError(err)
+}
+
+// TestRetryWorkflowNonLeader tests retrying a workflow on a non-leader
+func (suite *workflowHandlerTestSuite) TestRetryWorkflowNonLeader() {
+	suite.candidate.EXPECT().IsLeader().Return(false)
+
+	_, err := suite.handler.RetryWorkflow(
+		context.Background(),
+		&svc.RetryWorkflowRequest{
+			WorkflowId: &pbworkflow.WorkflowID{Value: testWorkflowID},
+		})
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
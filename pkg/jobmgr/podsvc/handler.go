@@ -118,6 +118,13 @@ func (h *serviceHandler) StartPod(
 		return nil, err
 	}
 
+	if err := handlerutil.CheckJobPermission(
+		ctx,
+		&v0peloton.JobID{Value: jobID},
+		h.jobStore); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&v0peloton.JobID{Value: jobID})
 	cachedConfig, err := cachedJob.GetConfig(ctx)
 	if err != nil {
@@ -283,6 +290,13 @@ func (h *serviceHandler) StopPod(
 		return nil, err
 	}
 
+	if err := handlerutil.CheckJobPermission(
+		ctx,
+		&v0peloton.JobID{Value: jobID},
+		h.jobStore); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&v0peloton.JobID{Value: jobID})
 
 	runtimeInfo, err := h.podStore.GetTaskRuntime(
@@ -348,6 +362,13 @@ func (h *serviceHandler) RestartPod(
 		return nil, yarpcerrors.InvalidArgumentErrorf("invalid pod name")
 	}
 
+	if err := handlerutil.CheckJobPermission(
+		ctx,
+		&v0peloton.JobID{Value: jobID},
+		h.jobStore); err != nil {
+		return nil, err
+	}
+
 	cachedJob := h.jobFactory.AddJob(&v0peloton.JobID{Value: jobID})
 
 	newPodID, err := h.getPodIDForRestart(ctx,
@@ -707,6 +728,13 @@ func (h *serviceHandler) DeletePodEvents(
 		return nil, err
 	}
 
+	if err := handlerutil.CheckJobPermission(
+		ctx,
+		&v0peloton.JobID{Value: jobID},
+		h.jobStore); err != nil {
+		return nil, err
+	}
+
 	if err = h.podStore.DeletePodEvents(
 		ctx,
 		jobID,
@@ -32,6 +32,8 @@ import (
 	hostmocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
 	"github.com/uber/peloton/.gen/peloton/private/models"
 
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
 	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
 	"github.com/uber/peloton/pkg/common/util"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
@@ -66,6 +68,7 @@ type podHandlerTestSuite struct {
 	jobFactory         *cachedmocks.MockJobFactory
 	candidate          *leadermocks.MockCandidate
 	podStore           *storemocks.MockTaskStore
+	jobStore           *storemocks.MockJobStore
 	goalStateDriver    *goalstatemocks.MockDriver
 	frameworkInfoStore *storemocks.MockFrameworkInfoStore
 	hostmgrClient      *hostmocks.MockInternalHostServiceYARPCClient
@@ -79,6 +82,7 @@ func (suite *podHandlerTestSuite) SetupTest() {
 	suite.cachedTask = cachedmocks.NewMockTask(suite.ctrl)
 	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
 	suite.podStore = storemocks.NewMockTaskStore(suite.ctrl)
+	suite.jobStore = storemocks.NewMockJobStore(suite.ctrl)
 	suite.candidate = leadermocks.NewMockCandidate(suite.ctrl)
 	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.ctrl)
 	suite.frameworkInfoStore = storemocks.NewMockFrameworkInfoStore(suite.ctrl)
@@ -89,6 +93,7 @@ func (suite *podHandlerTestSuite) SetupTest() {
 		jobFactory:         suite.jobFactory,
 		candidate:          suite.candidate,
 		podStore:           suite.podStore,
+		jobStore:           suite.jobStore,
 		goalStateDriver:    suite.goalStateDriver,
 		frameworkInfoStore: suite.frameworkInfoStore,
 		hostMgrClient:      suite.hostmgrClient,
@@ -1895,6 +1900,28 @@ func (suite *podHandlerTestSuite) TestDeletePodEventsStoreError() {
 	suite.Error(err)
 }
 
+// TestDeletePodEventsNotPermitted tests DeletePodEvents failure
+// due to the user not being permitted on the job of the pod
+func (suite *podHandlerTestSuite) TestDeletePodEventsNotPermitted() {
+	procedure := "peloton.api.v1alpha.pod.svc.PodService::DeletePodEvents"
+	user := authmocks.NewMockUser(suite.ctrl)
+	ctx := auth.WithUser(context.Background(), user, procedure)
+	request := &svc.DeletePodEventsRequest{
+		PodName: &v1alphapeloton.PodName{Value: testPodName},
+		PodId:   &v1alphapeloton.PodID{Value: testPodID},
+	}
+
+	suite.jobStore.EXPECT().
+		GetJobConfig(gomock.Any(), testJobID).
+		Return(&pbjob.JobConfig{OwningTeam: "team1"}, nil, nil)
+	user.EXPECT().
+		IsPermittedOnEntity(procedure, &auth.Entity{OwningTeam: "team1"}).
+		Return(false)
+
+	_, err := suite.handler.DeletePodEvents(ctx, request)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+}
+
 func TestPodServiceHandler(t *testing.T) {
 	suite.Run(t, new(podHandlerTestSuite))
 }
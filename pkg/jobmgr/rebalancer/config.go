@@ -0,0 +1,46 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebalancer
+
+import (
+	"time"
+)
+
+// Config is the rebalancer specific config
+type Config struct {
+	// Period is the period to compute the relocation recommendations
+	// of the running stateless pods. The rebalancer is disabled if 0.
+	Period time.Duration `yaml:"period"`
+
+	// MinRank is the minimum number of hosts better than the current
+	// host of a pod to recommend relocating the pod
+	MinRank uint32 `yaml:"min_rank"`
+
+	// MaxRecommendations is the maximum number of recommendations
+	// kept, with the highest ranks. All are kept if 0.
+	MaxRecommendations int `yaml:"max_recommendations"`
+
+	// AutoMigrate migrates the pods of the recommendations every
+	// period, once the recommendations are computed
+	AutoMigrate bool `yaml:"auto_migrate"`
+
+	// MaxMigrations is the maximum number of pods migrated at once,
+	// either every period or by a call to the API
+	MaxMigrations uint32 `yaml:"max_migrations"`
+
+	// Concurrency is the number of goroutines ranking the hosts
+	// for each pod
+	Concurrency int `yaml:"concurrency"`
+}
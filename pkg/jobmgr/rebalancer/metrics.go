@@ -0,0 +1,52 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebalancer
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the struct containing all the counters that track internal state
+// of the rebalancer.
+type Metrics struct {
+	Rebalance     tally.Counter
+	RebalanceFail tally.Counter
+
+	// Recommendations is the number of recommendations
+	// computed by the last rebalance
+	Recommendations tally.Gauge
+
+	PodsMigrated         tally.Counter
+	PodsMigrateFail      tally.Counter
+	PodsHeldBySLA        tally.Counter
+	RecommendationsStale tally.Counter
+}
+
+// NewMetrics returns a new Metrics struct, with all metrics
+// initialized and rooted at the given tally.Scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	successScope := scope.Tagged(map[string]string{"result": "success"})
+	failScope := scope.Tagged(map[string]string{"result": "fail"})
+
+	return &Metrics{
+		Rebalance:            successScope.Counter("rebalance"),
+		RebalanceFail:        failScope.Counter("rebalance"),
+		Recommendations:      scope.Gauge("recommendations"),
+		PodsMigrated:         successScope.Counter("pods_migrated"),
+		PodsMigrateFail:      failScope.Counter("pods_migrated"),
+		PodsHeldBySLA:        scope.Counter("pods_held_by_sla"),
+		RecommendationsStale: scope.Counter("recommendations_stale"),
+	}
+}
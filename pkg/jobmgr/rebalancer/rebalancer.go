@@ -0,0 +1,526 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebalancer
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/rebalance"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/algorithms"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/placement"
+	"github.com/uber/peloton/pkg/storage"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+)
+
+const (
+	// timeout to compute the recommendations and migrate the pods
+	_defaultRebalanceTimeout = 30 * time.Second
+
+	// minimum number of hosts to rank the hosts of a pod concurrently
+	_relocatorMinimumSize = 100
+
+	_podMigratedMessage = "Pod migrated by the rebalancer"
+)
+
+var (
+	// the states of the hosts in maintenance, which
+	// are neither relocated from nor relocated to
+	_maintenanceHostStates = []hpb.HostState{
+		hpb.HostState_HOST_STATE_DRAINING,
+		hpb.HostState_HOST_STATE_DRAINED,
+		hpb.HostState_HOST_STATE_DOWN,
+	}
+)
+
+// Rebalancer ranks the placement of the running stateless pods against
+// the other hosts of the cluster with the mimir relocator, and recommends
+// relocating the pods which would be better placed on another host.
+// The pods of the recommendations are migrated by restarting them with
+// their target host as their desired host.
+type Rebalancer interface {
+	// Rebalance computes the recommendations, and migrates the pods
+	// of the recommendations if auto migration is enabled
+	Rebalance()
+
+	// GetRecommendations returns the current recommendations sorted
+	// by descending rank, and the time at which they were computed
+	GetRecommendations() ([]*rebalance.Recommendation, time.Time)
+
+	// Migrate migrates the pods of the recommendations with the highest
+	// rank, at most maxMigrations bounded by the config. The pods whose
+	// job would exceed the maximum unavailable instances of its SLA are
+	// skipped, as are the pods of the jobs the user carried by ctx is not
+	// permitted to restart. It returns the recommendations which were
+	// applied, and the ones which failed to be applied.
+	Migrate(
+		ctx context.Context,
+		maxMigrations uint32,
+	) ([]*rebalance.Recommendation, []*rebalance.MigrationFailure)
+}
+
+// rebalancer implements the Rebalancer interface
+type rebalancer struct {
+	// protects recommendations and generateTime
+	mu sync.RWMutex
+	// serializes the migrations
+	migrateMu sync.Mutex
+
+	config          *Config
+	jobFactory      cached.JobFactory
+	jobStore        storage.JobStore
+	goalStateDriver goalstate.Driver
+	hostMgrClient   hostsvc.InternalHostServiceYARPCClient
+	hostClient      host_svc.HostServiceYARPCClient
+	resMgrClient    resmgrsvc.ResourceManagerServiceYARPCClient
+	relocator       algorithms.Relocator
+	metrics         *Metrics
+
+	recommendations []*rebalance.Recommendation
+	generateTime    time.Time
+}
+
+// New creates a rebalancer
+func New(
+	d *yarpc.Dispatcher,
+	jobFactory cached.JobFactory,
+	jobStore storage.JobStore,
+	goalStateDriver goalstate.Driver,
+	config *Config,
+	parent tally.Scope,
+) Rebalancer {
+	return &rebalancer{
+		config:          config,
+		jobFactory:      jobFactory,
+		jobStore:        jobStore,
+		goalStateDriver: goalStateDriver,
+		hostMgrClient: hostsvc.NewInternalHostServiceYARPCClient(
+			d.ClientConfig(common.PelotonHostManager)),
+		hostClient: host_svc.NewHostServiceYARPCClient(
+			d.ClientConfig(common.PelotonHostManager)),
+		resMgrClient: resmgrsvc.NewResourceManagerServiceYARPCClient(
+			d.ClientConfig(common.PelotonResourceManager)),
+		relocator: algorithms.NewRelocator(
+			config.Concurrency,
+			_relocatorMinimumSize),
+		metrics: NewMetrics(parent.SubScope("jobmgr").SubScope("rebalancer")),
+	}
+}
+
+// Rebalance computes the recommendations, and migrates the pods
+// of the recommendations if auto migration is enabled
+func (r *rebalancer) Rebalance() {
+	ctx, cancelFunc := context.WithTimeout(
+		context.Background(),
+		_defaultRebalanceTimeout)
+	defer cancelFunc()
+
+	recommendations, err := r.computeRecommendations(ctx)
+	if err != nil {
+		log.WithError(err).Error("failed to compute rebalance recommendations")
+		r.metrics.RebalanceFail.Inc(1)
+		return
+	}
+
+	r.mu.Lock()
+	r.recommendations = recommendations
+	r.generateTime = time.Now()
+	r.mu.Unlock()
+
+	log.WithField("recommendations", len(recommendations)).
+		Info("rebalance recommendations computed")
+	r.metrics.Rebalance.Inc(1)
+	r.metrics.Recommendations.Update(float64(len(recommendations)))
+
+	if !r.config.AutoMigrate {
+		return
+	}
+	if _, failures := r.Migrate(ctx, r.config.MaxMigrations); len(failures) > 0 {
+		log.WithField("failures", failures).
+			Error("failed to migrate pods to rebalance")
+	}
+}
+
+// GetRecommendations returns the current recommendations sorted
+// by descending rank, and the time at which they were computed
+func (r *rebalancer) GetRecommendations() ([]*rebalance.Recommendation, time.Time) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	recommendations := make(
+		[]*rebalance.Recommendation,
+		len(r.recommendations))
+	copy(recommendations, r.recommendations)
+	return recommendations, r.generateTime
+}
+
+// Migrate migrates the pods of the recommendations with the highest
+// rank. The recommendations which are applied or stale are dropped,
+// and the ones which failed are kept for the next migration.
+func (r *rebalancer) Migrate(
+	ctx context.Context,
+	maxMigrations uint32,
+) ([]*rebalance.Recommendation, []*rebalance.MigrationFailure) {
+	if maxMigrations == 0 || maxMigrations > r.config.MaxMigrations {
+		maxMigrations = r.config.MaxMigrations
+	}
+
+	r.migrateMu.Lock()
+	defer r.migrateMu.Unlock()
+
+	recommendations, _ := r.GetRecommendations()
+	var migrated []*rebalance.Recommendation
+	var failures []*rebalance.MigrationFailure
+	dropped := make(map[string]struct{})
+	for _, recommendation := range recommendations {
+		if uint32(len(migrated)) >= maxMigrations {
+			break
+		}
+
+		result, err := r.migratePod(ctx, recommendation)
+		if err != nil {
+			failures = append(failures, &rebalance.MigrationFailure{
+				Recommendation: recommendation,
+				Message:        err.Error(),
+			})
+			r.metrics.PodsMigrateFail.Inc(1)
+			continue
+		}
+
+		switch result {
+		case _migrateResultMigrated:
+			migrated = append(migrated, recommendation)
+			r.metrics.PodsMigrated.Inc(1)
+		case _migrateResultStale:
+			r.metrics.RecommendationsStale.Inc(1)
+		case _migrateResultHeld:
+			r.metrics.PodsHeldBySLA.Inc(1)
+			continue
+		}
+		dropped[recommendation.GetPodName().GetValue()] = struct{}{}
+	}
+
+	r.dropRecommendations(dropped)
+	return migrated, failures
+}
+
+// migrateResult is the result of the migration of a pod
+type migrateResult int
+
+const (
+	// the pod is migrated
+	_migrateResultMigrated migrateResult = iota
+	// the pod is no longer running on the current host of
+	// the recommendation, or is already being restarted
+	_migrateResultStale
+	// the migration is held because of the SLA of the job
+	_migrateResultHeld
+)
+
+// migratePod restarts the pod of the recommendation with the target host
+// of the recommendation as its desired host
+func (r *rebalancer) migratePod(
+	ctx context.Context,
+	recommendation *rebalance.Recommendation,
+) (migrateResult, error) {
+	podName := recommendation.GetPodName().GetValue()
+	id, instanceID, err := util.ParseTaskID(podName)
+	if err != nil {
+		return _migrateResultStale, err
+	}
+	jobID := &peloton.JobID{Value: id}
+
+	// the pod is restarted on behalf of the caller, if any
+	if err := handlerutil.CheckJobPermission(ctx, jobID, r.jobStore); err != nil {
+		return _migrateResultStale, err
+	}
+
+	cachedJob := r.jobFactory.GetJob(jobID)
+	if cachedJob == nil {
+		return _migrateResultStale, nil
+	}
+	cachedTask := cachedJob.GetTask(instanceID)
+	if cachedTask == nil {
+		return _migrateResultStale, nil
+	}
+	runtime, err := cachedTask.GetRuntime(ctx)
+	if err != nil {
+		return _migrateResultStale, err
+	}
+	if runtime.GetHost() != recommendation.GetCurrentHost() ||
+		jobutil.IsTaskUnavailable(runtime) {
+		return _migrateResultStale, nil
+	}
+
+	withinSLA, err := jobutil.IsWithinUnavailableInstances(ctx, cachedJob)
+	if err != nil {
+		return _migrateResultStale, err
+	}
+	if !withinSLA {
+		log.WithField("pod_name", podName).
+			Info("holding migration of pod, " +
+				"job has reached its maximum unavailable instances")
+		return _migrateResultHeld, nil
+	}
+
+	runID, err := util.ParseRunID(runtime.GetMesosTaskId().GetValue())
+	if err != nil {
+		runID = 0
+	}
+	err = cachedJob.PatchTasks(ctx, map[uint32]jobmgrcommon.RuntimeDiff{
+		instanceID: {
+			jobmgrcommon.DesiredHostField: recommendation.GetTargetHost(),
+			jobmgrcommon.DesiredMesosTaskIDField: util.CreateMesosTaskID(
+				jobID,
+				instanceID,
+				runID+1),
+			jobmgrcommon.MessageField: _podMigratedMessage,
+		},
+	})
+
+	// enqueue the task even if PatchTasks fails, because the runtime
+	// may have been updated in db
+	r.goalStateDriver.EnqueueTask(jobID, instanceID, time.Now())
+	if err != nil {
+		return _migrateResultStale, err
+	}
+
+	log.WithFields(log.Fields{
+		"pod_name":     podName,
+		"current_host": recommendation.GetCurrentHost(),
+		"target_host":  recommendation.GetTargetHost(),
+		"rank":         recommendation.GetRank(),
+	}).Info("pod migrated to rebalance")
+	return _migrateResultMigrated, nil
+}
+
+// dropRecommendations removes the recommendations of the pods
+func (r *rebalancer) dropRecommendations(podNames map[string]struct{}) {
+	if len(podNames) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var recommendations []*rebalance.Recommendation
+	for _, recommendation := range r.recommendations {
+		if _, ok := podNames[recommendation.GetPodName().GetValue()]; !ok {
+			recommendations = append(recommendations, recommendation)
+		}
+	}
+	r.recommendations = recommendations
+	r.metrics.Recommendations.Update(float64(len(recommendations)))
+}
+
+// computeRecommendations ranks the current host of each running stateless
+// pod against the other hosts, and returns the recommendations of the pods
+// which have at least MinRank better hosts, sorted by descending rank
+func (r *rebalancer) computeRecommendations(
+	ctx context.Context,
+) ([]*rebalance.Recommendation, error) {
+	groups, err := r.getGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	hostnames := make([]string, 0, len(groups))
+	for hostname := range groups {
+		hostnames = append(hostnames, hostname)
+	}
+	sort.Strings(hostnames)
+
+	tasksResp, err := r.resMgrClient.GetTasksByHosts(
+		ctx,
+		&resmgrsvc.GetTasksByHostsRequest{
+			// all the task types, so that the free resources
+			// of the hosts account for all the tasks
+			Type:      resmgr.TaskType_UNKNOWN,
+			Hostnames: hostnames,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var relocationRanks []*placement.RelocationRank
+	groupList := make([]*placement.Group, 0, len(groups))
+	for _, hostname := range hostnames {
+		group := groups[hostname]
+		for _, task := range tasksResp.GetHostTasksMap()[hostname].GetTasks() {
+			entity := mimir.TaskToEntity(task, false)
+			group.Entities.Add(entity)
+			if isRelocatable(task) {
+				relocationRanks = append(
+					relocationRanks,
+					placement.NewRelocationRank(entity, group))
+			}
+		}
+		group.Update()
+		groupList = append(groupList, group)
+	}
+
+	scopeSet := placement.NewScopeSet(groupList)
+	r.relocator.Relocate(relocationRanks, groupList, scopeSet)
+
+	var recommendations []*rebalance.Recommendation
+	for _, rank := range relocationRanks {
+		if rank.Rank == 0 || uint32(rank.Rank) < r.config.MinRank {
+			continue
+		}
+		target := bestGroup(rank, groupList, scopeSet)
+		if target == nil {
+			continue
+		}
+		recommendations = append(recommendations, &rebalance.Recommendation{
+			PodName:     &v1alphapeloton.PodName{Value: rank.Entity.Name},
+			CurrentHost: rank.CurrentGroup.Name,
+			TargetHost:  target.Name,
+			Rank:        uint32(rank.Rank),
+		})
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].GetRank() != recommendations[j].GetRank() {
+			return recommendations[i].GetRank() > recommendations[j].GetRank()
+		}
+		return recommendations[i].GetPodName().GetValue() <
+			recommendations[j].GetPodName().GetValue()
+	})
+	if r.config.MaxRecommendations > 0 &&
+		len(recommendations) > r.config.MaxRecommendations {
+		recommendations = recommendations[:r.config.MaxRecommendations]
+	}
+	return recommendations, nil
+}
+
+// getGroups returns the mimir groups of the registered hosts which
+// are not in maintenance, with their total resources, keyed by hostname
+func (r *rebalancer) getGroups(
+	ctx context.Context,
+) (map[string]*placement.Group, error) {
+	agentResp, err := r.hostMgrClient.GetMesosAgentInfo(
+		ctx,
+		&hostsvc.GetMesosAgentInfoRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	maintenanceResp, err := r.hostClient.QueryHosts(
+		ctx,
+		&host_svc.QueryHostsRequest{HostStates: _maintenanceHostStates})
+	if err != nil {
+		return nil, err
+	}
+
+	maintenanceHosts := make(map[string]bool)
+	for _, hostInfo := range maintenanceResp.GetHostInfos() {
+		maintenanceHosts[hostInfo.GetHostname()] = true
+	}
+
+	groups := make(map[string]*placement.Group)
+	for _, agent := range agentResp.GetAgents() {
+		hostname := agent.GetAgentInfo().GetHostname()
+		if len(hostname) == 0 || maintenanceHosts[hostname] {
+			continue
+		}
+
+		// the revocable resources are not reserved by the stateless pods
+		var resources []*mesos.Resource
+		for _, resource := range agent.GetTotalResources() {
+			if resource.GetRevocable() == nil {
+				resources = append(resources, resource)
+			}
+		}
+
+		groups[hostname] = mimir.OfferToGroup(&hostsvc.HostOffer{
+			Hostname:   hostname,
+			AgentId:    agent.GetAgentInfo().GetId(),
+			Resources:  resources,
+			Attributes: agent.GetAgentInfo().GetAttributes(),
+		})
+	}
+	return groups, nil
+}
+
+// isRelocatable returns true if the task is a stateless pod
+// which is not pinned to a host
+func isRelocatable(task *resmgr.Task) bool {
+	return task.GetType() == resmgr.TaskType_STATELESS &&
+		len(task.GetDesiredHost()) == 0
+}
+
+// bestGroup returns the best group other than the current group to
+// relocate the entity of the relocation rank on, nil if the entity
+// does not fit any other group
+func bestGroup(
+	rank *placement.RelocationRank,
+	groups []*placement.Group,
+	scopeSet *placement.ScopeSet,
+) *placement.Group {
+	entity := rank.Entity
+	current := rank.CurrentGroup
+
+	// remove the entity from its current group as the relocator does,
+	// and add it back once done
+	current.Entities.Remove(entity)
+	current.Update()
+	defer func() {
+		current.Entities.Add(entity)
+		current.Update()
+	}()
+
+	var best *placement.Group
+	var bestTuple []float64
+	for _, group := range groups {
+		if group == current {
+			continue
+		}
+		transcript := placement.NewTranscript("relocation")
+		if !entity.Requirement.Passed(group, scopeSet, entity, transcript) {
+			continue
+		}
+		tuple := entity.Ordering.Tuple(group, scopeSet, entity)
+		if best == nil || placement.Less(tuple, bestTuple) {
+			best = group
+			bestTuple = tuple
+		}
+	}
+	return best
+}
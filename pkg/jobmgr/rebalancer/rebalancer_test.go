@@ -0,0 +1,491 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebalancer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	mesos_master "github.com/uber/peloton/.gen/mesos/v1/master"
+	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	hostmocks "github.com/uber/peloton/.gen/peloton/api/v0/host/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/rebalance"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	hostmgrmocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+	resmgrmocks "github.com/uber/peloton/.gen/peloton/private/resmgrsvc/mocks"
+
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/algorithms"
+	storemocks "github.com/uber/peloton/pkg/storage/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+const (
+	_testJobID = "bca875f5-322a-4439-b0c9-63e3cf9f982e"
+)
+
+type RebalancerTestSuite struct {
+	suite.Suite
+
+	ctrl            *gomock.Controller
+	rebalancer      *rebalancer
+	jobFactory      *cachedmocks.MockJobFactory
+	jobStore        *storemocks.MockJobStore
+	goalStateDriver *goalstatemocks.MockDriver
+	hostMgrClient   *hostmgrmocks.MockInternalHostServiceYARPCClient
+	hostClient      *hostmocks.MockHostServiceYARPCClient
+	resMgrClient    *resmgrmocks.MockResourceManagerServiceYARPCClient
+	scope           tally.TestScope
+
+	jobID     *peloton.JobID
+	cachedJob *cachedmocks.MockJob
+}
+
+func TestRebalancer(t *testing.T) {
+	suite.Run(t, new(RebalancerTestSuite))
+}
+
+func (suite *RebalancerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.jobStore = storemocks.NewMockJobStore(suite.ctrl)
+	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.ctrl)
+	suite.hostMgrClient = hostmgrmocks.NewMockInternalHostServiceYARPCClient(suite.ctrl)
+	suite.hostClient = hostmocks.NewMockHostServiceYARPCClient(suite.ctrl)
+	suite.resMgrClient = resmgrmocks.NewMockResourceManagerServiceYARPCClient(suite.ctrl)
+	suite.scope = tally.NewTestScope("", map[string]string{})
+	suite.rebalancer = &rebalancer{
+		config: &Config{
+			MaxMigrations: 10,
+		},
+		jobFactory:      suite.jobFactory,
+		jobStore:        suite.jobStore,
+		goalStateDriver: suite.goalStateDriver,
+		hostMgrClient:   suite.hostMgrClient,
+		hostClient:      suite.hostClient,
+		resMgrClient:    suite.resMgrClient,
+		relocator:       algorithms.NewRelocator(0, _relocatorMinimumSize),
+		metrics:         NewMetrics(suite.scope),
+	}
+
+	suite.jobID = &peloton.JobID{Value: _testJobID}
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.cachedJob.EXPECT().ID().Return(suite.jobID).AnyTimes()
+}
+
+func (suite *RebalancerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// createAgent creates a registered mesos agent with 10 cpus,
+// 1000 MB of memory and 1000 MB of disk
+func createAgent(hostname string) *mesos_master.Response_GetAgents_Agent {
+	return &mesos_master.Response_GetAgents_Agent{
+		AgentInfo: &mesos.AgentInfo{
+			Hostname: &hostname,
+		},
+		TotalResources: []*mesos.Resource{
+			util.NewMesosResourceBuilder().
+				WithName("cpus").
+				WithValue(10).
+				Build(),
+			util.NewMesosResourceBuilder().
+				WithName("mem").
+				WithValue(1000).
+				Build(),
+			util.NewMesosResourceBuilder().
+				WithName("disk").
+				WithValue(1000).
+				Build(),
+		},
+	}
+}
+
+// createTask creates a task of the test job using the given
+// share of the resources of an agent
+func createTask(
+	instanceID uint32,
+	taskType resmgr.TaskType,
+	share float64) *resmgr.Task {
+	return &resmgr.Task{
+		Id: &peloton.TaskID{
+			Value: util.CreatePelotonTaskID(_testJobID, instanceID),
+		},
+		JobId: &peloton.JobID{Value: _testJobID},
+		Type:  taskType,
+		Resource: &task.ResourceConfig{
+			CpuLimit:    10 * share,
+			MemLimitMb:  1000 * share,
+			DiskLimitMb: 1000 * share,
+		},
+	}
+}
+
+// expectHosts sets up the registered hosts, the hosts in
+// maintenance, and the tasks running on the hosts
+func (suite *RebalancerTestSuite) expectHosts(
+	hostTasks map[string][]*resmgr.Task,
+	maintenanceHosts []string,
+) {
+	var agents []*mesos_master.Response_GetAgents_Agent
+	for _, hostname := range []string{"host1", "host2", "host3", "host4"} {
+		agents = append(agents, createAgent(hostname))
+	}
+	suite.hostMgrClient.EXPECT().
+		GetMesosAgentInfo(gomock.Any(), &hostsvc.GetMesosAgentInfoRequest{}).
+		Return(&hostsvc.GetMesosAgentInfoResponse{Agents: agents}, nil)
+
+	var hostInfos []*hpb.HostInfo
+	for _, hostname := range maintenanceHosts {
+		hostInfos = append(hostInfos, &hpb.HostInfo{
+			Hostname: hostname,
+			State:    hpb.HostState_HOST_STATE_DRAINING,
+		})
+	}
+	suite.hostClient.EXPECT().
+		QueryHosts(gomock.Any(), &host_svc.QueryHostsRequest{
+			HostStates: _maintenanceHostStates,
+		}).
+		Return(&host_svc.QueryHostsResponse{HostInfos: hostInfos}, nil)
+
+	hostTasksMap := make(map[string]*resmgrsvc.TaskList)
+	for hostname, tasks := range hostTasks {
+		hostTasksMap[hostname] = &resmgrsvc.TaskList{Tasks: tasks}
+	}
+	// the hosts in maintenance are not ranked
+	suite.resMgrClient.EXPECT().
+		GetTasksByHosts(gomock.Any(), &resmgrsvc.GetTasksByHostsRequest{
+			Type:      resmgr.TaskType_UNKNOWN,
+			Hostnames: []string{"host1", "host2", "host3"},
+		}).
+		Return(&resmgrsvc.GetTasksByHostsResponse{HostTasksMap: hostTasksMap}, nil)
+}
+
+// expectRebalance sets up two stateless pods on a busy host1, and one
+// stateless pod on host3 which is as good as the idle host2.
+// host4 is in maintenance.
+func (suite *RebalancerTestSuite) expectRebalance() {
+	suite.expectHosts(
+		map[string][]*resmgr.Task{
+			"host1": {
+				createTask(0, resmgr.TaskType_STATELESS, 0.4),
+				createTask(1, resmgr.TaskType_STATELESS, 0.4),
+				createTask(3, resmgr.TaskType_BATCH, 0.1),
+			},
+			"host3": {
+				createTask(2, resmgr.TaskType_STATELESS, 0.1),
+			},
+		},
+		[]string{"host4"})
+}
+
+// createRecommendation creates the recommendation to relocate
+// an instance of the test job
+func createRecommendation(
+	instanceID uint32,
+	currentHost string,
+	targetHost string,
+	rank uint32) *rebalance.Recommendation {
+	return &rebalance.Recommendation{
+		PodName: &v1alphapeloton.PodName{
+			Value: util.CreatePelotonTaskID(_testJobID, instanceID),
+		},
+		CurrentHost: currentHost,
+		TargetHost:  targetHost,
+		Rank:        rank,
+	}
+}
+
+// TestRebalance tests computing the recommendations
+func (suite *RebalancerTestSuite) TestRebalance() {
+	suite.expectRebalance()
+	suite.rebalancer.Rebalance()
+
+	recommendations, generateTime := suite.rebalancer.GetRecommendations()
+	suite.False(generateTime.IsZero())
+	suite.Equal([]*rebalance.Recommendation{
+		createRecommendation(0, "host1", "host2", 2),
+		createRecommendation(1, "host1", "host2", 2),
+	}, recommendations)
+	suite.Equal(
+		float64(2),
+		suite.scope.Snapshot().Gauges()["recommendations+"].Value())
+}
+
+// TestRebalanceMinRank tests that the pods with fewer better
+// hosts than the min rank are not recommended
+func (suite *RebalancerTestSuite) TestRebalanceMinRank() {
+	suite.rebalancer.config.MinRank = 3
+	suite.expectRebalance()
+	suite.rebalancer.Rebalance()
+
+	recommendations, _ := suite.rebalancer.GetRecommendations()
+	suite.Empty(recommendations)
+}
+
+// TestRebalanceMaxRecommendations tests that only the recommendations
+// with the highest rank are kept
+func (suite *RebalancerTestSuite) TestRebalanceMaxRecommendations() {
+	suite.rebalancer.config.MaxRecommendations = 1
+	suite.expectRebalance()
+	suite.rebalancer.Rebalance()
+
+	recommendations, _ := suite.rebalancer.GetRecommendations()
+	suite.Equal([]*rebalance.Recommendation{
+		createRecommendation(0, "host1", "host2", 2),
+	}, recommendations)
+}
+
+// TestRebalanceFailure tests that the recommendations are kept
+// if they cannot be computed
+func (suite *RebalancerTestSuite) TestRebalanceFailure() {
+	suite.rebalancer.recommendations = []*rebalance.Recommendation{
+		createRecommendation(0, "host1", "host2", 2),
+	}
+	suite.hostMgrClient.EXPECT().
+		GetMesosAgentInfo(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error"))
+	suite.rebalancer.Rebalance()
+
+	recommendations, _ := suite.rebalancer.GetRecommendations()
+	suite.Len(recommendations, 1)
+}
+
+// expectRunningTasks sets up running instances of the test job on
+// host1, with the SLA of the job allowing one unavailable instance
+func (suite *RebalancerTestSuite) expectRunningTasks(
+	instanceIDs ...uint32,
+) map[uint32]*task.RuntimeInfo {
+	runtimes := make(map[uint32]*task.RuntimeInfo)
+	tasks := make(map[uint32]cached.Task)
+	for _, instanceID := range instanceIDs {
+		mesosTaskID := util.CreateMesosTaskID(suite.jobID, instanceID, 1)
+		runtime := &task.RuntimeInfo{
+			State:              task.TaskState_RUNNING,
+			GoalState:          task.TaskState_RUNNING,
+			Host:               "host1",
+			MesosTaskId:        mesosTaskID,
+			DesiredMesosTaskId: mesosTaskID,
+		}
+		cachedTask := cachedmocks.NewMockTask(suite.ctrl)
+		cachedTask.EXPECT().GetRuntime(gomock.Any()).Return(runtime, nil).AnyTimes()
+		suite.cachedJob.EXPECT().GetTask(instanceID).Return(cachedTask).AnyTimes()
+		runtimes[instanceID] = runtime
+		tasks[instanceID] = cachedTask
+	}
+
+	suite.jobFactory.EXPECT().GetJob(suite.jobID).Return(suite.cachedJob).AnyTimes()
+	suite.cachedJob.EXPECT().GetAllTasks().Return(tasks).AnyTimes()
+	suite.cachedJob.EXPECT().GetConfig(gomock.Any()).Return(&job.JobConfig{
+		SLA: &job.SlaConfig{MaximumUnavailableInstances: 1},
+	}, nil).AnyTimes()
+	return runtimes
+}
+
+// expectMigration expects the instance to be restarted on the target host
+func (suite *RebalancerTestSuite) expectMigration(
+	runtime *task.RuntimeInfo,
+	instanceID uint32,
+	targetHost string,
+) {
+	desiredMesosTaskID := util.CreateMesosTaskID(suite.jobID, instanceID, 2)
+	suite.cachedJob.EXPECT().
+		PatchTasks(gomock.Any(), map[uint32]jobmgrcommon.RuntimeDiff{
+			instanceID: {
+				jobmgrcommon.DesiredHostField:        targetHost,
+				jobmgrcommon.DesiredMesosTaskIDField: desiredMesosTaskID,
+				jobmgrcommon.MessageField:            _podMigratedMessage,
+			},
+		}).
+		Do(func(context.Context, map[uint32]jobmgrcommon.RuntimeDiff) {
+			runtime.DesiredHost = targetHost
+			runtime.DesiredMesosTaskId = desiredMesosTaskID
+		}).
+		Return(nil)
+	suite.goalStateDriver.EXPECT().EnqueueTask(suite.jobID, instanceID, gomock.Any())
+}
+
+// TestMigrate tests that the pods are migrated within the job SLA
+func (suite *RebalancerTestSuite) TestMigrate() {
+	suite.rebalancer.recommendations = []*rebalance.Recommendation{
+		createRecommendation(0, "host1", "host2", 2),
+		createRecommendation(1, "host1", "host2", 2),
+	}
+	runtimes := suite.expectRunningTasks(0, 1)
+	suite.expectMigration(runtimes[0], 0, "host2")
+
+	migrated, failures := suite.rebalancer.Migrate(context.Background(), 0)
+	suite.Empty(failures)
+	suite.Equal([]*rebalance.Recommendation{
+		createRecommendation(0, "host1", "host2", 2),
+	}, migrated)
+
+	// the migration of the second instance is held by the
+	// SLA, and its recommendation is kept for the next run
+	recommendations, _ := suite.rebalancer.GetRecommendations()
+	suite.Equal([]*rebalance.Recommendation{
+		createRecommendation(1, "host1", "host2", 2),
+	}, recommendations)
+	counters := suite.scope.Snapshot().Counters()
+	suite.Equal(int64(1), counters["pods_migrated+result=success"].Value())
+	suite.Equal(int64(1), counters["pods_held_by_sla+"].Value())
+}
+
+// TestMigrateMaxMigrations tests that the migrations are
+// bounded by the config
+func (suite *RebalancerTestSuite) TestMigrateMaxMigrations() {
+	suite.rebalancer.config.MaxMigrations = 1
+	suite.rebalancer.recommendations = []*rebalance.Recommendation{
+		createRecommendation(0, "host1", "host2", 2),
+		createRecommendation(1, "host1", "host3", 2),
+	}
+	runtimes := suite.expectRunningTasks(0, 1)
+	suite.expectMigration(runtimes[0], 0, "host2")
+
+	migrated, failures := suite.rebalancer.Migrate(context.Background(), 5)
+	suite.Empty(failures)
+	suite.Len(migrated, 1)
+}
+
+// TestMigrateStaleRecommendation tests that the recommendations of the
+// pods which moved or are being restarted are dropped
+func (suite *RebalancerTestSuite) TestMigrateStaleRecommendation() {
+	suite.rebalancer.recommendations = []*rebalance.Recommendation{
+		createRecommendation(0, "host3", "host2", 2),
+		createRecommendation(1, "host1", "host2", 2),
+		createRecommendation(5, "host1", "host2", 1),
+	}
+	runtimes := suite.expectRunningTasks(0, 1)
+	runtimes[1].DesiredMesosTaskId = util.CreateMesosTaskID(suite.jobID, 1, 2)
+	suite.cachedJob.EXPECT().GetTask(uint32(5)).Return(nil)
+
+	migrated, failures := suite.rebalancer.Migrate(context.Background(), 0)
+	suite.Empty(failures)
+	suite.Empty(migrated)
+
+	recommendations, _ := suite.rebalancer.GetRecommendations()
+	suite.Empty(recommendations)
+	suite.Equal(
+		int64(3),
+		suite.scope.Snapshot().Counters()["recommendations_stale+"].Value())
+}
+
+// TestMigratePatchFailure tests that the recommendation is kept
+// if the pod cannot be migrated
+func (suite *RebalancerTestSuite) TestMigratePatchFailure() {
+	suite.rebalancer.recommendations = []*rebalance.Recommendation{
+		createRecommendation(0, "host1", "host2", 2),
+	}
+	suite.expectRunningTasks(0)
+	suite.cachedJob.EXPECT().
+		PatchTasks(gomock.Any(), gomock.Any()).
+		Return(errors.New("test error"))
+	suite.goalStateDriver.EXPECT().EnqueueTask(suite.jobID, uint32(0), gomock.Any())
+
+	migrated, failures := suite.rebalancer.Migrate(context.Background(), 0)
+	suite.Empty(migrated)
+	suite.Equal([]*rebalance.MigrationFailure{
+		{
+			Recommendation: createRecommendation(0, "host1", "host2", 2),
+			Message:        "test error",
+		},
+	}, failures)
+
+	recommendations, _ := suite.rebalancer.GetRecommendations()
+	suite.Len(recommendations, 1)
+}
+
+// TestMigrateNotPermitted tests that the pods of the jobs the caller
+// is not permitted to restart are not migrated, and that the other
+// pods are migrated
+func (suite *RebalancerTestSuite) TestMigrateNotPermitted() {
+	otherJobID := &peloton.JobID{Value: "7ac74273-4ef0-4ca4-8fd2-34bc52aeac06"}
+	suite.rebalancer.recommendations = []*rebalance.Recommendation{
+		{
+			PodName: &v1alphapeloton.PodName{
+				Value: util.CreatePelotonTaskID(otherJobID.GetValue(), 0),
+			},
+			CurrentHost: "host1",
+			TargetHost:  "host2",
+			Rank:        3,
+		},
+		createRecommendation(0, "host1", "host2", 2),
+	}
+	runtimes := suite.expectRunningTasks(0)
+	suite.expectMigration(runtimes[0], 0, "host2")
+
+	user := authmocks.NewMockUser(suite.ctrl)
+	ctx := auth.WithUser(context.Background(), user, "procedure")
+	suite.jobStore.EXPECT().
+		GetJobConfig(gomock.Any(), otherJobID.GetValue()).
+		Return(&job.JobConfig{Owner: "owner2"}, nil, nil)
+	suite.jobStore.EXPECT().
+		GetJobConfig(gomock.Any(), _testJobID).
+		Return(&job.JobConfig{Owner: "owner1"}, nil, nil)
+	user.EXPECT().
+		IsPermittedOnEntity("procedure", &auth.Entity{Owner: "owner2"}).
+		Return(false)
+	user.EXPECT().
+		IsPermittedOnEntity("procedure", &auth.Entity{Owner: "owner1"}).
+		Return(true)
+
+	migrated, failures := suite.rebalancer.Migrate(ctx, 0)
+	suite.Equal([]*rebalance.Recommendation{
+		createRecommendation(0, "host1", "host2", 2),
+	}, migrated)
+	suite.Len(failures, 1)
+	suite.Equal(
+		suite.rebalancer.recommendations[0],
+		failures[0].GetRecommendation())
+
+	// the recommendation is kept for a caller which is permitted
+	recommendations, _ := suite.rebalancer.GetRecommendations()
+	suite.Len(recommendations, 1)
+}
+
+// TestRebalanceAutoMigrate tests that the pods are migrated
+// once the recommendations are computed
+func (suite *RebalancerTestSuite) TestRebalanceAutoMigrate() {
+	suite.rebalancer.config.AutoMigrate = true
+	suite.rebalancer.config.MaxMigrations = 1
+	suite.expectRebalance()
+	runtimes := suite.expectRunningTasks(0, 1)
+	suite.expectMigration(runtimes[0], 0, "host2")
+
+	suite.rebalancer.Rebalance()
+
+	recommendations, _ := suite.rebalancer.GetRecommendations()
+	suite.Equal([]*rebalance.Recommendation{
+		createRecommendation(1, "host1", "host2", 2),
+	}, recommendations)
+}
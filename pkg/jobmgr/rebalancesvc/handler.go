@@ -0,0 +1,117 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebalancesvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/rebalance/svc"
+
+	"github.com/uber/peloton/pkg/common/leader"
+	"github.com/uber/peloton/pkg/jobmgr/rebalancer"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// serviceHandler implements peloton.api.v1alpha.rebalance.svc.RebalanceService
+type serviceHandler struct {
+	metrics    *Metrics
+	rebalancer rebalancer.Rebalancer
+	candidate  leader.Candidate
+}
+
+// InitServiceHandler initializes the RebalanceService
+func InitServiceHandler(
+	d *yarpc.Dispatcher,
+	parent tally.Scope,
+	rebalancer rebalancer.Rebalancer,
+	candidate leader.Candidate,
+) {
+	handler := &serviceHandler{
+		metrics:    NewMetrics(parent),
+		rebalancer: rebalancer,
+		candidate:  candidate,
+	}
+
+	d.Register(svc.BuildRebalanceServiceYARPCProcedures(handler))
+}
+
+// GetRecommendations returns the current relocation recommendations
+// of the rebalancer, sorted by descending rank
+func (h *serviceHandler) GetRecommendations(
+	ctx context.Context,
+	req *svc.GetRecommendationsRequest,
+) (*svc.GetRecommendationsResponse, error) {
+	h.metrics.GetRecommendationsAPI.Inc(1)
+
+	if !h.candidate.IsLeader() {
+		h.metrics.GetRecommendationsFail.Inc(1)
+		return nil, yarpcerrors.UnavailableErrorf(
+			"RebalanceSVC.GetRecommendations is not supported on non-leader")
+	}
+
+	recommendations, generateTime := h.rebalancer.GetRecommendations()
+	if limit := int(req.GetLimit()); limit > 0 && len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+
+	resp := &svc.GetRecommendationsResponse{
+		Recommendations: recommendations,
+	}
+	if !generateTime.IsZero() {
+		resp.GenerateTime = generateTime.UTC().Format(time.RFC3339)
+	}
+
+	h.metrics.GetRecommendations.Inc(1)
+	return resp, nil
+}
+
+// ApplyRecommendations migrates the pods of the recommendations
+// with the highest rank to their target host. The recommendations
+// which fail to be applied, including the ones of the jobs the caller
+// is not permitted to restart, are returned with the migrations.
+func (h *serviceHandler) ApplyRecommendations(
+	ctx context.Context,
+	req *svc.ApplyRecommendationsRequest,
+) (*svc.ApplyRecommendationsResponse, error) {
+	h.metrics.ApplyRecommendationsAPI.Inc(1)
+
+	if !h.candidate.IsLeader() {
+		h.metrics.ApplyRecommendationsFail.Inc(1)
+		return nil, yarpcerrors.UnavailableErrorf(
+			"RebalanceSVC.ApplyRecommendations is not supported on non-leader")
+	}
+
+	migrations, failures := h.rebalancer.Migrate(ctx, req.GetMaxMigrations())
+	if len(failures) > 0 {
+		log.WithField("request", req).
+			WithField("migrations", len(migrations)).
+			WithField("failures", failures).
+			Warn("RebalanceSVC.ApplyRecommendations failed to apply some recommendations")
+	} else {
+		log.WithField("migrations", len(migrations)).
+			Info("RebalanceSVC.ApplyRecommendations succeeded")
+	}
+
+	h.metrics.ApplyRecommendations.Inc(1)
+	return &svc.ApplyRecommendationsResponse{
+		Migrations: migrations,
+		Failures:   failures,
+	}, nil
+}
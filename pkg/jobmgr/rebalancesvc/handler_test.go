@@ -0,0 +1,171 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebalancesvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/rebalance"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/rebalance/svc"
+
+	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
+	rebalancermocks "github.com/uber/peloton/pkg/jobmgr/rebalancer/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type RebalanceHandlerTestSuite struct {
+	suite.Suite
+
+	ctrl       *gomock.Controller
+	rebalancer *rebalancermocks.MockRebalancer
+	candidate  *leadermocks.MockCandidate
+	handler    *serviceHandler
+
+	recommendations []*rebalance.Recommendation
+}
+
+func TestRebalanceHandler(t *testing.T) {
+	suite.Run(t, new(RebalanceHandlerTestSuite))
+}
+
+func (suite *RebalanceHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.rebalancer = rebalancermocks.NewMockRebalancer(suite.ctrl)
+	suite.candidate = leadermocks.NewMockCandidate(suite.ctrl)
+	suite.handler = &serviceHandler{
+		metrics:    NewMetrics(tally.NoopScope),
+		rebalancer: suite.rebalancer,
+		candidate:  suite.candidate,
+	}
+
+	for i, podName := range []string{"job-0", "job-1", "job-2"} {
+		suite.recommendations = append(
+			suite.recommendations,
+			&rebalance.Recommendation{
+				PodName:     &v1alphapeloton.PodName{Value: podName},
+				CurrentHost: "host1",
+				TargetHost:  "host2",
+				Rank:        uint32(10 - i),
+			})
+	}
+}
+
+func (suite *RebalanceHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// TestGetRecommendations tests getting the recommendations with a limit
+func (suite *RebalanceHandlerTestSuite) TestGetRecommendations() {
+	generateTime := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.candidate.EXPECT().IsLeader().Return(true).Times(2)
+	suite.rebalancer.EXPECT().
+		GetRecommendations().
+		Return(suite.recommendations, generateTime).
+		Times(2)
+
+	resp, err := suite.handler.GetRecommendations(
+		context.Background(),
+		&svc.GetRecommendationsRequest{})
+	suite.NoError(err)
+	suite.Equal(suite.recommendations, resp.GetRecommendations())
+	suite.Equal("2019-06-01T12:00:00Z", resp.GetGenerateTime())
+
+	resp, err = suite.handler.GetRecommendations(
+		context.Background(),
+		&svc.GetRecommendationsRequest{Limit: 2})
+	suite.NoError(err)
+	suite.Equal(suite.recommendations[:2], resp.GetRecommendations())
+}
+
+// TestGetRecommendationsNotComputed tests getting the recommendations
+// before they are computed
+func (suite *RebalanceHandlerTestSuite) TestGetRecommendationsNotComputed() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.rebalancer.EXPECT().
+		GetRecommendations().
+		Return(nil, time.Time{})
+
+	resp, err := suite.handler.GetRecommendations(
+		context.Background(),
+		&svc.GetRecommendationsRequest{})
+	suite.NoError(err)
+	suite.Empty(resp.GetRecommendations())
+	suite.Empty(resp.GetGenerateTime())
+}
+
+// TestGetRecommendationsNonLeader tests getting the
+// recommendations from a non-leader
+func (suite *RebalanceHandlerTestSuite) TestGetRecommendationsNonLeader() {
+	suite.candidate.EXPECT().IsLeader().Return(false)
+
+	_, err := suite.handler.GetRecommendations(
+		context.Background(),
+		&svc.GetRecommendationsRequest{})
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestApplyRecommendations tests applying the recommendations
+func (suite *RebalanceHandlerTestSuite) TestApplyRecommendations() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.rebalancer.EXPECT().
+		Migrate(gomock.Any(), uint32(2)).
+		Return(suite.recommendations[:2], nil)
+
+	resp, err := suite.handler.ApplyRecommendations(
+		context.Background(),
+		&svc.ApplyRecommendationsRequest{MaxMigrations: 2})
+	suite.NoError(err)
+	suite.Equal(suite.recommendations[:2], resp.GetMigrations())
+}
+
+// TestApplyRecommendationsFailure tests that the migrations are returned
+// with the recommendations which failed to be applied
+func (suite *RebalanceHandlerTestSuite) TestApplyRecommendationsFailure() {
+	failures := []*rebalance.MigrationFailure{
+		{
+			Recommendation: suite.recommendations[1],
+			Message:        "test error",
+		},
+	}
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.rebalancer.EXPECT().
+		Migrate(gomock.Any(), uint32(0)).
+		Return(suite.recommendations[:1], failures)
+
+	resp, err := suite.handler.ApplyRecommendations(
+		context.Background(),
+		&svc.ApplyRecommendationsRequest{})
+	suite.NoError(err)
+	suite.Equal(suite.recommendations[:1], resp.GetMigrations())
+	suite.Equal(failures, resp.GetFailures())
+}
+
+// TestApplyRecommendationsNonLeader tests applying the
+// recommendations on a non-leader
+func (suite *RebalanceHandlerTestSuite) TestApplyRecommendationsNonLeader() {
+	suite.candidate.EXPECT().IsLeader().Return(false)
+
+	_, err := suite.handler.ApplyRecommendations(
+		context.Background(),
+		&svc.ApplyRecommendationsRequest{})
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
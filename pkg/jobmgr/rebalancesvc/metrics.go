@@ -0,0 +1,44 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebalancesvc
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is a placeholder for all metrics in rebalance service.
+type Metrics struct {
+	GetRecommendationsAPI  tally.Counter
+	GetRecommendations     tally.Counter
+	GetRecommendationsFail tally.Counter
+
+	ApplyRecommendationsAPI  tally.Counter
+	ApplyRecommendations     tally.Counter
+	ApplyRecommendationsFail tally.Counter
+}
+
+// NewMetrics returns a new instance of rebalancesvc.Metrics.
+func NewMetrics(scope tally.Scope) *Metrics {
+	subScope := scope.SubScope("rebalance")
+	return &Metrics{
+		GetRecommendationsAPI:  subScope.Counter("get_recommendations_api"),
+		GetRecommendations:     subScope.Counter("get_recommendations"),
+		GetRecommendationsFail: subScope.Counter("get_recommendations_fail"),
+
+		ApplyRecommendationsAPI:  subScope.Counter("apply_recommendations_api"),
+		ApplyRecommendations:     subScope.Counter("apply_recommendations"),
+		ApplyRecommendationsFail: subScope.Counter("apply_recommendations_fail"),
+	}
+}
@@ -48,6 +48,7 @@ type Server struct {
 	statusUpdate       event.StatusUpdate
 	backgroundManager  background.Manager
 	watchProcessor     watchsvc.WatchProcessor
+	watchPoller        *watchsvc.Poller
 }
 
 // NewServer creates a job manager Server instance.
@@ -61,6 +62,7 @@ func NewServer(
 	statusUpdate event.StatusUpdate,
 	backgroundManager background.Manager,
 	watchProcessor watchsvc.WatchProcessor,
+	watchPoller *watchsvc.Poller,
 ) *Server {
 	return &Server{
 		ID:                 leader.NewID(httpPort, grpcPort),
@@ -73,6 +75,7 @@ func NewServer(
 		statusUpdate:       statusUpdate,
 		backgroundManager:  backgroundManager,
 		watchProcessor:     watchProcessor,
+		watchPoller:        watchPoller,
 	}
 }
 
@@ -82,6 +85,9 @@ func (s *Server) GainedLeadershipCallback() error {
 
 	log.WithFields(log.Fields{"role": s.role}).Info("Gained leadership")
 
+	// watch processor numbers and persists the events emitted by the
+	// cache, so it is started first.
+	s.watchProcessor.Start()
 	s.jobFactory.Start()
 
 	// goalstateDriver will perform recovery of jobs from DB as
@@ -95,6 +101,9 @@ func (s *Server) GainedLeadershipCallback() error {
 	s.placementProcessor.Start()
 	s.deadlineTracker.Start()
 	s.statusUpdate.Start()
+	// the poller forgets the previous polls, which may be stale after
+	// leadership moved, so it notifies all resource pools and hosts again.
+	s.watchPoller.Start()
 	s.backgroundManager.Start()
 
 	return nil
@@ -113,7 +122,7 @@ func (s *Server) LostLeadershipCallback() error {
 	s.backgroundManager.Stop()
 	s.goalstateDriver.Stop()
 	s.jobFactory.Stop()
-	s.watchProcessor.StopTaskClients()
+	s.watchProcessor.Stop()
 
 	return nil
 }
@@ -130,7 +139,7 @@ func (s *Server) ShutDownCallback() error {
 	s.backgroundManager.Stop()
 	s.goalstateDriver.Stop()
 	s.jobFactory.Stop()
-	s.watchProcessor.StopTaskClients()
+	s.watchProcessor.Stop()
 
 	return nil
 }
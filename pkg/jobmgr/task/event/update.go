@@ -20,6 +20,7 @@ import (
 	"time"
 
 	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	pb_job "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	pb_task "github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/api/v0/volume"
 	pb_eventstream "github.com/uber/peloton/.gen/peloton/private/eventstream"
@@ -35,6 +36,7 @@ import (
 	taskutil "github.com/uber/peloton/pkg/jobmgr/util/task"
 	"github.com/uber/peloton/pkg/storage"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
@@ -150,7 +152,9 @@ func (p *statusUpdate) ProcessStatusUpdate(ctx context.Context, event *pb_events
 		return err
 	}
 
-	p.logTaskMetrics(updateEvent)
+	if len(updateEvent.containerName) == 0 {
+		p.logTaskMetrics(updateEvent)
+	}
 
 	isOrphanTask, taskInfo, err := p.isOrphanTaskEvent(ctx, updateEvent)
 	if err != nil {
@@ -178,6 +182,18 @@ func (p *statusUpdate) ProcessStatusUpdate(ctx context.Context, event *pb_events
 		return nil
 	}
 
+	// status updates of sidecar and init containers only
+	// update the runtime of the container
+	if len(updateEvent.containerName) != 0 {
+		return p.processContainerStatusUpdate(ctx, taskInfo, updateEvent)
+	}
+
+	// the results of the readiness check of a running task
+	// only update the readiness of the task
+	if isReadinessStatusUpdate(taskInfo, updateEvent) {
+		return p.processReadinessStatusUpdate(ctx, taskInfo, updateEvent)
+	}
+
 	// whether to skip or not if instance state is similar before and after
 	if isDuplicateStateUpdate(
 		taskInfo,
@@ -203,6 +219,12 @@ func (p *statusUpdate) ProcessStatusUpdate(ctx context.Context, event *pb_events
 		p.persistHealthyField(updateEvent.state, reason, healthy, runtimeDiff)
 	}
 
+	// Reset the readiness of terminated tasks
+	if taskInfo.GetConfig().GetReadinessCheck() != nil &&
+		util.IsPelotonStateTerminal(updateEvent.state) {
+		runtimeDiff[jobmgrcommon.ReadinessField] = pb_task.HealthState_INVALID
+	}
+
 	// Update FailureCount
 	updateFailureCount(updateEvent.state, taskInfo.GetRuntime(), runtimeDiff)
 
@@ -223,24 +245,8 @@ func (p *statusUpdate) ProcessStatusUpdate(ctx context.Context, event *pb_events
 		runtimeDiff[jobmgrcommon.ReasonField] = reason.String()
 		runtimeDiff[jobmgrcommon.StateField] = updateEvent.state
 		runtimeDiff[jobmgrcommon.MessageField] = msg
-		termStatus := &pb_task.TerminationStatus{
-			Reason: pb_task.TerminationStatus_TERMINATION_STATUS_REASON_FAILED,
-		}
-		if code, err := taskutil.GetExitStatusFromMessage(msg); err == nil {
-			termStatus.ExitCode = code
-		} else if yarpcerrors.IsNotFound(err) == false {
-			log.WithField("task_id", updateEvent.taskID).
-				WithField("error", err).
-				Debug("Failed to extract exit status from message")
-		}
-		if sig, err := taskutil.GetSignalFromMessage(msg); err == nil {
-			termStatus.Signal = sig
-		} else if yarpcerrors.IsNotFound(err) == false {
-			log.WithField("task_id", updateEvent.taskID).
-				WithField("error", err).
-				Debug("Failed to extract termination signal from message")
-		}
-		runtimeDiff[jobmgrcommon.TerminationStatusField] = termStatus
+		runtimeDiff[jobmgrcommon.TerminationStatusField] =
+			getFailedTerminationStatus(updateEvent.taskID, msg)
 
 	case pb_task.TaskState_LOST:
 		runtimeDiff[jobmgrcommon.ReasonField] = event.GetMesosTaskStatus().GetReason().String()
@@ -320,6 +326,13 @@ func (p *statusUpdate) ProcessStatusUpdate(ctx context.Context, event *pb_events
 			// when task starts running, there is no need to keep desired host field around.
 			// it would be set again upon in-place update using the current running host.
 			runtimeDiff[jobmgrcommon.DesiredHostField] = ""
+
+			// the instances of a daemon job stay pinned to their host,
+			// so keep the desired host field of daemon tasks
+			if len(taskInfo.GetRuntime().GetDesiredHost()) != 0 &&
+				p.jobFactory.AddJob(taskInfo.GetJobId()).GetJobType() == pb_job.JobType_DAEMON {
+				delete(runtimeDiff, jobmgrcommon.DesiredHostField)
+			}
 		}
 
 	} else if util.IsPelotonStateTerminal(runtimeDiff[jobmgrcommon.StateField].(pb_task.TaskState)) {
@@ -377,11 +390,221 @@ func (p *statusUpdate) ProcessStatusUpdate(ctx context.Context, event *pb_events
 	return nil
 }
 
+// processReadinessStatusUpdate records the result of the readiness check
+// of a running task, and enqueues the job into the goal state engine so
+// that the update of the job can move forward once the task is ready.
+func (p *statusUpdate) processReadinessStatusUpdate(
+	ctx context.Context,
+	taskInfo *pb_task.TaskInfo,
+	updateEvent *statusUpateEvent,
+) error {
+	readiness, ok := getReadinessFromCheckStatus(
+		updateEvent.mesosTaskStatus.GetCheckStatus())
+	if !ok || readiness == taskInfo.GetRuntime().GetReadiness() {
+		return nil
+	}
+
+	if readiness == pb_task.HealthState_HEALTHY {
+		p.metrics.TasksReadyTotal.Inc(1)
+	} else {
+		p.metrics.TasksNotReadyTotal.Inc(1)
+	}
+
+	cachedJob := p.jobFactory.AddJob(taskInfo.GetJobId())
+	err := cachedJob.PatchTasks(
+		ctx,
+		map[uint32]jobmgrcommon.RuntimeDiff{taskInfo.GetInstanceId(): {
+			jobmgrcommon.ReadinessField: readiness,
+		}},
+	)
+	if err != nil {
+		log.WithError(err).
+			WithFields(log.Fields{
+				"task_id":   updateEvent.taskID,
+				"readiness": readiness.String()}).
+			Error("Fail to update readiness for taskID")
+		return err
+	}
+
+	goalstate.EnqueueJobWithDefaultDelay(
+		taskInfo.GetJobId(), p.goalStateDriver, cachedJob)
+	return nil
+}
+
+// isReadinessStatusUpdate returns true if the status update carries the
+// result of the readiness check of a running task
+func isReadinessStatusUpdate(
+	taskInfo *pb_task.TaskInfo,
+	updateEvent *statusUpateEvent,
+) bool {
+	return updateEvent.isMesosStatus &&
+		updateEvent.mesosTaskStatus.GetReason() ==
+			mesos_v1.TaskStatus_REASON_TASK_CHECK_STATUS_UPDATED &&
+		updateEvent.state == pb_task.TaskState_RUNNING &&
+		taskInfo.GetRuntime().GetState() == pb_task.TaskState_RUNNING
+}
+
+// getReadinessFromCheckStatus returns the readiness of a task from the
+// result of its Mesos check. It returns false if the result of the check
+// is not available, e.g. before the first check completes.
+func getReadinessFromCheckStatus(
+	checkStatus *mesos_v1.CheckStatusInfo,
+) (pb_task.HealthState, bool) {
+	var ready bool
+	switch checkStatus.GetType() {
+	case mesos_v1.CheckInfo_COMMAND:
+		if checkStatus.GetCommand().ExitCode == nil {
+			return pb_task.HealthState_INVALID, false
+		}
+		ready = checkStatus.GetCommand().GetExitCode() == 0
+	case mesos_v1.CheckInfo_HTTP:
+		if checkStatus.GetHttp().StatusCode == nil {
+			return pb_task.HealthState_INVALID, false
+		}
+		code := checkStatus.GetHttp().GetStatusCode()
+		ready = code >= 200 && code < 400
+	case mesos_v1.CheckInfo_TCP:
+		if checkStatus.GetTcp().Succeeded == nil {
+			return pb_task.HealthState_INVALID, false
+		}
+		ready = checkStatus.GetTcp().GetSucceeded()
+	default:
+		return pb_task.HealthState_INVALID, false
+	}
+
+	if ready {
+		return pb_task.HealthState_HEALTHY, true
+	}
+	return pb_task.HealthState_UNHEALTHY, true
+}
+
+// processContainerStatusUpdate records the status update of a sidecar or
+// init container in the runtime of the container. The state of the task
+// itself follows its primary container.
+func (p *statusUpdate) processContainerStatusUpdate(
+	ctx context.Context,
+	taskInfo *pb_task.TaskInfo,
+	updateEvent *statusUpateEvent,
+) error {
+	var containers []*pb_task.ContainerRuntimeInfo
+	var found bool
+	for _, container := range taskInfo.GetRuntime().GetContainers() {
+		if container.GetName() == updateEvent.containerName {
+			container = proto.Clone(container).(*pb_task.ContainerRuntimeInfo)
+			updateContainerRuntime(container, updateEvent)
+			found = true
+		}
+		containers = append(containers, container)
+	}
+
+	if !found {
+		log.WithFields(log.Fields{
+			"task_id":        updateEvent.taskID,
+			"container_name": updateEvent.containerName,
+			"state":          updateEvent.state.String(),
+		}).Info("received status update for unknown container")
+		return nil
+	}
+
+	cachedJob := p.jobFactory.AddJob(taskInfo.GetJobId())
+	err := cachedJob.PatchTasks(
+		ctx,
+		map[uint32]jobmgrcommon.RuntimeDiff{taskInfo.GetInstanceId(): {
+			jobmgrcommon.ContainersField: containers,
+		}},
+	)
+	if err != nil {
+		log.WithError(err).
+			WithFields(log.Fields{
+				"task_id":        updateEvent.taskID,
+				"container_name": updateEvent.containerName,
+				"state":          updateEvent.state}).
+			Error("Fail to update container runtime for taskID")
+		return err
+	}
+	return nil
+}
+
+// updateContainerRuntime updates the runtime of a container
+// with its status update
+func updateContainerRuntime(
+	container *pb_task.ContainerRuntimeInfo,
+	updateEvent *statusUpateEvent,
+) {
+	reason := updateEvent.mesosTaskStatus.GetReason()
+	if updateEvent.state == pb_task.TaskState_RUNNING &&
+		reason == mesos_v1.TaskStatus_REASON_TASK_CHECK_STATUS_UPDATED {
+		if readiness, ok := getReadinessFromCheckStatus(
+			updateEvent.mesosTaskStatus.GetCheckStatus()); ok {
+			container.Readiness = readiness
+		}
+	}
+
+	if updateEvent.state == pb_task.TaskState_RUNNING &&
+		reason == mesos_v1.TaskStatus_REASON_TASK_HEALTH_CHECK_STATUS_UPDATED {
+		if updateEvent.mesosTaskStatus.GetHealthy() {
+			container.Healthy = pb_task.HealthState_HEALTHY
+		} else {
+			container.Healthy = pb_task.HealthState_UNHEALTHY
+		}
+	}
+
+	if updateEvent.state == pb_task.TaskState_RUNNING &&
+		container.GetState() != pb_task.TaskState_RUNNING {
+		container.StartTime = now().UTC().Format(time.RFC3339Nano)
+		container.CompletionTime = ""
+	} else if util.IsPelotonStateTerminal(updateEvent.state) &&
+		!util.IsPelotonStateTerminal(container.GetState()) {
+		container.CompletionTime = now().UTC().Format(time.RFC3339Nano)
+		container.Healthy = pb_task.HealthState_INVALID
+		container.Readiness = pb_task.HealthState_INVALID
+	}
+
+	if updateEvent.state == pb_task.TaskState_FAILED {
+		container.TerminationStatus = getFailedTerminationStatus(
+			updateEvent.taskID, updateEvent.statusMsg)
+	}
+
+	container.State = updateEvent.state
+	container.Message = updateEvent.statusMsg
+	container.Reason = reason.String()
+}
+
+// getFailedTerminationStatus returns the termination status of a failed
+// task or container, with the exit code or signal in the Mesos message.
+func getFailedTerminationStatus(
+	taskID string,
+	msg string,
+) *pb_task.TerminationStatus {
+	termStatus := &pb_task.TerminationStatus{
+		Reason: pb_task.TerminationStatus_TERMINATION_STATUS_REASON_FAILED,
+	}
+	if code, err := taskutil.GetExitStatusFromMessage(msg); err == nil {
+		termStatus.ExitCode = code
+	} else if yarpcerrors.IsNotFound(err) == false {
+		log.WithField("task_id", taskID).
+			WithField("error", err).
+			Debug("Failed to extract exit status from message")
+	}
+	if sig, err := taskutil.GetSignalFromMessage(msg); err == nil {
+		termStatus.Signal = sig
+	} else if yarpcerrors.IsNotFound(err) == false {
+		log.WithField("task_id", taskID).
+			WithField("error", err).
+			Debug("Failed to extract termination signal from message")
+	}
+	return termStatus
+}
+
 type statusUpateEvent struct {
 	taskID    string
 	state     pb_task.TaskState
 	statusMsg string
 
+	// name of the sidecar or init container, which is
+	// empty for the primary container of the task
+	containerName string
+
 	isMesosStatus   bool
 	mesosTaskStatus *mesos_v1.TaskStatus
 }
@@ -401,6 +624,7 @@ func convertEvent(event *pb_eventstream.Event) (*statusUpateEvent, error) {
 				Error("Fail to parse taskID for mesostaskID")
 			return nil, err
 		}
+		_, updateEvent.containerName = util.ParseContainerMesosTaskID(mesosTaskID)
 		updateEvent.state = util.MesosStateToPelotonState(event.MesosTaskStatus.GetState())
 		updateEvent.statusMsg = event.MesosTaskStatus.GetMessage()
 
@@ -488,9 +712,12 @@ func (p *statusUpdate) isOrphanTaskEvent(
 		return false, nil, err
 	}
 
+	// the status updates of sidecar and init containers belong
+	// to the mesos task id of the task
 	dbTaskID := taskInfo.GetRuntime().GetMesosTaskId().GetValue()
-	if event.isMesosStatus && dbTaskID !=
-		event.mesosTaskStatus.GetTaskId().GetValue() {
+	eventTaskID, _ := util.ParseContainerMesosTaskID(
+		event.mesosTaskStatus.GetTaskId().GetValue())
+	if event.isMesosStatus && dbTaskID != eventTaskID {
 		log.WithFields(log.Fields{
 			"orphan_task_id":        event.mesosTaskStatus.GetTaskId().GetValue(),
 			"db_task_id":            dbTaskID,
@@ -311,6 +311,8 @@ func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateInPlaceUpdateTask() {
 			GetTaskByID(context.Background(), _pelotonTaskID).
 			Return(taskInfo, nil),
 		suite.jobFactory.EXPECT().AddJob(_pelotonJobID).Return(cachedJob),
+		cachedJob.EXPECT().GetJobType().Return(job.JobType_SERVICE),
+		suite.jobFactory.EXPECT().AddJob(_pelotonJobID).Return(cachedJob),
 		cachedJob.EXPECT().SetTaskUpdateTime(event.MesosTaskStatus.Timestamp).Return(),
 		cachedJob.EXPECT().PatchTasks(context.Background(), runtimeDiffs).Return(nil),
 		suite.goalStateDriver.EXPECT().EnqueueTask(_pelotonJobID, _instanceID, gomock.Any()).Return(),
@@ -354,6 +356,8 @@ func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateInPlaceUpdateTask() {
 			GetTaskByID(context.Background(), _pelotonTaskID).
 			Return(taskInfo, nil),
 		suite.jobFactory.EXPECT().AddJob(_pelotonJobID).Return(cachedJob),
+		cachedJob.EXPECT().GetJobType().Return(job.JobType_SERVICE),
+		suite.jobFactory.EXPECT().AddJob(_pelotonJobID).Return(cachedJob),
 		cachedJob.EXPECT().SetTaskUpdateTime(event.MesosTaskStatus.Timestamp).Return(),
 		cachedJob.EXPECT().PatchTasks(context.Background(), runtimeDiffs).Return(nil),
 		suite.goalStateDriver.EXPECT().EnqueueTask(_pelotonJobID, _instanceID, gomock.Any()).Return(),
@@ -378,6 +382,52 @@ func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateInPlaceUpdateTask() {
 
 }
 
+// Test case of processing status update for a daemon task, which
+// keeps its desired host when it starts running
+func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateDaemonTask() {
+	defer suite.ctrl.Finish()
+
+	hostname := "hostname1"
+	cachedJob := cachedmocks.NewMockJob(suite.ctrl)
+
+	event := createTestTaskUpdateEvent(mesos.TaskState_TASK_RUNNING)
+	timeNow := float64(time.Now().UnixNano())
+	event.MesosTaskStatus.Timestamp = &timeNow
+	taskInfo := createTestTaskInfo(task.TaskState_INITIALIZED)
+	taskInfo.Runtime.Host = hostname
+	taskInfo.Runtime.DesiredHost = hostname
+	runtimeDiff := jobmgrcommon.RuntimeDiff{
+		jobmgrcommon.MessageField:        "testFailure",
+		jobmgrcommon.CompletionTimeField: "",
+		jobmgrcommon.StateField:          task.TaskState_RUNNING,
+		jobmgrcommon.StartTimeField:      _currentTime,
+		jobmgrcommon.ReasonField:         "",
+	}
+	runtimeDiffs := make(map[uint32]jobmgrcommon.RuntimeDiff)
+	runtimeDiffs[_instanceID] = runtimeDiff
+
+	gomock.InOrder(
+		suite.mockTaskStore.EXPECT().
+			GetTaskByID(context.Background(), _pelotonTaskID).
+			Return(taskInfo, nil),
+		suite.jobFactory.EXPECT().AddJob(_pelotonJobID).Return(cachedJob),
+		cachedJob.EXPECT().GetJobType().Return(job.JobType_DAEMON),
+		suite.jobFactory.EXPECT().AddJob(_pelotonJobID).Return(cachedJob),
+		cachedJob.EXPECT().SetTaskUpdateTime(event.MesosTaskStatus.Timestamp).Return(),
+		cachedJob.EXPECT().PatchTasks(context.Background(), runtimeDiffs).Return(nil),
+		suite.goalStateDriver.EXPECT().EnqueueTask(_pelotonJobID, _instanceID, gomock.Any()).Return(),
+		cachedJob.EXPECT().GetJobType().Return(job.JobType_DAEMON),
+		suite.goalStateDriver.EXPECT().
+			JobRuntimeDuration(job.JobType_DAEMON).
+			Return(1*time.Second),
+		suite.goalStateDriver.EXPECT().EnqueueJob(_pelotonJobID, gomock.Any()).Return(),
+		cachedJob.EXPECT().UpdateResourceUsage(gomock.Any()).Return(),
+	)
+
+	now = nowMock
+	suite.NoError(suite.updater.ProcessStatusUpdate(context.Background(), event))
+}
+
 // Test processing Health check event
 func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateHealthy() {
 	defer suite.ctrl.Finish()
@@ -726,6 +776,266 @@ func (suite *TaskUpdaterTestSuite) doTestProcessTaskFailedStatusUpdate(
 	time.Sleep(_waitTime)
 }
 
+// createTestContainerTaskInfo returns a task with a sidecar container
+// and the status update of the sidecar container
+func createTestContainerTaskInfo(
+	state mesos.TaskState,
+) (*task.TaskInfo, *pb_eventstream.Event) {
+	containerMesosTaskID := _mesosTaskID + ".proxy"
+	taskInfo := createTestTaskInfo(task.TaskState_RUNNING)
+	taskInfo.Runtime.Containers = []*task.ContainerRuntimeInfo{
+		{
+			Name:        "proxy",
+			State:       task.TaskState_LAUNCHED,
+			MesosTaskId: &mesos.TaskID{Value: &containerMesosTaskID},
+			Healthy:     task.HealthState_HEALTH_UNKNOWN,
+		},
+	}
+	event := createTestTaskUpdateEvent(state)
+	event.MesosTaskStatus.TaskId = &mesos.TaskID{Value: &containerMesosTaskID}
+	return taskInfo, event
+}
+
+// TestProcessReadinessStatusUpdate tests processing the results of the
+// readiness check of a running task
+func (suite *TaskUpdaterTestSuite) TestProcessReadinessStatusUpdate() {
+	defer suite.ctrl.Finish()
+
+	exitCodeZero := int32(0)
+	exitCodeOne := int32(1)
+	checkReason := mesos.TaskStatus_REASON_TASK_CHECK_STATUS_UPDATED
+	commandCheck := mesos.CheckInfo_COMMAND
+
+	tt := []struct {
+		previousReadiness task.HealthState
+		exitCode          *int32
+		readiness         task.HealthState
+		updated           bool
+		readyCounter      int64
+		notReadyCounter   int64
+		msg               string
+	}{
+		{
+			previousReadiness: task.HealthState_HEALTH_UNKNOWN,
+			exitCode:          &exitCodeZero,
+			readiness:         task.HealthState_HEALTHY,
+			updated:           true,
+			readyCounter:      1,
+			notReadyCounter:   0,
+			msg:               "task becomes ready",
+		},
+		{
+			previousReadiness: task.HealthState_HEALTHY,
+			exitCode:          &exitCodeZero,
+			updated:           false,
+			readyCounter:      1,
+			notReadyCounter:   0,
+			msg:               "task stays ready",
+		},
+		{
+			previousReadiness: task.HealthState_HEALTHY,
+			exitCode:          &exitCodeOne,
+			readiness:         task.HealthState_UNHEALTHY,
+			updated:           true,
+			readyCounter:      1,
+			notReadyCounter:   1,
+			msg:               "task becomes not ready",
+		},
+		{
+			previousReadiness: task.HealthState_HEALTH_UNKNOWN,
+			exitCode:          nil,
+			updated:           false,
+			readyCounter:      1,
+			notReadyCounter:   1,
+			msg:               "result of the check not available",
+		},
+	}
+
+	for _, t := range tt {
+		taskInfo := createTestTaskInfo(task.TaskState_RUNNING)
+		taskInfo.Runtime.Readiness = t.previousReadiness
+		event := createTestTaskUpdateEvent(mesos.TaskState_TASK_RUNNING)
+		event.MesosTaskStatus.Reason = &checkReason
+		event.MesosTaskStatus.CheckStatus = &mesos.CheckStatusInfo{
+			Type: &commandCheck,
+			Command: &mesos.CheckStatusInfo_Command{
+				ExitCode: t.exitCode,
+			},
+		}
+
+		suite.mockTaskStore.EXPECT().
+			GetTaskByID(context.Background(), _pelotonTaskID).
+			Return(taskInfo, nil)
+		if t.updated {
+			cachedJob := cachedmocks.NewMockJob(suite.ctrl)
+			gomock.InOrder(
+				suite.jobFactory.EXPECT().AddJob(_pelotonJobID).Return(cachedJob),
+				cachedJob.EXPECT().
+					PatchTasks(context.Background(), map[uint32]jobmgrcommon.RuntimeDiff{
+						_instanceID: {jobmgrcommon.ReadinessField: t.readiness},
+					}).
+					Return(nil),
+				cachedJob.EXPECT().GetJobType().Return(job.JobType_SERVICE),
+				suite.goalStateDriver.EXPECT().
+					JobRuntimeDuration(job.JobType_SERVICE).
+					Return(1*time.Second),
+				suite.goalStateDriver.EXPECT().EnqueueJob(_pelotonJobID, gomock.Any()),
+			)
+		}
+
+		suite.NoError(suite.updater.ProcessStatusUpdate(context.Background(), event), t.msg)
+		suite.Equal(
+			t.readyCounter,
+			suite.testScope.Snapshot().Counters()["status_updater.tasks_ready_total+"].Value(),
+			t.msg)
+		suite.Equal(
+			t.notReadyCounter,
+			suite.testScope.Snapshot().Counters()["status_updater.tasks_not_ready_total+"].Value(),
+			t.msg)
+	}
+}
+
+// TestGetReadinessFromCheckStatus tests converting the result of a Mesos
+// check to the readiness of a task
+func (suite *TaskUpdaterTestSuite) TestGetReadinessFromCheckStatus() {
+	commandCheck := mesos.CheckInfo_COMMAND
+	httpCheck := mesos.CheckInfo_HTTP
+	tcpCheck := mesos.CheckInfo_TCP
+	statusOK := uint32(200)
+	statusError := uint32(503)
+	succeeded := true
+
+	tt := []struct {
+		checkStatus *mesos.CheckStatusInfo
+		readiness   task.HealthState
+		ok          bool
+	}{
+		{
+			checkStatus: nil,
+			ok:          false,
+		},
+		{
+			checkStatus: &mesos.CheckStatusInfo{
+				Type:    &commandCheck,
+				Command: &mesos.CheckStatusInfo_Command{},
+			},
+			ok: false,
+		},
+		{
+			checkStatus: &mesos.CheckStatusInfo{
+				Type: &httpCheck,
+				Http: &mesos.CheckStatusInfo_Http{StatusCode: &statusOK},
+			},
+			readiness: task.HealthState_HEALTHY,
+			ok:        true,
+		},
+		{
+			checkStatus: &mesos.CheckStatusInfo{
+				Type: &httpCheck,
+				Http: &mesos.CheckStatusInfo_Http{StatusCode: &statusError},
+			},
+			readiness: task.HealthState_UNHEALTHY,
+			ok:        true,
+		},
+		{
+			checkStatus: &mesos.CheckStatusInfo{
+				Type: &tcpCheck,
+				Tcp:  &mesos.CheckStatusInfo_Tcp{Succeeded: &succeeded},
+			},
+			readiness: task.HealthState_HEALTHY,
+			ok:        true,
+		},
+	}
+
+	for _, t := range tt {
+		readiness, ok := getReadinessFromCheckStatus(t.checkStatus)
+		suite.Equal(t.ok, ok)
+		if t.ok {
+			suite.Equal(t.readiness, readiness)
+		}
+	}
+}
+
+// TestProcessContainerStatusUpdate tests that the status update of a
+// sidecar container only updates the runtime of the container
+func (suite *TaskUpdaterTestSuite) TestProcessContainerStatusUpdate() {
+	defer suite.ctrl.Finish()
+	now = nowMock
+
+	cachedJob := cachedmocks.NewMockJob(suite.ctrl)
+	taskInfo, event := createTestContainerTaskInfo(mesos.TaskState_TASK_RUNNING)
+
+	suite.mockTaskStore.EXPECT().
+		GetTaskByID(context.Background(), _pelotonTaskID).
+		Return(taskInfo, nil)
+	suite.jobFactory.EXPECT().
+		AddJob(_pelotonJobID).Return(cachedJob)
+	cachedJob.EXPECT().
+		PatchTasks(context.Background(), gomock.Any()).
+		Do(func(ctx context.Context, runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff) {
+			runtimeDiff := runtimeDiffs[_instanceID]
+			suite.Len(runtimeDiff, 1)
+			containers := runtimeDiff[jobmgrcommon.ContainersField].([]*task.ContainerRuntimeInfo)
+			suite.Len(containers, 1)
+			suite.Equal(task.TaskState_RUNNING, containers[0].GetState())
+			suite.Equal(_currentTime, containers[0].GetStartTime())
+			suite.Equal(_mesosReason.String(), containers[0].GetReason())
+			suite.Equal(_failureMsg, containers[0].GetMessage())
+		}).
+		Return(nil)
+
+	suite.NoError(suite.updater.ProcessStatusUpdate(context.Background(), event))
+	// the runtime in the cache must not be modified
+	suite.Equal(task.TaskState_LAUNCHED, taskInfo.GetRuntime().GetContainers()[0].GetState())
+}
+
+// TestProcessContainerFailedStatusUpdate tests processing the failure
+// of a sidecar container
+func (suite *TaskUpdaterTestSuite) TestProcessContainerFailedStatusUpdate() {
+	defer suite.ctrl.Finish()
+	now = nowMock
+
+	cachedJob := cachedmocks.NewMockJob(suite.ctrl)
+	taskInfo, event := createTestContainerTaskInfo(mesos.TaskState_TASK_FAILED)
+	event.MesosTaskStatus.Message = &_failureMsgExitCode
+
+	suite.mockTaskStore.EXPECT().
+		GetTaskByID(context.Background(), _pelotonTaskID).
+		Return(taskInfo, nil)
+	suite.jobFactory.EXPECT().
+		AddJob(_pelotonJobID).Return(cachedJob)
+	cachedJob.EXPECT().
+		PatchTasks(context.Background(), gomock.Any()).
+		Do(func(ctx context.Context, runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff) {
+			containers := runtimeDiffs[_instanceID][jobmgrcommon.ContainersField].([]*task.ContainerRuntimeInfo)
+			suite.Equal(task.TaskState_FAILED, containers[0].GetState())
+			suite.Equal(_currentTime, containers[0].GetCompletionTime())
+			suite.Equal(task.HealthState_INVALID, containers[0].GetHealthy())
+			suite.Equal(&task.TerminationStatus{
+				Reason:   task.TerminationStatus_TERMINATION_STATUS_REASON_FAILED,
+				ExitCode: 250,
+			}, containers[0].GetTerminationStatus())
+		}).
+		Return(fmt.Errorf("patch error"))
+
+	suite.Error(suite.updater.ProcessStatusUpdate(context.Background(), event))
+}
+
+// TestProcessUnknownContainerStatusUpdate tests that the status update
+// of an unknown container is ignored
+func (suite *TaskUpdaterTestSuite) TestProcessUnknownContainerStatusUpdate() {
+	defer suite.ctrl.Finish()
+
+	taskInfo, event := createTestContainerTaskInfo(mesos.TaskState_TASK_RUNNING)
+	taskInfo.Runtime.Containers = nil
+
+	suite.mockTaskStore.EXPECT().
+		GetTaskByID(context.Background(), _pelotonTaskID).
+		Return(taskInfo, nil)
+
+	suite.NoError(suite.updater.ProcessStatusUpdate(context.Background(), event))
+}
+
 // Test processing task LOST status update w/ retry.
 func (suite *TaskUpdaterTestSuite) TestProcessTaskLostStatusUpdateWithRetry() {
 	defer suite.ctrl.Finish()
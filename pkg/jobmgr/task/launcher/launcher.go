@@ -34,9 +34,11 @@ import (
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/backoff"
+	"github.com/uber/peloton/pkg/common/taskconfig"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	taskutil "github.com/uber/peloton/pkg/jobmgr/util/task"
 	"github.com/uber/peloton/pkg/storage"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
@@ -315,11 +317,13 @@ func (l *launcher) GetLaunchableTasks(
 			runtimeDiff[jobmgrcommon.StateField] = task.TaskState_LAUNCHED
 		}
 
+		ports := cachedRuntime.GetPorts()
 		if selectedPorts != nil {
 			// Reset runtime ports to get new ports assignment if placement has ports.
-			ports := make(map[string]uint32)
-			// Assign selected dynamic port to task per port config.
-			for _, portConfig := range taskConfig.GetPorts() {
+			ports = make(map[string]uint32)
+			// Assign selected dynamic port to task per port config,
+			// including the ports of the sidecar and init containers.
+			for _, portConfig := range taskconfig.GetAllPorts(taskConfig) {
 				if portConfig.GetValue() != 0 {
 					// Skip static port.
 					continue
@@ -338,6 +342,13 @@ func (l *launcher) GetLaunchableTasks(
 			runtimeDiff[jobmgrcommon.PortsField] = ports
 		}
 
+		if taskconfig.HasMultipleContainers(taskConfig) {
+			runtimeDiff[jobmgrcommon.ContainersField] = taskutil.CreateContainersRuntime(
+				taskConfig, cachedRuntime.GetMesosTaskId(), ports)
+		}
+		runtimeDiff[jobmgrcommon.ReadinessField] =
+			taskutil.GetInitialReadinessState(taskConfig.GetReadinessCheck())
+
 		runtimeDiff[jobmgrcommon.MessageField] = "Add hostname and ports"
 		runtimeDiff[jobmgrcommon.ReasonField] = "REASON_UPDATE_OFFER"
 
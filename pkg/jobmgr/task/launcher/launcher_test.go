@@ -237,9 +237,64 @@ func (suite *LauncherTestSuite) TestGetLaunchableTasks() {
 		suite.Equal(task.TaskState_LAUNCHED, runtimeDiff[jobmgrcommon.StateField])
 		suite.Equal(hostOffer.Hostname, runtimeDiff[jobmgrcommon.HostField])
 		suite.Equal(hostOffer.AgentId, runtimeDiff[jobmgrcommon.AgentIDField])
+		suite.Equal(task.HealthState_DISABLED, runtimeDiff[jobmgrcommon.ReadinessField])
 	}
 	suite.EqualValues(unknownTasks, skippedTasks)
 }
+
+// TestGetLaunchableTasksWithSidecars tests that the dynamic ports of the
+// sidecar containers are assigned and their runtime is initialized
+func (suite *LauncherTestSuite) TestGetLaunchableTasksWithSidecars() {
+	taskInfo := createTestTask(0)
+	taskInfo.GetConfig().Sidecars = []*task.ContainerConfig{
+		{
+			Name:     "proxy",
+			Resource: &_defaultResourceConfig,
+			Ports: []*task.PortConfig{
+				{
+					Name:    "proxy",
+					EnvName: "PROXY_PORT",
+				},
+			},
+		},
+	}
+	taskID := &peloton.TaskID{
+		Value: taskInfo.JobId.Value + "-" + fmt.Sprint(taskInfo.InstanceId),
+	}
+	hostOffer := createHostOffer(0, createResources(1))
+
+	suite.jobFactory.EXPECT().
+		GetJob(taskInfo.GetJobId()).Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		AddTask(gomock.Any(), uint32(0)).
+		Return(suite.cachedTask, nil)
+	suite.mockTaskStore.EXPECT().
+		GetTaskConfig(gomock.Any(), taskInfo.GetJobId(), uint32(0), gomock.Any()).
+		Return(taskInfo.GetConfig(), &models.ConfigAddOn{}, nil)
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).Return(taskInfo.GetRuntime(), nil).AnyTimes()
+
+	launchableTasks, _, err := suite.taskLauncher.GetLaunchableTasks(
+		context.Background(), []*peloton.TaskID{taskID}, hostOffer.Hostname,
+		hostOffer.AgentId, []uint32{testPort, testPort + 1})
+	suite.NoError(err)
+
+	runtimeDiff := launchableTasks[taskID.GetValue()].RuntimeDiff
+	suite.Equal(map[string]uint32{
+		"port":  testPort,
+		"proxy": testPort + 1,
+	}, runtimeDiff[jobmgrcommon.PortsField])
+
+	containers := runtimeDiff[jobmgrcommon.ContainersField].([]*task.ContainerRuntimeInfo)
+	suite.Len(containers, 1)
+	suite.Equal("proxy", containers[0].GetName())
+	suite.Equal(task.TaskState_LAUNCHED, containers[0].GetState())
+	suite.Equal(map[string]uint32{"proxy": testPort + 1}, containers[0].GetPorts())
+	suite.Equal(
+		taskInfo.GetRuntime().GetMesosTaskId().GetValue()+".proxy",
+		containers[0].GetMesosTaskId().GetValue())
+}
+
 func (suite *LauncherTestSuite) TestGetLaunchableTasksStateful() {
 	unknownTasks := []*peloton.TaskID{
 		{Value: "bcabcabc-bcab-bcab-bcab-bcabcabcabca-0"},
@@ -23,6 +23,12 @@ import (
 type Metrics struct {
 	TaskPreemptSuccess tally.Counter
 	TaskPreemptFail    tally.Counter
+	// TaskPreemptHeld counts the evictions for host maintenance which
+	// are held because of the SLA of the job
+	TaskPreemptHeld tally.Counter
+	// HostsBlockedBySLA is the number of hosts in maintenance whose
+	// drain was blocked by a job SLA in the last preemption cycle
+	HostsBlockedBySLA tally.Gauge
 
 	GetPreemptibleTasks             tally.Counter
 	GetPreemptibleTasksFail         tally.Counter
@@ -40,6 +46,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	return &Metrics{
 		TaskPreemptSuccess: taskSuccessScope.Counter("preempt"),
 		TaskPreemptFail:    taskFailScope.Counter("preempt"),
+		TaskPreemptHeld:    scope.Counter("preempt_held_by_sla"),
+		HostsBlockedBySLA:  scope.Gauge("hosts_blocked_by_sla"),
 
 		GetPreemptibleTasks:             taskAPIScope.Counter("get_preemptible_tasks"),
 		GetPreemptibleTasksFail:         taskFailScope.Counter("get_preemptible_tasks"),
@@ -16,6 +16,7 @@ package preemptor
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
@@ -29,6 +30,7 @@ import (
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
 	"github.com/uber/peloton/pkg/storage"
 
 	multierror "github.com/hashicorp/go-multierror"
@@ -165,6 +167,10 @@ func (p *preemptor) preemptTasks(
 	preemptionCandidates []*resmgr.PreemptionCandidate,
 ) error {
 	errs := new(multierror.Error)
+	// tasks whose eviction for host maintenance is held
+	var heldTasks []*resmgrsvc.UpdateTasksStateRequest_UpdateTaskStateEntry
+	// host -> IDs of the jobs blocking its maintenance
+	blockedHosts := make(map[string]map[string]struct{})
 	for _, task := range preemptionCandidates {
 		log.WithField("task_ID", task.Id.Value).
 			Info("preempting running task")
@@ -193,6 +199,34 @@ func (p *preemptor) preemptTasks(
 			continue
 		}
 
+		if task.GetReason() ==
+			resmgr.PreemptionReason_PREEMPTION_REASON_HOST_MAINTENANCE {
+			withinSLA, err := jobutil.IsWithinUnavailableInstances(ctx, cachedJob)
+			if err != nil {
+				errs = multierror.Append(errs, err)
+				continue
+			}
+			if !withinSLA {
+				log.WithFields(log.Fields{
+					"task_ID":  task.Id.Value,
+					"hostname": runtime.GetHost(),
+				}).Info("holding eviction of task for host maintenance, " +
+					"job has reached its maximum unavailable instances")
+				p.metrics.TaskPreemptHeld.Inc(1)
+				heldTasks = append(heldTasks,
+					&resmgrsvc.UpdateTasksStateRequest_UpdateTaskStateEntry{
+						Task:        task.Id,
+						MesosTaskId: runtime.GetMesosTaskId(),
+						State:       pbtask.TaskState_RUNNING,
+					})
+				if _, ok := blockedHosts[runtime.GetHost()]; !ok {
+					blockedHosts[runtime.GetHost()] = make(map[string]struct{})
+				}
+				blockedHosts[runtime.GetHost()][jobID.GetValue()] = struct{}{}
+				continue
+			}
+		}
+
 		preemptPolicy, err := p.getTaskPreemptionPolicy(
 			ctx, jobID, uint32(instanceID), runtime.GetConfigVersion())
 		if err != nil {
@@ -218,9 +252,54 @@ func (p *preemptor) preemptTasks(
 				jobID, p.goalStateDriver, cachedJob)
 		}
 	}
+
+	if len(heldTasks) > 0 {
+		if err := p.releaseHeldTasks(ctx, heldTasks); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+		reportBlockedHosts(blockedHosts)
+	}
+	p.metrics.HostsBlockedBySLA.Update(float64(len(blockedHosts)))
 	return errs.ErrorOrNil()
 }
 
+// releaseHeldTasks moves the tasks whose eviction is held back to RUNNING
+// in resource manager, so that they are preempted again by the next drain
+// cycle of their host. Resource manager then reports their jobs to host
+// manager as blocking the drain of the host, which returns them with the
+// host in the host query API.
+func (p *preemptor) releaseHeldTasks(
+	ctx context.Context,
+	heldTasks []*resmgrsvc.UpdateTasksStateRequest_UpdateTaskStateEntry,
+) error {
+	ctx, cancelFunc := context.WithTimeout(ctx, _timeoutFunctionCall)
+	defer cancelFunc()
+
+	_, err := p.resMgrClient.UpdateTasksState(
+		ctx,
+		&resmgrsvc.UpdateTasksStateRequest{TaskStates: heldTasks})
+	if err != nil {
+		return errors.Wrap(err, "failed to release held tasks")
+	}
+	return nil
+}
+
+// reportBlockedHosts logs the jobs which block the maintenance of
+// each host because of their SLA
+func reportBlockedHosts(blockedHosts map[string]map[string]struct{}) {
+	for host, jobs := range blockedHosts {
+		var jobIDs []string
+		for jobID := range jobs {
+			jobIDs = append(jobIDs, jobID)
+		}
+		sort.Strings(jobIDs)
+		log.WithFields(log.Fields{
+			"hostname": host,
+			"job_ids":  jobIDs,
+		}).Warn("host maintenance blocked by job SLA")
+	}
+}
+
 func (p *preemptor) getTasks() ([]*resmgr.PreemptionCandidate, error) {
 	ctx, cancelFunc := context.WithTimeout(context.Background(), _timeoutFunctionCall)
 	defer cancelFunc()
@@ -31,6 +31,7 @@ import (
 
 	"github.com/uber/peloton/pkg/common/lifecycle"
 	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
@@ -218,6 +219,8 @@ func (suite *PreemptorTestSuite) TestPreemptionCycle() {
 		noRestartMaintTaskInfo.Runtime,
 		nil,
 	)
+	// the job has no maximum unavailable instances
+	cachedJob.EXPECT().GetConfig(gomock.Any()).Return(&job.JobConfig{}, nil)
 
 	cachedJob.EXPECT().PatchTasks(gomock.Any(), gomock.Any()).
 		Do(func(ctx context.Context,
@@ -317,6 +320,114 @@ func (suite *PreemptorTestSuite) TestPreemptionCycleGetRuntimeError() {
 	suite.Error(err)
 }
 
+// TestPreemptionCycleHostMaintenanceHeld tests that the eviction of a task
+// for host maintenance is held if its job has reached its maximum
+// unavailable instances
+func (suite *PreemptorTestSuite) TestPreemptionCycleHostMaintenanceHeld() {
+	cachedJob := cachedmocks.NewMockJob(suite.mockCtrl)
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	taskID := &peloton.TaskID{Value: fmt.Sprintf("%s-%d", jobID.GetValue(), 0)}
+	mesosTaskID := util.CreateMesosTaskID(jobID, 0, 1)
+
+	runtimes := []*peloton_task.RuntimeInfo{
+		{
+			State:       peloton_task.TaskState_RUNNING,
+			GoalState:   peloton_task.TaskState_RUNNING,
+			MesosTaskId: mesosTaskID,
+			Host:        "host1",
+		},
+		// being killed for host maintenance
+		{
+			State:     peloton_task.TaskState_RUNNING,
+			GoalState: peloton_task.TaskState_KILLED,
+		},
+		// stopped on purpose
+		{
+			State:     peloton_task.TaskState_KILLED,
+			GoalState: peloton_task.TaskState_KILLED,
+		},
+	}
+	cachedTasks := make(map[uint32]*cachedmocks.MockTask)
+	allTasks := make(map[uint32]cached.Task)
+	for i, runtime := range runtimes {
+		cachedTask := cachedmocks.NewMockTask(suite.mockCtrl)
+		cachedTask.EXPECT().GetRuntime(gomock.Any()).Return(runtime, nil).AnyTimes()
+		cachedTasks[uint32(i)] = cachedTask
+		allTasks[uint32(i)] = cachedTask
+	}
+
+	suite.mockResmgr.EXPECT().GetPreemptibleTasks(gomock.Any(), gomock.Any()).Return(
+		&resmgrsvc.GetPreemptibleTasksResponse{
+			PreemptionCandidates: []*resmgr.PreemptionCandidate{
+				{
+					Id:     taskID,
+					Reason: resmgr.PreemptionReason_PREEMPTION_REASON_HOST_MAINTENANCE,
+				},
+			},
+		}, nil,
+	)
+	suite.jobFactory.EXPECT().AddJob(jobID).Return(cachedJob)
+	cachedJob.EXPECT().AddTask(gomock.Any(), uint32(0)).Return(cachedTasks[0], nil)
+	cachedJob.EXPECT().GetConfig(gomock.Any()).Return(&job.JobConfig{
+		SLA: &job.SlaConfig{MaximumUnavailableInstances: 1},
+	}, nil)
+	cachedJob.EXPECT().GetAllTasks().Return(allTasks)
+	suite.mockResmgr.EXPECT().UpdateTasksState(
+		gomock.Any(),
+		&resmgrsvc.UpdateTasksStateRequest{
+			TaskStates: []*resmgrsvc.UpdateTasksStateRequest_UpdateTaskStateEntry{
+				{
+					Task:        taskID,
+					MesosTaskId: mesosTaskID,
+					State:       peloton_task.TaskState_RUNNING,
+				},
+			},
+		}).Return(&resmgrsvc.UpdateTasksStateResponse{}, nil)
+
+	err := suite.preemptor.performPreemptionCycle()
+	suite.NoError(err)
+}
+
+// TestPreemptionCycleHostMaintenanceReleaseError tests the failure to
+// release a task whose eviction is held
+func (suite *PreemptorTestSuite) TestPreemptionCycleHostMaintenanceReleaseError() {
+	cachedJob := cachedmocks.NewMockJob(suite.mockCtrl)
+	cachedTask := cachedmocks.NewMockTask(suite.mockCtrl)
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	taskID := &peloton.TaskID{Value: fmt.Sprintf("%s-%d", jobID.GetValue(), 0)}
+	runtime := &peloton_task.RuntimeInfo{
+		State:       peloton_task.TaskState_RUNNING,
+		GoalState:   peloton_task.TaskState_RUNNING,
+		MesosTaskId: util.CreateMesosTaskID(jobID, 0, 1),
+		// restarted on this host after its previous run was preempted
+		DesiredMesosTaskId: util.CreateMesosTaskID(jobID, 0, 2),
+	}
+
+	suite.mockResmgr.EXPECT().GetPreemptibleTasks(gomock.Any(), gomock.Any()).Return(
+		&resmgrsvc.GetPreemptibleTasksResponse{
+			PreemptionCandidates: []*resmgr.PreemptionCandidate{
+				{
+					Id:     taskID,
+					Reason: resmgr.PreemptionReason_PREEMPTION_REASON_HOST_MAINTENANCE,
+				},
+			},
+		}, nil,
+	)
+	suite.jobFactory.EXPECT().AddJob(jobID).Return(cachedJob)
+	cachedJob.EXPECT().AddTask(gomock.Any(), uint32(0)).Return(cachedTask, nil)
+	cachedTask.EXPECT().GetRuntime(gomock.Any()).Return(runtime, nil).Times(2)
+	cachedJob.EXPECT().GetConfig(gomock.Any()).Return(&job.JobConfig{
+		SLA: &job.SlaConfig{MaximumUnavailableInstances: 1},
+	}, nil)
+	cachedJob.EXPECT().GetAllTasks().
+		Return(map[uint32]cached.Task{0: cachedTask})
+	suite.mockResmgr.EXPECT().UpdateTasksState(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake UpdateTasksState error"))
+
+	err := suite.preemptor.performPreemptionCycle()
+	suite.Error(err)
+}
+
 func (suite *PreemptorTestSuite) TestReconciler_StartStop() {
 	defer func() {
 		suite.preemptor.Stop()
@@ -395,6 +395,11 @@ func (m *serviceHandler) Start(
 		return nil, yarpcerrors.UnavailableErrorf("Task Start API not suppported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobPermission(ctx, body.GetJobId(), m.jobStore); err != nil {
+		m.metrics.TaskStartFail.Inc(1)
+		return nil, err
+	}
+
 	cachedJob := m.jobFactory.AddJob(body.JobId)
 	cachedConfig, err := cachedJob.GetConfig(ctx)
 
@@ -667,6 +672,11 @@ func (m *serviceHandler) Stop(
 		return nil, yarpcerrors.UnavailableErrorf("Task Stop API not suppported on non-leader")
 	}
 
+	if err := handlerutil.CheckJobPermission(ctx, body.GetJobId(), m.jobStore); err != nil {
+		m.metrics.TaskStopFail.Inc(1)
+		return nil, err
+	}
+
 	cachedJob := m.jobFactory.AddJob(body.JobId)
 	cachedConfig, err := cachedJob.GetConfig(ctx)
 
@@ -790,6 +800,11 @@ func (m *serviceHandler) Restart(
 	)
 	defer cancelFunc()
 
+	if err := handlerutil.CheckJobPermission(ctx, req.GetJobId(), m.jobStore); err != nil {
+		m.metrics.TaskRestartFail.Inc(1)
+		return nil, err
+	}
+
 	cachedJob := m.jobFactory.AddJob(req.JobId)
 	runtimeDiffs, err := m.getRuntimeDiffsForRestart(ctx,
 		cachedJob,
@@ -24,10 +24,12 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/update/svc"
 	"github.com/uber/peloton/.gen/peloton/private/models"
 
+	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/common"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
 	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
 	"github.com/uber/peloton/pkg/storage"
 
@@ -147,6 +149,11 @@ func (h *serviceHandler) CreateUpdate(
 		return nil, err
 	}
 
+	if err := auth.CheckEntityPermission(ctx, handlerutil.JobEntity(prevJobConfig)); err != nil {
+		h.metrics.UpdateCreateFail.Inc(1)
+		return nil, err
+	}
+
 	// check that job type is service
 	if prevJobConfig.GetType() != job.JobType_SERVICE {
 		h.metrics.UpdateCreateFail.Inc(1)
@@ -228,6 +235,7 @@ func (h *serviceHandler) GetUpdate(ctx context.Context, req *svc.GetUpdateReques
 					updateModel.GetInstancesFailed(),
 				NumTasksFailed: updateModel.GetInstancesFailed(),
 				State:          updateModel.GetState(),
+				Canary:         updateModel.GetCanaryStatus(),
 			},
 		}
 
@@ -257,6 +265,7 @@ func (h *serviceHandler) GetUpdate(ctx context.Context, req *svc.GetUpdateReques
 				updateModel.GetInstancesFailed(),
 			NumTasksFailed: updateModel.GetInstancesFailed(),
 			State:          updateModel.GetState(),
+			Canary:         updateModel.GetCanaryStatus(),
 		},
 	}
 
@@ -308,6 +317,11 @@ func (h *serviceHandler) PauseUpdate(
 		return nil, err
 	}
 
+	if err := handlerutil.CheckJobPermission(ctx, cachedJob.ID(), h.jobStore); err != nil {
+		h.metrics.UpdatePauseFail.Inc(1)
+		return nil, err
+	}
+
 	runtime, err := cachedJob.GetRuntime(ctx)
 	if err != nil {
 		h.metrics.UpdatePauseFail.Inc(1)
@@ -348,6 +362,11 @@ func (h *serviceHandler) ResumeUpdate(
 		return nil, err
 	}
 
+	if err := handlerutil.CheckJobPermission(ctx, cachedJob.ID(), h.jobStore); err != nil {
+		h.metrics.UpdateResumeFail.Inc(1)
+		return nil, err
+	}
+
 	runtime, err := cachedJob.GetRuntime(ctx)
 	if err != nil {
 		h.metrics.UpdateResumeFail.Inc(1)
@@ -410,6 +429,7 @@ func (h *serviceHandler) ListUpdates(ctx context.Context,
 					updateModel.GetInstancesFailed(),
 				NumTasksFailed: updateModel.GetInstancesFailed(),
 				State:          updateModel.GetState(),
+				Canary:         updateModel.GetCanaryStatus(),
 			},
 		}
 
@@ -432,6 +452,11 @@ func (h *serviceHandler) AbortUpdate(ctx context.Context,
 		return nil, err
 	}
 
+	if err := handlerutil.CheckJobPermission(ctx, cachedJob.ID(), h.jobStore); err != nil {
+		h.metrics.UpdateAbortFail.Inc(1)
+		return nil, err
+	}
+
 	runtime, err := cachedJob.GetRuntime(ctx)
 	if err != nil {
 		h.metrics.UpdatePauseFail.Inc(1)
@@ -17,6 +17,7 @@ package handler
 import (
 	"reflect"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pelotonv0query "github.com/uber/peloton/.gen/peloton/api/v0/query"
@@ -31,8 +32,9 @@ import (
 
 	"github.com/uber/peloton/pkg/common/util"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
+	taskutil "github.com/uber/peloton/pkg/jobmgr/util/task"
 
-	"go.uber.org/yarpc/yarpcerrors"
+	"github.com/gogo/protobuf/proto"
 )
 
 // ConvertTaskStateToPodState converts v0 task.TaskState to v1alpha pod.PodState
@@ -135,7 +137,7 @@ func ConvertV1InstanceRangeToV0InstanceRange(
 // ConvertTaskRuntimeToPodStatus converts
 // v0 task.RuntimeInfo to v1alpha pod.PodStatus
 func ConvertTaskRuntimeToPodStatus(runtime *task.RuntimeInfo) *pod.PodStatus {
-	return &pod.PodStatus{
+	status := &pod.PodStatus{
 		State:          ConvertTaskStateToPodState(runtime.GetState()),
 		PodId:          &v1alphapeloton.PodID{Value: runtime.GetMesosTaskId().GetValue()},
 		StartTime:      runtime.GetStartTime(),
@@ -147,6 +149,9 @@ func ConvertTaskRuntimeToPodStatus(runtime *task.RuntimeInfo) *pod.PodStatus {
 				Healthy: &pod.HealthStatus{
 					State: pod.HealthState(runtime.GetHealthy()),
 				},
+				Readiness: &pod.HealthStatus{
+					State: pod.HealthState(runtime.GetReadiness()),
+				},
 				StartTime:      runtime.GetStartTime(),
 				CompletionTime: runtime.GetCompletionTime(),
 				Message:        runtime.GetMessage(),
@@ -173,7 +178,70 @@ func ConvertTaskRuntimeToPodStatus(runtime *task.RuntimeInfo) *pod.PodStatus {
 		ResourceUsage: runtime.GetResourceUsage(),
 		DesiredPodId:  &v1alphapeloton.PodID{Value: runtime.GetDesiredMesosTaskId().GetValue()},
 		DesiredHost:   runtime.GetDesiredHost(),
+		Ready:         taskutil.IsTaskReady(runtime),
 	}
+
+	for _, container := range runtime.GetContainers() {
+		status.ContainersStatus = append(
+			status.ContainersStatus,
+			convertContainerRuntimeToContainerStatus(container),
+		)
+	}
+
+	return status
+}
+
+// convertContainerRuntimeToContainerStatus converts the v0 runtime of
+// a sidecar or init container to v1alpha pod.ContainerStatus
+func convertContainerRuntimeToContainerStatus(
+	container *task.ContainerRuntimeInfo,
+) *pod.ContainerStatus {
+	return &pod.ContainerStatus{
+		Name:  container.GetName(),
+		State: convertTaskStateToContainerState(container.GetState()),
+		Ports: container.GetPorts(),
+		Healthy: &pod.HealthStatus{
+			State: pod.HealthState(container.GetHealthy()),
+		},
+		Readiness: &pod.HealthStatus{
+			State: pod.HealthState(container.GetReadiness()),
+		},
+		StartTime:      container.GetStartTime(),
+		CompletionTime: container.GetCompletionTime(),
+		Message:        container.GetMessage(),
+		Reason:         container.GetReason(),
+		TerminationStatus: convertTaskTerminationStatusToPodTerminationStatus(
+			container.GetTerminationStatus()),
+	}
+}
+
+// convertTaskStateToContainerState converts v0 task.TaskState
+// to v1alpha pod.ContainerState
+func convertTaskStateToContainerState(state task.TaskState) pod.ContainerState {
+	switch state {
+	case task.TaskState_INITIALIZED,
+		task.TaskState_PENDING,
+		task.TaskState_READY,
+		task.TaskState_PLACING,
+		task.TaskState_PLACED,
+		task.TaskState_LAUNCHING:
+		return pod.ContainerState_CONTAINER_STATE_PENDING
+	case task.TaskState_LAUNCHED:
+		return pod.ContainerState_CONTAINER_STATE_LAUNCHED
+	case task.TaskState_STARTING:
+		return pod.ContainerState_CONTAINER_STATE_STARTING
+	case task.TaskState_RUNNING:
+		return pod.ContainerState_CONTAINER_STATE_RUNNING
+	case task.TaskState_SUCCEEDED:
+		return pod.ContainerState_CONTAINER_STATE_SUCCEEDED
+	case task.TaskState_FAILED, task.TaskState_LOST:
+		return pod.ContainerState_CONTAINER_STATE_FAILED
+	case task.TaskState_PREEMPTING, task.TaskState_KILLING:
+		return pod.ContainerState_CONTAINER_STATE_KILLING
+	case task.TaskState_KILLED:
+		return pod.ContainerState_CONTAINER_STATE_KILLED
+	}
+	return pod.ContainerState_CONTAINER_STATE_INVALID
 }
 
 // ConvertTaskConfigToPodSpec converts v0 task.TaskConfig to v1alpha pod.PodSpec
@@ -249,33 +317,112 @@ func ConvertTaskConfigToPodSpec(taskConfig *task.TaskConfig, jobID string, insta
 	}
 
 	if taskConfig.GetHealthCheck() != nil {
-		container.LivenessCheck = &pod.HealthCheckSpec{
-			Enabled:                taskConfig.GetHealthCheck().GetEnabled(),
-			InitialIntervalSecs:    taskConfig.GetHealthCheck().GetInitialIntervalSecs(),
-			IntervalSecs:           taskConfig.GetHealthCheck().GetIntervalSecs(),
-			MaxConsecutiveFailures: taskConfig.GetHealthCheck().GetMaxConsecutiveFailures(),
-			TimeoutSecs:            taskConfig.GetHealthCheck().GetTimeoutSecs(),
-			Type:                   pod.HealthCheckSpec_HealthCheckType(taskConfig.GetHealthCheck().GetType()),
+		container.LivenessCheck = convertHealthCheckConfigToHealthCheckSpec(
+			taskConfig.GetHealthCheck())
+	}
+
+	if taskConfig.GetReadinessCheck() != nil {
+		container.ReadinessCheck = convertHealthCheckConfigToHealthCheckSpec(
+			taskConfig.GetReadinessCheck())
+	}
+
+	if !reflect.DeepEqual(*container, pod.ContainerSpec{}) {
+		result.Containers = []*pod.ContainerSpec{container}
+	}
+
+	for _, sidecar := range taskConfig.GetSidecars() {
+		result.Containers = append(
+			result.Containers,
+			convertContainerConfigToContainerSpec(sidecar),
+		)
+	}
+
+	for _, initContainer := range taskConfig.GetInitContainers() {
+		result.InitContainers = append(
+			result.InitContainers,
+			convertContainerConfigToContainerSpec(initContainer),
+		)
+	}
+
+	return result
+}
+
+// convertContainerConfigToContainerSpec converts the v0 task.ContainerConfig
+// of a sidecar or init container to v1alpha pod.ContainerSpec
+func convertContainerConfigToContainerSpec(
+	config *task.ContainerConfig,
+) *pod.ContainerSpec {
+	container := &pod.ContainerSpec{
+		Name:      config.GetName(),
+		Container: config.GetContainer(),
+		Command:   config.GetCommand(),
+		Ports:     ConvertPortConfigsToPortSpecs(config.GetPorts()),
+	}
+
+	if config.GetResource() != nil {
+		container.Resource = &pod.ResourceSpec{
+			CpuLimit:    config.GetResource().GetCpuLimit(),
+			MemLimitMb:  config.GetResource().GetMemLimitMb(),
+			DiskLimitMb: config.GetResource().GetDiskLimitMb(),
+			FdLimit:     config.GetResource().GetFdLimit(),
+			GpuLimit:    config.GetResource().GetGpuLimit(),
 		}
+	}
 
-		if taskConfig.GetHealthCheck().GetCommandCheck() != nil {
-			container.LivenessCheck.CommandCheck = &pod.HealthCheckSpec_CommandCheck{
-				Command:             taskConfig.GetHealthCheck().GetCommandCheck().GetCommand(),
-				UnshareEnvironments: taskConfig.GetHealthCheck().GetCommandCheck().GetUnshareEnvironments(),
-			}
+	if config.GetHealthCheck() != nil {
+		container.LivenessCheck = convertHealthCheckConfigToHealthCheckSpec(
+			config.GetHealthCheck())
+	}
+
+	if config.GetReadinessCheck() != nil {
+		container.ReadinessCheck = convertHealthCheckConfigToHealthCheckSpec(
+			config.GetReadinessCheck())
+	}
+
+	return container
+}
+
+// convertHealthCheckConfigToHealthCheckSpec converts v0
+// task.HealthCheckConfig to v1alpha pod.HealthCheckSpec
+func convertHealthCheckConfigToHealthCheckSpec(
+	healthCheck *task.HealthCheckConfig,
+) *pod.HealthCheckSpec {
+	result := &pod.HealthCheckSpec{
+		Enabled:                healthCheck.GetEnabled(),
+		InitialIntervalSecs:    healthCheck.GetInitialIntervalSecs(),
+		IntervalSecs:           healthCheck.GetIntervalSecs(),
+		MaxConsecutiveFailures: healthCheck.GetMaxConsecutiveFailures(),
+		TimeoutSecs:            healthCheck.GetTimeoutSecs(),
+		Type:                   pod.HealthCheckSpec_HealthCheckType(healthCheck.GetType()),
+	}
+
+	if healthCheck.GetCommandCheck() != nil {
+		result.CommandCheck = &pod.HealthCheckSpec_CommandCheck{
+			Command:             healthCheck.GetCommandCheck().GetCommand(),
+			UnshareEnvironments: healthCheck.GetCommandCheck().GetUnshareEnvironments(),
 		}
+	}
 
-		if taskConfig.GetHealthCheck().GetHttpCheck() != nil {
-			container.LivenessCheck.HttpCheck = &pod.HealthCheckSpec_HTTPCheck{
-				Scheme: taskConfig.GetHealthCheck().GetHttpCheck().GetScheme(),
-				Port:   taskConfig.GetHealthCheck().GetHttpCheck().GetPort(),
-				Path:   taskConfig.GetHealthCheck().GetHttpCheck().GetPath(),
-			}
+	if healthCheck.GetHttpCheck() != nil {
+		result.HttpCheck = &pod.HealthCheckSpec_HTTPCheck{
+			Scheme: healthCheck.GetHttpCheck().GetScheme(),
+			Port:   healthCheck.GetHttpCheck().GetPort(),
+			Path:   healthCheck.GetHttpCheck().GetPath(),
 		}
 	}
 
-	if !reflect.DeepEqual(*container, pod.ContainerSpec{}) {
-		result.Containers = []*pod.ContainerSpec{container}
+	if healthCheck.GetGrpcCheck() != nil {
+		result.GrpcCheck = &pod.HealthCheckSpec_GRPCCheck{
+			Port:      healthCheck.GetGrpcCheck().GetPort(),
+			Service:   healthCheck.GetGrpcCheck().GetService(),
+			ProbePath: healthCheck.GetGrpcCheck().GetProbePath(),
+		}
+	}
+
+	if healthCheck.GetTcpCheck() != nil {
+		result.TcpCheck = &pod.HealthCheckSpec_TCPCheck{
+			Port: healthCheck.GetTcpCheck().GetPort(),
+		}
 	}
 
 	return result
@@ -454,6 +601,23 @@ func ConvertUpdateModelToWorkflowStatus(
 		PrevVersion:           prevVersion,
 		CreationTime:          updateInfo.GetCreationTime(),
 		UpdateTime:            updateInfo.GetUpdateTime(),
+		Canary:                ConvertCanaryStatusToV1Alpha(updateInfo.GetCanaryStatus()),
+	}
+}
+
+// ConvertCanaryStatusToV1Alpha converts v0 update.CanaryStatus
+// to v1alpha stateless.CanaryStatus
+func ConvertCanaryStatusToV1Alpha(
+	canaryStatus *update.CanaryStatus,
+) *stateless.CanaryStatus {
+	if canaryStatus == nil {
+		return nil
+	}
+
+	return &stateless.CanaryStatus{
+		State:         stateless.CanaryState(canaryStatus.GetState()),
+		Instances:     canaryStatus.GetInstances(),
+		BakeStartTime: canaryStatus.GetBakeStartTime(),
 	}
 }
 
@@ -554,6 +718,7 @@ func ConvertUpdateModelToWorkflowInfo(
 			MaxTolerableInstanceFailures: updateInfo.GetUpdateConfig().GetMaxFailureInstances(),
 			StartPaused:                  updateInfo.GetUpdateConfig().GetStartPaused(),
 			InPlace:                      updateInfo.GetUpdateConfig().GetInPlace(),
+			Canary:                       convertCanaryConfigToCanarySpec(updateInfo.GetUpdateConfig().GetCanary()),
 		}
 	} else if updateInfo.GetType() == models.WorkflowType_RESTART {
 		result.RestartSpec = &stateless.RestartSpec{
@@ -703,17 +868,9 @@ func ConvertJobSpecToJobConfig(spec *stateless.JobSpec) (*job.JobConfig, error)
 }
 
 // ConvertPodSpecToTaskConfig converts a pod spec to task config
+// The first container of the pod is the primary container of the task,
+// the other containers are sidecar containers of the task.
 func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
-	if len(spec.GetContainers()) > 1 {
-		return nil,
-			yarpcerrors.UnimplementedErrorf("configuration of more than one container per pod is not supported")
-	}
-
-	if len(spec.GetInitContainers()) > 0 {
-		return nil,
-			yarpcerrors.UnimplementedErrorf("init containers are not supported")
-	}
-
 	result := &task.TaskConfig{
 		Controller:             spec.GetController(),
 		KillGracePeriodSeconds: spec.GetKillGracePeriodSeconds(),
@@ -724,7 +881,7 @@ func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
 	if len(spec.GetContainers()) > 0 {
 		mainContainer = spec.GetContainers()[0]
 		result.Container = mainContainer.GetContainer()
-		result.Command = mainContainer.GetCommand()
+		result.Command = convertContainerCommand(mainContainer)
 		result.Executor = mainContainer.GetExecutor()
 	}
 
@@ -740,54 +897,25 @@ func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
 		result.Labels = labels
 	}
 
-	if mainContainer.GetResource() != nil {
-		result.Resource = &task.ResourceConfig{
-			CpuLimit:    mainContainer.GetResource().GetCpuLimit(),
-			MemLimitMb:  mainContainer.GetResource().GetMemLimitMb(),
-			DiskLimitMb: mainContainer.GetResource().GetDiskLimitMb(),
-			FdLimit:     mainContainer.GetResource().GetFdLimit(),
-			GpuLimit:    mainContainer.GetResource().GetGpuLimit(),
-		}
-	}
+	result.Resource = convertResourceSpecToResourceConfig(mainContainer.GetResource())
+	result.HealthCheck = convertHealthCheckSpecToHealthCheckConfig(mainContainer.GetLivenessCheck())
+	result.ReadinessCheck = convertHealthCheckSpecToHealthCheckConfig(mainContainer.GetReadinessCheck())
+	result.Ports = convertPortSpecsToPortConfigs(mainContainer.GetPorts())
 
-	if mainContainer.GetLivenessCheck() != nil {
-		healthCheck := &task.HealthCheckConfig{
-			Enabled:                mainContainer.GetLivenessCheck().GetEnabled(),
-			InitialIntervalSecs:    mainContainer.GetLivenessCheck().GetInitialIntervalSecs(),
-			IntervalSecs:           mainContainer.GetLivenessCheck().GetIntervalSecs(),
-			MaxConsecutiveFailures: mainContainer.GetLivenessCheck().GetMaxConsecutiveFailures(),
-			TimeoutSecs:            mainContainer.GetLivenessCheck().GetTimeoutSecs(),
-			Type:                   task.HealthCheckConfig_Type(mainContainer.GetLivenessCheck().GetType()),
-		}
-
-		if mainContainer.GetLivenessCheck().GetCommandCheck() != nil {
-			healthCheck.CommandCheck = &task.HealthCheckConfig_CommandCheck{
-				Command:             mainContainer.GetLivenessCheck().GetCommandCheck().GetCommand(),
-				UnshareEnvironments: mainContainer.GetLivenessCheck().GetCommandCheck().GetUnshareEnvironments(),
-			}
-		}
-
-		if mainContainer.GetLivenessCheck().GetHttpCheck() != nil {
-			healthCheck.HttpCheck = &task.HealthCheckConfig_HTTPCheck{
-				Scheme: mainContainer.GetLivenessCheck().GetHttpCheck().GetScheme(),
-				Port:   mainContainer.GetLivenessCheck().GetHttpCheck().GetPort(),
-				Path:   mainContainer.GetLivenessCheck().GetHttpCheck().GetPath(),
-			}
+	if len(spec.GetContainers()) > 1 {
+		for _, sidecar := range spec.GetContainers()[1:] {
+			result.Sidecars = append(
+				result.Sidecars,
+				convertContainerSpecToContainerConfig(sidecar),
+			)
 		}
-
-		result.HealthCheck = healthCheck
 	}
 
-	if len(mainContainer.GetPorts()) != 0 {
-		var portConfigs []*task.PortConfig
-		for _, port := range mainContainer.GetPorts() {
-			portConfigs = append(portConfigs, &task.PortConfig{
-				Name:    port.GetName(),
-				Value:   port.GetValue(),
-				EnvName: port.GetEnvName(),
-			})
-		}
-		result.Ports = portConfigs
+	for _, initContainer := range spec.GetInitContainers() {
+		result.InitContainers = append(
+			result.InitContainers,
+			convertContainerSpecToContainerConfig(initContainer),
+		)
 	}
 
 	if spec.GetConstraint() != nil {
@@ -823,6 +951,127 @@ func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
 	return result, nil
 }
 
+// convertContainerSpecToContainerConfig converts the v1alpha pod.ContainerSpec
+// of a sidecar or init container to v0 task.ContainerConfig
+func convertContainerSpecToContainerConfig(
+	container *pod.ContainerSpec,
+) *task.ContainerConfig {
+	return &task.ContainerConfig{
+		Name:           container.GetName(),
+		Resource:       convertResourceSpecToResourceConfig(container.GetResource()),
+		Container:      container.GetContainer(),
+		Command:        convertContainerCommand(container),
+		HealthCheck:    convertHealthCheckSpecToHealthCheckConfig(container.GetLivenessCheck()),
+		ReadinessCheck: convertHealthCheckSpecToHealthCheckConfig(container.GetReadinessCheck()),
+		Ports:          convertPortSpecsToPortConfigs(container.GetPorts()),
+	}
+}
+
+// convertContainerCommand returns the command of a container,
+// including the environment variables of the container spec
+func convertContainerCommand(container *pod.ContainerSpec) *mesos.CommandInfo {
+	command := container.GetCommand()
+	if command == nil || len(container.GetEnvironment()) == 0 {
+		return command
+	}
+
+	command = proto.Clone(command).(*mesos.CommandInfo)
+	if command.Environment == nil {
+		command.Environment = &mesos.Environment{}
+	}
+	for _, env := range container.GetEnvironment() {
+		name := env.GetName()
+		value := env.GetValue()
+		command.Environment.Variables = append(
+			command.Environment.Variables,
+			&mesos.Environment_Variable{Name: &name, Value: &value},
+		)
+	}
+	return command
+}
+
+// convertResourceSpecToResourceConfig converts v1alpha pod.ResourceSpec
+// to v0 task.ResourceConfig
+func convertResourceSpecToResourceConfig(
+	resource *pod.ResourceSpec,
+) *task.ResourceConfig {
+	if resource == nil {
+		return nil
+	}
+
+	return &task.ResourceConfig{
+		CpuLimit:    resource.GetCpuLimit(),
+		MemLimitMb:  resource.GetMemLimitMb(),
+		DiskLimitMb: resource.GetDiskLimitMb(),
+		FdLimit:     resource.GetFdLimit(),
+		GpuLimit:    resource.GetGpuLimit(),
+	}
+}
+
+// convertHealthCheckSpecToHealthCheckConfig converts v1alpha
+// pod.HealthCheckSpec to v0 task.HealthCheckConfig
+func convertHealthCheckSpecToHealthCheckConfig(
+	healthCheck *pod.HealthCheckSpec,
+) *task.HealthCheckConfig {
+	if healthCheck == nil {
+		return nil
+	}
+
+	result := &task.HealthCheckConfig{
+		Enabled:                healthCheck.GetEnabled(),
+		InitialIntervalSecs:    healthCheck.GetInitialIntervalSecs(),
+		IntervalSecs:           healthCheck.GetIntervalSecs(),
+		MaxConsecutiveFailures: healthCheck.GetMaxConsecutiveFailures(),
+		TimeoutSecs:            healthCheck.GetTimeoutSecs(),
+		Type:                   task.HealthCheckConfig_Type(healthCheck.GetType()),
+	}
+
+	if healthCheck.GetCommandCheck() != nil {
+		result.CommandCheck = &task.HealthCheckConfig_CommandCheck{
+			Command:             healthCheck.GetCommandCheck().GetCommand(),
+			UnshareEnvironments: healthCheck.GetCommandCheck().GetUnshareEnvironments(),
+		}
+	}
+
+	if healthCheck.GetHttpCheck() != nil {
+		result.HttpCheck = &task.HealthCheckConfig_HTTPCheck{
+			Scheme: healthCheck.GetHttpCheck().GetScheme(),
+			Port:   healthCheck.GetHttpCheck().GetPort(),
+			Path:   healthCheck.GetHttpCheck().GetPath(),
+		}
+	}
+
+	if healthCheck.GetGrpcCheck() != nil {
+		result.GrpcCheck = &task.HealthCheckConfig_GRPCCheck{
+			Port:      healthCheck.GetGrpcCheck().GetPort(),
+			Service:   healthCheck.GetGrpcCheck().GetService(),
+			ProbePath: healthCheck.GetGrpcCheck().GetProbePath(),
+		}
+	}
+
+	if healthCheck.GetTcpCheck() != nil {
+		result.TcpCheck = &task.HealthCheckConfig_TCPCheck{
+			Port: healthCheck.GetTcpCheck().GetPort(),
+		}
+	}
+
+	return result
+}
+
+// convertPortSpecsToPortConfigs converts v1alpha pod.PortSpec array
+// to v0 task.PortConfig array
+func convertPortSpecsToPortConfigs(ports []*pod.PortSpec) []*task.PortConfig {
+	var portConfigs []*task.PortConfig
+	for _, port := range ports {
+		portConfigs = append(portConfigs, &task.PortConfig{
+			Name:    port.GetName(),
+			Value:   port.GetValue(),
+			EnvName: port.GetEnvName(),
+		})
+	}
+	return portConfigs
+}
+
 // ConvertPodConstraintsToTaskConstraints converts pod constraints to task constraints
 func ConvertPodConstraintsToTaskConstraints(
 	constraints []*pod.Constraint,
@@ -882,6 +1131,39 @@ func ConvertUpdateSpecToUpdateConfig(spec *stateless.UpdateSpec) *update.UpdateC
 		StartPaused:         spec.GetStartPaused(),
 		InPlace:             spec.GetInPlace(),
 		StartTasks:          spec.GetStartPods(),
+		Canary:              convertCanarySpecToCanaryConfig(spec.GetCanary()),
+	}
+}
+
+// convertCanarySpecToCanaryConfig converts v1alpha canary spec
+// to v0 canary config
+func convertCanarySpecToCanaryConfig(
+	spec *stateless.CanarySpec,
+) *update.CanaryConfig {
+	if spec == nil {
+		return nil
+	}
+
+	return &update.CanaryConfig{
+		InstanceCount:       spec.GetInstanceCount(),
+		BakeDurationSecs:    spec.GetBakeDurationSeconds(),
+		RequiredHealthState: task.HealthState(spec.GetRequiredHealthState()),
+	}
+}
+
+// convertCanaryConfigToCanarySpec converts v0 canary config
+// to v1alpha canary spec
+func convertCanaryConfigToCanarySpec(
+	config *update.CanaryConfig,
+) *stateless.CanarySpec {
+	if config == nil {
+		return nil
+	}
+
+	return &stateless.CanarySpec{
+		InstanceCount:       config.GetInstanceCount(),
+		BakeDurationSeconds: config.GetBakeDurationSecs(),
+		RequiredHealthState: pod.HealthState(config.GetRequiredHealthState()),
 	}
 }
 
@@ -155,6 +155,7 @@ func (suite *apiConverterTestSuite) TestConvertTaskRuntimeToPodStatus() {
 		},
 		ResourceUsage: resourceUsage,
 		Healthy:       task.HealthState_HEALTHY,
+		Readiness:     task.HealthState_HEALTHY,
 		DesiredMesosTaskId: &mesos.TaskID{
 			Value: &testMesosTaskID,
 		},
@@ -178,6 +179,9 @@ func (suite *apiConverterTestSuite) TestConvertTaskRuntimeToPodStatus() {
 				Healthy: &pod.HealthStatus{
 					State: pod.HealthState_HEALTH_STATE_HEALTHY,
 				},
+				Readiness: &pod.HealthStatus{
+					State: pod.HealthState_HEALTH_STATE_HEALTHY,
+				},
 				Message:   message,
 				Reason:    reason,
 				StartTime: startTime,
@@ -213,6 +217,7 @@ func (suite *apiConverterTestSuite) TestConvertTaskRuntimeToPodStatus() {
 		DesiredPodId: &v1alphapeloton.PodID{
 			Value: testMesosTaskID,
 		},
+		Ready: true,
 	}
 
 	suite.Equal(podStatus, ConvertTaskRuntimeToPodStatus(taskRuntime))
@@ -267,6 +272,14 @@ func (suite *apiConverterTestSuite) TestConvertTaskConfigToPodSpecAndViceVersa()
 				Path:   "/health",
 			},
 		},
+		ReadinessCheck: &task.HealthCheckConfig{
+			Enabled: true,
+			Type:    task.HealthCheckConfig_HTTP,
+			HttpCheck: &task.HealthCheckConfig_HTTPCheck{
+				Port: uint32(100),
+				Path: "/ready",
+			},
+		},
 		Ports: []*task.PortConfig{
 			{
 				Name:  portName,
@@ -329,6 +342,14 @@ func (suite *apiConverterTestSuite) TestConvertTaskConfigToPodSpecAndViceVersa()
 						Path:   taskConfig.GetHealthCheck().GetHttpCheck().GetPath(),
 					},
 				},
+				ReadinessCheck: &pod.HealthCheckSpec{
+					Enabled: true,
+					Type:    pod.HealthCheckSpec_HEALTH_CHECK_TYPE_HTTP,
+					HttpCheck: &pod.HealthCheckSpec_HTTPCheck{
+						Port: taskConfig.GetReadinessCheck().GetHttpCheck().GetPort(),
+						Path: taskConfig.GetReadinessCheck().GetHttpCheck().GetPath(),
+					},
+				},
 				Ports: []*pod.PortSpec{
 					{
 						Name:    taskConfig.GetPorts()[0].GetName(),
@@ -439,6 +460,157 @@ func (suite *apiConverterTestSuite) TestConvertPodSpecToTaskConfigNoContainers()
 	)
 }
 
+// TestConvertPodSpecWithMultipleContainers tests the conversion of a pod
+// spec with sidecar and init containers to task config and vice versa
+func (suite *apiConverterTestSuite) TestConvertPodSpecWithMultipleContainers() {
+	newContainer := func(name string) *pod.ContainerSpec {
+		return &pod.ContainerSpec{
+			Name: name,
+			Resource: &pod.ResourceSpec{
+				CpuLimit:   0.5,
+				MemLimitMb: 128,
+			},
+			Command: &mesos.CommandInfo{Value: util.PtrPrintf("./" + name)},
+			Ports: []*pod.PortSpec{
+				{Name: name, EnvName: "PORT_" + name},
+			},
+		}
+	}
+
+	app := newContainer("app")
+	app.LivenessCheck = &pod.HealthCheckSpec{
+		Enabled: true,
+		Type:    pod.HealthCheckSpec_HEALTH_CHECK_TYPE_GRPC,
+		GrpcCheck: &pod.HealthCheckSpec_GRPCCheck{
+			Port:      8081,
+			Service:   "app.Service",
+			ProbePath: "/bin/grpc_health_probe",
+		},
+	}
+	proxy := newContainer("proxy")
+	proxy.LivenessCheck = &pod.HealthCheckSpec{
+		Enabled: true,
+		Type:    pod.HealthCheckSpec_HEALTH_CHECK_TYPE_HTTP,
+		HttpCheck: &pod.HealthCheckSpec_HTTPCheck{
+			Scheme: "http",
+			Port:   8080,
+			Path:   "/health",
+		},
+	}
+	proxy.ReadinessCheck = &pod.HealthCheckSpec{
+		Enabled: true,
+		Type:    pod.HealthCheckSpec_HEALTH_CHECK_TYPE_TCP,
+		TcpCheck: &pod.HealthCheckSpec_TCPCheck{
+			Port: 8080,
+		},
+	}
+	setup := newContainer("setup")
+	podSpec := &pod.PodSpec{
+		Containers:     []*pod.ContainerSpec{app, proxy},
+		InitContainers: []*pod.ContainerSpec{setup},
+	}
+
+	taskConfig, err := ConvertPodSpecToTaskConfig(podSpec)
+	suite.NoError(err)
+	suite.Equal("app", taskConfig.GetName())
+	suite.Equal(task.HealthCheckConfig_GRPC, taskConfig.GetHealthCheck().GetType())
+	suite.Equal(uint32(8081), taskConfig.GetHealthCheck().GetGrpcCheck().GetPort())
+	suite.Equal("app.Service", taskConfig.GetHealthCheck().GetGrpcCheck().GetService())
+	suite.Equal(
+		"/bin/grpc_health_probe",
+		taskConfig.GetHealthCheck().GetGrpcCheck().GetProbePath())
+	suite.Len(taskConfig.GetSidecars(), 1)
+	suite.Equal("proxy", taskConfig.GetSidecars()[0].GetName())
+	suite.Equal(
+		task.HealthCheckConfig_HTTP,
+		taskConfig.GetSidecars()[0].GetHealthCheck().GetType())
+	suite.Equal(
+		uint32(8080),
+		taskConfig.GetSidecars()[0].GetReadinessCheck().GetTcpCheck().GetPort())
+	suite.Len(taskConfig.GetInitContainers(), 1)
+	suite.Equal("setup", taskConfig.GetInitContainers()[0].GetName())
+	suite.Equal(
+		"PORT_setup",
+		taskConfig.GetInitContainers()[0].GetPorts()[0].GetEnvName())
+
+	suite.Equal(podSpec, ConvertTaskConfigToPodSpec(taskConfig, "", 0))
+}
+
+// TestConvertPodSpecContainerEnvironment tests that the environment
+// variables of a container are passed on to its command
+func (suite *apiConverterTestSuite) TestConvertPodSpecContainerEnvironment() {
+	command := &mesos.CommandInfo{Value: util.PtrPrintf("./app")}
+	podSpec := &pod.PodSpec{
+		Containers: []*pod.ContainerSpec{
+			{
+				Name:    "app",
+				Command: command,
+				Environment: []*pod.Environment{
+					{Name: "ENV", Value: "production"},
+				},
+			},
+		},
+	}
+
+	taskConfig, err := ConvertPodSpecToTaskConfig(podSpec)
+	suite.NoError(err)
+	variables := taskConfig.GetCommand().GetEnvironment().GetVariables()
+	suite.Len(variables, 1)
+	suite.Equal("ENV", variables[0].GetName())
+	suite.Equal("production", variables[0].GetValue())
+
+	// the command of the pod spec is not modified
+	suite.Nil(command.GetEnvironment())
+}
+
+// TestConvertTaskRuntimeWithContainersToPodStatus tests that the runtime
+// of the sidecar and init containers is converted to container status
+func (suite *apiConverterTestSuite) TestConvertTaskRuntimeWithContainersToPodStatus() {
+	runtime := &task.RuntimeInfo{
+		State:       task.TaskState_RUNNING,
+		MesosTaskId: &mesos.TaskID{Value: &testMesosTaskID},
+		Containers: []*task.ContainerRuntimeInfo{
+			{
+				Name:      "proxy",
+				State:     task.TaskState_RUNNING,
+				Ports:     map[string]uint32{"proxy": 31000},
+				Healthy:   task.HealthState_HEALTHY,
+				Readiness: task.HealthState_UNHEALTHY,
+				StartTime: "2019-01-01T00:00:00Z",
+			},
+			{
+				Name:  "setup",
+				State: task.TaskState_FAILED,
+				TerminationStatus: &task.TerminationStatus{
+					Reason:   task.TerminationStatus_TERMINATION_STATUS_REASON_FAILED,
+					ExitCode: 1,
+				},
+			},
+		},
+	}
+
+	status := ConvertTaskRuntimeToPodStatus(runtime)
+	suite.Len(status.GetContainersStatus(), 3)
+
+	proxy := status.GetContainersStatus()[1]
+	suite.Equal("proxy", proxy.GetName())
+	suite.Equal(pod.ContainerState_CONTAINER_STATE_RUNNING, proxy.GetState())
+	suite.Equal(uint32(31000), proxy.GetPorts()["proxy"])
+	suite.Equal(pod.HealthState_HEALTH_STATE_HEALTHY, proxy.GetHealthy().GetState())
+	suite.Equal(pod.HealthState_HEALTH_STATE_UNHEALTHY, proxy.GetReadiness().GetState())
+	suite.Equal("2019-01-01T00:00:00Z", proxy.GetStartTime())
+	// the pod is not ready as long as one of its containers is not ready
+	suite.False(status.GetReady())
+
+	setup := status.GetContainersStatus()[2]
+	suite.Equal("setup", setup.GetName())
+	suite.Equal(pod.ContainerState_CONTAINER_STATE_FAILED, setup.GetState())
+	suite.Equal(
+		pod.TerminationStatus_TERMINATION_STATUS_REASON_FAILED,
+		setup.GetTerminationStatus().GetReason())
+	suite.Equal(uint32(1), setup.GetTerminationStatus().GetExitCode())
+}
+
 // TestConvertLabels tests conversion from v0 peloton.Label
 // array to v1alpha peloton.Label array
 func (suite *apiConverterTestSuite) TestConvertLabels() {
@@ -1354,6 +1526,47 @@ func (suite *apiConverterTestSuite) TestConvertUpdateSpecToUpdateConfig() {
 	suite.Equal(spec.GetMaxInstanceRetries(), config.GetMaxInstanceAttempts())
 	suite.Equal(spec.GetMaxTolerableInstanceFailures(), config.GetMaxFailureInstances())
 	suite.Equal(spec.GetStartPaused(), config.GetStartPaused())
+	suite.Nil(config.GetCanary())
+}
+
+// TestConvertUpdateSpecWithCanary tests conversion of the canary
+// configuration and status of an update between v1alpha and v0
+func (suite *apiConverterTestSuite) TestConvertUpdateSpecWithCanary() {
+	spec := &stateless.UpdateSpec{
+		BatchSize: 10,
+		Canary: &stateless.CanarySpec{
+			InstanceCount:       2,
+			BakeDurationSeconds: 300,
+			RequiredHealthState: pod.HealthState_HEALTH_STATE_HEALTHY,
+		},
+	}
+
+	config := ConvertUpdateSpecToUpdateConfig(spec)
+	suite.Equal(&update.CanaryConfig{
+		InstanceCount:       2,
+		BakeDurationSecs:    300,
+		RequiredHealthState: task.HealthState_HEALTHY,
+	}, config.GetCanary())
+
+	updateModel := &models.UpdateModel{
+		Type:         models.WorkflowType_UPDATE,
+		State:        update.State_ROLLING_FORWARD,
+		UpdateConfig: config,
+		CanaryStatus: &update.CanaryStatus{
+			State:         update.CanaryState_CANARY_STATE_BAKING,
+			Instances:     []uint32{0, 1},
+			BakeStartTime: "2019-01-30T21:25:23Z",
+		},
+	}
+
+	workflowInfo := ConvertUpdateModelToWorkflowInfo(
+		&job.RuntimeInfo{}, updateModel, nil, nil)
+	suite.Equal(spec.GetCanary(), workflowInfo.GetUpdateSpec().GetCanary())
+	suite.Equal(&stateless.CanaryStatus{
+		State:         stateless.CanaryState_CANARY_STATE_BAKING,
+		Instances:     []uint32{0, 1},
+		BakeStartTime: "2019-01-30T21:25:23Z",
+	}, workflowInfo.GetStatus().GetCanary())
 }
 
 // TestConvertInstanceIDListToInstanceRange tests conversion from
@@ -0,0 +1,63 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/storage"
+)
+
+// JobEntity returns the auth entity of the job, which is
+// owned by the owner, owning team and LDAP groups of the job
+func JobEntity(config *job.JobConfig) *auth.Entity {
+	return &auth.Entity{
+		Owner:      config.GetOwner(),
+		OwningTeam: config.GetOwningTeam(),
+		LdapGroups: config.GetLdapGroups(),
+	}
+}
+
+// ResPoolEntity returns the auth entity of the resource pool, which is
+// owned by the owning team and LDAP groups of the resource pool
+func ResPoolEntity(config *respool.ResourcePoolConfig) *auth.Entity {
+	return &auth.Entity{
+		OwningTeam: config.GetOwningTeam(),
+		LdapGroups: config.GetLdapGroups(),
+	}
+}
+
+// CheckJobPermission returns a permission denied error if the user
+// which called the API is not permitted to call it on the job.
+// The job config is read from the DB only for the authenticated calls.
+func CheckJobPermission(
+	ctx context.Context,
+	id *peloton.JobID,
+	store storage.JobStore) error {
+	if !auth.HasUser(ctx) {
+		return nil
+	}
+
+	jobConfig, _, err := store.GetJobConfig(ctx, id.GetValue())
+	if err != nil {
+		return err
+	}
+	return auth.CheckEntityPermission(ctx, JobEntity(jobConfig))
+}
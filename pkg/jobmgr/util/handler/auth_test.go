@@ -0,0 +1,72 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
+	storemocks "github.com/uber/peloton/pkg/storage/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+func TestCheckJobPermission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jobStore := storemocks.NewMockJobStore(ctrl)
+	user := authmocks.NewMockUser(ctrl)
+	jobID := &peloton.JobID{Value: uuid.New()}
+	config := &job.JobConfig{
+		Owner:      "user1",
+		OwningTeam: "team1",
+		LdapGroups: []string{"group1"},
+	}
+	procedure := "peloton.api.v0.job.JobManager::Delete"
+
+	// the job config is not read without a user
+	assert.NoError(t, CheckJobPermission(context.Background(), jobID, jobStore))
+
+	ctx := auth.WithUser(context.Background(), user, procedure)
+	jobStore.EXPECT().
+		GetJobConfig(gomock.Any(), jobID.GetValue()).
+		Return(config, nil, nil).
+		Times(2)
+	user.EXPECT().
+		IsPermittedOnEntity(procedure, JobEntity(config)).
+		Return(true)
+	assert.NoError(t, CheckJobPermission(ctx, jobID, jobStore))
+
+	user.EXPECT().
+		IsPermittedOnEntity(procedure, JobEntity(config)).
+		Return(false)
+	err := CheckJobPermission(ctx, jobID, jobStore)
+	assert.True(t, yarpcerrors.IsPermissionDenied(err))
+
+	jobStore.EXPECT().
+		GetJobConfig(gomock.Any(), jobID.GetValue()).
+		Return(nil, nil, fmt.Errorf("test error"))
+	assert.Error(t, CheckJobPermission(ctx, jobID, jobStore))
+}
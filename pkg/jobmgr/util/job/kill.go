@@ -0,0 +1,62 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+)
+
+// KillJob sets the goal state of a job to KILLED with the message,
+// retrying on concurrent updates of the job runtime. It returns false
+// if the goal state of the job was already KILLED. The caller needs to
+// enqueue the job into the goal state engine once its goal state is set.
+func KillJob(
+	ctx context.Context,
+	cachedJob cached.Job,
+	message string,
+) (bool, error) {
+	count := 0
+	for {
+		jobRuntime, err := cachedJob.GetRuntime(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		if jobRuntime.GetGoalState() == pbjob.JobState_KILLED {
+			return false, nil
+		}
+
+		jobRuntime.DesiredStateVersion++
+		jobRuntime.GoalState = pbjob.JobState_KILLED
+		jobRuntime.Message = message
+
+		if _, err = cachedJob.CompareAndSetRuntime(ctx, jobRuntime); err != nil {
+			if err == jobmgrcommon.UnexpectedVersionError {
+				// concurrency error; retry MaxConcurrencyErrorRetry times
+				count = count + 1
+				if count < jobmgrcommon.MaxConcurrencyErrorRetry {
+					continue
+				}
+			}
+			return false, err
+		}
+		return true, nil
+	}
+}
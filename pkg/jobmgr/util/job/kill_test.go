@@ -0,0 +1,92 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestKillJob tests setting the goal state of a job to KILLED
+func TestKillJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cachedJob := cachedmocks.NewMockJob(ctrl)
+	cachedJob.EXPECT().GetRuntime(gomock.Any()).Return(&pbjob.RuntimeInfo{
+		State:     pbjob.JobState_RUNNING,
+		GoalState: pbjob.JobState_SUCCEEDED,
+	}, nil)
+	cachedJob.EXPECT().
+		CompareAndSetRuntime(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, runtime *pbjob.RuntimeInfo) {
+			assert.Equal(t, pbjob.JobState_KILLED, runtime.GetGoalState())
+			assert.Equal(t, "test message", runtime.GetMessage())
+			assert.Equal(t, uint64(1), runtime.GetDesiredStateVersion())
+		}).
+		Return(&pbjob.RuntimeInfo{}, nil)
+
+	killed, err := KillJob(context.Background(), cachedJob, "test message")
+	assert.NoError(t, err)
+	assert.True(t, killed)
+}
+
+// TestKillJobAlreadyKilled tests killing a job whose goal state
+// is already KILLED
+func TestKillJobAlreadyKilled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cachedJob := cachedmocks.NewMockJob(ctrl)
+	cachedJob.EXPECT().GetRuntime(gomock.Any()).Return(&pbjob.RuntimeInfo{
+		GoalState: pbjob.JobState_KILLED,
+	}, nil)
+
+	killed, err := KillJob(context.Background(), cachedJob, "test message")
+	assert.NoError(t, err)
+	assert.False(t, killed)
+}
+
+// TestKillJobConcurrencyError tests retrying on concurrent
+// updates of the job runtime
+func TestKillJobConcurrencyError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cachedJob := cachedmocks.NewMockJob(ctrl)
+	cachedJob.EXPECT().GetRuntime(gomock.Any()).Return(&pbjob.RuntimeInfo{
+		GoalState: pbjob.JobState_SUCCEEDED,
+	}, nil).Times(jobmgrcommon.MaxConcurrencyErrorRetry)
+	cachedJob.EXPECT().
+		CompareAndSetRuntime(gomock.Any(), gomock.Any()).
+		Return(nil, jobmgrcommon.UnexpectedVersionError).
+		Times(jobmgrcommon.MaxConcurrencyErrorRetry)
+
+	_, err := KillJob(context.Background(), cachedJob, "test message")
+	assert.Equal(t, jobmgrcommon.UnexpectedVersionError, err)
+
+	cachedJob.EXPECT().GetRuntime(gomock.Any()).Return(nil, errors.New("test error"))
+	_, err = KillJob(context.Background(), cachedJob, "test message")
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	taskutil "github.com/uber/peloton/pkg/jobmgr/util/task"
+)
+
+// IsWithinUnavailableInstances returns true if another instance of the job
+// can be made unavailable without exceeding the maximum unavailable
+// instances of the job SLA. Jobs without that limit always return true.
+func IsWithinUnavailableInstances(
+	ctx context.Context,
+	cachedJob cached.Job,
+) (bool, error) {
+	config, err := cachedJob.GetConfig(ctx)
+	if err != nil {
+		return false, err
+	}
+	maxUnavailable := config.GetSLA().GetMaximumUnavailableInstances()
+	if maxUnavailable == 0 {
+		return true, nil
+	}
+
+	var unavailable uint32
+	for _, cachedTask := range cachedJob.GetAllTasks() {
+		runtime, err := cachedTask.GetRuntime(ctx)
+		if err != nil {
+			return false, err
+		}
+		if IsTaskUnavailable(runtime) {
+			unavailable++
+		}
+	}
+	return unavailable < maxUnavailable, nil
+}
+
+// IsTaskUnavailable returns true if the task is supposed to be running
+// but is not running or not ready, or is being killed or restarted
+func IsTaskUnavailable(runtime *pbtask.RuntimeInfo) bool {
+	if util.IsPelotonStateTerminal(runtime.GetState()) &&
+		util.IsPelotonStateTerminal(runtime.GetGoalState()) {
+		// the task is done or stopped on purpose
+		return false
+	}
+	if !taskutil.IsTaskReady(runtime) {
+		return true
+	}
+	if util.IsPelotonStateTerminal(runtime.GetGoalState()) ||
+		runtime.GetGoalState() == pbtask.TaskState_PREEMPTING {
+		return true
+	}
+	return runtime.GetDesiredMesosTaskId() != nil &&
+		runtime.GetDesiredMesosTaskId().GetValue() !=
+			runtime.GetMesosTaskId().GetValue()
+}
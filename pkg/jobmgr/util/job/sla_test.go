@@ -0,0 +1,154 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIsWithinUnavailableInstances tests the maximum unavailable
+// instances of the job SLA
+func TestIsWithinUnavailableInstances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cachedJob := cachedmocks.NewMockJob(ctrl)
+	available := cachedmocks.NewMockTask(ctrl)
+	unavailable := cachedmocks.NewMockTask(ctrl)
+	available.EXPECT().GetRuntime(gomock.Any()).Return(&pbtask.RuntimeInfo{
+		State:     pbtask.TaskState_RUNNING,
+		GoalState: pbtask.TaskState_RUNNING,
+	}, nil).AnyTimes()
+	unavailable.EXPECT().GetRuntime(gomock.Any()).Return(&pbtask.RuntimeInfo{
+		State:     pbtask.TaskState_PENDING,
+		GoalState: pbtask.TaskState_RUNNING,
+	}, nil).AnyTimes()
+	cachedJob.EXPECT().GetAllTasks().Return(map[uint32]cached.Task{
+		0: available,
+		1: unavailable,
+	}).AnyTimes()
+
+	tests := []struct {
+		maxUnavailable uint32
+		within         bool
+	}{
+		{maxUnavailable: 0, within: true},
+		{maxUnavailable: 1, within: false},
+		{maxUnavailable: 2, within: true},
+	}
+	for _, test := range tests {
+		cachedJob.EXPECT().GetConfig(gomock.Any()).Return(&pbjob.JobConfig{
+			SLA: &pbjob.SlaConfig{MaximumUnavailableInstances: test.maxUnavailable},
+		}, nil)
+		within, err := IsWithinUnavailableInstances(context.Background(), cachedJob)
+		assert.NoError(t, err)
+		assert.Equal(t, test.within, within)
+	}
+
+	cachedJob.EXPECT().GetConfig(gomock.Any()).Return(nil, errors.New("test error"))
+	_, err := IsWithinUnavailableInstances(context.Background(), cachedJob)
+	assert.Error(t, err)
+}
+
+// TestIsTaskUnavailable tests which task runtimes count as unavailable
+// instances of a job
+func TestIsTaskUnavailable(t *testing.T) {
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	tests := []struct {
+		runtime     *pbtask.RuntimeInfo
+		unavailable bool
+	}{
+		{
+			runtime: &pbtask.RuntimeInfo{
+				State:     pbtask.TaskState_RUNNING,
+				GoalState: pbtask.TaskState_RUNNING,
+			},
+			unavailable: false,
+		},
+		{
+			runtime: &pbtask.RuntimeInfo{
+				State:     pbtask.TaskState_SUCCEEDED,
+				GoalState: pbtask.TaskState_SUCCEEDED,
+			},
+			unavailable: false,
+		},
+		{
+			runtime: &pbtask.RuntimeInfo{
+				State:     pbtask.TaskState_PENDING,
+				GoalState: pbtask.TaskState_RUNNING,
+			},
+			unavailable: true,
+		},
+		{
+			runtime: &pbtask.RuntimeInfo{
+				State:     pbtask.TaskState_RUNNING,
+				GoalState: pbtask.TaskState_PREEMPTING,
+			},
+			unavailable: true,
+		},
+		{
+			runtime: &pbtask.RuntimeInfo{
+				State:     pbtask.TaskState_RUNNING,
+				GoalState: pbtask.TaskState_RUNNING,
+				Readiness: pbtask.HealthState_UNHEALTHY,
+			},
+			unavailable: true,
+		},
+		{
+			runtime: &pbtask.RuntimeInfo{
+				State:     pbtask.TaskState_RUNNING,
+				GoalState: pbtask.TaskState_RUNNING,
+				Readiness: pbtask.HealthState_HEALTHY,
+			},
+			unavailable: false,
+		},
+		{
+			runtime: &pbtask.RuntimeInfo{
+				State:              pbtask.TaskState_RUNNING,
+				GoalState:          pbtask.TaskState_RUNNING,
+				MesosTaskId:        util.CreateMesosTaskID(jobID, 0, 1),
+				DesiredMesosTaskId: util.CreateMesosTaskID(jobID, 0, 1),
+			},
+			unavailable: false,
+		},
+		{
+			runtime: &pbtask.RuntimeInfo{
+				State:              pbtask.TaskState_RUNNING,
+				GoalState:          pbtask.TaskState_RUNNING,
+				MesosTaskId:        util.CreateMesosTaskID(jobID, 0, 1),
+				DesiredMesosTaskId: util.CreateMesosTaskID(jobID, 0, 2),
+			},
+			unavailable: true,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.unavailable, IsTaskUnavailable(test.runtime))
+	}
+}
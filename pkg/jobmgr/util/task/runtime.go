@@ -46,6 +46,40 @@ func GetInitialHealthState(taskConfig *task.TaskConfig) task.HealthState {
 	return task.HealthState_DISABLED
 }
 
+// GetInitialReadinessState returns the readiness state of a task or
+// container being launched, which is HEALTH_UNKNOWN or DISABLED
+// depending on whether the readiness check is enabled or not
+func GetInitialReadinessState(
+	readinessCheck *task.HealthCheckConfig) task.HealthState {
+	if readinessCheck.GetEnabled() {
+		return task.HealthState_HEALTH_UNKNOWN
+	}
+	return task.HealthState_DISABLED
+}
+
+// IsTaskReady returns true if the task is running and neither the task nor
+// any of its sidecar containers is waiting for or failing its readiness
+// check. Tasks without readiness check are ready once running.
+func IsTaskReady(runtime *task.RuntimeInfo) bool {
+	if runtime.GetState() != task.TaskState_RUNNING ||
+		!isReadinessPassing(runtime.GetReadiness()) {
+		return false
+	}
+	for _, container := range runtime.GetContainers() {
+		if !isReadinessPassing(container.GetReadiness()) {
+			return false
+		}
+	}
+	return true
+}
+
+// isReadinessPassing returns false if the readiness check has not
+// succeeded yet or has failed
+func isReadinessPassing(readiness task.HealthState) bool {
+	return readiness != task.HealthState_HEALTH_UNKNOWN &&
+		readiness != task.HealthState_UNHEALTHY
+}
+
 // RegenerateMesosTaskRuntime changes the runtime to INITIALIZED state
 // with correct initial health state and a regenerated mesos task id
 // and the previous mesos task id set to the current value.
@@ -70,6 +104,8 @@ func RegenerateMesosTaskRuntime(
 	taskRuntime.TerminationStatus = nil
 	taskRuntime.Reason = ""
 	taskRuntime.Message = ""
+	taskRuntime.Containers = nil
+	taskRuntime.Readiness = task.HealthState_INVALID
 }
 
 // RegenerateMesosTaskIDDiff returns a diff for patch with the previous mesos
@@ -97,7 +133,48 @@ func RegenerateMesosTaskIDDiff(
 		jobmgrcommon.TerminationStatusField: nil,
 		jobmgrcommon.MessageField:           "",
 		jobmgrcommon.ReasonField:            "",
+		jobmgrcommon.ContainersField:        nil,
+		jobmgrcommon.ReadinessField:         task.HealthState_INVALID,
+	}
+}
+
+// CreateContainersRuntime returns the initial runtime of the sidecar and
+// init containers of a task being launched with the given mesos task id
+// and dynamic ports. It returns nil for tasks with a single container.
+func CreateContainersRuntime(
+	taskConfig *task.TaskConfig,
+	mesosTaskID *mesos.TaskID,
+	ports map[string]uint32,
+) []*task.ContainerRuntimeInfo {
+	var containers []*task.ContainerRuntimeInfo
+	for _, configs := range [][]*task.ContainerConfig{
+		taskConfig.GetSidecars(),
+		taskConfig.GetInitContainers(),
+	} {
+		for _, config := range configs {
+			container := &task.ContainerRuntimeInfo{
+				Name:  config.GetName(),
+				State: task.TaskState_LAUNCHED,
+				MesosTaskId: util.CreateContainerMesosTaskID(
+					mesosTaskID, config.GetName()),
+				Healthy:   task.HealthState_DISABLED,
+				Readiness: GetInitialReadinessState(config.GetReadinessCheck()),
+			}
+			if config.GetHealthCheck().GetEnabled() {
+				container.Healthy = task.HealthState_HEALTH_UNKNOWN
+			}
+			for _, port := range config.GetPorts() {
+				if value, ok := ports[port.GetName()]; ok {
+					if container.Ports == nil {
+						container.Ports = make(map[string]uint32)
+					}
+					container.Ports[port.GetName()] = value
+				}
+			}
+			containers = append(containers, container)
+		}
 	}
+	return containers
 }
 
 func getMesosTaskID(
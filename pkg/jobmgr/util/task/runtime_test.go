@@ -23,6 +23,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 
+	"github.com/uber/peloton/pkg/common/util"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 )
 
@@ -85,6 +86,8 @@ func TestRegenerateMesosTaskRuntime(t *testing.T) {
 		assert.Empty(t, runtime.Host)
 		assert.Empty(t, runtime.Ports)
 		assert.Empty(t, runtime.TerminationStatus)
+		assert.Empty(t, runtime.Containers)
+		assert.Equal(t, task.HealthState_INVALID, runtime.Readiness)
 	}
 }
 
@@ -150,6 +153,114 @@ func TestRegenerateMesosTaskIDDiff(t *testing.T) {
 		assert.Empty(t, diff[jobmgrcommon.HostField])
 		assert.Empty(t, diff[jobmgrcommon.PortsField])
 		assert.Empty(t, diff[jobmgrcommon.TerminationStatusField])
+		assert.Contains(t, diff, jobmgrcommon.ContainersField)
+		assert.Empty(t, diff[jobmgrcommon.ContainersField])
+		assert.Equal(t, task.HealthState_INVALID, diff[jobmgrcommon.ReadinessField])
+	}
+}
+
+// TestCreateContainersRuntime tests the initial runtime of the sidecar
+// and init containers of a task
+func TestCreateContainersRuntime(t *testing.T) {
+	mesosTaskID := util.CreateMesosTaskID(
+		&peloton.JobID{Value: "b64fd26b-0e39-41b7-b22a-205b69f247bd"}, 0, 1)
+	ports := map[string]uint32{"http": 31000, "proxy": 31001}
+
+	assert.Nil(t, CreateContainersRuntime(&task.TaskConfig{}, mesosTaskID, ports))
+
+	taskConfig := &task.TaskConfig{
+		Ports: []*task.PortConfig{{Name: "http"}},
+		Sidecars: []*task.ContainerConfig{
+			{
+				Name:           "proxy",
+				Ports:          []*task.PortConfig{{Name: "proxy"}},
+				HealthCheck:    &task.HealthCheckConfig{Enabled: true},
+				ReadinessCheck: &task.HealthCheckConfig{Enabled: true},
+			},
+		},
+		InitContainers: []*task.ContainerConfig{
+			{Name: "setup"},
+		},
+	}
+	containers := CreateContainersRuntime(taskConfig, mesosTaskID, ports)
+	assert.Len(t, containers, 2)
+
+	assert.Equal(t, "proxy", containers[0].GetName())
+	assert.Equal(t, task.TaskState_LAUNCHED, containers[0].GetState())
+	assert.Equal(t, mesosTaskID.GetValue()+".proxy", containers[0].GetMesosTaskId().GetValue())
+	assert.Equal(t, map[string]uint32{"proxy": 31001}, containers[0].GetPorts())
+	assert.Equal(t, task.HealthState_HEALTH_UNKNOWN, containers[0].GetHealthy())
+	assert.Equal(t, task.HealthState_HEALTH_UNKNOWN, containers[0].GetReadiness())
+
+	assert.Equal(t, "setup", containers[1].GetName())
+	assert.Equal(t, mesosTaskID.GetValue()+".setup", containers[1].GetMesosTaskId().GetValue())
+	assert.Empty(t, containers[1].GetPorts())
+	assert.Equal(t, task.HealthState_DISABLED, containers[1].GetHealthy())
+	assert.Equal(t, task.HealthState_DISABLED, containers[1].GetReadiness())
+}
+
+// TestIsTaskReady tests checking whether a task is running and ready
+func TestIsTaskReady(t *testing.T) {
+	testTable := []struct {
+		runtime *task.RuntimeInfo
+		ready   bool
+	}{
+		{
+			runtime: &task.RuntimeInfo{
+				State:     task.TaskState_RUNNING,
+				Readiness: task.HealthState_DISABLED,
+			},
+			ready: true,
+		},
+		{
+			runtime: &task.RuntimeInfo{
+				State:     task.TaskState_RUNNING,
+				Readiness: task.HealthState_HEALTHY,
+			},
+			ready: true,
+		},
+		{
+			runtime: &task.RuntimeInfo{
+				State:     task.TaskState_RUNNING,
+				Readiness: task.HealthState_HEALTH_UNKNOWN,
+			},
+			ready: false,
+		},
+		{
+			runtime: &task.RuntimeInfo{
+				State:     task.TaskState_RUNNING,
+				Readiness: task.HealthState_UNHEALTHY,
+			},
+			ready: false,
+		},
+		{
+			runtime: &task.RuntimeInfo{
+				State:     task.TaskState_STARTING,
+				Readiness: task.HealthState_DISABLED,
+			},
+			ready: false,
+		},
+		{
+			runtime: &task.RuntimeInfo{
+				State:     task.TaskState_RUNNING,
+				Readiness: task.HealthState_HEALTHY,
+				Containers: []*task.ContainerRuntimeInfo{
+					{Readiness: task.HealthState_HEALTH_UNKNOWN},
+				},
+			},
+			ready: false,
+		},
+		{
+			// tasks launched without readiness state are ready once running
+			runtime: &task.RuntimeInfo{
+				State: task.TaskState_RUNNING,
+			},
+			ready: true,
+		},
+	}
+
+	for _, tt := range testTable {
+		assert.Equal(t, tt.ready, IsTaskReady(tt.runtime))
 	}
 }
 
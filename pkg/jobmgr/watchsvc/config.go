@@ -14,9 +14,16 @@
 
 package watchsvc
 
+import (
+	"time"
+)
+
 const (
-	_defaultBufferSize int = 100
-	_defaultMaxClient  int = 1000
+	_defaultBufferSize        int    = 100
+	_defaultMaxClient         int    = 1000
+	_defaultEventQueueSize    int    = 10000
+	_defaultMaxHistory        uint64 = 10000
+	_defaultRevisionBlockSize uint64 = 1000
 )
 
 // Config for Watch API
@@ -26,6 +33,21 @@ type Config struct {
 
 	// Maximum number of concurrent watch clients
 	MaxClient int `yaml:"max_client"`
+
+	// Size of the queue of the events waiting to be written to
+	// the store and sent to the clients
+	EventQueueSize int `yaml:"event_queue_size"`
+
+	// Number of revisions kept in the store, which a client can
+	// resume a watch from
+	MaxHistory uint64 `yaml:"max_history"`
+
+	// Number of revisions reserved at once by the job manager leader
+	RevisionBlockSize uint64 `yaml:"revision_block_size"`
+
+	// Period at which the resource pools and the hosts are polled
+	// for changes, polling is disabled if not set
+	PollPeriod time.Duration `yaml:"poll_period"`
 }
 
 func (c *Config) normalize() {
@@ -35,4 +57,13 @@ func (c *Config) normalize() {
 	if c.MaxClient <= 0 {
 		c.MaxClient = _defaultMaxClient
 	}
+	if c.EventQueueSize <= 0 {
+		c.EventQueueSize = _defaultEventQueueSize
+	}
+	if c.MaxHistory == 0 {
+		c.MaxHistory = _defaultMaxHistory
+	}
+	if c.RevisionBlockSize == 0 {
+		c.RevisionBlockSize = _defaultRevisionBlockSize
+	}
 }
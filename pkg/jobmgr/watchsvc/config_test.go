@@ -26,4 +26,8 @@ func TestConfigNormalize(t *testing.T) {
 	c.normalize()
 	assert.True(t, c.BufferSize > 0)
 	assert.True(t, c.MaxClient > 0)
+	assert.True(t, c.EventQueueSize > 0)
+	assert.True(t, c.MaxHistory > 0)
+	assert.True(t, c.RevisionBlockSize > 0)
+	assert.Zero(t, c.PollPeriod)
 }
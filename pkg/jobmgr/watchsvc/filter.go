@@ -0,0 +1,203 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchsvc
+
+import (
+	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
+
+	"github.com/uber/peloton/pkg/common/util"
+)
+
+// hasFilter returns whether the request selects any object to watch
+func hasFilter(req *svc.WatchRequest) bool {
+	return req.GetPodFilter() != nil ||
+		req.GetStatelessJobFilter() != nil ||
+		req.GetBatchJobFilter() != nil ||
+		req.GetWorkflowFilter() != nil ||
+		req.GetResourcePoolFilter() != nil ||
+		req.GetHostFilter() != nil
+}
+
+// matchEvent returns whether the object changed by an event is selected
+// by the filters of a request. A nil filter selects no object of its
+// kind, and each event carries exactly one object.
+func matchEvent(req *svc.WatchRequest, e *event) bool {
+	resp := e.response
+
+	for _, p := range resp.GetPods() {
+		if req.GetPodFilter() == nil ||
+			!matchPod(req.GetPodFilter(), p, e.labels) {
+			return false
+		}
+	}
+
+	for _, j := range resp.GetStatelessJobs() {
+		if req.GetStatelessJobFilter() == nil ||
+			!matchJobID(req.GetStatelessJobFilter().GetJobIds(), j.GetJobId()) {
+			return false
+		}
+	}
+
+	for _, j := range resp.GetBatchJobs() {
+		if req.GetBatchJobFilter() == nil ||
+			!matchJobID(req.GetBatchJobFilter().GetJobIds(), j.GetJobId()) {
+			return false
+		}
+	}
+
+	for _, w := range resp.GetWorkflows() {
+		if req.GetWorkflowFilter() == nil ||
+			!matchJobID(req.GetWorkflowFilter().GetJobIds(), w.GetJobId()) {
+			return false
+		}
+	}
+
+	for _, r := range resp.GetResourcePools() {
+		if req.GetResourcePoolFilter() == nil ||
+			!matchRespoolID(
+				req.GetResourcePoolFilter().GetRespoolIds(), r.GetRespoolId()) {
+			return false
+		}
+	}
+
+	for _, r := range resp.GetResourcePoolsDeleted() {
+		if req.GetResourcePoolFilter() == nil ||
+			!matchRespoolID(req.GetResourcePoolFilter().GetRespoolIds(), r) {
+			return false
+		}
+	}
+
+	for _, h := range resp.GetHosts() {
+		if req.GetHostFilter() == nil ||
+			!matchHostname(req.GetHostFilter().GetHostnames(), h.GetHostname()) {
+			return false
+		}
+	}
+
+	for _, h := range resp.GetHostsDeleted() {
+		if req.GetHostFilter() == nil ||
+			!matchHostname(req.GetHostFilter().GetHostnames(), h) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchPod returns whether a pod is selected by the pod filter
+func matchPod(
+	filter *watch.PodFilter,
+	p *pod.PodSummary,
+	podLabels []*v0peloton.Label,
+) bool {
+	// Check the job ID filter
+	if filter.GetJobId() != nil {
+		jobID, _, err := util.ParseTaskID(p.GetPodName().GetValue())
+		if err != nil {
+			// Cannot parse podName to match the jobID, assume that
+			// filter does not match.
+			return false
+		}
+
+		if jobID != filter.GetJobId().GetValue() {
+			// job id filter did not match
+			return false
+		}
+
+		// check the podname filter next
+		if len(filter.GetPodNames()) > 0 {
+			found := false
+			for _, podName := range filter.GetPodNames() {
+				if podName.GetValue() == p.GetPodName().GetValue() {
+					found = true
+					break
+				}
+			}
+			if !found {
+				// pod name filter did not match
+				return false
+			}
+		}
+	}
+
+	// Check the pod label filter next
+	for _, labelFilter := range filter.GetLabels() {
+		found := false
+		for _, labelPod := range podLabels {
+			if labelFilter.GetKey() == labelPod.GetKey() &&
+				labelFilter.GetValue() == labelPod.GetValue() {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			// label filter did not match
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchJobID returns whether a job id is in the list of job ids
+// of a filter, an empty list selects all the jobs
+func matchJobID(jobIDs []*peloton.JobID, jobID *peloton.JobID) bool {
+	if len(jobIDs) == 0 {
+		return true
+	}
+	for _, id := range jobIDs {
+		if id.GetValue() == jobID.GetValue() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRespoolID returns whether a resource pool id is in the list of
+// resource pool ids of a filter, an empty list selects all the
+// resource pools
+func matchRespoolID(
+	respoolIDs []*peloton.ResourcePoolID,
+	respoolID *peloton.ResourcePoolID,
+) bool {
+	if len(respoolIDs) == 0 {
+		return true
+	}
+	for _, id := range respoolIDs {
+		if id.GetValue() == respoolID.GetValue() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHostname returns whether a hostname is in the list of hostnames
+// of a filter, an empty list selects all the hosts
+func matchHostname(hostnames []string, hostname string) bool {
+	if len(hostnames) == 0 {
+		return true
+	}
+	for _, h := range hostnames {
+		if h == hostname {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchsvc
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/respool"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMatchEvent tests the events are matched against the filter of
+// their kind of object
+func TestMatchEvent(t *testing.T) {
+	jobID := &peloton.JobID{Value: "job-1"}
+	respoolID := &peloton.ResourcePoolID{Value: "respool-1"}
+
+	statelessJob := &svc.WatchResponse{
+		StatelessJobs: []*stateless.JobSummary{{JobId: jobID}},
+	}
+	batchJob := &svc.WatchResponse{
+		BatchJobs: []*stateless.JobSummary{{JobId: jobID}},
+	}
+	workflow := &svc.WatchResponse{
+		Workflows: []*svc.WorkflowSummary{{JobId: jobID}},
+	}
+	respoolChanged := &svc.WatchResponse{
+		ResourcePools: []*respool.ResourcePoolInfo{{RespoolId: respoolID}},
+	}
+	respoolDeleted := &svc.WatchResponse{
+		ResourcePoolsDeleted: []*peloton.ResourcePoolID{respoolID},
+	}
+	hostChanged := &svc.WatchResponse{
+		Hosts: []*host.HostInfo{{Hostname: "host-1"}},
+	}
+	hostDeleted := &svc.WatchResponse{
+		HostsDeleted: []string{"host-1"},
+	}
+	podChanged := &svc.WatchResponse{
+		Pods: []*pod.PodSummary{{
+			PodName: &peloton.PodName{Value: "job-1-0"},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		req      *svc.WatchRequest
+		response *svc.WatchResponse
+		match    bool
+	}{
+		{
+			name:     "no filter",
+			req:      &svc.WatchRequest{},
+			response: statelessJob,
+			match:    false,
+		},
+		{
+			name: "stateless job filter for all jobs",
+			req: &svc.WatchRequest{
+				StatelessJobFilter: &watch.StatelessJobFilter{},
+			},
+			response: statelessJob,
+			match:    true,
+		},
+		{
+			name: "stateless job filter for batch job",
+			req: &svc.WatchRequest{
+				StatelessJobFilter: &watch.StatelessJobFilter{},
+			},
+			response: batchJob,
+			match:    false,
+		},
+		{
+			name: "batch job filter for the job",
+			req: &svc.WatchRequest{
+				BatchJobFilter: &watch.BatchJobFilter{
+					JobIds: []*peloton.JobID{jobID},
+				},
+			},
+			response: batchJob,
+			match:    true,
+		},
+		{
+			name: "batch job filter for other job",
+			req: &svc.WatchRequest{
+				BatchJobFilter: &watch.BatchJobFilter{
+					JobIds: []*peloton.JobID{{Value: "job-2"}},
+				},
+			},
+			response: batchJob,
+			match:    false,
+		},
+		{
+			name: "workflow filter for the job",
+			req: &svc.WatchRequest{
+				WorkflowFilter: &watch.WorkflowFilter{
+					JobIds: []*peloton.JobID{jobID},
+				},
+			},
+			response: workflow,
+			match:    true,
+		},
+		{
+			name: "resource pool filter for changed resource pool",
+			req: &svc.WatchRequest{
+				ResourcePoolFilter: &watch.ResourcePoolFilter{
+					RespoolIds: []*peloton.ResourcePoolID{respoolID},
+				},
+			},
+			response: respoolChanged,
+			match:    true,
+		},
+		{
+			name: "resource pool filter for deleted resource pool",
+			req: &svc.WatchRequest{
+				ResourcePoolFilter: &watch.ResourcePoolFilter{},
+			},
+			response: respoolDeleted,
+			match:    true,
+		},
+		{
+			name: "resource pool filter for other resource pool",
+			req: &svc.WatchRequest{
+				ResourcePoolFilter: &watch.ResourcePoolFilter{
+					RespoolIds: []*peloton.ResourcePoolID{{Value: "respool-2"}},
+				},
+			},
+			response: respoolDeleted,
+			match:    false,
+		},
+		{
+			name: "host filter for the host",
+			req: &svc.WatchRequest{
+				HostFilter: &watch.HostFilter{Hostnames: []string{"host-1"}},
+			},
+			response: hostChanged,
+			match:    true,
+		},
+		{
+			name: "host filter for other host",
+			req: &svc.WatchRequest{
+				HostFilter: &watch.HostFilter{Hostnames: []string{"host-2"}},
+			},
+			response: hostChanged,
+			match:    false,
+		},
+		{
+			name: "host filter for deleted host",
+			req: &svc.WatchRequest{
+				HostFilter: &watch.HostFilter{Hostnames: []string{"host-1"}},
+			},
+			response: hostDeleted,
+			match:    true,
+		},
+		{
+			name: "resource pool filter for deleted host",
+			req: &svc.WatchRequest{
+				ResourcePoolFilter: &watch.ResourcePoolFilter{},
+			},
+			response: hostDeleted,
+			match:    false,
+		},
+		{
+			name: "pod filter for the job",
+			req: &svc.WatchRequest{
+				PodFilter: &watch.PodFilter{JobId: jobID},
+			},
+			response: podChanged,
+			match:    true,
+		},
+		{
+			name: "host filter for pod",
+			req: &svc.WatchRequest{
+				HostFilter: &watch.HostFilter{},
+			},
+			response: podChanged,
+			match:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.match,
+			matchEvent(tt.req, &event{response: tt.response}), tt.name)
+		assert.Equal(t, tt.name != "no filter", hasFilter(tt.req), tt.name)
+	}
+}
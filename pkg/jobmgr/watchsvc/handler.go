@@ -16,11 +16,11 @@ package watchsvc
 
 import (
 	"context"
-	"strings"
 
-	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
 
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
 	"go.uber.org/yarpc"
@@ -50,8 +50,9 @@ func InitV1AlphaWatchServiceHandler(
 	d *yarpc.Dispatcher,
 	parent tally.Scope,
 	config Config,
+	ormStore *ormobjects.Store,
 ) WatchProcessor {
-	InitWatchProcessor(config, parent)
+	InitWatchProcessor(config, ormStore, parent)
 	processor := GetWatchProcessor()
 
 	handler := NewServiceHandler(NewMetrics(parent), processor)
@@ -67,81 +68,97 @@ func (h *ServiceHandler) Watch(
 	req *svc.WatchRequest,
 	stream svc.WatchServiceServiceWatchYARPCServer,
 ) error {
-	// Create watch for pod
-	if req.GetPodFilter() != nil {
-		log.WithField("request", req).
-			Debug("starting new pod watch")
+	if !hasFilter(req) {
+		err := yarpcerrors.InvalidArgumentErrorf("not supported watch type")
+		log.Warn("not supported watch type")
+		return err
+	}
 
-		watchID, watchClient, err := h.processor.NewTaskClient(req.GetPodFilter())
-		if err != nil {
-			log.WithError(err).
-				Warn("failed to create pod watch client")
-			return err
-		}
+	log.WithField("request", req).
+		Debug("starting new watch")
+
+	watchID, watchClient, revision, err := h.processor.NewClient(req)
+	if err != nil {
+		log.WithError(err).
+			Warn("failed to create watch client")
+		return err
+	}
 
-		defer func() {
-			h.processor.StopTaskClient(watchID)
-		}()
+	defer func() {
+		h.processor.StopClient(watchID)
+	}()
 
-		initResp := &svc.WatchResponse{
-			WatchId: watchID,
-		}
-		if err := stream.Send(initResp); err != nil {
+	initResp := &svc.WatchResponse{
+		WatchId:  watchID,
+		Revision: revision,
+	}
+	if err := stream.Send(initResp); err != nil {
+		log.WithField("watch_id", watchID).
+			WithError(err).
+			Warn("failed to send initial response for watch")
+		return err
+	}
+
+	// Replay the events from the start revision up to the revision
+	// at which the client was created, the later events are sent
+	// through the client input
+	if startRevision := req.GetStartRevision(); startRevision != 0 {
+		ctx, cancel := context.WithTimeout(
+			context.Background(), _storeTimeout)
+		resps, err := h.processor.GetEvents(
+			ctx, watchID, req, startRevision, revision)
+		cancel()
+		if err != nil {
+			h.metrics.WatchReplayFail.Inc(1)
 			log.WithField("watch_id", watchID).
 				WithError(err).
-				Warn("failed to send initial response for pod watch")
+				Warn("failed to replay events for watch")
 			return err
 		}
 
-		for {
-			select {
-			case p := <-watchClient.Input:
-				resp := &svc.WatchResponse{
-					WatchId: watchID,
-					Pods:    []*pod.PodSummary{p},
-				}
-				if err := stream.Send(resp); err != nil {
-					log.WithField("watch_id", watchID).
-						WithError(err).
-						Warn("failed to send response for pod watch")
-					return err
-				}
-			case s := <-watchClient.Signal:
-				log.WithFields(log.Fields{
-					"watch_id": watchID,
-					"signal":   s,
-				}).Debug("received signal")
-
-				err := handleSignal(
-					watchID,
-					s,
-					map[StopSignal]tally.Counter{
-						StopSignalCancel:   h.metrics.WatchPodCancel,
-						StopSignalOverflow: h.metrics.WatchPodOverflow,
-					},
-				)
-
-				if !yarpcerrors.IsCancelled(err) {
-					log.WithField("watch_id", watchID).
-						WithError(err).
-						Warn("watch stopped due to signal")
-				}
-
+		for _, resp := range resps {
+			if err := stream.Send(resp); err != nil {
+				log.WithField("watch_id", watchID).
+					WithError(err).
+					Warn("failed to send replayed response for watch")
 				return err
 			}
 		}
 	}
 
-	// Create watch for job
-	if req.GetStatelessJobFilter() != nil {
-		err := yarpcerrors.UnimplementedErrorf("job watch is not implemented")
-		log.Warn("job watch is not implemented")
-		return err
-	}
+	for {
+		select {
+		case resp := <-watchClient.Input:
+			if err := stream.Send(resp); err != nil {
+				log.WithField("watch_id", watchID).
+					WithError(err).
+					Warn("failed to send response for watch")
+				return err
+			}
+		case s := <-watchClient.Signal:
+			log.WithFields(log.Fields{
+				"watch_id": watchID,
+				"signal":   s,
+			}).Debug("received signal")
+
+			err := handleSignal(
+				watchID,
+				s,
+				map[StopSignal]tally.Counter{
+					StopSignalCancel:   h.metrics.WatchCancel,
+					StopSignalOverflow: h.metrics.WatchOverflow,
+				},
+			)
+
+			if !yarpcerrors.IsCancelled(err) {
+				log.WithField("watch_id", watchID).
+					WithError(err).
+					Warn("watch stopped due to signal")
+			}
 
-	err := yarpcerrors.InvalidArgumentErrorf("not supported watch type")
-	log.Warn("not supported watch type")
-	return err
+			return err
+		}
+	}
 }
 
 // handleSignal converts StopSignal to appropriate yarpcerror
@@ -173,26 +190,18 @@ func (h *ServiceHandler) Cancel(
 ) (*svc.CancelResponse, error) {
 	watchID := req.GetWatchId()
 
-	if strings.HasPrefix(watchID, ClientTypeTask.String()) {
-		err := h.processor.StopTaskClient(watchID)
-		if err != nil {
-			if yarpcerrors.IsNotFound(err) {
-				h.metrics.CancelNotFound.Inc(1)
-			}
-
-			log.WithField("watch_id", watchID).
-				WithError(err).
-				Warn("failed to stop task client")
-
-			return nil, err
+	err := h.processor.StopClient(watchID)
+	if err != nil {
+		if yarpcerrors.IsNotFound(err) {
+			h.metrics.CancelNotFound.Inc(1)
 		}
 
-		return &svc.CancelResponse{}, nil
+		log.WithField("watch_id", watchID).
+			WithError(err).
+			Warn("failed to stop watch client")
+
+		return nil, err
 	}
 
-	err := yarpcerrors.NotFoundErrorf("invalid watch id")
-	log.WithFields(log.Fields{
-		"watch_id": watchID,
-	}).Warn("invalid watch id")
-	return nil, err
+	return &svc.CancelResponse{}, nil
 }
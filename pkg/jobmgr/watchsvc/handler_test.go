@@ -19,8 +19,11 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/respool"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
 	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
 	watchsvcmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc/mocks"
@@ -77,6 +80,7 @@ func (suite *WatchServiceHandlerTestSuite) TestInitV1AlphaWatchServiceHandler()
 		dispatcher,
 		suite.testScope,
 		Config{},
+		nil,
 	)
 	suite.NotNil(processor)
 }
@@ -91,80 +95,165 @@ func (suite *WatchServiceHandlerTestSuite) TestWatch_InvalidRequest() {
 	suite.True(yarpcerrors.IsInvalidArgument(err))
 }
 
-func (suite *WatchServiceHandlerTestSuite) TestJobWatch() {
-	req := &watchsvc.WatchRequest{
-		StatelessJobFilter: &watch.StatelessJobFilter{},
-	}
-
-	// job watch is currently unimplemented
-	err := suite.handler.Watch(req, suite.watchServer)
-	suite.Error(err)
-	suite.True(yarpcerrors.IsUnimplemented(err))
-}
-
-// TestTaskWatch sets up a watch client, and verifies the responses
+// TestWatch sets up a watch client, and verifies the responses
 // are streamed back correctly based on the input, finally the
 // test cancels the watch stream.
-func (suite *WatchServiceHandlerTestSuite) TestTaskWatch() {
-	watchID := NewWatchID(ClientTypeTask)
-	taskClient := &TaskClient{
+func (suite *WatchServiceHandlerTestSuite) TestWatch() {
+	watchID := NewWatchID()
+	watchClient := &Client{
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *pod.PodSummary),
+		Input:  make(chan *watchsvc.WatchResponse),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
-		Return(watchID, taskClient, nil)
-	suite.processor.EXPECT().StopTaskClient(watchID)
+	req := &watchsvc.WatchRequest{
+		PodFilter:          &watch.PodFilter{},
+		StatelessJobFilter: &watch.StatelessJobFilter{},
+	}
 
-	pods := []*pod.PodSummary{
+	suite.processor.EXPECT().NewClient(req).
+		Return(watchID, watchClient, uint64(10), nil)
+	suite.processor.EXPECT().StopClient(watchID)
+
+	resps := []*watchsvc.WatchResponse{
 		{
-			PodName: &peloton.PodName{Value: "pod-0"},
+			WatchId:  watchID,
+			Revision: 11,
+			Pods: []*pod.PodSummary{
+				{PodName: &peloton.PodName{Value: "pod-0"}},
+			},
 		},
 		{
-			PodName: &peloton.PodName{Value: "pod-1"},
+			WatchId:  watchID,
+			Revision: 12,
+			StatelessJobs: []*stateless.JobSummary{
+				{JobId: &peloton.JobID{Value: "job-0"}},
+			},
 		},
 	}
 
 	suite.watchServer.EXPECT().
 		Send(&watchsvc.WatchResponse{
-			WatchId: watchID,
-			Pods:    nil,
+			WatchId:  watchID,
+			Revision: 10,
 		}).
 		Return(nil)
-	for _, p := range pods {
+	for _, resp := range resps {
 		suite.watchServer.EXPECT().
-			Send(&watchsvc.WatchResponse{
-				WatchId: watchID,
-				Pods:    []*pod.PodSummary{p},
-			}).
+			Send(resp).
 			Return(nil)
 	}
 
+	go func() {
+		for _, resp := range resps {
+			watchClient.Input <- resp
+		}
+		// cancelling watch
+		watchClient.Signal <- StopSignalCancel
+	}()
+
+	err := suite.handler.Watch(req, suite.watchServer)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsCancelled(err))
+}
+
+// TestWatch_StartRevision checks the events from the start revision
+// are replayed before the events sent through the client input.
+func (suite *WatchServiceHandlerTestSuite) TestWatch_StartRevision() {
+	watchID := NewWatchID()
+	watchClient := &Client{
+		Input:  make(chan *watchsvc.WatchResponse),
+		Signal: make(chan StopSignal, 1),
+	}
+
 	req := &watchsvc.WatchRequest{
-		PodFilter: &watch.PodFilter{},
+		StartRevision: 5,
+		HostFilter:    &watch.HostFilter{},
+	}
+
+	replayed := []*watchsvc.WatchResponse{
+		{
+			WatchId:  watchID,
+			Revision: 6,
+			Hosts:    []*host.HostInfo{{Hostname: "host-0"}},
+		},
+	}
+	live := &watchsvc.WatchResponse{
+		WatchId:  watchID,
+		Revision: 11,
+		Hosts:    []*host.HostInfo{{Hostname: "host-1"}},
 	}
 
+	gomock.InOrder(
+		suite.processor.EXPECT().NewClient(req).
+			Return(watchID, watchClient, uint64(10), nil),
+		suite.watchServer.EXPECT().
+			Send(&watchsvc.WatchResponse{
+				WatchId:  watchID,
+				Revision: 10,
+			}).
+			Return(nil),
+		suite.processor.EXPECT().
+			GetEvents(gomock.Any(), watchID, req, uint64(5), uint64(10)).
+			Return(replayed, nil),
+		suite.watchServer.EXPECT().
+			Send(replayed[0]).
+			Return(nil),
+		suite.watchServer.EXPECT().
+			Send(live).
+			Return(nil),
+	)
+	suite.processor.EXPECT().StopClient(watchID)
+
 	go func() {
-		for _, p := range pods {
-			taskClient.Input <- p
-		}
-		// cancelling task watch
-		taskClient.Signal <- StopSignalCancel
+		watchClient.Input <- live
+		watchClient.Signal <- StopSignalOverflow
 	}()
 
 	err := suite.handler.Watch(req, suite.watchServer)
 	suite.Error(err)
-	suite.True(yarpcerrors.IsCancelled(err))
+	suite.True(yarpcerrors.IsInternal(err))
+}
+
+// TestWatch_ReplayError checks Watch returns the error when the events
+// from the start revision cannot be replayed.
+func (suite *WatchServiceHandlerTestSuite) TestWatch_ReplayError() {
+	watchID := NewWatchID()
+	watchClient := &Client{
+		Input:  make(chan *watchsvc.WatchResponse),
+		Signal: make(chan StopSignal, 1),
+	}
+
+	req := &watchsvc.WatchRequest{
+		StartRevision:  5,
+		BatchJobFilter: &watch.BatchJobFilter{},
+	}
+
+	suite.processor.EXPECT().NewClient(req).
+		Return(watchID, watchClient, uint64(10), nil)
+	suite.processor.EXPECT().StopClient(watchID)
+	suite.watchServer.EXPECT().
+		Send(&watchsvc.WatchResponse{
+			WatchId:  watchID,
+			Revision: 10,
+		}).
+		Return(nil)
+	suite.processor.EXPECT().
+		GetEvents(gomock.Any(), watchID, req, uint64(5), uint64(10)).
+		Return(nil, yarpcerrors.OutOfRangeErrorf("compacted"))
+
+	err := suite.handler.Watch(req, suite.watchServer)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsOutOfRange(err))
 }
 
-// TestTaskWatch_MaxClientReached checks Watch will return resource-exhausted
-// error when NewTaskClient reached max client.
-func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_MaxClientReached() {
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
-		Return("", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached"))
+// TestWatch_NewClientError checks Watch will return the error of
+// NewClient, such as resource-exhausted error when max client is reached.
+func (suite *WatchServiceHandlerTestSuite) TestWatch_NewClientError() {
+	suite.processor.EXPECT().NewClient(gomock.Any()).
+		Return("", nil, uint64(0), yarpcerrors.ResourceExhaustedErrorf("max client reached"))
 
 	req := &watchsvc.WatchRequest{
 		PodFilter: &watch.PodFilter{},
@@ -175,20 +264,17 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_MaxClientReached() {
 	suite.True(yarpcerrors.IsResourceExhausted(err))
 }
 
-// TestTaskWatch_InitSendError tests for error case of iniitial response.
-func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_InitSendError() {
-	watchID := NewWatchID(ClientTypeTask)
-	taskClient := &TaskClient{
-		// do not set buffer size for input to make sure the
-		// tests sends all the events before sending stop
-		// signal
-		Input:  make(chan *pod.PodSummary),
+// TestWatch_InitSendError tests for error case of iniitial response.
+func (suite *WatchServiceHandlerTestSuite) TestWatch_InitSendError() {
+	watchID := NewWatchID()
+	watchClient := &Client{
+		Input:  make(chan *watchsvc.WatchResponse),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
-		Return(watchID, taskClient, nil)
-	suite.processor.EXPECT().StopTaskClient(watchID)
+	suite.processor.EXPECT().NewClient(gomock.Any()).
+		Return(watchID, watchClient, uint64(0), nil)
+	suite.processor.EXPECT().StopClient(watchID)
 
 	sendErr := errors.New("message:transport is closing")
 
@@ -196,12 +282,11 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_InitSendError() {
 	suite.watchServer.EXPECT().
 		Send(&watchsvc.WatchResponse{
 			WatchId: watchID,
-			Pods:    nil,
 		}).
 		Return(sendErr)
 
 	req := &watchsvc.WatchRequest{
-		PodFilter: &watch.PodFilter{},
+		WorkflowFilter: &watch.WorkflowFilter{},
 	}
 
 	err := suite.handler.Watch(req, suite.watchServer)
@@ -209,31 +294,31 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_InitSendError() {
 	suite.Equal(sendErr, err)
 }
 
-// TestTaskWatch_InitSendError tests for error case of subsequent response
+// TestWatch_SendError tests for error case of subsequent response
 // after initial one.
-func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_SendError() {
-	watchID := NewWatchID(ClientTypeTask)
-	taskClient := &TaskClient{
-		// do not set buffer size for input to make sure the
-		// tests sends all the events before sending stop
-		// signal
-		Input:  make(chan *pod.PodSummary),
+func (suite *WatchServiceHandlerTestSuite) TestWatch_SendError() {
+	watchID := NewWatchID()
+	watchClient := &Client{
+		Input:  make(chan *watchsvc.WatchResponse),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
-		Return(watchID, taskClient, nil)
-	suite.processor.EXPECT().StopTaskClient(watchID)
+	suite.processor.EXPECT().NewClient(gomock.Any()).
+		Return(watchID, watchClient, uint64(0), nil)
+	suite.processor.EXPECT().StopClient(watchID)
 
-	p := &pod.PodSummary{
-		PodName: &peloton.PodName{Value: "pod-0"},
+	resp := &watchsvc.WatchResponse{
+		WatchId:  watchID,
+		Revision: 1,
+		ResourcePools: []*respool.ResourcePoolInfo{
+			{RespoolId: &peloton.ResourcePoolID{Value: "respool-0"}},
+		},
 	}
 
 	// initial response
 	suite.watchServer.EXPECT().
 		Send(&watchsvc.WatchResponse{
 			WatchId: watchID,
-			Pods:    nil,
 		}).
 		Return(nil)
 
@@ -241,19 +326,16 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_SendError() {
 
 	// subsequent response
 	suite.watchServer.EXPECT().
-		Send(&watchsvc.WatchResponse{
-			WatchId: watchID,
-			Pods:    []*pod.PodSummary{p},
-		}).
+		Send(resp).
 		Return(sendErr)
 
 	req := &watchsvc.WatchRequest{
-		PodFilter: &watch.PodFilter{},
+		ResourcePoolFilter: &watch.ResourcePoolFilter{},
 	}
 
 	go func() {
-		taskClient.Input <- p
-		taskClient.Signal <- StopSignalCancel
+		watchClient.Input <- resp
+		watchClient.Signal <- StopSignalCancel
 	}()
 
 	err := suite.handler.Watch(req, suite.watchServer)
@@ -263,9 +345,9 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_SendError() {
 
 // TestCancel tests Cancel request are proxied to watch processor correctly.
 func (suite *WatchServiceHandlerTestSuite) TestCancel() {
-	watchID := NewWatchID(ClientTypeTask)
+	watchID := NewWatchID()
 
-	suite.processor.EXPECT().StopTaskClient(watchID).Return(nil)
+	suite.processor.EXPECT().StopClient(watchID).Return(nil)
 
 	resp, err := suite.handler.Cancel(suite.ctx, &watchsvc.CancelRequest{
 		WatchId: watchID,
@@ -274,15 +356,15 @@ func (suite *WatchServiceHandlerTestSuite) TestCancel() {
 	suite.NoError(err)
 }
 
-// TestCancel_NotFoundTask tests Cancel response returns not-found error, when
-// an invalid task watch-id is passed in.
-func (suite *WatchServiceHandlerTestSuite) TestCancel_NotFoundTask() {
-	watchID := NewWatchID(ClientTypeTask)
+// TestCancel_NotFound tests Cancel response returns not-found error, when
+// an unknown watch id is passed in.
+func (suite *WatchServiceHandlerTestSuite) TestCancel_NotFound() {
+	watchID := uuid.New()
 
-	err := yarpcerrors.NotFoundErrorf("watch_id %s not exist for task watch client", watchID)
+	err := yarpcerrors.NotFoundErrorf("watch_id %s not exist for watch client", watchID)
 
 	suite.processor.EXPECT().
-		StopTaskClient(watchID).
+		StopClient(watchID).
 		Return(err)
 
 	resp, err := suite.handler.Cancel(suite.ctx, &watchsvc.CancelRequest{
@@ -293,19 +375,6 @@ func (suite *WatchServiceHandlerTestSuite) TestCancel_NotFoundTask() {
 	suite.True(yarpcerrors.IsNotFound(err))
 }
 
-// TestCancel_InvalidWatchID tests Cancel response returns not-found error,
-// when an invalid watch id (without proper prefix) is passed in.
-func (suite *WatchServiceHandlerTestSuite) TestCancel_InvalidWatchID() {
-	watchID := uuid.New()
-
-	resp, err := suite.handler.Cancel(suite.ctx, &watchsvc.CancelRequest{
-		WatchId: watchID,
-	})
-	suite.Nil(resp)
-	suite.Error(err)
-	suite.True(yarpcerrors.IsNotFound(err))
-}
-
 func TestWatchServiceHandler(t *testing.T) {
 	suite.Run(t, &WatchServiceHandlerTestSuite{})
 }
@@ -18,8 +18,11 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	v1peloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/uber/peloton/pkg/common/util"
@@ -53,7 +56,26 @@ func (l WatchListener) JobRuntimeChanged(
 	jobType job.JobType,
 	runtime *job.RuntimeInfo,
 ) {
-	// TODO(kevinxu): to be implemented
+	if jobID == nil {
+		log.Debug("skip JobRuntimeChanged due to jobID being nil")
+		return
+	}
+
+	if runtime == nil {
+		log.Debug("skip JobRuntimeChanged due to runtime being nil")
+		return
+	}
+
+	if jobType != job.JobType_SERVICE && jobType != job.JobType_BATCH {
+		log.Debug("skip JobRuntimeChanged due to unknown job type")
+		return
+	}
+
+	j := &stateless.JobSummary{
+		JobId:  &v1peloton.JobID{Value: jobID.GetValue()},
+		Status: handlerutil.ConvertRuntimeInfoToJobStatus(runtime, nil),
+	}
+	l.processor.NotifyJobChange(j, jobType)
 }
 
 // TaskRuntimeChanged is invoked when the runtime for a task is updated
@@ -89,3 +111,32 @@ func (l WatchListener) TaskRuntimeChanged(
 	}
 	l.processor.NotifyTaskChange(p, labels)
 }
+
+// UpdateChanged is invoked when an update of a job is changed
+// in cache and persistent store.
+func (l WatchListener) UpdateChanged(
+	jobID *v0peloton.JobID,
+	updateModel *models.UpdateModel,
+) {
+	if jobID == nil {
+		log.Debug("skip UpdateChanged due to jobID being nil")
+		return
+	}
+
+	if updateModel == nil {
+		log.Debug("skip UpdateChanged due to updateModel being nil")
+		return
+	}
+
+	workflow := handlerutil.ConvertUpdateModelToWorkflowInfo(
+		nil, updateModel, nil, nil)
+	// the entity versions depend on the job runtime, which is
+	// not known here
+	workflow.GetStatus().Version = nil
+	workflow.GetStatus().PrevVersion = nil
+
+	l.processor.NotifyWorkflowChange(&svc.WorkflowSummary{
+		JobId:    &v1peloton.JobID{Value: jobID.GetValue()},
+		Workflow: workflow,
+	})
+}
@@ -20,6 +20,10 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/api/v0/update"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	watchmocks "github.com/uber/peloton/pkg/jobmgr/watchsvc/mocks"
 
@@ -103,6 +107,80 @@ func (suite *WatchListenerTestSuite) TestTaskRuntimeChanged_NilFields() {
 	)
 }
 
+// TestJobRuntimeChanged checks WatchProcessor.NotifyJobChange() is called
+// with the job type when JobRuntimeChanged is called on listener
+func (suite *WatchListenerTestSuite) TestJobRuntimeChanged() {
+	for _, jobType := range []job.JobType{
+		job.JobType_SERVICE,
+		job.JobType_BATCH,
+	} {
+		suite.processor.EXPECT().
+			NotifyJobChange(gomock.Any(), jobType).
+			Do(func(j *stateless.JobSummary, _ job.JobType) {
+				suite.Equal("test-job-1", j.GetJobId().GetValue())
+				suite.Equal(stateless.JobState_JOB_STATE_RUNNING,
+					j.GetStatus().GetState())
+			})
+
+		suite.listener.JobRuntimeChanged(
+			&v0peloton.JobID{Value: "test-job-1"},
+			jobType,
+			&job.RuntimeInfo{State: job.JobState_RUNNING},
+		)
+	}
+}
+
+// TestJobRuntimeChanged_NilFields checks WatchProcessor.NotifyJobChange()
+// is not called when some of the fields are passed in as nil.
+func (suite *WatchListenerTestSuite) TestJobRuntimeChanged_NilFields() {
+	// do not expect calls to processor.NotifyJobChange
+
+	suite.listener.JobRuntimeChanged(
+		nil,
+		job.JobType_SERVICE,
+		&job.RuntimeInfo{},
+	)
+
+	suite.listener.JobRuntimeChanged(
+		&v0peloton.JobID{Value: "test-job-1"},
+		job.JobType_SERVICE,
+		nil,
+	)
+}
+
+// TestUpdateChanged checks WatchProcessor.NotifyWorkflowChange() is called
+// when UpdateChanged is called on listener
+func (suite *WatchListenerTestSuite) TestUpdateChanged() {
+	suite.processor.EXPECT().
+		NotifyWorkflowChange(gomock.Any()).
+		Do(func(w *svc.WorkflowSummary) {
+			suite.Equal("test-job-1", w.GetJobId().GetValue())
+			suite.Equal(stateless.WorkflowState_WORKFLOW_STATE_PAUSED,
+				w.GetWorkflow().GetStatus().GetState())
+			suite.Equal(uint32(3),
+				w.GetWorkflow().GetStatus().GetNumInstancesCompleted())
+			suite.Nil(w.GetWorkflow().GetStatus().GetVersion())
+		})
+
+	suite.listener.UpdateChanged(
+		&v0peloton.JobID{Value: "test-job-1"},
+		&models.UpdateModel{
+			State:          update.State_PAUSED,
+			InstancesTotal: 10,
+			InstancesDone:  3,
+		},
+	)
+}
+
+// TestUpdateChanged_NilFields checks WatchProcessor.NotifyWorkflowChange()
+// is not called when some of the fields are passed in as nil.
+func (suite *WatchListenerTestSuite) TestUpdateChanged_NilFields() {
+	// do not expect calls to processor.NotifyWorkflowChange
+
+	suite.listener.UpdateChanged(nil, &models.UpdateModel{})
+	suite.listener.UpdateChanged(&v0peloton.JobID{Value: "test-job-1"}, nil)
+}
+
 func TestWatchListener(t *testing.T) {
 	suite.Run(t, &WatchListenerTestSuite{})
 }
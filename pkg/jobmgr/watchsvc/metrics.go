@@ -20,11 +20,27 @@ import (
 
 // Metrics is a placeholder for all metrics in watch api.
 type Metrics struct {
-	WatchPodCancel   tally.Counter
-	WatchPodOverflow tally.Counter
+	WatchCancel     tally.Counter
+	WatchOverflow   tally.Counter
+	WatchOutOfRange tally.Counter
+	WatchReplayFail tally.Counter
 
 	CancelNotFound tally.Counter
 
+	// Events dropped because the event queue was full
+	EventDropped   tally.Counter
+	EventWrite     tally.Counter
+	EventWriteFail tally.Counter
+
+	RevisionReserveFail tally.Counter
+	// Revision of the last event sent to the clients
+	Revision tally.Gauge
+
+	Compaction     tally.Counter
+	CompactionFail tally.Counter
+
+	PollFail tally.Counter
+
 	// Time takes to acquire lock in watch processor
 	ProcessorLockDuration tally.Timer
 }
@@ -33,11 +49,25 @@ type Metrics struct {
 func NewMetrics(scope tally.Scope) *Metrics {
 	subScope := scope.SubScope("watch")
 	return &Metrics{
-		WatchPodCancel:   subScope.Counter("watch_pod_cancel"),
-		WatchPodOverflow: subScope.Counter("watch_pod_overflow"),
+		WatchCancel:     subScope.Counter("watch_cancel"),
+		WatchOverflow:   subScope.Counter("watch_overflow"),
+		WatchOutOfRange: subScope.Counter("watch_out_of_range"),
+		WatchReplayFail: subScope.Counter("watch_replay_fail"),
 
 		CancelNotFound: subScope.Counter("cancel_not_found"),
 
+		EventDropped:   subScope.Counter("event_dropped"),
+		EventWrite:     subScope.Counter("event_write"),
+		EventWriteFail: subScope.Counter("event_write_fail"),
+
+		RevisionReserveFail: subScope.Counter("revision_reserve_fail"),
+		Revision:            subScope.Gauge("revision"),
+
+		Compaction:     subScope.Counter("compaction"),
+		CompactionFail: subScope.Counter("compaction_fail"),
+
+		PollFail: subScope.Counter("poll_fail"),
+
 		ProcessorLockDuration: subScope.Timer("processor_lock_duration"),
 	}
 }
@@ -0,0 +1,275 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchsvc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	v0respool "github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/respool"
+
+	"github.com/uber/peloton/pkg/common"
+
+	"github.com/golang/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// timeout of the calls to query the resource pools and the hosts
+const _pollTimeout = 30 * time.Second
+
+// Poller polls the resource pools from the resource manager and the
+// hosts from the host manager, and notifies the watch processor of
+// the ones which changed since the previous poll.
+type Poller struct {
+	sync.Mutex
+
+	processor     WatchProcessor
+	respoolClient v0respool.ResourceManagerYARPCClient
+	hostClient    host_svc.HostServiceYARPCClient
+
+	// resource pools and hosts of the previous poll, keyed
+	// by resource pool id and hostname. Only the watched fields
+	// of the resource pools are kept.
+	respools map[string]*respool.ResourcePoolInfo
+	hosts    map[string]*host.HostInfo
+
+	metrics *Metrics
+}
+
+// NewPoller returns a new instance of watchsvc.Poller
+func NewPoller(
+	d *yarpc.Dispatcher,
+	processor WatchProcessor,
+	parent tally.Scope,
+) *Poller {
+	return &Poller{
+		processor: processor,
+		respoolClient: v0respool.NewResourceManagerYARPCClient(
+			d.ClientConfig(common.PelotonResourceManager)),
+		hostClient: host_svc.NewHostServiceYARPCClient(
+			d.ClientConfig(common.PelotonHostManager)),
+		metrics: NewMetrics(parent),
+	}
+}
+
+// Start clears the resource pools and the hosts of the previous polls,
+// so that all of them are notified again on the first poll after the
+// job manager gains leadership.
+func (p *Poller) Start() {
+	p.Lock()
+	defer p.Unlock()
+
+	p.respools = nil
+	p.hosts = nil
+}
+
+// Poll queries the resource pools and the hosts, and notifies the
+// watch processor of the ones which changed or were deleted since the
+// previous poll. All of them are notified on the first poll.
+func (p *Poller) Poll() {
+	p.Lock()
+	defer p.Unlock()
+
+	if err := p.pollResourcePools(); err != nil {
+		p.metrics.PollFail.Inc(1)
+		log.WithError(err).Warn("failed to poll resource pools for watch")
+	}
+
+	if err := p.pollHosts(); err != nil {
+		p.metrics.PollFail.Inc(1)
+		log.WithError(err).Warn("failed to poll hosts for watch")
+	}
+}
+
+func (p *Poller) pollResourcePools() error {
+	ctx, cancel := context.WithTimeout(context.Background(), _pollTimeout)
+	defer cancel()
+
+	resp, err := p.respoolClient.Query(ctx, &v0respool.QueryRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.GetError() != nil {
+		return yarpcerrors.InternalErrorf(
+			"failed to query resource pools: %v", resp.GetError())
+	}
+
+	respools := make(map[string]*respool.ResourcePoolInfo)
+	for _, info := range resp.GetResourcePools() {
+		r := convertResourcePoolInfo(info)
+		id := r.GetRespoolId().GetValue()
+		watched := watchedResourcePoolFields(r)
+		respools[id] = watched
+
+		if prev, ok := p.respools[id]; ok && proto.Equal(prev, watched) {
+			continue
+		}
+		p.processor.NotifyResourcePoolChange(r)
+	}
+
+	for id := range p.respools {
+		if _, ok := respools[id]; !ok {
+			p.processor.NotifyResourcePoolDelete(
+				&peloton.ResourcePoolID{Value: id})
+		}
+	}
+
+	p.respools = respools
+	return nil
+}
+
+func (p *Poller) pollHosts() error {
+	ctx, cancel := context.WithTimeout(context.Background(), _pollTimeout)
+	defer cancel()
+
+	resp, err := p.hostClient.QueryHosts(ctx, &host_svc.QueryHostsRequest{})
+	if err != nil {
+		return err
+	}
+
+	hosts := make(map[string]*host.HostInfo)
+	for _, info := range resp.GetHostInfos() {
+		h := &host.HostInfo{
+			Hostname: info.GetHostname(),
+			Ip:       info.GetIp(),
+			State:    host.HostState(info.GetState()),
+		}
+		hosts[h.GetHostname()] = h
+
+		if prev, ok := p.hosts[h.GetHostname()]; ok && proto.Equal(prev, h) {
+			continue
+		}
+		p.processor.NotifyHostChange(h)
+	}
+
+	for hostname := range p.hosts {
+		if _, ok := hosts[hostname]; !ok {
+			p.processor.NotifyHostDelete(hostname)
+		}
+	}
+
+	p.hosts = hosts
+	return nil
+}
+
+// watchedResourcePoolFields returns the fields of a resource pool whose
+// changes are notified. The usages are left out since they change on
+// nearly every poll.
+func watchedResourcePoolFields(
+	r *respool.ResourcePoolInfo,
+) *respool.ResourcePoolInfo {
+	return &respool.ResourcePoolInfo{
+		RespoolId: r.GetRespoolId(),
+		Parent:    r.GetParent(),
+		Children:  r.GetChildren(),
+		Path:      r.GetPath(),
+		Spec:      r.GetSpec(),
+	}
+}
+
+// convertResourcePoolInfo converts v0 respool.ResourcePoolInfo to
+// v1alpha respool.ResourcePoolInfo
+func convertResourcePoolInfo(
+	info *v0respool.ResourcePoolInfo,
+) *respool.ResourcePoolInfo {
+	var resources []*respool.ResourceSpec
+	for _, r := range info.GetConfig().GetResources() {
+		resources = append(resources, &respool.ResourceSpec{
+			Kind:        r.GetKind(),
+			Reservation: r.GetReservation(),
+			Limit:       r.GetLimit(),
+			Share:       r.GetShare(),
+			Type:        respool.ReservationType(r.GetType()),
+		})
+	}
+
+	var children []*peloton.ResourcePoolID
+	for _, c := range info.GetChildren() {
+		children = append(children, &peloton.ResourcePoolID{
+			Value: c.GetValue(),
+		})
+	}
+
+	var usages []*respool.ResourceUsage
+	for _, u := range info.GetUsage() {
+		usages = append(usages, &respool.ResourceUsage{
+			Kind:       u.GetKind(),
+			Allocation: u.GetAllocation(),
+			Slack:      u.GetSlack(),
+		})
+	}
+
+	result := &respool.ResourcePoolInfo{
+		RespoolId: &peloton.ResourcePoolID{Value: info.GetId().GetValue()},
+		Children:  children,
+		Usages:    usages,
+	}
+
+	if info.GetParent() != nil {
+		result.Parent = &peloton.ResourcePoolID{
+			Value: info.GetParent().GetValue(),
+		}
+	}
+
+	if info.GetPath() != nil {
+		result.Path = &respool.ResourcePoolPath{
+			Value: info.GetPath().GetValue(),
+		}
+	}
+
+	if config := info.GetConfig(); config != nil {
+		result.Spec = &respool.ResourcePoolSpec{
+			Name:        config.GetName(),
+			OwningTeam:  config.GetOwningTeam(),
+			LdapGroups:  config.GetLdapGroups(),
+			Description: config.GetDescription(),
+			Resources:   resources,
+			Policy:      respool.SchedulingPolicy(config.GetPolicy()),
+		}
+		if config.GetChangeLog() != nil {
+			result.Spec.Revision = &peloton.Revision{
+				Version:   uint64(config.GetChangeLog().GetVersion()),
+				CreatedAt: uint64(config.GetChangeLog().GetCreatedAt()),
+				UpdatedAt: uint64(config.GetChangeLog().GetUpdatedAt()),
+				UpdatedBy: config.GetChangeLog().GetUpdatedBy(),
+			}
+		}
+		if config.GetParent() != nil {
+			result.Spec.Parent = &peloton.ResourcePoolID{
+				Value: config.GetParent().GetValue(),
+			}
+		}
+		if config.GetControllerLimit() != nil {
+			result.Spec.ControllerLimit = &respool.ControllerLimit{
+				MaxPercent: config.GetControllerLimit().GetMaxPercent(),
+			}
+		}
+		if config.GetSlackLimit() != nil {
+			result.Spec.SlackLimit = &respool.SlackLimit{
+				MaxPercent: config.GetSlackLimit().GetMaxPercent(),
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchsvc
+
+import (
+	"errors"
+	"testing"
+
+	v0changelog "github.com/uber/peloton/.gen/peloton/api/v0/changelog"
+	v0host "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	hostmocks "github.com/uber/peloton/.gen/peloton/api/v0/host/svc/mocks"
+	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	v0respool "github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/respool"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type PollerTestSuite struct {
+	suite.Suite
+
+	ctrl          *gomock.Controller
+	processor     *watchProcessor
+	respoolClient *respoolmocks.MockResourceManagerYARPCClient
+	hostClient    *hostmocks.MockHostServiceYARPCClient
+
+	poller *Poller
+}
+
+func (suite *PollerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.processor = newWatchProcessor(Config{}, nil, tally.NoopScope)
+	suite.respoolClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.hostClient = hostmocks.NewMockHostServiceYARPCClient(suite.ctrl)
+
+	suite.poller = &Poller{
+		processor:     suite.processor,
+		respoolClient: suite.respoolClient,
+		hostClient:    suite.hostClient,
+		metrics:       NewMetrics(tally.NoopScope),
+	}
+}
+
+func (suite *PollerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestPoller(t *testing.T) {
+	suite.Run(t, &PollerTestSuite{})
+}
+
+// events returns the events the poller notified the processor of
+func (suite *PollerTestSuite) events() []*svc.WatchResponse {
+	var responses []*svc.WatchResponse
+	for len(suite.processor.events) > 0 {
+		e := <-suite.processor.events
+		responses = append(responses, e.response)
+	}
+	return responses
+}
+
+func (suite *PollerTestSuite) expectQuery(
+	respools []*v0respool.ResourcePoolInfo,
+	hosts []*v0host.HostInfo,
+) {
+	suite.respoolClient.EXPECT().
+		Query(gomock.Any(), gomock.Any()).
+		Return(&v0respool.QueryResponse{ResourcePools: respools}, nil)
+	suite.hostClient.EXPECT().
+		QueryHosts(gomock.Any(), gomock.Any()).
+		Return(&host_svc.QueryHostsResponse{HostInfos: hosts}, nil)
+}
+
+// TestPoll tests all the resource pools and hosts are notified on the
+// first poll, and only the changed and deleted ones afterwards
+func (suite *PollerTestSuite) TestPoll() {
+	respool1 := &v0respool.ResourcePoolInfo{
+		Id: &v0peloton.ResourcePoolID{Value: "respool-1"},
+		Config: &v0respool.ResourcePoolConfig{
+			Name: "respool-1",
+		},
+	}
+	respool2 := &v0respool.ResourcePoolInfo{
+		Id: &v0peloton.ResourcePoolID{Value: "respool-2"},
+	}
+	host1 := &v0host.HostInfo{
+		Hostname: "host-1",
+		State:    v0host.HostState_HOST_STATE_UP,
+	}
+
+	suite.expectQuery([]*v0respool.ResourcePoolInfo{respool1, respool2},
+		[]*v0host.HostInfo{host1})
+	suite.poller.Poll()
+
+	events := suite.events()
+	suite.Len(events, 3)
+	suite.Equal("respool-1",
+		events[0].GetResourcePools()[0].GetRespoolId().GetValue())
+	suite.Equal("respool-2",
+		events[1].GetResourcePools()[0].GetRespoolId().GetValue())
+	suite.Equal(&host.HostInfo{
+		Hostname: "host-1",
+		State:    host.HostState_HOST_STATE_UP,
+	}, events[2].GetHosts()[0])
+
+	// respool-1 is unchanged, respool-2 is deleted and host-1 changed
+	suite.expectQuery([]*v0respool.ResourcePoolInfo{respool1},
+		[]*v0host.HostInfo{{
+			Hostname: "host-1",
+			State:    v0host.HostState_HOST_STATE_DRAINING,
+		}})
+	suite.poller.Poll()
+
+	events = suite.events()
+	suite.Len(events, 2)
+	suite.Equal([]*peloton.ResourcePoolID{{Value: "respool-2"}},
+		events[0].GetResourcePoolsDeleted())
+	suite.Equal(&host.HostInfo{
+		Hostname: "host-1",
+		State:    host.HostState_HOST_STATE_DRAINING,
+	}, events[1].GetHosts()[0])
+}
+
+// TestPollUsageAndHostRemoval tests a change of the usages alone is not
+// notified, and a host which is no longer returned is notified as removed
+func (suite *PollerTestSuite) TestPollUsageAndHostRemoval() {
+	respool1 := &v0respool.ResourcePoolInfo{
+		Id: &v0peloton.ResourcePoolID{Value: "respool-1"},
+		Usage: []*v0respool.ResourceUsage{{
+			Kind:       "cpu",
+			Allocation: 0.5,
+		}},
+	}
+	host1 := &v0host.HostInfo{Hostname: "host-1"}
+	host2 := &v0host.HostInfo{Hostname: "host-2"}
+
+	suite.expectQuery([]*v0respool.ResourcePoolInfo{respool1},
+		[]*v0host.HostInfo{host1, host2})
+	suite.poller.Poll()
+	suite.Len(suite.events(), 3)
+
+	respool1.Usage[0].Allocation = 1
+	suite.expectQuery([]*v0respool.ResourcePoolInfo{respool1},
+		[]*v0host.HostInfo{host1})
+	suite.poller.Poll()
+
+	events := suite.events()
+	suite.Len(events, 1)
+	suite.Equal([]string{"host-2"}, events[0].GetHostsDeleted())
+}
+
+// TestStart tests all the resource pools and hosts are notified again
+// on the first poll after the poller is started
+func (suite *PollerTestSuite) TestStart() {
+	respool1 := &v0respool.ResourcePoolInfo{
+		Id: &v0peloton.ResourcePoolID{Value: "respool-1"},
+	}
+	host1 := &v0host.HostInfo{Hostname: "host-1"}
+
+	suite.expectQuery([]*v0respool.ResourcePoolInfo{respool1},
+		[]*v0host.HostInfo{host1})
+	suite.poller.Poll()
+	suite.Len(suite.events(), 2)
+
+	suite.poller.Start()
+	suite.expectQuery([]*v0respool.ResourcePoolInfo{respool1},
+		[]*v0host.HostInfo{host1})
+	suite.poller.Poll()
+	suite.Len(suite.events(), 2)
+}
+
+// TestPollFail tests the snapshot is kept when the query fails
+func (suite *PollerTestSuite) TestPollFail() {
+	suite.respoolClient.EXPECT().
+		Query(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("query fail"))
+	suite.hostClient.EXPECT().
+		QueryHosts(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("query fail"))
+	suite.poller.Poll()
+
+	suite.respoolClient.EXPECT().
+		Query(gomock.Any(), gomock.Any()).
+		Return(&v0respool.QueryResponse{
+			Error: &v0respool.QueryResponse_Error{},
+		}, nil)
+	suite.hostClient.EXPECT().
+		QueryHosts(gomock.Any(), gomock.Any()).
+		Return(&host_svc.QueryHostsResponse{}, nil)
+	suite.poller.Poll()
+
+	suite.Empty(suite.events())
+}
+
+// TestConvertResourcePoolInfo tests the conversion of v0 resource pool
+// to v1alpha resource pool
+func (suite *PollerTestSuite) TestConvertResourcePoolInfo() {
+	info := &v0respool.ResourcePoolInfo{
+		Id:     &v0peloton.ResourcePoolID{Value: "respool-1"},
+		Parent: &v0peloton.ResourcePoolID{Value: "root"},
+		Children: []*v0peloton.ResourcePoolID{
+			{Value: "respool-2"},
+		},
+		Path: &v0respool.ResourcePoolPath{Value: "/respool-1"},
+		Config: &v0respool.ResourcePoolConfig{
+			ChangeLog: &v0changelog.ChangeLog{Version: 3},
+			Name:      "respool-1",
+			Resources: []*v0respool.ResourceConfig{{
+				Kind:        "cpu",
+				Reservation: 1,
+				Limit:       2,
+				Share:       1,
+				Type:        v0respool.ReservationType_STATIC,
+			}},
+			Parent:          &v0peloton.ResourcePoolID{Value: "root"},
+			Policy:          v0respool.SchedulingPolicy_PriorityFIFO,
+			ControllerLimit: &v0respool.ControllerLimit{MaxPercent: 10},
+		},
+		Usage: []*v0respool.ResourceUsage{{
+			Kind:       "cpu",
+			Allocation: 0.5,
+			Demand:     1,
+		}},
+	}
+
+	suite.Equal(&respool.ResourcePoolInfo{
+		RespoolId: &peloton.ResourcePoolID{Value: "respool-1"},
+		Parent:    &peloton.ResourcePoolID{Value: "root"},
+		Children: []*peloton.ResourcePoolID{
+			{Value: "respool-2"},
+		},
+		Path: &respool.ResourcePoolPath{Value: "/respool-1"},
+		Spec: &respool.ResourcePoolSpec{
+			Revision: &peloton.Revision{Version: 3},
+			Name:     "respool-1",
+			Resources: []*respool.ResourceSpec{{
+				Kind:        "cpu",
+				Reservation: 1,
+				Limit:       2,
+				Share:       1,
+				Type:        respool.ReservationType_RESERVATION_TYPE_STATIC,
+			}},
+			Parent:          &peloton.ResourcePoolID{Value: "root"},
+			Policy:          respool.SchedulingPolicy_SCHEDULING_POLICY_PRIORITY_FIFO,
+			ControllerLimit: &respool.ControllerLimit{MaxPercent: 10},
+		},
+		Usages: []*respool.ResourceUsage{{
+			Kind:       "cpu",
+			Allocation: 0.5,
+		}},
+	}, convertResourcePoolInfo(info))
+}
@@ -15,24 +15,36 @@
 package watchsvc
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
 
-	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	v0job "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
-	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/respool"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
 
-	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/common/lifecycle"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
 	"github.com/pborman/uuid"
 	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/atomic"
 	"github.com/uber-go/tally"
 	"go.uber.org/yarpc/yarpcerrors"
 )
 
-// StopSignal is an event sent through task / job client Signal channel
-// indicating a stnop event for the specific watcher.
+// time to wait for space in the event queue for the events
+// of the objects which are polled
+const _pollEnqueueTimeout = 10 * time.Second
+
+// StopSignal is an event sent through watch client Signal channel
+// indicating a stop event for the specific watcher.
 type StopSignal int
 
 const (
@@ -57,79 +69,139 @@ func (s StopSignal) String() string {
 	}
 }
 
-// ClientType is a enum string to be embedded in the watch id
-// returned to the client, used to indicate the watch client type.
-type ClientType string
-
-const (
-	// ClientTypeTask indicates the watch id belongs to a task watch client
-	ClientTypeTask ClientType = "task"
-	// ClientTypeJob indicates the watch id belongs to a job watch client
-	ClientTypeJob ClientType = "job"
-)
-
-func (t ClientType) String() string {
-	return string(t)
-}
-
 // WatchProcessor interface is a central controller which handles watch
-// client lifecycle, and task / job event fan-out.
+// client lifecycle, and event persistence and fan-out.
 type WatchProcessor interface {
-	// NewTaskClient creates a new watch client for task event changes.
-	// Returns the watch id and a new instance of TaskClient.
-	NewTaskClient(filter *watch.PodFilter) (string, *TaskClient, error)
+	// Start starts writing the events to the store and sending them
+	// to the clients, on gaining leadership.
+	Start() error
+
+	// Stop writes the pending events to the store and stops all the
+	// clients, on losing leadership.
+	Stop() error
 
-	// StopTaskClients stops all the task clients on leadership change.
-	StopTaskClients()
+	// NewClient creates a new watch client for the objects selected by
+	// the filters of the request. Returns the watch id, a new instance
+	// of Client, and the revision of the last event sent to the clients
+	// before the client was created. Returns "out-of-range" error if
+	// the start revision of the request has been compacted.
+	NewClient(req *svc.WatchRequest) (string, *Client, uint64, error)
 
-	// StopTaskClient stops a task watch client. Returns "not-found" error
+	// StopClients stops all the clients.
+	StopClients()
+
+	// StopClient stops a watch client. Returns "not-found" error
 	// if the corresponding watch client is not found.
-	StopTaskClient(watchID string) error
+	StopClient(watchID string) error
+
+	// GetEvents returns the events of the revisions in [from, to] which
+	// match the filters of the request of a client, read from the store.
+	GetEvents(
+		ctx context.Context,
+		watchID string,
+		req *svc.WatchRequest,
+		from uint64,
+		to uint64,
+	) ([]*svc.WatchResponse, error)
 
 	// NotifyTaskChange receives pod event, and notifies all the clients
 	// which are interested in the pod.
-	NotifyTaskChange(pod *pod.PodSummary, podLabels []*peloton.Label)
+	NotifyTaskChange(pod *pod.PodSummary, podLabels []*v0peloton.Label)
+
+	// NotifyJobChange receives job event, and notifies all the clients
+	// which are interested in the job.
+	NotifyJobChange(job *stateless.JobSummary, jobType v0job.JobType)
+
+	// NotifyWorkflowChange receives workflow event, and notifies all
+	// the clients which are interested in the workflow.
+	NotifyWorkflowChange(workflow *svc.WorkflowSummary)
+
+	// NotifyResourcePoolChange receives resource pool event, and
+	// notifies all the clients which are interested in the resource pool.
+	NotifyResourcePoolChange(respool *respool.ResourcePoolInfo)
+
+	// NotifyResourcePoolDelete receives resource pool deletion, and
+	// notifies all the clients which are interested in the resource pool.
+	NotifyResourcePoolDelete(respoolID *peloton.ResourcePoolID)
+
+	// NotifyHostChange receives host event, and notifies all the
+	// clients which are interested in the host.
+	NotifyHostChange(host *host.HostInfo)
+
+	// NotifyHostDelete receives host removal, and notifies all the
+	// clients which are interested in the host.
+	NotifyHostDelete(hostname string)
+}
+
+// event is a change of an object waiting to be written and sent
+// to the clients
+type event struct {
+	// response holding the changed object
+	response *svc.WatchResponse
+	// labels of the pod, for the pod events
+	labels []*v0peloton.Label
 }
 
 // watchProcessor is an implementation of WatchProcessor interface.
 type watchProcessor struct {
 	sync.Mutex
-	bufferSize  int
-	maxClient   int
-	taskClients map[string]*TaskClient
-	jobClients  map[string]*JobClient
-	metrics     *Metrics
+	bufferSize int
+	maxClient  int
+	clients    map[string]*Client
+
+	// the events waiting to be written by the writer
+	events chan *event
+	// whether events have been dropped since they were last written
+	lost atomic.Bool
+
+	maxHistory  uint64
+	blockSize   uint64
+	eventOps    ormobjects.WatchEventOps
+	revisionOps ormobjects.WatchRevisionOps
+
+	// whether the revisions have been recovered from the store
+	ready bool
+	// revision of the last event sent to the clients
+	revision uint64
+	// highest revision which has been compacted
+	compacted uint64
+	// revisions reserved by this leader, only used by the writer
+	firstRevision    uint64
+	reservedRevision uint64
+
+	lifeCycle lifecycle.LifeCycle
+	metrics   *Metrics
 }
 
 var processor *watchProcessor
 var onceInitWatchProcessor sync.Once
 
-// TaskClient represents a client which interested in task event changes.
-type TaskClient struct {
-	Filter *watch.PodFilter
-	Input  chan *pod.PodSummary
-	Signal chan StopSignal
-}
-
-// JobClient represents a client which interested in job event changes.
-type JobClient struct {
-	Filter *watch.StatelessJobFilter
-	Input  chan *stateless.JobSummary
-	Signal chan StopSignal
+// Client represents a client which is interested in the changes of the
+// objects selected by the filters of its request.
+type Client struct {
+	Request *svc.WatchRequest
+	Input   chan *svc.WatchResponse
+	Signal  chan StopSignal
 }
 
 // newWatchProcessor should only be used in unit tests.
 // Call InitWatchProcessor for regular case use.
 func newWatchProcessor(
 	cfg Config,
+	ormStore *ormobjects.Store,
 	parent tally.Scope,
 ) *watchProcessor {
 	cfg.normalize()
 	return &watchProcessor{
 		bufferSize:  cfg.BufferSize,
 		maxClient:   cfg.MaxClient,
-		taskClients: make(map[string]*TaskClient),
-		jobClients:  make(map[string]*JobClient),
+		clients:     make(map[string]*Client),
+		events:      make(chan *event, cfg.EventQueueSize),
+		maxHistory:  cfg.MaxHistory,
+		blockSize:   cfg.RevisionBlockSize,
+		eventOps:    ormobjects.NewWatchEventOps(ormStore),
+		revisionOps: ormobjects.NewWatchRevisionOps(ormStore),
+		lifeCycle:   lifecycle.NewLifeCycle(),
 		metrics:     NewMetrics(parent),
 	}
 }
@@ -137,10 +209,11 @@ func newWatchProcessor(
 // InitWatchProcessor initializes WatchProcessor singleton.
 func InitWatchProcessor(
 	cfg Config,
+	ormStore *ormobjects.Store,
 	parent tally.Scope,
 ) {
 	onceInitWatchProcessor.Do(func() {
-		processor = newWatchProcessor(cfg, parent)
+		processor = newWatchProcessor(cfg, ormStore, parent)
 	})
 }
 
@@ -149,75 +222,99 @@ func GetWatchProcessor() WatchProcessor {
 	return processor
 }
 
-// NewWatchID creates a new watch id UUID string for the specific
-// watch client type
-func NewWatchID(clientType ClientType) string {
-	return fmt.Sprintf("%s_%s", clientType, uuid.New())
+// NewWatchID creates a new watch id UUID string
+func NewWatchID() string {
+	return fmt.Sprintf("watch_%s", uuid.New())
 }
 
-// NewTaskClient creates a new watch client for task event changes.
-// Returns the watch id and a new instance of TaskClient.
-func (p *watchProcessor) NewTaskClient(filter *watch.PodFilter) (string, *TaskClient, error) {
+// NewClient creates a new watch client for the objects selected by
+// the filters of the request.
+func (p *watchProcessor) NewClient(
+	req *svc.WatchRequest,
+) (string, *Client, uint64, error) {
 	sw := p.metrics.ProcessorLockDuration.Start()
 	p.Lock()
 	defer p.Unlock()
 	sw.Stop()
 
-	if len(p.taskClients) >= p.maxClient {
-		return "", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached")
+	if !p.ready {
+		return "", nil, 0, yarpcerrors.UnavailableErrorf(
+			"watch revisions are not recovered yet")
+	}
+
+	if len(p.clients) >= p.maxClient {
+		return "", nil, 0, yarpcerrors.ResourceExhaustedErrorf(
+			"max client reached")
+	}
+
+	if startRevision := req.GetStartRevision(); startRevision != 0 {
+		if startRevision <= p.compacted {
+			p.metrics.WatchOutOfRange.Inc(1)
+			return "", nil, 0, yarpcerrors.OutOfRangeErrorf(
+				"start revision %d has been compacted, compacted revision is %d",
+				startRevision, p.compacted)
+		}
+		if startRevision > p.revision+1 {
+			return "", nil, 0, yarpcerrors.InvalidArgumentErrorf(
+				"start revision %d is newer than server revision %d",
+				startRevision, p.revision)
+		}
 	}
 
-	watchID := NewWatchID(ClientTypeTask)
-	p.taskClients[watchID] = &TaskClient{
-		Input: make(chan *pod.PodSummary, p.bufferSize),
+	watchID := NewWatchID()
+	p.clients[watchID] = &Client{
+		Request: req,
+		Input:   make(chan *svc.WatchResponse, p.bufferSize),
 		// Make buffer size 1 so that sender is not blocked when sending
 		// the Signal
 		Signal: make(chan StopSignal, 1),
-		Filter: filter,
 	}
 
-	log.WithField("watch_id", watchID).Info("task watch client created")
-	return watchID, p.taskClients[watchID], nil
+	log.WithFields(log.Fields{
+		"watch_id": watchID,
+		"revision": p.revision,
+	}).Info("watch client created")
+	return watchID, p.clients[watchID], p.revision, nil
 }
 
-// StopTaskClients stops all the task clients on job manager leader change
-func (p *watchProcessor) StopTaskClients() {
+// StopClients stops all the clients.
+func (p *watchProcessor) StopClients() {
 	p.Lock()
 	defer p.Unlock()
 
-	for watchID := range p.taskClients {
-		p.stopTaskClient(watchID, StopSignalCancel)
+	for watchID := range p.clients {
+		p.stopClient(watchID, StopSignalCancel)
 	}
 }
 
-// StopTaskClient stops a task watch client. Returns "not-found" error
+// StopClient stops a watch client. Returns "not-found" error
 // if the corresponding watch client is not found.
-func (p *watchProcessor) StopTaskClient(watchID string) error {
+func (p *watchProcessor) StopClient(watchID string) error {
 	sw := p.metrics.ProcessorLockDuration.Start()
 	p.Lock()
 	defer p.Unlock()
 	sw.Stop()
 
-	return p.stopTaskClient(watchID, StopSignalCancel)
+	return p.stopClient(watchID, StopSignalCancel)
 }
 
-func (p *watchProcessor) stopTaskClient(
+func (p *watchProcessor) stopClient(
 	watchID string,
 	Signal StopSignal,
 ) error {
-	c, ok := p.taskClients[watchID]
+	c, ok := p.clients[watchID]
 	if !ok {
 		return yarpcerrors.NotFoundErrorf(
-			"watch_id %s not exist for task watch client", watchID)
+			"watch_id %s not exist for watch client", watchID)
 	}
 
 	log.WithFields(log.Fields{
 		"watch_id": watchID,
 		"signal":   Signal,
-	}).Info("stopping task watch client")
+	}).Info("stopping watch client")
 
 	c.Signal <- Signal
-	delete(p.taskClients, watchID)
+	delete(p.clients, watchID)
 
 	return nil
 }
@@ -226,80 +323,148 @@ func (p *watchProcessor) stopTaskClient(
 // which are interested in the pod.
 func (p *watchProcessor) NotifyTaskChange(
 	pod *pod.PodSummary,
-	podLabels []*peloton.Label) {
+	podLabels []*v0peloton.Label) {
+	p.enqueue(&event{
+		response: &svc.WatchResponse{Pods: []*pod.PodSummary{pod}},
+		labels:   podLabels,
+	}, 0)
+}
+
+// NotifyJobChange receives job event, and notifies all the clients
+// which are interested in the job.
+func (p *watchProcessor) NotifyJobChange(
+	job *stateless.JobSummary,
+	jobType v0job.JobType) {
+	response := &svc.WatchResponse{}
+	switch jobType {
+	case v0job.JobType_SERVICE:
+		response.StatelessJobs = []*stateless.JobSummary{job}
+	case v0job.JobType_BATCH:
+		response.BatchJobs = []*stateless.JobSummary{job}
+	default:
+		return
+	}
+	p.enqueue(&event{response: response}, 0)
+}
+
+// NotifyWorkflowChange receives workflow event, and notifies all
+// the clients which are interested in the workflow.
+func (p *watchProcessor) NotifyWorkflowChange(workflow *svc.WorkflowSummary) {
+	p.enqueue(&event{
+		response: &svc.WatchResponse{
+			Workflows: []*svc.WorkflowSummary{workflow},
+		},
+	}, 0)
+}
+
+// NotifyResourcePoolChange receives resource pool event, and notifies
+// all the clients which are interested in the resource pool.
+func (p *watchProcessor) NotifyResourcePoolChange(
+	respoolInfo *respool.ResourcePoolInfo) {
+	p.enqueue(&event{
+		response: &svc.WatchResponse{
+			ResourcePools: []*respool.ResourcePoolInfo{respoolInfo},
+		},
+	}, _pollEnqueueTimeout)
+}
+
+// NotifyResourcePoolDelete receives resource pool deletion, and notifies
+// all the clients which are interested in the resource pool.
+func (p *watchProcessor) NotifyResourcePoolDelete(
+	respoolID *peloton.ResourcePoolID) {
+	p.enqueue(&event{
+		response: &svc.WatchResponse{
+			ResourcePoolsDeleted: []*peloton.ResourcePoolID{respoolID},
+		},
+	}, _pollEnqueueTimeout)
+}
+
+// NotifyHostChange receives host event, and notifies all the clients
+// which are interested in the host.
+func (p *watchProcessor) NotifyHostChange(hostInfo *host.HostInfo) {
+	p.enqueue(&event{
+		response: &svc.WatchResponse{
+			Hosts: []*host.HostInfo{hostInfo},
+		},
+	}, _pollEnqueueTimeout)
+}
+
+// NotifyHostDelete receives host removal, and notifies all the clients
+// which are interested in the host.
+func (p *watchProcessor) NotifyHostDelete(hostname string) {
+	p.enqueue(&event{
+		response: &svc.WatchResponse{
+			HostsDeleted: []string{hostname},
+		},
+	}, _pollEnqueueTimeout)
+}
+
+// enqueue adds an event to the queue of the writer, waiting up to
+// timeout for space in the queue. The event is dropped if the queue
+// stays full, in which case the clients which are interested in the
+// event are stopped since they would miss it.
+func (p *watchProcessor) enqueue(e *event, timeout time.Duration) {
+	select {
+	case p.events <- e:
+		return
+	default:
+	}
+
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case p.events <- e:
+			return
+		case <-timer.C:
+		}
+	}
+
+	log.Warn("event queue overflow for watch processor")
+	p.drop(e)
+}
+
+// dispatch sends an event which has been written to the clients
+// which are interested in it
+func (p *watchProcessor) dispatch(e *event) {
 	sw := p.metrics.ProcessorLockDuration.Start()
 	p.Lock()
 	defer p.Unlock()
 	sw.Stop()
 
-	for watchID, c := range p.taskClients {
-		filterCheckPass := true
-
-		if c.Filter != nil {
-
-			// Check the job ID filter
-			if c.Filter.GetJobId() != nil {
-				jobID, _, err := util.ParseTaskID(pod.GetPodName().GetValue())
-				if err != nil {
-					// Cannot parse podName to match the jobID, assume that
-					// filter does not match.
-					filterCheckPass = false
-				}
-
-				if jobID != c.Filter.GetJobId().GetValue() {
-					// job id filter did not match
-					filterCheckPass = false
-				}
-
-				// check the podname filter next
-				if filterCheckPass == true && len(c.Filter.GetPodNames()) > 0 {
-					found := false
-					for _, podName := range c.Filter.GetPodNames() {
-						if podName.GetValue() == pod.GetPodName().GetValue() {
-							found = true
-							break
-						}
-					}
-					if found == false {
-						// pod name filter did not match
-						filterCheckPass = false
-					}
-				}
-			}
-
-			if filterCheckPass == false {
-				continue
-			}
-
-			// Check the pod label filter next
-			for _, labelFilter := range c.Filter.GetLabels() {
-				found := false
-				for _, labelPod := range podLabels {
-					if labelFilter.GetKey() == labelPod.GetKey() &&
-						labelFilter.GetValue() == labelPod.GetValue() {
-						found = true
-						break
-					}
-				}
-
-				if found == false {
-					// label filter did not match
-					filterCheckPass = false
-					break
-				}
-			}
-		}
+	p.revision = e.response.GetRevision()
+	p.metrics.Revision.Update(float64(p.revision))
 
-		if filterCheckPass == false {
+	for watchID, c := range p.clients {
+		if !matchEvent(c.Request, e) {
 			continue
 		}
 
 		select {
-		case c.Input <- pod:
+		case c.Input <- newResponse(watchID, e.response):
 		default:
 			log.WithField("watch_id", watchID).
-				Warn("event overflow for task watch client")
-			p.stopTaskClient(watchID, StopSignalOverflow)
+				Warn("event overflow for watch client")
+			p.stopClient(watchID, StopSignalOverflow)
 		}
 	}
 }
+
+// newResponse returns the response sent to a client for an event
+func newResponse(
+	watchID string,
+	response *svc.WatchResponse,
+) *svc.WatchResponse {
+	return &svc.WatchResponse{
+		WatchId:              watchID,
+		Revision:             response.GetRevision(),
+		StatelessJobs:        response.GetStatelessJobs(),
+		Pods:                 response.GetPods(),
+		BatchJobs:            response.GetBatchJobs(),
+		Workflows:            response.GetWorkflows(),
+		ResourcePools:        response.GetResourcePools(),
+		ResourcePoolsDeleted: response.GetResourcePoolsDeleted(),
+		Hosts:                response.GetHosts(),
+		HostsDeleted:         response.GetHostsDeleted(),
+	}
+}
@@ -16,18 +16,27 @@ package watchsvc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
 	"time"
 
+	v0job "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
 
 	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
@@ -38,6 +47,7 @@ type WatchProcessorTestSuite struct {
 	suite.Suite
 
 	ctx       context.Context
+	ctrl      *gomock.Controller
 	testScope tally.TestScope
 
 	config     Config
@@ -45,100 +55,176 @@ type WatchProcessorTestSuite struct {
 	instanceID uint32
 	podName    *peloton.PodName
 
-	processor WatchProcessor
+	eventOps    *objectmocks.MockWatchEventOps
+	revisionOps *objectmocks.MockWatchRevisionOps
+
+	processor *watchProcessor
 }
 
 func (suite *WatchProcessorTestSuite) SetupTest() {
 	suite.ctx = context.Background()
+	suite.ctrl = gomock.NewController(suite.T())
 	suite.testScope = tally.NewTestScope("", map[string]string{})
 
 	suite.config = Config{
-		BufferSize: 10,
-		MaxClient:  2,
+		BufferSize:        10,
+		MaxClient:         2,
+		EventQueueSize:    10,
+		MaxHistory:        5,
+		RevisionBlockSize: 10,
 	}
 	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
 	suite.instanceID = uint32(1)
 	suite.podName = &peloton.PodName{Value: fmt.Sprintf("%s-%d", suite.jobID.GetValue(), suite.instanceID)}
-	suite.processor = newWatchProcessor(suite.config, suite.testScope)
+
+	suite.eventOps = objectmocks.NewMockWatchEventOps(suite.ctrl)
+	suite.revisionOps = objectmocks.NewMockWatchRevisionOps(suite.ctrl)
+	suite.processor = newWatchProcessor(suite.config, nil, suite.testScope)
+	suite.processor.eventOps = suite.eventOps
+	suite.processor.revisionOps = suite.revisionOps
+}
+
+func (suite *WatchProcessorTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
 }
 
 func TestWatchProcessor(t *testing.T) {
 	suite.Run(t, &WatchProcessorTestSuite{})
 }
 
-// TestInitWatchProcessor tests initialization of WatchProcessor
-func (suite *WatchProcessorTestSuite) TestInitWatchProcessor() {
-	suite.Nil(GetWatchProcessor())
-	InitWatchProcessor(suite.config, suite.testScope)
-	suite.NotNil(GetWatchProcessor())
+// start starts the processor with no revisions in the store, and
+// waits for the revisions to be recovered
+func (suite *WatchProcessorTestSuite) start() {
+	suite.revisionOps.EXPECT().GetAll(gomock.Any()).Return(nil, nil)
+	suite.revisionOps.EXPECT().
+		Create(gomock.Any(), uint64(1), uint64(10), uint64(0)).
+		Return(nil)
+
+	suite.NoError(suite.processor.Start())
+	suite.waitReady()
 }
 
-// TestTaskClient tests basic setup and teardown of task watch client
-func (suite *WatchProcessorTestSuite) TestTaskClient() {
-	watchID, c, err := suite.processor.NewTaskClient(nil)
-	suite.NoError(err)
-	suite.NotEmpty(watchID)
-	suite.NotNil(c)
+// stop stops the processor, expecting the revisions to be written
+func (suite *WatchProcessorTestSuite) stop(clean bool) {
+	suite.revisionOps.EXPECT().
+		Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+			gomock.Any(), clean).
+		Return(nil)
+	suite.NoError(suite.processor.Stop())
+}
 
-	var wg sync.WaitGroup
-	wg.Add(1)
-	var stopSignal StopSignal
+func (suite *WatchProcessorTestSuite) waitReady() {
+	for i := 0; i < 100; i++ {
+		suite.processor.Lock()
+		ready := suite.processor.ready
+		suite.processor.Unlock()
+		if ready {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	suite.Fail("watch processor not ready")
+}
 
+// receive collects the responses sent to a client until it is stopped
+func receive(c *Client) (<-chan []*svc.WatchResponse, <-chan StopSignal) {
+	resps := make(chan []*svc.WatchResponse, 1)
+	signal := make(chan StopSignal, 1)
 	go func() {
-		defer wg.Done()
+		var received []*svc.WatchResponse
 		for {
 			select {
-			case <-c.Input:
-			case stopSignal = <-c.Signal:
+			case resp := <-c.Input:
+				received = append(received, resp)
+			case s := <-c.Signal:
+				// the responses sent before the signal
+				for len(c.Input) > 0 {
+					received = append(received, <-c.Input)
+				}
+				resps <- received
+				signal <- s
 				return
 			}
 		}
 	}()
+	return resps, signal
+}
+
+// TestInitWatchProcessor tests initialization of WatchProcessor
+func (suite *WatchProcessorTestSuite) TestInitWatchProcessor() {
+	suite.Nil(GetWatchProcessor())
+	InitWatchProcessor(suite.config, nil, suite.testScope)
+	suite.NotNil(GetWatchProcessor())
+}
 
-	err = suite.processor.StopTaskClient(watchID)
-	wg.Wait()
+// TestClient tests basic setup and teardown of watch client
+func (suite *WatchProcessorTestSuite) TestClient() {
+	suite.start()
+	defer suite.stop(true)
 
+	watchID, c, revision, err := suite.processor.NewClient(&svc.WatchRequest{})
 	suite.NoError(err)
-	suite.Equal(StopSignalCancel, stopSignal)
+	suite.NotEmpty(watchID)
+	suite.NotNil(c)
+	suite.Equal(uint64(0), revision)
+
+	_, signal := receive(c)
+	suite.NoError(suite.processor.StopClient(watchID))
+	suite.Equal(StopSignalCancel, <-signal)
+}
+
+// TestClient_NotReady tests an error will be thrown when creating a
+// client before the revisions are recovered.
+func (suite *WatchProcessorTestSuite) TestClient_NotReady() {
+	_, _, _, err := suite.processor.NewClient(&svc.WatchRequest{})
+	suite.Error(err)
+	suite.True(yarpcerrors.IsUnavailable(err))
 }
 
-// TestTaskClient_StopNonexistentClient tests an error will be thrown if
+// TestClient_StopNonexistentClient tests an error will be thrown if
 // tearing down a client with unknown watch id.
-func (suite *WatchProcessorTestSuite) TestTaskClient_StopNonexistentClient() {
-	watchID, c, err := suite.processor.NewTaskClient(nil)
+func (suite *WatchProcessorTestSuite) TestClient_StopNonexistentClient() {
+	suite.start()
+	defer suite.stop(true)
+
+	watchID, c, _, err := suite.processor.NewClient(&svc.WatchRequest{})
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
 
-	err = suite.processor.StopTaskClient("00000000-0000-0000-0000-000000000000")
+	err = suite.processor.StopClient("00000000-0000-0000-0000-000000000000")
 	suite.Error(err)
 	suite.True(yarpcerrors.IsNotFound(err))
 }
 
 // Test stop all clients on losing leadership
-func (suite *WatchProcessorTestSuite) TestTaskClient_StopAllClients() {
-	watchID1, c, err := suite.processor.NewTaskClient(nil)
-	suite.NoError(err)
-	suite.NotEmpty(watchID1)
-	suite.NotNil(c)
+func (suite *WatchProcessorTestSuite) TestClient_StopAllClients() {
+	suite.start()
 
-	watchID2, c, err := suite.processor.NewTaskClient(nil)
+	watchID1, _, _, err := suite.processor.NewClient(&svc.WatchRequest{})
+	suite.NoError(err)
+	watchID2, _, _, err := suite.processor.NewClient(&svc.WatchRequest{})
 	suite.NoError(err)
-	suite.NotEmpty(watchID2)
-	suite.NotNil(c)
 
-	suite.processor.StopTaskClients()
+	suite.stop(true)
 
 	// all clients are alredy stopped
-	suite.Error(suite.processor.StopTaskClient(watchID1))
-	suite.Error(suite.processor.StopTaskClient(watchID2))
+	suite.Error(suite.processor.StopClient(watchID1))
+	suite.Error(suite.processor.StopClient(watchID2))
+
+	// no client can be created until the processor is started again
+	_, _, _, err = suite.processor.NewClient(&svc.WatchRequest{})
+	suite.True(yarpcerrors.IsUnavailable(err))
 }
 
-// TestTaskClient_MaxClientReached tests an error will be thrown when
+// TestClient_MaxClientReached tests an error will be thrown when
 // creating a new client if max number of clients is reached.
-func (suite *WatchProcessorTestSuite) TestTaskClient_MaxClientReached() {
+func (suite *WatchProcessorTestSuite) TestClient_MaxClientReached() {
+	suite.start()
+	defer suite.stop(true)
+
 	for i := 0; i < 3; i++ {
-		watchID, c, err := suite.processor.NewTaskClient(nil)
+		watchID, c, _, err := suite.processor.NewClient(&svc.WatchRequest{})
 		if i < 2 {
 			suite.NoError(err)
 			suite.NotEmpty(watchID)
@@ -150,71 +236,514 @@ func (suite *WatchProcessorTestSuite) TestTaskClient_MaxClientReached() {
 	}
 }
 
-// TestTaskClient_EventOverflow tests that a "overflow" stop Signal will be
+// TestClient_StartRevision tests the start revision of the request is
+// checked against the compacted and the current revisions.
+func (suite *WatchProcessorTestSuite) TestClient_StartRevision() {
+	suite.processor.ready = true
+	suite.processor.revision = 20
+	suite.processor.compacted = 10
+
+	_, _, _, err := suite.processor.NewClient(
+		&svc.WatchRequest{StartRevision: 10})
+	suite.True(yarpcerrors.IsOutOfRange(err))
+
+	_, _, _, err = suite.processor.NewClient(
+		&svc.WatchRequest{StartRevision: 22})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	_, _, revision, err := suite.processor.NewClient(
+		&svc.WatchRequest{StartRevision: 21})
+	suite.NoError(err)
+	suite.Equal(uint64(20), revision)
+}
+
+// TestClient_EventOverflow tests that a "overflow" stop Signal will be
 // sent to the client and the client will be closed if the client buffer is
 // overflown.
-func (suite *WatchProcessorTestSuite) TestTaskClient_EventOverflow() {
-	watchID, c, err := suite.processor.NewTaskClient(nil)
+func (suite *WatchProcessorTestSuite) TestClient_EventOverflow() {
+	suite.processor.ready = true
+	suite.processor.reservedRevision = 100
+
+	watchID, c, _, err := suite.processor.NewClient(
+		&svc.WatchRequest{PodFilter: &watch.PodFilter{}})
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
-	suite.NotNil(c)
 
-	var wg sync.WaitGroup
-	wg.Add(1)
-	var stopSignal StopSignal
-
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case stopSignal = <-c.Signal:
-				return
-			}
-		}
-	}()
+	suite.eventOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(11)
 
 	// send number of events equal to buffer size
 	for i := 0; i < 10; i++ {
-		suite.processor.NotifyTaskChange(&pod.PodSummary{}, nil)
+		suite.processor.write(&event{
+			response: &svc.WatchResponse{Pods: []*pod.PodSummary{{}}},
+		})
 	}
-	time.Sleep(1 * time.Second)
-	suite.Equal(StopSignalUnknown, stopSignal)
+	suite.Len(c.Signal, 0)
 
 	// trigger buffer overflow
-	suite.processor.NotifyTaskChange(&pod.PodSummary{}, nil)
-	wg.Wait()
-	suite.Equal(StopSignalOverflow, stopSignal)
+	suite.processor.write(&event{
+		response: &svc.WatchResponse{Pods: []*pod.PodSummary{{}}},
+	})
+	suite.Equal(StopSignalOverflow, <-c.Signal)
 }
 
-func (suite *WatchProcessorTestSuite) TestTaskClientPodFilter() {
-	filter := &watch.PodFilter{
-		JobId:    suite.jobID,
-		PodNames: []*peloton.PodName{suite.podName},
+// TestWrite tests the events are numbered, written to the store and
+// sent to the clients which are interested in them.
+func (suite *WatchProcessorTestSuite) TestWrite() {
+	suite.start()
+
+	watchID, c, _, err := suite.processor.NewClient(&svc.WatchRequest{
+		StatelessJobFilter: &watch.StatelessJobFilter{},
+		HostFilter:         &watch.HostFilter{},
+	})
+	suite.NoError(err)
+	resps, _ := receive(c)
+
+	var mu sync.Mutex
+	var written []uint64
+	suite.eventOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, e *svc.WatchResponse, _ []*v0peloton.Label) {
+			mu.Lock()
+			defer mu.Unlock()
+			written = append(written, e.GetRevision())
+		}).
+		Return(nil).
+		Times(4)
+
+	suite.processor.NotifyJobChange(&stateless.JobSummary{
+		JobId: suite.jobID,
+	}, v0job.JobType_SERVICE)
+	suite.processor.NotifyJobChange(&stateless.JobSummary{
+		JobId: suite.jobID,
+	}, v0job.JobType_BATCH)
+	suite.processor.NotifyHostChange(&host.HostInfo{Hostname: "host-0"})
+	suite.processor.NotifyWorkflowChange(&svc.WorkflowSummary{
+		JobId: suite.jobID,
+	})
+
+	suite.stop(true)
+
+	suite.ElementsMatch([]uint64{1, 2, 3, 4}, written)
+	received := <-resps
+	suite.Len(received, 2)
+	suite.Equal(watchID, received[0].GetWatchId())
+	suite.Equal(uint64(1), received[0].GetRevision())
+	suite.Equal(suite.jobID, received[0].GetStatelessJobs()[0].GetJobId())
+	suite.Equal(uint64(3), received[1].GetRevision())
+	suite.Equal("host-0", received[1].GetHosts()[0].GetHostname())
+}
+
+// TestWrite_ReserveRevisions tests a new block of revisions is reserved
+// when the reserved revisions are used up.
+func (suite *WatchProcessorTestSuite) TestWrite_ReserveRevisions() {
+	suite.processor.ready = true
+	suite.processor.firstRevision = 1
+	suite.processor.reservedRevision = 1
+
+	suite.eventOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(2)
+	suite.revisionOps.EXPECT().
+		Update(gomock.Any(), uint64(1), uint64(1), uint64(11), uint64(0),
+			false).
+		Return(nil)
+
+	for i := 0; i < 2; i++ {
+		suite.processor.write(&event{
+			response: &svc.WatchResponse{Pods: []*pod.PodSummary{{}}},
+		})
 	}
+	suite.Equal(uint64(2), suite.processor.revision)
+	suite.Equal(uint64(11), suite.processor.reservedRevision)
+}
+
+// TestWrite_ReserveRevisionsFail tests the event is dropped and the
+// clients are stopped when the revisions cannot be reserved, and that
+// a revision is burnt once the revisions can be reserved again.
+func (suite *WatchProcessorTestSuite) TestWrite_ReserveRevisionsFail() {
+	suite.processor.ready = true
+	suite.processor.firstRevision = 1
+	suite.processor.reservedRevision = 1
+	suite.processor.revision = 1
+
+	_, c, _, err := suite.processor.NewClient(&svc.WatchRequest{
+		PodFilter: &watch.PodFilter{},
+	})
+	suite.NoError(err)
+
+	gomock.InOrder(
+		suite.revisionOps.EXPECT().
+			Update(gomock.Any(), uint64(1), uint64(1), uint64(11), uint64(0),
+				false).
+			Return(errors.New("update fail")),
+		suite.revisionOps.EXPECT().
+			Update(gomock.Any(), uint64(1), uint64(1), uint64(11), uint64(0),
+				false).
+			Return(nil),
+		// the burnt revision is compacted
+		suite.revisionOps.EXPECT().
+			Update(gomock.Any(), uint64(1), uint64(11), uint64(11), uint64(2),
+				false).
+			Return(nil),
+		suite.eventOps.EXPECT().
+			Delete(gomock.Any(), uint64(1), uint64(2)).
+			Return(nil),
+		suite.eventOps.EXPECT().
+			Create(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil),
+	)
+
+	e := &event{response: &svc.WatchResponse{Pods: []*pod.PodSummary{{}}}}
+	suite.processor.write(e)
+	suite.Equal(StopSignalOverflow, <-c.Signal)
+	suite.True(suite.processor.lost.Load())
 
-	var mutex = &sync.Mutex{}
-	var wg sync.WaitGroup
-	wg.Add(1)
-	received := 0
+	suite.processor.write(e)
+	suite.False(suite.processor.lost.Load())
+	suite.Equal(uint64(3), suite.processor.revision)
+	suite.Equal(uint64(2), suite.processor.compacted)
+}
 
-	watchID, c, err := suite.processor.NewTaskClient(filter)
+// TestWrite_CreateFail tests the event which cannot be written to the
+// store is still sent to the clients, and compacted so that the
+// clients cannot resume before it.
+func (suite *WatchProcessorTestSuite) TestWrite_CreateFail() {
+	suite.processor.ready = true
+	suite.processor.firstRevision = 1
+	suite.processor.reservedRevision = 10
+
+	_, c, _, err := suite.processor.NewClient(&svc.WatchRequest{
+		PodFilter: &watch.PodFilter{},
+	})
 	suite.NoError(err)
-	suite.NotEmpty(watchID)
-	suite.NotNil(c)
 
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case <-c.Input:
-				mutex.Lock()
-				received++
-				mutex.Unlock()
-			case <-c.Signal:
-				return
+	suite.eventOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errors.New("create fail"))
+	suite.revisionOps.EXPECT().
+		Update(gomock.Any(), uint64(1), uint64(10), uint64(10), uint64(1),
+			false).
+		Return(nil)
+	suite.eventOps.EXPECT().
+		Delete(gomock.Any(), uint64(1), uint64(1)).
+		Return(nil)
+
+	suite.processor.write(&event{
+		response: &svc.WatchResponse{Pods: []*pod.PodSummary{{}}},
+	})
+	suite.Len(c.Input, 1)
+	suite.Equal(uint64(1), suite.processor.compacted)
+}
+
+// TestWrite_Compaction tests the events older than the max history
+// are compacted.
+func (suite *WatchProcessorTestSuite) TestWrite_Compaction() {
+	suite.processor.ready = true
+	suite.processor.firstRevision = 1
+	suite.processor.reservedRevision = 1000
+
+	events := suite.config.MaxHistory + _compactionBatchSize + 1
+	suite.eventOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(int(events))
+	suite.revisionOps.EXPECT().
+		Update(gomock.Any(), uint64(1), uint64(1000), uint64(1000),
+			events-suite.config.MaxHistory, false).
+		Return(nil)
+	suite.eventOps.EXPECT().
+		Delete(gomock.Any(), uint64(1), events-suite.config.MaxHistory).
+		Return(nil)
+
+	for i := uint64(0); i < events; i++ {
+		suite.processor.write(&event{
+			response: &svc.WatchResponse{Pods: []*pod.PodSummary{{}}},
+		})
+	}
+	suite.Equal(events-suite.config.MaxHistory, suite.processor.compacted)
+}
+
+// TestEnqueueOverflow tests that the clients interested in the dropped
+// event are stopped when the event queue is full, and that a revision
+// is burnt before the next event is written.
+func (suite *WatchProcessorTestSuite) TestEnqueueOverflow() {
+	suite.processor.ready = true
+
+	_, c, _, err := suite.processor.NewClient(&svc.WatchRequest{
+		PodFilter: &watch.PodFilter{},
+	})
+	suite.NoError(err)
+	hostWatchID, _, _, err := suite.processor.NewClient(&svc.WatchRequest{
+		HostFilter: &watch.HostFilter{},
+	})
+	suite.NoError(err)
+
+	for i := 0; i < suite.config.EventQueueSize+1; i++ {
+		suite.processor.NotifyTaskChange(&pod.PodSummary{}, nil)
+	}
+	suite.Equal(StopSignalOverflow, <-c.Signal)
+	suite.True(suite.processor.lost.Load())
+
+	// the client which is not interested in the event keeps watching
+	suite.Len(suite.processor.clients, 1)
+	suite.Contains(suite.processor.clients, hostWatchID)
+}
+
+// TestWrite_Batch tests the events queued behind an event are written
+// together, and sent to the clients in order of revision.
+func (suite *WatchProcessorTestSuite) TestWrite_Batch() {
+	suite.processor.ready = true
+	suite.processor.firstRevision = 1
+	suite.processor.reservedRevision = 10
+
+	_, c, _, err := suite.processor.NewClient(&svc.WatchRequest{
+		PodFilter: &watch.PodFilter{},
+	})
+	suite.NoError(err)
+
+	for i := 0; i < 4; i++ {
+		suite.processor.NotifyTaskChange(&pod.PodSummary{}, nil)
+	}
+
+	suite.eventOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			e *svc.WatchResponse,
+			_ []*v0peloton.Label,
+		) error {
+			if e.GetRevision() == 3 {
+				return errors.New("create fail")
 			}
+			return nil
+		}).
+		Times(5)
+	// the events up to the one which cannot be written are compacted
+	suite.revisionOps.EXPECT().
+		Update(gomock.Any(), uint64(1), uint64(10), uint64(10), uint64(3),
+			false).
+		Return(nil)
+	suite.eventOps.EXPECT().
+		Delete(gomock.Any(), uint64(1), uint64(3)).
+		Return(nil)
+
+	e := &event{response: &svc.WatchResponse{Pods: []*pod.PodSummary{{}}}}
+	suite.processor.write(suite.processor.batch(e)...)
+
+	suite.Len(suite.processor.events, 0)
+	suite.Equal(uint64(5), suite.processor.revision)
+	suite.Equal(uint64(3), suite.processor.compacted)
+	suite.Len(c.Input, 5)
+	for i := uint64(1); i <= 5; i++ {
+		suite.Equal(i, (<-c.Input).GetRevision())
+	}
+}
+
+// TestWrite_RevisionsTakenOver tests the events are dropped when the
+// revisions of the leader have been taken over by the next leader.
+func (suite *WatchProcessorTestSuite) TestWrite_RevisionsTakenOver() {
+	suite.processor.ready = true
+	suite.processor.firstRevision = 1
+	suite.processor.reservedRevision = 1
+
+	_, c, _, err := suite.processor.NewClient(&svc.WatchRequest{
+		PodFilter: &watch.PodFilter{},
+	})
+	suite.NoError(err)
+
+	suite.eventOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil)
+	suite.revisionOps.EXPECT().
+		Update(gomock.Any(), uint64(1), uint64(1), uint64(11), uint64(0),
+			false).
+		Return(yarpcerrors.FailedPreconditionErrorf("not applied"))
+	suite.revisionOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*ormobjects.WatchRevisionObject{
+			{FirstRevision: 2, ReservedRevision: 11},
+		}, nil)
+
+	suite.processor.write(
+		&event{response: &svc.WatchResponse{Pods: []*pod.PodSummary{{}}}},
+		&event{response: &svc.WatchResponse{Pods: []*pod.PodSummary{{}}}},
+	)
+	suite.Equal(uint64(1), suite.processor.revision)
+	suite.Equal(uint64(1), suite.processor.reservedRevision)
+	suite.True(suite.processor.lost.Load())
+	suite.Equal(StopSignalOverflow, <-c.Signal)
+}
+
+// TestWrite_ReserveRevisionsAmbiguous tests the revisions are reserved
+// when a previous attempt which failed had reserved them in the store.
+func (suite *WatchProcessorTestSuite) TestWrite_ReserveRevisionsAmbiguous() {
+	suite.processor.ready = true
+	suite.processor.firstRevision = 1
+	suite.processor.reservedRevision = 1
+	suite.processor.revision = 1
+
+	suite.revisionOps.EXPECT().
+		Update(gomock.Any(), uint64(1), uint64(1), uint64(11), uint64(0),
+			false).
+		Return(yarpcerrors.FailedPreconditionErrorf("not applied"))
+	suite.revisionOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*ormobjects.WatchRevisionObject{
+			{FirstRevision: 1, ReservedRevision: 11},
+		}, nil)
+	suite.eventOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	suite.processor.write(
+		&event{response: &svc.WatchResponse{Pods: []*pod.PodSummary{{}}}})
+	suite.Equal(uint64(2), suite.processor.revision)
+	suite.Equal(uint64(11), suite.processor.reservedRevision)
+	suite.False(suite.processor.lost.Load())
+}
+
+// TestRecoverRevisions tests the revisions are recovered after the
+// revisions of the previous leaders, and the revisions of a leader
+// which did not stop cleanly are compacted.
+func (suite *WatchProcessorTestSuite) TestRecoverRevisions() {
+	rows := []*ormobjects.WatchRevisionObject{
+		{
+			FirstRevision:     1,
+			ReservedRevision:  10,
+			CompactedRevision: 3,
+			Clean:             true,
+		},
+		{
+			FirstRevision:     11,
+			ReservedRevision:  30,
+			CompactedRevision: 5,
+			Clean:             false,
+		},
+	}
+
+	gomock.InOrder(
+		suite.revisionOps.EXPECT().
+			GetAll(gomock.Any()).
+			Return(nil, errors.New("get fail")),
+		suite.revisionOps.EXPECT().
+			GetAll(gomock.Any()).
+			Return(rows, nil),
+		suite.revisionOps.EXPECT().
+			Create(gomock.Any(), uint64(31), uint64(40), uint64(5)).
+			Return(nil),
+		suite.revisionOps.EXPECT().
+			Delete(gomock.Any(), uint64(1)).
+			Return(nil),
+		suite.revisionOps.EXPECT().
+			Delete(gomock.Any(), uint64(11)).
+			Return(errors.New("delete fail")),
+		suite.revisionOps.EXPECT().
+			Update(gomock.Any(), uint64(31), uint64(40), uint64(40), uint64(30),
+				false).
+			Return(nil),
+		suite.eventOps.EXPECT().
+			Delete(gomock.Any(), uint64(6), uint64(30)).
+			Return(nil),
+	)
+
+	suite.Error(suite.processor.tryRecoverRevisions())
+	suite.False(suite.processor.ready)
+
+	suite.NoError(suite.processor.tryRecoverRevisions())
+	suite.Equal(uint64(30), suite.processor.revision)
+	suite.Equal(uint64(30), suite.processor.compacted)
+	suite.True(suite.processor.ready)
+}
+
+// TestGetEvents tests the events read from the store are filtered
+// by the request of the client.
+func (suite *WatchProcessorTestSuite) TestGetEvents() {
+	label := &v0peloton.Label{Key: "key1", Value: "value1"}
+	req := &svc.WatchRequest{
+		PodFilter: &watch.PodFilter{
+			Labels: handlerutil.ConvertLabels([]*v0peloton.Label{label}),
+		},
+	}
+
+	var objs []*ormobjects.WatchEventObject
+	for i, labels := range [][]*v0peloton.Label{{label}, nil} {
+		payload, err := proto.Marshal(&svc.WatchResponse{
+			Revision: uint64(i + 5),
+			Pods:     []*pod.PodSummary{{PodName: suite.podName}},
+		})
+		suite.NoError(err)
+		obj := &ormobjects.WatchEventObject{
+			Revision: uint64(i + 5),
+			Payload:  payload,
 		}
-	}()
+		if len(labels) > 0 {
+			obj.Labels = `[{"key":"key1","value":"value1"}]`
+		}
+		objs = append(objs, obj)
+	}
+
+	suite.eventOps.EXPECT().
+		Query(gomock.Any(), uint64(5), uint64(6)).
+		Return(objs, nil)
+
+	resps, err := suite.processor.GetEvents(suite.ctx, "watch-1", req, 5, 6)
+	suite.NoError(err)
+	suite.Len(resps, 1)
+	suite.Equal("watch-1", resps[0].GetWatchId())
+	suite.Equal(uint64(5), resps[0].GetRevision())
+	suite.Equal(suite.podName.GetValue(),
+		resps[0].GetPods()[0].GetPodName().GetValue())
+
+	// nothing to replay
+	resps, err = suite.processor.GetEvents(suite.ctx, "watch-1", req, 7, 6)
+	suite.NoError(err)
+	suite.Empty(resps)
+}
+
+// TestGetEvents_Compacted tests "out-of-range" error is returned if the
+// events were compacted while being read.
+func (suite *WatchProcessorTestSuite) TestGetEvents_Compacted() {
+	suite.processor.compacted = 5
+
+	suite.eventOps.EXPECT().
+		Query(gomock.Any(), uint64(5), uint64(6)).
+		Return(nil, nil)
+
+	_, err := suite.processor.GetEvents(
+		suite.ctx, "watch-1", &svc.WatchRequest{}, 5, 6)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+
+	suite.eventOps.EXPECT().
+		Query(gomock.Any(), uint64(6), uint64(6)).
+		Return(nil, errors.New("query fail"))
+
+	_, err = suite.processor.GetEvents(
+		suite.ctx, "watch-1", &svc.WatchRequest{}, 6, 6)
+	suite.Error(err)
+}
+
+// TestClientPodFilter tests the pod events are filtered by the job id
+// and the pod names of the filter.
+func (suite *WatchProcessorTestSuite) TestClientPodFilter() {
+	suite.start()
+
+	filter := &watch.PodFilter{
+		JobId:    suite.jobID,
+		PodNames: []*peloton.PodName{suite.podName},
+	}
+
+	_, c, _, err := suite.processor.NewClient(&svc.WatchRequest{PodFilter: filter})
+	suite.NoError(err)
+	resps, _ := receive(c)
+
+	suite.eventOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(3)
 
 	suite.processor.NotifyTaskChange(&pod.PodSummary{
 		PodName: suite.podName,
@@ -228,16 +757,15 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodFilter() {
 		PodName: &peloton.PodName{Value: fmt.Sprintf("%s-%d", suite.jobID, 5)},
 	}, nil)
 
-	time.Sleep(1 * time.Second)
-	err = suite.processor.StopTaskClient(watchID)
-	wg.Wait()
-
-	mutex.Lock()
-	suite.Equal(received, 1)
-	mutex.Unlock()
+	suite.stop(true)
+	suite.Len(<-resps, 1)
 }
 
-func (suite *WatchProcessorTestSuite) TestTaskClientPodLabelFilter() {
+// TestClientPodLabelFilter tests the pod events are filtered by the
+// labels of the filter.
+func (suite *WatchProcessorTestSuite) TestClientPodLabelFilter() {
+	suite.start()
+
 	label1 := &v0peloton.Label{
 		Key:   "key1",
 		Value: "value1",
@@ -251,29 +779,14 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodLabelFilter() {
 		Labels: handlerutil.ConvertLabels([]*v0peloton.Label{label1}),
 	}
 
-	var mutex = &sync.Mutex{}
-	var wg sync.WaitGroup
-	wg.Add(1)
-	received := 0
-
-	watchID, c, err := suite.processor.NewTaskClient(filter)
+	_, c, _, err := suite.processor.NewClient(&svc.WatchRequest{PodFilter: filter})
 	suite.NoError(err)
-	suite.NotEmpty(watchID)
-	suite.NotNil(c)
+	resps, _ := receive(c)
 
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case <-c.Input:
-				mutex.Lock()
-				received++
-				mutex.Unlock()
-			case <-c.Signal:
-				return
-			}
-		}
-	}()
+	suite.eventOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(3)
 
 	suite.processor.NotifyTaskChange(
 		&pod.PodSummary{},
@@ -290,11 +803,6 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodLabelFilter() {
 		[]*v0peloton.Label{label1, label2},
 	)
 
-	time.Sleep(1 * time.Second)
-	err = suite.processor.StopTaskClient(watchID)
-	wg.Wait()
-
-	mutex.Lock()
-	suite.Equal(2, received)
-	mutex.Unlock()
+	suite.stop(true)
+	suite.Len(<-resps, 2)
 }
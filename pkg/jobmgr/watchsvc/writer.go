@@ -0,0 +1,471 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchsvc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// timeout of each store operation of the writer
+	_storeTimeout = 10 * time.Second
+	// period between the attempts to recover the revisions
+	_recoverRetryPeriod = 5 * time.Second
+	// number of revisions over the max history after which
+	// the oldest events are compacted
+	_compactionBatchSize uint64 = 100
+	// max number of queued events written to the store together
+	_writeBatchSize = 100
+)
+
+// Start recovers the revisions from the store, and starts writing the
+// events to the store and sending them to the clients.
+func (p *watchProcessor) Start() error {
+	if !p.lifeCycle.Start() {
+		log.Warn("Watch processor is already running, no action will be " +
+			"performed")
+		return nil
+	}
+
+	go func() {
+		defer p.lifeCycle.StopComplete()
+
+		log.Info("Starting watch processor")
+		if !p.recoverRevisions() {
+			return
+		}
+
+		for {
+			select {
+			case <-p.lifeCycle.StopCh():
+				p.flush()
+				return
+			case e := <-p.events:
+				p.write(p.batch(e)...)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop writes the pending events to the store, and stops all the
+// clients.
+func (p *watchProcessor) Stop() error {
+	if !p.lifeCycle.Stop() {
+		log.Warn("Watch processor is already stopped, no action will be " +
+			"performed")
+		return nil
+	}
+
+	log.Info("Stopping watch processor")
+
+	// Wait for the pending events to be written
+	p.lifeCycle.Wait()
+
+	p.Lock()
+	p.ready = false
+	p.Unlock()
+	p.StopClients()
+
+	log.Info("Watch processor stopped")
+	return nil
+}
+
+// recoverRevisions reserves the first block of revisions of this leader
+// after the revisions reserved by the previous leaders, retrying until
+// it succeeds or the processor is stopped. If the previous leader did
+// not stop cleanly, its events may have been lost, so its revisions are
+// compacted. Returns false if the processor is stopped.
+func (p *watchProcessor) recoverRevisions() bool {
+	for {
+		err := p.tryRecoverRevisions()
+		if err == nil {
+			return true
+		}
+
+		log.WithError(err).Warn("failed to recover watch revisions")
+		select {
+		case <-p.lifeCycle.StopCh():
+			return false
+		case <-time.After(_recoverRetryPeriod):
+		}
+	}
+}
+
+func (p *watchProcessor) tryRecoverRevisions() error {
+	ctx, cancel := context.WithTimeout(context.Background(), _storeTimeout)
+	defer cancel()
+
+	rows, err := p.revisionOps.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reserved, compacted uint64
+	clean := true
+	for _, row := range rows {
+		if row.ReservedRevision > reserved {
+			reserved = row.ReservedRevision
+		}
+		if row.CompactedRevision > compacted {
+			compacted = row.CompactedRevision
+		}
+	}
+	if len(rows) > 0 {
+		clean = rows[len(rows)-1].Clean
+	}
+
+	// another leader recovering the revisions concurrently
+	// reserves the same block, so only one of them succeeds
+	first := reserved + 1
+	last := reserved + p.blockSize
+	if err := p.revisionOps.Create(ctx, first, last, compacted); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if err := p.revisionOps.Delete(ctx, row.FirstRevision); err != nil {
+			log.WithError(err).
+				WithField("first_revision", row.FirstRevision).
+				Warn("failed to delete watch revisions of previous leader")
+		}
+	}
+
+	p.firstRevision = first
+	p.reservedRevision = last
+
+	p.Lock()
+	p.revision = reserved
+	p.compacted = compacted
+	p.ready = true
+	p.Unlock()
+	p.metrics.Revision.Update(float64(reserved))
+
+	log.WithFields(log.Fields{
+		"revision":           reserved,
+		"compacted_revision": compacted,
+		"reserved_revision":  last,
+		"clean":              clean,
+	}).Info("watch revisions recovered")
+
+	if !clean {
+		p.compact(reserved)
+	}
+	return nil
+}
+
+// batch returns the event along with the events queued behind it,
+// up to the write batch size
+func (p *watchProcessor) batch(e *event) []*event {
+	events := []*event{e}
+	for len(events) < _writeBatchSize {
+		select {
+		case e := <-p.events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+	return events
+}
+
+// write writes a batch of events to the store with the next revisions,
+// and sends them to the clients which are interested in them.
+func (p *watchProcessor) write(events ...*event) {
+	// Events have been dropped since the last event was written, burn
+	// a revision so that the clients cannot resume after the last
+	// event and silently miss the dropped events.
+	if p.lost.Swap(false) {
+		revision, err := p.nextRevision(p.revision)
+		if err != nil {
+			p.drop(events...)
+			return
+		}
+		p.Lock()
+		p.revision = revision
+		p.Unlock()
+		p.compact(revision)
+	}
+
+	last := p.revision
+	for i, e := range events {
+		revision, err := p.nextRevision(last)
+		if err != nil {
+			p.drop(events[i:]...)
+			events = events[:i]
+			break
+		}
+		e.response.Revision = revision
+		last = revision
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	failed := p.persist(events)
+
+	for _, e := range events {
+		p.dispatch(e)
+	}
+
+	switch {
+	case failed > 0:
+		// The events cannot be replayed, so the clients
+		// cannot resume before them
+		p.compact(failed)
+	case last > p.compacted+p.maxHistory+_compactionBatchSize:
+		p.compact(last - p.maxHistory)
+	}
+}
+
+// persist writes a batch of numbered events to the store concurrently.
+// Returns the highest revision of the events which could not be
+// written, or 0 if all the events have been written.
+func (p *watchProcessor) persist(events []*event) uint64 {
+	ctx, cancel := context.WithTimeout(context.Background(), _storeTimeout)
+	defer cancel()
+
+	errs := make([]error, len(events))
+	var wg sync.WaitGroup
+	for i, e := range events {
+		wg.Add(1)
+		go func(i int, e *event) {
+			defer wg.Done()
+			errs[i] = p.eventOps.Create(ctx, e.response, e.labels)
+		}(i, e)
+	}
+	wg.Wait()
+
+	var failed uint64
+	for i, err := range errs {
+		revision := events[i].response.GetRevision()
+		if err != nil {
+			log.WithError(err).
+				WithField("revision", revision).
+				Warn("failed to write watch event")
+			p.metrics.EventWriteFail.Inc(1)
+			failed = revision
+			continue
+		}
+		p.metrics.EventWrite.Inc(1)
+	}
+	return failed
+}
+
+// drop drops events which cannot be numbered. The clients which are
+// interested in them would silently miss them, so these clients are
+// stopped, while the other clients keep watching.
+func (p *watchProcessor) drop(events ...*event) {
+	p.metrics.EventDropped.Inc(int64(len(events)))
+	p.lost.Store(true)
+
+	p.Lock()
+	defer p.Unlock()
+	for watchID, c := range p.clients {
+		for _, e := range events {
+			if matchEvent(c.Request, e) {
+				p.stopClient(watchID, StopSignalOverflow)
+				break
+			}
+		}
+	}
+}
+
+// nextRevision returns the revision following the last one, reserving
+// a new block of revisions in the store if needed. The block is only
+// reserved if the revisions of this leader have not been taken over
+// by the next leader.
+func (p *watchProcessor) nextRevision(last uint64) (uint64, error) {
+	revision := last + 1
+	if revision <= p.reservedRevision {
+		return revision, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), _storeTimeout)
+	defer cancel()
+
+	reserved := p.reservedRevision + p.blockSize
+	err := p.revisionOps.Update(
+		ctx,
+		p.firstRevision,
+		p.reservedRevision,
+		reserved,
+		p.compacted,
+		false,
+	)
+	if yarpcerrors.IsFailedPrecondition(err) && p.isReserved(ctx, reserved) {
+		// a previous attempt which timed out had reserved the block
+		err = nil
+	}
+	if err != nil {
+		log.WithError(err).
+			WithField("reserved_revision", reserved).
+			Warn("failed to reserve watch revisions")
+		p.metrics.RevisionReserveFail.Inc(1)
+		return 0, err
+	}
+
+	p.reservedRevision = reserved
+	return revision, nil
+}
+
+// isReserved returns true if the revisions of this leader
+// up to reserved are reserved in the store
+func (p *watchProcessor) isReserved(
+	ctx context.Context,
+	reserved uint64,
+) bool {
+	rows, err := p.revisionOps.GetAll(ctx)
+	if err != nil {
+		return false
+	}
+	for _, row := range rows {
+		if row.FirstRevision == p.firstRevision {
+			return row.ReservedRevision == reserved
+		}
+	}
+	return false
+}
+
+// compact removes the events of the revisions up to target from the
+// store, after which the clients can no longer resume from them.
+func (p *watchProcessor) compact(target uint64) {
+	p.Lock()
+	if target <= p.compacted {
+		p.Unlock()
+		return
+	}
+	from := p.compacted + 1
+	p.compacted = target
+	p.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), _storeTimeout)
+	defer cancel()
+
+	// persist the compacted revision first, so that the next leader
+	// does not allow resuming from the removed events
+	if err := p.revisionOps.Update(
+		ctx,
+		p.firstRevision,
+		p.reservedRevision,
+		p.reservedRevision,
+		target,
+		false,
+	); err != nil {
+		log.WithError(err).
+			WithField("compacted_revision", target).
+			Warn("failed to write watch compacted revision")
+		p.metrics.CompactionFail.Inc(1)
+		return
+	}
+
+	if err := p.eventOps.Delete(ctx, from, target); err != nil {
+		log.WithError(err).
+			WithFields(log.Fields{
+				"from": from,
+				"to":   target,
+			}).
+			Warn("failed to compact watch events")
+		p.metrics.CompactionFail.Inc(1)
+		return
+	}
+
+	p.metrics.Compaction.Inc(1)
+}
+
+// flush writes the events left in the queue, and records in the store
+// whether all the events of this leader have been written.
+func (p *watchProcessor) flush() {
+	for {
+		select {
+		case e := <-p.events:
+			p.write(p.batch(e)...)
+			continue
+		default:
+		}
+		break
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), _storeTimeout)
+	defer cancel()
+
+	if err := p.revisionOps.Update(
+		ctx,
+		p.firstRevision,
+		p.reservedRevision,
+		p.reservedRevision,
+		p.compacted,
+		!p.lost.Load(),
+	); err != nil {
+		log.WithError(err).Warn("failed to write watch revisions on stop")
+	}
+}
+
+// GetEvents returns the events of the revisions in [from, to] which
+// match the filters of the request of a client, read from the store.
+func (p *watchProcessor) GetEvents(
+	ctx context.Context,
+	watchID string,
+	req *svc.WatchRequest,
+	from uint64,
+	to uint64,
+) ([]*svc.WatchResponse, error) {
+	if from > to {
+		return nil, nil
+	}
+
+	objs, err := p.eventOps.Query(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// the events may have been compacted while being read
+	p.Lock()
+	compacted := p.compacted
+	p.Unlock()
+	if from <= compacted {
+		p.metrics.WatchOutOfRange.Inc(1)
+		return nil, yarpcerrors.OutOfRangeErrorf(
+			"revision %d has been compacted, compacted revision is %d",
+			from, compacted)
+	}
+
+	var responses []*svc.WatchResponse
+	for _, obj := range objs {
+		response, err := obj.GetEvent()
+		if err != nil {
+			return nil, err
+		}
+		labels, err := obj.GetLabels()
+		if err != nil {
+			return nil, err
+		}
+
+		e := &event{response: response, labels: labels}
+		if !matchEvent(req, e) {
+			continue
+		}
+		responses = append(responses, newResponse(watchID, response))
+	}
+	return responses, nil
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"time"
+)
+
+// Config is workflow controller specific config
+type Config struct {
+	// ReconcilePeriod is the period to check the batch jobs of the
+	// running nodes of the workflows, in case the terminal state of a
+	// batch job was missed, e.g. while no job manager was leader
+	ReconcilePeriod time.Duration `yaml:"reconcile_period"`
+
+	// CleanupPeriod is the period to delete the workflows
+	// which completed longer than Retention ago
+	CleanupPeriod time.Duration `yaml:"cleanup_period"`
+
+	// Retention is how long the completed workflows are kept
+	Retention time.Duration `yaml:"retention"`
+}
@@ -0,0 +1,786 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	pbworkflow "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
+	"github.com/uber/peloton/pkg/storage"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gocql/gocql"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// timeout for the calls made to reconcile a workflow
+	_defaultReconcileTimeout = 30 * time.Second
+
+	// separator of the workflow and node names in the default job name
+	_jobNameSeparator = "."
+
+	// creator of the workflows created by unauthenticated calls
+	_defaultCreatedBy = "peloton"
+
+	_nodeSkippedMessage   = "Dependency on node %s is not satisfied"
+	_nodeCancelledMessage = "Cancelled with the workflow"
+	_jobNotFoundMessage   = "Batch job of the node not found"
+	_jobTerminalMessage   = "Batch job of the node is %s"
+	_jobKilledMessage     = "Killed by the cancellation of the workflow"
+)
+
+// Controller runs the batch jobs of the workflows. The batch job of a node
+// is created once the batch jobs of the nodes it depends on reach a
+// terminal state which satisfies its dependencies. The controller is
+// notified of the terminal batch jobs by the goal state engine.
+type Controller interface {
+	goalstate.JobTerminalListener
+
+	// Create validates and stores a new workflow, and creates the batch
+	// jobs of the nodes without dependencies. It returns the ID of the
+	// new workflow.
+	Create(
+		ctx context.Context,
+		spec *pbworkflow.WorkflowSpec,
+	) (*pbworkflow.WorkflowID, error)
+
+	// Cancel kills the batch jobs of the running nodes of a workflow,
+	// and cancels its pending nodes.
+	Cancel(ctx context.Context, id *pbworkflow.WorkflowID) error
+
+	// Retry runs again the failed nodes of a failed workflow, and
+	// the nodes which depend on them directly or transitively.
+	Retry(ctx context.Context, id *pbworkflow.WorkflowID) error
+
+	// Reconcile advances all the running workflows from the states
+	// of the batch jobs of their running nodes.
+	Reconcile()
+
+	// Cleanup deletes the workflows which completed
+	// longer than the retention period ago.
+	Cleanup()
+}
+
+// controller implements the Controller interface
+type controller struct {
+	// serializes the changes of the workflows, and protects jobIndex
+	mu sync.Mutex
+
+	jobStore        storage.JobStore
+	workflowOps     ormobjects.JobWorkflowOps
+	jobFactory      cached.JobFactory
+	goalStateDriver goalstate.Driver
+	respoolClient   respool.ResourceManagerYARPCClient
+	metrics         *Metrics
+
+	// retention period of the completed workflows
+	retention time.Duration
+
+	// ID of the workflow of the batch job of each running node,
+	// keyed by job ID, filled by the reconciliations
+	jobIndex map[string]string
+}
+
+// workflowInfo is the spec and status of a workflow, and the user
+// who created it, who also creates the batch jobs of its nodes
+type workflowInfo struct {
+	spec      *pbworkflow.WorkflowSpec
+	status    *pbworkflow.WorkflowStatus
+	createdBy string
+}
+
+// New creates a workflow controller
+func New(
+	d *yarpc.Dispatcher,
+	jobStore storage.JobStore,
+	ormStore *ormobjects.Store,
+	jobFactory cached.JobFactory,
+	goalStateDriver goalstate.Driver,
+	cfg *Config,
+	parent tally.Scope,
+) Controller {
+	return &controller{
+		jobStore:        jobStore,
+		workflowOps:     ormobjects.NewJobWorkflowOps(ormStore),
+		jobFactory:      jobFactory,
+		goalStateDriver: goalStateDriver,
+		respoolClient: respool.NewResourceManagerYARPCClient(
+			d.ClientConfig(common.PelotonResourceManager)),
+		metrics:   NewMetrics(parent.SubScope("jobmgr").SubScope("workflow")),
+		retention: cfg.Retention,
+		jobIndex:  make(map[string]string),
+	}
+}
+
+// Create stores a new workflow and runs its nodes without dependencies
+func (c *controller) Create(
+	ctx context.Context,
+	spec *pbworkflow.WorkflowSpec,
+) (*pbworkflow.WorkflowID, error) {
+	if err := ValidateSpec(spec); err != nil {
+		c.metrics.WorkflowCreateFail.Inc(1)
+		return nil, err
+	}
+
+	id := &pbworkflow.WorkflowID{Value: uuid.New()}
+	status := &pbworkflow.WorkflowStatus{
+		State: pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+	}
+	for _, node := range spec.GetNodes() {
+		status.Nodes = append(status.Nodes, &pbworkflow.NodeStatus{
+			Name:  node.GetName(),
+			State: pbworkflow.NodeState_NODE_STATE_PENDING,
+		})
+	}
+
+	// the batch jobs of the nodes are created on behalf of the
+	// user who created the workflow
+	createdBy := auth.GetUsername(ctx)
+	if len(createdBy) == 0 {
+		createdBy = _defaultCreatedBy
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.workflowOps.Create(ctx, id, spec, status, createdBy); err != nil {
+		c.metrics.WorkflowCreateFail.Inc(1)
+		return nil, err
+	}
+	c.metrics.WorkflowCreate.Inc(1)
+
+	log.WithFields(log.Fields{
+		"workflow_id": id.GetValue(),
+		"name":        spec.GetName(),
+	}).Info("workflow created")
+
+	// the workflow is stored, so a failure to run its first nodes
+	// is retried by the next reconciliation
+	w := &workflowInfo{spec: spec, status: status, createdBy: createdBy}
+	if err := c.advanceWorkflow(ctx, id, w); err != nil {
+		log.WithError(err).
+			WithField("workflow_id", id.GetValue()).
+			Warn("failed to run the nodes of the new workflow")
+		c.metrics.WorkflowAdvanceFail.Inc(1)
+	}
+	return id, nil
+}
+
+// Cancel cancels a running workflow
+func (c *controller) Cancel(
+	ctx context.Context,
+	id *pbworkflow.WorkflowID,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w, err := c.getWorkflow(ctx, id)
+	if err != nil {
+		return err
+	}
+	status := w.status
+	if status.GetState() != pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING {
+		return yarpcerrors.FailedPreconditionErrorf(
+			"workflow %s is not running", id.GetValue())
+	}
+
+	now := formatTime(time.Now())
+	for _, node := range status.GetNodes() {
+		switch node.GetState() {
+		case pbworkflow.NodeState_NODE_STATE_RUNNING:
+			// cancelling again kills the remaining jobs if
+			// killing one of them fails
+			if err := c.killJob(ctx, node.GetJobId()); err != nil {
+				c.metrics.JobKillFail.Inc(1)
+				return err
+			}
+		case pbworkflow.NodeState_NODE_STATE_PENDING:
+		default:
+			continue
+		}
+		node.State = pbworkflow.NodeState_NODE_STATE_CANCELLED
+		node.Message = _nodeCancelledMessage
+		node.CompletionTime = now
+	}
+	status.State = pbworkflow.WorkflowState_WORKFLOW_STATE_CANCELLED
+	status.CompletionTime = now
+
+	if err := c.updateStatus(ctx, id, status); err != nil {
+		return err
+	}
+
+	log.WithField("workflow_id", id.GetValue()).Info("workflow cancelled")
+	c.metrics.WorkflowCancelled.Inc(1)
+	return nil
+}
+
+// Retry runs again the failed nodes of a failed workflow
+func (c *controller) Retry(
+	ctx context.Context,
+	id *pbworkflow.WorkflowID,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w, err := c.getWorkflow(ctx, id)
+	if err != nil {
+		return err
+	}
+	spec, status := w.spec, w.status
+	if status.GetState() != pbworkflow.WorkflowState_WORKFLOW_STATE_FAILED {
+		return yarpcerrors.FailedPreconditionErrorf(
+			"workflow %s has not failed", id.GetValue())
+	}
+
+	failed := make(map[string]bool)
+	for _, node := range status.GetNodes() {
+		if node.GetState() == pbworkflow.NodeState_NODE_STATE_FAILED {
+			failed[node.GetName()] = true
+		}
+	}
+
+	// the attempts are kept, so that the batch jobs of
+	// the new attempts get new job IDs
+	reset := getDownstreamNodes(spec, failed)
+	for _, node := range status.GetNodes() {
+		if !reset[node.GetName()] {
+			continue
+		}
+		node.State = pbworkflow.NodeState_NODE_STATE_PENDING
+		node.JobId = nil
+		node.StartTime = ""
+		node.CompletionTime = ""
+		node.Message = ""
+	}
+	status.State = pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING
+	status.CompletionTime = ""
+
+	if err := c.updateStatus(ctx, id, status); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"workflow_id": id.GetValue(),
+		"nodes":       len(reset),
+	}).Info("workflow retried")
+	c.metrics.WorkflowRetried.Inc(1)
+
+	if err := c.advanceWorkflow(ctx, id, w); err != nil {
+		log.WithError(err).
+			WithField("workflow_id", id.GetValue()).
+			Warn("failed to run the nodes of the retried workflow")
+		c.metrics.WorkflowAdvanceFail.Inc(1)
+	}
+	return nil
+}
+
+// JobTerminated advances the workflow of a terminal batch job
+// if the job was created for a node of a workflow
+func (c *controller) JobTerminated(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	state job.JobState,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	workflowID, ok := c.jobIndex[jobID.GetValue()]
+	if !ok {
+		return nil
+	}
+
+	id := &pbworkflow.WorkflowID{Value: workflowID}
+	w, err := c.getWorkflow(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"workflow_id": workflowID,
+		"job_id":      jobID.GetValue(),
+		"state":       state.String(),
+	}).Debug("batch job of workflow node terminated")
+
+	if err := c.advanceWorkflow(ctx, id, w); err != nil {
+		c.metrics.WorkflowAdvanceFail.Inc(1)
+		return err
+	}
+	return nil
+}
+
+// Reconcile advances all the running workflows
+func (c *controller) Reconcile() {
+	// the running workflows are read without holding the lock, which is
+	// held only while advancing each workflow, so that the terminal
+	// batch jobs and the API calls are not blocked by the reconciliation
+	ctx, cancelFunc := context.WithTimeout(
+		context.Background(),
+		_defaultReconcileTimeout)
+	ids, err := c.workflowOps.GetRunning(ctx)
+	cancelFunc()
+	if err != nil {
+		log.WithError(err).
+			Error("failed to get workflows to reconcile")
+		c.metrics.ReconcileFail.Inc(1)
+		return
+	}
+
+	for _, id := range ids {
+		ctx, cancelFunc := context.WithTimeout(
+			context.Background(),
+			_defaultReconcileTimeout)
+		err := c.reconcileWorkflow(ctx, id)
+		cancelFunc()
+		if err != nil {
+			log.WithError(err).
+				WithField("workflow_id", id.GetValue()).
+				Error("failed to reconcile workflow")
+			c.metrics.WorkflowAdvanceFail.Inc(1)
+		}
+	}
+	c.metrics.Reconcile.Inc(1)
+}
+
+// reconcileWorkflow advances a running workflow
+func (c *controller) reconcileWorkflow(
+	ctx context.Context,
+	id *pbworkflow.WorkflowID,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w, err := c.getWorkflow(ctx, id)
+	if err == gocql.ErrNotFound {
+		// the workflow was indexed, but failed to be stored
+		return c.workflowOps.Delete(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if w.status.GetState() != pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING {
+		// the workflow completed, but failed to be removed from the
+		// running workflows, which storing its status again does
+		return c.updateStatus(ctx, id, w.status)
+	}
+	return c.advanceWorkflow(ctx, id, w)
+}
+
+// Cleanup deletes the workflows which completed
+// longer than the retention period ago
+func (c *controller) Cleanup() {
+	ctx, cancelFunc := context.WithTimeout(
+		context.Background(),
+		_defaultReconcileTimeout)
+	objs, err := c.workflowOps.GetAll(ctx)
+	cancelFunc()
+	if err != nil {
+		log.WithError(err).
+			Error("failed to get workflows to clean up")
+		c.metrics.CleanupFail.Inc(1)
+		return
+	}
+
+	for _, obj := range objs {
+		if time.Since(obj.UpdateTime) < c.retention {
+			continue
+		}
+
+		id := &pbworkflow.WorkflowID{Value: obj.WorkflowID}
+		ctx, cancelFunc := context.WithTimeout(
+			context.Background(),
+			_defaultReconcileTimeout)
+		err := c.deleteWorkflow(ctx, id)
+		cancelFunc()
+		if err != nil {
+			log.WithError(err).
+				WithField("workflow_id", obj.WorkflowID).
+				Error("failed to delete workflow")
+			c.metrics.WorkflowDeleteFail.Inc(1)
+		}
+	}
+	c.metrics.Cleanup.Inc(1)
+}
+
+// deleteWorkflow deletes a workflow if it is completed and its status
+// was not updated during the retention period
+func (c *controller) deleteWorkflow(
+	ctx context.Context,
+	id *pbworkflow.WorkflowID,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the workflow is read again, since it may have
+	// been retried since the workflows were read
+	obj, err := c.workflowOps.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	status, err := obj.GetStatus()
+	if err != nil {
+		return err
+	}
+	if status.GetState() == pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING ||
+		time.Since(obj.UpdateTime) < c.retention {
+		return nil
+	}
+
+	if err := c.workflowOps.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	log.WithField("workflow_id", id.GetValue()).Info("workflow deleted")
+	c.metrics.WorkflowDeleted.Inc(1)
+	return nil
+}
+
+// getWorkflow returns the spec, the status and the creator of a workflow
+func (c *controller) getWorkflow(
+	ctx context.Context,
+	id *pbworkflow.WorkflowID,
+) (*workflowInfo, error) {
+	obj, err := c.workflowOps.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := obj.GetSpec()
+	if err != nil {
+		return nil, err
+	}
+	status, err := obj.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	return &workflowInfo{
+		spec:      spec,
+		status:    status,
+		createdBy: obj.CreatedBy,
+	}, nil
+}
+
+// advanceWorkflow updates the running nodes of a running workflow from
+// the states of their batch jobs, runs the pending nodes whose
+// dependencies are satisfied, skips the pending nodes whose dependencies
+// can no longer be satisfied, and stores the status if it changed
+func (c *controller) advanceWorkflow(
+	ctx context.Context,
+	id *pbworkflow.WorkflowID,
+	w *workflowInfo,
+) error {
+	spec, status := w.spec, w.status
+	if status.GetState() != pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING {
+		return nil
+	}
+
+	statuses := make(map[string]*pbworkflow.NodeStatus)
+	for _, node := range status.GetNodes() {
+		statuses[node.GetName()] = node
+	}
+
+	changed := false
+	for _, node := range status.GetNodes() {
+		if node.GetState() != pbworkflow.NodeState_NODE_STATE_RUNNING {
+			continue
+		}
+		updated, err := c.updateRunningNode(ctx, node)
+		if err != nil {
+			c.indexWorkflow(id, status)
+			return err
+		}
+		changed = changed || updated
+	}
+
+	// a skipped node or a node whose job cannot be created may resolve
+	// the dependencies of other nodes, so the nodes are evaluated until
+	// no pending node changes
+	for progressed := true; progressed; {
+		progressed = false
+		for _, nodeSpec := range spec.GetNodes() {
+			node := statuses[nodeSpec.GetName()]
+			if node.GetState() != pbworkflow.NodeState_NODE_STATE_PENDING {
+				continue
+			}
+
+			satisfied, unsatisfied := evaluateDependencies(nodeSpec, statuses)
+			switch {
+			case len(unsatisfied) != 0:
+				node.State = pbworkflow.NodeState_NODE_STATE_SKIPPED
+				node.Message = fmt.Sprintf(_nodeSkippedMessage, unsatisfied)
+				node.CompletionTime = formatTime(time.Now())
+				c.metrics.NodeSkipped.Inc(1)
+			case satisfied:
+				c.runNode(ctx, id, w, nodeSpec, node)
+			default:
+				continue
+			}
+			progressed = true
+			changed = true
+		}
+	}
+
+	state := getWorkflowState(spec, statuses)
+	if state != status.GetState() {
+		status.State = state
+		status.CompletionTime = formatTime(time.Now())
+		changed = true
+
+		log.WithFields(log.Fields{
+			"workflow_id": id.GetValue(),
+			"state":       state.String(),
+		}).Info("workflow completed")
+		if state == pbworkflow.WorkflowState_WORKFLOW_STATE_SUCCEEDED {
+			c.metrics.WorkflowSucceeded.Inc(1)
+		} else {
+			c.metrics.WorkflowFailed.Inc(1)
+		}
+	}
+
+	if !changed {
+		c.indexWorkflow(id, status)
+		return nil
+	}
+	return c.updateStatus(ctx, id, status)
+}
+
+// updateRunningNode updates a running node from the state of its batch
+// job, and returns true if the batch job reached a terminal state
+func (c *controller) updateRunningNode(
+	ctx context.Context,
+	node *pbworkflow.NodeStatus,
+) (bool, error) {
+	runtime, err := c.jobStore.GetJobRuntime(ctx, node.GetJobId().GetValue())
+	if err != nil && !yarpcerrors.IsNotFound(err) {
+		return false, err
+	}
+
+	switch {
+	case err != nil:
+		node.State = pbworkflow.NodeState_NODE_STATE_FAILED
+		node.Message = _jobNotFoundMessage
+	case !util.IsPelotonJobStateTerminal(runtime.GetState()):
+		return false, nil
+	case runtime.GetState() == job.JobState_SUCCEEDED:
+		node.State = pbworkflow.NodeState_NODE_STATE_SUCCEEDED
+		node.Message = fmt.Sprintf(_jobTerminalMessage, runtime.GetState())
+	default:
+		node.State = pbworkflow.NodeState_NODE_STATE_FAILED
+		node.Message = fmt.Sprintf(_jobTerminalMessage, runtime.GetState())
+	}
+	node.CompletionTime = formatTime(time.Now())
+
+	if node.GetState() == pbworkflow.NodeState_NODE_STATE_SUCCEEDED {
+		c.metrics.NodeSucceeded.Inc(1)
+	} else {
+		c.metrics.NodeFailed.Inc(1)
+	}
+	return true, nil
+}
+
+// runNode creates the batch job of a new attempt of a node. The node
+// fails if the batch job cannot be created.
+func (c *controller) runNode(
+	ctx context.Context,
+	id *pbworkflow.WorkflowID,
+	w *workflowInfo,
+	nodeSpec *pbworkflow.NodeSpec,
+	node *pbworkflow.NodeStatus,
+) {
+	node.Attempts++
+	jobID := getNodeJobID(id, node.GetName(), node.GetAttempts())
+	jobSpec := proto.Clone(nodeSpec.GetJobSpec()).(*stateless.JobSpec)
+	if len(jobSpec.GetName()) == 0 {
+		jobSpec.Name = w.spec.GetName() + _jobNameSeparator + node.GetName()
+	}
+
+	now := formatTime(time.Now())
+	if err := c.createJob(ctx, jobID, jobSpec, w.createdBy); err != nil {
+		log.WithError(err).
+			WithFields(log.Fields{
+				"workflow_id": id.GetValue(),
+				"node":        node.GetName(),
+			}).Warn("failed to create batch job of workflow node")
+		c.metrics.NodeLaunchFail.Inc(1)
+		node.State = pbworkflow.NodeState_NODE_STATE_FAILED
+		node.Message = err.Error()
+		node.CompletionTime = now
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"workflow_id": id.GetValue(),
+		"node":        node.GetName(),
+		"attempt":     node.GetAttempts(),
+		"job_id":      jobID.GetValue(),
+	}).Info("created batch job of workflow node")
+	c.metrics.NodeLaunched.Inc(1)
+	node.State = pbworkflow.NodeState_NODE_STATE_RUNNING
+	node.JobId = &v1alphapeloton.JobID{Value: jobID.GetValue()}
+	node.StartTime = now
+	node.Message = ""
+}
+
+// getNodeJobID returns the ID of the batch job of an attempt of a node.
+// The ID is derived from the workflow, node and attempt, so that the
+// batch job is not created twice if storing the workflow status fails.
+func getNodeJobID(
+	id *pbworkflow.WorkflowID,
+	name string,
+	attempt uint32,
+) *peloton.JobID {
+	return &peloton.JobID{
+		Value: uuid.NewSHA1(
+			uuid.Parse(id.GetValue()),
+			[]byte(fmt.Sprintf("%s-%d", name, attempt)),
+		).String(),
+	}
+}
+
+// createJob creates the batch job of a node on behalf of the
+// creator of the workflow, unless the batch job already exists
+func (c *controller) createJob(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	jobSpec *stateless.JobSpec,
+	createdBy string,
+) error {
+	_, err := c.jobStore.GetJobRuntime(ctx, jobID.GetValue())
+	if err == nil {
+		return nil
+	}
+	if !yarpcerrors.IsNotFound(err) {
+		return err
+	}
+
+	jobConfig, err := handlerutil.ConvertJobSpecToJobConfig(jobSpec)
+	if err != nil {
+		return err
+	}
+	jobConfig.Type = job.JobType_BATCH
+
+	respoolPath, err := c.getResourcePoolPath(ctx, jobConfig.GetRespoolID())
+	if err != nil {
+		return err
+	}
+
+	cachedJob := c.jobFactory.AddJob(jobID)
+	configAddOn := &models.ConfigAddOn{
+		SystemLabels: jobutil.ConstructSystemLabels(
+			jobConfig,
+			respoolPath.GetValue()),
+	}
+	err = cachedJob.Create(ctx, jobConfig, configAddOn, createdBy)
+	// if err is not nil, still enqueue to goal state engine,
+	// because job may be partially created. Goal state engine
+	// knows if the job can be recovered
+	c.goalStateDriver.EnqueueJob(jobID, time.Now())
+	return err
+}
+
+// getResourcePoolPath returns the path of the resource pool of a job
+func (c *controller) getResourcePoolPath(
+	ctx context.Context,
+	respoolID *peloton.ResourcePoolID,
+) (*respool.ResourcePoolPath, error) {
+	resp, err := c.respoolClient.GetResourcePool(
+		ctx,
+		&respool.GetRequest{Id: respoolID})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.GetError() != nil || resp.GetPoolinfo() == nil {
+		return nil, fmt.Errorf(
+			"resource pool %s not found", respoolID.GetValue())
+	}
+	return resp.GetPoolinfo().GetPath(), nil
+}
+
+// killJob sets the goal state of the batch job of a node to KILLED
+func (c *controller) killJob(
+	ctx context.Context,
+	jobID *v1alphapeloton.JobID,
+) error {
+	pelotonJobID := &peloton.JobID{Value: jobID.GetValue()}
+	cachedJob := c.jobFactory.AddJob(pelotonJobID)
+
+	killed, err := jobutil.KillJob(ctx, cachedJob, _jobKilledMessage)
+	if err != nil {
+		return err
+	}
+	if killed {
+		c.goalStateDriver.EnqueueJob(pelotonJobID, time.Now())
+	}
+	return nil
+}
+
+// updateStatus stores the status of a workflow, and indexes
+// the batch jobs of its running nodes
+func (c *controller) updateStatus(
+	ctx context.Context,
+	id *pbworkflow.WorkflowID,
+	status *pbworkflow.WorkflowStatus,
+) error {
+	// the batch jobs are indexed even if the status is not stored,
+	// since they may have been created
+	c.indexWorkflow(id, status)
+	return c.workflowOps.UpdateStatus(ctx, id, status)
+}
+
+// indexWorkflow updates the workflow of the batch jobs of the nodes
+func (c *controller) indexWorkflow(
+	id *pbworkflow.WorkflowID,
+	status *pbworkflow.WorkflowStatus,
+) {
+	for _, node := range status.GetNodes() {
+		jobID := node.GetJobId().GetValue()
+		if len(jobID) == 0 {
+			continue
+		}
+		if node.GetState() == pbworkflow.NodeState_NODE_STATE_RUNNING {
+			c.jobIndex[jobID] = id.GetValue()
+		} else {
+			delete(c.jobIndex, jobID)
+		}
+	}
+}
+
+// formatTime returns the time in RFC3339 form with UTC timezone
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,743 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	pbworkflow "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
+	storage_mocks "github.com/uber/peloton/pkg/storage/mocks"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_testWorkflowName = "test-workflow"
+	_testRespoolID    = "respool-1"
+	_testUser         = "user1"
+	_testRetention    = 24 * time.Hour
+)
+
+type WorkflowControllerTestSuite struct {
+	suite.Suite
+
+	ctrl            *gomock.Controller
+	controller      *controller
+	mockJobStore    *storage_mocks.MockJobStore
+	workflowOps     *objectmocks.MockJobWorkflowOps
+	jobFactory      *cachedmocks.MockJobFactory
+	goalStateDriver *goalstatemocks.MockDriver
+	respoolClient   *respoolmocks.MockResourceManagerYARPCClient
+
+	workflowID *pbworkflow.WorkflowID
+	cachedJob  *cachedmocks.MockJob
+}
+
+func TestWorkflowController(t *testing.T) {
+	suite.Run(t, new(WorkflowControllerTestSuite))
+}
+
+func (suite *WorkflowControllerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.mockJobStore = storage_mocks.NewMockJobStore(suite.ctrl)
+	suite.workflowOps = objectmocks.NewMockJobWorkflowOps(suite.ctrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.ctrl)
+	suite.respoolClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.controller = &controller{
+		jobStore:        suite.mockJobStore,
+		workflowOps:     suite.workflowOps,
+		jobFactory:      suite.jobFactory,
+		goalStateDriver: suite.goalStateDriver,
+		respoolClient:   suite.respoolClient,
+		metrics:         NewMetrics(tally.NoopScope),
+		retention:       _testRetention,
+		jobIndex:        make(map[string]string),
+	}
+
+	suite.workflowID = &pbworkflow.WorkflowID{Value: uuid.New()}
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+}
+
+func (suite *WorkflowControllerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// createSpec returns a workflow spec where node b runs on the success
+// of node a, and node cleanup runs on the failure of node a
+func (suite *WorkflowControllerTestSuite) createSpec() *pbworkflow.WorkflowSpec {
+	jobSpec := &stateless.JobSpec{
+		InstanceCount: 2,
+		RespoolId:     &v1alphapeloton.ResourcePoolID{Value: _testRespoolID},
+	}
+	return &pbworkflow.WorkflowSpec{
+		Name: _testWorkflowName,
+		Nodes: []*pbworkflow.NodeSpec{
+			{Name: "a", JobSpec: jobSpec},
+			{
+				Name:    "b",
+				JobSpec: jobSpec,
+				Dependencies: []*pbworkflow.Dependency{
+					{Node: "a", Condition: _onSuccess},
+				},
+			},
+			{
+				Name:    "cleanup",
+				JobSpec: jobSpec,
+				Dependencies: []*pbworkflow.Dependency{
+					{Node: "a", Condition: _onFailure},
+				},
+			},
+		},
+	}
+}
+
+// createStatus returns a workflow status with the nodes in the given
+// states, and the other nodes pending. The nodes which are not pending
+// have the job of their first attempt.
+func (suite *WorkflowControllerTestSuite) createStatus(
+	state pbworkflow.WorkflowState,
+	nodeStates map[string]pbworkflow.NodeState,
+) *pbworkflow.WorkflowStatus {
+	status := &pbworkflow.WorkflowStatus{State: state}
+	for _, name := range []string{"a", "b", "cleanup"} {
+		node := &pbworkflow.NodeStatus{
+			Name:  name,
+			State: pbworkflow.NodeState_NODE_STATE_PENDING,
+		}
+		if state, ok := nodeStates[name]; ok {
+			node.State = state
+		}
+		if node.GetState() != pbworkflow.NodeState_NODE_STATE_PENDING {
+			node.Attempts = 1
+			node.JobId = &v1alphapeloton.JobID{
+				Value: suite.jobID(name, 1).GetValue(),
+			}
+		}
+		status.Nodes = append(status.Nodes, node)
+	}
+	return status
+}
+
+// createWorkflow returns a workflow object with the spec and status
+func (suite *WorkflowControllerTestSuite) createWorkflow(
+	status *pbworkflow.WorkflowStatus,
+) *ormobjects.JobWorkflowObject {
+	specBuffer, err := proto.Marshal(suite.createSpec())
+	suite.NoError(err)
+	statusBuffer, err := proto.Marshal(status)
+	suite.NoError(err)
+
+	return &ormobjects.JobWorkflowObject{
+		WorkflowID:   suite.workflowID.GetValue(),
+		Name:         _testWorkflowName,
+		Spec:         specBuffer,
+		Status:       statusBuffer,
+		CreationTime: time.Now().Add(-time.Hour),
+		UpdateTime:   time.Now(),
+		CreatedBy:    _testUser,
+	}
+}
+
+// jobID returns the ID of the batch job of an attempt of a node
+func (suite *WorkflowControllerTestSuite) jobID(
+	name string,
+	attempt uint32,
+) *peloton.JobID {
+	return getNodeJobID(suite.workflowID, name, attempt)
+}
+
+// expectJobState sets up the state of the batch job of a node
+func (suite *WorkflowControllerTestSuite) expectJobState(
+	name string,
+	state job.JobState,
+) {
+	suite.mockJobStore.EXPECT().
+		GetJobRuntime(gomock.Any(), suite.jobID(name, 1).GetValue()).
+		Return(&job.RuntimeInfo{State: state}, nil)
+}
+
+// expectJobCreate sets up the creation of the batch job of a node
+func (suite *WorkflowControllerTestSuite) expectJobCreate(
+	name string,
+	attempt uint32,
+	createErr error,
+) {
+	jobID := suite.jobID(name, attempt)
+	suite.mockJobStore.EXPECT().
+		GetJobRuntime(gomock.Any(), jobID.GetValue()).
+		Return(nil, yarpcerrors.NotFoundErrorf("job not found"))
+
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{
+			Id: &peloton.ResourcePoolID{Value: _testRespoolID},
+		}).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Id:   &peloton.ResourcePoolID{Value: _testRespoolID},
+				Path: &respool.ResourcePoolPath{Value: "/respool-1"},
+			},
+		}, nil)
+
+	suite.jobFactory.EXPECT().
+		AddJob(jobID).
+		Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any(), _testUser).
+		Do(func(
+			_ context.Context,
+			config *job.JobConfig,
+			configAddOn *models.ConfigAddOn,
+			_ string) {
+			suite.Equal(job.JobType_BATCH, config.GetType())
+			suite.Equal(_testWorkflowName+"."+name, config.GetName())
+			suite.Equal(uint32(2), config.GetInstanceCount())
+			suite.NotEmpty(configAddOn.GetSystemLabels())
+		}).
+		Return(createErr)
+
+	suite.goalStateDriver.EXPECT().
+		EnqueueJob(jobID, gomock.Any())
+}
+
+// expectUpdateStatus sets up the update of the workflow status, and
+// checks the states of the workflow and of its nodes
+func (suite *WorkflowControllerTestSuite) expectUpdateStatus(
+	state pbworkflow.WorkflowState,
+	nodeStates map[string]pbworkflow.NodeState,
+) *gomock.Call {
+	return suite.workflowOps.EXPECT().
+		UpdateStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(
+			_ context.Context,
+			id *pbworkflow.WorkflowID,
+			status *pbworkflow.WorkflowStatus) {
+			suite.Equal(suite.workflowID, id)
+			suite.Equal(state, status.GetState())
+			for _, node := range status.GetNodes() {
+				suite.Equal(nodeStates[node.GetName()], node.GetState(),
+					node.GetName())
+			}
+		}).
+		Return(nil)
+}
+
+// TestCreate tests creating a workflow, which runs the nodes
+// without dependencies on behalf of the calling user
+func (suite *WorkflowControllerTestSuite) TestCreate() {
+	user := authmocks.NewMockUser(suite.ctrl)
+	user.EXPECT().Username().Return(_testUser)
+	ctx := auth.WithUser(context.Background(), user, "CreateWorkflow")
+
+	var id *pbworkflow.WorkflowID
+	suite.workflowOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), _testUser).
+		Do(func(
+			_ context.Context,
+			workflowID *pbworkflow.WorkflowID,
+			_ *pbworkflow.WorkflowSpec,
+			status *pbworkflow.WorkflowStatus,
+			_ string) {
+			suite.Equal(
+				pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+				status.GetState())
+			suite.Len(status.GetNodes(), 3)
+			id = workflowID
+			suite.workflowID = workflowID
+		}).
+		Return(nil)
+
+	// the job of node a is created once the workflow ID is known
+	suite.mockJobStore.EXPECT().
+		GetJobRuntime(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.NotFoundErrorf("job not found"))
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), gomock.Any()).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Path: &respool.ResourcePoolPath{Value: "/respool-1"},
+			},
+		}, nil)
+	suite.jobFactory.EXPECT().
+		AddJob(gomock.Any()).
+		Do(func(jobID *peloton.JobID) {
+			suite.Equal(suite.jobID("a", 1), jobID)
+		}).
+		Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any(), _testUser).
+		Return(nil)
+	suite.goalStateDriver.EXPECT().
+		EnqueueJob(gomock.Any(), gomock.Any())
+	suite.expectUpdateStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		map[string]pbworkflow.NodeState{
+			"a":       pbworkflow.NodeState_NODE_STATE_RUNNING,
+			"b":       pbworkflow.NodeState_NODE_STATE_PENDING,
+			"cleanup": pbworkflow.NodeState_NODE_STATE_PENDING,
+		})
+
+	workflowID, err := suite.controller.Create(ctx, suite.createSpec())
+	suite.NoError(err)
+	suite.Equal(id, workflowID)
+	suite.Equal(
+		workflowID.GetValue(),
+		suite.controller.jobIndex[suite.jobID("a", 1).GetValue()])
+}
+
+// TestCreateInvalidSpec tests that invalid workflows are not stored
+func (suite *WorkflowControllerTestSuite) TestCreateInvalidSpec() {
+	_, err := suite.controller.Create(
+		context.Background(),
+		&pbworkflow.WorkflowSpec{Name: _testWorkflowName})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestCreateStoreFail tests the failure to store a new workflow, which
+// is created by the default user if the call is not authenticated
+func (suite *WorkflowControllerTestSuite) TestCreateStoreFail() {
+	suite.workflowOps.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+			_defaultCreatedBy).
+		Return(errors.New("test error"))
+
+	_, err := suite.controller.Create(context.Background(), suite.createSpec())
+	suite.Error(err)
+}
+
+// TestJobTerminatedSucceeded tests running the nodes
+// which depend on the success of a succeeded node
+func (suite *WorkflowControllerTestSuite) TestJobTerminatedSucceeded() {
+	status := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		map[string]pbworkflow.NodeState{
+			"a": pbworkflow.NodeState_NODE_STATE_RUNNING,
+		})
+	suite.controller.indexWorkflow(suite.workflowID, status)
+
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(suite.createWorkflow(status), nil)
+	suite.expectJobState("a", job.JobState_SUCCEEDED)
+	suite.expectJobCreate("b", 1, nil)
+	suite.expectUpdateStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		map[string]pbworkflow.NodeState{
+			"a":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			"b":       pbworkflow.NodeState_NODE_STATE_RUNNING,
+			"cleanup": pbworkflow.NodeState_NODE_STATE_SKIPPED,
+		})
+
+	suite.NoError(suite.controller.JobTerminated(
+		context.Background(), suite.jobID("a", 1), job.JobState_SUCCEEDED))
+	suite.NotContains(suite.controller.jobIndex, suite.jobID("a", 1).GetValue())
+	suite.Contains(suite.controller.jobIndex, suite.jobID("b", 1).GetValue())
+}
+
+// TestJobTerminatedFailed tests running the nodes which depend on the
+// failure of a failed node, and the completion of the workflow
+func (suite *WorkflowControllerTestSuite) TestJobTerminatedFailed() {
+	status := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		map[string]pbworkflow.NodeState{
+			"a": pbworkflow.NodeState_NODE_STATE_RUNNING,
+		})
+	suite.controller.indexWorkflow(suite.workflowID, status)
+
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(suite.createWorkflow(status), nil)
+	suite.expectJobState("a", job.JobState_FAILED)
+	suite.expectJobCreate("cleanup", 1, errors.New("test error"))
+	suite.expectUpdateStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_FAILED,
+		map[string]pbworkflow.NodeState{
+			"a":       pbworkflow.NodeState_NODE_STATE_FAILED,
+			"b":       pbworkflow.NodeState_NODE_STATE_SKIPPED,
+			"cleanup": pbworkflow.NodeState_NODE_STATE_FAILED,
+		})
+
+	suite.NoError(suite.controller.JobTerminated(
+		context.Background(), suite.jobID("a", 1), job.JobState_FAILED))
+	suite.Empty(suite.controller.jobIndex)
+}
+
+// TestJobTerminatedNotWorkflowJob tests that the jobs
+// not created for a workflow are ignored
+func (suite *WorkflowControllerTestSuite) TestJobTerminatedNotWorkflowJob() {
+	suite.NoError(suite.controller.JobTerminated(
+		context.Background(),
+		&peloton.JobID{Value: uuid.New()},
+		job.JobState_SUCCEEDED))
+}
+
+// TestJobTerminatedGetFail tests that the failure to read
+// the workflow is returned so that the notification is retried
+func (suite *WorkflowControllerTestSuite) TestJobTerminatedGetFail() {
+	status := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		map[string]pbworkflow.NodeState{
+			"a": pbworkflow.NodeState_NODE_STATE_RUNNING,
+		})
+	suite.controller.indexWorkflow(suite.workflowID, status)
+
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(nil, errors.New("test error"))
+
+	suite.Error(suite.controller.JobTerminated(
+		context.Background(), suite.jobID("a", 1), job.JobState_SUCCEEDED))
+}
+
+// TestCancel tests killing the running jobs of a workflow,
+// and cancelling its nodes
+func (suite *WorkflowControllerTestSuite) TestCancel() {
+	status := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		map[string]pbworkflow.NodeState{
+			"a": pbworkflow.NodeState_NODE_STATE_RUNNING,
+		})
+	jobID := suite.jobID("a", 1)
+
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(suite.createWorkflow(status), nil)
+	gomock.InOrder(
+		suite.jobFactory.EXPECT().
+			AddJob(jobID).
+			Return(suite.cachedJob),
+		suite.cachedJob.EXPECT().
+			GetRuntime(gomock.Any()).
+			Return(&job.RuntimeInfo{
+				State:     job.JobState_RUNNING,
+				GoalState: job.JobState_SUCCEEDED,
+			}, nil),
+		suite.cachedJob.EXPECT().
+			CompareAndSetRuntime(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, runtime *job.RuntimeInfo) {
+				suite.Equal(job.JobState_KILLED, runtime.GetGoalState())
+			}).
+			Return(&job.RuntimeInfo{}, nil),
+		suite.goalStateDriver.EXPECT().
+			EnqueueJob(jobID, gomock.Any()),
+	)
+	suite.expectUpdateStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_CANCELLED,
+		map[string]pbworkflow.NodeState{
+			"a":       pbworkflow.NodeState_NODE_STATE_CANCELLED,
+			"b":       pbworkflow.NodeState_NODE_STATE_CANCELLED,
+			"cleanup": pbworkflow.NodeState_NODE_STATE_CANCELLED,
+		})
+
+	suite.NoError(suite.controller.Cancel(context.Background(), suite.workflowID))
+}
+
+// TestCancelKillFail tests that the workflow is not cancelled
+// if one of its running jobs cannot be killed
+func (suite *WorkflowControllerTestSuite) TestCancelKillFail() {
+	status := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		map[string]pbworkflow.NodeState{
+			"a": pbworkflow.NodeState_NODE_STATE_RUNNING,
+		})
+
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(suite.createWorkflow(status), nil)
+	suite.jobFactory.EXPECT().
+		AddJob(suite.jobID("a", 1)).
+		Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	suite.Error(suite.controller.Cancel(context.Background(), suite.workflowID))
+}
+
+// TestCancelNotRunning tests that only running workflows can be cancelled
+func (suite *WorkflowControllerTestSuite) TestCancelNotRunning() {
+	status := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_SUCCEEDED,
+		map[string]pbworkflow.NodeState{
+			"a":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			"b":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			"cleanup": pbworkflow.NodeState_NODE_STATE_SKIPPED,
+		})
+
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(suite.createWorkflow(status), nil)
+
+	err := suite.controller.Cancel(context.Background(), suite.workflowID)
+	suite.True(yarpcerrors.IsFailedPrecondition(err))
+}
+
+// TestRetry tests running again the failed nodes of a failed
+// workflow and the nodes which depend on them
+func (suite *WorkflowControllerTestSuite) TestRetry() {
+	status := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_FAILED,
+		map[string]pbworkflow.NodeState{
+			"a":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			"b":       pbworkflow.NodeState_NODE_STATE_FAILED,
+			"cleanup": pbworkflow.NodeState_NODE_STATE_SKIPPED,
+		})
+
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(suite.createWorkflow(status), nil)
+	gomock.InOrder(
+		suite.expectUpdateStatus(
+			pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+			map[string]pbworkflow.NodeState{
+				"a":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+				"b":       pbworkflow.NodeState_NODE_STATE_PENDING,
+				"cleanup": pbworkflow.NodeState_NODE_STATE_SKIPPED,
+			}),
+		suite.expectUpdateStatus(
+			pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+			map[string]pbworkflow.NodeState{
+				"a":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+				"b":       pbworkflow.NodeState_NODE_STATE_RUNNING,
+				"cleanup": pbworkflow.NodeState_NODE_STATE_SKIPPED,
+			}),
+	)
+	// the second attempt of node b gets a new job
+	suite.expectJobCreate("b", 2, nil)
+
+	suite.NoError(suite.controller.Retry(context.Background(), suite.workflowID))
+	suite.Contains(suite.controller.jobIndex, suite.jobID("b", 2).GetValue())
+}
+
+// TestRetryNotFailed tests that only failed workflows can be retried
+func (suite *WorkflowControllerTestSuite) TestRetryNotFailed() {
+	status := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		map[string]pbworkflow.NodeState{
+			"a": pbworkflow.NodeState_NODE_STATE_RUNNING,
+		})
+
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(suite.createWorkflow(status), nil)
+
+	err := suite.controller.Retry(context.Background(), suite.workflowID)
+	suite.True(yarpcerrors.IsFailedPrecondition(err))
+}
+
+// TestReconcile tests advancing the running workflows
+// and indexing their jobs
+func (suite *WorkflowControllerTestSuite) TestReconcile() {
+	running := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		map[string]pbworkflow.NodeState{
+			"a": pbworkflow.NodeState_NODE_STATE_RUNNING,
+		})
+
+	suite.workflowOps.EXPECT().
+		GetRunning(gomock.Any()).
+		Return([]*pbworkflow.WorkflowID{suite.workflowID}, nil)
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(suite.createWorkflow(running), nil)
+	// the job of node a is still running, so the workflow is not updated
+	suite.expectJobState("a", job.JobState_RUNNING)
+
+	suite.controller.Reconcile()
+	suite.Equal(map[string]string{
+		suite.jobID("a", 1).GetValue(): suite.workflowID.GetValue(),
+	}, suite.controller.jobIndex)
+}
+
+// TestReconcileCompleted tests that a completed workflow which is
+// still indexed as running is removed from the running workflows
+func (suite *WorkflowControllerTestSuite) TestReconcileCompleted() {
+	succeeded := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_SUCCEEDED,
+		map[string]pbworkflow.NodeState{
+			"a":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			"b":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			"cleanup": pbworkflow.NodeState_NODE_STATE_SKIPPED,
+		})
+
+	suite.workflowOps.EXPECT().
+		GetRunning(gomock.Any()).
+		Return([]*pbworkflow.WorkflowID{suite.workflowID}, nil)
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(suite.createWorkflow(succeeded), nil)
+	suite.expectUpdateStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_SUCCEEDED,
+		map[string]pbworkflow.NodeState{
+			"a":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			"b":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			"cleanup": pbworkflow.NodeState_NODE_STATE_SKIPPED,
+		})
+
+	suite.controller.Reconcile()
+	suite.Empty(suite.controller.jobIndex)
+}
+
+// TestReconcileNotStored tests that a running workflow
+// which failed to be stored is removed
+func (suite *WorkflowControllerTestSuite) TestReconcileNotStored() {
+	suite.workflowOps.EXPECT().
+		GetRunning(gomock.Any()).
+		Return([]*pbworkflow.WorkflowID{suite.workflowID}, nil)
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(nil, gocql.ErrNotFound)
+	suite.workflowOps.EXPECT().
+		Delete(gomock.Any(), suite.workflowID).
+		Return(nil)
+
+	suite.controller.Reconcile()
+}
+
+// TestReconcileJobNotFound tests that a running node fails
+// if its batch job does not exist
+func (suite *WorkflowControllerTestSuite) TestReconcileJobNotFound() {
+	status := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		map[string]pbworkflow.NodeState{
+			"a": pbworkflow.NodeState_NODE_STATE_RUNNING,
+		})
+
+	suite.workflowOps.EXPECT().
+		GetRunning(gomock.Any()).
+		Return([]*pbworkflow.WorkflowID{suite.workflowID}, nil)
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(suite.createWorkflow(status), nil)
+	suite.mockJobStore.EXPECT().
+		GetJobRuntime(gomock.Any(), suite.jobID("a", 1).GetValue()).
+		Return(nil, yarpcerrors.NotFoundErrorf("job not found"))
+	suite.expectJobCreate("cleanup", 1, nil)
+	suite.expectUpdateStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		map[string]pbworkflow.NodeState{
+			"a":       pbworkflow.NodeState_NODE_STATE_FAILED,
+			"b":       pbworkflow.NodeState_NODE_STATE_SKIPPED,
+			"cleanup": pbworkflow.NodeState_NODE_STATE_RUNNING,
+		})
+
+	suite.controller.Reconcile()
+}
+
+// TestReconcileGetRunningFail tests the failure to read the workflows
+func (suite *WorkflowControllerTestSuite) TestReconcileGetRunningFail() {
+	suite.controller.jobIndex[uuid.New()] = suite.workflowID.GetValue()
+	suite.workflowOps.EXPECT().
+		GetRunning(gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	suite.controller.Reconcile()
+	// the index is kept until the workflows can be read
+	suite.Len(suite.controller.jobIndex, 1)
+}
+
+// TestCleanup tests deleting the workflows which
+// completed longer than the retention period ago
+func (suite *WorkflowControllerTestSuite) TestCleanup() {
+	succeeded := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_SUCCEEDED,
+		map[string]pbworkflow.NodeState{
+			"a":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			"b":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			"cleanup": pbworkflow.NodeState_NODE_STATE_SKIPPED,
+		})
+	running := suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		map[string]pbworkflow.NodeState{
+			"a": pbworkflow.NodeState_NODE_STATE_RUNNING,
+		})
+
+	expired := suite.createWorkflow(succeeded)
+	expired.UpdateTime = time.Now().Add(-2 * _testRetention)
+	recent := suite.createWorkflow(succeeded)
+	recent.WorkflowID = uuid.New()
+	longRunning := suite.createWorkflow(running)
+	longRunning.WorkflowID = uuid.New()
+	longRunning.UpdateTime = time.Now().Add(-2 * _testRetention)
+	longRunningID := &pbworkflow.WorkflowID{Value: longRunning.WorkflowID}
+
+	suite.workflowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*ormobjects.JobWorkflowObject{
+			expired,
+			recent,
+			longRunning,
+		}, nil)
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(expired, nil)
+	suite.workflowOps.EXPECT().
+		Delete(gomock.Any(), suite.workflowID).
+		Return(nil)
+	// the running workflows are kept
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), longRunningID).
+		Return(longRunning, nil)
+
+	suite.controller.Cleanup()
+}
+
+// TestCleanupFail tests the failure to read the workflows
+// to clean up, and to delete a workflow
+func (suite *WorkflowControllerTestSuite) TestCleanupFail() {
+	expired := suite.createWorkflow(suite.createStatus(
+		pbworkflow.WorkflowState_WORKFLOW_STATE_CANCELLED, nil))
+	expired.UpdateTime = time.Now().Add(-2 * _testRetention)
+
+	suite.workflowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, errors.New("test error"))
+	suite.controller.Cleanup()
+
+	suite.workflowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*ormobjects.JobWorkflowObject{expired}, nil)
+	suite.workflowOps.EXPECT().
+		Get(gomock.Any(), suite.workflowID).
+		Return(expired, nil)
+	suite.workflowOps.EXPECT().
+		Delete(gomock.Any(), suite.workflowID).
+		Return(errors.New("test error"))
+	suite.controller.Cleanup()
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	pbworkflow "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// ValidateSpec validates the nodes of a workflow spec, and checks that
+// the dependencies between the nodes form a directed acyclic graph
+func ValidateSpec(spec *pbworkflow.WorkflowSpec) error {
+	if len(spec.GetName()) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf("workflow name is empty")
+	}
+	if len(spec.GetNodes()) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf("workflow has no nodes")
+	}
+
+	nodes := make(map[string]*pbworkflow.NodeSpec)
+	for _, node := range spec.GetNodes() {
+		if len(node.GetName()) == 0 {
+			return yarpcerrors.InvalidArgumentErrorf("node name is empty")
+		}
+		if _, ok := nodes[node.GetName()]; ok {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"duplicate node %s", node.GetName())
+		}
+		if node.GetJobSpec() == nil {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"node %s has no job spec", node.GetName())
+		}
+		nodes[node.GetName()] = node
+	}
+
+	for _, node := range spec.GetNodes() {
+		upstreams := make(map[string]bool)
+		for _, dependency := range node.GetDependencies() {
+			upstream := dependency.GetNode()
+			if _, ok := nodes[upstream]; !ok {
+				return yarpcerrors.InvalidArgumentErrorf(
+					"node %s depends on unknown node %s",
+					node.GetName(), upstream)
+			}
+			if upstreams[upstream] {
+				return yarpcerrors.InvalidArgumentErrorf(
+					"node %s depends on node %s more than once",
+					node.GetName(), upstream)
+			}
+			if dependency.GetCondition() ==
+				pbworkflow.DependencyCondition_DEPENDENCY_CONDITION_INVALID {
+				return yarpcerrors.InvalidArgumentErrorf(
+					"dependency of node %s on node %s has no condition",
+					node.GetName(), upstream)
+			}
+			upstreams[upstream] = true
+		}
+	}
+
+	if cycle := findCycle(spec); len(cycle) != 0 {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"dependencies of node %s form a cycle", cycle)
+	}
+	return nil
+}
+
+// findCycle returns the name of a node which depends on itself
+// directly or transitively, or an empty string if there is none
+func findCycle(spec *pbworkflow.WorkflowSpec) string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	nodes := make(map[string]*pbworkflow.NodeSpec)
+	for _, node := range spec.GetNodes() {
+		nodes[node.GetName()] = node
+	}
+
+	states := make(map[string]int)
+	var visit func(name string) string
+	visit = func(name string) string {
+		switch states[name] {
+		case visiting:
+			return name
+		case visited:
+			return ""
+		}
+
+		states[name] = visiting
+		for _, dependency := range nodes[name].GetDependencies() {
+			if cycle := visit(dependency.GetNode()); len(cycle) != 0 {
+				return cycle
+			}
+		}
+		states[name] = visited
+		return ""
+	}
+
+	for _, node := range spec.GetNodes() {
+		if cycle := visit(node.GetName()); len(cycle) != 0 {
+			return cycle
+		}
+	}
+	return ""
+}
+
+// evaluateDependencies returns whether all the dependencies of a node are
+// satisfied, and the name of the first upstream node of a dependency
+// which can no longer be satisfied, if any
+func evaluateDependencies(
+	node *pbworkflow.NodeSpec,
+	statuses map[string]*pbworkflow.NodeStatus,
+) (bool, string) {
+	satisfied := true
+	for _, dependency := range node.GetDependencies() {
+		switch isDependencySatisfied(
+			dependency.GetCondition(),
+			statuses[dependency.GetNode()].GetState()) {
+		case _dependencyPending:
+			satisfied = false
+		case _dependencyUnsatisfied:
+			return false, dependency.GetNode()
+		}
+	}
+	return satisfied, ""
+}
+
+// dependencyResult is the result of the evaluation of a dependency
+type dependencyResult int
+
+const (
+	// the upstream node is not done yet
+	_dependencyPending dependencyResult = iota
+	// the outcome of the upstream node satisfies the dependency
+	_dependencySatisfied
+	// the outcome of the upstream node does not satisfy the dependency
+	_dependencyUnsatisfied
+)
+
+// isDependencySatisfied evaluates a dependency on an upstream node
+// from the condition of the dependency and the state of the node
+func isDependencySatisfied(
+	condition pbworkflow.DependencyCondition,
+	upstream pbworkflow.NodeState,
+) dependencyResult {
+	var satisfied bool
+	switch upstream {
+	case pbworkflow.NodeState_NODE_STATE_SUCCEEDED:
+		satisfied = condition ==
+			pbworkflow.DependencyCondition_DEPENDENCY_CONDITION_ON_SUCCESS
+	case pbworkflow.NodeState_NODE_STATE_FAILED:
+		satisfied = condition ==
+			pbworkflow.DependencyCondition_DEPENDENCY_CONDITION_ON_FAILURE
+	case pbworkflow.NodeState_NODE_STATE_SKIPPED,
+		pbworkflow.NodeState_NODE_STATE_CANCELLED:
+		satisfied = false
+	default:
+		return _dependencyPending
+	}
+
+	if satisfied ||
+		condition == pbworkflow.DependencyCondition_DEPENDENCY_CONDITION_ALWAYS {
+		return _dependencySatisfied
+	}
+	return _dependencyUnsatisfied
+}
+
+// getWorkflowState returns the state of a workflow from the states of
+// its nodes. A failed node fails the workflow unless a node which
+// depends on its failure was run.
+func getWorkflowState(
+	spec *pbworkflow.WorkflowSpec,
+	statuses map[string]*pbworkflow.NodeStatus,
+) pbworkflow.WorkflowState {
+	handled := make(map[string]bool)
+	for _, node := range spec.GetNodes() {
+		state := statuses[node.GetName()].GetState()
+		if state == pbworkflow.NodeState_NODE_STATE_SKIPPED ||
+			state == pbworkflow.NodeState_NODE_STATE_CANCELLED {
+			continue
+		}
+		for _, dependency := range node.GetDependencies() {
+			if dependency.GetCondition() ==
+				pbworkflow.DependencyCondition_DEPENDENCY_CONDITION_ON_FAILURE {
+				handled[dependency.GetNode()] = true
+			}
+		}
+	}
+
+	failed := false
+	for _, node := range spec.GetNodes() {
+		switch statuses[node.GetName()].GetState() {
+		case pbworkflow.NodeState_NODE_STATE_PENDING,
+			pbworkflow.NodeState_NODE_STATE_RUNNING:
+			return pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING
+		case pbworkflow.NodeState_NODE_STATE_FAILED:
+			if !handled[node.GetName()] {
+				failed = true
+			}
+		}
+	}
+
+	if failed {
+		return pbworkflow.WorkflowState_WORKFLOW_STATE_FAILED
+	}
+	return pbworkflow.WorkflowState_WORKFLOW_STATE_SUCCEEDED
+}
+
+// getDownstreamNodes returns the nodes, and all the nodes which
+// depend on them directly or transitively
+func getDownstreamNodes(
+	spec *pbworkflow.WorkflowSpec,
+	nodes map[string]bool,
+) map[string]bool {
+	downstream := make(map[string]bool)
+	for name := range nodes {
+		downstream[name] = true
+	}
+
+	// the nodes are visited until no more downstream node is found,
+	// which converges since the dependencies form no cycle
+	for found := true; found; {
+		found = false
+		for _, node := range spec.GetNodes() {
+			if downstream[node.GetName()] {
+				continue
+			}
+			for _, dependency := range node.GetDependencies() {
+				if downstream[dependency.GetNode()] {
+					downstream[node.GetName()] = true
+					found = true
+					break
+				}
+			}
+		}
+	}
+	return downstream
+}
@@ -0,0 +1,348 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	pbworkflow "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_onSuccess = pbworkflow.DependencyCondition_DEPENDENCY_CONDITION_ON_SUCCESS
+	_onFailure = pbworkflow.DependencyCondition_DEPENDENCY_CONDITION_ON_FAILURE
+	_always    = pbworkflow.DependencyCondition_DEPENDENCY_CONDITION_ALWAYS
+)
+
+// newNode returns a node spec depending on the given nodes
+func newNode(
+	name string,
+	dependencies map[string]pbworkflow.DependencyCondition,
+) *pbworkflow.NodeSpec {
+	node := &pbworkflow.NodeSpec{
+		Name:    name,
+		JobSpec: &stateless.JobSpec{InstanceCount: 1},
+	}
+	for upstream, condition := range dependencies {
+		node.Dependencies = append(node.Dependencies, &pbworkflow.Dependency{
+			Node:      upstream,
+			Condition: condition,
+		})
+	}
+	return node
+}
+
+// newStatuses returns the node statuses in the given states
+func newStatuses(
+	states map[string]pbworkflow.NodeState,
+) map[string]*pbworkflow.NodeStatus {
+	statuses := make(map[string]*pbworkflow.NodeStatus)
+	for name, state := range states {
+		statuses[name] = &pbworkflow.NodeStatus{Name: name, State: state}
+	}
+	return statuses
+}
+
+// TestValidateSpec tests the validation of workflow specs
+func TestValidateSpec(t *testing.T) {
+	tests := []struct {
+		spec  *pbworkflow.WorkflowSpec
+		valid bool
+	}{
+		{
+			spec: &pbworkflow.WorkflowSpec{
+				Name: "wf",
+				Nodes: []*pbworkflow.NodeSpec{
+					newNode("a", nil),
+					newNode("b", map[string]pbworkflow.DependencyCondition{"a": _onSuccess}),
+					newNode("c", map[string]pbworkflow.DependencyCondition{
+						"a": _onFailure,
+						"b": _always,
+					}),
+				},
+			},
+			valid: true,
+		},
+		{
+			// no name
+			spec: &pbworkflow.WorkflowSpec{
+				Nodes: []*pbworkflow.NodeSpec{newNode("a", nil)},
+			},
+		},
+		{
+			// no nodes
+			spec: &pbworkflow.WorkflowSpec{Name: "wf"},
+		},
+		{
+			// node without name
+			spec: &pbworkflow.WorkflowSpec{
+				Name:  "wf",
+				Nodes: []*pbworkflow.NodeSpec{newNode("", nil)},
+			},
+		},
+		{
+			// duplicate node
+			spec: &pbworkflow.WorkflowSpec{
+				Name:  "wf",
+				Nodes: []*pbworkflow.NodeSpec{newNode("a", nil), newNode("a", nil)},
+			},
+		},
+		{
+			// node without job spec
+			spec: &pbworkflow.WorkflowSpec{
+				Name:  "wf",
+				Nodes: []*pbworkflow.NodeSpec{{Name: "a"}},
+			},
+		},
+		{
+			// unknown dependency
+			spec: &pbworkflow.WorkflowSpec{
+				Name: "wf",
+				Nodes: []*pbworkflow.NodeSpec{
+					newNode("a", map[string]pbworkflow.DependencyCondition{"b": _always}),
+				},
+			},
+		},
+		{
+			// dependency without condition
+			spec: &pbworkflow.WorkflowSpec{
+				Name: "wf",
+				Nodes: []*pbworkflow.NodeSpec{
+					newNode("a", nil),
+					newNode("b", map[string]pbworkflow.DependencyCondition{
+						"a": pbworkflow.DependencyCondition_DEPENDENCY_CONDITION_INVALID,
+					}),
+				},
+			},
+		},
+		{
+			// cycle
+			spec: &pbworkflow.WorkflowSpec{
+				Name: "wf",
+				Nodes: []*pbworkflow.NodeSpec{
+					newNode("a", map[string]pbworkflow.DependencyCondition{"c": _onSuccess}),
+					newNode("b", map[string]pbworkflow.DependencyCondition{"a": _onSuccess}),
+					newNode("c", map[string]pbworkflow.DependencyCondition{"b": _onSuccess}),
+				},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		err := ValidateSpec(test.spec)
+		if test.valid {
+			assert.NoError(t, err, "test %d", i)
+		} else {
+			assert.True(t, yarpcerrors.IsInvalidArgument(err), "test %d", i)
+		}
+	}
+}
+
+// TestValidateSpecDuplicateDependency tests that a node
+// cannot depend on another node more than once
+func TestValidateSpecDuplicateDependency(t *testing.T) {
+	b := newNode("b", nil)
+	b.Dependencies = []*pbworkflow.Dependency{
+		{Node: "a", Condition: _onSuccess},
+		{Node: "a", Condition: _onFailure},
+	}
+	err := ValidateSpec(&pbworkflow.WorkflowSpec{
+		Name:  "wf",
+		Nodes: []*pbworkflow.NodeSpec{newNode("a", nil), b},
+	})
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestIsDependencySatisfied tests the evaluation of
+// each dependency condition for each upstream state
+func TestIsDependencySatisfied(t *testing.T) {
+	tests := []struct {
+		upstream pbworkflow.NodeState
+		results  map[pbworkflow.DependencyCondition]dependencyResult
+	}{
+		{
+			upstream: pbworkflow.NodeState_NODE_STATE_PENDING,
+			results: map[pbworkflow.DependencyCondition]dependencyResult{
+				_onSuccess: _dependencyPending,
+				_onFailure: _dependencyPending,
+				_always:    _dependencyPending,
+			},
+		},
+		{
+			upstream: pbworkflow.NodeState_NODE_STATE_RUNNING,
+			results: map[pbworkflow.DependencyCondition]dependencyResult{
+				_onSuccess: _dependencyPending,
+				_onFailure: _dependencyPending,
+				_always:    _dependencyPending,
+			},
+		},
+		{
+			upstream: pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			results: map[pbworkflow.DependencyCondition]dependencyResult{
+				_onSuccess: _dependencySatisfied,
+				_onFailure: _dependencyUnsatisfied,
+				_always:    _dependencySatisfied,
+			},
+		},
+		{
+			upstream: pbworkflow.NodeState_NODE_STATE_FAILED,
+			results: map[pbworkflow.DependencyCondition]dependencyResult{
+				_onSuccess: _dependencyUnsatisfied,
+				_onFailure: _dependencySatisfied,
+				_always:    _dependencySatisfied,
+			},
+		},
+		{
+			upstream: pbworkflow.NodeState_NODE_STATE_SKIPPED,
+			results: map[pbworkflow.DependencyCondition]dependencyResult{
+				_onSuccess: _dependencyUnsatisfied,
+				_onFailure: _dependencyUnsatisfied,
+				_always:    _dependencySatisfied,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		for condition, result := range test.results {
+			assert.Equal(t, result,
+				isDependencySatisfied(condition, test.upstream),
+				"%s %s", test.upstream, condition)
+		}
+	}
+}
+
+// TestEvaluateDependencies tests the evaluation of all
+// the dependencies of a node
+func TestEvaluateDependencies(t *testing.T) {
+	node := newNode("c", map[string]pbworkflow.DependencyCondition{
+		"a": _onSuccess,
+		"b": _always,
+	})
+
+	satisfied, unsatisfied := evaluateDependencies(node, newStatuses(
+		map[string]pbworkflow.NodeState{
+			"a": pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			"b": pbworkflow.NodeState_NODE_STATE_RUNNING,
+		}))
+	assert.False(t, satisfied)
+	assert.Empty(t, unsatisfied)
+
+	satisfied, unsatisfied = evaluateDependencies(node, newStatuses(
+		map[string]pbworkflow.NodeState{
+			"a": pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			"b": pbworkflow.NodeState_NODE_STATE_FAILED,
+		}))
+	assert.True(t, satisfied)
+	assert.Empty(t, unsatisfied)
+
+	satisfied, unsatisfied = evaluateDependencies(node, newStatuses(
+		map[string]pbworkflow.NodeState{
+			"a": pbworkflow.NodeState_NODE_STATE_FAILED,
+			"b": pbworkflow.NodeState_NODE_STATE_RUNNING,
+		}))
+	assert.False(t, satisfied)
+	assert.Equal(t, "a", unsatisfied)
+}
+
+// TestGetWorkflowState tests the state of a workflow
+// derived from the states of its nodes
+func TestGetWorkflowState(t *testing.T) {
+	spec := &pbworkflow.WorkflowSpec{
+		Name: "wf",
+		Nodes: []*pbworkflow.NodeSpec{
+			newNode("a", nil),
+			newNode("b", map[string]pbworkflow.DependencyCondition{"a": _onSuccess}),
+			newNode("cleanup", map[string]pbworkflow.DependencyCondition{"a": _onFailure}),
+		},
+	}
+
+	tests := []struct {
+		states map[string]pbworkflow.NodeState
+		state  pbworkflow.WorkflowState
+	}{
+		{
+			states: map[string]pbworkflow.NodeState{
+				"a":       pbworkflow.NodeState_NODE_STATE_RUNNING,
+				"b":       pbworkflow.NodeState_NODE_STATE_PENDING,
+				"cleanup": pbworkflow.NodeState_NODE_STATE_PENDING,
+			},
+			state: pbworkflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		},
+		{
+			states: map[string]pbworkflow.NodeState{
+				"a":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+				"b":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+				"cleanup": pbworkflow.NodeState_NODE_STATE_SKIPPED,
+			},
+			state: pbworkflow.WorkflowState_WORKFLOW_STATE_SUCCEEDED,
+		},
+		{
+			// the failure of a is handled by cleanup
+			states: map[string]pbworkflow.NodeState{
+				"a":       pbworkflow.NodeState_NODE_STATE_FAILED,
+				"b":       pbworkflow.NodeState_NODE_STATE_SKIPPED,
+				"cleanup": pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+			},
+			state: pbworkflow.WorkflowState_WORKFLOW_STATE_SUCCEEDED,
+		},
+		{
+			states: map[string]pbworkflow.NodeState{
+				"a":       pbworkflow.NodeState_NODE_STATE_SUCCEEDED,
+				"b":       pbworkflow.NodeState_NODE_STATE_FAILED,
+				"cleanup": pbworkflow.NodeState_NODE_STATE_SKIPPED,
+			},
+			state: pbworkflow.WorkflowState_WORKFLOW_STATE_FAILED,
+		},
+		{
+			states: map[string]pbworkflow.NodeState{
+				"a":       pbworkflow.NodeState_NODE_STATE_FAILED,
+				"b":       pbworkflow.NodeState_NODE_STATE_SKIPPED,
+				"cleanup": pbworkflow.NodeState_NODE_STATE_FAILED,
+			},
+			state: pbworkflow.WorkflowState_WORKFLOW_STATE_FAILED,
+		},
+	}
+
+	for i, test := range tests {
+		assert.Equal(t, test.state,
+			getWorkflowState(spec, newStatuses(test.states)), "test %d", i)
+	}
+}
+
+// TestGetDownstreamNodes tests the transitive
+// downstream nodes of a set of nodes
+func TestGetDownstreamNodes(t *testing.T) {
+	spec := &pbworkflow.WorkflowSpec{
+		Name: "wf",
+		Nodes: []*pbworkflow.NodeSpec{
+			newNode("d", map[string]pbworkflow.DependencyCondition{"c": _always}),
+			newNode("c", map[string]pbworkflow.DependencyCondition{"b": _onSuccess}),
+			newNode("b", map[string]pbworkflow.DependencyCondition{"a": _onSuccess}),
+			newNode("a", nil),
+			newNode("e", nil),
+		},
+	}
+
+	assert.Equal(t,
+		map[string]bool{"b": true, "c": true, "d": true},
+		getDownstreamNodes(spec, map[string]bool{"b": true}))
+	assert.Equal(t,
+		map[string]bool{"e": true},
+		getDownstreamNodes(spec, map[string]bool{"e": true}))
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the struct containing all the counters that track internal state
+// of workflow controller.
+type Metrics struct {
+	Reconcile     tally.Counter
+	ReconcileFail tally.Counter
+	Cleanup       tally.Counter
+	CleanupFail   tally.Counter
+
+	WorkflowCreate      tally.Counter
+	WorkflowCreateFail  tally.Counter
+	WorkflowAdvanceFail tally.Counter
+	WorkflowSucceeded   tally.Counter
+	WorkflowFailed      tally.Counter
+	WorkflowCancelled   tally.Counter
+	WorkflowRetried     tally.Counter
+	WorkflowDeleted     tally.Counter
+	WorkflowDeleteFail  tally.Counter
+
+	NodeLaunched   tally.Counter
+	NodeLaunchFail tally.Counter
+	NodeSucceeded  tally.Counter
+	NodeFailed     tally.Counter
+	NodeSkipped    tally.Counter
+	JobKillFail    tally.Counter
+}
+
+// NewMetrics returns a new Metrics struct, with all metrics
+// initialized and rooted at the given tally.Scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	successScope := scope.Tagged(map[string]string{"result": "success"})
+	failScope := scope.Tagged(map[string]string{"result": "fail"})
+
+	return &Metrics{
+		Reconcile:     successScope.Counter("reconcile"),
+		ReconcileFail: failScope.Counter("reconcile"),
+		Cleanup:       successScope.Counter("cleanup"),
+		CleanupFail:   failScope.Counter("cleanup"),
+
+		WorkflowCreate:      successScope.Counter("workflow_create"),
+		WorkflowCreateFail:  failScope.Counter("workflow_create"),
+		WorkflowAdvanceFail: failScope.Counter("workflow_advance"),
+		WorkflowSucceeded:   scope.Counter("workflow_succeeded"),
+		WorkflowFailed:      scope.Counter("workflow_failed"),
+		WorkflowCancelled:   scope.Counter("workflow_cancelled"),
+		WorkflowRetried:     scope.Counter("workflow_retried"),
+		WorkflowDeleted:     successScope.Counter("workflow_delete"),
+		WorkflowDeleteFail:  failScope.Counter("workflow_delete"),
+
+		NodeLaunched:   successScope.Counter("node_launch"),
+		NodeLaunchFail: failScope.Counter("node_launch"),
+		NodeSucceeded:  scope.Counter("node_succeeded"),
+		NodeFailed:     scope.Counter("node_failed"),
+		NodeSkipped:    scope.Counter("node_skipped"),
+		JobKillFail:    failScope.Counter("job_kill"),
+	}
+}
@@ -0,0 +1,323 @@
+package inbound
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/logging"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/encoding/protobuf"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// only the calls to the public APIs are audited
+	_auditedProcedurePrefix = "peloton.api."
+	_procedureSeparator     = "::"
+
+	// max size of the request recorded by an audit record
+	_maxAuditRequestSize = 4 * 1024
+	// timeout to write an audit record
+	_auditWriteTimeout = 5 * time.Second
+
+	_auditOutcomeOK = "ok"
+)
+
+// _mutatingMethodPrefixes are the prefixes of the
+// methods which mutate the state of the cluster
+var _mutatingMethodPrefixes = []string{
+	"Abort",
+	"Apply",
+	"Cancel",
+	"Complete",
+	"Create",
+	"Delete",
+	"Patch",
+	"Pause",
+	"Refresh",
+	"Replace",
+	"Restart",
+	"Resume",
+	"Retry",
+	"Rollback",
+	"Schedule",
+	"Start",
+	"Stop",
+	"Update",
+}
+
+// AuditRecorder persists the audit records
+type AuditRecorder interface {
+	Create(ctx context.Context, record *audit.AuditRecord) error
+}
+
+// auditMetrics are the metrics of the audit records
+type auditMetrics struct {
+	RecordWrite     tally.Counter
+	RecordWriteFail tally.Counter
+}
+
+func newAuditMetrics(scope tally.Scope) *auditMetrics {
+	return &auditMetrics{
+		RecordWrite:     scope.Counter("record_write"),
+		RecordWriteFail: scope.Counter("record_write_fail"),
+	}
+}
+
+type auditInboundMiddleware struct {
+	recorder AuditRecorder
+	metrics  *auditMetrics
+}
+
+func (m *auditInboundMiddleware) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter, h transport.UnaryHandler) error {
+	if !isAuditedProcedure(req.Procedure) {
+		return h.Handle(ctx, req, resw)
+	}
+
+	record, err := newAuditRecord(req)
+	if err != nil {
+		return err
+	}
+
+	// the user is read once the call returns, as it is
+	// authenticated by the auth middleware applied after audit
+	ctx = auth.WithCallUser(ctx)
+	err = h.Handle(ctx, req, resw)
+	m.write(ctx, record, err)
+	return err
+}
+
+func (m *auditInboundMiddleware) HandleOneway(ctx context.Context, req *transport.Request, h transport.OnewayHandler) error {
+	if !isAuditedProcedure(req.Procedure) {
+		return h.HandleOneway(ctx, req)
+	}
+
+	record, err := newAuditRecord(req)
+	if err != nil {
+		return err
+	}
+
+	ctx = auth.WithCallUser(ctx)
+	err = h.HandleOneway(ctx, req)
+	m.write(ctx, record, err)
+	return err
+}
+
+// HandleStream does not audit streams, which only serve reads
+func (m *auditInboundMiddleware) HandleStream(s *transport.ServerStream, h transport.StreamHandler) error {
+	return h.HandleStream(s)
+}
+
+// write records the user and the outcome of the call, and writes the
+// record before the call returns, so that the calls are not acknowledged
+// faster than they are recorded. The write is bounded by _auditWriteTimeout, and is
+// not cancelled with the call. A failure to write the record is logged,
+// without failing the call which already took effect.
+func (m *auditInboundMiddleware) write(
+	ctx context.Context,
+	record *audit.AuditRecord,
+	err error) {
+	record.User = auth.GetCallUsername(ctx)
+	record.Outcome = _auditOutcomeOK
+	if err != nil {
+		record.Outcome = yarpcerrors.FromError(err).Code().String()
+		record.Message = yarpcerrors.FromError(err).Message()
+	}
+
+	writeCtx, cancel := context.WithTimeout(
+		context.Background(), _auditWriteTimeout)
+	defer cancel()
+	if err := m.recorder.Create(writeCtx, record); err != nil {
+		log.WithError(err).
+			WithField("procedure", record.GetProcedure()).
+			WithField("user", record.GetUser()).
+			Error("failed to write audit record")
+		m.metrics.RecordWriteFail.Inc(1)
+		return
+	}
+	m.metrics.RecordWrite.Inc(1)
+}
+
+// newAuditRecord returns the audit record of a call, without its user
+// and outcome. The body of the request is read and replaced by a copy.
+func newAuditRecord(req *transport.Request) (*audit.AuditRecord, error) {
+	record := &audit.AuditRecord{
+		Time:      time.Now().UTC().Format(time.RFC3339Nano),
+		Procedure: req.Procedure,
+	}
+
+	if req.Body == nil {
+		return record, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, yarpcerrors.InternalErrorf("failed to read request: %v", err)
+	}
+	req.Body = bytes.NewReader(body)
+
+	msg := decodeRequest(req, body)
+	if msg == nil {
+		return record, nil
+	}
+
+	record.Entities = requestEntities(msg)
+
+	request, err := (&jsonpb.Marshaler{}).MarshalToString(logging.RedactMessage(msg))
+	if err != nil {
+		log.WithError(err).
+			WithField("procedure", req.Procedure).
+			Warn("failed to marshal audited request")
+		return record, nil
+	}
+	if len(request) > _maxAuditRequestSize {
+		request = request[:_maxAuditRequestSize]
+	}
+	record.Request = request
+	return record, nil
+}
+
+// isAuditedProcedure returns if the procedure is a method of
+// a public API which mutates the state of the cluster
+func isAuditedProcedure(procedure string) bool {
+	if !strings.HasPrefix(procedure, _auditedProcedurePrefix) {
+		return false
+	}
+
+	_, method, ok := splitProcedure(procedure)
+	if !ok {
+		return false
+	}
+	for _, prefix := range _mutatingMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitProcedure splits a procedure into its service and its method
+func splitProcedure(procedure string) (string, string, bool) {
+	sep := strings.LastIndex(procedure, _procedureSeparator)
+	if sep < 0 {
+		return "", "", false
+	}
+	return procedure[:sep], procedure[sep+len(_procedureSeparator):], true
+}
+
+// decodeRequest decodes the body of a request into the request message
+// of the procedure, which is <package>.<method>Request by convention.
+// It returns nil if the request cannot be decoded.
+func decodeRequest(req *transport.Request, body []byte) proto.Message {
+	service, method, ok := splitProcedure(req.Procedure)
+	if !ok {
+		return nil
+	}
+	pkg := service[:strings.LastIndex(service, ".")+1]
+
+	t := proto.MessageType(pkg + method + "Request")
+	if t == nil {
+		return nil
+	}
+	msg, ok := reflect.New(t.Elem()).Interface().(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	switch req.Encoding {
+	case protobuf.Encoding:
+		err := proto.Unmarshal(body, msg)
+		if err != nil {
+			return nil
+		}
+	case protobuf.JSONEncoding:
+		unmarshaler := &jsonpb.Unmarshaler{AllowUnknownFields: true}
+		err := unmarshaler.Unmarshal(bytes.NewReader(body), msg)
+		if err != nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return msg
+}
+
+// requestEntities returns the entities targeted by a request,
+// as kind:id, found in the top level fields of the request
+func requestEntities(msg proto.Message) []string {
+	var entities []string
+	add := func(kind string, ids ...string) {
+		for _, id := range ids {
+			if len(id) != 0 {
+				entities = append(entities, kind+":"+id)
+			}
+		}
+	}
+
+	v := reflect.ValueOf(msg).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if len(field.PkgPath) != 0 {
+			// unexported field
+			continue
+		}
+
+		switch id := v.Field(i).Interface().(type) {
+		case *peloton.JobID:
+			add("job", id.GetValue())
+		case *v1alphapeloton.JobID:
+			add("job", id.GetValue())
+		case *peloton.TaskID:
+			add("task", id.GetValue())
+		case *v1alphapeloton.PodName:
+			add("pod", id.GetValue())
+		case *peloton.ResourcePoolID:
+			add("respool", id.GetValue())
+		case *v1alphapeloton.ResourcePoolID:
+			add("respool", id.GetValue())
+		case *peloton.UpdateID:
+			add("update", id.GetValue())
+		case string:
+			if field.Name == "Hostname" {
+				add("host", id)
+			}
+		case []string:
+			if field.Name == "Hostnames" {
+				add("host", id...)
+			}
+		}
+	}
+	return entities
+}
+
+// NewAuditInboundMiddleware returns DispatcherInboundMiddleWare which
+// records the calls to the methods of the public APIs mutating the
+// state of the cluster. The records are written before the calls
+// return. It must be applied before the auth middleware, which sets the
+// user of the calls, so that the calls rejected by auth are recorded.
+func NewAuditInboundMiddleware(
+	recorder AuditRecorder,
+	parent tally.Scope,
+) DispatcherInboundMiddleWare {
+	return &auditInboundMiddleware{
+		recorder: recorder,
+		metrics:  newAuditMetrics(parent.SubScope("audit")),
+	}
+}
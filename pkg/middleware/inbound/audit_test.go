@@ -0,0 +1,302 @@
+package inbound
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	"github.com/uber/peloton/pkg/auth"
+	auth_mocks "github.com/uber/peloton/pkg/auth/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+	"go.uber.org/yarpc/encoding/protobuf"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_testDeleteProcedure = "peloton.api.v0.job.JobManager::Delete"
+	_testGetProcedure    = "peloton.api.v0.job.JobManager::Get"
+)
+
+// testAuditRecorder keeps the records in memory
+type testAuditRecorder struct {
+	records []*audit.AuditRecord
+	err     error
+	// errors and deadlines of the contexts of the writes
+	ctxErrs   []error
+	deadlines []time.Time
+}
+
+func (r *testAuditRecorder) Create(ctx context.Context, record *audit.AuditRecord) error {
+	r.records = append(r.records, record)
+	deadline, _ := ctx.Deadline()
+	r.ctxErrs = append(r.ctxErrs, ctx.Err())
+	r.deadlines = append(r.deadlines, deadline)
+	return r.err
+}
+
+type AuditInboundMiddlewareSuite struct {
+	suite.Suite
+
+	ctrl     *gomock.Controller
+	m        *auditInboundMiddleware
+	recorder *testAuditRecorder
+}
+
+func (suite *AuditInboundMiddlewareSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.recorder = &testAuditRecorder{}
+	suite.m = NewAuditInboundMiddleware(
+		suite.recorder, tally.NoopScope).(*auditInboundMiddleware)
+}
+
+func (suite *AuditInboundMiddlewareSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// deleteRequest returns a request to delete a job, encoded with proto
+func (suite *AuditInboundMiddlewareSuite) deleteRequest() (*transport.Request, []byte) {
+	body, err := proto.Marshal(&job.DeleteRequest{
+		Id: &peloton.JobID{Value: "job1"},
+	})
+	suite.NoError(err)
+	return &transport.Request{
+		Procedure: _testDeleteProcedure,
+		Encoding:  protobuf.Encoding,
+		Body:      bytes.NewReader(body),
+	}, body
+}
+
+// expectHandle expects the handler to be called with the
+// unread body and returns err
+func (suite *AuditInboundMiddlewareSuite) expectHandle(
+	h *transporttest.MockUnaryHandler,
+	body []byte,
+	err error) {
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *transport.Request, _ transport.ResponseWriter) {
+			read, readErr := ioutil.ReadAll(req.Body)
+			suite.NoError(readErr)
+			suite.Equal(body, read)
+		}).
+		Return(err)
+}
+
+func (suite *AuditInboundMiddlewareSuite) TestHandleRecordsCall() {
+	u := auth_mocks.NewMockUser(suite.ctrl)
+	u.EXPECT().Username().Return("user1")
+	ctx := auth.WithUser(context.Background(), u, _testDeleteProcedure)
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	req, body := suite.deleteRequest()
+	suite.expectHandle(h, body, nil)
+	suite.NoError(suite.m.Handle(ctx, req, nil, h))
+
+	suite.Len(suite.recorder.records, 1)
+	record := suite.recorder.records[0]
+	suite.NotEmpty(record.GetTime())
+	suite.Equal("user1", record.GetUser())
+	suite.Equal(_testDeleteProcedure, record.GetProcedure())
+	suite.Equal([]string{"job:job1"}, record.GetEntities())
+	suite.Equal(`{"id":{"value":"job1"}}`, record.GetRequest())
+	suite.Equal(_auditOutcomeOK, record.GetOutcome())
+	suite.Empty(record.GetMessage())
+}
+
+func (suite *AuditInboundMiddlewareSuite) TestHandleRecordsJSONCall() {
+	body, err := (&jsonpb.Marshaler{}).MarshalToString(
+		&host_svc.StartMaintenanceRequest{Hostnames: []string{"host1", "host2"}})
+	suite.NoError(err)
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	suite.expectHandle(h, []byte(body), nil)
+	suite.NoError(suite.m.Handle(
+		context.Background(),
+		&transport.Request{
+			Procedure: "peloton.api.v0.host.svc.HostService::StartMaintenance",
+			Encoding:  protobuf.JSONEncoding,
+			Body:      bytes.NewReader([]byte(body)),
+		},
+		nil,
+		h))
+
+	suite.Len(suite.recorder.records, 1)
+	suite.Empty(suite.recorder.records[0].GetUser())
+	suite.Equal(
+		[]string{"host:host1", "host:host2"},
+		suite.recorder.records[0].GetEntities())
+}
+
+func (suite *AuditInboundMiddlewareSuite) TestHandleRecordsError() {
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	req, body := suite.deleteRequest()
+	suite.expectHandle(h, body, yarpcerrors.NotFoundErrorf("job not found"))
+	suite.Error(suite.m.Handle(context.Background(), req, nil, h))
+
+	suite.Len(suite.recorder.records, 1)
+	suite.Equal("not-found", suite.recorder.records[0].GetOutcome())
+	suite.Equal("job not found", suite.recorder.records[0].GetMessage())
+}
+
+func (suite *AuditInboundMiddlewareSuite) TestHandleRecordFailure() {
+	suite.recorder.err = errors.New("test error")
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	req, body := suite.deleteRequest()
+	suite.expectHandle(h, body, nil)
+	// the call succeeds even if its record is not written
+	suite.NoError(suite.m.Handle(context.Background(), req, nil, h))
+	suite.Len(suite.recorder.records, 1)
+}
+
+func (suite *AuditInboundMiddlewareSuite) TestHandleUndecodedRequest() {
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	body := []byte("invalid")
+	suite.expectHandle(h, body, nil)
+	suite.NoError(suite.m.Handle(
+		context.Background(),
+		&transport.Request{
+			Procedure: _testDeleteProcedure,
+			Encoding:  protobuf.Encoding,
+			Body:      bytes.NewReader(body),
+		},
+		nil,
+		h))
+
+	// the call is recorded without its request
+	suite.Len(suite.recorder.records, 1)
+	suite.Empty(suite.recorder.records[0].GetRequest())
+	suite.Empty(suite.recorder.records[0].GetEntities())
+}
+
+func (suite *AuditInboundMiddlewareSuite) TestHandleSkipsReads() {
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.NoError(suite.m.Handle(
+		context.Background(),
+		&transport.Request{Procedure: _testGetProcedure},
+		nil,
+		h))
+
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.NoError(suite.m.Handle(
+		context.Background(),
+		&transport.Request{Procedure: "peloton.private.resmgr.ResourceManagerService::EnqueueGangs"},
+		nil,
+		h))
+
+	suite.Empty(suite.recorder.records)
+}
+
+func (suite *AuditInboundMiddlewareSuite) TestHandleOnewayRecordsCall() {
+	h := transporttest.NewMockOnewayHandler(suite.ctrl)
+	req, _ := suite.deleteRequest()
+	h.EXPECT().HandleOneway(gomock.Any(), gomock.Any()).Return(nil)
+	suite.NoError(suite.m.HandleOneway(context.Background(), req, h))
+
+	suite.Len(suite.recorder.records, 1)
+	suite.Equal(_auditOutcomeOK, suite.recorder.records[0].GetOutcome())
+}
+
+func (suite *AuditInboundMiddlewareSuite) TestHandleStream() {
+	h := transporttest.NewMockStreamHandler(suite.ctrl)
+	s := transporttest.NewMockStream(suite.ctrl)
+	ss, err := transport.NewServerStream(s)
+	suite.NoError(err)
+	h.EXPECT().HandleStream(ss).Return(nil)
+	suite.NoError(suite.m.HandleStream(ss, h))
+	suite.Empty(suite.recorder.records)
+}
+
+// TestHandleRecordsAuthDenial tests that the calls rejected by the
+// auth middleware applied after audit are recorded with their user
+func (suite *AuditInboundMiddlewareSuite) TestHandleRecordsAuthDenial() {
+	s := auth_mocks.NewMockSecurityManager(suite.ctrl)
+	u := auth_mocks.NewMockUser(suite.ctrl)
+	m := NewChainInboundMiddleware(suite.m, NewAuthInboundMiddleware(s))
+
+	s.EXPECT().Authenticate(gomock.Any()).Return(u, nil)
+	u.EXPECT().IsPermitted(_testDeleteProcedure).Return(false)
+	u.EXPECT().Username().Return("user1")
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	req, _ := suite.deleteRequest()
+	err := m.Handle(context.Background(), req, nil, h)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+
+	// the calls which cannot be authenticated are recorded without user
+	s.EXPECT().Authenticate(gomock.Any()).
+		Return(nil, yarpcerrors.UnauthenticatedErrorf("invalid token"))
+	req, _ = suite.deleteRequest()
+	err = m.Handle(context.Background(), req, nil, h)
+	suite.True(yarpcerrors.IsUnauthenticated(err))
+
+	suite.Len(suite.recorder.records, 2)
+	suite.Equal("user1", suite.recorder.records[0].GetUser())
+	suite.Equal("permission-denied", suite.recorder.records[0].GetOutcome())
+	suite.Empty(suite.recorder.records[1].GetUser())
+	suite.Equal("unauthenticated", suite.recorder.records[1].GetOutcome())
+}
+
+// TestHandleWritesRecordBeforeReturn tests that the records are
+// written before the calls return, with a bounded timeout which
+// is not cancelled with the call
+func (suite *AuditInboundMiddlewareSuite) TestHandleWritesRecordBeforeReturn() {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	req, body := suite.deleteRequest()
+	suite.expectHandle(h, body, nil)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(context.Context, *transport.Request, transport.ResponseWriter) {
+			cancel()
+		}).
+		Return(nil)
+	req2, _ := suite.deleteRequest()
+
+	suite.NoError(suite.m.Handle(ctx, req, nil, h))
+	suite.Len(suite.recorder.records, 1)
+	suite.NoError(suite.m.Handle(ctx, req2, nil, h))
+	suite.Len(suite.recorder.records, 2)
+
+	for i := range suite.recorder.records {
+		suite.NoError(suite.recorder.ctxErrs[i])
+		suite.False(suite.recorder.deadlines[i].IsZero())
+		suite.True(time.Until(suite.recorder.deadlines[i]) <= _auditWriteTimeout)
+	}
+}
+
+func (suite *AuditInboundMiddlewareSuite) TestIsAuditedProcedure() {
+	tests := map[string]bool{
+		_testDeleteProcedure: true,
+		_testGetProcedure:    false,
+		"peloton.api.v1alpha.job.stateless.svc.JobService::ReplaceJob":             true,
+		"peloton.api.v1alpha.job.stateless.svc.JobService::QueryPods":              false,
+		"peloton.api.v0.respool.ResourceManager::CreateResourcePool":               true,
+		"peloton.private.hostmgr.InternalHostService::KillTasks":                   false,
+		"peloton.api.v1alpha.rebalance.svc.RebalanceService::ApplyRecommendations": true,
+		"peloton.api.v1alpha.rebalance.svc.RebalanceService::GetRecommendations":   false,
+		"peloton.api.v1alpha.job.workflow.svc.WorkflowService::RetryWorkflow":      true,
+		"peloton.api.v1alpha.job.workflow.svc.WorkflowService::ListWorkflows":      false,
+		"peloton.api.v0.job.JobManager":                                            false,
+	}
+	for procedure, audited := range tests {
+		suite.Equal(audited, isAuditedProcedure(procedure), procedure)
+	}
+}
+
+func TestAuditInboundMiddlewareSuite(t *testing.T) {
+	suite.Run(t, new(AuditInboundMiddlewareSuite))
+}
@@ -12,13 +12,24 @@ var permissionDeniedErrorStr = "not permitted to call %s in %s"
 
 type authInboundMiddleware struct {
 	auth.SecurityManager
+
+	// services whose calls are not authenticated
+	exemptServices map[string]bool
 }
 
 func (m *authInboundMiddleware) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter, h transport.UnaryHandler) error {
-	permitted, err := m.isPermitted(req.Headers, req.Procedure)
+	if m.exemptServices[req.Service] {
+		return h.Handle(ctx, req, resw)
+	}
+
+	user, permitted, err := m.isPermitted(req.Headers, req.Procedure)
 	if err != nil {
 		return err
 	}
+	// pass the user to the handler, which checks if it is permitted on
+	// the entity of the request. The user is set before checking if it
+	// is permitted, so that the calls it is denied are attributed to it.
+	ctx = auth.WithUser(ctx, user, req.Procedure)
 
 	if !permitted {
 		return yarpcerrors.PermissionDeniedErrorf(permissionDeniedErrorStr, req.Procedure, req.Service)
@@ -28,10 +39,15 @@ func (m *authInboundMiddleware) Handle(ctx context.Context, req *transport.Reque
 }
 
 func (m *authInboundMiddleware) HandleOneway(ctx context.Context, req *transport.Request, h transport.OnewayHandler) error {
-	permitted, err := m.isPermitted(req.Headers, req.Procedure)
+	if m.exemptServices[req.Service] {
+		return h.HandleOneway(ctx, req)
+	}
+
+	user, permitted, err := m.isPermitted(req.Headers, req.Procedure)
 	if err != nil {
 		return err
 	}
+	ctx = auth.WithUser(ctx, user, req.Procedure)
 
 	if !permitted {
 		return yarpcerrors.PermissionDeniedErrorf(permissionDeniedErrorStr, req.Procedure, req.Service)
@@ -43,8 +59,12 @@ func (m *authInboundMiddleware) HandleOneway(ctx context.Context, req *transport
 func (m *authInboundMiddleware) HandleStream(s *transport.ServerStream, h transport.StreamHandler) error {
 	service := s.Request().Meta.Service
 	procedure := s.Request().Meta.Procedure
+	if m.exemptServices[service] {
+		return h.HandleStream(s)
+	}
 
-	permitted, err := m.isPermitted(s.Request().Meta.Headers, procedure)
+	// streams only serve reads, which are not checked per entity
+	_, permitted, err := m.isPermitted(s.Request().Meta.Headers, procedure)
 	if err != nil {
 		return err
 	}
@@ -56,18 +76,28 @@ func (m *authInboundMiddleware) HandleStream(s *transport.ServerStream, h transp
 	return h.HandleStream(s)
 }
 
-func (m *authInboundMiddleware) isPermitted(headers transport.Headers, procedure string) (bool, error) {
+func (m *authInboundMiddleware) isPermitted(headers transport.Headers, procedure string) (auth.User, bool, error) {
 	user, err := m.Authenticate(headers)
 	if err != nil {
-		return false, err
+		return nil, false, err
 	}
 
-	return user.IsPermitted(procedure), nil
+	return user, user.IsPermitted(procedure), nil
 }
 
-// NewAuthInboundMiddleware returns DispatcherInboundMiddleWare with auth check
-func NewAuthInboundMiddleware(security auth.SecurityManager) DispatcherInboundMiddleWare {
-	return &authInboundMiddleware{
+// NewAuthInboundMiddleware returns DispatcherInboundMiddleWare with auth check.
+// The calls to the exempt services are not authenticated, such as the
+// Mesos callbacks of hostmgr which do not carry Peloton credentials.
+func NewAuthInboundMiddleware(
+	security auth.SecurityManager,
+	exemptServices ...string,
+) DispatcherInboundMiddleWare {
+	m := &authInboundMiddleware{
 		SecurityManager: security,
+		exemptServices:  make(map[string]bool),
+	}
+	for _, service := range exemptServices {
+		m.exemptServices[service] = true
 	}
+	return m
 }
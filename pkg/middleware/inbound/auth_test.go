@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/uber/peloton/pkg/auth"
 	auth_mocks "github.com/uber/peloton/pkg/auth/mocks"
 
 	"github.com/golang/mock/gomock"
@@ -41,6 +42,24 @@ func (suite *AuthInboundMiddlewareSuite) TestHandleSuccess() {
 	suite.NoError(suite.m.Handle(context.Background(), &transport.Request{}, nil, h))
 }
 
+func (suite *AuthInboundMiddlewareSuite) TestHandlePassesUser() {
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	suite.s.EXPECT().Authenticate(gomock.Any()).Return(suite.u, nil)
+	suite.u.EXPECT().IsPermitted("procedure").Return(true)
+	suite.u.EXPECT().IsPermittedOnEntity("procedure", gomock.Any()).Return(false)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, _ *transport.Request, _ transport.ResponseWriter) {
+			suite.True(auth.HasUser(ctx))
+			suite.Error(auth.CheckEntityPermission(ctx, &auth.Entity{}))
+		}).
+		Return(nil)
+	suite.NoError(suite.m.Handle(
+		context.Background(),
+		&transport.Request{Procedure: "procedure"},
+		nil,
+		h))
+}
+
 func (suite *AuthInboundMiddlewareSuite) TestHandleAuthenticateFail() {
 	h := transporttest.NewMockUnaryHandler(suite.ctrl)
 	suite.s.EXPECT().Authenticate(gomock.Any()).Return(nil, errors.New("test error"))
@@ -148,6 +167,33 @@ func (suite *AuthInboundMiddlewareSuite) TestHandleStreamHandlerFail() {
 	suite.Error(suite.m.HandleStream(ss, h))
 }
 
+// TestHandleExemptService tests that the calls to the
+// exempt services are not authenticated
+func (suite *AuthInboundMiddlewareSuite) TestHandleExemptService() {
+	m := NewAuthInboundMiddleware(suite.s, "Scheduler")
+	req := &transport.Request{Service: "Scheduler", Procedure: "UPDATE"}
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), req, gomock.Any()).
+		Do(func(ctx context.Context, _ *transport.Request, _ transport.ResponseWriter) {
+			suite.False(auth.HasUser(ctx))
+		}).
+		Return(nil)
+	suite.NoError(m.Handle(context.Background(), req, nil, h))
+
+	o := transporttest.NewMockOnewayHandler(suite.ctrl)
+	o.EXPECT().HandleOneway(gomock.Any(), req).Return(nil)
+	suite.NoError(m.HandleOneway(context.Background(), req, o))
+
+	// the other services are authenticated
+	suite.s.EXPECT().Authenticate(gomock.Any()).Return(nil, errors.New("test error"))
+	suite.Error(m.Handle(
+		context.Background(),
+		&transport.Request{Service: "peloton", Procedure: "UPDATE"},
+		nil,
+		h))
+}
+
 func TestAuthInboundMiddlewareSuite(t *testing.T) {
 	suite.Run(t, &AuthInboundMiddlewareSuite{})
 }
@@ -0,0 +1,54 @@
+package inbound
+
+import (
+	"context"
+
+	"go.uber.org/yarpc/api/middleware"
+	"go.uber.org/yarpc/api/transport"
+)
+
+// chainInboundMiddleware applies its middleware in order,
+// the first one being the outermost
+type chainInboundMiddleware struct {
+	mws []DispatcherInboundMiddleWare
+}
+
+func (m *chainInboundMiddleware) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter, h transport.UnaryHandler) error {
+	if len(m.mws) == 0 {
+		return h.Handle(ctx, req, resw)
+	}
+	for i := len(m.mws) - 1; i > 0; i-- {
+		h = middleware.ApplyUnaryInbound(h, m.mws[i])
+	}
+	return m.mws[0].Handle(ctx, req, resw, h)
+}
+
+func (m *chainInboundMiddleware) HandleOneway(ctx context.Context, req *transport.Request, h transport.OnewayHandler) error {
+	if len(m.mws) == 0 {
+		return h.HandleOneway(ctx, req)
+	}
+	for i := len(m.mws) - 1; i > 0; i-- {
+		h = middleware.ApplyOnewayInbound(h, m.mws[i])
+	}
+	return m.mws[0].HandleOneway(ctx, req, h)
+}
+
+func (m *chainInboundMiddleware) HandleStream(s *transport.ServerStream, h transport.StreamHandler) error {
+	if len(m.mws) == 0 {
+		return h.HandleStream(s)
+	}
+	for i := len(m.mws) - 1; i > 0; i-- {
+		h = middleware.ApplyStreamInbound(h, m.mws[i])
+	}
+	return m.mws[0].HandleStream(s, h)
+}
+
+// NewChainInboundMiddleware returns DispatcherInboundMiddleWare which
+// applies mws in order, so that the middleware at the front see the
+// requests first. Without mws, the requests are passed to the handlers.
+func NewChainInboundMiddleware(mws ...DispatcherInboundMiddleWare) DispatcherInboundMiddleWare {
+	if len(mws) == 1 {
+		return mws[0]
+	}
+	return &chainInboundMiddleware{mws: mws}
+}
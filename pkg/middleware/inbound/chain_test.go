@@ -0,0 +1,83 @@
+package inbound
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+)
+
+// testInboundMiddleware appends its name to calls before calling the handler
+type testInboundMiddleware struct {
+	name  string
+	calls *[]string
+}
+
+func (m *testInboundMiddleware) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter, h transport.UnaryHandler) error {
+	*m.calls = append(*m.calls, m.name)
+	return h.Handle(ctx, req, resw)
+}
+
+func (m *testInboundMiddleware) HandleOneway(ctx context.Context, req *transport.Request, h transport.OnewayHandler) error {
+	*m.calls = append(*m.calls, m.name)
+	return h.HandleOneway(ctx, req)
+}
+
+func (m *testInboundMiddleware) HandleStream(s *transport.ServerStream, h transport.StreamHandler) error {
+	*m.calls = append(*m.calls, m.name)
+	return h.HandleStream(s)
+}
+
+func TestChainInboundMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls []string
+	m := NewChainInboundMiddleware(
+		&testInboundMiddleware{name: "first", calls: &calls},
+		&testInboundMiddleware{name: "second", calls: &calls},
+	)
+
+	h := transporttest.NewMockUnaryHandler(ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	assert.NoError(t, m.Handle(context.Background(), &transport.Request{}, nil, h))
+	assert.Equal(t, []string{"first", "second"}, calls)
+
+	calls = nil
+	oh := transporttest.NewMockOnewayHandler(ctrl)
+	oh.EXPECT().HandleOneway(gomock.Any(), gomock.Any()).Return(nil)
+	assert.NoError(t, m.HandleOneway(context.Background(), &transport.Request{}, oh))
+	assert.Equal(t, []string{"first", "second"}, calls)
+
+	calls = nil
+	sh := transporttest.NewMockStreamHandler(ctrl)
+	ss, err := transport.NewServerStream(transporttest.NewMockStream(ctrl))
+	assert.NoError(t, err)
+	sh.EXPECT().HandleStream(ss).Return(nil)
+	assert.NoError(t, m.HandleStream(ss, sh))
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
+
+func TestEmptyChainInboundMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	m := NewChainInboundMiddleware()
+
+	h := transporttest.NewMockUnaryHandler(ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	assert.NoError(t, m.Handle(context.Background(), &transport.Request{}, nil, h))
+
+	oh := transporttest.NewMockOnewayHandler(ctrl)
+	oh.EXPECT().HandleOneway(gomock.Any(), gomock.Any()).Return(nil)
+	assert.NoError(t, m.HandleOneway(context.Background(), &transport.Request{}, oh))
+
+	sh := transporttest.NewMockStreamHandler(ctrl)
+	ss, err := transport.NewServerStream(transporttest.NewMockStream(ctrl))
+	assert.NoError(t, err)
+	sh.EXPECT().HandleStream(ss).Return(nil)
+	assert.NoError(t, m.HandleStream(ss, sh))
+}
@@ -0,0 +1,510 @@
+package inbound
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/background"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/atomic"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_limitTypeUser      = "user"
+	_limitTypeProcedure = "procedure"
+
+	// the limiters of the users idle for longer are evicted,
+	// so that the limiters do not grow with the users over time
+	_userLimiterIdleTimeout = 10 * time.Minute
+
+	_rateLimitConfigReloadWorkName = "rate_limit_config_reload"
+)
+
+// RateLimitInboundMiddleware is a DispatcherInboundMiddleWare which
+// rejects the requests over the limits of their procedure with
+// ResourceExhausted errors. It is applied before the auth middleware,
+// so that the calls are rejected before being authenticated.
+type RateLimitInboundMiddleware interface {
+	DispatcherInboundMiddleWare
+
+	// UserLimits returns the middleware which rejects the requests over
+	// the limits of their user, read from the context set by the auth
+	// middleware. It is applied after the auth middleware.
+	UserLimits() DispatcherInboundMiddleWare
+
+	// Update replaces the limits. The requests already
+	// admitted still count toward the updated limits.
+	Update(config *RateLimitConfig)
+}
+
+// limiterMetrics are the metrics of a limit
+type limiterMetrics struct {
+	Admitted         tally.Counter
+	RejectedRate     tally.Counter
+	RejectedInFlight tally.Counter
+	Refunded         tally.Counter
+	// InFlight is nil for the metrics shared by several limiters
+	InFlight tally.Gauge
+}
+
+// newLimiterMetrics returns the metrics of the limits of limitType. The
+// metrics are shared by all the limiters of limitType if key is empty,
+// which is the case of the users to keep the number of metrics bounded.
+func newLimiterMetrics(scope tally.Scope, limitType, key string) *limiterMetrics {
+	tags := map[string]string{"limit_type": limitType}
+	if key != "" {
+		tags["limit_key"] = key
+	}
+	limitScope := scope.Tagged(tags)
+
+	metrics := &limiterMetrics{
+		Admitted:         limitScope.Counter("admitted"),
+		RejectedRate:     limitScope.Counter("rejected_rate"),
+		RejectedInFlight: limitScope.Counter("rejected_in_flight"),
+		Refunded:         limitScope.Counter("refunded"),
+	}
+	if key != "" {
+		metrics.InFlight = limitScope.Gauge("in_flight")
+	}
+	return metrics
+}
+
+// updateInFlight reports the number of requests in flight of a limiter
+func (m *limiterMetrics) updateInFlight(inFlight int) {
+	if m.InFlight != nil {
+		m.InFlight.Update(float64(inFlight))
+	}
+}
+
+// limiter enforces a limit with a token bucket for the rate
+// and a counter of the requests in flight
+type limiter struct {
+	sync.Mutex
+
+	limitType string
+	key       string
+	config    *LimitConfig
+
+	// tokens in the bucket at time last
+	tokens float64
+	last   time.Time
+
+	inFlight int
+	// time the limiter was last acquired or released
+	used time.Time
+
+	metrics *limiterMetrics
+}
+
+func newLimiter(
+	limitType string,
+	key string,
+	config *LimitConfig,
+	now time.Time,
+	metrics *limiterMetrics) *limiter {
+	return &limiter{
+		limitType: limitType,
+		key:       key,
+		config:    config,
+		tokens:    config.burst(),
+		last:      now,
+		used:      now,
+		metrics:   metrics,
+	}
+}
+
+// setConfig replaces the config of the limit
+func (l *limiter) setConfig(config *LimitConfig) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.config = config
+	l.tokens = math.Min(l.tokens, config.burst())
+}
+
+// acquire admits a request at time now if it is within the limit,
+// the request must be released, or cancelled, once it is done
+func (l *limiter) acquire(now time.Time) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.config.MaxInFlight > 0 && l.inFlight >= l.config.MaxInFlight {
+		l.metrics.RejectedInFlight.Inc(1)
+		return yarpcerrors.ResourceExhaustedErrorf(
+			"too many requests in flight for %s %s", l.limitType, l.key)
+	}
+
+	if l.config.Rate > 0 {
+		if now.After(l.last) {
+			l.tokens = math.Min(
+				l.config.burst(),
+				l.tokens+now.Sub(l.last).Seconds()*l.config.Rate)
+			l.last = now
+		}
+		if l.tokens < 1 {
+			l.metrics.RejectedRate.Inc(1)
+			return yarpcerrors.ResourceExhaustedErrorf(
+				"rate limit exceeded for %s %s", l.limitType, l.key)
+		}
+		l.tokens--
+	}
+
+	l.inFlight++
+	l.used = now
+	l.metrics.Admitted.Inc(1)
+	l.metrics.updateInFlight(l.inFlight)
+	return nil
+}
+
+// release marks an admitted request as done
+func (l *limiter) release(now time.Time) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.inFlight--
+	l.used = now
+	l.metrics.updateInFlight(l.inFlight)
+}
+
+// cancel marks an admitted request as done, and refunds its token
+// because the request was rejected by another limit
+func (l *limiter) cancel(now time.Time) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.config.Rate > 0 {
+		l.tokens = math.Min(l.config.burst(), l.tokens+1)
+	}
+	l.inFlight--
+	l.used = now
+	l.metrics.Refunded.Inc(1)
+	l.metrics.updateInFlight(l.inFlight)
+}
+
+// idle returns whether the limiter has no request in
+// flight and has not been used for timeout at time now
+func (l *limiter) idle(now time.Time, timeout time.Duration) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	return l.inFlight == 0 && now.Sub(l.used) >= timeout
+}
+
+// rateLimitedCallKey is the context key of the rateLimitedCall of a call
+type rateLimitedCallKey struct{}
+
+// rateLimitedCall holds the limiters which admitted a call
+type rateLimitedCall struct {
+	sync.Mutex
+
+	m        *rateLimitInboundMiddleware
+	acquired []*limiter
+}
+
+// acquire admits the call if it is within all the limits, nil limiters
+// are skipped. If a limit rejects the call, the limits which already
+// admitted the call are cancelled, so that their tokens are refunded.
+func (c *rateLimitedCall) acquire(limiters ...*limiter) error {
+	c.Lock()
+	defer c.Unlock()
+
+	now := c.m.now()
+	for _, l := range limiters {
+		if l == nil {
+			continue
+		}
+		if err := l.acquire(now); err != nil {
+			for _, acquired := range c.acquired {
+				acquired.cancel(now)
+			}
+			c.acquired = nil
+			return err
+		}
+		c.acquired = append(c.acquired, l)
+	}
+	return nil
+}
+
+// release releases the limits which admitted the call once it is done
+func (c *rateLimitedCall) release() {
+	c.Lock()
+	defer c.Unlock()
+
+	now := c.m.now()
+	for _, l := range c.acquired {
+		l.release(now)
+	}
+	c.acquired = nil
+}
+
+type rateLimitInboundMiddleware struct {
+	sync.RWMutex
+
+	config *RateLimitConfig
+	// limiters by username and by procedure, created at the first
+	// request. The limiters of the idle users are evicted.
+	users      map[string]*limiter
+	procedures map[string]*limiter
+	// time the idle user limiters were last evicted
+	evicted time.Time
+
+	userMetrics *limiterMetrics
+	scope       tally.Scope
+	now         func() time.Time
+}
+
+func (m *rateLimitInboundMiddleware) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter, h transport.UnaryHandler) error {
+	call, err := m.acquire(m.procedureLimiter(req.Procedure))
+	if err != nil {
+		return err
+	}
+	defer call.release()
+
+	return h.Handle(context.WithValue(ctx, rateLimitedCallKey{}, call), req, resw)
+}
+
+func (m *rateLimitInboundMiddleware) HandleOneway(ctx context.Context, req *transport.Request, h transport.OnewayHandler) error {
+	call, err := m.acquire(m.procedureLimiter(req.Procedure))
+	if err != nil {
+		return err
+	}
+	defer call.release()
+
+	return h.HandleOneway(context.WithValue(ctx, rateLimitedCallKey{}, call), req)
+}
+
+// HandleStream only enforces the limits of the procedure, because
+// the user of a stream is not passed by the auth middleware
+func (m *rateLimitInboundMiddleware) HandleStream(s *transport.ServerStream, h transport.StreamHandler) error {
+	call, err := m.acquire(m.procedureLimiter(s.Request().Meta.Procedure))
+	if err != nil {
+		return err
+	}
+	defer call.release()
+
+	return h.HandleStream(s)
+}
+
+// UserLimits returns the middleware enforcing the limits of the users
+func (m *rateLimitInboundMiddleware) UserLimits() DispatcherInboundMiddleWare {
+	return &userRateLimitInboundMiddleware{m: m}
+}
+
+// Update replaces the limits, and drops the limiters
+// which are no longer limited
+func (m *rateLimitInboundMiddleware) Update(config *RateLimitConfig) {
+	if config == nil {
+		config = &RateLimitConfig{}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	m.config = config
+	for username, l := range m.users {
+		if limit := config.userLimit(username); limit != nil {
+			l.setConfig(limit)
+		} else {
+			delete(m.users, username)
+		}
+	}
+	for procedure, l := range m.procedures {
+		if limit := config.ProcedureLimits[procedure]; limit != nil {
+			l.setConfig(limit)
+		} else {
+			delete(m.procedures, procedure)
+		}
+	}
+}
+
+// acquire admits a call if it is within the limits, the returned
+// call must be released once it is done
+func (m *rateLimitInboundMiddleware) acquire(limiters ...*limiter) (*rateLimitedCall, error) {
+	call := &rateLimitedCall{m: m}
+	if err := call.acquire(limiters...); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+func (m *rateLimitInboundMiddleware) userLimiter(username string) *limiter {
+	return m.getLimiter(
+		m.users,
+		_limitTypeUser,
+		username,
+		func(config *RateLimitConfig) *LimitConfig {
+			return config.userLimit(username)
+		},
+		func() *limiterMetrics {
+			return m.userMetrics
+		})
+}
+
+func (m *rateLimitInboundMiddleware) procedureLimiter(procedure string) *limiter {
+	return m.getLimiter(
+		m.procedures,
+		_limitTypeProcedure,
+		procedure,
+		func(config *RateLimitConfig) *LimitConfig {
+			return config.ProcedureLimits[procedure]
+		},
+		func() *limiterMetrics {
+			return newLimiterMetrics(m.scope, _limitTypeProcedure, procedure)
+		})
+}
+
+// getLimiter returns the limiter of the key in limiters, which is
+// created if the key is limited. It returns nil if the key is not limited.
+func (m *rateLimitInboundMiddleware) getLimiter(
+	limiters map[string]*limiter,
+	limitType string,
+	key string,
+	limitOf func(config *RateLimitConfig) *LimitConfig,
+	metricsOf func() *limiterMetrics) *limiter {
+	m.RLock()
+	l, ok := limiters[key]
+	limit := limitOf(m.config)
+	m.RUnlock()
+	if ok || limit == nil {
+		return l
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	// the limiter may have been created, or the
+	// config updated, since the read lock was released
+	if l, ok := limiters[key]; ok {
+		return l
+	}
+	limit = limitOf(m.config)
+	if limit == nil {
+		return nil
+	}
+
+	now := m.now()
+	if now.Sub(m.evicted) >= _userLimiterIdleTimeout {
+		m.evictIdleUsers(now)
+	}
+
+	l = newLimiter(limitType, key, limit, now, metricsOf())
+	limiters[key] = l
+	return l
+}
+
+// evictIdleUsers drops the limiters of the users idle at time now,
+// which are created again at their next request. It must be called
+// with the write lock held.
+func (m *rateLimitInboundMiddleware) evictIdleUsers(now time.Time) {
+	for username, l := range m.users {
+		if l.idle(now, _userLimiterIdleTimeout) {
+			delete(m.users, username)
+		}
+	}
+	m.evicted = now
+}
+
+// userRateLimitInboundMiddleware enforces the limits of the user of the
+// calls, which is set in the context by the auth middleware
+type userRateLimitInboundMiddleware struct {
+	m *rateLimitInboundMiddleware
+}
+
+func (u *userRateLimitInboundMiddleware) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter, h transport.UnaryHandler) error {
+	release, err := u.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return h.Handle(ctx, req, resw)
+}
+
+func (u *userRateLimitInboundMiddleware) HandleOneway(ctx context.Context, req *transport.Request, h transport.OnewayHandler) error {
+	release, err := u.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return h.HandleOneway(ctx, req)
+}
+
+// HandleStream does not enforce the limits of the user,
+// because the user of a stream is not passed by the auth middleware
+func (u *userRateLimitInboundMiddleware) HandleStream(s *transport.ServerStream, h transport.StreamHandler) error {
+	return h.HandleStream(s)
+}
+
+// acquire admits the call if it is within the limit of its user, and
+// returns the func to call once the call is done. The limit is added to
+// the call admitted by the limit of its procedure if any, so that the
+// token of the procedure is refunded if the call is rejected.
+func (u *userRateLimitInboundMiddleware) acquire(ctx context.Context) (func(), error) {
+	if !auth.HasUser(ctx) {
+		// not authenticated by the auth middleware
+		return func() {}, nil
+	}
+	l := u.m.userLimiter(auth.GetUsername(ctx))
+	if l == nil {
+		return func() {}, nil
+	}
+
+	if call, ok := ctx.Value(rateLimitedCallKey{}).(*rateLimitedCall); ok {
+		// released by the middleware which admitted the call
+		return func() {}, call.acquire(l)
+	}
+	call, err := u.m.acquire(l)
+	if err != nil {
+		return nil, err
+	}
+	return call.release, nil
+}
+
+// NewRateLimitInboundMiddleware returns RateLimitInboundMiddleware
+// which enforces the limits of config
+func NewRateLimitInboundMiddleware(
+	config *RateLimitConfig,
+	parent tally.Scope,
+) RateLimitInboundMiddleware {
+	if config == nil {
+		config = &RateLimitConfig{}
+	}
+	scope := parent.SubScope("rate_limit")
+	return &rateLimitInboundMiddleware{
+		config:      config,
+		users:       make(map[string]*limiter),
+		procedures:  make(map[string]*limiter),
+		userMetrics: newLimiterMetrics(scope, _limitTypeUser, ""),
+		scope:       scope,
+		now:         time.Now,
+	}
+}
+
+// NewRateLimitConfigReloadWork returns a background work which
+// periodically updates the middleware with the config returned by load
+func NewRateLimitConfigReloadWork(
+	m RateLimitInboundMiddleware,
+	period time.Duration,
+	load func() (*RateLimitConfig, error),
+) background.Work {
+	return background.Work{
+		Name: _rateLimitConfigReloadWorkName,
+		Func: func(_ *atomic.Bool) {
+			config, err := load()
+			if err != nil {
+				log.WithError(err).Warn("failed to reload rate limit config")
+				return
+			}
+			m.Update(config)
+		},
+		Period: period,
+	}
+}
@@ -0,0 +1,56 @@
+package inbound
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimitConfig is the config of the limits of the inbound requests.
+// A request is admitted only if it is within the limits of its user
+// and of its procedure.
+type RateLimitConfig struct {
+	// Limits of each user which does not have its own limits,
+	// including the anonymous user. Users are not limited if not set.
+	DefaultUserLimit *LimitConfig `yaml:"default_user_limit"`
+
+	// Limits of the users by username
+	UserLimits map[string]*LimitConfig `yaml:"user_limits"`
+
+	// Limits of the procedures, shared by all the users, by procedure
+	// name such as peloton.api.v1alpha.job.stateless.svc.JobService::QueryJobs
+	ProcedureLimits map[string]*LimitConfig `yaml:"procedure_limits"`
+
+	// Interval to reload the limits from the config files,
+	// the limits are not reloaded if 0
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+// LimitConfig is the config of a limit
+type LimitConfig struct {
+	// Rate of the requests per second, which is not limited if 0
+	Rate float64 `yaml:"rate"`
+
+	// Max number of requests admitted at once above the rate,
+	// defaults to the rate rounded up
+	Burst int `yaml:"burst"`
+
+	// Max number of requests in flight, which is not limited if 0
+	MaxInFlight int `yaml:"max_in_flight"`
+}
+
+// burst returns the size of the token bucket of the limit
+func (c *LimitConfig) burst() float64 {
+	if c.Burst > 0 {
+		return float64(c.Burst)
+	}
+	return math.Max(1, math.Ceil(c.Rate))
+}
+
+// userLimit returns the limit of a user,
+// nil if the user is not limited
+func (c *RateLimitConfig) userLimit(username string) *LimitConfig {
+	if limit, ok := c.UserLimits[username]; ok {
+		return limit
+	}
+	return c.DefaultUserLimit
+}
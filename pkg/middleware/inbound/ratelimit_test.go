@@ -0,0 +1,365 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/pkg/auth"
+	auth_mocks "github.com/uber/peloton/pkg/auth/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/atomic"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_testQueryJobsProcedure = "peloton.api.v1alpha.job.stateless.svc.JobService::QueryJobs"
+	_testGetJobProcedure    = "peloton.api.v1alpha.job.stateless.svc.JobService::GetJob"
+)
+
+type RateLimitInboundMiddlewareSuite struct {
+	suite.Suite
+
+	ctrl  *gomock.Controller
+	scope tally.TestScope
+	now   time.Time
+	m     *rateLimitInboundMiddleware
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.scope = tally.NewTestScope("", map[string]string{})
+	suite.now = time.Unix(1560000000, 0)
+	suite.m = suite.newMiddleware(&RateLimitConfig{})
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) newMiddleware(
+	config *RateLimitConfig) *rateLimitInboundMiddleware {
+	m := NewRateLimitInboundMiddleware(config, suite.scope).(*rateLimitInboundMiddleware)
+	m.now = func() time.Time { return suite.now }
+	return m
+}
+
+// user returns a user which is permitted to call any procedure
+func (suite *RateLimitInboundMiddlewareSuite) user(username string) auth.User {
+	u := auth_mocks.NewMockUser(suite.ctrl)
+	u.EXPECT().Username().Return(username).AnyTimes()
+	u.EXPECT().IsPermitted(gomock.Any()).Return(true).AnyTimes()
+	return u
+}
+
+// chain returns the middleware followed by the auth middleware, which
+// authenticates the call as the user, and by the user limits
+func (suite *RateLimitInboundMiddlewareSuite) chain(u auth.User) DispatcherInboundMiddleWare {
+	s := auth_mocks.NewMockSecurityManager(suite.ctrl)
+	s.EXPECT().Authenticate(gomock.Any()).Return(u, nil).MaxTimes(1)
+	return NewChainInboundMiddleware(
+		suite.m,
+		NewAuthInboundMiddleware(s),
+		suite.m.UserLimits(),
+	)
+}
+
+// handle calls the middleware as the user with a handler which succeeds
+func (suite *RateLimitInboundMiddlewareSuite) handle(
+	u auth.User,
+	procedure string) error {
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).MaxTimes(1)
+	return suite.chain(u).Handle(
+		context.Background(), &transport.Request{Procedure: procedure}, nil, h)
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) TestNoLimits() {
+	for i := 0; i < 100; i++ {
+		suite.NoError(suite.handle(suite.user("user1"), _testQueryJobsProcedure))
+	}
+	suite.Empty(suite.m.users)
+	suite.Empty(suite.m.procedures)
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) TestUserRateLimit() {
+	suite.m = suite.newMiddleware(&RateLimitConfig{
+		DefaultUserLimit: &LimitConfig{Rate: 1, Burst: 2},
+		UserLimits: map[string]*LimitConfig{
+			"user2": {Rate: 10},
+		},
+	})
+	user1 := suite.user("user1")
+	user2 := suite.user("user2")
+
+	// the burst of user1 is admitted
+	suite.NoError(suite.handle(user1, _testQueryJobsProcedure))
+	suite.NoError(suite.handle(user1, _testGetJobProcedure))
+	err := suite.handle(user1, _testQueryJobsProcedure)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsResourceExhausted(err))
+
+	// user2 has its own limit, and the anonymous user the default limit
+	for i := 0; i < 10; i++ {
+		suite.NoError(suite.handle(user2, _testQueryJobsProcedure))
+	}
+	suite.Error(suite.handle(user2, _testQueryJobsProcedure))
+	suite.NoError(suite.handle(suite.user(""), _testQueryJobsProcedure))
+
+	// a token is added to the bucket of user1 every second
+	suite.now = suite.now.Add(time.Second)
+	suite.NoError(suite.handle(user1, _testQueryJobsProcedure))
+	suite.Error(suite.handle(user1, _testQueryJobsProcedure))
+
+	// the bucket is never filled above its burst
+	suite.now = suite.now.Add(time.Hour)
+	suite.NoError(suite.handle(user1, _testQueryJobsProcedure))
+	suite.NoError(suite.handle(user1, _testQueryJobsProcedure))
+	suite.Error(suite.handle(user1, _testQueryJobsProcedure))
+
+	// the users share their metrics
+	tags := map[string]string{"limit_type": "user"}
+	suite.Equal(int64(16), suite.counter("rate_limit.admitted", tags))
+	suite.Equal(int64(4), suite.counter("rate_limit.rejected_rate", tags))
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) TestProcedureRateLimit() {
+	suite.m = suite.newMiddleware(&RateLimitConfig{
+		ProcedureLimits: map[string]*LimitConfig{
+			_testQueryJobsProcedure: {Rate: 1},
+		},
+	})
+
+	// the limit of a procedure is shared by all users
+	suite.NoError(suite.handle(suite.user("user1"), _testQueryJobsProcedure))
+	suite.Error(suite.handle(suite.user("user2"), _testQueryJobsProcedure))
+	suite.NoError(suite.handle(suite.user("user2"), _testGetJobProcedure))
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) TestMaxInFlight() {
+	suite.m = suite.newMiddleware(&RateLimitConfig{
+		DefaultUserLimit: &LimitConfig{MaxInFlight: 1},
+	})
+	user1 := suite.user("user1")
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(context.Context, *transport.Request, transport.ResponseWriter) {
+			// the request in flight blocks the other requests of the user
+			err := suite.handle(user1, _testGetJobProcedure)
+			suite.Error(err)
+			suite.True(yarpcerrors.IsResourceExhausted(err))
+			suite.NoError(suite.handle(suite.user("user2"), _testGetJobProcedure))
+		}).
+		Return(errors.New("test error"))
+	suite.Error(suite.chain(user1).Handle(
+		context.Background(),
+		&transport.Request{Procedure: _testQueryJobsProcedure},
+		nil,
+		h))
+
+	// the request is released even if it failed
+	suite.NoError(suite.handle(user1, _testGetJobProcedure))
+	suite.Equal(int64(1), suite.counter(
+		"rate_limit.rejected_in_flight",
+		map[string]string{"limit_type": "user"}))
+}
+
+// TestRejectedByUserRefundsProcedure tests that the token of the
+// procedure is refunded if the call is rejected by the limit of its user
+func (suite *RateLimitInboundMiddlewareSuite) TestRejectedByUserRefundsProcedure() {
+	suite.m = suite.newMiddleware(&RateLimitConfig{
+		UserLimits: map[string]*LimitConfig{"user1": {Rate: 1}},
+		ProcedureLimits: map[string]*LimitConfig{
+			_testQueryJobsProcedure: {Rate: 1, Burst: 2},
+		},
+	})
+	user1 := suite.user("user1")
+	user2 := suite.user("user2")
+
+	suite.NoError(suite.handle(user1, _testQueryJobsProcedure))
+	suite.Error(suite.handle(user1, _testQueryJobsProcedure))
+	suite.NoError(suite.handle(user2, _testQueryJobsProcedure))
+	suite.Error(suite.handle(user2, _testQueryJobsProcedure))
+
+	suite.Equal(0, suite.m.procedures[_testQueryJobsProcedure].inFlight)
+	suite.Equal(int64(1), suite.counter(
+		"rate_limit.refunded",
+		map[string]string{
+			"limit_type": "procedure",
+			"limit_key":  _testQueryJobsProcedure,
+		}))
+}
+
+// TestCallCancelsAcquired tests that the limits which admitted a
+// call are cancelled if another limit rejects the call
+func (suite *RateLimitInboundMiddlewareSuite) TestCallCancelsAcquired() {
+	suite.m = suite.newMiddleware(&RateLimitConfig{
+		DefaultUserLimit: &LimitConfig{Rate: 1, MaxInFlight: 1},
+		ProcedureLimits: map[string]*LimitConfig{
+			_testQueryJobsProcedure: {Rate: 1},
+		},
+	})
+	user := suite.m.userLimiter("user1")
+	procedure := suite.m.procedureLimiter(_testQueryJobsProcedure)
+
+	call, err := suite.m.acquire(procedure)
+	suite.NoError(err)
+	call.release()
+
+	_, err = suite.m.acquire(user, procedure)
+	suite.Error(err)
+	suite.Equal(0, user.inFlight)
+	suite.Equal(float64(1), user.tokens)
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) TestEvictIdleUsers() {
+	suite.m = suite.newMiddleware(&RateLimitConfig{
+		DefaultUserLimit: &LimitConfig{Rate: 1},
+	})
+	suite.NoError(suite.handle(suite.user("user1"), _testQueryJobsProcedure))
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(context.Context, *transport.Request, transport.ResponseWriter) {
+			// user1 is idle, but user2 has a request in flight
+			suite.now = suite.now.Add(_userLimiterIdleTimeout)
+			suite.NoError(suite.handle(suite.user("user3"), _testQueryJobsProcedure))
+		}).
+		Return(nil)
+	suite.NoError(suite.chain(suite.user("user2")).Handle(
+		context.Background(),
+		&transport.Request{Procedure: _testQueryJobsProcedure},
+		nil,
+		h))
+
+	suite.NotContains(suite.m.users, "user1")
+	suite.Contains(suite.m.users, "user2")
+	suite.Contains(suite.m.users, "user3")
+}
+
+// TestUserLimitsWithoutProcedureLimits tests that the user limits are
+// enforced without the middleware enforcing the procedure limits
+func (suite *RateLimitInboundMiddlewareSuite) TestUserLimitsWithoutProcedureLimits() {
+	suite.m = suite.newMiddleware(&RateLimitConfig{
+		DefaultUserLimit: &LimitConfig{Rate: 1, MaxInFlight: 1},
+	})
+	m := suite.m.UserLimits()
+	ctx := auth.WithUser(context.Background(), suite.user("user1"), _testQueryJobsProcedure)
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(context.Context, *transport.Request, transport.ResponseWriter) {
+			suite.Equal(1, suite.m.users["user1"].inFlight)
+		}).
+		Return(nil)
+	suite.NoError(m.Handle(ctx, &transport.Request{}, nil, h))
+	suite.Equal(0, suite.m.users["user1"].inFlight)
+	suite.Error(m.Handle(ctx, &transport.Request{}, nil, h))
+
+	// the calls which are not authenticated are not limited
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	suite.NoError(m.Handle(context.Background(), &transport.Request{}, nil, h))
+	suite.NoError(m.Handle(context.Background(), &transport.Request{}, nil, h))
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) TestHandleOneway() {
+	suite.m = suite.newMiddleware(&RateLimitConfig{
+		DefaultUserLimit: &LimitConfig{Rate: 1},
+	})
+	user1 := suite.user("user1")
+
+	h := transporttest.NewMockOnewayHandler(suite.ctrl)
+	h.EXPECT().HandleOneway(gomock.Any(), gomock.Any()).Return(nil)
+	suite.NoError(suite.chain(user1).HandleOneway(context.Background(), &transport.Request{}, h))
+	suite.Error(suite.chain(user1).HandleOneway(context.Background(), &transport.Request{}, h))
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) TestHandleStream() {
+	procedure := "peloton.api.v1alpha.watch.svc.WatchService::Watch"
+	suite.m = suite.newMiddleware(&RateLimitConfig{
+		ProcedureLimits: map[string]*LimitConfig{procedure: {MaxInFlight: 1}},
+	})
+
+	h := transporttest.NewMockStreamHandler(suite.ctrl)
+	s := transporttest.NewMockStream(suite.ctrl)
+	s.EXPECT().
+		Request().
+		Return(&transport.StreamRequest{
+			Meta: &transport.RequestMeta{Procedure: procedure},
+		}).
+		AnyTimes()
+	ss, err := transport.NewServerStream(s)
+	suite.NoError(err)
+
+	h.EXPECT().HandleStream(ss).
+		Do(func(*transport.ServerStream) {
+			suite.Error(suite.m.HandleStream(ss, h))
+		}).
+		Return(nil)
+	suite.NoError(suite.m.HandleStream(ss, h))
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) TestUpdate() {
+	suite.m = suite.newMiddleware(&RateLimitConfig{
+		DefaultUserLimit: &LimitConfig{Rate: 1, Burst: 2},
+		ProcedureLimits: map[string]*LimitConfig{
+			_testQueryJobsProcedure: {Rate: 5},
+		},
+	})
+	user1 := suite.user("user1")
+	suite.NoError(suite.handle(user1, _testQueryJobsProcedure))
+
+	// the tokens left are capped by the updated burst
+	suite.m.Update(&RateLimitConfig{
+		UserLimits: map[string]*LimitConfig{"user1": {Rate: 1}},
+	})
+	suite.Contains(suite.m.users, "user1")
+	suite.NotContains(suite.m.procedures, _testQueryJobsProcedure)
+	suite.NoError(suite.handle(user1, _testQueryJobsProcedure))
+	suite.Error(suite.handle(user1, _testQueryJobsProcedure))
+
+	// the users which are no longer limited are dropped
+	suite.m.Update(nil)
+	suite.Empty(suite.m.users)
+	suite.NoError(suite.handle(user1, _testQueryJobsProcedure))
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) TestConfigReloadWork() {
+	config := &RateLimitConfig{DefaultUserLimit: &LimitConfig{Rate: 1}}
+	var loadErr error
+	work := NewRateLimitConfigReloadWork(suite.m, time.Minute, func() (*RateLimitConfig, error) {
+		return config, loadErr
+	})
+	suite.Equal(time.Minute, work.Period)
+
+	work.Func(atomic.NewBool(true))
+	suite.Equal(config, suite.m.config)
+
+	// the limits are kept if the config cannot be loaded
+	loadErr = errors.New("test error")
+	work.Func(atomic.NewBool(true))
+	suite.Equal(config, suite.m.config)
+}
+
+func (suite *RateLimitInboundMiddlewareSuite) counter(
+	name string,
+	tags map[string]string) int64 {
+	for _, c := range suite.scope.Snapshot().Counters() {
+		if c.Name() == name && c.Tags()["limit_type"] == tags["limit_type"] &&
+			c.Tags()["limit_key"] == tags["limit_key"] {
+			return c.Value()
+		}
+	}
+	return 0
+}
+
+func TestRateLimitInboundMiddlewareSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitInboundMiddlewareSuite))
+}
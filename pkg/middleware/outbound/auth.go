@@ -0,0 +1,44 @@
+package outbound
+
+import (
+	"context"
+
+	"github.com/uber/peloton/pkg/auth"
+	"go.uber.org/yarpc/api/transport"
+)
+
+type authOutboundMiddleware struct {
+	auth.SecurityClient
+}
+
+func (m *authOutboundMiddleware) Call(ctx context.Context, req *transport.Request, out transport.UnaryOutbound) (*transport.Response, error) {
+	req.Headers = m.withCredentials(req.Headers)
+	return out.Call(ctx, req)
+}
+
+func (m *authOutboundMiddleware) CallOneway(ctx context.Context, req *transport.Request, out transport.OnewayOutbound) (transport.Ack, error) {
+	req.Headers = m.withCredentials(req.Headers)
+	return out.CallOneway(ctx, req)
+}
+
+func (m *authOutboundMiddleware) CallStream(ctx context.Context, req *transport.StreamRequest, out transport.StreamOutbound) (*transport.ClientStream, error) {
+	req.Meta.Headers = m.withCredentials(req.Meta.Headers)
+	return out.CallStream(ctx, req)
+}
+
+// withCredentials adds the credentials of the component to the headers,
+// so that its calls are authenticated by the other components
+func (m *authOutboundMiddleware) withCredentials(headers transport.Headers) transport.Headers {
+	for k, v := range m.GetCredentials() {
+		headers = headers.With(k, v)
+	}
+	return headers
+}
+
+// NewAuthOutboundMiddleware returns DispatcherOutboundMiddleWare
+// which passes the credentials of the security client
+func NewAuthOutboundMiddleware(security auth.SecurityClient) DispatcherOutboundMiddleWare {
+	return &authOutboundMiddleware{
+		SecurityClient: security,
+	}
+}
@@ -0,0 +1,86 @@
+package outbound
+
+import (
+	"context"
+	"testing"
+
+	auth_mocks "github.com/uber/peloton/pkg/auth/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+)
+
+type AuthOutboundMiddlewareSuite struct {
+	suite.Suite
+
+	ctrl *gomock.Controller
+	m    DispatcherOutboundMiddleWare
+	c    *auth_mocks.MockSecurityClient
+}
+
+func (suite *AuthOutboundMiddlewareSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.c = auth_mocks.NewMockSecurityClient(suite.ctrl)
+	suite.m = NewAuthOutboundMiddleware(suite.c)
+}
+
+func (suite *AuthOutboundMiddlewareSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *AuthOutboundMiddlewareSuite) TestCallPassesCredentials() {
+	out := transporttest.NewMockUnaryOutbound(suite.ctrl)
+	suite.c.EXPECT().GetCredentials().
+		Return(map[string]string{"username": "peloton", "password": "secret"})
+	out.EXPECT().Call(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *transport.Request) {
+			username, _ := req.Headers.Get("username")
+			suite.Equal("peloton", username)
+			password, _ := req.Headers.Get("password")
+			suite.Equal("secret", password)
+			// the headers of the request are kept
+			value, _ := req.Headers.Get("key")
+			suite.Equal("value", value)
+		}).
+		Return(&transport.Response{}, nil)
+
+	_, err := suite.m.Call(
+		context.Background(),
+		&transport.Request{Headers: transport.NewHeaders().With("key", "value")},
+		out)
+	suite.NoError(err)
+}
+
+func (suite *AuthOutboundMiddlewareSuite) TestCallWithoutCredentials() {
+	out := transporttest.NewMockUnaryOutbound(suite.ctrl)
+	suite.c.EXPECT().GetCredentials().Return(nil)
+	out.EXPECT().Call(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *transport.Request) {
+			suite.Equal(0, req.Headers.Len())
+		}).
+		Return(&transport.Response{}, nil)
+
+	_, err := suite.m.Call(context.Background(), &transport.Request{}, out)
+	suite.NoError(err)
+}
+
+func (suite *AuthOutboundMiddlewareSuite) TestCallOnewayPassesCredentials() {
+	out := transporttest.NewMockOnewayOutbound(suite.ctrl)
+	suite.c.EXPECT().GetCredentials().
+		Return(map[string]string{"authorization": "Bearer token"})
+	out.EXPECT().CallOneway(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *transport.Request) {
+			authorization, _ := req.Headers.Get("authorization")
+			suite.Equal("Bearer token", authorization)
+		}).
+		Return(nil, nil)
+
+	_, err := suite.m.CallOneway(context.Background(), &transport.Request{}, out)
+	suite.NoError(err)
+}
+
+func TestAuthOutboundMiddleware(t *testing.T) {
+	suite.Run(t, new(AuthOutboundMiddlewareSuite))
+}
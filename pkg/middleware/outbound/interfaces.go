@@ -0,0 +1,12 @@
+package outbound
+
+import "go.uber.org/yarpc/api/middleware"
+
+// DispatcherOutboundMiddleWare implements the union of
+// all outbound middleware interface, so all of the peloton
+// outbound requests can utilize the middleware
+type DispatcherOutboundMiddleWare interface {
+	middleware.UnaryOutbound
+	middleware.OnewayOutbound
+	middleware.StreamOutbound
+}
@@ -377,6 +377,9 @@ func (e *engine) filterAssignments(
 			// we haven't found an assignment yet
 			if task.PastDeadline(now) {
 				// tried enough
+				if assignment.GetReason() == "" {
+					assignment.SetReason(_failedToPlaceTaskAfterTimeout)
+				}
 				unassigned = append(unassigned, assignment)
 				continue
 			}
@@ -504,6 +504,7 @@ func TestEngineFilterAssignments(t *testing.T) {
 	assert.Equal(t, []*models.Assignment{assignment3, assignment6}, retryable)
 	assert.Equal(t, 1, len(unassigned))
 	assert.Equal(t, []*models.Assignment{assignment4}, unassigned)
+	assert.Equal(t, _failedToPlaceTaskAfterTimeout, assignment4.GetReason())
 }
 
 func TestEngineCleanup(t *testing.T) {
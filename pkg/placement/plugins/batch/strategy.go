@@ -105,6 +105,12 @@ func (batch *batch) getHostFilter(assignment *models.Assignment) *hostsvc.HostFi
 			NumPorts:  assignment.GetTask().GetTask().NumPorts,
 			Revocable: assignment.GetTask().GetTask().Revocable,
 		},
+		// long-running tasks are not placed on hosts which are going
+		// to be drained for maintenance
+		ExcludeScheduledMaintenance: plugins.IsLongRunning(assignment),
+		// tasks are not placed on hosts which are going to be made
+		// unavailable by Mesos before the tasks finish
+		MaxRunningTimeSecs: assignment.GetTask().GetTask().GetMaxRunningTime(),
 	}
 	if constraint := assignment.GetTask().GetTask().Constraint; constraint != nil {
 		result.SchedulingConstraint = constraint
@@ -132,8 +138,10 @@ func (batch *batch) Filters(assignments []*models.Assignment) map[*hostsvc.HostF
 	result := map[*hostsvc.HostFilter][]*models.Assignment{}
 	for filter, assignments := range filters {
 		filterWithQuantity := &hostsvc.HostFilter{
-			ResourceConstraint:   filter.GetResourceConstraint(),
-			SchedulingConstraint: filter.GetSchedulingConstraint(),
+			ResourceConstraint:          filter.GetResourceConstraint(),
+			SchedulingConstraint:        filter.GetSchedulingConstraint(),
+			ExcludeScheduledMaintenance: filter.GetExcludeScheduledMaintenance(),
+			MaxRunningTimeSecs:          filter.GetMaxRunningTimeSecs(),
 			Quantity: &hostsvc.QuantityControl{
 				MaxHosts: uint32(len(assignments)),
 			},
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/pkg/placement/models"
 	"github.com/uber/peloton/pkg/placement/testutil"
 )
@@ -106,3 +107,41 @@ func TestBatchFiltersWithPorts(t *testing.T) {
 		}
 	}
 }
+
+func TestBatchFiltersExcludeScheduledMaintenance(t *testing.T) {
+	assignments := []*models.Assignment{
+		testutil.SetupAssignment(time.Now().Add(10*time.Second), 1),
+		testutil.SetupAssignment(time.Now().Add(10*time.Second), 1),
+	}
+	assignments[1].GetTask().GetTask().Type = resmgr.TaskType_STATELESS
+	strategy := New()
+
+	filters := strategy.Filters(assignments)
+
+	assert.Equal(t, 2, len(filters))
+	for filter, batch := range filters {
+		assert.Equal(t, 1, len(batch))
+		assert.Equal(t,
+			batch[0].GetTask().GetTask().GetType() == resmgr.TaskType_STATELESS,
+			filter.GetExcludeScheduledMaintenance())
+	}
+}
+
+func TestBatchFiltersMaxRunningTime(t *testing.T) {
+	assignments := []*models.Assignment{
+		testutil.SetupAssignment(time.Now().Add(10*time.Second), 1),
+		testutil.SetupAssignment(time.Now().Add(10*time.Second), 1),
+	}
+	assignments[0].GetTask().GetTask().MaxRunningTime = 100
+	strategy := New()
+
+	filters := strategy.Filters(assignments)
+
+	assert.Equal(t, 2, len(filters))
+	for filter, batch := range filters {
+		assert.Equal(t, 1, len(batch))
+		assert.Equal(t,
+			batch[0].GetTask().GetTask().GetMaxRunningTime(),
+			filter.GetMaxRunningTimeSecs())
+	}
+}
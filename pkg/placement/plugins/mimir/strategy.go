@@ -157,8 +157,12 @@ func (mimir *mimir) PlaceOnce(
 func (mimir *mimir) Filters(assignments []*models.Assignment) map[*hostsvc.HostFilter][]*models.Assignment {
 	assignmentsCopy := make([]*models.Assignment, 0, len(assignments))
 	var maxCPU, maxGPU, maxMemory, maxDisk, maxPorts float64
-	var revocable bool
+	var revocable, longRunning bool
 	var hostHints []*hostsvc.FilterHint_Host
+	// the max running time of the assignments, which is unbounded
+	// if any of the assignments has no max running time
+	var maxRunningTime uint32
+	unboundedRunningTime := false
 	for _, assignment := range assignments {
 		assignmentsCopy = append(assignmentsCopy, assignment)
 		resmgrTask := assignment.GetTask().GetTask()
@@ -168,6 +172,14 @@ func (mimir *mimir) Filters(assignments []*models.Assignment) map[*hostsvc.HostF
 		maxDisk = math.Max(maxDisk, resmgrTask.Resource.DiskLimitMb)
 		maxPorts = math.Max(maxPorts, float64(resmgrTask.NumPorts))
 		revocable = resmgrTask.Revocable
+		if plugins.IsLongRunning(assignment) {
+			longRunning = true
+		}
+		if resmgrTask.GetMaxRunningTime() == 0 {
+			unboundedRunningTime = true
+		} else if resmgrTask.GetMaxRunningTime() > maxRunningTime {
+			maxRunningTime = resmgrTask.GetMaxRunningTime()
+		}
 		if len(resmgrTask.GetDesiredHost()) != 0 {
 			hostHints = append(hostHints, &hostsvc.FilterHint_Host{
 				Hostname: resmgrTask.GetDesiredHost(),
@@ -181,6 +193,9 @@ func (mimir *mimir) Filters(assignments []*models.Assignment) map[*hostsvc.HostF
 	if float64(maxOffers) > neededOffers {
 		maxOffers = int(neededOffers)
 	}
+	if unboundedRunningTime {
+		maxRunningTime = 0
+	}
 	return map[*hostsvc.HostFilter][]*models.Assignment{
 		{
 			ResourceConstraint: &hostsvc.ResourceConstraint{
@@ -199,6 +214,8 @@ func (mimir *mimir) Filters(assignments []*models.Assignment) map[*hostsvc.HostF
 			Hint: &hostsvc.FilterHint{
 				HostHint: hostHints,
 			},
+			ExcludeScheduledMaintenance: longRunning,
+			MaxRunningTimeSecs:          maxRunningTime,
 		}: assignmentsCopy,
 	}
 }
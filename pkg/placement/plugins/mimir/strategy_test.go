@@ -182,3 +182,24 @@ func TestMimirFilters(t *testing.T) {
 		}
 	}
 }
+
+func TestMimirFiltersMaxRunningTime(t *testing.T) {
+	strategy := setupStrategy()
+
+	deadline := time.Now().Add(30 * time.Second)
+	assignments := []*models.Assignment{
+		testutil.SetupAssignment(deadline, 1),
+		testutil.SetupAssignment(deadline, 1),
+	}
+	assignments[0].GetTask().GetTask().MaxRunningTime = 100
+	assignments[1].GetTask().GetTask().MaxRunningTime = 200
+	for filter := range strategy.Filters(assignments) {
+		assert.Equal(t, uint32(200), filter.GetMaxRunningTimeSecs())
+	}
+
+	// the running time is unbounded if any assignment has no max running time
+	assignments[1].GetTask().GetTask().MaxRunningTime = 0
+	for filter := range strategy.Filters(assignments) {
+		assert.Equal(t, uint32(0), filter.GetMaxRunningTimeSecs())
+	}
+}
@@ -16,6 +16,7 @@ package plugins
 
 import (
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/pkg/placement/models"
 )
 
@@ -36,3 +37,16 @@ type Strategy interface {
 	// go-routine is allowed to run the PlaceOnce method at a time.
 	ConcurrencySafe() bool
 }
+
+// IsLongRunning returns true if the task of the assignment is long-running,
+// in which case it should not be placed on hosts which are going to be
+// drained for maintenance.
+func IsLongRunning(assignment *models.Assignment) bool {
+	switch assignment.GetTask().GetTask().GetType() {
+	case resmgr.TaskType_STATELESS,
+		resmgr.TaskType_STATEFUL,
+		resmgr.TaskType_DAEMON:
+		return true
+	}
+	return false
+}
@@ -30,6 +30,10 @@ type PreemptionConfig struct {
 	// If the value exceeds this number then the preemption logic will kick
 	// in to reduce the allocation.
 	SustainedOverAllocationCount int `yaml:"sustained_over_allocation_count"`
+
+	// Name of the ranker which ranks the tasks of a resource pool to be
+	// preempted. Defaults to STATE_PRIORITY_RUNTIME if not set.
+	Ranker string `yaml:"ranker"`
 }
 
 // RecoveryConfig is the container for recovery related config
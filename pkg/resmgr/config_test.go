@@ -38,6 +38,7 @@ const testConfig = `
     task_preemption_period: 60s
     sustained_over_allocation_count: 5
     enabled: true
+    ranker: COST_AWARE
 `
 
 func writeFile(t *testing.T, contents string) string {
@@ -67,4 +68,5 @@ func TestResourceManagerConfig(t *testing.T) {
 	assert.Equal(t, 1*time.Minute, testConfig.PreemptionConfig.TaskPreemptionPeriod)
 	assert.Equal(t, 5, testConfig.PreemptionConfig.SustainedOverAllocationCount)
 	assert.Equal(t, true, testConfig.PreemptionConfig.Enabled)
+	assert.Equal(t, "COST_AWARE", testConfig.PreemptionConfig.Ranker)
 }
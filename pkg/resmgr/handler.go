@@ -841,6 +841,7 @@ func (h *ServiceHandler) fillTaskEntry(task *rmtask.RMTask,
 		TaskState:      rmTaskState.State.String(),
 		Reason:         rmTaskState.Reason,
 		LastUpdateTime: rmTaskState.LastUpdateTime.String(),
+		PendingReason:  task.PendingReason(),
 	}
 	return taskEntry
 }
@@ -18,6 +18,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 
@@ -157,14 +159,35 @@ func (d *Drainer) drainHosts() error {
 	// Get all tasks on the DRAINING hosts
 	tasksByHost := d.rmTracker.TasksByHosts(drainingHosts, resmgr.TaskType_UNKNOWN)
 	var drainedHosts []string
+	var blockedHosts []*hostsvc.BlockedHost
+	var blocked int
+	// job ID -> number of running instances of the job which can
+	// still be evicted in this cycle without exceeding its SLA
+	evictable := make(map[string]uint32)
 	for _, host := range drainingHosts {
 		if _, ok := tasksByHost[host]; !ok {
 			drainedHosts = append(drainedHosts, host)
 			continue
 		}
 
+		tasks, blockingJobs := d.gateEvictions(tasksByHost[host], evictable)
+		blockedHosts = append(blockedHosts, &hostsvc.BlockedHost{
+			Hostname:     host,
+			BlockingJobs: blockingJobs,
+		})
+		if len(blockingJobs) != 0 {
+			blocked++
+			log.WithFields(log.Fields{
+				"host":          host,
+				"blocking_jobs": blockingJobs,
+			}).Info("Host drain blocked by job SLA")
+		}
+		if len(tasks) == 0 {
+			continue
+		}
+
 		err := d.preemptionQueue.EnqueueTasks(
-			tasksByHost[host],
+			tasks,
 			resmgr.PreemptionReason_PREEMPTION_REASON_HOST_MAINTENANCE)
 		if err != nil {
 			log.WithField("host", host).
@@ -173,6 +196,12 @@ func (d *Drainer) drainHosts() error {
 			errs = multierror.Append(errs, err)
 		}
 	}
+	d.metrics.HostsBlockedBySLA.Update(float64(blocked))
+	if len(blockedHosts) != 0 {
+		if err := d.markHostsBlocked(blockedHosts); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
 	if len(drainedHosts) != 0 {
 		err := d.markHostsDrained(drainedHosts)
 		if err != nil {
@@ -184,6 +213,84 @@ func (d *Drainer) drainHosts() error {
 	return errs
 }
 
+// gateEvictions returns the tasks of a host which can be evicted without
+// exceeding the maximum unavailable instances of their job, and the jobs
+// blocking the drain of the host. The instances which are being preempted
+// are unavailable, and evictable is updated with the running tasks returned.
+// The job manager checks the SLA of the jobs again with the tasks it knows
+// of before evicting the tasks, and the jobs of the tasks whose eviction it
+// held also block the host.
+func (d *Drainer) gateEvictions(
+	tasks []*rmtask.RMTask,
+	evictable map[string]uint32,
+) ([]*rmtask.RMTask, []*peloton.JobID) {
+	var tasksToEvict []*rmtask.RMTask
+	var blockingJobs []*peloton.JobID
+	blocking := stringset.New()
+	block := func(jobID *peloton.JobID) {
+		if !blocking.Contains(jobID.GetValue()) {
+			blocking.Add(jobID.GetValue())
+			blockingJobs = append(blockingJobs, jobID)
+		}
+	}
+
+	for _, t := range tasks {
+		jobID := t.Task().GetJobId()
+		if t.EvictionHeld() {
+			block(jobID)
+		}
+
+		limit := t.Task().GetMaximumUnavailableInstances()
+		if limit == 0 || t.GetCurrentState().State != task.TaskState_RUNNING {
+			tasksToEvict = append(tasksToEvict, t)
+			continue
+		}
+
+		remaining, ok := evictable[jobID.GetValue()]
+		if !ok {
+			remaining = limit - d.getPreemptingInstances(jobID.GetValue(), limit)
+		}
+		if remaining == 0 {
+			evictable[jobID.GetValue()] = 0
+			d.metrics.TasksHeldBySLA.Inc(1)
+			block(jobID)
+			continue
+		}
+		evictable[jobID.GetValue()] = remaining - 1
+		tasksToEvict = append(tasksToEvict, t)
+	}
+	return tasksToEvict, blockingJobs
+}
+
+// getPreemptingInstances returns the number of instances of the job which
+// are being preempted, up to the limit
+func (d *Drainer) getPreemptingInstances(jobID string, limit uint32) uint32 {
+	var preempting uint32
+	for _, tasks := range d.rmTracker.GetActiveTasks(
+		jobID,
+		"",
+		[]string{task.TaskState_PREEMPTING.String()}) {
+		preempting += uint32(len(tasks))
+	}
+	if preempting > limit {
+		return limit
+	}
+	return preempting
+}
+
+// markHostsBlocked sets the jobs blocking the drain of the hosts in
+// host manager, so that they are returned by the host query API
+func (d *Drainer) markHostsBlocked(hosts []*hostsvc.BlockedHost) error {
+	ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+	defer cancel()
+	_, err := d.hostMgrClient.MarkHostsBlocked(
+		ctx,
+		&hostsvc.MarkHostsBlockedRequest{
+			Hosts: hosts,
+		})
+	return err
+}
+
 func (d *Drainer) markHostsDrained(hosts []string) error {
 	err := backoff.Retry(
 		func() error {
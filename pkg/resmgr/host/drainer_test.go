@@ -15,11 +15,13 @@
 package host
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	host_mocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
@@ -35,6 +37,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
 )
 
 const (
@@ -77,6 +80,7 @@ func (suite *DrainerTestSuite) SetupTest() {
 	suite.drainer = Drainer{
 		drainerPeriod:   drainerPeriod,
 		hostMgrClient:   suite.mockHostmgr,
+		metrics:         NewMetrics(tally.NoopScope),
 		preemptionQueue: suite.preemptor,
 		rmTracker:       suite.tracker,
 		lifecycle:       lifecycle.NewLifeCycle(),
@@ -103,6 +107,16 @@ func (suite *DrainerTestSuite) addTaskToTracker(t *resmgr.Task) {
 		&rm_task.Config{})
 }
 
+// transitTask moves a task of the tracker through the states
+func (suite *DrainerTestSuite) transitTask(
+	taskID *peloton.TaskID,
+	states ...task.TaskState) {
+	rmTask := suite.tracker.GetTask(taskID)
+	for _, state := range states {
+		suite.NoError(rmTask.TransitTo(state.String()))
+	}
+}
+
 func TestDrainer(t *testing.T) {
 	suite.Run(t, new(DrainerTestSuite))
 }
@@ -149,6 +163,9 @@ func (suite *DrainerTestSuite) TestDrainCycle_EnqueueError() {
 	suite.preemptor.EXPECT().
 		EnqueueTasks(gomock.Any(), gomock.Any()).
 		Return(fmt.Errorf("fake Enqueue error"))
+	suite.mockHostmgr.EXPECT().
+		MarkHostsBlocked(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.MarkHostsBlockedResponse{}, nil)
 	err := suite.drainer.performDrainCycle()
 	suite.Error(err)
 	suite.drainer.drainingHosts.Clear()
@@ -160,6 +177,9 @@ func (suite *DrainerTestSuite) TestDrainCycle() {
 	suite.preemptor.EXPECT().
 		EnqueueTasks(gomock.Any(), gomock.Any()).
 		Return(nil).Times(2)
+	suite.mockHostmgr.EXPECT().
+		MarkHostsBlocked(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.MarkHostsBlockedResponse{}, nil).Times(2)
 
 	// simulate 2 cycles
 	for i := 0; i < 2; i++ {
@@ -217,3 +237,99 @@ func (suite *DrainerTestSuite) TestDrainCycle_MarkHostsDrained() {
 	err := suite.drainer.performDrainCycle()
 	suite.NoError(err)
 }
+
+// TestDrainCycle_SLA tests that the running tasks of a job are not evicted
+// beyond the maximum unavailable instances of the job, and that the jobs
+// holding the eviction of their tasks block the drain of their hosts
+func (suite *DrainerTestSuite) TestDrainCycle_SLA() {
+	suite.tracker.Clear()
+
+	running := []task.TaskState{
+		task.TaskState_PENDING,
+		task.TaskState_READY,
+		task.TaskState_PLACING,
+		task.TaskState_PLACED,
+		task.TaskState_LAUNCHING,
+		task.TaskState_RUNNING,
+	}
+
+	slaJobID := &peloton.JobID{Value: "sla-job"}
+	for i, hostname := range []string{"host1", "host1", "host3"} {
+		taskID := &peloton.TaskID{Value: fmt.Sprintf("sla-job-%d", i)}
+		suite.addTaskToTracker(&resmgr.Task{
+			Name:                        taskName,
+			JobId:                       slaJobID,
+			Id:                          taskID,
+			Hostname:                    hostname,
+			MaximumUnavailableInstances: 2,
+		})
+		suite.transitTask(taskID, running...)
+	}
+	// one instance of the job is already being preempted
+	suite.transitTask(
+		&peloton.TaskID{Value: "sla-job-2"},
+		task.TaskState_PREEMPTING)
+
+	// the job manager held the eviction of the task of the job
+	heldJobID := &peloton.JobID{Value: "held-job"}
+	heldTaskID := &peloton.TaskID{Value: "held-job-0"}
+	suite.addTaskToTracker(&resmgr.Task{
+		Name:     taskName,
+		JobId:    heldJobID,
+		Id:       heldTaskID,
+		Hostname: "host2",
+	})
+	suite.transitTask(heldTaskID, running...)
+	suite.transitTask(
+		heldTaskID,
+		task.TaskState_PREEMPTING,
+		task.TaskState_RUNNING)
+
+	suite.mockHostmgr.EXPECT().
+		GetDrainingHosts(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetDrainingHostsResponse{
+			Hostnames: []string{"host1", "host2"},
+		}, nil)
+
+	var enqueued []*rm_task.RMTask
+	suite.preemptor.EXPECT().
+		EnqueueTasks(
+			gomock.Any(),
+			resmgr.PreemptionReason_PREEMPTION_REASON_HOST_MAINTENANCE).
+		Do(func(tasks []*rm_task.RMTask, _ resmgr.PreemptionReason) {
+			enqueued = append(enqueued, tasks...)
+		}).
+		Return(nil).
+		Times(2)
+
+	blockingJobs := make(map[string][]*peloton.JobID)
+	suite.mockHostmgr.EXPECT().
+		MarkHostsBlocked(gomock.Any(), gomock.Any()).
+		Do(func(
+			_ context.Context,
+			req *hostsvc.MarkHostsBlockedRequest,
+			_ ...yarpc.CallOption) {
+			for _, host := range req.GetHosts() {
+				blockingJobs[host.GetHostname()] = host.GetBlockingJobs()
+			}
+		}).
+		Return(&hostsvc.MarkHostsBlockedResponse{}, nil)
+
+	suite.NoError(suite.drainer.performDrainCycle())
+
+	// a single instance of the job can still be made unavailable
+	var slaJobTasks int
+	for _, t := range enqueued {
+		if t.Task().GetJobId().GetValue() == slaJobID.GetValue() {
+			slaJobTasks++
+		}
+	}
+	suite.Equal(1, slaJobTasks)
+	// the held task is evicted again
+	suite.Len(enqueued, 2)
+
+	suite.Equal(map[string][]*peloton.JobID{
+		"host1": {slaJobID},
+		"host2": {heldJobID},
+	}, blockingJobs)
+}
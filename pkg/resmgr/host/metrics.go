@@ -20,6 +20,13 @@ import "github.com/uber-go/tally"
 type Metrics struct {
 	HostDrainSuccess tally.Counter
 	HostDrainFail    tally.Counter
+
+	// TasksHeldBySLA counts the tasks which are not evicted from the
+	// hosts being drained because of the SLA of their job
+	TasksHeldBySLA tally.Counter
+	// HostsBlockedBySLA is the number of hosts whose drain was
+	// blocked by a job SLA in the last drain cycle
+	HostsBlockedBySLA tally.Gauge
 }
 
 // NewMetrics returns a new instance of host.Metrics.
@@ -29,5 +36,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	return &Metrics{
 		HostDrainSuccess: hostSuccessScope.Counter("host_drain"),
 		HostDrainFail:    hostFailScope.Counter("host_drain"),
+
+		TasksHeldBySLA:    scope.Counter("tasks_held_by_sla"),
+		HostsBlockedBySLA: scope.Gauge("hosts_blocked_by_sla"),
 	}
 }
@@ -0,0 +1,293 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preemption
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+	rm_task "github.com/uber/peloton/pkg/resmgr/task"
+)
+
+// task states which are evicted before the running tasks, since no work
+// is lost by evicting them
+var nonRunningStatesPreemptionOrder = []task.TaskState{
+	task.TaskState_READY,
+	task.TaskState_PLACING,
+}
+
+// costAwareRanker ranks the tasks such that the work lost by preemption
+// is minimised. Tasks which are not running are evicted first, in the same
+// order as the statePriorityRuntimeRanker. Running tasks are sorted on the
+// task Priority, then the evictions are spread across the jobs, and then
+// the tasks are sorted on their progress, which is their runtime relative
+// to the max running time of their job, so that the tasks which are about
+// to finish are evicted last. Tasks of jobs without a max running time
+// have no progress and are sorted on their runtime.
+//
+// A job does not get more running instances evicted than the maximum
+// unavailable instances of its SLA, including the instances which are
+// already being preempted. Tasks beyond that limit are evicted only if
+// the resources cannot be freed otherwise.
+type costAwareRanker struct {
+	tracker rm_task.Tracker
+	sorter  taskSorter
+	// returns the current time, used to compute the runtime of the tasks
+	now func() time.Time
+}
+
+// newCostAwareRanker returns a new instance of the costAwareRanker
+func newCostAwareRanker(tracker rm_task.Tracker) Ranker {
+	return &costAwareRanker{
+		tracker: tracker,
+		sorter: taskSorter{
+			cmpFuncs: []cmpFunc{
+				priorityCmp,
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// GetTasksToEvict returns the tasks in the order in which they should be evicted from
+// the resource pool such that the cumulative resources of those tasks >= requiredResources
+func (r *costAwareRanker) GetTasksToEvict(
+	respoolID string,
+	slackResourcesToFree, nonSlackResourcesToFree *scalar.Resources) []*rm_task.RMTask {
+
+	// get all active tasks for this resource pool
+	stateTaskMap := r.tracker.GetActiveTasks("", respoolID, nil)
+
+	// the instances which are being preempted are already unavailable
+	unavailable := make(map[string]uint32)
+	for _, t := range stateTaskMap[task.TaskState_PREEMPTING.String()] {
+		unavailable[t.Task().GetJobId().GetValue()]++
+	}
+
+	// get revocable tasks to preempt to free slack resources
+	revocableTasksToEvict := r.selectTasks(
+		filterRevocableTasks,
+		stateTaskMap,
+		unavailable,
+		slackResourcesToFree)
+
+	// get non-revocable preemptible tasks to preempt to free
+	// non-slack resources
+	nonRevocTasksToEvict := r.selectTasks(
+		filterNonRevocableTasks,
+		stateTaskMap,
+		unavailable,
+		nonSlackResourcesToFree)
+	return append(revocableTasksToEvict, nonRevocTasksToEvict...)
+}
+
+// selectTasks selects the tasks returned by the filter to free the
+// resources. unavailable is updated with the running tasks selected.
+func (r *costAwareRanker) selectTasks(
+	filter func([]*rm_task.RMTask) []*rm_task.RMTask,
+	stateTaskMap map[string][]*rm_task.RMTask,
+	unavailable map[string]uint32,
+	resourcesToFree *scalar.Resources) []*rm_task.RMTask {
+	var tasksToEvict []*rm_task.RMTask
+	resFilter := newResourceFilter(resourcesToFree)
+
+	for _, taskState := range nonRunningStatesPreemptionOrder {
+		tasksInState := filter(stateTaskMap[taskState.String()])
+		r.sorter.Sort(tasksInState)
+		for _, t := range tasksInState {
+			if resFilter.satisfied() {
+				return tasksToEvict
+			}
+			if resFilter.add(t) {
+				tasksToEvict = append(tasksToEvict, t)
+			}
+		}
+	}
+
+	picker := newRunningTaskPicker(
+		filter(stateTaskMap[task.TaskState_RUNNING.String()]),
+		unavailable,
+		r.now())
+	for !resFilter.satisfied() {
+		t := picker.next()
+		if t == nil {
+			break
+		}
+		if resFilter.add(t) {
+			tasksToEvict = append(tasksToEvict, t)
+			unavailable[t.Task().GetJobId().GetValue()]++
+		}
+	}
+	return tasksToEvict
+}
+
+// rankedTask is a running task with its progress
+type rankedTask struct {
+	task     *rm_task.RMTask
+	progress float64
+	runtime  time.Duration
+}
+
+// runningTaskPicker picks the running tasks of a resource pool in the
+// order in which they should be evicted. The tasks of each job are sorted
+// once, since the unavailable instances of a job order its tasks all the
+// same, and the jobs are kept in a heap ordered on their next task.
+type runningTaskPicker struct {
+	jobs *jobHeap
+	// job whose task was picked last, it is pushed back on the heap on
+	// the next pick since its unavailable instances may have changed
+	picked *jobTasks
+}
+
+// jobTasks are the tasks of a job which have not been picked yet,
+// in the order in which they should be evicted
+type jobTasks struct {
+	jobID string
+	tasks []*rankedTask
+}
+
+func newRunningTaskPicker(
+	tasks []*rm_task.RMTask,
+	unavailable map[string]uint32,
+	now time.Time) *runningTaskPicker {
+	byJob := make(map[string]*jobTasks)
+	h := &jobHeap{unavailable: unavailable}
+	for _, t := range tasks {
+		jobID := t.Task().GetJobId().GetValue()
+		j, ok := byJob[jobID]
+		if !ok {
+			j = &jobTasks{jobID: jobID}
+			byJob[jobID] = j
+			h.jobs = append(h.jobs, j)
+		}
+		j.tasks = append(j.tasks, newRankedTask(t, now))
+	}
+
+	for _, j := range h.jobs {
+		sort.Slice(j.tasks, func(a, b int) bool {
+			return h.less(j.tasks[a], j.tasks[b])
+		})
+	}
+	heap.Init(h)
+
+	return &runningTaskPicker{jobs: h}
+}
+
+func newRankedTask(t *rm_task.RMTask, now time.Time) *rankedTask {
+	var runtime time.Duration
+	if startTime := t.RunTimeStats().StartTime; !startTime.IsZero() {
+		runtime = now.Sub(startTime)
+	}
+
+	var progress float64
+	if maxRunningTime := t.Task().GetMaxRunningTime(); maxRunningTime > 0 {
+		progress = runtime.Seconds() / float64(maxRunningTime)
+	}
+	return &rankedTask{
+		task:     t,
+		progress: progress,
+		runtime:  runtime,
+	}
+}
+
+// next returns the next task to evict, or nil if all the tasks have
+// been picked. The tasks of the jobs which have reached their maximum
+// unavailable instances are only returned once no other task is left.
+func (p *runningTaskPicker) next() *rm_task.RMTask {
+	if p.picked != nil {
+		heap.Push(p.jobs, p.picked)
+		p.picked = nil
+	}
+	if p.jobs.Len() == 0 {
+		return nil
+	}
+
+	j := heap.Pop(p.jobs).(*jobTasks)
+	t := j.tasks[0]
+	j.tasks = j.tasks[1:]
+	if len(j.tasks) != 0 {
+		p.picked = j
+	}
+	return t.task
+}
+
+// jobHeap orders the jobs on the next task to evict of each job. It
+// implements container/heap.Interface and must only be used through
+// the container/heap functions.
+type jobHeap struct {
+	jobs []*jobTasks
+	// job ID -> number of unavailable instances
+	unavailable map[string]uint32
+}
+
+func (h *jobHeap) Len() int { return len(h.jobs) }
+
+func (h *jobHeap) Less(i, j int) bool {
+	t1, t2 := h.jobs[i].tasks[0], h.jobs[j].tasks[0]
+	w1 := h.withinLimit(h.jobs[i].jobID, t1)
+	w2 := h.withinLimit(h.jobs[j].jobID, t2)
+	if w1 != w2 {
+		return w1
+	}
+	return h.less(t1, t2)
+}
+
+func (h *jobHeap) Swap(i, j int) { h.jobs[i], h.jobs[j] = h.jobs[j], h.jobs[i] }
+
+func (h *jobHeap) Push(x interface{}) {
+	h.jobs = append(h.jobs, x.(*jobTasks))
+}
+
+func (h *jobHeap) Pop() interface{} {
+	n := len(h.jobs)
+	j := h.jobs[n-1]
+	h.jobs[n-1] = nil
+	h.jobs = h.jobs[:n-1]
+	return j
+}
+
+// withinLimit returns true if evicting the task does not exceed the
+// maximum unavailable instances of its job
+func (h *jobHeap) withinLimit(jobID string, t *rankedTask) bool {
+	limit := t.task.Task().GetMaximumUnavailableInstances()
+	return limit == 0 || h.unavailable[jobID] < limit
+}
+
+// less returns true if t1 should be evicted before t2
+func (h *jobHeap) less(t1, t2 *rankedTask) bool {
+	if r := priorityCmp(t1.task, t2.task); r != 0 {
+		return r < 0
+	}
+
+	// spread the evictions across the jobs
+	u1 := h.unavailable[t1.task.Task().GetJobId().GetValue()]
+	u2 := h.unavailable[t2.task.Task().GetJobId().GetValue()]
+	if u1 != u2 {
+		return u1 < u2
+	}
+
+	if t1.progress != t2.progress {
+		return t1.progress < t2.progress
+	}
+	if t1.runtime != t2.runtime {
+		return t1.runtime < t2.runtime
+	}
+	// keep the order deterministic
+	return t1.task.Task().GetId().GetValue() < t2.task.Task().GetId().GetValue()
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preemption
+
+import (
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+	rm_task "github.com/uber/peloton/pkg/resmgr/task"
+	"github.com/uber/peloton/pkg/resmgr/tasktestutil"
+)
+
+// addRunningTask adds a running task to the tracker which
+// started runtime ago
+func (suite *RankerTestSuite) addRunningTask(
+	tid string,
+	jid string,
+	priority uint32,
+	maxRunningTime uint32,
+	maxUnavailableInstances uint32,
+	runtime time.Duration) {
+	suite.addTaskToTracker(&resmgr.Task{
+		Name:     tid,
+		Priority: priority,
+		JobId:    &peloton.JobID{Value: jid},
+		Id:       &peloton.TaskID{Value: tid},
+		Hostname: "hostname",
+		Resource: &task.ResourceConfig{
+			CpuLimit:    1,
+			DiskLimitMb: 9,
+			GpuLimit:    0,
+			MemLimitMb:  100,
+		},
+		Preemptible:                 true,
+		MaxRunningTime:              maxRunningTime,
+		MaximumUnavailableInstances: maxUnavailableInstances,
+	})
+	taskID := &peloton.TaskID{Value: tid}
+	suite.transitToRunning(taskID)
+	suite.tracker.GetTask(taskID).RunTimeStats().StartTime =
+		time.Now().Add(-runtime)
+}
+
+// resourcesOfTasks returns the resources of count tasks added by
+// addRunningTask
+func resourcesOfTasks(count float64) *scalar.Resources {
+	return &scalar.Resources{
+		CPU:    count,
+		MEMORY: 100 * count,
+		GPU:    0,
+		DISK:   9 * count,
+	}
+}
+
+func (suite *RankerTestSuite) validateTasksToEvict(
+	tasksToEvict []*rm_task.RMTask,
+	expectedTasks []string) {
+	suite.Len(tasksToEvict, len(expectedTasks))
+	for i, taskToEvict := range tasksToEvict {
+		suite.Equal(expectedTasks[i], taskToEvict.Task().GetId().GetValue())
+	}
+}
+
+// TestCostAwareRanker_Progress tests that non running tasks are evicted
+// first, and then the running tasks which made the least progress
+func (suite *RankerTestSuite) TestCostAwareRanker_Progress() {
+	suite.addRunningTask("job1-0", "job1", 0, 1000, 0, 900*time.Second)
+	suite.addRunningTask("job1-1", "job1", 0, 1000, 0, 100*time.Second)
+	suite.addRunningTask("job1-2", "job1", 0, 1000, 0, 500*time.Second)
+	suite.addTaskWithID("job1-3", "job1", true)
+	suite.transitToReady(&peloton.TaskID{Value: "job1-3"})
+
+	ranker := newCostAwareRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		resourcesOfTasks(4))
+	suite.validateTasksToEvict(tasksToEvict, []string{
+		"job1-3",
+		"job1-1",
+		"job1-2",
+		"job1-0",
+	})
+}
+
+// TestCostAwareRanker_ProgressRelativeToMaxRunningTime tests that the
+// progress of the tasks takes the max running time of their job into
+// account
+func (suite *RankerTestSuite) TestCostAwareRanker_ProgressRelativeToMaxRunningTime() {
+	// 90% done
+	suite.addRunningTask("job1-0", "job1", 0, 100, 0, 90*time.Second)
+	// 50% done
+	suite.addRunningTask("job2-0", "job2", 0, 1000, 0, 500*time.Second)
+	// 10% done, but running for longer than the others
+	suite.addRunningTask("job3-0", "job3", 0, 10000, 0, 1000*time.Second)
+
+	ranker := newCostAwareRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		resourcesOfTasks(2))
+	suite.validateTasksToEvict(tasksToEvict, []string{
+		"job3-0",
+		"job2-0",
+	})
+}
+
+// TestCostAwareRanker_Priority tests that running tasks are sorted on
+// priority before progress
+func (suite *RankerTestSuite) TestCostAwareRanker_Priority() {
+	suite.addRunningTask("job1-0", "job1", 0, 1000, 0, 900*time.Second)
+	suite.addRunningTask("job2-0", "job2", 1, 1000, 0, 100*time.Second)
+
+	ranker := newCostAwareRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		resourcesOfTasks(2))
+	suite.validateTasksToEvict(tasksToEvict, []string{
+		"job1-0",
+		"job2-0",
+	})
+}
+
+// TestCostAwareRanker_SpreadAcrossJobs tests that the evictions are
+// spread across the jobs with the same priority
+func (suite *RankerTestSuite) TestCostAwareRanker_SpreadAcrossJobs() {
+	suite.addRunningTask("job1-0", "job1", 0, 1000, 0, 100*time.Second)
+	suite.addRunningTask("job1-1", "job1", 0, 1000, 0, 200*time.Second)
+	suite.addRunningTask("job1-2", "job1", 0, 1000, 0, 300*time.Second)
+	suite.addRunningTask("job2-0", "job2", 0, 1000, 0, 800*time.Second)
+	suite.addRunningTask("job2-1", "job2", 0, 1000, 0, 900*time.Second)
+
+	ranker := newCostAwareRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		resourcesOfTasks(4))
+	suite.validateTasksToEvict(tasksToEvict, []string{
+		"job1-0",
+		"job2-0",
+		"job1-1",
+		"job2-1",
+	})
+}
+
+// TestCostAwareRanker_MaximumUnavailableInstances tests that the tasks of
+// a job are evicted beyond its maximum unavailable instances only if the
+// resources cannot be freed otherwise
+func (suite *RankerTestSuite) TestCostAwareRanker_MaximumUnavailableInstances() {
+	suite.addRunningTask("job1-0", "job1", 0, 1000, 2, 100*time.Second)
+	suite.addRunningTask("job1-1", "job1", 0, 1000, 2, 200*time.Second)
+	suite.addRunningTask("job1-2", "job1", 0, 1000, 2, 300*time.Second)
+	suite.addRunningTask("job2-0", "job2", 1, 1000, 0, 900*time.Second)
+
+	// one instance of job1 is already being preempted
+	suite.addRunningTask("job1-3", "job1", 0, 1000, 2, 100*time.Second)
+	tasktestutil.ValidateStateTransitions(
+		suite.tracker.GetTask(&peloton.TaskID{Value: "job1-3"}),
+		[]task.TaskState{task.TaskState_PREEMPTING})
+
+	ranker := newCostAwareRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		resourcesOfTasks(2))
+	suite.validateTasksToEvict(tasksToEvict, []string{
+		"job1-0",
+		"job2-0",
+	})
+
+	tasksToEvict = ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		resourcesOfTasks(4))
+	suite.validateTasksToEvict(tasksToEvict, []string{
+		"job1-0",
+		"job2-0",
+		"job1-1",
+		"job1-2",
+	})
+}
+
+// TestCostAwareRanker_Revocable tests that revocable tasks are evicted
+// to free slack resources
+func (suite *RankerTestSuite) TestCostAwareRanker_Revocable() {
+	suite.addRevocableTaskWithID("job1-0", "job1")
+	suite.transitToRunning(&peloton.TaskID{Value: "job1-0"})
+	suite.addRunningTask("job2-0", "job2", 0, 1000, 0, 100*time.Second)
+
+	ranker := newCostAwareRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		resourcesOfTasks(1),
+		scalar.ZeroResource)
+	suite.validateTasksToEvict(tasksToEvict, []string{"job1-0"})
+}
+
+// TestCreateRanker tests creating the registered rankers
+func (suite *RankerTestSuite) TestCreateRanker() {
+	ranker, err := CreateRanker("", suite.tracker)
+	suite.NoError(err)
+	suite.IsType(&statePriorityRuntimeRanker{}, ranker)
+
+	ranker, err = CreateRanker(StatePriorityRuntime, suite.tracker)
+	suite.NoError(err)
+	suite.IsType(&statePriorityRuntimeRanker{}, ranker)
+
+	ranker, err = CreateRanker(CostAware, suite.tracker)
+	suite.NoError(err)
+	suite.IsType(&costAwareRanker{}, ranker)
+
+	_, err = CreateRanker("unknown", suite.tracker)
+	suite.Error(err)
+
+	// a ranker cannot be registered twice
+	RegisterRanker(CostAware, newStatePriorityRuntimeRanker)
+	ranker, err = CreateRanker(CostAware, suite.tracker)
+	suite.NoError(err)
+	suite.IsType(&costAwareRanker{}, ranker)
+}
@@ -81,7 +81,7 @@ type Preemptor struct {
 	preemptionQueue queue.Queue
 
 	// the ranker ranks the tasks in the resource pool to be preempted
-	ranker ranker
+	ranker Ranker
 	// The task tracker
 	tracker task.Tracker
 
@@ -91,13 +91,18 @@ type Preemptor struct {
 	m map[string]*Metrics
 }
 
-// NewPreemptor creates a new preemptor and returns it
+// NewPreemptor creates a new preemptor and returns it, or an error
+// if the ranker of the config is not registered
 func NewPreemptor(
 	parent tally.Scope,
 	cfg *common.PreemptionConfig,
 	tracker task.Tracker,
 	resTree respool.Tree,
-) *Preemptor {
+) (*Preemptor, error) {
+	ranker, err := CreateRanker(cfg.Ranker, tracker)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create preemption ranker")
+	}
 
 	return &Preemptor{
 		lifeCycle:                    lifecycle.NewLifeCycle(),
@@ -112,11 +117,11 @@ func NewPreemptor(
 			reflect.TypeOf(resmgr.PreemptionCandidate{}),
 			maxPreemptionQueueSize,
 		),
-		ranker:  newStatePriorityRuntimeRanker(tracker),
+		ranker:  ranker,
 		tracker: tracker,
 		scope:   parent.SubScope("preemption"),
 		m:       make(map[string]*Metrics),
-	}
+	}, nil
 }
 
 // returns per resource pool tagged metrics
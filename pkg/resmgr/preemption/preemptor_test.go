@@ -838,7 +838,7 @@ func (suite *PreemptorTestSuite) TestPreemptorEnqueue() {
 }
 
 func (suite *PreemptorTestSuite) TestNewPreemptor() {
-	p := NewPreemptor(tally.NoopScope, &res_common.PreemptionConfig{
+	p, err := NewPreemptor(tally.NoopScope, &res_common.PreemptionConfig{
 		Enabled:                      true,
 		TaskPreemptionPeriod:         100 * time.Hour,
 		SustainedOverAllocationCount: 100,
@@ -846,9 +846,25 @@ func (suite *PreemptorTestSuite) TestNewPreemptor() {
 		suite.tracker,
 		suite.getResourceTree(),
 	)
+	suite.NoError(err)
 	suite.NotNil(p)
 }
 
+// TestNewPreemptorUnknownRanker tests that the preemptor is not created
+// with a ranker which is not registered
+func (suite *PreemptorTestSuite) TestNewPreemptorUnknownRanker() {
+	p, err := NewPreemptor(tally.NoopScope, &res_common.PreemptionConfig{
+		Enabled:              true,
+		TaskPreemptionPeriod: 100 * time.Hour,
+		Ranker:               "UNKNOWN",
+	},
+		suite.tracker,
+		suite.getResourceTree(),
+	)
+	suite.Error(err)
+	suite.Nil(p)
+}
+
 func (suite *PreemptorTestSuite) TestPreemptionQueueDuplicateTasks() {
 	mockResTree := mocks.NewMockTree(suite.mockCtrl)
 	mockResPool := mocks.NewMockResPool(suite.mockCtrl)
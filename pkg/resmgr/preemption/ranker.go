@@ -22,6 +22,7 @@ import (
 	"github.com/uber/peloton/pkg/resmgr/scalar"
 	rm_task "github.com/uber/peloton/pkg/resmgr/task"
 
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -35,15 +36,64 @@ var taskStatesPreemptionOrder = []task.TaskState{
 	task.TaskState_RUNNING,
 }
 
-// ranker sorts revocable tasks first for eviction to satisfy slackResourcesToFree and
+const (
+	// StatePriorityRuntime is the name of the ranker which ranks the tasks
+	// by state, priority and runtime
+	StatePriorityRuntime = "STATE_PRIORITY_RUNTIME"
+
+	// CostAware is the name of the ranker which minimises the work lost
+	// by preempting running tasks
+	CostAware = "COST_AWARE"
+)
+
+// Ranker sorts revocable tasks first for eviction to satisfy slackResourcesToFree and
 // then sort non-revocable tasks to satisfy nonSlackResourcesToFree
-type ranker interface {
+type Ranker interface {
 	GetTasksToEvict(
 		respoolID string,
 		slackResourcesToFree,
 		nonSlackResourcesToFree *scalar.Resources) []*rm_task.RMTask
 }
 
+// RankerFunc is the type of func which creates a Ranker
+type RankerFunc func(tracker rm_task.Tracker) Ranker
+
+// map of ranker name to the func which creates the ranker
+var rankers = make(map[string]RankerFunc)
+
+func init() {
+	RegisterRanker(StatePriorityRuntime, newStatePriorityRuntimeRanker)
+	RegisterRanker(CostAware, newCostAwareRanker)
+}
+
+// RegisterRanker registers a ranker, which can then be selected by
+// its name in the preemption config.
+func RegisterRanker(name string, ranker RankerFunc) {
+	log.Infof("Registering %s preemption ranker", name)
+	if ranker == nil {
+		log.Errorf("preemption ranker does not exist")
+		return
+	}
+	if _, registered := rankers[name]; registered {
+		log.Errorf("preemption ranker already registered")
+		return
+	}
+	rankers[name] = ranker
+}
+
+// CreateRanker creates and returns the ranker registered with the name.
+// The StatePriorityRuntime ranker is returned if the name is empty.
+func CreateRanker(name string, tracker rm_task.Tracker) (Ranker, error) {
+	if name == "" {
+		name = StatePriorityRuntime
+	}
+	ranker, ok := rankers[name]
+	if !ok {
+		return nil, errors.Errorf("preemption ranker %s is not registered", name)
+	}
+	return ranker(tracker), nil
+}
+
 // statePriorityRuntimeRanker sorts the tasks in the following order
 // * Task State : READY > PLACING > RUNNING
 // * If task state is the same it sorts on the task Priority
@@ -54,7 +104,7 @@ type statePriorityRuntimeRanker struct {
 }
 
 // newStatePriorityRuntimeRanker returns a new instance of the statePriorityRuntimeRanker
-func newStatePriorityRuntimeRanker(tracker rm_task.Tracker) ranker {
+func newStatePriorityRuntimeRanker(tracker rm_task.Tracker) Ranker {
 	return &statePriorityRuntimeRanker{
 		tracker: tracker,
 		sorter: taskSorter{
@@ -153,31 +203,57 @@ func filterTasks(
 	resourcesLimit *scalar.Resources,
 	allTasks []*rm_task.RMTask) []*rm_task.RMTask {
 	var tasksToEvict []*rm_task.RMTask
-	resourceRunningCount := scalar.ZeroResource
+	filter := newResourceFilter(resourcesLimit)
 	for _, task := range allTasks {
-		// Check how many resource we need to free
-		resourceToFree := resourcesLimit.Subtract(resourceRunningCount)
-		if resourceToFree.Equal(scalar.ZeroResource) {
+		if filter.satisfied() {
 			// we have enough tasks
 			break
 		}
-		// get task resources
-		taskResources := scalar.ConvertToResmgrResource(task.Task().Resource)
-
-		// check if the task resource helps in satisfying resourceToFree
-		newResourceToFree := resourcesLimit.Subtract(taskResources)
-		if newResourceToFree.Equal(resourcesLimit) {
-			// this task doesn't help with meeting the resourcesLimit
-			continue
+		if filter.add(task) {
+			tasksToEvict = append(tasksToEvict, task)
 		}
-		// we can add more tasks
-		tasksToEvict = append(tasksToEvict, task)
-		// Add the task resource to the running count
-		resourceRunningCount = resourceRunningCount.Add(taskResources)
 	}
 	return tasksToEvict
 }
 
+// resourceFilter keeps track of the resources of the tasks selected for
+// eviction until they satisfy the resources limit
+type resourceFilter struct {
+	resourcesLimit       *scalar.Resources
+	resourceRunningCount *scalar.Resources
+}
+
+func newResourceFilter(resourcesLimit *scalar.Resources) *resourceFilter {
+	return &resourceFilter{
+		resourcesLimit:       resourcesLimit,
+		resourceRunningCount: scalar.ZeroResource,
+	}
+}
+
+// satisfied returns true if the selected tasks free enough resources
+func (f *resourceFilter) satisfied() bool {
+	// Check how many resource we need to free
+	resourceToFree := f.resourcesLimit.Subtract(f.resourceRunningCount)
+	return resourceToFree.Equal(scalar.ZeroResource)
+}
+
+// add selects the task if it helps in satisfying the resources limit,
+// and returns whether it was selected
+func (f *resourceFilter) add(task *rm_task.RMTask) bool {
+	// get task resources
+	taskResources := scalar.ConvertToResmgrResource(task.Task().Resource)
+
+	// check if the task resource helps in satisfying resourceToFree
+	newResourceToFree := f.resourcesLimit.Subtract(taskResources)
+	if newResourceToFree.Equal(f.resourcesLimit) {
+		// this task doesn't help with meeting the resourcesLimit
+		return false
+	}
+	// Add the task resource to the running count
+	f.resourceRunningCount = f.resourceRunningCount.Add(taskResources)
+	return true
+}
+
 // return 0  if  t1 == t2
 // return <0 if  t1 < t2
 // return >0 if  t1 > t2
@@ -19,6 +19,8 @@ import (
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
 )
 
 // Queue is the interface implemented by all the the queues
@@ -39,12 +41,25 @@ type Queue interface {
 	Size() int
 }
 
-// CreateQueue is factory method to create the specified queue
-func CreateQueue(policy respool.SchedulingPolicy, limit int64) (Queue, error) {
+// JobAllocator returns the resources currently allocated to a job in the
+// resource pool which owns the queue
+type JobAllocator func(jobID string) *scalar.Resources
+
+// CreateQueue is factory method to create the specified queue.
+// allocator is used by the policies which order the gangs by the
+// allocation of their job.
+func CreateQueue(
+	policy respool.SchedulingPolicy,
+	limit int64,
+	allocator JobAllocator) (Queue, error) {
 	// Factory method to create specific queue object based on policy
 	switch policy {
 	case respool.SchedulingPolicy_PriorityFIFO:
 		return NewPriorityQueue(limit), nil
+	case respool.SchedulingPolicy_WeightedFairShare:
+		return NewWeightedFairShareQueue(limit, allocator), nil
+	case respool.SchedulingPolicy_ShortestJobFirst:
+		return NewShortestJobFirstQueue(limit), nil
 	default:
 		//if type is invalid, return an error
 		return nil, errors.New("invalid queue type")
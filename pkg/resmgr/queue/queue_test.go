@@ -33,14 +33,22 @@ func TestQueue(t *testing.T) {
 
 // TestCreateQueue tests the Create Queue
 func (suite *QueueTestSuite) TestCreateQueueSuccess() {
-	q, err := CreateQueue(respool.SchedulingPolicy_PriorityFIFO, 100)
+	q, err := CreateQueue(respool.SchedulingPolicy_PriorityFIFO, 100, nil)
 	suite.NoError(err)
-	suite.NotNil(q)
+	suite.IsType(&PriorityQueue{}, q)
+
+	q, err = CreateQueue(respool.SchedulingPolicy_WeightedFairShare, 100, nil)
+	suite.NoError(err)
+	suite.IsType(&WeightedFairShareQueue{}, q)
+
+	q, err = CreateQueue(respool.SchedulingPolicy_ShortestJobFirst, 100, nil)
+	suite.NoError(err)
+	suite.IsType(&ShortestJobFirstQueue{}, q)
 }
 
 // TestCreateQueue tests the Create Queue
 func (suite *QueueTestSuite) TestCreateQueueError() {
-	q, err := CreateQueue(2, 100)
+	q, err := CreateQueue(respool.SchedulingPolicy_UNKNOWN, 100, nil)
 	suite.Nil(q)
 	suite.Error(err)
 	suite.EqualError(err, "invalid queue type")
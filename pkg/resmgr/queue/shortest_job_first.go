@@ -0,0 +1,167 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+)
+
+// ShortestJobFirstQueue is the queue which removes the gang of the job with
+// the shortest max running time first. Gangs of jobs with the same max
+// running time are removed by priority and then in the order they entered
+// the queue.
+type ShortestJobFirstQueue struct {
+	sync.RWMutex
+	limit int64
+	// seq is incremented on every enqueue to keep FIFO order between gangs
+	// of the same size and priority
+	seq uint64
+	// items sorted in the dequeue order
+	items []*sjfItem
+}
+
+// sjfItem is a gang in the shortest job first queue
+type sjfItem struct {
+	gang     *resmgrsvc.Gang
+	size     uint32
+	priority uint32
+	seq      uint64
+}
+
+// less returns true if the item needs to be dequeued before the other one
+func (i *sjfItem) less(other *sjfItem) bool {
+	if i.size != other.size {
+		return i.size < other.size
+	}
+	if i.priority != other.priority {
+		return i.priority > other.priority
+	}
+	return i.seq < other.seq
+}
+
+// NewShortestJobFirstQueue initializes the shortest job first queue and
+// returns the pointer
+func NewShortestJobFirstQueue(limit int64) *ShortestJobFirstQueue {
+	return &ShortestJobFirstQueue{
+		limit: limit,
+	}
+}
+
+// Enqueue queues a gang (task list gang) based on its max running time
+func (q *ShortestJobFirstQueue) Enqueue(gang *resmgrsvc.Gang) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if (gang == nil) || (len(gang.Tasks) == 0) {
+		return errors.New("enqueue of empty list")
+	}
+
+	if q.limit >= 0 && q.limit <= int64(len(q.items)) {
+		return fmt.Errorf("list size limit reached")
+	}
+
+	q.seq++
+	item := &sjfItem{
+		gang:     gang,
+		size:     getGangSize(gang),
+		priority: gang.Tasks[0].GetPriority(),
+		seq:      q.seq,
+	}
+
+	// keep the items sorted on insertion
+	index := sort.Search(len(q.items), func(i int) bool {
+		return item.less(q.items[i])
+	})
+	q.items = append(q.items, nil)
+	copy(q.items[index+1:], q.items[index:])
+	q.items[index] = item
+	return nil
+}
+
+// Dequeue dequeues the gang (task list gang) of the shortest job
+func (q *ShortestJobFirstQueue) Dequeue() (*resmgrsvc.Gang, error) {
+	q.Lock()
+	defer q.Unlock()
+
+	if len(q.items) == 0 {
+		return nil, ErrorQueueEmpty("dequeue failed, queue is empty")
+	}
+
+	item := q.items[0]
+	q.items = q.items[1:]
+	return item.gang, nil
+}
+
+// Peek peeks the limit number of gangs of the shortest jobs.
+// It will return an `ErrorQueueEmpty` if there is no gangs in the queue
+func (q *ShortestJobFirstQueue) Peek(limit uint32) ([]*resmgrsvc.Gang, error) {
+	q.RLock()
+	defer q.RUnlock()
+
+	var items []*resmgrsvc.Gang
+	for _, item := range q.items {
+		if len(items) == int(limit) {
+			break
+		}
+		items = append(items, item.gang)
+	}
+
+	if len(items) == 0 {
+		return items, ErrorQueueEmpty("peek failed, queue is empty")
+	}
+	return items, nil
+}
+
+// Remove removes the item from the queue
+func (q *ShortestJobFirstQueue) Remove(gang *resmgrsvc.Gang) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if gang == nil || len(gang.Tasks) <= 0 {
+		return errors.New("removal of empty list")
+	}
+
+	for i, item := range q.items {
+		if item.gang == gang {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return nil
+		}
+	}
+	return ErrorQueueEmpty(fmt.Sprintf("No items found in queue %s", gang))
+}
+
+// Size returns the number of elements in the ShortestJobFirstQueue
+func (q *ShortestJobFirstQueue) Size() int {
+	q.RLock()
+	defer q.RUnlock()
+
+	return len(q.items)
+}
+
+// getGangSize returns the size hint of the gang which is the max running
+// time of its job. Gangs without a max running time are the largest.
+func getGangSize(gang *resmgrsvc.Gang) uint32 {
+	size := gang.Tasks[0].GetMaxRunningTime()
+	if size == 0 {
+		return math.MaxUint32
+	}
+	return size
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"math"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ShortestJobFirstQueueTestSuite struct {
+	suite.Suite
+	queue *ShortestJobFirstQueue
+}
+
+func TestShortestJobFirstQueue(t *testing.T) {
+	suite.Run(t, new(ShortestJobFirstQueueTestSuite))
+}
+
+func (suite *ShortestJobFirstQueueTestSuite) SetupTest() {
+	suite.queue = NewShortestJobFirstQueue(math.MaxInt64)
+}
+
+func (suite *ShortestJobFirstQueueTestSuite) createGang(
+	jobID string,
+	priority uint32,
+	maxRunningTime uint32) *resmgrsvc.Gang {
+	return &resmgrsvc.Gang{
+		Tasks: []*resmgr.Task{
+			{
+				Name:           jobID,
+				JobId:          &peloton.JobID{Value: jobID},
+				Priority:       priority,
+				MaxRunningTime: maxRunningTime,
+			},
+		},
+	}
+}
+
+// TestDequeueOrder tests that the gangs are dequeued by max running time,
+// then by priority and then in FIFO order
+func (suite *ShortestJobFirstQueueTestSuite) TestDequeueOrder() {
+	unbounded := suite.createGang("unbounded", 2, 0)
+	long := suite.createGang("long", 2, 3600)
+	short1 := suite.createGang("short1", 0, 60)
+	short2 := suite.createGang("short2", 0, 60)
+	shortHighPriority := suite.createGang("short-high-priority", 1, 60)
+
+	for _, gang := range []*resmgrsvc.Gang{
+		unbounded, long, short1, short2, shortHighPriority} {
+		suite.NoError(suite.queue.Enqueue(gang))
+	}
+	suite.Equal(5, suite.queue.Size())
+
+	gangs, err := suite.queue.Peek(2)
+	suite.NoError(err)
+	suite.Equal([]*resmgrsvc.Gang{shortHighPriority, short1}, gangs)
+
+	for _, expected := range []*resmgrsvc.Gang{
+		shortHighPriority, short1, short2, long, unbounded} {
+		gang, err := suite.queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(expected, gang)
+	}
+
+	_, err = suite.queue.Dequeue()
+	suite.Error(err)
+	_, err = suite.queue.Peek(1)
+	suite.IsType(ErrorQueueEmpty(""), err)
+}
+
+// TestRemove tests removing gangs from the queue
+func (suite *ShortestJobFirstQueueTestSuite) TestRemove() {
+	gang1 := suite.createGang("job1", 0, 60)
+	gang2 := suite.createGang("job2", 0, 120)
+	suite.NoError(suite.queue.Enqueue(gang1))
+	suite.NoError(suite.queue.Enqueue(gang2))
+
+	suite.NoError(suite.queue.Remove(gang1))
+	suite.Equal(1, suite.queue.Size())
+	suite.Error(suite.queue.Remove(gang1))
+	suite.Error(suite.queue.Remove(nil))
+
+	gangs, err := suite.queue.Peek(10)
+	suite.NoError(err)
+	suite.Equal([]*resmgrsvc.Gang{gang2}, gangs)
+}
+
+// TestEnqueueErrors tests the errors on enqueue
+func (suite *ShortestJobFirstQueueTestSuite) TestEnqueueErrors() {
+	q := NewShortestJobFirstQueue(1)
+	suite.EqualError(q.Enqueue(nil), "enqueue of empty list")
+	suite.EqualError(q.Enqueue(&resmgrsvc.Gang{}), "enqueue of empty list")
+
+	suite.NoError(q.Enqueue(suite.createGang("job1", 0, 60)))
+	suite.EqualError(
+		q.Enqueue(suite.createGang("job2", 0, 60)),
+		"list size limit reached")
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+)
+
+// WeightedFairShareQueue is the queue which shares the resource pool across
+// jobs. It removes the gang of the job with the lowest allocation relative
+// to its share first, where the share of a job is weighted by its priority.
+// The gangs of the same job are removed in the order they entered the queue.
+type WeightedFairShareQueue struct {
+	sync.RWMutex
+	limit int64
+	size  int
+	// seq is incremented on every enqueue to keep FIFO order between jobs
+	// with the same share
+	seq uint64
+	// the gangs of every job in the order they entered the queue, keyed by
+	// job ID
+	jobs map[string]*list.List
+	// returns the current allocation of a job
+	allocator JobAllocator
+}
+
+// fairShareItem is a gang in the weighted fair share queue
+type fairShareItem struct {
+	gang *resmgrsvc.Gang
+	seq  uint64
+}
+
+// fairShareJob holds the state of a job while choosing the order of the
+// gangs in the queue
+type fairShareJob struct {
+	next       *list.Element
+	weight     float64
+	allocation *scalar.Resources
+}
+
+// NewWeightedFairShareQueue initializes the weighted fair share queue and
+// returns the pointer
+func NewWeightedFairShareQueue(
+	limit int64,
+	allocator JobAllocator) *WeightedFairShareQueue {
+	return &WeightedFairShareQueue{
+		limit:     limit,
+		jobs:      make(map[string]*list.List),
+		allocator: allocator,
+	}
+}
+
+// Enqueue queues a gang (task list gang) at the end of the gangs of its job
+func (q *WeightedFairShareQueue) Enqueue(gang *resmgrsvc.Gang) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if (gang == nil) || (len(gang.Tasks) == 0) {
+		return errors.New("enqueue of empty list")
+	}
+
+	if q.limit >= 0 && q.limit <= int64(q.size) {
+		return fmt.Errorf("list size limit reached")
+	}
+
+	jobID := getGangJobID(gang)
+	l, ok := q.jobs[jobID]
+	if !ok {
+		l = list.New()
+		q.jobs[jobID] = l
+	}
+
+	q.seq++
+	l.PushBack(&fairShareItem{
+		gang: gang,
+		seq:  q.seq,
+	})
+	q.size++
+	return nil
+}
+
+// Dequeue dequeues the gang (task list gang) of the job with the lowest
+// allocation relative to its share
+func (q *WeightedFairShareQueue) Dequeue() (*resmgrsvc.Gang, error) {
+	gangs, err := q.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.Remove(gangs[0]); err != nil {
+		return nil, err
+	}
+	return gangs[0], nil
+}
+
+// Peek peeks the limit number of gangs in the order of the allocation of
+// their job relative to its share. Every peeked gang is accounted to the
+// allocation of its job, so that the gangs of different jobs interleave.
+// It will return an `ErrorQueueEmpty` if there is no gangs in the queue
+func (q *WeightedFairShareQueue) Peek(limit uint32) ([]*resmgrsvc.Gang, error) {
+	// the allocations are looked up without holding the queue lock, as
+	// the resource pool holds its own lock while updating the queue
+	allocations := q.getJobAllocations()
+
+	q.RLock()
+	defer q.RUnlock()
+
+	jobs := make(map[string]*fairShareJob)
+	total := &scalar.Resources{}
+	for jobID, l := range q.jobs {
+		allocation, ok := allocations[jobID]
+		if !ok {
+			allocation = scalar.ZeroResource
+		}
+		first := l.Front().Value.(*fairShareItem)
+		jobs[jobID] = &fairShareJob{
+			next:       l.Front(),
+			weight:     float64(first.gang.Tasks[0].GetPriority() + 1),
+			allocation: allocation,
+		}
+		total = total.Add(allocation)
+	}
+
+	var items []*resmgrsvc.Gang
+	for len(items) < int(limit) && len(jobs) > 0 {
+		var nextJobID string
+		var nextJob *fairShareJob
+		var nextShare float64
+		for jobID, job := range jobs {
+			share := getDominantShare(job.allocation, total) / job.weight
+			if nextJob == nil ||
+				share < nextShare ||
+				(share == nextShare && job.seq() < nextJob.seq()) {
+				nextJobID, nextJob, nextShare = jobID, job, share
+			}
+		}
+
+		gang := nextJob.next.Value.(*fairShareItem).gang
+		items = append(items, gang)
+
+		// account the gang as allocated to pick the following gangs
+		resources := scalar.GetGangResources(gang)
+		nextJob.allocation = nextJob.allocation.Add(resources)
+		total = total.Add(resources)
+
+		nextJob.next = nextJob.next.Next()
+		if nextJob.next == nil {
+			delete(jobs, nextJobID)
+		}
+	}
+
+	if len(items) == 0 {
+		return items, ErrorQueueEmpty("peek failed, queue is empty")
+	}
+	return items, nil
+}
+
+// Remove removes the item from the queue
+func (q *WeightedFairShareQueue) Remove(gang *resmgrsvc.Gang) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if gang == nil || len(gang.Tasks) <= 0 {
+		return errors.New("removal of empty list")
+	}
+
+	jobID := getGangJobID(gang)
+	if l, ok := q.jobs[jobID]; ok {
+		for e := l.Front(); e != nil; e = e.Next() {
+			if e.Value.(*fairShareItem).gang != gang {
+				continue
+			}
+			l.Remove(e)
+			if l.Len() == 0 {
+				delete(q.jobs, jobID)
+			}
+			q.size--
+			return nil
+		}
+	}
+	return ErrorQueueEmpty(fmt.Sprintf("No items found in queue %s", gang))
+}
+
+// Size returns the number of elements in the WeightedFairShareQueue
+func (q *WeightedFairShareQueue) Size() int {
+	q.RLock()
+	defer q.RUnlock()
+
+	return q.size
+}
+
+// getJobAllocations returns the current allocation of the jobs in the queue
+func (q *WeightedFairShareQueue) getJobAllocations() map[string]*scalar.Resources {
+	q.RLock()
+	jobIDs := make([]string, 0, len(q.jobs))
+	for jobID := range q.jobs {
+		jobIDs = append(jobIDs, jobID)
+	}
+	q.RUnlock()
+
+	allocations := make(map[string]*scalar.Resources)
+	if q.allocator == nil {
+		return allocations
+	}
+	for _, jobID := range jobIDs {
+		allocations[jobID] = q.allocator(jobID)
+	}
+	return allocations
+}
+
+// seq returns the sequence of the next gang of the job
+func (j *fairShareJob) seq() uint64 {
+	return j.next.Value.(*fairShareItem).seq
+}
+
+// getDominantShare returns the largest share of the allocation across all
+// the resource kinds
+func getDominantShare(allocation, total *scalar.Resources) float64 {
+	var share float64
+	for _, s := range []struct{ allocated, total float64 }{
+		{allocation.GetCPU(), total.GetCPU()},
+		{allocation.GetMem(), total.GetMem()},
+		{allocation.GetDisk(), total.GetDisk()},
+		{allocation.GetGPU(), total.GetGPU()},
+	} {
+		if s.total > 0 && s.allocated/s.total > share {
+			share = s.allocated / s.total
+		}
+	}
+	return share
+}
+
+// getGangJobID returns the ID of the job of the gang
+func getGangJobID(gang *resmgrsvc.Gang) string {
+	return gang.Tasks[0].GetJobId().GetValue()
+}
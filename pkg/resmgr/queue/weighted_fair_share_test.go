@@ -0,0 +1,156 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"math"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WeightedFairShareQueueTestSuite struct {
+	suite.Suite
+	queue      *WeightedFairShareQueue
+	allocation map[string]*scalar.Resources
+}
+
+func TestWeightedFairShareQueue(t *testing.T) {
+	suite.Run(t, new(WeightedFairShareQueueTestSuite))
+}
+
+func (suite *WeightedFairShareQueueTestSuite) SetupTest() {
+	suite.allocation = make(map[string]*scalar.Resources)
+	suite.queue = NewWeightedFairShareQueue(
+		math.MaxInt64,
+		func(jobID string) *scalar.Resources {
+			if res, ok := suite.allocation[jobID]; ok {
+				return res
+			}
+			return scalar.ZeroResource
+		})
+}
+
+func (suite *WeightedFairShareQueueTestSuite) createGang(
+	jobID string,
+	priority uint32) *resmgrsvc.Gang {
+	return &resmgrsvc.Gang{
+		Tasks: []*resmgr.Task{
+			{
+				Name:     jobID,
+				JobId:    &peloton.JobID{Value: jobID},
+				Priority: priority,
+				Resource: &task.ResourceConfig{
+					CpuLimit:   1,
+					MemLimitMb: 100,
+				},
+			},
+		},
+	}
+}
+
+// TestDequeueInterleavesJobs tests that the gangs of a large job do not
+// starve the gangs of a job which entered the queue later
+func (suite *WeightedFairShareQueueTestSuite) TestDequeueInterleavesJobs() {
+	var large, small []*resmgrsvc.Gang
+	for i := 0; i < 3; i++ {
+		gang := suite.createGang("large", 0)
+		large = append(large, gang)
+		suite.NoError(suite.queue.Enqueue(gang))
+	}
+	for i := 0; i < 2; i++ {
+		gang := suite.createGang("small", 0)
+		small = append(small, gang)
+		suite.NoError(suite.queue.Enqueue(gang))
+	}
+	suite.Equal(5, suite.queue.Size())
+
+	gangs, err := suite.queue.Peek(5)
+	suite.NoError(err)
+	suite.Equal([]*resmgrsvc.Gang{
+		large[0], small[0], large[1], small[1], large[2]}, gangs)
+}
+
+// TestDequeueByAllocation tests that the gangs of the job with the lowest
+// allocation are dequeued first
+func (suite *WeightedFairShareQueueTestSuite) TestDequeueByAllocation() {
+	suite.allocation["large"] = &scalar.Resources{CPU: 10, MEMORY: 1000}
+	suite.allocation["small"] = &scalar.Resources{CPU: 1, MEMORY: 100}
+
+	large := suite.createGang("large", 0)
+	small := suite.createGang("small", 0)
+	suite.NoError(suite.queue.Enqueue(large))
+	suite.NoError(suite.queue.Enqueue(small))
+
+	gang, err := suite.queue.Dequeue()
+	suite.NoError(err)
+	suite.Equal(small, gang)
+	suite.Equal(1, suite.queue.Size())
+}
+
+// TestDequeueByWeight tests that the share of a job is weighted by
+// its priority
+func (suite *WeightedFairShareQueueTestSuite) TestDequeueByWeight() {
+	suite.allocation["high"] = &scalar.Resources{CPU: 2, MEMORY: 200}
+	suite.allocation["low"] = &scalar.Resources{CPU: 1, MEMORY: 100}
+
+	high := suite.createGang("high", 3)
+	low := suite.createGang("low", 0)
+	suite.NoError(suite.queue.Enqueue(low))
+	suite.NoError(suite.queue.Enqueue(high))
+
+	gangs, err := suite.queue.Peek(1)
+	suite.NoError(err)
+	suite.Equal([]*resmgrsvc.Gang{high}, gangs)
+}
+
+// TestRemove tests removing gangs from the queue
+func (suite *WeightedFairShareQueueTestSuite) TestRemove() {
+	gang1 := suite.createGang("job1", 0)
+	gang2 := suite.createGang("job1", 0)
+	suite.NoError(suite.queue.Enqueue(gang1))
+	suite.NoError(suite.queue.Enqueue(gang2))
+
+	suite.NoError(suite.queue.Remove(gang2))
+	suite.Error(suite.queue.Remove(gang2))
+	suite.Error(suite.queue.Remove(nil))
+	suite.NoError(suite.queue.Remove(gang1))
+	suite.Equal(0, suite.queue.Size())
+	suite.Empty(suite.queue.jobs)
+
+	_, err := suite.queue.Peek(1)
+	suite.IsType(ErrorQueueEmpty(""), err)
+	_, err = suite.queue.Dequeue()
+	suite.Error(err)
+}
+
+// TestEnqueueErrors tests the errors on enqueue
+func (suite *WeightedFairShareQueueTestSuite) TestEnqueueErrors() {
+	q := NewWeightedFairShareQueue(1, nil)
+	suite.EqualError(q.Enqueue(nil), "enqueue of empty list")
+	suite.EqualError(q.Enqueue(&resmgrsvc.Gang{}), "enqueue of empty list")
+
+	suite.NoError(q.Enqueue(suite.createGang("job1", 0)))
+	suite.EqualError(
+		q.Enqueue(suite.createGang("job2", 0)),
+		"list size limit reached")
+}
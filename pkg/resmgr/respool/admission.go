@@ -15,6 +15,8 @@
 package respool
 
 import (
+	"fmt"
+
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
@@ -73,6 +75,13 @@ func (qt QueueType) String() string {
 // returns true if the gang can be admitted to the pool
 type admitter func(gang *resmgrsvc.Gang, pool *resPool) bool
 
+// namedAdmitter is an admitter with the reason reported for the gangs which
+// it does not admit
+type namedAdmitter struct {
+	admit  admitter
+	reason string
+}
+
 // returns true iff there's enough resources in the pool to admit the gang
 func entitlementAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
 	var currentAllocation, currentEntitlement *scalar.Resources
@@ -163,15 +172,24 @@ func reservationAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
 }
 
 type admissionController struct {
-	admitters []admitter
+	admitters []namedAdmitter
 }
 
 // the global admission controller for all resource pool
 var admission = admissionController{
-	admitters: []admitter{
-		entitlementAdmitter,
-		controllerAdmitter,
-		reservationAdmitter,
+	admitters: []namedAdmitter{
+		{
+			admit:  entitlementAdmitter,
+			reason: "entitlement exhausted",
+		},
+		{
+			admit:  controllerAdmitter,
+			reason: "controller limit reached",
+		},
+		{
+			admit:  reservationAdmitter,
+			reason: "reservation for non-preemptible tasks exhausted",
+		},
 	},
 }
 
@@ -194,7 +212,13 @@ func (ac admissionController) TryAdmit(
 		return errGangInvalid
 	}
 
-	if admitted := ac.canAdmit(gang, pool); !admitted {
+	if reason, admitted := ac.canAdmit(gang, pool); !admitted {
+		// record the reason to explain why the tasks are still pending
+		for _, task := range gang.GetTasks() {
+			pool.admissionFailures[task.GetId().GetValue()] = fmt.Sprintf(
+				"%s in resource pool %s", reason, pool.path)
+		}
+
 		if qt == PendingQueue {
 			// If a gang can't be admitted from the pending queue to the resource
 			// pool, then if:
@@ -234,7 +258,8 @@ func (ac admissionController) TryAdmit(
 	// gang is admittable,
 	// 1. remove the gang from queue
 	// 2. remove the demand for resource pool
-	// 3. add gang resources to allocation
+	// 3. add gang resources to allocation, which also accounts them to the
+	//    allocation of the job used to order the weighted fair share queues
 	if err := removeGangFromQueue(
 		pool,
 		qt,
@@ -243,6 +268,9 @@ func (ac admissionController) TryAdmit(
 		return err
 	}
 
+	for _, task := range gang.GetTasks() {
+		delete(pool.admissionFailures, task.GetId().GetValue())
+	}
 	pool.allocation = pool.allocation.Add(scalar.GetGangAllocation(gang))
 	return nil
 }
@@ -303,6 +331,7 @@ func (ac admissionController) validateGang(
 		} else {
 			// the task is invalid so we mark the gang as invalid
 			delete(pool.invalidTasks, task.Id.Value)
+			delete(pool.admissionFailures, task.Id.Value)
 			isGangValid = false
 		}
 	}
@@ -361,20 +390,21 @@ func (ac admissionController) validateGang(
 	return false, nil
 }
 
-// returns true if gang can be admitted to the pool
+// returns true if gang can be admitted to the pool, otherwise the reason
+// of the first admitter which does not admit the gang
 func (ac admissionController) canAdmit(
 	gang *resmgrsvc.Gang,
-	pool *resPool) bool {
+	pool *resPool) (string, bool) {
 
 	// loop through the admitters
 	for _, admitter := range ac.admitters {
-		if !admitter(gang, pool) {
+		if !admitter.admit(gang, pool) {
 			// bail out fast
-			return false
+			return admitter.reason, false
 		}
 	}
 	// all admitters can admit
-	return true
+	return "", true
 }
 
 // removeGangFromQueue removes a gang from a queue (pending/np/controller/revocable)
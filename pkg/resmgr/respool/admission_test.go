@@ -173,6 +173,13 @@ func (s *ResPoolSuite) TestBatchAdmissionController_TryAdmitFailure() {
 	s.Equal(float64(0), resPool.GetTotalAllocatedResources().MEMORY)
 	s.Equal(float64(0), resPool.GetTotalAllocatedResources().DISK)
 	s.Equal(float64(0), resPool.GetTotalAllocatedResources().GPU)
+
+	// the reason is recorded until the task is invalidated
+	s.Equal(
+		"entitlement exhausted in resource pool "+resPool.GetPath(),
+		resPool.GetAdmissionFailure(task.GetId()))
+	resPool.AddInvalidTask(task.GetId())
+	s.Empty(resPool.GetAdmissionFailure(task.GetId()))
 }
 
 // Test adds 9 revocable tasks and 2 non-revocable tasks.
@@ -159,6 +159,11 @@ type ResPool interface {
 	// discarded asynchronously which scheduling.
 	AddInvalidTask(task *peloton.TaskID)
 
+	// GetAdmissionFailure returns the reason why the task was last not
+	// admitted into the resource pool, or an empty string if the task was
+	// admitted since.
+	GetAdmissionFailure(task *peloton.TaskID) string
+
 	// UpdateResourceMetrics updates metrics for this resource pool
 	// on each entitlement cycle calculation (15s)
 	UpdateResourceMetrics()
@@ -240,6 +245,9 @@ type resPool struct {
 	// set of invalid tasks which will be discarded during admission control.
 	invalidTasks map[string]bool
 
+	// task ID -> reason why the gang of the task was last not admitted.
+	admissionFailures map[string]string
+
 	metrics *Metrics
 }
 
@@ -256,36 +264,12 @@ func NewRespool(
 			"ResourcePoolConfig is nil", id)
 	}
 
-	pq, err := queue.CreateQueue(config.Policy, math.MaxInt64)
-	if err != nil {
-		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
-	}
-
-	cq, err := queue.CreateQueue(config.Policy, math.MaxInt64)
-	if err != nil {
-		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
-	}
-
-	nq, err := queue.CreateQueue(config.Policy, math.MaxInt64)
-	if err != nil {
-		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
-	}
-
-	rq, err := queue.CreateQueue(config.Policy, math.MaxInt64)
-	if err != nil {
-		return nil, errors.Wrapf(err, "error creating revocable queue %s", id)
-	}
-
 	pool := &resPool{
 		id:                  id,
 		children:            list.New(),
 		parent:              parent,
 		resourceConfigs:     make(map[string]*respool.ResourceConfig),
 		poolConfig:          config,
-		pendingQueue:        pq,
-		controllerQueue:     cq,
-		npQueue:             nq,
-		revocableQueue:      rq,
 		allocation:          scalar.NewAllocation(),
 		entitlement:         &scalar.Resources{},
 		nonSlackEntitlement: &scalar.Resources{},
@@ -295,10 +279,44 @@ func NewRespool(
 		slackLimit:          &scalar.Resources{},
 		reservation:         &scalar.Resources{},
 		invalidTasks:        make(map[string]bool),
+		admissionFailures:   make(map[string]string),
 		preemptionCfg:       preemptionConfig,
 	}
 	pool.path = pool.calculatePath()
 
+	var err error
+	pool.pendingQueue, err = queue.CreateQueue(
+		config.Policy,
+		math.MaxInt64,
+		pool.getJobAllocation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
+	}
+
+	pool.controllerQueue, err = queue.CreateQueue(
+		config.Policy,
+		math.MaxInt64,
+		pool.getJobAllocation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
+	}
+
+	pool.npQueue, err = queue.CreateQueue(
+		config.Policy,
+		math.MaxInt64,
+		pool.getJobAllocation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
+	}
+
+	pool.revocableQueue, err = queue.CreateQueue(
+		config.Policy,
+		math.MaxInt64,
+		pool.getJobAllocation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating revocable queue %s", id)
+	}
+
 	// Initialize metrics
 	pool.metrics = NewMetrics(scope.Tagged(map[string]string{
 		"path": pool.GetPath(),
@@ -560,7 +578,9 @@ func (n *resPool) ToResourcePoolInfo() *respool.ResourcePoolInfo {
 		Path:     &respool.ResourcePoolPath{Value: n.path},
 		Usage: n.createRespoolUsage(
 			n.allocation.GetByType(scalar.TotalAllocation),
-			n.allocation.GetByType(scalar.SlackAllocation)),
+			n.allocation.GetByType(scalar.SlackAllocation),
+			n.demand,
+			n.slackDemand),
 	}
 }
 
@@ -639,6 +659,14 @@ func (n *resPool) calculateSlackDemand() *scalar.Resources {
 	return slackDemand
 }
 
+// getJobAllocation returns the resources allocated to a job in the
+// resource pool
+func (n *resPool) getJobAllocation(jobID string) *scalar.Resources {
+	n.RLock()
+	defer n.RUnlock()
+	return n.allocation.GetByJob(jobID)
+}
+
 // getQueue returns the queue depending on the queue type
 func (n *resPool) queue(qt QueueType) queue.Queue {
 	switch qt {
@@ -660,30 +688,40 @@ func (n *resPool) queue(qt QueueType) queue.Queue {
 // [cpus] is the only slack resource supported.
 func (n *resPool) createRespoolUsage(
 	allocation *scalar.Resources,
-	slackAllocation *scalar.Resources) []*respool.ResourceUsage {
+	slackAllocation *scalar.Resources,
+	demand *scalar.Resources,
+	slackDemand *scalar.Resources) []*respool.ResourceUsage {
 	resUsage := make([]*respool.ResourceUsage, 0, 4)
 	ru := &respool.ResourceUsage{
-		Kind:       common.CPU,
-		Allocation: allocation.CPU - slackAllocation.CPU,
-		Slack:      slackAllocation.CPU,
+		Kind:        common.CPU,
+		Allocation:  allocation.CPU - slackAllocation.CPU,
+		Slack:       slackAllocation.CPU,
+		Demand:      demand.CPU,
+		SlackDemand: slackDemand.CPU,
 	}
 	resUsage = append(resUsage, ru)
 	ru = &respool.ResourceUsage{
-		Kind:       common.GPU,
-		Allocation: allocation.GPU - slackAllocation.GPU,
-		Slack:      slackAllocation.GPU,
+		Kind:        common.GPU,
+		Allocation:  allocation.GPU - slackAllocation.GPU,
+		Slack:       slackAllocation.GPU,
+		Demand:      demand.GPU,
+		SlackDemand: slackDemand.GPU,
 	}
 	resUsage = append(resUsage, ru)
 	ru = &respool.ResourceUsage{
-		Kind:       common.MEMORY,
-		Allocation: allocation.MEMORY - slackAllocation.MEMORY,
-		Slack:      slackAllocation.MEMORY,
+		Kind:        common.MEMORY,
+		Allocation:  allocation.MEMORY - slackAllocation.MEMORY,
+		Slack:       slackAllocation.MEMORY,
+		Demand:      demand.MEMORY,
+		SlackDemand: slackDemand.MEMORY,
 	}
 	resUsage = append(resUsage, ru)
 	ru = &respool.ResourceUsage{
-		Kind:       common.DISK,
-		Allocation: allocation.DISK - slackAllocation.DISK,
-		Slack:      slackAllocation.DISK,
+		Kind:        common.DISK,
+		Allocation:  allocation.DISK - slackAllocation.DISK,
+		Slack:       slackAllocation.DISK,
+		Demand:      demand.DISK,
+		SlackDemand: slackDemand.DISK,
 	}
 	resUsage = append(resUsage, ru)
 	return resUsage
@@ -1126,14 +1164,23 @@ func (n *resPool) AddInvalidTask(task *peloton.TaskID) {
 	n.Lock()
 	defer n.Unlock()
 	n.invalidTasks[task.Value] = true
+	delete(n.admissionFailures, task.Value)
 }
 
-// PeekGangs returns a list of gangs from the queue based on the queue type.
-func (n *resPool) PeekGangs(qt QueueType, limit uint32) ([]*resmgrsvc.Gang,
-	error) {
+// GetAdmissionFailure returns the reason why the task was last not admitted
+// into the resource pool.
+func (n *resPool) GetAdmissionFailure(task *peloton.TaskID) string {
 	n.RLock()
 	defer n.RUnlock()
+	return n.admissionFailures[task.GetValue()]
+}
 
+// PeekGangs returns a list of gangs from the queue based on the queue type.
+// The queues are never replaced and are thread safe, so the resource pool
+// lock is not held, as peeking the queue can look up the allocation of the
+// resource pool.
+func (n *resPool) PeekGangs(qt QueueType, limit uint32) ([]*resmgrsvc.Gang,
+	error) {
 	switch qt {
 	case PendingQueue:
 		return n.pendingQueue.Peek(limit)
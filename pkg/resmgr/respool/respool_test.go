@@ -251,6 +251,22 @@ func (s *ResPoolSuite) TestNewResPool() {
 				Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
 			},
 		},
+		{
+			poolConfig: &pb_respool.ResourcePoolConfig{
+				Name:      _testResPoolName,
+				Parent:    &_rootResPoolID,
+				Resources: s.getResources(),
+				Policy:    pb_respool.SchedulingPolicy_WeightedFairShare,
+			},
+		},
+		{
+			poolConfig: &pb_respool.ResourcePoolConfig{
+				Name:      _testResPoolName,
+				Parent:    &_rootResPoolID,
+				Resources: s.getResources(),
+				Policy:    pb_respool.SchedulingPolicy_ShortestJobFirst,
+			},
+		},
 		{
 			poolConfig: &pb_respool.ResourcePoolConfig{
 				Name:      _testResPoolName,
@@ -508,6 +524,47 @@ func (s *ResPoolSuite) TestSlackEntitlement() {
 	s.Equal(expectedEntitlement, resPoolNode.GetSlackEntitlement())
 }
 
+// TestDequeueWeightedFairShare tests that the gangs of a job which entered
+// the queue later are admitted before the rest of the gangs of a job which
+// is already allocated
+func (s *ResPoolSuite) TestDequeueWeightedFairShare() {
+	poolConfig := &pb_respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    pb_respool.SchedulingPolicy_WeightedFairShare,
+	}
+	resPoolNode, err := NewRespool(tally.NoopScope, uuid.New(), s.root,
+		poolConfig, s.cfg)
+	s.NoError(err)
+	resPoolNode.SetNonSlackEntitlement(s.getNonSlackEntitlement())
+
+	newTask := func(jobID string, instance int) *resmgr.Task {
+		taskID := fmt.Sprintf("%s-%d", jobID, instance)
+		return &resmgr.Task{
+			Name:  taskID,
+			JobId: &peloton.JobID{Value: jobID},
+			Id:    &peloton.TaskID{Value: taskID},
+			Resource: &task.ResourceConfig{
+				CpuLimit:    1,
+				DiskLimitMb: 10,
+				MemLimitMb:  100,
+			},
+			Preemptible: true,
+		}
+	}
+	for i := 0; i < 3; i++ {
+		s.NoError(resPoolNode.EnqueueGang(makeTaskGang(newTask("large", i))))
+	}
+	s.NoError(resPoolNode.EnqueueGang(makeTaskGang(newTask("small", 0))))
+
+	dequeuedGangs, err := resPoolNode.DequeueGangs(2)
+	s.NoError(err)
+	s.Len(dequeuedGangs, 2)
+	s.Equal("large-0", dequeuedGangs[0].GetTasks()[0].GetId().GetValue())
+	s.Equal("small-0", dequeuedGangs[1].GetTasks()[0].GetId().GetValue())
+}
+
 func (s *ResPoolSuite) TestAllocation() {
 	resPoolNode := s.createTestResourcePool()
 	resPoolNode.SetNonSlackEntitlement(s.getNonSlackEntitlement())
@@ -1074,6 +1131,39 @@ func (s *ResPoolSuite) TestToResourcePoolInfo() {
 	s.Equal("/"+_testResPoolName, info.GetPath().GetValue())
 }
 
+func (s *ResPoolSuite) TestToResourcePoolInfoDemand() {
+	respoolNode := s.createTestResourcePool()
+	s.NoError(respoolNode.AddToDemand(&scalar.Resources{
+		CPU:    2,
+		MEMORY: 200,
+		DISK:   20,
+		GPU:    1,
+	}))
+	s.NoError(respoolNode.AddToSlackDemand(&scalar.Resources{
+		CPU:    1,
+		MEMORY: 100,
+	}))
+
+	info := respoolNode.ToResourcePoolInfo()
+	expectedDemand := map[string]float64{
+		common.CPU:    2,
+		common.MEMORY: 200,
+		common.DISK:   20,
+		common.GPU:    1,
+	}
+	expectedSlackDemand := map[string]float64{
+		common.CPU:    1,
+		common.MEMORY: 100,
+		common.DISK:   0,
+		common.GPU:    0,
+	}
+	s.Len(info.GetUsage(), 4)
+	for _, resUsage := range info.GetUsage() {
+		s.Equal(expectedDemand[resUsage.GetKind()], resUsage.GetDemand())
+		s.Equal(expectedSlackDemand[resUsage.GetKind()], resUsage.GetSlackDemand())
+	}
+}
+
 func (s *ResPoolSuite) TestAggregatedChildrenReservations() {
 	respool1ID := peloton.ResourcePoolID{Value: "respool1"}
 	respool2ID := peloton.ResourcePoolID{Value: "respool2"}
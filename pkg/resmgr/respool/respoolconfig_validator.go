@@ -58,6 +58,7 @@ func NewResourcePoolConfigValidator(rTree Tree) (Validator, error) {
 	return resourcePoolConfigValidator.Register(
 		[]ResourcePoolConfigValidatorFunc{
 			ValidateResourcePool,
+			ValidateSchedulingPolicy,
 			ValidateCycle,
 			ValidateParent,
 			ValidateSiblings,
@@ -291,6 +292,39 @@ func ValidateResourcePool(_ Tree,
 	return nil
 }
 
+// ValidateSchedulingPolicy if the scheduling policy is supported and is not
+// changed for an existing resource pool, as the queues of a resource pool
+// are created with its policy.
+func ValidateSchedulingPolicy(resTree Tree,
+	resourcePoolConfigData ResourcePoolConfigData) error {
+	resPoolConfig := resourcePoolConfigData.ResourcePoolConfig
+	ID := resourcePoolConfigData.ID
+
+	switch resPoolConfig.Policy {
+	case respool.SchedulingPolicy_PriorityFIFO,
+		respool.SchedulingPolicy_WeightedFairShare,
+		respool.SchedulingPolicy_ShortestJobFirst:
+	default:
+		return errors.Errorf("invalid scheduling policy %v", resPoolConfig.Policy)
+	}
+
+	existingResourcePool, _ := resTree.Get(ID)
+	if existingResourcePool == nil {
+		return nil
+	}
+
+	existingPolicy := existingResourcePool.ResourcePoolConfig().GetPolicy()
+	if existingPolicy != resPoolConfig.Policy {
+		return errors.Errorf(
+			"scheduling policy of resource pool %s cannot be changed from %v to %v",
+			ID.Value,
+			existingPolicy,
+			resPoolConfig.Policy,
+		)
+	}
+	return nil
+}
+
 // ValidateCycle if adding/updating current pool would result in a cycle
 func ValidateCycle(_ Tree,
 	resourcePoolConfigData ResourcePoolConfigData) error {
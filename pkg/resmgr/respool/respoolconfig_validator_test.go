@@ -629,6 +629,68 @@ func (s *resPoolConfigValidatorSuite) TestNoPolicy() {
 		pb_respool.SchedulingPolicy_PriorityFIFO)
 }
 
+func (s *resPoolConfigValidatorSuite) TestValidateSchedulingPolicy() {
+	rv := &resourcePoolConfigValidator{resTree: s.resourceTree}
+	_, err := rv.Register(
+		[]ResourcePoolConfigValidatorFunc{
+			ValidateSchedulingPolicy,
+		},
+	)
+	s.NoError(err)
+
+	tt := []struct {
+		msg    string
+		id     string
+		policy pb_respool.SchedulingPolicy
+		err    string
+	}{
+		{
+			msg:    "new resource pool with weighted fair share policy",
+			id:     "respool99",
+			policy: pb_respool.SchedulingPolicy_WeightedFairShare,
+		},
+		{
+			msg:    "new resource pool with shortest job first policy",
+			id:     "respool99",
+			policy: pb_respool.SchedulingPolicy_ShortestJobFirst,
+		},
+		{
+			msg:    "existing resource pool with same policy",
+			id:     "respool1",
+			policy: pb_respool.SchedulingPolicy_PriorityFIFO,
+		},
+		{
+			msg:    "new resource pool with invalid policy",
+			id:     "respool99",
+			policy: pb_respool.SchedulingPolicy(100),
+			err:    "invalid scheduling policy 100",
+		},
+		{
+			msg:    "existing resource pool with changed policy",
+			id:     "respool1",
+			policy: pb_respool.SchedulingPolicy_ShortestJobFirst,
+			err: "scheduling policy of resource pool respool1 cannot be " +
+				"changed from PriorityFIFO to ShortestJobFirst",
+		},
+	}
+
+	for _, t := range tt {
+		err := rv.Validate(ResourcePoolConfigData{
+			ID: &peloton.ResourcePoolID{Value: t.id},
+			ResourcePoolConfig: &pb_respool.ResourcePoolConfig{
+				Parent: &peloton.ResourcePoolID{Value: common.RootResPoolID},
+				Name:   t.id,
+				Policy: t.policy,
+			},
+		})
+		if t.err == "" {
+			s.NoError(err, t.msg)
+		} else {
+			s.EqualError(err, t.err, t.msg)
+		}
+	}
+}
+
 func (s *resPoolConfigValidatorSuite) TestValidatePathError() {
 	rv := &resourcePoolConfigValidator{resTree: s.resourceTree}
 	_, err := rv.Register(
@@ -21,6 +21,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 
+	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/lifecycle"
 	rc "github.com/uber/peloton/pkg/resmgr/common"
@@ -138,6 +139,17 @@ func (h *ServiceHandler) CreateResourcePool(
 		}, nil
 	}
 
+	// only the owners of the parent can add children to it
+	if auth.HasUser(ctx) {
+		if parent, err := h.resPoolTree.Get(
+			resPoolConfig.GetParent()); err == nil {
+			if err := checkResPoolPermission(ctx, parent); err != nil {
+				h.metrics.CreateResourcePoolFail.Inc(1)
+				return nil, err
+			}
+		}
+	}
+
 	// TODO Handle parent of the new_resource_pool_config
 	// already has tasks added running, drain, distinguish?
 
@@ -296,6 +308,11 @@ func (h *ServiceHandler) DeleteResourcePool(
 		return resp, nil
 	}
 
+	if err := checkResPoolPermission(ctx, resPool); err != nil {
+		h.metrics.DeleteResourcePoolFail.Inc(1)
+		return nil, err
+	}
+
 	// As if the resource pool is not leaf, Delete method should
 	// not let this operation occur. As delete is only supported for
 	// leaf resource pools
@@ -459,6 +476,11 @@ func (h *ServiceHandler) UpdateResourcePool(
 		}, nil
 	}
 
+	if err := checkResPoolPermission(ctx, existingResPool); err != nil {
+		h.metrics.UpdateResourcePoolFail.Inc(1)
+		return nil, err
+	}
+
 	// update persistent store.
 	if err := h.store.UpdateResourcePool(ctx, resPoolID, resPoolConfig); err != nil {
 		h.metrics.UpdateResourcePoolFail.Inc(1)
@@ -506,6 +528,19 @@ func (h *ServiceHandler) UpdateResourcePool(
 	return &respool.UpdateResponse{}, nil
 }
 
+// checkResPoolPermission checks that the user of the request is permitted
+// on the resource pool, which is owned by its owning team and LDAP groups.
+func checkResPoolPermission(ctx context.Context, resPool res.ResPool) error {
+	if !auth.HasUser(ctx) {
+		return nil
+	}
+	cfg := resPool.ResourcePoolConfig()
+	return auth.CheckEntityPermission(ctx, &auth.Entity{
+		OwningTeam: cfg.GetOwningTeam(),
+		LdapGroups: cfg.GetLdapGroups(),
+	})
+}
+
 // LookupResourcePoolID returns the resource pool ID for a given resource pool
 // path.
 func (h *ServiceHandler) LookupResourcePoolID(ctx context.Context,
@@ -21,6 +21,8 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pb_respool "github.com/uber/peloton/.gen/peloton/api/v0/respool"
 
+	"github.com/uber/peloton/pkg/auth"
+	authmocks "github.com/uber/peloton/pkg/auth/mocks"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/lifecycle"
 	rc "github.com/uber/peloton/pkg/resmgr/common"
@@ -35,6 +37,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
 	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 type resPoolHandlerTestSuite struct {
@@ -570,6 +573,33 @@ func (s *resPoolHandlerTestSuite) TestUpsertError() {
 	s.Equal(err.Error(), "Error")
 }
 
+// TestUpdateResourcePoolNotPermitted tests that a resource pool cannot be
+// updated by a user which does not own it
+func (s *resPoolHandlerTestSuite) TestUpdateResourcePoolNotPermitted() {
+	handler, resTree, respool := s.getMockHandlerWithResTreeAndRespool()
+	updateReq := s.getUpdateRequest()
+	procedure := "peloton.api.v0.respool.ResourceManager::UpdateResourcePool"
+	user := authmocks.NewMockUser(s.mockCtrl)
+	ctx := auth.WithUser(s.context, user, procedure)
+
+	resTree.EXPECT().Get(gomock.Any()).Return(respool, nil)
+	respool.EXPECT().ResourcePoolConfig().Return(
+		&pb_respool.ResourcePoolConfig{
+			OwningTeam: "team1",
+			LdapGroups: []string{"group1"},
+		})
+	user.EXPECT().
+		IsPermittedOnEntity(procedure, &auth.Entity{
+			OwningTeam: "team1",
+			LdapGroups: []string{"group1"},
+		}).
+		Return(false)
+
+	updateResp, err := handler.UpdateResourcePool(ctx, updateReq)
+	s.Nil(updateResp)
+	s.True(yarpcerrors.IsPermissionDenied(err))
+}
+
 func (s *resPoolHandlerTestSuite) getMockHandlerWithResTreeAndRespool() (*ServiceHandler, *mocks.MockTree, *mocks.MockResPool) {
 	resTree := mocks.NewMockTree(s.mockCtrl)
 	return &ServiceHandler{
@@ -51,6 +51,8 @@ const (
 // Allocation is the container to track allocation across different dimensions
 type Allocation struct {
 	Value map[AllocationType]*Resources
+	// JobValue tracks the total allocation of every job, keyed by job ID
+	JobValue map[string]*Resources
 }
 
 // NewAllocation returns a new Allocation
@@ -63,12 +65,26 @@ func (a *Allocation) GetByType(allocationType AllocationType) *Resources {
 	return a.Value[allocationType]
 }
 
+// GetByJob returns the total allocation of a job
+func (a *Allocation) GetByJob(jobID string) *Resources {
+	if res, ok := a.JobValue[jobID]; ok {
+		return res
+	}
+	return ZeroResource
+}
+
 // Add adds one allocation to another
 func (a *Allocation) Add(other *Allocation) *Allocation {
 	result := initializeZeroAlloc()
 	for t, v := range a.Value {
 		result.Value[t] = v.Add(other.Value[t])
 	}
+	for jobID, v := range a.JobValue {
+		result.JobValue[jobID] = v
+	}
+	for jobID, v := range other.JobValue {
+		result.JobValue[jobID] = result.GetByJob(jobID).Add(v)
+	}
 	return result
 }
 
@@ -78,13 +94,23 @@ func (a *Allocation) Subtract(other *Allocation) *Allocation {
 	for t, v := range a.Value {
 		result.Value[t] = v.Subtract(other.Value[t])
 	}
+	for jobID, v := range a.JobValue {
+		if o, ok := other.JobValue[jobID]; ok {
+			v = v.Subtract(o)
+		}
+		// jobs with nothing allocated are not tracked anymore
+		if !v.Equal(ZeroResource) {
+			result.JobValue[jobID] = v
+		}
+	}
 	return result
 }
 
 // initializeZeroAlloc initializes a zero alloc
 func initializeZeroAlloc() *Allocation {
 	alloc := &Allocation{
-		Value: make(map[AllocationType]*Resources),
+		Value:    make(map[AllocationType]*Resources),
+		JobValue: make(map[string]*Resources),
 	}
 
 	alloc.Value[TotalAllocation] = ZeroResource
@@ -136,6 +162,11 @@ func GetTaskAllocation(rmTask *resmgr.Task) *Allocation {
 	// every task account for total allocation
 	alloc.Value[TotalAllocation] = taskResource
 
+	// and for the allocation of its job
+	if jobID := rmTask.GetJobId().GetValue(); jobID != "" {
+		alloc.JobValue[jobID] = taskResource
+	}
+
 	return alloc
 }
 
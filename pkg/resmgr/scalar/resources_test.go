@@ -17,6 +17,7 @@ package scalar
 import (
 	"testing"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
@@ -409,3 +410,31 @@ func TestGetGangAllocation(t *testing.T) {
 	})
 	assertEqual(t, &Resources{1.0, 1.0, 1.0, 1.0}, res.GetByType(TotalAllocation))
 }
+
+func TestJobAllocation(t *testing.T) {
+	newTask := func(jobID string) *resmgr.Task {
+		return &resmgr.Task{
+			JobId: &peloton.JobID{Value: jobID},
+			Resource: &task.ResourceConfig{
+				CpuLimit:    1,
+				DiskLimitMb: 1,
+				GpuLimit:    1,
+				MemLimitMb:  1,
+			},
+		}
+	}
+
+	alloc := GetGangAllocation(&resmgrsvc.Gang{
+		Tasks: []*resmgr.Task{newTask("job1"), newTask("job1")},
+	})
+	alloc = alloc.Add(GetTaskAllocation(newTask("job2")))
+	assertEqual(t, &Resources{2.0, 2.0, 2.0, 2.0}, alloc.GetByJob("job1"))
+	assertEqual(t, &Resources{1.0, 1.0, 1.0, 1.0}, alloc.GetByJob("job2"))
+	assert.Equal(t, ZeroResource, alloc.GetByJob("job3"))
+
+	// jobs with nothing left allocated are dropped
+	alloc = alloc.Subtract(GetTaskAllocation(newTask("job2")))
+	assert.Len(t, alloc.JobValue, 1)
+	assertEqual(t, &Resources{2.0, 2.0, 2.0, 2.0}, alloc.GetByJob("job1"))
+	assertEqual(t, &Resources{2.0, 2.0, 2.0, 2.0}, alloc.GetByType(TotalAllocation))
+}
@@ -77,6 +77,14 @@ type RMTask struct {
 
 	// observes the state transitions of the rm task
 	transitionObserver TransitionObserver
+
+	// the reason why the task was last not placed, reset once it is placed
+	placementFailure string
+
+	// whether the last eviction of the running task was held by the job
+	// manager because of the SLA of its job, reset once the task is
+	// running again without being evicted
+	evictionHeld bool
 }
 
 // CreateRMTask creates the RM task from resmgr.task
@@ -241,6 +249,18 @@ func (rmTask *RMTask) initStateMachine() error {
 					},
 					Callback: nil,
 				}).
+			AddRule(
+				&state.Rule{
+					From: state.State(task.TaskState_PREEMPTING.String()),
+					To: []state.State{
+						// The job manager holds the eviction of a task
+						// for host maintenance if it would exceed the
+						// SLA of its job, in which case the task is moved
+						// back to RUNNING to be preempted again later.
+						state.State(task.TaskState_RUNNING.String()),
+					},
+					Callback: nil,
+				}).
 			AddRule(
 				&state.Rule{
 					From: state.State(task.TaskState_FAILED.String()),
@@ -356,6 +376,42 @@ func (rmTask *RMTask) getCurrentState() RMTaskState {
 	}
 }
 
+// PendingReason returns the last reason why the task could not be admitted
+// into its resource pool or placed on a host. It is empty if the task is
+// not waiting to be admitted or placed.
+func (rmTask *RMTask) PendingReason() string {
+	rmTask.mu.Lock()
+	defer rmTask.mu.Unlock()
+
+	switch rmTask.getCurrentState().State {
+	case task.TaskState_PENDING:
+		// the admission failure is reset once the task is admitted, so it
+		// is more recent than any placement failure
+		if reason := rmTask.respool.GetAdmissionFailure(
+			rmTask.task.GetId()); reason != "" {
+			return reason
+		}
+		return rmTask.placementFailure
+	case task.TaskState_READY, task.TaskState_PLACING:
+		return rmTask.placementFailure
+	}
+	return ""
+}
+
+// EvictionHeld returns true if the task is running or being preempted,
+// and its last eviction was held by the job manager because of the SLA
+// of its job.
+func (rmTask *RMTask) EvictionHeld() bool {
+	rmTask.mu.Lock()
+	defer rmTask.mu.Unlock()
+
+	switch rmTask.getCurrentState().State {
+	case task.TaskState_RUNNING, task.TaskState_PREEMPTING:
+		return rmTask.evictionHeld
+	}
+	return false
+}
+
 // Respool returns the respool of the RMTask.
 func (rmTask *RMTask) Respool() respool.ResPool {
 	return rmTask.respool
@@ -421,6 +477,11 @@ func (rmTask *RMTask) RequeueUnPlaced(reason string) error {
 		return errUnplacedTaskInWrongState
 	}
 
+	rmTask.placementFailure = reason
+	if rmTask.placementFailure == "" {
+		rmTask.placementFailure = reasonPlacementRetry
+	}
+
 	// If task is in PLACING state we need to determine which STATE it will
 	// transition to based on retry attempts
 
@@ -546,8 +607,17 @@ func (rmTask *RMTask) transitionCallBack(t *state.Transition) error {
 		rmTask.Task().GetTaskId().GetValue(),
 		tState)
 
-	// we only care about running state here
-	if tState == task.TaskState_RUNNING {
+	switch tState {
+	case task.TaskState_PLACED:
+		// the task is not waiting to be placed anymore
+		rmTask.placementFailure = ""
+	case task.TaskState_RUNNING:
+		if t.From == state.State(task.TaskState_PREEMPTING.String()) {
+			// the task kept running as its eviction was held
+			rmTask.evictionHeld = true
+			break
+		}
+		rmTask.evictionHeld = false
 		// update the start time
 		rmTask.UpdateStartTime(time.Now().UTC())
 	}
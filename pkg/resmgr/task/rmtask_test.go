@@ -328,6 +328,50 @@ func (s *RMTaskTestSuite) TestStateChanges() {
 	s.NoError(err)
 }
 
+// TestPreemptingToRunning tests that a task picked for preemption can be
+// moved back to RUNNING if its eviction is held, which is then recorded
+// until the task is evicted
+func (s *RMTaskTestSuite) TestPreemptingToRunning() {
+	node, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool3"})
+	s.NoError(err)
+
+	s.tracker.AddTask(
+		s.pendingGang0().Tasks[0],
+		nil,
+		node,
+		&Config{
+			LaunchingTimeout: 1 * time.Minute,
+			PlacingTimeout:   1 * time.Minute,
+			PolicyName:       ExponentialBackOffPolicy,
+		})
+
+	rmtask := s.tracker.GetTask(s.pendingGang0().Tasks[0].Id)
+	for _, state := range []task.TaskState{
+		task.TaskState_PENDING,
+		task.TaskState_READY,
+		task.TaskState_PLACING,
+		task.TaskState_PLACED,
+		task.TaskState_LAUNCHING,
+		task.TaskState_RUNNING,
+	} {
+		s.NoError(rmtask.TransitTo(state.String()))
+	}
+	s.False(rmtask.EvictionHeld())
+	startTime := rmtask.RunTimeStats().StartTime
+
+	s.NoError(rmtask.TransitTo(task.TaskState_PREEMPTING.String()))
+	s.False(rmtask.EvictionHeld())
+	s.NoError(rmtask.TransitTo(task.TaskState_RUNNING.String()))
+	s.Equal(task.TaskState_RUNNING, rmtask.GetCurrentState().State)
+	s.True(rmtask.EvictionHeld())
+	// the task kept running
+	s.Equal(startTime, rmtask.RunTimeStats().StartTime)
+
+	s.NoError(rmtask.TransitTo(task.TaskState_PREEMPTING.String()))
+	s.True(rmtask.EvictionHeld())
+	s.Error(rmtask.TransitTo(task.TaskState_READY.String()))
+}
+
 func (s *RMTaskTestSuite) TestReadyBackoff() {
 
 	node, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool3"})
@@ -766,6 +810,63 @@ func (s *RMTaskTestSuite) TestRMTaskRequeueUnPlacedTaskInPlacingToReady() {
 	s.Nil(err, "placing to ready requeue should not fail")
 }
 
+func (s *RMTaskTestSuite) TestRMTaskPendingReason() {
+	// Tests the pending reason of a task is the admission failure if the
+	// task is pending admission, and the placement failure otherwise.
+	mockNode := mocks.NewMockResPool(s.ctrl)
+	mockNode.EXPECT().GetPath().Return("/mocknode").Times(1)
+
+	rmTask, err := CreateRMTask(
+		tally.NoopScope,
+		s.createTask(1),
+		nil,
+		mockNode,
+		&Config{
+			PolicyName: ExponentialBackOffPolicy,
+		},
+	)
+	s.NoError(err)
+
+	mockStateMachine := sm_mock.NewMockStateMachine(s.ctrl)
+	mockStateMachine.
+		EXPECT().GetReason().
+		Return("testing").AnyTimes()
+	mockStateMachine.
+		EXPECT().GetLastUpdateTime().
+		Return(time.Now()).AnyTimes()
+	rmTask.stateMachine = mockStateMachine
+	rmTask.placementFailure = "no offers from the cluster"
+
+	// task is in PENDING state
+	mockStateMachine.
+		EXPECT().GetCurrentState().
+		Return(statemachine.State(task.TaskState_PENDING.String())).
+		Times(2)
+	mockNode.EXPECT().
+		GetAdmissionFailure(rmTask.Task().GetId()).
+		Return("entitlement exhausted in resource pool /mocknode")
+	s.Equal(
+		"entitlement exhausted in resource pool /mocknode",
+		rmTask.PendingReason())
+
+	mockNode.EXPECT().
+		GetAdmissionFailure(rmTask.Task().GetId()).
+		Return("")
+	s.Equal("no offers from the cluster", rmTask.PendingReason())
+
+	// task is in READY state
+	mockStateMachine.
+		EXPECT().GetCurrentState().
+		Return(statemachine.State(task.TaskState_READY.String()))
+	s.Equal("no offers from the cluster", rmTask.PendingReason())
+
+	// task is in RUNNING state
+	mockStateMachine.
+		EXPECT().GetCurrentState().
+		Return(statemachine.State(task.TaskState_RUNNING.String()))
+	s.Empty(rmTask.PendingReason())
+}
+
 func (s *RMTaskTestSuite) TestRMTaskRequeueUnPlacedTaskInPlacingToReadyErr() {
 	// Tests a task is PLACING state can't be requeued because of error in
 	// state machine transition.
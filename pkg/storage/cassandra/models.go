@@ -163,6 +163,7 @@ type UpdateRecord struct {
 	CreationTime         time.Time         `cql:"creation_time"`
 	UpdateTime           time.Time         `cql:"update_time"`
 	OpaqueData           string            `cql:"opaque_data"`
+	CanaryStatus         []byte            `cql:"canary_status"`
 }
 
 // GetUpdateConfig unmarshals and returns the configuration of the job update.
@@ -171,6 +172,16 @@ func (u *UpdateRecord) GetUpdateConfig() (*update.UpdateConfig, error) {
 	return config, proto.Unmarshal(u.UpdateOptions, config)
 }
 
+// GetCanaryStatus unmarshals and returns the progress of the canary phase
+// of the job update. Returns nil if the update does not have a canary phase.
+func (u *UpdateRecord) GetCanaryStatus() (*update.CanaryStatus, error) {
+	if len(u.CanaryStatus) == 0 {
+		return nil, nil
+	}
+	status := &update.CanaryStatus{}
+	return status, proto.Unmarshal(u.CanaryStatus, status)
+}
+
 // GetProcessingInstances returns a list of tasks currently being updated.
 func (u *UpdateRecord) GetProcessingInstances() []uint32 {
 	p := make([]uint32, len(u.InstancesCurrent))
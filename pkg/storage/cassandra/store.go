@@ -2791,8 +2791,21 @@ func (s *Store) CreateUpdate(
 			updateInfo.GetJobConfigVersion(),
 			updateInfo.GetPrevJobConfigVersion(),
 			updateInfo.GetOpaqueData().GetData(),
-			time.Now()).
-		IfNotExist()
+			time.Now())
+
+	if updateInfo.GetCanaryStatus() != nil {
+		canaryStatusBuffer, err := proto.Marshal(updateInfo.GetCanaryStatus())
+		if err != nil {
+			log.WithError(err).
+				WithField("update_id", updateInfo.GetUpdateID().GetValue()).
+				WithField("job_id", updateInfo.GetJobID().GetValue()).
+				Error("failed to marshal canary status")
+			s.metrics.UpdateMetrics.UpdateCreateFail.Inc(1)
+			return err
+		}
+		stmt = stmt.Columns("canary_status").Values(canaryStatusBuffer)
+	}
+	stmt = stmt.IfNotExist()
 
 	if err := s.applyStatement(
 		ctx,
@@ -2850,6 +2863,39 @@ func (s *Store) AddJobUpdateEvent(
 	return nil
 }
 
+// AddJobCanaryEvent adds a state change event of the canary phase
+// of an update for a job
+func (s *Store) AddJobCanaryEvent(
+	ctx context.Context,
+	updateID *peloton.UpdateID,
+	updateType models.WorkflowType,
+	updateState update.State,
+	canaryState update.CanaryState,
+) error {
+	queryBuilder := s.DataStore.NewQuery()
+	stmt := queryBuilder.Insert(jobUpdateEvents).
+		Columns(
+			"update_id",
+			"type",
+			"state",
+			"canary_state",
+			"create_time").
+		Values(
+			updateID.GetValue(),
+			updateType.String(),
+			updateState.String(),
+			canaryState.String(),
+			qb.UUID{UUID: gocql.UUIDFromTime(time.Now())})
+	err := s.applyStatement(ctx, stmt, updateID.GetValue())
+	if err != nil {
+		s.metrics.UpdateMetrics.JobUpdateEventAddFail.Inc(1)
+		return err
+	}
+
+	s.metrics.UpdateMetrics.JobUpdateEventAdd.Inc(1)
+	return nil
+}
+
 // GetJobUpdateEvents gets update state change events for a job
 // in descending create timestamp order
 func (s *Store) GetJobUpdateEvents(
@@ -2874,6 +2920,10 @@ func (s *Store) GetJobUpdateEvents(
 				update.State_value[value["state"].(string)]),
 			Timestamp: value["create_time"].(qb.UUID).Time().Format(time.RFC3339),
 		}
+		if canaryState, ok := value["canary_state"].(string); ok {
+			workflowEvent.CanaryState = stateless.CanaryState(
+				update.CanaryState_value[canaryState])
+		}
 
 		workflowEvents = append(workflowEvents, workflowEvent)
 	}
@@ -3112,6 +3162,12 @@ func (s *Store) GetUpdate(ctx context.Context, id *peloton.UpdateID) (
 			return nil, err
 		}
 
+		canaryStatus, err := record.GetCanaryStatus()
+		if err != nil {
+			s.metrics.UpdateMetrics.UpdateGetFail.Inc(1)
+			return nil, err
+		}
+
 		updateInfo := &models.UpdateModel{
 			UpdateID:             id,
 			UpdateConfig:         updateConfig,
@@ -3131,6 +3187,7 @@ func (s *Store) GetUpdate(ctx context.Context, id *peloton.UpdateID) (
 			CreationTime:         record.CreationTime.Format(time.RFC3339Nano),
 			UpdateTime:           record.UpdateTime.Format(time.RFC3339Nano),
 			OpaqueData:           &peloton.OpaqueData{Data: record.OpaqueData},
+			CanaryStatus:         canaryStatus,
 		}
 		s.metrics.UpdateMetrics.UpdateGet.Inc(1)
 		return updateInfo, nil
@@ -3243,6 +3300,15 @@ func (s *Store) WriteUpdateProgress(
 		stmt = stmt.Set("opaque_data", updateInfo.GetOpaqueData().GetData())
 	}
 
+	if updateInfo.GetCanaryStatus() != nil {
+		canaryStatusBuffer, err := proto.Marshal(updateInfo.GetCanaryStatus())
+		if err != nil {
+			s.metrics.UpdateMetrics.UpdateWriteProgressFail.Inc(1)
+			return err
+		}
+		stmt = stmt.Set("canary_status", canaryStatusBuffer)
+	}
+
 	stmt = stmt.Where(qb.Eq{"update_id": updateInfo.GetUpdateID().GetValue()})
 
 	if err := s.applyStatement(
@@ -3332,6 +3398,12 @@ func (s *Store) GetUpdateProgress(ctx context.Context, id *peloton.UpdateID) (
 			return nil, err
 		}
 
+		canaryStatus, err := record.GetCanaryStatus()
+		if err != nil {
+			s.metrics.UpdateMetrics.UpdateGetProgessFail.Inc(1)
+			return nil, err
+		}
+
 		updateInfo := &models.UpdateModel{
 			UpdateID:         id,
 			State:            update.State(update.State_value[record.State]),
@@ -3341,6 +3413,7 @@ func (s *Store) GetUpdateProgress(ctx context.Context, id *peloton.UpdateID) (
 			InstancesFailed:  uint32(record.InstancesFailed),
 			InstancesCurrent: record.GetProcessingInstances(),
 			UpdateTime:       record.UpdateTime.Format(time.RFC3339Nano),
+			CanaryStatus:     canaryStatus,
 		}
 
 		s.metrics.UpdateMetrics.UpdateGetProgess.Inc(1)
@@ -3600,6 +3600,106 @@ func (suite *CassandraStoreTestSuite) TestSortedTaskInfoList() {
 }
 
 // TestSortedUpdateList tests sort functions for SortedUpdateList
+// TestUpdateCanary tests persisting the progress of the canary
+// phase of an update
+func (suite *CassandraStoreTestSuite) TestUpdateCanary() {
+	jobID := &peloton.JobID{Value: uuid.New()}
+	updateID := &peloton.UpdateID{Value: uuid.New()}
+	jobVersion := uint64(2)
+
+	canaryStatus := &update.CanaryStatus{
+		State:     update.CanaryState_CANARY_STATE_ROLLING_FORWARD,
+		Instances: []uint32{0, 1},
+	}
+
+	suite.NoError(store.CreateUpdate(
+		context.Background(),
+		&models.UpdateModel{
+			UpdateID: updateID,
+			JobID:    jobID,
+			UpdateConfig: &update.UpdateConfig{
+				BatchSize: 5,
+				Canary: &update.CanaryConfig{
+					InstanceCount:    2,
+					BakeDurationSecs: 60,
+				},
+			},
+			JobConfigVersion:     jobVersion,
+			PrevJobConfigVersion: jobVersion - 1,
+			State:                update.State_INITIALIZED,
+			InstancesTotal:       5,
+			InstancesUpdated:     []uint32{0, 1, 2, 3, 4},
+			Type:                 models.WorkflowType_UPDATE,
+			CanaryStatus:         canaryStatus,
+		},
+	))
+
+	updateResult, err := store.GetUpdate(context.Background(), updateID)
+	suite.NoError(err)
+	suite.Equal(canaryStatus, updateResult.GetCanaryStatus())
+	suite.Equal(uint32(2),
+		updateResult.GetUpdateConfig().GetCanary().GetInstanceCount())
+
+	// progress written without canary status keeps the canary status
+	suite.NoError(store.WriteUpdateProgress(
+		context.Background(),
+		&models.UpdateModel{
+			UpdateID:         updateID,
+			State:            update.State_ROLLING_FORWARD,
+			PrevState:        update.State_INITIALIZED,
+			InstancesCurrent: []uint32{0, 1},
+		},
+	))
+
+	updateResult, err = store.GetUpdateProgress(context.Background(), updateID)
+	suite.NoError(err)
+	suite.Equal(canaryStatus, updateResult.GetCanaryStatus())
+
+	canaryStatus = &update.CanaryStatus{
+		State:         update.CanaryState_CANARY_STATE_BAKING,
+		Instances:     []uint32{0, 1},
+		BakeStartTime: "2019-01-30T21:25:23Z",
+	}
+	suite.NoError(store.AddJobCanaryEvent(
+		context.Background(),
+		updateID,
+		models.WorkflowType_UPDATE,
+		update.State_ROLLING_FORWARD,
+		update.CanaryState_CANARY_STATE_BAKING,
+	))
+	suite.NoError(store.WriteUpdateProgress(
+		context.Background(),
+		&models.UpdateModel{
+			UpdateID:         updateID,
+			State:            update.State_ROLLING_FORWARD,
+			PrevState:        update.State_INITIALIZED,
+			InstancesCurrent: []uint32{0, 1},
+			CanaryStatus:     canaryStatus,
+		},
+	))
+
+	updateResult, err = store.GetUpdateProgress(context.Background(), updateID)
+	suite.NoError(err)
+	suite.Equal(canaryStatus, updateResult.GetCanaryStatus())
+
+	jobUpdateEvents, err := store.GetJobUpdateEvents(
+		context.Background(),
+		updateID)
+	suite.NoError(err)
+	suite.Equal(1, len(jobUpdateEvents))
+	suite.Equal(stateless.WorkflowState_WORKFLOW_STATE_ROLLING_FORWARD,
+		jobUpdateEvents[0].GetState())
+	suite.Equal(stateless.CanaryState_CANARY_STATE_BAKING,
+		jobUpdateEvents[0].GetCanaryState())
+
+	suite.NoError(store.DeleteUpdate(
+		context.Background(),
+		updateID,
+		jobID,
+		jobVersion,
+	))
+}
+
 func (suite *CassandraStoreTestSuite) TestSortedUpdateList() {
 	l := SortedUpdateList{
 		&SortUpdateInfo{
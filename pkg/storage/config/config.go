@@ -25,4 +25,11 @@ type Config struct {
 	UseCassandra       bool             `yaml:"use_cassandra"`
 	AutoMigrate        bool             `yaml:"auto_migrate"`
 	DbWriteConcurrency int              `yaml:"db_write_concurrency"`
+	// InMemoryORMForTesting keeps the objects of the ORM store in the
+	// memory of the daemon instead of Cassandra, for testing. The objects
+	// are neither persisted nor shared with the other daemons, so it does
+	// not support running a cluster. It does not cover the legacy store
+	// of the jobs, tasks, updates and volumes, which still requires
+	// Cassandra.
+	InMemoryORMForTesting bool `yaml:"in_memory_orm_for_testing"`
 }
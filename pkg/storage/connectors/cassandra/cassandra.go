@@ -384,6 +384,29 @@ func (c *cassandraConnector) Update(
 	row []base.Column,
 	keyCols []base.Column,
 ) error {
+	return c.update(ctx, e, row, keyCols, nil, !useCasWrite)
+}
+
+// UpdateIf updates an existing row in DB if the conditions hold, or
+// if the row exists when there are no conditions. Uses CAS write.
+func (c *cassandraConnector) UpdateIf(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	keyCols []base.Column,
+	conditions []base.Column,
+) error {
+	return c.update(ctx, e, row, keyCols, conditions, useCasWrite)
+}
+
+func (c *cassandraConnector) update(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	keyCols []base.Column,
+	conditions []base.Column,
+	casWrite bool,
+) error {
 
 	// split keyCols into a list of names and values to compose query stmt using
 	// names and use values in the session query call, so the order needs to be
@@ -395,11 +418,16 @@ func (c *cassandraConnector) Update(
 	// maintained.
 	colNames, colValues := splitColumnNameValue(row)
 
+	// split conditions the same way for the if clause of a CAS write
+	condNames, condValues := splitColumnNameValue(conditions)
+
 	// Prepare update statement
 	stmt, err := UpdateStmt(
 		Table(e.Name),
 		Updates(colNames),
 		Conditions(keyColNames),
+		IfExists(casWrite),
+		IfConditions(condNames),
 	)
 
 	if err != nil {
@@ -408,14 +436,30 @@ func (c *cassandraConnector) Update(
 
 	// list of values to be supplied in the query
 	updateVals := append(colValues, keyColValues...)
+	updateVals = append(updateVals, condValues...)
 
 	q := c.Session.Query(
 		stmt, updateVals...).WithContext(ctx)
 	defer c.sendLatency(ctx, "execute_latency", time.Duration(q.Latency()))
 
-	if err := q.Exec(); err != nil {
-		c.metrics.ExecuteFail.Inc(1)
-		return err
+	if casWrite {
+		applied, err := q.MapScanCAS(map[string]interface{}{})
+		if err != nil {
+			c.metrics.ExecuteFail.Inc(1)
+			return err
+		}
+		if !applied {
+			if len(conditions) == 0 {
+				return yarpcerrors.NotFoundErrorf("item not found")
+			}
+			return yarpcerrors.FailedPreconditionErrorf(
+				"update conditions not met")
+		}
+	} else {
+		if err := q.Exec(); err != nil {
+			c.metrics.ExecuteFail.Inc(1)
+			return err
+		}
 	}
 
 	c.metrics.ExecuteSuccess.Inc(1)
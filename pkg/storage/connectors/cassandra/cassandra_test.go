@@ -257,6 +257,32 @@ func (suite *CassandraConnSuite) TestCreateUpdateGet() {
 		context.Background(), obj, testUpdateRowWithPrimaryKey, keyRow)
 	suite.Error(err)
 	suite.Equal(err.Error(), "PRIMARY KEY part id found in SET part")
+
+	// the conditional update is only applied if the conditions hold
+	err = connector.UpdateIf(
+		context.Background(),
+		obj,
+		[]base.Column{{Name: "name", Value: "test-cas"}},
+		keyRow,
+		[]base.Column{{Name: "name", Value: "test"}})
+	suite.True(yarpcerrors.IsFailedPrecondition(err))
+
+	err = connector.UpdateIf(
+		context.Background(),
+		obj,
+		[]base.Column{{Name: "name", Value: "test-cas"}},
+		keyRow,
+		[]base.Column{{Name: "name", Value: "test-update"}})
+	suite.NoError(err)
+
+	// the conditional update without conditions does not create the row
+	err = connector.UpdateIf(
+		context.Background(),
+		obj,
+		testUpdateRow,
+		[]base.Column{{Name: "id", Value: uint64(1000)}},
+		nil)
+	suite.True(yarpcerrors.IsNotFound(err))
 }
 
 // TestCreateGetAll tests the GetAll operation
@@ -35,6 +35,12 @@ const (
 	updates = "Updates"
 	// ifNotExist is used to indicate CAS write in the insert query
 	ifNotExist = "IfNotExist"
+	// ifExists is used to indicate CAS write of an existing row in the
+	// update query
+	ifExists = "IfExists"
+	// ifConditions is used to indicate the =? conditions of a CAS write
+	// in the update query
+	ifConditions = "IfConditions"
 
 	// insertTemplate is used to construct an insert query
 	insertTemplate = `INSERT INTO {{.Table}} ({{ColumnFunc .Columns ", "}})` +
@@ -50,7 +56,8 @@ const (
 
 	// updateTemplate is used to construct update query
 	updateTemplate = `UPDATE {{.Table}} SET {{ConditionsFunc .Updates ", "}}` +
-		`{{WhereFunc .Conditions}}{{ConditionsFunc .Conditions " AND "}};`
+		`{{WhereFunc .Conditions}}{{ConditionsFunc .Conditions " AND "}}` +
+		`{{IfFunc .IfExists .IfConditions}};`
 )
 
 var (
@@ -61,6 +68,7 @@ var (
 		"ConditionsFunc": conditionsFunc,
 		"WhereFunc":      whereFunc,
 		"ExistsFunc":     existsFunc,
+		"IfFunc":         ifFunc,
 	}
 
 	// insert CQL query template implementation
@@ -111,6 +119,18 @@ func existsFunc(exists bool) string {
 	return ""
 }
 
+// ifFunc adds an if clause to the update query, either with the given
+// conditions, or an if exists clause if there are no conditions
+func ifFunc(exists bool, conds []string) string {
+	if len(conds) > 0 {
+		return " IF " + conditionsFunc(conds, " AND ")
+	}
+	if exists {
+		return " IF EXISTS"
+	}
+	return ""
+}
+
 // Option to compose a cql statement
 type Option map[string]interface{}
 
@@ -163,6 +183,20 @@ func IfNotExist(v interface{}) OptFunc {
 	}
 }
 
+// IfExists sets the `if exists` clause to the cql statement
+func IfExists(v bool) OptFunc {
+	return func(opt Option) {
+		opt[ifExists] = v
+	}
+}
+
+// IfConditions sets the `if` clause with conditions to the cql statement
+func IfConditions(v []string) OptFunc {
+	return func(opt Option) {
+		opt[ifConditions] = v
+	}
+}
+
 // InsertStmt creates insert statement
 func InsertStmt(opts ...OptFunc) (string, error) {
 	var bb bytes.Buffer
@@ -199,7 +233,7 @@ func DeleteStmt(opts ...OptFunc) (string, error) {
 // UpdateStmt creates update statement
 func UpdateStmt(opts ...OptFunc) (string, error) {
 	var bb bytes.Buffer
-	option := Option{}
+	option := Option{ifExists: false, ifConditions: []string(nil)}
 	for _, opt := range opts {
 		opt(option)
 	}
@@ -197,3 +197,27 @@ func (suite *CassandraConnSuite) TestUpdateStmt() {
 		suite.Equal(stmt, d.stmt)
 	}
 }
+
+// TestConditionalUpdateStmt tests constructing the update statement
+// of a CAS write
+func (suite *CassandraConnSuite) TestConditionalUpdateStmt() {
+	stmt, err := UpdateStmt(
+		Table("table1"),
+		Updates([]string{"c1", "c2"}),
+		Conditions([]string{"c3"}),
+		IfExists(true),
+	)
+	suite.NoError(err)
+	suite.Equal("UPDATE \"table1\" SET c1=?, c2=? WHERE c3=? IF EXISTS;", stmt)
+
+	stmt, err = UpdateStmt(
+		Table("table1"),
+		Updates([]string{"c1", "c2"}),
+		Conditions([]string{"c3"}),
+		IfConditions([]string{"c1", "c4"}),
+	)
+	suite.NoError(err)
+	suite.Equal(
+		"UPDATE \"table1\" SET c1=?, c2=? WHERE c3=? IF c1=? AND c4=?;",
+		stmt)
+}
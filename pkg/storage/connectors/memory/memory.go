@@ -0,0 +1,421 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+	"github.com/uber/peloton/pkg/storage/orm"
+
+	"github.com/gocql/gocql"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// row is a row of a table, mapping the column names to their values
+type row map[string]interface{}
+
+// partition holds the rows of a table sharing the same partition key
+type partition struct {
+	// rows sorted by the clustering keys of the table
+	rows []row
+}
+
+// memoryConnector implements the orm.Connector interface by keeping the
+// rows of each table in memory. Like Cassandra, the rows are grouped by
+// partition key and sorted by clustering keys within a partition, and
+// writes to an existing row are upserts.
+type memoryConnector struct {
+	sync.RWMutex
+
+	// table name -> encoded partition key -> partition
+	tables map[string]map[string]*partition
+}
+
+// NewMemoryConnector initializes an in-memory Connector. The data is not
+// persisted nor shared between processes, so it is only meant for tests.
+func NewMemoryConnector() orm.Connector {
+	return &memoryConnector{
+		tables: make(map[string]map[string]*partition),
+	}
+}
+
+// CreateIfNotExists creates a new row if a row with the same
+// primary key does not exist yet.
+func (c *memoryConnector) CreateIfNotExists(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+) error {
+	return c.create(ctx, e, row, true)
+}
+
+// Create creates a new row, or overwrites the columns of
+// the row with the same primary key.
+func (c *memoryConnector) Create(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+) error {
+	return c.create(ctx, e, row, false)
+}
+
+func (c *memoryConnector) create(
+	ctx context.Context,
+	e *base.Definition,
+	values []base.Column,
+	ifNotExists bool,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := validateColumns(e, values); err != nil {
+		return err
+	}
+
+	partitionKey, clusteringKeys, err := splitPrimaryKey(e, values)
+	if err != nil {
+		return err
+	}
+	if len(clusteringKeys) != len(e.Key.ClusteringKeys) {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"missing clustering key in row of table %s", e.Name)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	p := c.getOrCreatePartition(e.Name, partitionKey)
+	i, found := p.search(e, clusteringKeys)
+	if found {
+		if ifNotExists {
+			return yarpcerrors.AlreadyExistsErrorf("item already exists")
+		}
+		p.rows[i].set(values)
+		return nil
+	}
+
+	r := row{}
+	r.set(values)
+	p.insert(i, r)
+	return nil
+}
+
+// Get fetches the first row matching the primary key. gocql.ErrNotFound
+// is returned if there is no such row, like the Cassandra connector.
+func (c *memoryConnector) Get(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) ([]base.Column, error) {
+	rows, err := c.GetAll(ctx, e, keyCols)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, gocql.ErrNotFound
+	}
+	return rows[0], nil
+}
+
+// GetAll fetches all the rows matching the partition key, and
+// optionally a prefix of the clustering keys, in clustering order.
+func (c *memoryConnector) GetAll(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) ([][]base.Column, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := validateKeyColumns(e, keyCols); err != nil {
+		return nil, err
+	}
+
+	partitionKey, clusteringKeys, err := splitPrimaryKey(e, keyCols)
+	if err != nil {
+		return nil, err
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+
+	p := c.getPartition(e.Name, partitionKey)
+	if p == nil {
+		return nil, nil
+	}
+
+	var rows [][]base.Column
+	for _, r := range p.rows {
+		if r.matches(e, clusteringKeys) {
+			rows = append(rows, r.columns(e))
+		}
+	}
+	return rows, nil
+}
+
+// Update updates the columns of the row matching the primary key,
+// creating the row if it does not exist like Cassandra does.
+func (c *memoryConnector) Update(
+	ctx context.Context,
+	e *base.Definition,
+	values []base.Column,
+	keyCols []base.Column,
+) error {
+	return c.update(ctx, e, values, keyCols, nil, false)
+}
+
+// UpdateIf updates the columns of the row matching the primary key if
+// the conditions hold, or if the row exists when there are no conditions.
+func (c *memoryConnector) UpdateIf(
+	ctx context.Context,
+	e *base.Definition,
+	values []base.Column,
+	keyCols []base.Column,
+	conditions []base.Column,
+) error {
+	return c.update(ctx, e, values, keyCols, conditions, true)
+}
+
+func (c *memoryConnector) update(
+	ctx context.Context,
+	e *base.Definition,
+	values []base.Column,
+	keyCols []base.Column,
+	conditions []base.Column,
+	conditional bool,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := validateColumns(e, values); err != nil {
+		return err
+	}
+
+	if err := validateColumns(e, conditions); err != nil {
+		return err
+	}
+
+	if err := validateKeyColumns(e, keyCols); err != nil {
+		return err
+	}
+
+	partitionKey, clusteringKeys, err := splitPrimaryKey(e, keyCols)
+	if err != nil {
+		return err
+	}
+	if len(clusteringKeys) != len(e.Key.ClusteringKeys) {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"missing clustering key in update of table %s", e.Name)
+	}
+
+	// primary key columns can be present in the values, for instance if
+	// all the fields of an object are updated, but cannot be modified
+	keys := row{}
+	keys.set(keyCols)
+	var updates []base.Column
+	for _, value := range values {
+		if key, ok := keys[value.Name]; ok {
+			if compareValues(key, value.Value) != 0 {
+				return yarpcerrors.InvalidArgumentErrorf(
+					"cannot update primary key column %s of table %s",
+					value.Name, e.Name)
+			}
+			continue
+		}
+		updates = append(updates, value)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if conditional {
+		p := c.getPartition(e.Name, partitionKey)
+		if p == nil {
+			return notAppliedError(conditions)
+		}
+		i, found := p.search(e, clusteringKeys)
+		if !found {
+			return notAppliedError(conditions)
+		}
+		for _, cond := range conditions {
+			if compareValues(p.rows[i][cond.Name], cond.Value) != 0 {
+				return notAppliedError(conditions)
+			}
+		}
+		p.rows[i].set(updates)
+		return nil
+	}
+
+	p := c.getOrCreatePartition(e.Name, partitionKey)
+	i, found := p.search(e, clusteringKeys)
+	if found {
+		p.rows[i].set(updates)
+		return nil
+	}
+
+	r := row{}
+	r.set(keyCols)
+	r.set(updates)
+	p.insert(i, r)
+	return nil
+}
+
+// notAppliedError returns the error of a conditional update which is not
+// applied, like the Cassandra connector
+func notAppliedError(conditions []base.Column) error {
+	if len(conditions) == 0 {
+		return yarpcerrors.NotFoundErrorf("item not found")
+	}
+	return yarpcerrors.FailedPreconditionErrorf("update conditions not met")
+}
+
+// Delete deletes all the rows matching the partition key, and optionally
+// a prefix of the clustering keys. Deleting a row which does not exist
+// is a no-op.
+func (c *memoryConnector) Delete(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := validateKeyColumns(e, keyCols); err != nil {
+		return err
+	}
+
+	partitionKey, clusteringKeys, err := splitPrimaryKey(e, keyCols)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	p := c.getPartition(e.Name, partitionKey)
+	if p == nil {
+		return nil
+	}
+
+	rows := p.rows[:0]
+	for _, r := range p.rows {
+		if !r.matches(e, clusteringKeys) {
+			rows = append(rows, r)
+		}
+	}
+	p.rows = rows
+
+	if len(p.rows) == 0 {
+		delete(c.tables[e.Name], partitionKey)
+	}
+	return nil
+}
+
+// getPartition returns the partition of a table, or nil if it is empty
+func (c *memoryConnector) getPartition(
+	table string,
+	partitionKey string,
+) *partition {
+	return c.tables[table][partitionKey]
+}
+
+// getOrCreatePartition returns the partition of a table,
+// creating it if it is empty
+func (c *memoryConnector) getOrCreatePartition(
+	table string,
+	partitionKey string,
+) *partition {
+	partitions, ok := c.tables[table]
+	if !ok {
+		partitions = make(map[string]*partition)
+		c.tables[table] = partitions
+	}
+
+	p, ok := partitions[partitionKey]
+	if !ok {
+		p = &partition{}
+		partitions[partitionKey] = p
+	}
+	return p
+}
+
+// search returns the index of the row with the given clustering keys, and
+// whether it exists. If it does not exist, the index is where the row
+// needs to be inserted to keep the rows sorted.
+func (p *partition) search(
+	e *base.Definition,
+	clusteringKeys []base.Column,
+) (int, bool) {
+	i := sort.Search(len(p.rows), func(i int) bool {
+		return p.rows[i].compare(e, clusteringKeys) >= 0
+	})
+	return i, i < len(p.rows) && p.rows[i].compare(e, clusteringKeys) == 0
+}
+
+// insert inserts a row at the given index
+func (p *partition) insert(i int, r row) {
+	p.rows = append(p.rows, nil)
+	copy(p.rows[i+1:], p.rows[i:])
+	p.rows[i] = r
+}
+
+// set sets the values of the given columns in the row
+func (r row) set(values []base.Column) {
+	for _, value := range values {
+		r[value.Name] = copyValue(value.Value)
+	}
+}
+
+// columns returns a copy of all the columns of the row. Columns which
+// were never set are returned with a nil value, like null columns
+// read from Cassandra.
+func (r row) columns(e *base.Definition) []base.Column {
+	var columns []base.Column
+	for _, name := range e.GetColumnsToRead() {
+		columns = append(columns, base.Column{
+			Name:  name,
+			Value: copyValue(r[name]),
+		})
+	}
+	return columns
+}
+
+// compare compares the clustering keys of the row with the given
+// clustering keys, honouring the clustering order of the table. Only
+// the clustering keys which are given are compared.
+func (r row) compare(e *base.Definition, clusteringKeys []base.Column) int {
+	for i, ck := range clusteringKeys {
+		result := compareValues(r[ck.Name], ck.Value)
+		if e.Key.ClusteringKeys[i].Descending {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// matches returns true if the row has the given clustering keys
+func (r row) matches(e *base.Definition, clusteringKeys []base.Column) bool {
+	return r.compare(e, clusteringKeys) == 0
+}
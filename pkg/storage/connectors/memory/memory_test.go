@@ -0,0 +1,443 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+	"github.com/uber/peloton/pkg/storage/orm"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// testTable has the primary key "id"
+var testTable = &base.Definition{
+	Name: "test_table",
+	Key: &base.PrimaryKey{
+		PartitionKeys: []string{"id"},
+	},
+	ColumnToType: map[string]reflect.Type{
+		"id":   reflect.TypeOf(1),
+		"data": reflect.TypeOf("data"),
+		"name": reflect.TypeOf("name"),
+	},
+}
+
+// testTableWithCK has the partition key "id" and the
+// descending clustering key "ck"
+var testTableWithCK = &base.Definition{
+	Name: "test_table_ck",
+	Key: &base.PrimaryKey{
+		PartitionKeys: []string{"id"},
+		ClusteringKeys: []*base.ClusteringKey{
+			{
+				Name:       "ck",
+				Descending: true,
+			},
+		},
+	},
+	ColumnToType: map[string]reflect.Type{
+		"id":   reflect.TypeOf(1),
+		"ck":   reflect.TypeOf(1),
+		"data": reflect.TypeOf("data"),
+	},
+}
+
+var testRow = []base.Column{
+	{Name: "id", Value: uint64(1)},
+	{Name: "name", Value: "test"},
+	{Name: "data", Value: "testdata"},
+}
+
+var keyRow = []base.Column{
+	{Name: "id", Value: uint64(1)},
+}
+
+type MemoryConnSuite struct {
+	suite.Suite
+
+	ctx       context.Context
+	connector orm.Connector
+}
+
+func (suite *MemoryConnSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.connector = NewMemoryConnector()
+}
+
+func TestMemoryConnSuite(t *testing.T) {
+	suite.Run(t, new(MemoryConnSuite))
+}
+
+// getValue returns the value of a column of the row
+func getValue(row []base.Column, name string) interface{} {
+	for _, col := range row {
+		if col.Name == name {
+			return col.Value
+		}
+	}
+	return nil
+}
+
+// createRowsWithCK creates rows in the test table with clustering keys
+func (suite *MemoryConnSuite) createRowsWithCK(id uint64, cks ...uint64) {
+	for _, ck := range cks {
+		suite.NoError(suite.connector.Create(
+			suite.ctx,
+			testTableWithCK,
+			[]base.Column{
+				{Name: "id", Value: id},
+				{Name: "ck", Value: ck},
+				{Name: "data", Value: "testdata"},
+			}))
+	}
+}
+
+// TestCreateGetDelete creates a row, reads it back, and then deletes it
+func (suite *MemoryConnSuite) TestCreateGetDelete() {
+	suite.NoError(suite.connector.Create(suite.ctx, testTable, testRow))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow)
+	suite.NoError(err)
+	suite.Len(row, 3)
+	suite.Equal("test", getValue(row, "name"))
+	suite.Equal("testdata", getValue(row, "data"))
+
+	suite.NoError(suite.connector.Delete(suite.ctx, testTable, keyRow))
+
+	_, err = suite.connector.Get(suite.ctx, testTable, keyRow)
+	suite.Equal(gocql.ErrNotFound, err)
+
+	// deleting a row which does not exist is a noop
+	suite.NoError(suite.connector.Delete(suite.ctx, testTable, keyRow))
+}
+
+// TestGetKeyOfDifferentType tests that integer keys match
+// irrespective of their type
+func (suite *MemoryConnSuite) TestGetKeyOfDifferentType() {
+	suite.NoError(suite.connector.Create(suite.ctx, testTable, testRow))
+
+	_, err := suite.connector.Get(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "id", Value: 1}})
+	suite.NoError(err)
+}
+
+// TestCreateOverwrites tests that Create overwrites an existing row
+func (suite *MemoryConnSuite) TestCreateOverwrites() {
+	suite.NoError(suite.connector.Create(suite.ctx, testTable, testRow))
+	suite.NoError(suite.connector.Create(
+		suite.ctx,
+		testTable,
+		[]base.Column{
+			{Name: "id", Value: uint64(1)},
+			{Name: "name", Value: "test-overwrite"},
+		}))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow)
+	suite.NoError(err)
+	suite.Equal("test-overwrite", getValue(row, "name"))
+	suite.Equal("testdata", getValue(row, "data"))
+}
+
+// TestCreateIfNotExists tests that CreateIfNotExists
+// fails if the row already exists
+func (suite *MemoryConnSuite) TestCreateIfNotExists() {
+	suite.NoError(
+		suite.connector.CreateIfNotExists(suite.ctx, testTable, testRow))
+
+	err := suite.connector.CreateIfNotExists(suite.ctx, testTable, testRow)
+	suite.True(yarpcerrors.IsAlreadyExists(err))
+
+	// a row with another primary key can be created
+	suite.NoError(suite.connector.CreateIfNotExists(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "id", Value: uint64(2)}}))
+}
+
+// TestCreateInvalidRow tests creating rows with unknown or missing columns
+func (suite *MemoryConnSuite) TestCreateInvalidRow() {
+	err := suite.connector.Create(
+		suite.ctx,
+		testTable,
+		append([]base.Column{{Name: "unknown", Value: 1}}, testRow...))
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	err = suite.connector.Create(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "name", Value: "test"}})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	err = suite.connector.Create(suite.ctx, testTableWithCK, keyRow)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestCreateUpdateGet tests updating some columns of a row
+func (suite *MemoryConnSuite) TestCreateUpdateGet() {
+	suite.NoError(suite.connector.Create(suite.ctx, testTable, testRow))
+
+	suite.NoError(suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "name", Value: "test-update"}},
+		keyRow))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow)
+	suite.NoError(err)
+	suite.Equal("test-update", getValue(row, "name"))
+	suite.Equal("testdata", getValue(row, "data"))
+
+	// the primary key columns cannot be modified
+	err = suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{
+			{Name: "id", Value: uint64(2)},
+			{Name: "name", Value: "test-update"},
+		},
+		keyRow)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestUpdateCreatesRow tests that updating a row which
+// does not exist creates it
+func (suite *MemoryConnSuite) TestUpdateCreatesRow() {
+	suite.NoError(suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "name", Value: "test"}},
+		keyRow))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow)
+	suite.NoError(err)
+	suite.Equal(uint64(1), getValue(row, "id"))
+	suite.Equal("test", getValue(row, "name"))
+	suite.Nil(getValue(row, "data"))
+}
+
+// TestUpdateIf tests that a conditional update is only applied if the
+// conditions hold, and never creates the row
+func (suite *MemoryConnSuite) TestUpdateIf() {
+	update := []base.Column{{Name: "name", Value: "test-update"}}
+
+	err := suite.connector.UpdateIf(
+		suite.ctx, testTable, update, keyRow, nil)
+	suite.True(yarpcerrors.IsNotFound(err))
+
+	err = suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		update,
+		keyRow,
+		[]base.Column{{Name: "name", Value: "test"}})
+	suite.True(yarpcerrors.IsFailedPrecondition(err))
+
+	_, err = suite.connector.Get(suite.ctx, testTable, keyRow)
+	suite.Equal(gocql.ErrNotFound, err)
+
+	suite.NoError(suite.connector.Create(suite.ctx, testTable, testRow))
+
+	err = suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		update,
+		keyRow,
+		[]base.Column{{Name: "name", Value: "other"}})
+	suite.True(yarpcerrors.IsFailedPrecondition(err))
+
+	suite.NoError(suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		update,
+		keyRow,
+		[]base.Column{{Name: "name", Value: "test"}}))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow)
+	suite.NoError(err)
+	suite.Equal("test-update", getValue(row, "name"))
+
+	suite.NoError(suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "data", Value: "data-update"}},
+		keyRow,
+		nil))
+
+	row, err = suite.connector.Get(suite.ctx, testTable, keyRow)
+	suite.NoError(err)
+	suite.Equal("data-update", getValue(row, "data"))
+}
+
+// TestGetAllClusteringOrder tests that GetAll returns the rows of
+// the partition only, sorted by descending clustering key
+func (suite *MemoryConnSuite) TestGetAllClusteringOrder() {
+	suite.createRowsWithCK(1, 20, 10, 30)
+	suite.createRowsWithCK(2, 40)
+
+	rows, err := suite.connector.GetAll(suite.ctx, testTableWithCK, keyRow)
+	suite.NoError(err)
+	suite.Len(rows, 3)
+	for i, ck := range []uint64{30, 20, 10} {
+		suite.Equal(uint64(1), getValue(rows[i], "id"))
+		suite.Equal(ck, getValue(rows[i], "ck"))
+	}
+
+	rows, err = suite.connector.GetAll(
+		suite.ctx,
+		testTableWithCK,
+		[]base.Column{{Name: "id", Value: uint64(3)}})
+	suite.NoError(err)
+	suite.Empty(rows)
+}
+
+// TestGetAllAscending tests the ascending clustering order
+func (suite *MemoryConnSuite) TestGetAllAscending() {
+	table := *testTableWithCK
+	table.Name = "test_table_ck_asc"
+	table.Key = &base.PrimaryKey{
+		PartitionKeys:  []string{"id"},
+		ClusteringKeys: []*base.ClusteringKey{{Name: "ck"}},
+	}
+
+	for _, ck := range []int64{5, -10, 0} {
+		suite.NoError(suite.connector.Create(
+			suite.ctx,
+			&table,
+			[]base.Column{
+				{Name: "id", Value: uint64(1)},
+				{Name: "ck", Value: ck},
+			}))
+	}
+
+	rows, err := suite.connector.GetAll(suite.ctx, &table, keyRow)
+	suite.NoError(err)
+	suite.Len(rows, 3)
+	for i, ck := range []int64{-10, 0, 5} {
+		suite.Equal(ck, getValue(rows[i], "ck"))
+	}
+}
+
+// TestGetWithClusteringKey tests fetching and deleting a single row
+// of a partition
+func (suite *MemoryConnSuite) TestGetWithClusteringKey() {
+	suite.createRowsWithCK(1, 10, 20)
+
+	keys := []base.Column{
+		{Name: "id", Value: uint64(1)},
+		{Name: "ck", Value: uint64(10)},
+	}
+	row, err := suite.connector.Get(suite.ctx, testTableWithCK, keys)
+	suite.NoError(err)
+	suite.Equal(uint64(10), getValue(row, "ck"))
+
+	suite.NoError(suite.connector.Delete(suite.ctx, testTableWithCK, keys))
+
+	_, err = suite.connector.Get(suite.ctx, testTableWithCK, keys)
+	suite.Equal(gocql.ErrNotFound, err)
+
+	rows, err := suite.connector.GetAll(suite.ctx, testTableWithCK, keyRow)
+	suite.NoError(err)
+	suite.Len(rows, 1)
+	suite.Equal(uint64(20), getValue(rows[0], "ck"))
+}
+
+// TestDeletePartition tests deleting all the rows of a partition
+func (suite *MemoryConnSuite) TestDeletePartition() {
+	suite.createRowsWithCK(1, 10, 20)
+	suite.createRowsWithCK(2, 10)
+
+	suite.NoError(suite.connector.Delete(suite.ctx, testTableWithCK, keyRow))
+
+	rows, err := suite.connector.GetAll(suite.ctx, testTableWithCK, keyRow)
+	suite.NoError(err)
+	suite.Empty(rows)
+
+	rows, err = suite.connector.GetAll(
+		suite.ctx,
+		testTableWithCK,
+		[]base.Column{{Name: "id", Value: uint64(2)}})
+	suite.NoError(err)
+	suite.Len(rows, 1)
+}
+
+// TestInvalidKeys tests reading with keys which are not a partition
+// key followed by a prefix of the clustering keys
+func (suite *MemoryConnSuite) TestInvalidKeys() {
+	_, err := suite.connector.Get(
+		suite.ctx,
+		testTableWithCK,
+		[]base.Column{{Name: "ck", Value: uint64(10)}})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	_, err = suite.connector.GetAll(
+		suite.ctx,
+		testTableWithCK,
+		[]base.Column{
+			{Name: "id", Value: uint64(1)},
+			{Name: "data", Value: "testdata"},
+		})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	err = suite.connector.Update(
+		suite.ctx,
+		testTableWithCK,
+		[]base.Column{{Name: "data", Value: "testdata"}},
+		keyRow)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestValuesAreCopied tests that the stored rows do not
+// share memory with the callers
+func (suite *MemoryConnSuite) TestValuesAreCopied() {
+	table := *testTable
+	table.Name = "test_table_bytes"
+	table.ColumnToType = map[string]reflect.Type{
+		"id":   reflect.TypeOf(1),
+		"data": reflect.TypeOf([]byte{}),
+	}
+
+	data := []byte("testdata")
+	suite.NoError(suite.connector.Create(
+		suite.ctx,
+		&table,
+		[]base.Column{
+			{Name: "id", Value: uint64(1)},
+			{Name: "data", Value: data},
+		}))
+	data[0] = 'x'
+
+	row, err := suite.connector.Get(suite.ctx, &table, keyRow)
+	suite.NoError(err)
+	suite.Equal([]byte("testdata"), getValue(row, "data"))
+}
+
+// TestContextCanceled tests that operations fail with a canceled context
+func (suite *MemoryConnSuite) TestContextCanceled() {
+	ctx, cancel := context.WithCancel(suite.ctx)
+	cancel()
+
+	suite.Error(suite.connector.Create(ctx, testTable, testRow))
+	_, err := suite.connector.Get(ctx, testTable, keyRow)
+	suite.Error(err)
+	suite.Error(suite.connector.Delete(ctx, testTable, keyRow))
+}
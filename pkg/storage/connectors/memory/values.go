@@ -0,0 +1,272 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gocql/gocql"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// validateColumns checks that all the columns belong to the table
+func validateColumns(e *base.Definition, columns []base.Column) error {
+	for _, column := range columns {
+		if _, ok := e.ColumnToType[column.Name]; !ok {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"unknown column %s in table %s", column.Name, e.Name)
+		}
+	}
+	return nil
+}
+
+// splitPrimaryKey returns the encoded partition key of the given columns,
+// which must contain all the partition key columns, and the longest
+// prefix of clustering key columns present in the given columns. Like in
+// Cassandra, a clustering key can only be given if all the preceding
+// clustering keys are given as well.
+func splitPrimaryKey(
+	e *base.Definition,
+	columns []base.Column,
+) (string, []base.Column, error) {
+	values := make(map[string]interface{})
+	for _, column := range columns {
+		values[column.Name] = column.Value
+	}
+
+	var partitionKey strings.Builder
+	for _, pk := range e.Key.PartitionKeys {
+		value, ok := values[pk]
+		if !ok {
+			return "", nil, yarpcerrors.InvalidArgumentErrorf(
+				"missing partition key column %s of table %s", pk, e.Name)
+		}
+		// prefix each value with its length so that
+		// the encoding of different keys cannot collide
+		encoded := encodeValue(value)
+		fmt.Fprintf(&partitionKey, "%d:%s", len(encoded), encoded)
+	}
+
+	var clusteringKeys []base.Column
+	for _, ck := range e.Key.ClusteringKeys {
+		value, ok := values[ck.Name]
+		if !ok {
+			break
+		}
+		clusteringKeys = append(clusteringKeys, base.Column{
+			Name:  ck.Name,
+			Value: value,
+		})
+	}
+	for _, ck := range e.Key.ClusteringKeys[len(clusteringKeys):] {
+		if _, ok := values[ck.Name]; ok {
+			return "", nil, yarpcerrors.InvalidArgumentErrorf(
+				"clustering key column %s of table %s is given without "+
+					"the preceding clustering keys", ck.Name, e.Name)
+		}
+	}
+	return partitionKey.String(), clusteringKeys, nil
+}
+
+// validateKeyColumns checks that the columns are all primary key columns
+func validateKeyColumns(e *base.Definition, columns []base.Column) error {
+	keys := make(map[string]struct{})
+	for _, pk := range e.Key.PartitionKeys {
+		keys[pk] = struct{}{}
+	}
+	for _, ck := range e.Key.ClusteringKeys {
+		keys[ck.Name] = struct{}{}
+	}
+
+	for _, column := range columns {
+		if _, ok := keys[column.Name]; !ok {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"column %s is not part of the primary key of table %s",
+				column.Name, e.Name)
+		}
+	}
+	return nil
+}
+
+// encodeValue encodes a value as a string, such that values which are
+// equal according to compareValues have the same encoding
+func encodeValue(value interface{}) string {
+	value = indirect(value)
+	if value == nil {
+		return ""
+	}
+
+	if neg, mag, ok := toNumber(value); ok {
+		if neg {
+			return "-" + strconv.FormatUint(mag, 10)
+		}
+		return strconv.FormatUint(mag, 10)
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return strconv.FormatInt(v.UnixNano(), 10)
+	case gocql.UUID:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compareValues compares two column values of the same column, returning
+// -1, 0 or 1. Integers are compared by value irrespective of their type,
+// and time UUIDs are compared by time first like in Cassandra.
+func compareValues(a, b interface{}) int {
+	a, b = indirect(a), indirect(b)
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	if aNeg, aMag, ok := toNumber(a); ok {
+		if bNeg, bMag, ok := toNumber(b); ok {
+			return compareNumbers(aNeg, aMag, bNeg, bMag)
+		}
+	}
+
+	switch av := a.(type) {
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv)
+		}
+	case []byte:
+		if bv, ok := b.([]byte); ok {
+			return bytes.Compare(av, bv)
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0
+			case !av:
+				return -1
+			default:
+				return 1
+			}
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			switch {
+			case av.Before(bv):
+				return -1
+			case av.After(bv):
+				return 1
+			default:
+				return 0
+			}
+		}
+	case gocql.UUID:
+		if bv, ok := b.(gocql.UUID); ok {
+			return compareUUIDs(av, bv)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// compareUUIDs compares two UUIDs, ordering time UUIDs by their time
+func compareUUIDs(a, b gocql.UUID) int {
+	if a.Version() == 1 && b.Version() == 1 {
+		ta, tb := a.Timestamp(), b.Timestamp()
+		switch {
+		case ta < tb:
+			return -1
+		case ta > tb:
+			return 1
+		}
+	}
+	return bytes.Compare(a.Bytes(), b.Bytes())
+}
+
+// toNumber converts an integer of any type into its sign and magnitude
+func toNumber(value interface{}) (neg bool, mag uint64, ok bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		if i < 0 {
+			return true, uint64(-(i + 1)) + 1, true
+		}
+		return false, uint64(i), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return false, v.Uint(), true
+	}
+	return false, 0, false
+}
+
+// compareNumbers compares two integers given by their sign and magnitude
+func compareNumbers(aNeg bool, aMag uint64, bNeg bool, bMag uint64) int {
+	if aNeg != bNeg {
+		if aNeg {
+			return -1
+		}
+		return 1
+	}
+
+	result := 0
+	switch {
+	case aMag < bMag:
+		result = -1
+	case aMag > bMag:
+		result = 1
+	}
+	if aNeg {
+		return -result
+	}
+	return result
+}
+
+// indirect dereferences a pointer value, returning nil for a nil pointer
+func indirect(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Ptr {
+		return value
+	}
+	if v.IsNil() {
+		return nil
+	}
+	return v.Elem().Interface()
+}
+
+// copyValue returns a copy of a value which does not share
+// memory with the original value
+func copyValue(value interface{}) interface{} {
+	value = indirect(value)
+	if b, ok := value.([]byte); ok && b != nil {
+		return append([]byte(nil), b...)
+	}
+	return value
+}
@@ -230,6 +230,16 @@ type UpdateStore interface {
 		updateState update.State,
 	) error
 
+	// AddJobCanaryEvent adds a state change event of the canary phase
+	// of an update for a job
+	AddJobCanaryEvent(
+		ctx context.Context,
+		updateID *peloton.UpdateID,
+		updateType models.WorkflowType,
+		updateState update.State,
+		canaryState update.CanaryState,
+	) error
+
 	// GetJobUpdateEvents gets update state events for a job
 	// in descending create timestamp order
 	GetJobUpdateEvents(
@@ -118,6 +118,40 @@ type OrmJobMetrics struct {
 	SecretInfoUpdateFail tally.Counter
 	SecretInfoDelete     tally.Counter
 	SecretInfoDeleteFail tally.Counter
+
+	// cron_jobs
+	CronJobCreate     tally.Counter
+	CronJobCreateFail tally.Counter
+	CronJobGet        tally.Counter
+	CronJobGetFail    tally.Counter
+	CronJobGetAll     tally.Counter
+	CronJobGetAllFail tally.Counter
+	CronJobUpdate     tally.Counter
+	CronJobUpdateFail tally.Counter
+	CronJobDelete     tally.Counter
+	CronJobDeleteFail tally.Counter
+
+	// cron_job_runs
+	CronJobRunCreate     tally.Counter
+	CronJobRunCreateFail tally.Counter
+	CronJobRunGetAll     tally.Counter
+	CronJobRunGetAllFail tally.Counter
+	CronJobRunDelete     tally.Counter
+	CronJobRunDeleteFail tally.Counter
+
+	// job_workflows
+	JobWorkflowCreate         tally.Counter
+	JobWorkflowCreateFail     tally.Counter
+	JobWorkflowGet            tally.Counter
+	JobWorkflowGetFail        tally.Counter
+	JobWorkflowGetAll         tally.Counter
+	JobWorkflowGetAllFail     tally.Counter
+	JobWorkflowGetRunning     tally.Counter
+	JobWorkflowGetRunningFail tally.Counter
+	JobWorkflowUpdate         tally.Counter
+	JobWorkflowUpdateFail     tally.Counter
+	JobWorkflowDelete         tally.Counter
+	JobWorkflowDeleteFail     tally.Counter
 }
 
 // TaskMetrics is a struct for tracking all the task related counters in the storage layer
@@ -307,6 +341,42 @@ type OrmTaskMetrics struct {
 	PodEventsGetFail tally.Counter
 }
 
+// OrmHostMetrics tracks counters for host related tables
+type OrmHostMetrics struct {
+	MaintenanceWindowCreate     tally.Counter
+	MaintenanceWindowCreateFail tally.Counter
+	MaintenanceWindowGetAll     tally.Counter
+	MaintenanceWindowGetAllFail tally.Counter
+	MaintenanceWindowDelete     tally.Counter
+	MaintenanceWindowDeleteFail tally.Counter
+}
+
+// OrmAuditMetrics tracks counters for audit related tables
+type OrmAuditMetrics struct {
+	AuditRecordCreate     tally.Counter
+	AuditRecordCreateFail tally.Counter
+	AuditRecordQuery      tally.Counter
+	AuditRecordQueryFail  tally.Counter
+}
+
+// OrmWatchMetrics tracks counters for watch related tables
+type OrmWatchMetrics struct {
+	WatchEventCreate        tally.Counter
+	WatchEventCreateFail    tally.Counter
+	WatchEventQuery         tally.Counter
+	WatchEventQueryFail     tally.Counter
+	WatchEventDelete        tally.Counter
+	WatchEventDeleteFail    tally.Counter
+	WatchRevisionGetAll     tally.Counter
+	WatchRevisionGetAllFail tally.Counter
+	WatchRevisionCreate     tally.Counter
+	WatchRevisionCreateFail tally.Counter
+	WatchRevisionUpdate     tally.Counter
+	WatchRevisionUpdateFail tally.Counter
+	WatchRevisionDelete     tally.Counter
+	WatchRevisionDeleteFail tally.Counter
+}
+
 // Metrics is a struct for tracking all the general purpose counters that have relevance to the storage
 // layer, i.e. how many jobs and tasks were created/deleted in the storage layer
 type Metrics struct {
@@ -321,6 +391,9 @@ type Metrics struct {
 	WorkflowMetrics       *WorkflowMetrics
 	OrmJobMetrics         *OrmJobMetrics
 	OrmTaskMetrics        *OrmTaskMetrics
+	OrmHostMetrics        *OrmHostMetrics
+	OrmAuditMetrics       *OrmAuditMetrics
+	OrmWatchMetrics       *OrmWatchMetrics
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics initialized and rooted at the given tally.Scope
@@ -603,6 +676,48 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	secretInfoFailScope := secretInfoScope.Tagged(
 		map[string]string{"result": "fail"})
 
+	cronJobScope := ormScope.SubScope("cron_jobs")
+	cronJobSuccessScope := cronJobScope.Tagged(
+		map[string]string{"result": "success"})
+	cronJobFailScope := cronJobScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	cronJobRunScope := ormScope.SubScope("cron_job_runs")
+	cronJobRunSuccessScope := cronJobRunScope.Tagged(
+		map[string]string{"result": "success"})
+	cronJobRunFailScope := cronJobRunScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	jobWorkflowScope := ormScope.SubScope("job_workflows")
+	jobWorkflowSuccessScope := jobWorkflowScope.Tagged(
+		map[string]string{"result": "success"})
+	jobWorkflowFailScope := jobWorkflowScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	maintenanceWindowScope := ormScope.SubScope("maintenance_windows")
+	maintenanceWindowSuccessScope := maintenanceWindowScope.Tagged(
+		map[string]string{"result": "success"})
+	maintenanceWindowFailScope := maintenanceWindowScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	auditRecordScope := ormScope.SubScope("audit_records")
+	auditRecordSuccessScope := auditRecordScope.Tagged(
+		map[string]string{"result": "success"})
+	auditRecordFailScope := auditRecordScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	watchEventScope := ormScope.SubScope("watch_events")
+	watchEventSuccessScope := watchEventScope.Tagged(
+		map[string]string{"result": "success"})
+	watchEventFailScope := watchEventScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	watchRevisionScope := ormScope.SubScope("watch_revisions")
+	watchRevisionSuccessScope := watchRevisionScope.Tagged(
+		map[string]string{"result": "success"})
+	watchRevisionFailScope := watchRevisionScope.Tagged(
+		map[string]string{"result": "fail"})
+
 	ormJobMetrics := &OrmJobMetrics{
 		JobIndexCreate:     jobIndexSuccessScope.Counter("create"),
 		JobIndexCreateFail: jobIndexFailScope.Counter("create"),
@@ -633,6 +748,37 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		SecretInfoUpdateFail: secretInfoFailScope.Counter("update"),
 		SecretInfoDelete:     secretInfoSuccessScope.Counter("delete"),
 		SecretInfoDeleteFail: secretInfoFailScope.Counter("delete"),
+
+		CronJobCreate:     cronJobSuccessScope.Counter("create"),
+		CronJobCreateFail: cronJobFailScope.Counter("create"),
+		CronJobGet:        cronJobSuccessScope.Counter("get"),
+		CronJobGetFail:    cronJobFailScope.Counter("get"),
+		CronJobGetAll:     cronJobSuccessScope.Counter("get_all"),
+		CronJobGetAllFail: cronJobFailScope.Counter("get_all"),
+		CronJobUpdate:     cronJobSuccessScope.Counter("update"),
+		CronJobUpdateFail: cronJobFailScope.Counter("update"),
+		CronJobDelete:     cronJobSuccessScope.Counter("delete"),
+		CronJobDeleteFail: cronJobFailScope.Counter("delete"),
+
+		CronJobRunCreate:     cronJobRunSuccessScope.Counter("create"),
+		CronJobRunCreateFail: cronJobRunFailScope.Counter("create"),
+		CronJobRunGetAll:     cronJobRunSuccessScope.Counter("get_all"),
+		CronJobRunGetAllFail: cronJobRunFailScope.Counter("get_all"),
+		CronJobRunDelete:     cronJobRunSuccessScope.Counter("delete"),
+		CronJobRunDeleteFail: cronJobRunFailScope.Counter("delete"),
+
+		JobWorkflowCreate:         jobWorkflowSuccessScope.Counter("create"),
+		JobWorkflowCreateFail:     jobWorkflowFailScope.Counter("create"),
+		JobWorkflowGet:            jobWorkflowSuccessScope.Counter("get"),
+		JobWorkflowGetFail:        jobWorkflowFailScope.Counter("get"),
+		JobWorkflowGetAll:         jobWorkflowSuccessScope.Counter("get_all"),
+		JobWorkflowGetAllFail:     jobWorkflowFailScope.Counter("get_all"),
+		JobWorkflowGetRunning:     jobWorkflowSuccessScope.Counter("get_running"),
+		JobWorkflowGetRunningFail: jobWorkflowFailScope.Counter("get_running"),
+		JobWorkflowUpdate:         jobWorkflowSuccessScope.Counter("update"),
+		JobWorkflowUpdateFail:     jobWorkflowFailScope.Counter("update"),
+		JobWorkflowDelete:         jobWorkflowSuccessScope.Counter("delete"),
+		JobWorkflowDeleteFail:     jobWorkflowFailScope.Counter("delete"),
 	}
 
 	ormTaskMetrics := &OrmTaskMetrics{
@@ -642,6 +788,39 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		PodEventsGetFail: podEventsFailScope.Counter("get"),
 	}
 
+	ormHostMetrics := &OrmHostMetrics{
+		MaintenanceWindowCreate:     maintenanceWindowSuccessScope.Counter("create"),
+		MaintenanceWindowCreateFail: maintenanceWindowFailScope.Counter("create"),
+		MaintenanceWindowGetAll:     maintenanceWindowSuccessScope.Counter("get_all"),
+		MaintenanceWindowGetAllFail: maintenanceWindowFailScope.Counter("get_all"),
+		MaintenanceWindowDelete:     maintenanceWindowSuccessScope.Counter("delete"),
+		MaintenanceWindowDeleteFail: maintenanceWindowFailScope.Counter("delete"),
+	}
+
+	ormAuditMetrics := &OrmAuditMetrics{
+		AuditRecordCreate:     auditRecordSuccessScope.Counter("create"),
+		AuditRecordCreateFail: auditRecordFailScope.Counter("create"),
+		AuditRecordQuery:      auditRecordSuccessScope.Counter("query"),
+		AuditRecordQueryFail:  auditRecordFailScope.Counter("query"),
+	}
+
+	ormWatchMetrics := &OrmWatchMetrics{
+		WatchEventCreate:        watchEventSuccessScope.Counter("create"),
+		WatchEventCreateFail:    watchEventFailScope.Counter("create"),
+		WatchEventQuery:         watchEventSuccessScope.Counter("query"),
+		WatchEventQueryFail:     watchEventFailScope.Counter("query"),
+		WatchEventDelete:        watchEventSuccessScope.Counter("delete"),
+		WatchEventDeleteFail:    watchEventFailScope.Counter("delete"),
+		WatchRevisionGetAll:     watchRevisionSuccessScope.Counter("get_all"),
+		WatchRevisionGetAllFail: watchRevisionFailScope.Counter("get_all"),
+		WatchRevisionCreate:     watchRevisionSuccessScope.Counter("create"),
+		WatchRevisionCreateFail: watchRevisionFailScope.Counter("create"),
+		WatchRevisionUpdate:     watchRevisionSuccessScope.Counter("update"),
+		WatchRevisionUpdateFail: watchRevisionFailScope.Counter("update"),
+		WatchRevisionDelete:     watchRevisionSuccessScope.Counter("delete"),
+		WatchRevisionDeleteFail: watchRevisionFailScope.Counter("delete"),
+	}
+
 	metrics := &Metrics{
 		JobMetrics:            jobMetrics,
 		TaskMetrics:           taskMetrics,
@@ -654,6 +833,9 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		WorkflowMetrics:       workflowMetrics,
 		OrmJobMetrics:         ormJobMetrics,
 		OrmTaskMetrics:        ormTaskMetrics,
+		OrmHostMetrics:        ormHostMetrics,
+		OrmAuditMetrics:       ormAuditMetrics,
+		OrmWatchMetrics:       ormWatchMetrics,
 	}
 
 	return metrics
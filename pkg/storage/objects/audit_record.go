@@ -0,0 +1,201 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gocql/gocql"
+)
+
+const (
+	// format of the day which partitions the audit records
+	_auditRecordDayFormat = "2006-01-02"
+	// separator of the entities of an audit record
+	_auditRecordEntitySeparator = ","
+)
+
+// init adds a AuditRecordObject instance to the global list of storage objects
+func init() {
+	Objs = append(Objs, &AuditRecordObject{})
+}
+
+// AuditRecordObject corresponds to a row in audit_records table.
+type AuditRecordObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=audit_records, primaryKey=((day), record_id)"`
+
+	// Day of the call, which partitions the records
+	Day string `column:"name=day"`
+	// RecordID of the record, based on the time of the call
+	RecordID gocql.UUID `column:"name=record_id"`
+	// User which made the call
+	User string `column:"name=username"`
+	// Procedure which was called
+	Procedure string `column:"name=procedure"`
+	// Entities targeted by the call, separated by commas
+	Entities string `column:"name=entities"`
+	// Redacted request of the call
+	Request string `column:"name=request"`
+	// Outcome of the call
+	Outcome string `column:"name=outcome"`
+	// Message of the error returned by the call
+	Message string `column:"name=message"`
+}
+
+// AuditRecordOps provides methods for manipulating audit_records table.
+type AuditRecordOps interface {
+	// Create inserts a row in the table.
+	Create(ctx context.Context, record *audit.AuditRecord) error
+
+	// Query retrieves the rows of the calls received in [from, to)
+	// which match the filter from the table, sorted by descending time.
+	// At most limit rows are retrieved, if limit is positive.
+	Query(
+		ctx context.Context,
+		from time.Time,
+		to time.Time,
+		filter func(*AuditRecordObject) bool,
+		limit int,
+	) ([]*AuditRecordObject, error)
+}
+
+// ensure that default implementation (auditRecordOps) satisfies the interface
+var _ AuditRecordOps = (*auditRecordOps)(nil)
+
+// ToProto returns the record as an audit.AuditRecord
+func (a *AuditRecordObject) ToProto() *audit.AuditRecord {
+	record := &audit.AuditRecord{
+		RecordId:  a.RecordID.String(),
+		Time:      formatTime(a.RecordID.Time()),
+		User:      a.User,
+		Procedure: a.Procedure,
+		Request:   a.Request,
+		Outcome:   a.Outcome,
+		Message:   a.Message,
+	}
+	if len(a.Entities) != 0 {
+		record.Entities = strings.Split(a.Entities, _auditRecordEntitySeparator)
+	}
+	return record
+}
+
+// auditRecordDay returns the day partitioning the records at time t
+func auditRecordDay(t time.Time) string {
+	return t.UTC().Format(_auditRecordDayFormat)
+}
+
+// auditRecordOps implements AuditRecordOps using a particular Store
+type auditRecordOps struct {
+	store *Store
+}
+
+// NewAuditRecordOps constructs a AuditRecordOps object for provided Store.
+func NewAuditRecordOps(s *Store) AuditRecordOps {
+	return &auditRecordOps{store: s}
+}
+
+// Create creates a AuditRecordObject in db. The record time
+// defaults to the current time if it is not set.
+func (d *auditRecordOps) Create(
+	ctx context.Context,
+	record *audit.AuditRecord,
+) error {
+
+	recordTime := time.Now()
+	if len(record.GetTime()) != 0 {
+		t, err := time.Parse(time.RFC3339Nano, record.GetTime())
+		if err != nil {
+			d.store.metrics.OrmAuditMetrics.AuditRecordCreateFail.Inc(1)
+			return err
+		}
+		recordTime = t
+	}
+
+	obj := &AuditRecordObject{
+		Day:       auditRecordDay(recordTime),
+		RecordID:  gocql.UUIDFromTime(recordTime),
+		User:      record.GetUser(),
+		Procedure: record.GetProcedure(),
+		Entities: strings.Join(
+			record.GetEntities(), _auditRecordEntitySeparator),
+		Request: record.GetRequest(),
+		Outcome: record.GetOutcome(),
+		Message: record.GetMessage(),
+	}
+
+	if err := d.store.oClient.Create(ctx, obj); err != nil {
+		d.store.metrics.OrmAuditMetrics.AuditRecordCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmAuditMetrics.AuditRecordCreate.Inc(1)
+	return nil
+}
+
+// Query gets the AuditRecordObjects of the calls received in
+// [from, to) which match the filter from db, reading one day partition
+// at a time from the most recent one, until limit objects are found
+func (d *auditRecordOps) Query(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+	filter func(*AuditRecordObject) bool,
+	limit int,
+) ([]*AuditRecordObject, error) {
+
+	var resultObjs []*AuditRecordObject
+	lastDay := auditRecordDay(from)
+	for day := to; ; day = day.Add(-24 * time.Hour) {
+		objs, err := d.store.oClient.GetAll(
+			ctx,
+			&AuditRecordObject{Day: auditRecordDay(day)})
+		if err != nil {
+			d.store.metrics.OrmAuditMetrics.AuditRecordQueryFail.Inc(1)
+			return nil, err
+		}
+
+		for _, obj := range objs {
+			record := obj.(*AuditRecordObject)
+			recordTime := record.RecordID.Time()
+			if recordTime.Before(from) || !recordTime.Before(to) {
+				continue
+			}
+			if filter != nil && !filter(record) {
+				continue
+			}
+			resultObjs = append(resultObjs, record)
+			if limit > 0 && len(resultObjs) >= limit {
+				break
+			}
+		}
+
+		if limit > 0 && len(resultObjs) >= limit {
+			break
+		}
+		if auditRecordDay(day) <= lastDay {
+			break
+		}
+	}
+
+	d.store.metrics.OrmAuditMetrics.AuditRecordQuery.Inc(1)
+	return resultObjs, nil
+}
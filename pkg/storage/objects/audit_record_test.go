@@ -0,0 +1,160 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditRecordObjectTestSuite struct {
+	suite.Suite
+}
+
+func (s *AuditRecordObjectTestSuite) SetupTest() {
+}
+
+func TestAuditRecordObjectSuite(t *testing.T) {
+	suite.Run(t, new(AuditRecordObjectTestSuite))
+}
+
+// recordsOfUser returns the records of the objects made by the user
+func recordsOfUser(
+	objs []*AuditRecordObject,
+	user string) []*audit.AuditRecord {
+	var records []*audit.AuditRecord
+	for _, obj := range objs {
+		if obj.User == user {
+			records = append(records, obj.ToProto())
+		}
+	}
+	return records
+}
+
+// TestAuditRecordOps tests creating and querying AuditRecordObject
+// across day partitions
+func (s *AuditRecordObjectTestSuite) TestAuditRecordOps() {
+	db := NewAuditRecordOps(testStore)
+	ctx := context.Background()
+
+	// the records of other tests may be in the same partitions,
+	// so a unique user identifies the records of this test
+	user := "user-" + uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	records := []*audit.AuditRecord{
+		{
+			Time:      now.Add(-48 * time.Hour).Format(time.RFC3339),
+			User:      user,
+			Procedure: "peloton.api.v0.job.JobManager::Create",
+			Request:   `{"config":{"name":"test"}}`,
+			Outcome:   "ok",
+		},
+		{
+			Time:      now.Add(-24 * time.Hour).Format(time.RFC3339),
+			User:      user,
+			Procedure: "peloton.api.v0.job.JobManager::Delete",
+			Entities:  []string{"job:" + uuid.New()},
+			Outcome:   "not-found",
+			Message:   "job not found",
+		},
+		{
+			Time:      now.Format(time.RFC3339),
+			User:      user,
+			Procedure: "peloton.api.v0.host.svc.HostService::StartMaintenance",
+			Entities:  []string{"host:host1", "host:host2"},
+			Outcome:   "ok",
+		},
+	}
+	for _, record := range records {
+		s.NoError(db.Create(ctx, record))
+	}
+
+	objs, err := db.Query(
+		ctx, now.Add(-72*time.Hour), now.Add(time.Minute), nil, 0)
+	s.NoError(err)
+	stored := recordsOfUser(objs, user)
+	s.Len(stored, len(records))
+
+	// records are sorted by descending time
+	for i, record := range stored {
+		expected := records[len(records)-1-i]
+		s.NotEmpty(record.GetRecordId())
+		s.Equal(expected.GetTime(), record.GetTime())
+		s.Equal(expected.GetProcedure(), record.GetProcedure())
+		s.Equal(expected.GetEntities(), record.GetEntities())
+		s.Equal(expected.GetRequest(), record.GetRequest())
+		s.Equal(expected.GetOutcome(), record.GetOutcome())
+		s.Equal(expected.GetMessage(), record.GetMessage())
+	}
+
+	// only the records in the time range are returned
+	objs, err = db.Query(ctx, now.Add(-30*time.Hour), now, nil, 0)
+	s.NoError(err)
+	stored = recordsOfUser(objs, user)
+	s.Len(stored, 1)
+	s.Equal(records[1].GetTime(), stored[0].GetTime())
+
+	// only the most recent records matching the filter
+	// are returned, up to the limit
+	ofUser := func(obj *AuditRecordObject) bool {
+		return obj.User == user
+	}
+	objs, err = db.Query(
+		ctx, now.Add(-72*time.Hour), now.Add(time.Minute), ofUser, 2)
+	s.NoError(err)
+	s.Len(objs, 2)
+	s.Equal(records[2].GetTime(), objs[0].ToProto().GetTime())
+	s.Equal(records[1].GetTime(), objs[1].ToProto().GetTime())
+}
+
+// TestAuditRecordOpsClientFail tests failure cases due to ORM Client errors
+func (s *AuditRecordObjectTestSuite) TestAuditRecordOpsClientFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	db := NewAuditRecordOps(mockStore)
+
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+
+	ctx := context.Background()
+
+	err := db.Create(ctx, &audit.AuditRecord{})
+	s.Error(err)
+	s.Equal("create failed", err.Error())
+
+	// invalid record time fails before reaching the client
+	err = db.Create(ctx, &audit.AuditRecord{Time: "invalid"})
+	s.Error(err)
+
+	now := time.Now()
+	_, err = db.Query(ctx, now.Add(-time.Hour), now, nil, 0)
+	s.Error(err)
+	s.Equal("getall failed", err.Error())
+}
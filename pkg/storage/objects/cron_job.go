@@ -0,0 +1,282 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// all the cron jobs are stored in a single shard of the cron_jobs table,
+// so that they can be read with a single query
+const _cronJobShardID = 0
+
+// init adds a CronJobObject instance to the global list of storage objects
+func init() {
+	Objs = append(Objs, &CronJobObject{})
+}
+
+// CronJobObject corresponds to a row in cron_jobs table.
+type CronJobObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=cron_jobs, primaryKey=((shard_id), name)"`
+
+	// Shard of the cron job
+	ShardID int `column:"name=shard_id"`
+	// Name of the cron job
+	Name string `column:"name=name"`
+	// Spec of the cron job
+	Spec []byte `column:"name=spec"`
+	// Time at which the last run of the cron job was scheduled
+	LastScheduledTime time.Time `column:"name=last_scheduled_time"`
+	// JobID of the batch job created by the last run of the cron job
+	LastRunJobID string `column:"name=last_run_job_id"`
+	// Creation time of the cron job
+	CreationTime time.Time `column:"name=creation_time"`
+	// Time when the cron job was updated
+	UpdateTime time.Time `column:"name=update_time"`
+	// Name of the user who created the cron job
+	CreatedBy string `column:"name=created_by"`
+}
+
+// CronJobOps provides methods for manipulating cron_jobs table.
+type CronJobOps interface {
+	// Create inserts a row in the table if a cron job
+	// with the same name does not exist yet.
+	Create(ctx context.Context, spec *cron.CronJobSpec, createdBy string) error
+
+	// Get retrieves a row from the table.
+	Get(ctx context.Context, name string) (*CronJobObject, error)
+
+	// GetAll retrieves all the rows from the table.
+	GetAll(ctx context.Context) ([]*CronJobObject, error)
+
+	// UpdateSpec modifies the spec of a cron job in the table.
+	// Returns a not-found error if the cron job does not exist.
+	UpdateSpec(ctx context.Context, spec *cron.CronJobSpec) error
+
+	// UpdateLastRun modifies the last run of a cron job in the table.
+	// Returns a not-found error if the cron job does not exist.
+	UpdateLastRun(
+		ctx context.Context,
+		name string,
+		scheduledTime time.Time,
+		jobID *peloton.JobID,
+	) error
+
+	// Delete removes a row from the table.
+	Delete(ctx context.Context, name string) error
+}
+
+// ensure that default implementation (cronJobOps) satisfies the interface
+var _ CronJobOps = (*cronJobOps)(nil)
+
+// GetSpec returns the unmarshaled spec of the cron job
+func (c *CronJobObject) GetSpec() (*cron.CronJobSpec, error) {
+	spec := &cron.CronJobSpec{}
+	if err := proto.Unmarshal(c.Spec, spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// GetStatus returns the status of the cron job. The next scheduled time
+// is not stored, and needs to be computed from the schedule by the caller.
+func (c *CronJobObject) GetStatus() *cron.CronJobStatus {
+	status := &cron.CronJobStatus{
+		CreationTime:      formatTime(c.CreationTime),
+		UpdateTime:        formatTime(c.UpdateTime),
+		LastScheduledTime: formatTime(c.LastScheduledTime),
+	}
+	if len(c.LastRunJobID) != 0 {
+		status.LastRunJobId = &peloton.JobID{Value: c.LastRunJobID}
+	}
+	return status
+}
+
+// formatTime returns the time in RFC3339 form with UTC timezone,
+// and an empty string for an unset time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// cronJobOps implements CronJobOps using a particular Store
+type cronJobOps struct {
+	store *Store
+}
+
+// NewCronJobOps constructs a CronJobOps object for provided Store.
+func NewCronJobOps(s *Store) CronJobOps {
+	return &cronJobOps{store: s}
+}
+
+// Create creates a CronJobObject in db
+func (d *cronJobOps) Create(
+	ctx context.Context,
+	spec *cron.CronJobSpec,
+	createdBy string,
+) error {
+
+	specBuffer, err := proto.Marshal(spec)
+	if err != nil {
+		d.store.metrics.OrmJobMetrics.CronJobCreateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal cron job spec")
+	}
+
+	now := time.Now().UTC()
+	obj := &CronJobObject{
+		ShardID:      _cronJobShardID,
+		Name:         spec.GetName(),
+		Spec:         specBuffer,
+		CreationTime: now,
+		UpdateTime:   now,
+		CreatedBy:    createdBy,
+	}
+
+	if err := d.store.oClient.CreateIfNotExists(ctx, obj); err != nil {
+		d.store.metrics.OrmJobMetrics.CronJobCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmJobMetrics.CronJobCreate.Inc(1)
+	return nil
+}
+
+// Get gets a CronJobObject from db
+func (d *cronJobOps) Get(
+	ctx context.Context,
+	name string,
+) (*CronJobObject, error) {
+
+	obj := &CronJobObject{
+		ShardID: _cronJobShardID,
+		Name:    name,
+	}
+
+	if err := d.store.oClient.Get(ctx, obj); err != nil {
+		d.store.metrics.OrmJobMetrics.CronJobGetFail.Inc(1)
+		return nil, err
+	}
+
+	d.store.metrics.OrmJobMetrics.CronJobGet.Inc(1)
+	return obj, nil
+}
+
+// GetAll gets all the CronJobObjects from db
+func (d *cronJobOps) GetAll(
+	ctx context.Context,
+) ([]*CronJobObject, error) {
+
+	objs, err := d.store.oClient.GetAll(
+		ctx,
+		&CronJobObject{ShardID: _cronJobShardID})
+	if err != nil {
+		d.store.metrics.OrmJobMetrics.CronJobGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	resultObjs := make([]*CronJobObject, 0, len(objs))
+	for _, obj := range objs {
+		resultObjs = append(resultObjs, obj.(*CronJobObject))
+	}
+
+	d.store.metrics.OrmJobMetrics.CronJobGetAll.Inc(1)
+	return resultObjs, nil
+}
+
+// UpdateSpec updates the spec of a CronJobObject in db
+func (d *cronJobOps) UpdateSpec(
+	ctx context.Context,
+	spec *cron.CronJobSpec,
+) error {
+
+	specBuffer, err := proto.Marshal(spec)
+	if err != nil {
+		d.store.metrics.OrmJobMetrics.CronJobUpdateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal cron job spec")
+	}
+
+	obj := &CronJobObject{
+		ShardID:    _cronJobShardID,
+		Name:       spec.GetName(),
+		Spec:       specBuffer,
+		UpdateTime: time.Now().UTC(),
+	}
+
+	// only update the cron job if it exists, so that a deleted
+	// cron job is not recreated
+	if err := d.store.oClient.UpdateIf(
+		ctx, obj, nil, "Spec", "UpdateTime"); err != nil {
+		d.store.metrics.OrmJobMetrics.CronJobUpdateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmJobMetrics.CronJobUpdate.Inc(1)
+	return nil
+}
+
+// UpdateLastRun updates the last run of a CronJobObject in db
+func (d *cronJobOps) UpdateLastRun(
+	ctx context.Context,
+	name string,
+	scheduledTime time.Time,
+	jobID *peloton.JobID,
+) error {
+
+	obj := &CronJobObject{
+		ShardID:           _cronJobShardID,
+		Name:              name,
+		LastScheduledTime: scheduledTime.UTC(),
+		LastRunJobID:      jobID.GetValue(),
+	}
+
+	// a run may complete after its cron job was deleted,
+	// which must not recreate the cron job
+	if err := d.store.oClient.UpdateIf(
+		ctx, obj, nil, "LastScheduledTime", "LastRunJobID"); err != nil {
+		d.store.metrics.OrmJobMetrics.CronJobUpdateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmJobMetrics.CronJobUpdate.Inc(1)
+	return nil
+}
+
+// Delete deletes a CronJobObject from db
+func (d *cronJobOps) Delete(ctx context.Context, name string) error {
+	obj := &CronJobObject{
+		ShardID: _cronJobShardID,
+		Name:    name,
+	}
+
+	if err := d.store.oClient.Delete(ctx, obj); err != nil {
+		d.store.metrics.OrmJobMetrics.CronJobDeleteFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmJobMetrics.CronJobDelete.Inc(1)
+	return nil
+}
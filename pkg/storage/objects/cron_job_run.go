@@ -0,0 +1,169 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gocql/gocql"
+)
+
+// init adds a CronJobRunObject instance to the global list of storage objects
+func init() {
+	Objs = append(Objs, &CronJobRunObject{})
+}
+
+// CronJobRunObject corresponds to a row in cron_job_runs table.
+type CronJobRunObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=cron_job_runs, primaryKey=((name), run_id)"`
+
+	// Name of the cron job
+	Name string `column:"name=name"`
+	// RunID of the run, based on the time at which the run was processed
+	RunID gocql.UUID `column:"name=run_id"`
+	// JobID of the batch job created for the run
+	JobID string `column:"name=job_id"`
+	// State of the run
+	State string `column:"name=state"`
+	// Time at which the run was scheduled
+	ScheduledTime time.Time `column:"name=scheduled_time"`
+	// Whether the run was started on demand
+	Manual bool `column:"name=manual"`
+	// Message explaining the outcome of the run
+	Message string `column:"name=message"`
+}
+
+// CronJobRunOps provides methods for manipulating cron_job_runs table.
+type CronJobRunOps interface {
+	// Create inserts a row in the table.
+	Create(ctx context.Context, name string, run *cron.CronJobRun) error
+
+	// GetAll retrieves all the rows of a cron job from the table,
+	// sorted by descending run time.
+	GetAll(ctx context.Context, name string) ([]*CronJobRunObject, error)
+
+	// Delete removes a row from the table.
+	Delete(ctx context.Context, name string, runID gocql.UUID) error
+}
+
+// ensure that default implementation (cronJobRunOps) satisfies the interface
+var _ CronJobRunOps = (*cronJobRunOps)(nil)
+
+// ToProto returns the run as a cron.CronJobRun
+func (c *CronJobRunObject) ToProto() *cron.CronJobRun {
+	run := &cron.CronJobRun{
+		State:         cron.CronJobRunState(cron.CronJobRunState_value[c.State]),
+		ScheduledTime: formatTime(c.ScheduledTime),
+		RunTime:       formatTime(c.RunID.Time()),
+		Manual:        c.Manual,
+		Message:       c.Message,
+	}
+	if len(c.JobID) != 0 {
+		run.JobId = &peloton.JobID{Value: c.JobID}
+	}
+	return run
+}
+
+// cronJobRunOps implements CronJobRunOps using a particular Store
+type cronJobRunOps struct {
+	store *Store
+}
+
+// NewCronJobRunOps constructs a CronJobRunOps object for provided Store.
+func NewCronJobRunOps(s *Store) CronJobRunOps {
+	return &cronJobRunOps{store: s}
+}
+
+// Create creates a CronJobRunObject in db
+func (d *cronJobRunOps) Create(
+	ctx context.Context,
+	name string,
+	run *cron.CronJobRun,
+) error {
+
+	obj := &CronJobRunObject{
+		Name:    name,
+		RunID:   gocql.UUIDFromTime(time.Now()),
+		JobID:   run.GetJobId().GetValue(),
+		State:   run.GetState().String(),
+		Manual:  run.GetManual(),
+		Message: run.GetMessage(),
+	}
+	if len(run.GetScheduledTime()) != 0 {
+		scheduledTime, err := time.Parse(time.RFC3339, run.GetScheduledTime())
+		if err != nil {
+			d.store.metrics.OrmJobMetrics.CronJobRunCreateFail.Inc(1)
+			return err
+		}
+		obj.ScheduledTime = scheduledTime
+	}
+
+	if err := d.store.oClient.Create(ctx, obj); err != nil {
+		d.store.metrics.OrmJobMetrics.CronJobRunCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmJobMetrics.CronJobRunCreate.Inc(1)
+	return nil
+}
+
+// GetAll gets all the CronJobRunObjects of a cron job from db
+func (d *cronJobRunOps) GetAll(
+	ctx context.Context,
+	name string,
+) ([]*CronJobRunObject, error) {
+
+	objs, err := d.store.oClient.GetAll(ctx, &CronJobRunObject{Name: name})
+	if err != nil {
+		d.store.metrics.OrmJobMetrics.CronJobRunGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	resultObjs := make([]*CronJobRunObject, 0, len(objs))
+	for _, obj := range objs {
+		resultObjs = append(resultObjs, obj.(*CronJobRunObject))
+	}
+
+	d.store.metrics.OrmJobMetrics.CronJobRunGetAll.Inc(1)
+	return resultObjs, nil
+}
+
+// Delete deletes a CronJobRunObject from db
+func (d *cronJobRunOps) Delete(
+	ctx context.Context,
+	name string,
+	runID gocql.UUID,
+) error {
+
+	obj := &CronJobRunObject{
+		Name:  name,
+		RunID: runID,
+	}
+
+	if err := d.store.oClient.Delete(ctx, obj); err != nil {
+		d.store.metrics.OrmJobMetrics.CronJobRunDeleteFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmJobMetrics.CronJobRunDelete.Inc(1)
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type CronJobRunObjectTestSuite struct {
+	suite.Suite
+}
+
+func (s *CronJobRunObjectTestSuite) SetupTest() {
+}
+
+func TestCronJobRunObjectSuite(t *testing.T) {
+	suite.Run(t, new(CronJobRunObjectTestSuite))
+}
+
+// TestCronJobRunOps tests creating, listing and deleting CronJobRunObject
+func (s *CronJobRunObjectTestSuite) TestCronJobRunOps() {
+	db := NewCronJobRunOps(testStore)
+	ctx := context.Background()
+
+	name := "cron-" + uuid.New()
+	jobID := &peloton.JobID{Value: uuid.New()}
+
+	runs := []*cron.CronJobRun{
+		{
+			JobId:         jobID,
+			State:         cron.CronJobRunState_CRON_JOB_RUN_STATE_LAUNCHED,
+			ScheduledTime: "2019-03-04T05:00:00Z",
+		},
+		{
+			State:         cron.CronJobRunState_CRON_JOB_RUN_STATE_SKIPPED,
+			ScheduledTime: "2019-03-04T06:00:00Z",
+			Message:       "previous run is active",
+		},
+		{
+			State:   cron.CronJobRunState_CRON_JOB_RUN_STATE_FAILED,
+			Manual:  true,
+			Message: "failed to create job",
+		},
+	}
+	for _, run := range runs {
+		s.NoError(db.Create(ctx, name, run))
+	}
+
+	objs, err := db.GetAll(ctx, name)
+	s.NoError(err)
+	s.Len(objs, len(runs))
+
+	// runs are sorted by descending run time
+	for i, obj := range objs {
+		run := obj.ToProto()
+		expected := runs[len(runs)-1-i]
+		s.Equal(expected.GetJobId().GetValue(), run.GetJobId().GetValue())
+		s.Equal(expected.GetState(), run.GetState())
+		s.Equal(expected.GetScheduledTime(), run.GetScheduledTime())
+		s.Equal(expected.GetManual(), run.GetManual())
+		s.Equal(expected.GetMessage(), run.GetMessage())
+		s.NotEmpty(run.GetRunTime())
+	}
+
+	for _, obj := range objs {
+		s.NoError(db.Delete(ctx, name, obj.RunID))
+	}
+
+	objs, err = db.GetAll(ctx, name)
+	s.NoError(err)
+	s.Empty(objs)
+}
+
+// TestCronJobRunOpsClientFail tests failure cases due to ORM Client errors
+func (s *CronJobRunObjectTestSuite) TestCronJobRunOpsClientFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	db := NewCronJobRunOps(mockStore)
+
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).
+		Return(errors.New("delete failed"))
+
+	ctx := context.Background()
+
+	err := db.Create(ctx, "test", &cron.CronJobRun{})
+	s.Error(err)
+	s.Equal("create failed", err.Error())
+
+	// invalid scheduled time fails before reaching the client
+	err = db.Create(ctx, "test", &cron.CronJobRun{ScheduledTime: "invalid"})
+	s.Error(err)
+
+	_, err = db.GetAll(ctx, "test")
+	s.Error(err)
+	s.Equal("getall failed", err.Error())
+
+	err = db.Delete(ctx, "test", gocql.TimeUUID())
+	s.Error(err)
+	s.Equal("delete failed", err.Error())
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type CronJobObjectTestSuite struct {
+	suite.Suite
+}
+
+func (s *CronJobObjectTestSuite) SetupTest() {
+}
+
+func TestCronJobObjectSuite(t *testing.T) {
+	suite.Run(t, new(CronJobObjectTestSuite))
+}
+
+// TestCronJobOps tests CronJobObject CRUD operations
+func (s *CronJobObjectTestSuite) TestCronJobOps() {
+	db := NewCronJobOps(testStore)
+	ctx := context.Background()
+
+	name := "cron-" + uuid.New()
+	spec := &cron.CronJobSpec{
+		Name:            name,
+		Schedule:        "*/5 * * * *",
+		CollisionPolicy: cron.CollisionPolicy_COLLISION_POLICY_SKIP_NEW,
+		JobSpec: &stateless.JobSpec{
+			Name:          name,
+			InstanceCount: 2,
+		},
+	}
+
+	// CREATE and GET ops
+	s.NoError(db.Create(ctx, spec, "user1"))
+
+	err := db.Create(ctx, spec, "user1")
+	s.Error(err)
+	s.True(yarpcerrors.IsAlreadyExists(err))
+
+	obj, err := db.Get(ctx, name)
+	s.NoError(err)
+	s.Equal(name, obj.Name)
+	s.Equal("user1", obj.CreatedBy)
+	s.False(obj.CreationTime.IsZero())
+	s.True(obj.LastScheduledTime.IsZero())
+
+	storedSpec, err := obj.GetSpec()
+	s.NoError(err)
+	s.Equal(spec, storedSpec)
+	s.Nil(obj.GetStatus().GetLastRunJobId())
+	s.Empty(obj.GetStatus().GetLastScheduledTime())
+
+	objs, err := db.GetAll(ctx)
+	s.NoError(err)
+	found := false
+	for _, o := range objs {
+		if o.Name == name {
+			found = true
+		}
+	}
+	s.True(found)
+
+	// UPDATE ops
+	spec.Schedule = "@hourly"
+	s.NoError(db.UpdateSpec(ctx, spec))
+
+	jobID := &peloton.JobID{Value: uuid.New()}
+	scheduledTime := time.Date(2019, 3, 4, 5, 0, 0, 0, time.UTC)
+	s.NoError(db.UpdateLastRun(ctx, name, scheduledTime, jobID))
+
+	obj, err = db.Get(ctx, name)
+	s.NoError(err)
+	storedSpec, err = obj.GetSpec()
+	s.NoError(err)
+	s.Equal("@hourly", storedSpec.GetSchedule())
+	s.True(scheduledTime.Equal(obj.LastScheduledTime))
+	s.Equal(jobID.GetValue(), obj.GetStatus().GetLastRunJobId().GetValue())
+	s.Equal("2019-03-04T05:00:00Z", obj.GetStatus().GetLastScheduledTime())
+
+	// DELETE ops
+	s.NoError(db.Delete(ctx, name))
+
+	_, err = db.Get(ctx, name)
+	s.Equal(gocql.ErrNotFound, err)
+
+	// the updates of a deleted cron job do not recreate it
+	err = db.UpdateSpec(ctx, spec)
+	s.True(yarpcerrors.IsNotFound(err))
+
+	err = db.UpdateLastRun(ctx, name, scheduledTime, jobID)
+	s.True(yarpcerrors.IsNotFound(err))
+
+	_, err = db.Get(ctx, name)
+	s.Equal(gocql.ErrNotFound, err)
+}
+
+// TestCronJobOpsClientFail tests failure cases due to ORM Client errors
+func (s *CronJobObjectTestSuite) TestCronJobOpsClientFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	db := NewCronJobOps(mockStore)
+
+	mockClient.EXPECT().CreateIfNotExists(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(errors.New("get failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+	mockClient.EXPECT().
+		UpdateIf(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errors.New("update failed")).Times(2)
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).
+		Return(errors.New("delete failed"))
+
+	ctx := context.Background()
+	spec := &cron.CronJobSpec{Name: "test"}
+
+	err := db.Create(ctx, spec, "user1")
+	s.Error(err)
+	s.Equal("create failed", err.Error())
+
+	_, err = db.Get(ctx, "test")
+	s.Error(err)
+	s.Equal("get failed", err.Error())
+
+	_, err = db.GetAll(ctx)
+	s.Error(err)
+	s.Equal("getall failed", err.Error())
+
+	err = db.UpdateSpec(ctx, spec)
+	s.Error(err)
+	s.Equal("update failed", err.Error())
+
+	err = db.UpdateLastRun(
+		ctx, "test", time.Now(), &peloton.JobID{Value: uuid.New()})
+	s.Error(err)
+	s.Equal("update failed", err.Error())
+
+	err = db.Delete(ctx, "test")
+	s.Error(err)
+	s.Equal("delete failed", err.Error())
+}
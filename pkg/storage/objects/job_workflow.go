@@ -0,0 +1,396 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// the workflows are spread over the shards of the job_workflows table
+// by the hash of their IDs, and the running workflows are indexed in
+// the same shards of the running_job_workflows table
+const _jobWorkflowShards = 32
+
+// init adds JobWorkflowObject and RunningJobWorkflowObject instances
+// to the global list of storage objects
+func init() {
+	Objs = append(Objs, &JobWorkflowObject{})
+	Objs = append(Objs, &RunningJobWorkflowObject{})
+}
+
+// JobWorkflowObject corresponds to a row in job_workflows table.
+type JobWorkflowObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=job_workflows, primaryKey=((shard_id), workflow_id)"`
+
+	// Shard of the workflow
+	ShardID int `column:"name=shard_id"`
+	// ID of the workflow
+	WorkflowID string `column:"name=workflow_id"`
+	// Name of the workflow
+	Name string `column:"name=name"`
+	// Spec of the workflow
+	Spec []byte `column:"name=spec"`
+	// Status of the workflow and its nodes
+	Status []byte `column:"name=status"`
+	// Creation time of the workflow
+	CreationTime time.Time `column:"name=creation_time"`
+	// Time when the status of the workflow was updated
+	UpdateTime time.Time `column:"name=update_time"`
+	// Name of the user who created the workflow
+	CreatedBy string `column:"name=created_by"`
+}
+
+// RunningJobWorkflowObject corresponds to a row in
+// running_job_workflows table.
+type RunningJobWorkflowObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=running_job_workflows, primaryKey=((shard_id), workflow_id)"`
+
+	// Shard of the workflow
+	ShardID int `column:"name=shard_id"`
+	// ID of the workflow
+	WorkflowID string `column:"name=workflow_id"`
+}
+
+// JobWorkflowOps provides methods for manipulating job_workflows table.
+type JobWorkflowOps interface {
+	// Create inserts a row in the table, and indexes
+	// the workflow if it is running.
+	Create(
+		ctx context.Context,
+		id *workflow.WorkflowID,
+		spec *workflow.WorkflowSpec,
+		status *workflow.WorkflowStatus,
+		createdBy string,
+	) error
+
+	// Get retrieves a row from the table.
+	Get(ctx context.Context, id *workflow.WorkflowID) (*JobWorkflowObject, error)
+
+	// GetAll retrieves all the rows from all the shards of the table.
+	GetAll(ctx context.Context) ([]*JobWorkflowObject, error)
+
+	// GetRunning retrieves the IDs of the running workflows.
+	GetRunning(ctx context.Context) ([]*workflow.WorkflowID, error)
+
+	// UpdateStatus modifies the status of a workflow in the table,
+	// and indexes the workflow only while it is running.
+	UpdateStatus(
+		ctx context.Context,
+		id *workflow.WorkflowID,
+		status *workflow.WorkflowStatus,
+	) error
+
+	// Delete removes a workflow from the table and from the index.
+	Delete(ctx context.Context, id *workflow.WorkflowID) error
+}
+
+// ensure that default implementation (jobWorkflowOps) satisfies the interface
+var _ JobWorkflowOps = (*jobWorkflowOps)(nil)
+
+// GetSpec returns the unmarshaled spec of the workflow
+func (w *JobWorkflowObject) GetSpec() (*workflow.WorkflowSpec, error) {
+	spec := &workflow.WorkflowSpec{}
+	if err := proto.Unmarshal(w.Spec, spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// GetStatus returns the unmarshaled status of the workflow,
+// with its creation and update times
+func (w *JobWorkflowObject) GetStatus() (*workflow.WorkflowStatus, error) {
+	status := &workflow.WorkflowStatus{}
+	if err := proto.Unmarshal(w.Status, status); err != nil {
+		return nil, err
+	}
+	status.CreationTime = formatTime(w.CreationTime)
+	status.UpdateTime = formatTime(w.UpdateTime)
+	return status, nil
+}
+
+// ToProto returns the workflow info of the workflow
+func (w *JobWorkflowObject) ToProto() (*workflow.WorkflowInfo, error) {
+	spec, err := w.GetSpec()
+	if err != nil {
+		return nil, err
+	}
+	status, err := w.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	return &workflow.WorkflowInfo{
+		WorkflowId: &workflow.WorkflowID{Value: w.WorkflowID},
+		Spec:       spec,
+		Status:     status,
+	}, nil
+}
+
+// marshalJobWorkflowStatus marshals the status of a workflow, without
+// its creation and update times which are stored in their own columns
+func marshalJobWorkflowStatus(status *workflow.WorkflowStatus) ([]byte, error) {
+	status = proto.Clone(status).(*workflow.WorkflowStatus)
+	status.CreationTime = ""
+	status.UpdateTime = ""
+	return proto.Marshal(status)
+}
+
+// jobWorkflowShardID returns the shard of a workflow
+func jobWorkflowShardID(id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() % _jobWorkflowShards)
+}
+
+// jobWorkflowOps implements JobWorkflowOps using a particular Store
+type jobWorkflowOps struct {
+	store *Store
+}
+
+// NewJobWorkflowOps constructs a JobWorkflowOps object for provided Store.
+func NewJobWorkflowOps(s *Store) JobWorkflowOps {
+	return &jobWorkflowOps{store: s}
+}
+
+// Create creates a JobWorkflowObject in db
+func (d *jobWorkflowOps) Create(
+	ctx context.Context,
+	id *workflow.WorkflowID,
+	spec *workflow.WorkflowSpec,
+	status *workflow.WorkflowStatus,
+	createdBy string,
+) error {
+
+	specBuffer, err := proto.Marshal(spec)
+	if err != nil {
+		d.store.metrics.OrmJobMetrics.JobWorkflowCreateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal workflow spec")
+	}
+
+	statusBuffer, err := marshalJobWorkflowStatus(status)
+	if err != nil {
+		d.store.metrics.OrmJobMetrics.JobWorkflowCreateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal workflow status")
+	}
+
+	// the workflow is indexed first, so that a running workflow is
+	// never missing from the index. An index entry without workflow
+	// is removed by the workflow controller.
+	if err := d.index(ctx, id, status); err != nil {
+		d.store.metrics.OrmJobMetrics.JobWorkflowCreateFail.Inc(1)
+		return err
+	}
+
+	now := time.Now().UTC()
+	obj := &JobWorkflowObject{
+		ShardID:      jobWorkflowShardID(id.GetValue()),
+		WorkflowID:   id.GetValue(),
+		Name:         spec.GetName(),
+		Spec:         specBuffer,
+		Status:       statusBuffer,
+		CreationTime: now,
+		UpdateTime:   now,
+		CreatedBy:    createdBy,
+	}
+
+	if err := d.store.oClient.CreateIfNotExists(ctx, obj); err != nil {
+		d.store.metrics.OrmJobMetrics.JobWorkflowCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmJobMetrics.JobWorkflowCreate.Inc(1)
+	return nil
+}
+
+// Get gets a JobWorkflowObject from db
+func (d *jobWorkflowOps) Get(
+	ctx context.Context,
+	id *workflow.WorkflowID,
+) (*JobWorkflowObject, error) {
+
+	obj := &JobWorkflowObject{
+		ShardID:    jobWorkflowShardID(id.GetValue()),
+		WorkflowID: id.GetValue(),
+	}
+
+	if err := d.store.oClient.Get(ctx, obj); err != nil {
+		d.store.metrics.OrmJobMetrics.JobWorkflowGetFail.Inc(1)
+		return nil, err
+	}
+
+	d.store.metrics.OrmJobMetrics.JobWorkflowGet.Inc(1)
+	return obj, nil
+}
+
+// GetAll gets all the JobWorkflowObjects from db
+func (d *jobWorkflowOps) GetAll(
+	ctx context.Context,
+) ([]*JobWorkflowObject, error) {
+
+	var resultObjs []*JobWorkflowObject
+	for shardID := 0; shardID < _jobWorkflowShards; shardID++ {
+		objs, err := d.store.oClient.GetAll(
+			ctx,
+			&JobWorkflowObject{ShardID: shardID})
+		if err != nil {
+			d.store.metrics.OrmJobMetrics.JobWorkflowGetAllFail.Inc(1)
+			return nil, err
+		}
+
+		for _, obj := range objs {
+			resultObjs = append(resultObjs, obj.(*JobWorkflowObject))
+		}
+	}
+
+	d.store.metrics.OrmJobMetrics.JobWorkflowGetAll.Inc(1)
+	return resultObjs, nil
+}
+
+// GetRunning gets the IDs of the running workflows from db
+func (d *jobWorkflowOps) GetRunning(
+	ctx context.Context,
+) ([]*workflow.WorkflowID, error) {
+
+	var ids []*workflow.WorkflowID
+	for shardID := 0; shardID < _jobWorkflowShards; shardID++ {
+		objs, err := d.store.oClient.GetAll(
+			ctx,
+			&RunningJobWorkflowObject{ShardID: shardID})
+		if err != nil {
+			d.store.metrics.OrmJobMetrics.JobWorkflowGetRunningFail.Inc(1)
+			return nil, err
+		}
+
+		for _, obj := range objs {
+			ids = append(ids, &workflow.WorkflowID{
+				Value: obj.(*RunningJobWorkflowObject).WorkflowID,
+			})
+		}
+	}
+
+	d.store.metrics.OrmJobMetrics.JobWorkflowGetRunning.Inc(1)
+	return ids, nil
+}
+
+// UpdateStatus updates the status of a JobWorkflowObject in db
+func (d *jobWorkflowOps) UpdateStatus(
+	ctx context.Context,
+	id *workflow.WorkflowID,
+	status *workflow.WorkflowStatus,
+) error {
+
+	statusBuffer, err := marshalJobWorkflowStatus(status)
+	if err != nil {
+		d.store.metrics.OrmJobMetrics.JobWorkflowUpdateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal workflow status")
+	}
+
+	// a workflow which runs again is indexed before its status is
+	// updated, and a completed workflow is removed from the index
+	// after, so that a running workflow is never missing from the index
+	running := status.GetState() == workflow.WorkflowState_WORKFLOW_STATE_RUNNING
+	if running {
+		if err := d.index(ctx, id, status); err != nil {
+			d.store.metrics.OrmJobMetrics.JobWorkflowUpdateFail.Inc(1)
+			return err
+		}
+	}
+
+	obj := &JobWorkflowObject{
+		ShardID:    jobWorkflowShardID(id.GetValue()),
+		WorkflowID: id.GetValue(),
+		Status:     statusBuffer,
+		UpdateTime: time.Now().UTC(),
+	}
+
+	if err := d.store.oClient.Update(
+		ctx, obj, "Status", "UpdateTime"); err != nil {
+		d.store.metrics.OrmJobMetrics.JobWorkflowUpdateFail.Inc(1)
+		return err
+	}
+
+	if !running {
+		if err := d.unindex(ctx, id); err != nil {
+			d.store.metrics.OrmJobMetrics.JobWorkflowUpdateFail.Inc(1)
+			return err
+		}
+	}
+
+	d.store.metrics.OrmJobMetrics.JobWorkflowUpdate.Inc(1)
+	return nil
+}
+
+// Delete deletes a JobWorkflowObject from db, and removes
+// the workflow from the running workflows
+func (d *jobWorkflowOps) Delete(
+	ctx context.Context,
+	id *workflow.WorkflowID,
+) error {
+
+	if err := d.unindex(ctx, id); err != nil {
+		d.store.metrics.OrmJobMetrics.JobWorkflowDeleteFail.Inc(1)
+		return err
+	}
+
+	obj := &JobWorkflowObject{
+		ShardID:    jobWorkflowShardID(id.GetValue()),
+		WorkflowID: id.GetValue(),
+	}
+
+	if err := d.store.oClient.Delete(ctx, obj); err != nil {
+		d.store.metrics.OrmJobMetrics.JobWorkflowDeleteFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmJobMetrics.JobWorkflowDelete.Inc(1)
+	return nil
+}
+
+// index adds a running workflow to the running workflows
+func (d *jobWorkflowOps) index(
+	ctx context.Context,
+	id *workflow.WorkflowID,
+	status *workflow.WorkflowStatus,
+) error {
+	if status.GetState() != workflow.WorkflowState_WORKFLOW_STATE_RUNNING {
+		return nil
+	}
+
+	return d.store.oClient.Create(ctx, &RunningJobWorkflowObject{
+		ShardID:    jobWorkflowShardID(id.GetValue()),
+		WorkflowID: id.GetValue(),
+	})
+}
+
+// unindex removes a workflow from the running workflows
+func (d *jobWorkflowOps) unindex(
+	ctx context.Context,
+	id *workflow.WorkflowID,
+) error {
+	return d.store.oClient.Delete(ctx, &RunningJobWorkflowObject{
+		ShardID:    jobWorkflowShardID(id.GetValue()),
+		WorkflowID: id.GetValue(),
+	})
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/workflow"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type JobWorkflowObjectTestSuite struct {
+	suite.Suite
+}
+
+func (s *JobWorkflowObjectTestSuite) SetupTest() {
+}
+
+func TestJobWorkflowObjectSuite(t *testing.T) {
+	suite.Run(t, new(JobWorkflowObjectTestSuite))
+}
+
+// TestJobWorkflowOps tests JobWorkflowObject CRUD operations
+func (s *JobWorkflowObjectTestSuite) TestJobWorkflowOps() {
+	db := NewJobWorkflowOps(testStore)
+	ctx := context.Background()
+
+	id := &workflow.WorkflowID{Value: uuid.New()}
+	spec := &workflow.WorkflowSpec{
+		Name: "etl",
+		Nodes: []*workflow.NodeSpec{
+			{
+				Name:    "extract",
+				JobSpec: &stateless.JobSpec{InstanceCount: 2},
+			},
+			{
+				Name:    "load",
+				JobSpec: &stateless.JobSpec{InstanceCount: 1},
+				Dependencies: []*workflow.Dependency{
+					{
+						Node:      "extract",
+						Condition: workflow.DependencyCondition_DEPENDENCY_CONDITION_ON_SUCCESS,
+					},
+				},
+			},
+		},
+	}
+	status := &workflow.WorkflowStatus{
+		State: workflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+		Nodes: []*workflow.NodeStatus{
+			{Name: "extract", State: workflow.NodeState_NODE_STATE_PENDING},
+			{Name: "load", State: workflow.NodeState_NODE_STATE_PENDING},
+		},
+	}
+
+	// CREATE and GET ops
+	s.NoError(db.Create(ctx, id, spec, status, "user1"))
+
+	err := db.Create(ctx, id, spec, status, "user1")
+	s.Error(err)
+	s.True(yarpcerrors.IsAlreadyExists(err))
+
+	obj, err := db.Get(ctx, id)
+	s.NoError(err)
+	s.Equal("etl", obj.Name)
+	s.Equal("user1", obj.CreatedBy)
+	s.False(obj.CreationTime.IsZero())
+
+	info, err := obj.ToProto()
+	s.NoError(err)
+	s.Equal(id.GetValue(), info.GetWorkflowId().GetValue())
+	s.Equal(spec, info.GetSpec())
+	s.Equal(status.GetNodes(), info.GetStatus().GetNodes())
+	s.NotEmpty(info.GetStatus().GetCreationTime())
+
+	objs, err := db.GetAll(ctx)
+	s.NoError(err)
+	found := false
+	for _, o := range objs {
+		if o.WorkflowID == id.GetValue() {
+			found = true
+		}
+	}
+	s.True(found)
+	s.True(s.isRunning(db, id))
+
+	// UPDATE ops
+	status.Nodes[0].State = workflow.NodeState_NODE_STATE_RUNNING
+	status.Nodes[0].JobId = &peloton.JobID{Value: uuid.New()}
+	status.Nodes[0].Attempts = 1
+	s.NoError(db.UpdateStatus(ctx, id, status))
+
+	obj, err = db.Get(ctx, id)
+	s.NoError(err)
+	storedStatus, err := obj.GetStatus()
+	s.NoError(err)
+	s.Equal(status.GetNodes(), storedStatus.GetNodes())
+	s.True(s.isRunning(db, id))
+
+	// a completed workflow is no longer running
+	status.State = workflow.WorkflowState_WORKFLOW_STATE_FAILED
+	s.NoError(db.UpdateStatus(ctx, id, status))
+	s.False(s.isRunning(db, id))
+
+	// a retried workflow runs again
+	status.State = workflow.WorkflowState_WORKFLOW_STATE_RUNNING
+	s.NoError(db.UpdateStatus(ctx, id, status))
+	s.True(s.isRunning(db, id))
+
+	// DELETE ops
+	s.NoError(db.Delete(ctx, id))
+	s.False(s.isRunning(db, id))
+
+	_, err = db.Get(ctx, id)
+	s.Equal(gocql.ErrNotFound, err)
+
+	_, err = db.Get(ctx, &workflow.WorkflowID{Value: uuid.New()})
+	s.Equal(gocql.ErrNotFound, err)
+}
+
+// isRunning returns whether a workflow is indexed as running
+func (s *JobWorkflowObjectTestSuite) isRunning(
+	db JobWorkflowOps,
+	id *workflow.WorkflowID,
+) bool {
+	ids, err := db.GetRunning(context.Background())
+	s.NoError(err)
+	for _, runningID := range ids {
+		if runningID.GetValue() == id.GetValue() {
+			return true
+		}
+	}
+	return false
+}
+
+// TestJobWorkflowShardID tests that the workflows
+// are spread over all the shards
+func (s *JobWorkflowObjectTestSuite) TestJobWorkflowShardID() {
+	shards := make(map[int]bool)
+	for i := 0; i < 1000; i++ {
+		shardID := jobWorkflowShardID(uuid.New())
+		s.True(shardID >= 0 && shardID < _jobWorkflowShards)
+		shards[shardID] = true
+	}
+	s.Len(shards, _jobWorkflowShards)
+}
+
+// TestJobWorkflowOpsClientFail tests failure cases due to ORM Client errors
+func (s *JobWorkflowObjectTestSuite) TestJobWorkflowOpsClientFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	db := NewJobWorkflowOps(mockStore)
+
+	ctx := context.Background()
+	id := &workflow.WorkflowID{Value: uuid.New()}
+	spec := &workflow.WorkflowSpec{Name: "test"}
+	status := &workflow.WorkflowStatus{
+		State: workflow.WorkflowState_WORKFLOW_STATE_RUNNING,
+	}
+
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("index failed"))
+	err := db.Create(ctx, id, spec, status, "user1")
+	s.Error(err)
+	s.Equal("index failed", err.Error())
+
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	mockClient.EXPECT().CreateIfNotExists(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(errors.New("get failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed")).Times(2)
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errors.New("update failed"))
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).
+		Return(errors.New("delete failed"))
+
+	err = db.Create(ctx, id, spec, status, "user1")
+	s.Error(err)
+	s.Equal("create failed", err.Error())
+
+	_, err = db.Get(ctx, id)
+	s.Error(err)
+	s.Equal("get failed", err.Error())
+
+	_, err = db.GetAll(ctx)
+	s.Error(err)
+	s.Equal("getall failed", err.Error())
+
+	_, err = db.GetRunning(ctx)
+	s.Error(err)
+	s.Equal("getall failed", err.Error())
+
+	err = db.UpdateStatus(ctx, id, status)
+	s.Error(err)
+	s.Equal("update failed", err.Error())
+
+	err = db.Delete(ctx, id)
+	s.Error(err)
+	s.Equal("delete failed", err.Error())
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/host"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// all the maintenance windows are stored in a single shard of the
+// maintenance_windows table, so that they can be read with a single query
+const _maintenanceWindowShardID = 0
+
+// init adds a MaintenanceWindowObject instance to the global list of
+// storage objects
+func init() {
+	Objs = append(Objs, &MaintenanceWindowObject{})
+}
+
+// MaintenanceWindowObject corresponds to a row in maintenance_windows table.
+type MaintenanceWindowObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=maintenance_windows, primaryKey=((shard_id), window_id)"`
+
+	// Shard of the maintenance window
+	ShardID int `column:"name=shard_id"`
+	// ID of the maintenance window
+	WindowID string `column:"name=window_id"`
+	// The maintenance window
+	Window []byte `column:"name=window"`
+	// Creation time of the maintenance window
+	CreationTime time.Time `column:"name=creation_time"`
+}
+
+// MaintenanceWindowOps provides methods for manipulating
+// maintenance_windows table.
+type MaintenanceWindowOps interface {
+	// Create inserts a row in the table.
+	Create(ctx context.Context, window *host.MaintenanceWindow) error
+
+	// GetAll retrieves all the rows from the table.
+	GetAll(ctx context.Context) ([]*MaintenanceWindowObject, error)
+
+	// Delete removes a row from the table.
+	Delete(ctx context.Context, id string) error
+}
+
+// ensure that default implementation (maintenanceWindowOps) satisfies
+// the interface
+var _ MaintenanceWindowOps = (*maintenanceWindowOps)(nil)
+
+// GetWindow returns the unmarshaled maintenance window
+func (m *MaintenanceWindowObject) GetWindow() (*host.MaintenanceWindow, error) {
+	window := &host.MaintenanceWindow{}
+	if err := proto.Unmarshal(m.Window, window); err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+// maintenanceWindowOps implements MaintenanceWindowOps using a
+// particular Store
+type maintenanceWindowOps struct {
+	store *Store
+}
+
+// NewMaintenanceWindowOps constructs a MaintenanceWindowOps object for
+// provided Store.
+func NewMaintenanceWindowOps(s *Store) MaintenanceWindowOps {
+	return &maintenanceWindowOps{store: s}
+}
+
+// Create creates a MaintenanceWindowObject in db
+func (d *maintenanceWindowOps) Create(
+	ctx context.Context,
+	window *host.MaintenanceWindow,
+) error {
+
+	windowBuffer, err := proto.Marshal(window)
+	if err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowCreateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal maintenance window")
+	}
+
+	obj := &MaintenanceWindowObject{
+		ShardID:      _maintenanceWindowShardID,
+		WindowID:     window.GetId(),
+		Window:       windowBuffer,
+		CreationTime: time.Now().UTC(),
+	}
+
+	if err := d.store.oClient.CreateIfNotExists(ctx, obj); err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmHostMetrics.MaintenanceWindowCreate.Inc(1)
+	return nil
+}
+
+// GetAll gets all the MaintenanceWindowObjects from db
+func (d *maintenanceWindowOps) GetAll(
+	ctx context.Context,
+) ([]*MaintenanceWindowObject, error) {
+
+	objs, err := d.store.oClient.GetAll(
+		ctx,
+		&MaintenanceWindowObject{ShardID: _maintenanceWindowShardID})
+	if err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	resultObjs := make([]*MaintenanceWindowObject, 0, len(objs))
+	for _, obj := range objs {
+		resultObjs = append(resultObjs, obj.(*MaintenanceWindowObject))
+	}
+
+	d.store.metrics.OrmHostMetrics.MaintenanceWindowGetAll.Inc(1)
+	return resultObjs, nil
+}
+
+// Delete deletes a MaintenanceWindowObject from db
+func (d *maintenanceWindowOps) Delete(ctx context.Context, id string) error {
+	obj := &MaintenanceWindowObject{
+		ShardID:  _maintenanceWindowShardID,
+		WindowID: id,
+	}
+
+	if err := d.store.oClient.Delete(ctx, obj); err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowDeleteFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmHostMetrics.MaintenanceWindowDelete.Inc(1)
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/host"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type MaintenanceWindowObjectTestSuite struct {
+	suite.Suite
+}
+
+func (s *MaintenanceWindowObjectTestSuite) SetupTest() {
+}
+
+func TestMaintenanceWindowObjectSuite(t *testing.T) {
+	suite.Run(t, new(MaintenanceWindowObjectTestSuite))
+}
+
+// TestMaintenanceWindowOps tests MaintenanceWindowObject CRUD operations
+func (s *MaintenanceWindowObjectTestSuite) TestMaintenanceWindowOps() {
+	db := NewMaintenanceWindowOps(testStore)
+	ctx := context.Background()
+
+	window := &host.MaintenanceWindow{
+		Id:           uuid.New(),
+		Hostnames:    []string{"host1", "host2"},
+		StartTime:    "2019-03-04T05:00:00Z",
+		DurationSecs: 3600,
+	}
+
+	// CREATE and GET ops
+	s.NoError(db.Create(ctx, window))
+
+	err := db.Create(ctx, window)
+	s.Error(err)
+	s.True(yarpcerrors.IsAlreadyExists(err))
+
+	objs, err := db.GetAll(ctx)
+	s.NoError(err)
+	var found *MaintenanceWindowObject
+	for _, o := range objs {
+		if o.WindowID == window.GetId() {
+			found = o
+		}
+	}
+	s.NotNil(found)
+	s.False(found.CreationTime.IsZero())
+	storedWindow, err := found.GetWindow()
+	s.NoError(err)
+	s.Equal(window, storedWindow)
+
+	// DELETE ops
+	s.NoError(db.Delete(ctx, window.GetId()))
+
+	objs, err = db.GetAll(ctx)
+	s.NoError(err)
+	for _, o := range objs {
+		s.NotEqual(window.GetId(), o.WindowID)
+	}
+}
+
+// TestMaintenanceWindowOpsClientFail tests failure cases due to ORM
+// Client errors
+func (s *MaintenanceWindowObjectTestSuite) TestMaintenanceWindowOpsClientFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	db := NewMaintenanceWindowOps(mockStore)
+
+	mockClient.EXPECT().CreateIfNotExists(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).
+		Return(errors.New("delete failed"))
+
+	ctx := context.Background()
+
+	err := db.Create(ctx, &host.MaintenanceWindow{Id: "test"})
+	s.Error(err)
+	s.Equal("create failed", err.Error())
+
+	_, err = db.GetAll(ctx)
+	s.Error(err)
+	s.Equal("getall failed", err.Error())
+
+	err = db.Delete(ctx, "test")
+	s.Error(err)
+	s.Equal("delete failed", err.Error())
+}
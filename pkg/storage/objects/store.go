@@ -18,6 +18,7 @@ import (
 	pelotonstore "github.com/uber/peloton/pkg/storage"
 	"github.com/uber/peloton/pkg/storage/cassandra"
 	escassandra "github.com/uber/peloton/pkg/storage/connectors/cassandra"
+	"github.com/uber/peloton/pkg/storage/connectors/memory"
 	"github.com/uber/peloton/pkg/storage/objects/base"
 	"github.com/uber/peloton/pkg/storage/orm"
 
@@ -55,3 +56,16 @@ func NewCassandraStore(
 		metrics: pelotonstore.NewMetrics(scope),
 	}, nil
 }
+
+// NewMemoryStore creates a new storage client which keeps the objects
+// in memory, for tests. The objects are only visible in the process.
+func NewMemoryStore(scope tally.Scope) (*Store, error) {
+	oclient, err := orm.NewClient(memory.NewMemoryConnector(), Objs...)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		oClient: oclient,
+		metrics: pelotonstore.NewMetrics(scope),
+	}, nil
+}
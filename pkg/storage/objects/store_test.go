@@ -16,9 +16,12 @@ package objects
 
 import (
 	"fmt"
+	"testing"
 
 	"github.com/uber/peloton/pkg/storage/cassandra"
 
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
 )
 
@@ -39,3 +42,35 @@ func init() {
 	}
 	testStore = s
 }
+
+// TestObjectSuitesWithMemoryStore runs the object suites
+// against the in-memory connector instead of Cassandra
+func TestObjectSuitesWithMemoryStore(t *testing.T) {
+	memoryStore, err := NewMemoryStore(
+		tally.NewTestScope("", map[string]string{}))
+	require.NoError(t, err)
+
+	cassandraStore := testStore
+	testStore = memoryStore
+	defer func() { testStore = cassandraStore }()
+
+	suites := map[string]suite.TestingSuite{
+		"AuditRecord":       new(AuditRecordObjectTestSuite),
+		"CronJob":           new(CronJobObjectTestSuite),
+		"CronJobRun":        new(CronJobRunObjectTestSuite),
+		"JobConfig":         new(JobConfigObjectTestSuite),
+		"JobIndex":          new(JobIndexObjectTestSuite),
+		"JobNameToID":       new(JobNameToIDObjectTestSuite),
+		"JobWorkflow":       new(JobWorkflowObjectTestSuite),
+		"MaintenanceWindow": new(MaintenanceWindowObjectTestSuite),
+		"PodEvents":         new(PodEventsObjectTestSuite),
+		"Secret":            new(SecretInfoObjectTestSuite),
+		"WatchEvent":        new(WatchEventObjectTestSuite),
+		"WatchRevision":     new(WatchRevisionObjectTestSuite),
+	}
+	for name, s := range suites {
+		t.Run(name, func(t *testing.T) {
+			suite.Run(t, s)
+		})
+	}
+}
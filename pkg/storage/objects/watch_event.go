@@ -0,0 +1,227 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// number of consecutive revisions stored in a partition
+// of the watch_events table
+const _watchEventBucketSize = 1000
+
+// init adds a WatchEventObject instance to the global list of storage objects
+func init() {
+	Objs = append(Objs, &WatchEventObject{})
+}
+
+// WatchEventObject corresponds to a row in watch_events table.
+type WatchEventObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=watch_events, primaryKey=((bucket), revision)"`
+
+	// Bucket of consecutive revisions which partitions the events
+	Bucket uint64 `column:"name=bucket"`
+	// Revision of the event
+	Revision uint64 `column:"name=revision"`
+	// Event as sent to the watch clients
+	Payload []byte `column:"name=payload"`
+	// Labels of the pod of the event, used to filter the pod events
+	Labels string `column:"name=labels"`
+}
+
+// WatchEventOps provides methods for manipulating watch_events table.
+type WatchEventOps interface {
+	// Create inserts a row in the table.
+	Create(
+		ctx context.Context,
+		event *svc.WatchResponse,
+		labels []*peloton.Label,
+	) error
+
+	// Query retrieves the rows of the events with revisions in
+	// [from, to] from the table, sorted by ascending revision.
+	Query(ctx context.Context, from, to uint64) ([]*WatchEventObject, error)
+
+	// Delete removes the rows of the events with revisions in
+	// [from, to] from the table.
+	Delete(ctx context.Context, from, to uint64) error
+}
+
+// ensure that default implementation (watchEventOps) satisfies the interface
+var _ WatchEventOps = (*watchEventOps)(nil)
+
+// GetEvent returns the unmarshaled event
+func (w *WatchEventObject) GetEvent() (*svc.WatchResponse, error) {
+	event := &svc.WatchResponse{}
+	if err := proto.Unmarshal(w.Payload, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// GetLabels returns the unmarshaled labels of the pod of the event
+func (w *WatchEventObject) GetLabels() ([]*peloton.Label, error) {
+	var labels []*peloton.Label
+	if len(w.Labels) == 0 {
+		return labels, nil
+	}
+	if err := json.Unmarshal([]byte(w.Labels), &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// watchEventBucket returns the bucket partitioning the event
+// of the revision
+func watchEventBucket(revision uint64) uint64 {
+	return revision / _watchEventBucketSize
+}
+
+// watchEventOps implements WatchEventOps using a particular Store
+type watchEventOps struct {
+	store *Store
+}
+
+// NewWatchEventOps constructs a WatchEventOps object for provided Store.
+func NewWatchEventOps(s *Store) WatchEventOps {
+	return &watchEventOps{store: s}
+}
+
+// Create creates a WatchEventObject in db
+func (d *watchEventOps) Create(
+	ctx context.Context,
+	event *svc.WatchResponse,
+	labels []*peloton.Label,
+) error {
+
+	payload, err := proto.Marshal(event)
+	if err != nil {
+		d.store.metrics.OrmWatchMetrics.WatchEventCreateFail.Inc(1)
+		return errors.Wrap(err, "Failed to marshal watch event")
+	}
+
+	obj := &WatchEventObject{
+		Bucket:   watchEventBucket(event.GetRevision()),
+		Revision: event.GetRevision(),
+		Payload:  payload,
+	}
+
+	if len(labels) != 0 {
+		buffer, err := json.Marshal(labels)
+		if err != nil {
+			d.store.metrics.OrmWatchMetrics.WatchEventCreateFail.Inc(1)
+			return errors.Wrap(err, "Failed to marshal watch event labels")
+		}
+		obj.Labels = string(buffer)
+	}
+
+	if err := d.store.oClient.Create(ctx, obj); err != nil {
+		d.store.metrics.OrmWatchMetrics.WatchEventCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmWatchMetrics.WatchEventCreate.Inc(1)
+	return nil
+}
+
+// Query gets the WatchEventObjects of the revisions in [from, to]
+// from db, reading one bucket at a time
+func (d *watchEventOps) Query(
+	ctx context.Context,
+	from uint64,
+	to uint64,
+) ([]*WatchEventObject, error) {
+
+	objs, err := d.getRange(ctx, from, to)
+	if err != nil {
+		d.store.metrics.OrmWatchMetrics.WatchEventQueryFail.Inc(1)
+		return nil, err
+	}
+
+	d.store.metrics.OrmWatchMetrics.WatchEventQuery.Inc(1)
+	return objs, nil
+}
+
+// Delete deletes the WatchEventObjects of the revisions in [from, to]
+// from db. Only the revisions which were created are read and deleted.
+func (d *watchEventOps) Delete(
+	ctx context.Context,
+	from uint64,
+	to uint64,
+) error {
+
+	objs, err := d.getRange(ctx, from, to)
+	if err != nil {
+		d.store.metrics.OrmWatchMetrics.WatchEventDeleteFail.Inc(1)
+		return err
+	}
+
+	for _, obj := range objs {
+		if err := d.store.oClient.Delete(ctx, obj); err != nil {
+			d.store.metrics.OrmWatchMetrics.WatchEventDeleteFail.Inc(1)
+			return err
+		}
+	}
+
+	d.store.metrics.OrmWatchMetrics.WatchEventDelete.Inc(1)
+	return nil
+}
+
+// getRange reads the WatchEventObjects of the revisions in [from, to]
+// from db, sorted by ascending revision
+func (d *watchEventOps) getRange(
+	ctx context.Context,
+	from uint64,
+	to uint64,
+) ([]*WatchEventObject, error) {
+
+	var resultObjs []*WatchEventObject
+	if from > to {
+		return resultObjs, nil
+	}
+
+	for bucket := watchEventBucket(from); bucket <= watchEventBucket(to); bucket++ {
+		objs, err := d.store.oClient.GetAll(
+			ctx,
+			&WatchEventObject{Bucket: bucket})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objs {
+			event := obj.(*WatchEventObject)
+			if event.Revision < from || event.Revision > to {
+				continue
+			}
+			resultObjs = append(resultObjs, event)
+		}
+	}
+
+	sort.Slice(resultObjs, func(i, j int) bool {
+		return resultObjs[i].Revision < resultObjs[j].Revision
+	})
+	return resultObjs, nil
+}
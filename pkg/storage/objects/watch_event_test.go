@@ -0,0 +1,130 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type WatchEventObjectTestSuite struct {
+	suite.Suite
+}
+
+func (s *WatchEventObjectTestSuite) SetupTest() {
+}
+
+func TestWatchEventObjectSuite(t *testing.T) {
+	suite.Run(t, new(WatchEventObjectTestSuite))
+}
+
+// TestWatchEventOps tests creating, querying and deleting
+// WatchEventObject across buckets
+func (s *WatchEventObjectTestSuite) TestWatchEventOps() {
+	db := NewWatchEventOps(testStore)
+	ctx := context.Background()
+
+	// the events of other tests may be in the same buckets,
+	// so the revisions of this test start at a unique bucket
+	start := uint64(time.Now().UnixNano()) /
+		_watchEventBucketSize * _watchEventBucketSize
+	revisions := []uint64{
+		start + 1,
+		start + 2,
+		start + _watchEventBucketSize + 1,
+	}
+	labels := []*peloton.Label{{Key: "key", Value: "value"}}
+
+	for _, revision := range revisions {
+		s.NoError(db.Create(
+			ctx,
+			&svc.WatchResponse{
+				Revision: revision,
+				Pods: []*pod.PodSummary{
+					{PodName: &v1alphapeloton.PodName{Value: "pod-0"}},
+				},
+			},
+			labels,
+		))
+	}
+
+	objs, err := db.Query(ctx, start, start+2*_watchEventBucketSize)
+	s.NoError(err)
+	s.Len(objs, len(revisions))
+	for i, obj := range objs {
+		s.Equal(revisions[i], obj.Revision)
+
+		event, err := obj.GetEvent()
+		s.NoError(err)
+		s.Equal(revisions[i], event.GetRevision())
+		s.Equal("pod-0", event.GetPods()[0].GetPodName().GetValue())
+
+		eventLabels, err := obj.GetLabels()
+		s.NoError(err)
+		s.Equal(labels, eventLabels)
+	}
+
+	// only the events in the revision range are returned
+	objs, err = db.Query(ctx, start+2, start+_watchEventBucketSize)
+	s.NoError(err)
+	s.Len(objs, 1)
+	s.Equal(revisions[1], objs[0].Revision)
+
+	s.NoError(db.Delete(ctx, start, start+2))
+	objs, err = db.Query(ctx, start, start+2*_watchEventBucketSize)
+	s.NoError(err)
+	s.Len(objs, 1)
+	s.Equal(revisions[2], objs[0].Revision)
+}
+
+// TestWatchEventOpsClientFail tests failure cases due to ORM Client errors
+func (s *WatchEventObjectTestSuite) TestWatchEventOpsClientFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	db := NewWatchEventOps(mockStore)
+
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed")).Times(2)
+
+	ctx := context.Background()
+
+	err := db.Create(ctx, &svc.WatchResponse{Revision: 1}, nil)
+	s.Error(err)
+	s.Equal("create failed", err.Error())
+
+	_, err = db.Query(ctx, 1, 2)
+	s.Error(err)
+	s.Equal("getall failed", err.Error())
+
+	err = db.Delete(ctx, 1, 2)
+	s.Error(err)
+	s.Equal("getall failed", err.Error())
+}
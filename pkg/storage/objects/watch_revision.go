@@ -0,0 +1,210 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+)
+
+// the revisions reserved by all the job manager leaders are stored
+// in a single shard of the watch_revisions table, so that they can
+// be read with a single query
+const _watchRevisionShardID = 0
+
+// init adds a WatchRevisionObject instance to the global list of storage objects
+func init() {
+	Objs = append(Objs, &WatchRevisionObject{})
+}
+
+// WatchRevisionObject corresponds to a row in watch_revisions table.
+// Each job manager leader writes its own row, so that a leader which
+// is stopping does not overwrite the revisions of the next leader.
+type WatchRevisionObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=watch_revisions, primaryKey=((shard_id), first_revision)"`
+
+	// Shard of the revisions
+	ShardID int `column:"name=shard_id"`
+	// First revision reserved by the leader
+	FirstRevision uint64 `column:"name=first_revision"`
+	// Highest revision reserved by the leader, the next
+	// leader numbers its events after it
+	ReservedRevision uint64 `column:"name=reserved_revision"`
+	// Highest revision which has been compacted, the events of the
+	// revisions up to it can no longer be replayed
+	CompactedRevision uint64 `column:"name=compacted_revision"`
+	// Whether the leader stopped after writing all its events
+	Clean bool `column:"name=clean"`
+	// Time when the revisions were updated
+	UpdateTime time.Time `column:"name=update_time"`
+}
+
+// WatchRevisionOps provides methods for manipulating watch_revisions table.
+type WatchRevisionOps interface {
+	// GetAll retrieves all the rows from the table,
+	// sorted by ascending first revision.
+	GetAll(ctx context.Context) ([]*WatchRevisionObject, error)
+
+	// Create writes the row of the first block of revisions reserved
+	// by a leader to the table. Returns an "already-exists" error if
+	// another leader reserved the same revisions.
+	Create(
+		ctx context.Context,
+		firstRevision uint64,
+		reservedRevision uint64,
+		compactedRevision uint64,
+	) error
+
+	// Update updates the row of the revisions reserved by a leader,
+	// only if its reserved revision is still the expected one. Returns
+	// a "failed-precondition" error otherwise, for instance if the row
+	// was removed by the next leader.
+	Update(
+		ctx context.Context,
+		firstRevision uint64,
+		expectedReservedRevision uint64,
+		reservedRevision uint64,
+		compactedRevision uint64,
+		clean bool,
+	) error
+
+	// Delete removes the row of the revisions reserved by a leader
+	// from the table.
+	Delete(ctx context.Context, firstRevision uint64) error
+}
+
+// ensure that default implementation (watchRevisionOps) satisfies the interface
+var _ WatchRevisionOps = (*watchRevisionOps)(nil)
+
+// watchRevisionOps implements WatchRevisionOps using a particular Store
+type watchRevisionOps struct {
+	store *Store
+}
+
+// NewWatchRevisionOps constructs a WatchRevisionOps object for provided Store.
+func NewWatchRevisionOps(s *Store) WatchRevisionOps {
+	return &watchRevisionOps{store: s}
+}
+
+// GetAll gets all the WatchRevisionObjects from db
+func (d *watchRevisionOps) GetAll(
+	ctx context.Context,
+) ([]*WatchRevisionObject, error) {
+
+	objs, err := d.store.oClient.GetAll(
+		ctx,
+		&WatchRevisionObject{ShardID: _watchRevisionShardID})
+	if err != nil {
+		d.store.metrics.OrmWatchMetrics.WatchRevisionGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	var resultObjs []*WatchRevisionObject
+	for _, obj := range objs {
+		resultObjs = append(resultObjs, obj.(*WatchRevisionObject))
+	}
+	sort.Slice(resultObjs, func(i, j int) bool {
+		return resultObjs[i].FirstRevision < resultObjs[j].FirstRevision
+	})
+
+	d.store.metrics.OrmWatchMetrics.WatchRevisionGetAll.Inc(1)
+	return resultObjs, nil
+}
+
+// Create creates a WatchRevisionObject in db if it does not exist
+func (d *watchRevisionOps) Create(
+	ctx context.Context,
+	firstRevision uint64,
+	reservedRevision uint64,
+	compactedRevision uint64,
+) error {
+
+	obj := &WatchRevisionObject{
+		ShardID:           _watchRevisionShardID,
+		FirstRevision:     firstRevision,
+		ReservedRevision:  reservedRevision,
+		CompactedRevision: compactedRevision,
+		UpdateTime:        time.Now().UTC(),
+	}
+
+	if err := d.store.oClient.CreateIfNotExists(ctx, obj); err != nil {
+		d.store.metrics.OrmWatchMetrics.WatchRevisionCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmWatchMetrics.WatchRevisionCreate.Inc(1)
+	return nil
+}
+
+// Update updates a WatchRevisionObject in db if its reserved
+// revision is the expected one
+func (d *watchRevisionOps) Update(
+	ctx context.Context,
+	firstRevision uint64,
+	expectedReservedRevision uint64,
+	reservedRevision uint64,
+	compactedRevision uint64,
+	clean bool,
+) error {
+
+	obj := &WatchRevisionObject{
+		ShardID:           _watchRevisionShardID,
+		FirstRevision:     firstRevision,
+		ReservedRevision:  reservedRevision,
+		CompactedRevision: compactedRevision,
+		Clean:             clean,
+		UpdateTime:        time.Now().UTC(),
+	}
+
+	if err := d.store.oClient.UpdateIf(
+		ctx,
+		obj,
+		map[string]interface{}{"ReservedRevision": expectedReservedRevision},
+		"ReservedRevision",
+		"CompactedRevision",
+		"Clean",
+		"UpdateTime",
+	); err != nil {
+		d.store.metrics.OrmWatchMetrics.WatchRevisionUpdateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmWatchMetrics.WatchRevisionUpdate.Inc(1)
+	return nil
+}
+
+// Delete deletes a WatchRevisionObject from db
+func (d *watchRevisionOps) Delete(
+	ctx context.Context,
+	firstRevision uint64,
+) error {
+
+	obj := &WatchRevisionObject{
+		ShardID:       _watchRevisionShardID,
+		FirstRevision: firstRevision,
+	}
+
+	if err := d.store.oClient.Delete(ctx, obj); err != nil {
+		d.store.metrics.OrmWatchMetrics.WatchRevisionDeleteFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmWatchMetrics.WatchRevisionDelete.Inc(1)
+	return nil
+}
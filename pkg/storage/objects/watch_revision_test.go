@@ -0,0 +1,145 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type WatchRevisionObjectTestSuite struct {
+	suite.Suite
+}
+
+func (s *WatchRevisionObjectTestSuite) SetupTest() {
+}
+
+func TestWatchRevisionObjectSuite(t *testing.T) {
+	suite.Run(t, new(WatchRevisionObjectTestSuite))
+}
+
+// findWatchRevision returns the object with the first revision
+func findWatchRevision(
+	objs []*WatchRevisionObject,
+	firstRevision uint64,
+) *WatchRevisionObject {
+	for _, obj := range objs {
+		if obj.FirstRevision == firstRevision {
+			return obj
+		}
+	}
+	return nil
+}
+
+// TestWatchRevisionOps tests updating, getting and deleting
+// WatchRevisionObject
+func (s *WatchRevisionObjectTestSuite) TestWatchRevisionOps() {
+	db := NewWatchRevisionOps(testStore)
+	ctx := context.Background()
+
+	// the rows of other tests may be in the same shard,
+	// so the revisions of this test are unique
+	first := uint64(time.Now().UnixNano())
+
+	s.NoError(db.Create(ctx, first, first+999, first-1))
+	s.NoError(db.Create(ctx, first+1000, first+1999, first+500))
+
+	// another leader cannot reserve the same revisions
+	err := db.Create(ctx, first+1000, first+2999, first+500)
+	s.True(yarpcerrors.IsAlreadyExists(err))
+
+	objs, err := db.GetAll(ctx)
+	s.NoError(err)
+	for i := 1; i < len(objs); i++ {
+		s.True(objs[i-1].FirstRevision < objs[i].FirstRevision)
+	}
+
+	obj := findWatchRevision(objs, first)
+	s.NotNil(obj)
+	s.Equal(first+999, obj.ReservedRevision)
+	s.Equal(first-1, obj.CompactedRevision)
+	s.False(obj.Clean)
+	s.False(obj.UpdateTime.IsZero())
+
+	s.NoError(db.Update(ctx, first, first+999, first+1999, first-1, true))
+	objs, err = db.GetAll(ctx)
+	s.NoError(err)
+	s.True(findWatchRevision(objs, first).Clean)
+	s.Equal(first+1999, findWatchRevision(objs, first).ReservedRevision)
+
+	// the update is rejected if the reserved revision has changed
+	err = db.Update(ctx, first, first+999, first+2999, first-1, false)
+	s.True(yarpcerrors.IsFailedPrecondition(err))
+
+	s.NoError(db.Delete(ctx, first))
+	s.NoError(db.Delete(ctx, first+1000))
+	objs, err = db.GetAll(ctx)
+	s.NoError(err)
+	s.Nil(findWatchRevision(objs, first))
+	s.Nil(findWatchRevision(objs, first+1000))
+
+	// the update does not recreate a removed row
+	err = db.Update(ctx, first, first+1999, first+2999, first-1, false)
+	s.True(yarpcerrors.IsFailedPrecondition(err))
+	objs, err = db.GetAll(ctx)
+	s.NoError(err)
+	s.Nil(findWatchRevision(objs, first))
+}
+
+// TestWatchRevisionOpsClientFail tests failure cases due to ORM Client errors
+func (s *WatchRevisionObjectTestSuite) TestWatchRevisionOpsClientFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	db := NewWatchRevisionOps(mockStore)
+
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+	mockClient.EXPECT().CreateIfNotExists(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().UpdateIf(gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errors.New("update failed"))
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).
+		Return(errors.New("delete failed"))
+
+	ctx := context.Background()
+
+	_, err := db.GetAll(ctx)
+	s.Error(err)
+	s.Equal("getall failed", err.Error())
+
+	err = db.Create(ctx, 1, 1000, 0)
+	s.Error(err)
+	s.Equal("create failed", err.Error())
+
+	err = db.Update(ctx, 1, 1000, 2000, 0, false)
+	s.Error(err)
+	s.Equal("update failed", err.Error())
+
+	err = db.Delete(ctx, 1)
+	s.Error(err)
+	s.Equal("delete failed", err.Error())
+}
@@ -17,6 +17,7 @@ package orm
 import (
 	"context"
 	"reflect"
+	"sort"
 
 	"github.com/uber/peloton/pkg/storage/objects/base"
 
@@ -40,6 +41,18 @@ type Client interface {
 	// the caller. If not specified, all fields in the object will be updated
 	// to the DB
 	Update(ctx context.Context, e base.Object, fieldsToUpdate ...string) error
+	// UpdateIf updates the storage object in the database only if the
+	// conditions hold. The conditions map field names to their expected
+	// values in the database. If there are no conditions, the storage
+	// object is only updated if it exists. Returns a "failed-precondition"
+	// error if the conditions do not hold, or a "not-found" error if the
+	// storage object does not exist.
+	UpdateIf(
+		ctx context.Context,
+		e base.Object,
+		conditions map[string]interface{},
+		fieldsToUpdate ...string,
+	) error
 	// Delete deletes the storage object from the database
 	Delete(ctx context.Context, e base.Object) error
 }
@@ -174,6 +187,53 @@ func (c *client) Update(
 	return c.connector.Update(ctx, &table.Definition, row, keyRow)
 }
 
+// UpdateIf updates the storage object in the database if the conditions
+// hold
+func (c *client) UpdateIf(
+	ctx context.Context,
+	e base.Object,
+	conditions map[string]interface{},
+	fieldsToUpdate ...string,
+) error {
+	// lookup if a table exists for this object, return error if not found
+	table, err := c.getTable(e)
+	if err != nil {
+		return err
+	}
+
+	// translate the conditions on fields into conditions on columns,
+	// sorted so that the same conditions always give the same query
+	fields := make([]string, 0, len(conditions))
+	for field := range conditions {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var condRow []base.Column
+	for _, field := range fields {
+		columnName, ok := table.FieldToCol[field]
+		if !ok {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"unknown field %q in update conditions", field)
+		}
+		condRow = append(condRow, base.Column{
+			Name:  columnName,
+			Value: conditions[field],
+		})
+	}
+
+	// translate the storage object into a row (list of column)
+	row := table.GetRowFromObject(e, fieldsToUpdate...)
+
+	// build a primary key row from storage object
+	keyRow := table.GetKeyRowFromObject(e)
+
+	// Tell the connector to update a row in the DB using this row
+	// if the conditions hold
+	return c.connector.UpdateIf(
+		ctx, &table.Definition, row, keyRow, condRow)
+}
+
 // Delete deletes the storage object in the database
 func (c *client) Delete(ctx context.Context, e base.Object) error {
 	// lookup if a table exists for this object, return error if not found
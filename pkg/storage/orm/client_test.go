@@ -23,6 +23,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 type ORMTestSuite struct {
@@ -276,6 +277,47 @@ func (suite *ORMTestSuite) TestClientUpdate() {
 	suite.Error(err)
 }
 
+// TestClientUpdateIf tests client conditional update operation
+func (suite *ORMTestSuite) TestClientUpdateIf() {
+	defer suite.ctrl.Finish()
+	conn := ormmocks.NewMockConnector(suite.ctrl)
+
+	conn.EXPECT().UpdateIf(
+		suite.ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ *base.Definition,
+			row []base.Column, keyRow []base.Column, condRow []base.Column) {
+			suite.Equal("data", row[0].Name)
+			suite.Equal("testdata", row[0].Value)
+			suite.Equal("id", keyRow[0].Name)
+			suite.Equal(uint64(1), keyRow[0].Value)
+			suite.Equal([]base.Column{
+				{Name: "data", Value: "olddata"},
+			}, condRow)
+		}).Return(nil)
+
+	client, err := NewClient(conn, &ValidObject{})
+	suite.NoError(err)
+
+	// Update Data field in testValidObject if it has the expected Data
+	err = client.UpdateIf(
+		suite.ctx,
+		testValidObject,
+		map[string]interface{}{"Data": "olddata"},
+		"Data")
+	suite.NoError(err)
+
+	// the conditions must be on fields of the object
+	err = client.UpdateIf(
+		suite.ctx,
+		testValidObject,
+		map[string]interface{}{"Unknown": "test"},
+		"Data")
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	err = client.UpdateIf(suite.ctx, &InvalidObject1{}, nil)
+	suite.Error(err)
+}
+
 // TestClientDelete tests client delete operation on valid and invalid entities
 func (suite *ORMTestSuite) TestClientDelete() {
 	defer suite.ctrl.Finish()
@@ -55,6 +55,18 @@ type Connector interface {
 		keys []base.Column,
 	) error
 
+	// UpdateIf updates a row in the DB for the base object only if the
+	// conditions hold, or only if the row exists if there are no
+	// conditions. Returns a "failed-precondition" error if the conditions
+	// do not hold, or a "not-found" error if the row does not exist.
+	UpdateIf(
+		ctx context.Context,
+		e *base.Definition,
+		values []base.Column,
+		keys []base.Column,
+		conditions []base.Column,
+	) error
+
 	// Delete deletes a row from the DB for the base object
 	Delete(ctx context.Context, e *base.Definition, keys []base.Column) error
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/uber/peloton/pkg/storage"
 	"github.com/uber/peloton/pkg/storage/cassandra"
 	storage_config "github.com/uber/peloton/pkg/storage/config"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 )
 
 // MustCreateStore creates a generic store that is needed by peloton
@@ -41,3 +42,25 @@ func MustCreateStore(
 	}
 	return store
 }
+
+// MustCreateORMStore creates the ORM store that is needed by peloton,
+// in the memory of the daemon if configured for testing, and exits if
+// store can't be created. The store created by MustCreateStore is always
+// backed by Cassandra.
+func MustCreateORMStore(
+	cfg *storage_config.Config, rootScope tally.Scope) *ormobjects.Store {
+	var ormStore *ormobjects.Store
+	var err error
+	if cfg.InMemoryORMForTesting {
+		log.Warn("Using in-memory ORM store for testing, the ORM objects " +
+			"are neither persisted nor shared with the other daemons, " +
+			"the other objects are still stored in Cassandra")
+		ormStore, err = ormobjects.NewMemoryStore(rootScope)
+	} else {
+		ormStore, err = ormobjects.NewCassandraStore(&cfg.Cassandra, rootScope)
+	}
+	if err != nil {
+		log.WithError(err).Fatal("Failed to create ORM store")
+	}
+	return ormStore
+}